@@ -0,0 +1,147 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/kubeflow/kfserving/pkg/batch"
+	s3credential "github.com/kubeflow/kfserving/pkg/credentials/s3"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+var (
+	inputDir           = flag.String("input-dir", "/mnt/models", "Directory of the JSON lines input files")
+	outputURI          = flag.String("output-uri", "", "s3://<bucket>/<path> or local directory the results are written to")
+	predictorURL       = flag.String("predictor-url", "", "Predict endpoint of the InferenceService")
+	shardIndex         = flag.Int("shard-index", 0, "Index of the shard processed by this runner")
+	shardCount         = flag.Int("shard-count", 1, "Number of shards")
+	workers            = flag.Int("workers", 1, "Number of concurrent requests")
+	retries            = flag.Int("retries", 3, "Number of retries of a failed request")
+	timeout            = flag.Duration("timeout", 60*time.Second, "Timeout of a predict request")
+	terminationMessage = flag.String("termination-message-path", "/dev/termination-log", "File the record summary is written to")
+)
+
+func main() {
+	flag.Parse()
+
+	logf.SetLogger(logf.ZapLogger(false))
+	log := logf.Log.WithName("entrypoint")
+
+	if *outputURI == "" || *predictorURL == "" {
+		log.Info("output-uri and predictor-url arguments must not be empty.")
+		os.Exit(-1)
+	}
+
+	outputDir := *outputURI
+	if strings.HasPrefix(*outputURI, "s3://") {
+		dir, err := ioutil.TempDir("", "batch")
+		if err != nil {
+			log.Error(err, "Failed to create output directory")
+			os.Exit(1)
+		}
+		outputDir = dir
+	}
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		log.Error(err, "Failed to create output directory", "dir", outputDir)
+		os.Exit(1)
+	}
+	outputFile := filepath.Join(outputDir, batch.OutputFileName(*shardIndex))
+	out, err := os.Create(outputFile)
+	if err != nil {
+		log.Error(err, "Failed to create output file", "file", outputFile)
+		os.Exit(1)
+	}
+
+	runner := &batch.Runner{
+		InputDir:     *inputDir,
+		PredictorURL: *predictorURL,
+		ShardIndex:   *shardIndex,
+		ShardCount:   *shardCount,
+		Workers:      *workers,
+		Retries:      *retries,
+		Backoff:      time.Second,
+		Client:       &http.Client{Timeout: *timeout},
+		Log:          log,
+	}
+	log.Info("Starting", "shard", *shardIndex, "shards", *shardCount, "predictor", *predictorURL)
+	summary, err := runner.Run(out)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		log.Error(err, "Failed to process shard")
+		os.Exit(1)
+	}
+
+	if strings.HasPrefix(*outputURI, "s3://") {
+		if err := upload(outputFile, *outputURI); err != nil {
+			log.Error(err, "Failed to upload results", "outputUri", *outputURI)
+			os.Exit(1)
+		}
+	}
+
+	log.Info("Completed", "processed", summary.Processed, "failed", summary.Failed)
+	data, _ := json.Marshal(summary)
+	if err := ioutil.WriteFile(*terminationMessage, data, 0644); err != nil {
+		log.Error(err, "Failed to write termination message")
+	}
+}
+
+// upload copies the output file to s3://<bucket>/<path>/<file>, the client is configured the same way as by the model agent
+func upload(file string, uri string) error {
+	tokens := strings.SplitN(strings.TrimPrefix(uri, "s3://"), "/", 2)
+	key := filepath.Base(file)
+	if len(tokens) == 2 && tokens[1] != "" {
+		key = strings.TrimSuffix(tokens[1], "/") + "/" + key
+	}
+	config := &aws.Config{}
+	if endpoint, ok := os.LookupEnv(s3credential.AWSEndpointUrl); ok {
+		region, _ := os.LookupEnv(s3credential.AWSRegion)
+		useVirtualBucket := strings.ToLower(os.Getenv(s3credential.S3UseVirtualBucket)) != "false"
+		config = &aws.Config{
+			Endpoint:         aws.String(endpoint),
+			Region:           aws.String(region),
+			S3ForcePathStyle: aws.Bool(!useVirtualBucket),
+		}
+	}
+	sess, err := session.NewSession(config)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = s3manager.NewUploader(sess).Upload(&s3manager.UploadInput{
+		Bucket: aws.String(tokens[0]),
+		Key:    aws.String(key),
+		Body:   f,
+	})
+	return err
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/diagnose"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func newDiagnoseCmd() *cobra.Command {
+	var namespace string
+	cmd := &cobra.Command{
+		Use:   "diagnose NAME",
+		Short: "Explain why an InferenceService is not ready",
+		Long: `Diagnose walks the conditions of the InferenceService, the knative services, revisions and pods
+of its components and their recent warning events, and prints the causes of the InferenceService not being ready.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cli, err := newClient()
+			if err != nil {
+				return err
+			}
+			isvc := &v1beta1.InferenceService{}
+			if err := cli.Get(context.TODO(), types.NamespacedName{Name: args[0], Namespace: namespace}, isvc); err != nil {
+				return err
+			}
+			findings, err := diagnose.Diagnose(context.TODO(), cli, isvc)
+			if err != nil {
+				return err
+			}
+			if len(findings) == 0 {
+				fmt.Printf("InferenceService %s is ready\n", isvc.Name)
+				return nil
+			}
+			fmt.Printf("InferenceService %s is not ready:\n", isvc.Name)
+			for _, finding := range findings {
+				fmt.Printf("- %s\n", finding)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "default", "Namespace of the InferenceService")
+	return cmd
+}
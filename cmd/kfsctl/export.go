@@ -0,0 +1,121 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"os"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/kubeflow/kfserving/pkg/render"
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+)
+
+func newExportCmd() *cobra.Command {
+	var (
+		filename  string
+		namespace string
+		domain    string
+		pin       bool
+	)
+	cmd := &cobra.Command{
+		Use:   "export [NAME]",
+		Short: "Export the fully resolved manifests of an InferenceService as YAML",
+		Long: `Export prints the defaulted InferenceService followed by all the resources the controller creates
+for it as YAML. The configuration is read from the inferenceservice configmap of the current cluster. The
+InferenceService is read from the cluster by name, or from a file with --filename to review a change before
+it is applied.
+
+With --pin the component images are pinned to the digests resolved in the running pods of the deployed
+InferenceService, and the digests of the s3:// models are recorded in the serving.kubeflow.org/model-digests
+annotation, so that "kfsctl import" deploys the same images and models in another cluster.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cli, err := newClient()
+			if err != nil {
+				return err
+			}
+			isvc := &v1beta1.InferenceService{}
+			if filename != "" {
+				if err := readYAMLFile(filename, isvc); err != nil {
+					return err
+				}
+				if isvc.Namespace == "" {
+					isvc.Namespace = namespace
+				}
+			} else {
+				if len(args) != 1 {
+					return cmd.Usage()
+				}
+				if err := cli.Get(context.TODO(), types.NamespacedName{Name: args[0], Namespace: namespace}, isvc); err != nil {
+					return err
+				}
+			}
+			if pin {
+				pods := &v1.PodList{}
+				if err := cli.List(context.TODO(), pods, client.InNamespace(isvc.Namespace),
+					client.MatchingLabels{constants.InferenceServicePodLabelKey: isvc.Name}); err != nil {
+					return err
+				}
+				if err := render.Pin(isvc, pods.Items, modelDigest); err != nil {
+					return err
+				}
+			}
+			isvcConfig, err := v1beta1.NewInferenceServicesConfig(cli)
+			if err != nil {
+				return err
+			}
+			ingressConfig, err := v1beta1.NewIngressConfig(cli)
+			if err != nil {
+				return err
+			}
+			objects, err := render.Export(isvc, render.Options{
+				InferenceServicesConfig: isvcConfig,
+				IngressConfig:           ingressConfig,
+				Domain:                  domain,
+			})
+			if err != nil {
+				return err
+			}
+			data, err := render.ToYAML(objects)
+			if err != nil {
+				return err
+			}
+			_, err = os.Stdout.Write(data)
+			return err
+		},
+	}
+	cmd.Flags().StringVarP(&filename, "filename", "f", "", "Path of an InferenceService YAML file to export instead of the deployed one")
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "default", "Namespace of the InferenceService")
+	cmd.Flags().StringVar(&domain, "domain", render.DefaultDomain, "Knative domain used to derive the host of an InferenceService without url")
+	cmd.Flags().BoolVar(&pin, "pin", false, "Pin the images and models to the digests of the deployed InferenceService")
+	return cmd
+}
+
+// newClient creates a client for the cluster of the current kubeconfig context
+func newClient() (client.Client, error) {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	return client.New(cfg, client.Options{Scheme: render.Scheme})
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/kubeflow/kfserving/pkg/agent/storage"
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	s3credential "github.com/kubeflow/kfserving/pkg/credentials/s3"
+	"github.com/kubeflow/kfserving/pkg/render"
+	"github.com/spf13/cobra"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func newImportCmd() *cobra.Command {
+	var (
+		filename   string
+		namespace  string
+		skipVerify bool
+	)
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Import an InferenceService exported with kfsctl export",
+		Long: `Import creates or updates the InferenceService of the manifests printed by "kfsctl export" in the cluster of
+the current kubeconfig context. The child resources of the manifests are ignored, the controller creates them
+again. The models are verified against the digests recorded by "kfsctl export --pin" before the InferenceService
+is applied, so that the imported InferenceService serves the same models as the exported one.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if filename == "" {
+				return cmd.Usage()
+			}
+			data, err := ioutil.ReadFile(filename)
+			if err != nil {
+				return fmt.Errorf(ReadFileError, filename, err)
+			}
+			isvc, err := render.ParseExport(data)
+			if err != nil {
+				return fmt.Errorf(ParseFileError, filename, err)
+			}
+			if namespace != "" {
+				isvc.Namespace = namespace
+			}
+			if !skipVerify {
+				if err := render.VerifyModelDigests(isvc, modelDigest); err != nil {
+					return err
+				}
+			}
+			cli, err := newClient()
+			if err != nil {
+				return err
+			}
+			existing := &v1beta1.InferenceService{}
+			err = cli.Get(context.TODO(), types.NamespacedName{Name: isvc.Name, Namespace: isvc.Namespace}, existing)
+			if apierr.IsNotFound(err) {
+				if err := cli.Create(context.TODO(), isvc); err != nil {
+					return err
+				}
+				fmt.Fprintf(os.Stdout, "inferenceservice/%s created\n", isvc.Name)
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			existing.Labels = isvc.Labels
+			existing.Annotations = isvc.Annotations
+			existing.Spec = isvc.Spec
+			if err := cli.Update(context.TODO(), existing); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stdout, "inferenceservice/%s configured\n", isvc.Name)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&filename, "filename", "f", "", "Path of the manifests printed by kfsctl export")
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "Namespace to import the InferenceService to, defaults to the exported namespace")
+	cmd.Flags().BoolVar(&skipVerify, "skip-model-verification", false, "Import without verifying the model digests")
+	return cmd
+}
+
+// modelDigest computes the digest of the s3:// models with the S3 client of the model agent, the client is
+// configured from the same environment variables. The other storages are not pinned.
+func modelDigest(storageURI string) (string, error) {
+	if !strings.HasPrefix(storageURI, string(storage.S3)) {
+		fmt.Fprintf(os.Stderr, "Model %s is not pinned, only s3:// models have a digest\n", storageURI)
+		return "", nil
+	}
+	config := &aws.Config{}
+	if endpoint, ok := os.LookupEnv(s3credential.AWSEndpointUrl); ok {
+		region, _ := os.LookupEnv(s3credential.AWSRegion)
+		useVirtualBucket := strings.ToLower(os.Getenv(s3credential.S3UseVirtualBucket)) != "false"
+		config = &aws.Config{
+			Endpoint:         aws.String(endpoint),
+			Region:           aws.String(region),
+			S3ForcePathStyle: aws.Bool(!useVirtualBucket),
+		}
+	}
+	sess, err := session.NewSession(config)
+	if err != nil {
+		return "", err
+	}
+	provider := &storage.S3Provider{Client: s3.New(sess)}
+	return provider.ModelDigest(storageURI)
+}
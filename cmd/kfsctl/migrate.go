@@ -0,0 +1,118 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/spf13/cobra"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+)
+
+// InferenceServiceCRDName is the name of the InferenceService custom resource definition
+const InferenceServiceCRDName = "inferenceservices.serving.kubeflow.org"
+
+func newMigrateCmd() *cobra.Command {
+	var namespace string
+	cmd := &cobra.Command{
+		Use:   "migrate-storage",
+		Short: "Rewrite the stored InferenceServices in the storage version of the CRD",
+		Long: `Migrate-storage rewrites every InferenceService so that the API server stores it in the storage version of
+the InferenceService CRD, then records the storage version as the only stored version of the CRD. Run it after
+upgrading to a release which changes the storage version and before removing a version from the CRD, the
+objects of a removed version which are still stored can no longer be read.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cli, err := newMigrateClient()
+			if err != nil {
+				return err
+			}
+			crd := &apiextensionsv1beta1.CustomResourceDefinition{}
+			if err := cli.Get(context.TODO(), types.NamespacedName{Name: InferenceServiceCRDName}, crd); err != nil {
+				return err
+			}
+			storageVersion := ""
+			for _, version := range crd.Spec.Versions {
+				if version.Storage {
+					storageVersion = version.Name
+				}
+			}
+			if storageVersion == "" {
+				return fmt.Errorf("custom resource definition %s has no storage version", InferenceServiceCRDName)
+			}
+			isvcs := &v1beta1.InferenceServiceList{}
+			if err := cli.List(context.TODO(), isvcs, client.InNamespace(namespace)); err != nil {
+				return err
+			}
+			for i := range isvcs.Items {
+				isvc := &isvcs.Items[i]
+				// an update without changes is written in the storage version
+				if err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+					err := cli.Update(context.TODO(), isvc)
+					if apierrors.IsConflict(err) {
+						if err := cli.Get(context.TODO(), types.NamespacedName{Name: isvc.Name, Namespace: isvc.Namespace},
+							isvc); err != nil {
+							return err
+						}
+					}
+					return err
+				}); client.IgnoreNotFound(err) != nil {
+					return fmt.Errorf("fails to migrate InferenceService %s/%s: %v", isvc.Namespace, isvc.Name, err)
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "migrated %s/%s\n", isvc.Namespace, isvc.Name)
+			}
+			if namespace != "" {
+				// the objects of the other namespaces may still be stored in the previous versions
+				return nil
+			}
+			crd.Status.StoredVersions = []string{storageVersion}
+			if err := cli.Status().Update(context.TODO(), crd); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "custom resource definition %s is stored in %s\n", InferenceServiceCRDName, storageVersion)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "Namespace of the InferenceServices, all namespaces by default")
+	return cmd
+}
+
+// newMigrateClient creates a client for the InferenceServices and their custom resource definition
+func newMigrateClient() (client.Client, error) {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	scheme := runtime.NewScheme()
+	for _, addToScheme := range []func(*runtime.Scheme) error{
+		v1beta1.AddToScheme,
+		apiextensionsv1beta1.AddToScheme,
+	} {
+		if err := addToScheme(scheme); err != nil {
+			return nil, err
+		}
+	}
+	return client.New(cfg, client.Options{Scheme: scheme})
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/render"
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// Known error messages
+const (
+	ReadFileError  = "Error reading file %s: %v"
+	ParseFileError = "Error parsing file %s: %v"
+)
+
+func newRenderCmd() *cobra.Command {
+	var (
+		filename   string
+		configFile string
+		namespace  string
+		domain     string
+	)
+	cmd := &cobra.Command{
+		Use:   "render",
+		Short: "Render the resources generated for an InferenceService without applying them",
+		Long: `Render prints the Knative Services, Istio VirtualService and other resources the controller
+creates for the given InferenceService as YAML. The InferenceService is defaulted and validated with the
+configuration of the given inferenceservice configmap.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			isvc := &v1beta1.InferenceService{}
+			if err := readYAMLFile(filename, isvc); err != nil {
+				return err
+			}
+			if isvc.Namespace == "" {
+				isvc.Namespace = namespace
+			}
+			options, err := readOptions(configFile)
+			if err != nil {
+				return err
+			}
+			options.Domain = domain
+			objects, err := render.Render(isvc, *options)
+			if err != nil {
+				return err
+			}
+			data, err := render.ToYAML(objects)
+			if err != nil {
+				return err
+			}
+			_, err = os.Stdout.Write(data)
+			return err
+		},
+	}
+	cmd.Flags().StringVarP(&filename, "filename", "f", "", "Path of the InferenceService YAML file")
+	cmd.MarkFlagRequired("filename")
+	cmd.Flags().StringVarP(&configFile, "config", "c", "", "Path of the inferenceservice configmap YAML file, e.g. the output of kubectl get configmap -n kfserving-system inferenceservice-config -o yaml")
+	cmd.MarkFlagRequired("config")
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "default", "Namespace of the InferenceService if not set in the file")
+	cmd.Flags().StringVar(&domain, "domain", render.DefaultDomain, "Knative domain used to derive the InferenceService host")
+	return cmd
+}
+
+// readOptions reads the component and ingress configurations from an inferenceservice configmap file
+func readOptions(configFile string) (*render.Options, error) {
+	configMap := &v1.ConfigMap{}
+	if err := readYAMLFile(configFile, configMap); err != nil {
+		return nil, err
+	}
+	isvcConfig, err := v1beta1.NewInferenceServicesConfigFromConfigMap(configMap)
+	if err != nil {
+		return nil, err
+	}
+	ingressConfig, err := v1beta1.NewIngressConfigFromConfigMap(configMap)
+	if err != nil {
+		return nil, err
+	}
+	return &render.Options{
+		InferenceServicesConfig: isvcConfig,
+		IngressConfig:           ingressConfig,
+	}, nil
+}
+
+func readYAMLFile(filename string, object interface{}) error {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf(ReadFileError, filename, err)
+	}
+	if err := yaml.Unmarshal(data, object); err != nil {
+		return fmt.Errorf(ParseFileError, filename, err)
+	}
+	return nil
+}
@@ -2,24 +2,29 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"net/http"
 	"net/url"
 	"os"
+	"strings"
 
 	"github.com/kubeflow/kfserving/pkg/apis/serving/v1alpha2"
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
 	"sigs.k8s.io/controller-runtime/pkg/runtime/signals"
 
 	"github.com/kubeflow/kfserving/pkg/logger"
+	"github.com/kubeflow/kfserving/pkg/modelschema"
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"golang.org/x/net/http2"
 	"golang.org/x/net/http2/h2c"
 	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
 )
 
 var (
-	logUrl           = flag.String("log-url", "", "The URL to send request/response logs to")
+	logUrl           = flag.String("log-url", "", "The comma separated URLs to send request/response logs to")
 	port             = flag.String("port", "8081", "Logger port")
 	componentHost    = flag.String("component-host", "0.0.0.0", "Component host")
 	componentPort    = flag.String("component-port", "8080", "Component port")
@@ -29,6 +34,12 @@ var (
 	inferenceService = flag.String("inference-service", "", "The InferenceService name to add as header to log events")
 	namespace        = flag.String("namespace", "", "The namespace to add as header to log events")
 	endpoint         = flag.String("endpoint", "", "The endpoint name to add as header to log events")
+	outlierUrl       = flag.String("outlier-url", "", "The URL of the outlier detector scoring the requests before the response is returned")
+	schemaFile       = flag.String("schema-file", "", "The model metadata file the v2 inference requests are validated against")
+	retries          = flag.Int("retries", logger.DefaultRetries, "The number of retries of the log events which failed to be delivered")
+	deadLetterUrl    = flag.String("dead-letter-url", "", "The URL the log events are sent to once their retries are exhausted")
+	metricsPort      = flag.String("metrics-port", "9081", "The port of the metrics of the log event deliveries")
+	ensembleSpec     = flag.String("ensemble", "", "The JSON ensemble of the models whose predictions are combined for the predict requests of the InferenceService")
 )
 
 func main() {
@@ -37,15 +48,40 @@ func main() {
 	logf.SetLogger(logf.ZapLogger(false))
 	log := logf.Log.WithName("entrypoint")
 
-	if *logUrl == "" {
-		log.Info("log-url argument must not be empty.")
+	if *logUrl == "" && *outlierUrl == "" && *schemaFile == "" && *ensembleSpec == "" {
+		log.Info("log-url, outlier-url, schema-file or ensemble argument must not be empty.")
 		os.Exit(-1)
 	}
 
-	logUrlParsed, err := url.Parse(*logUrl)
-	if err != nil {
-		log.Info("Malformed log-url", "URL", *logUrl)
-		os.Exit(-1)
+	var logUrls []*url.URL
+	if *logUrl != "" {
+		for _, u := range strings.Split(*logUrl, ",") {
+			logUrlParsed, err := url.Parse(u)
+			if err != nil {
+				log.Info("Malformed log-url", "URL", u)
+				os.Exit(-1)
+			}
+			logUrls = append(logUrls, logUrlParsed)
+		}
+	}
+
+	var outlierUrlParsed *url.URL
+	if *outlierUrl != "" {
+		parsed, err := url.Parse(*outlierUrl)
+		if err != nil {
+			log.Info("Malformed outlier-url", "URL", *outlierUrl)
+			os.Exit(-1)
+		}
+		outlierUrlParsed = parsed
+	}
+	delivery := logger.DeliveryPolicy{Retries: *retries, RetryInterval: logger.DefaultRetryInterval}
+	if *deadLetterUrl != "" {
+		parsed, err := url.Parse(*deadLetterUrl)
+		if err != nil {
+			log.Info("Malformed dead-letter-url", "URL", *deadLetterUrl)
+			os.Exit(-1)
+		}
+		delivery.DeadLetterUrl = parsed
 	}
 	loggingMode := v1alpha2.LoggerMode(*logMode)
 	switch loggingMode {
@@ -64,9 +100,23 @@ func main() {
 		os.Exit(-1)
 	}
 
+	var schema *modelschema.File
+	if *schemaFile != "" {
+		schema = modelschema.NewFile(*schemaFile)
+	}
+
+	var ensemble *v1beta1.EnsembleSpec
+	if *ensembleSpec != "" {
+		ensemble = &v1beta1.EnsembleSpec{}
+		if err := json.Unmarshal([]byte(*ensembleSpec), ensemble); err != nil {
+			log.Info("Malformed ensemble", "ensemble", *ensembleSpec)
+			os.Exit(-1)
+		}
+	}
+
 	stopCh := signals.SetupSignalHandler()
 
-	var eh http.Handler = logger.New(log, *componentHost, *componentPort, logUrlParsed, sourceUriParsed, loggingMode, *inferenceService, *namespace, *endpoint)
+	var eh http.Handler = logger.New(log, *componentHost, *componentPort, logUrls, sourceUriParsed, loggingMode, *inferenceService, *namespace, *endpoint, outlierUrlParsed, schema, ensemble)
 
 	h1s := &http.Server{
 		Addr:    ":" + *port,
@@ -74,11 +124,11 @@ func main() {
 	}
 
 	log.Info("Starting the log dispatcher")
-	logger.StartDispatcher(*workers, log)
+	logger.StartDispatcher(*workers, delivery, log)
 
 	log.Info("Starting", "port", *port)
 
-	errCh := make(chan error, 1)
+	errCh := make(chan error, 2)
 	go func(name string, s *http.Server) {
 		// Don't forward ErrServerClosed as that indicates we're already shutting down.
 		if err := s.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -86,6 +136,16 @@ func main() {
 		}
 	}("default", h1s)
 
+	metricsServer := &http.Server{
+		Addr:    ":" + *metricsPort,
+		Handler: promhttp.Handler(),
+	}
+	go func(name string, s *http.Server) {
+		if err := s.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			errCh <- errors.Wrapf(err, "%s server failed", name)
+		}
+	}("metrics", metricsServer)
+
 	// Exit as soon as we see a shutdown signal or the server failed.
 	select {
 	case <-stopCh:
@@ -97,5 +157,8 @@ func main() {
 	if err != nil {
 		log.Error(err, "Failed to shutdown HTTP server")
 	}
+	if err := metricsServer.Shutdown(context.Background()); err != nil {
+		log.Error(err, "Failed to shutdown metrics server")
+	}
 
 }
@@ -18,12 +18,17 @@ package main
 
 import (
 	"flag"
+	servingv1 "github.com/kubeflow/kfserving/pkg/apis/serving/v1"
 	"github.com/kubeflow/kfserving/pkg/apis/serving/v1alpha1"
 	"github.com/kubeflow/kfserving/pkg/apis/serving/v1alpha2"
 	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	batchinferencejobcontroller "github.com/kubeflow/kfserving/pkg/controller/v1alpha1/batchinferencejob"
+	inferencequotacontroller "github.com/kubeflow/kfserving/pkg/controller/v1alpha1/inferencequota"
 	trainedmodelcontroller "github.com/kubeflow/kfserving/pkg/controller/v1alpha1/trainedmodel"
 	"github.com/kubeflow/kfserving/pkg/controller/v1alpha1/trainedmodel/reconcilers/modelconfig"
 	v1beta1controller "github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/reconcilers/knative"
+	multiclustercontroller "github.com/kubeflow/kfserving/pkg/controller/v1beta1/multicluster"
 	"github.com/kubeflow/kfserving/pkg/webhook/admission/pod"
 	istio_networking "istio.io/api/networking/v1alpha3"
 	"istio.io/client-go/pkg/apis/networking/v1alpha3"
@@ -33,6 +38,7 @@ import (
 	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 	"k8s.io/client-go/tools/record"
+	autoscalingv1alpha1 "knative.dev/serving/pkg/apis/autoscaling/v1alpha1"
 	knservingv1 "knative.dev/serving/pkg/apis/serving/v1"
 	"os"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -41,6 +47,7 @@ import (
 	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
 	"sigs.k8s.io/controller-runtime/pkg/runtime/signals"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"time"
 )
 
 var (
@@ -52,11 +59,14 @@ func init() {
 	// Allow unknown fields in Istio API client for backwards compatibility if cluster has existing vs with deprecated fields.
 	istio_networking.VirtualServiceUnmarshaler.AllowUnknownFields = true
 	istio_networking.GatewayUnmarshaler.AllowUnknownFields = true
+	istio_networking.DestinationRuleUnmarshaler.AllowUnknownFields = true
 }
 
 func main() {
 	var metricsAddr string
+	var syncPeriod time.Duration
 	flag.StringVar(&metricsAddr, "metrics-addr", ":8080", "The address the metric endpoint binds to.")
+	flag.DurationVar(&syncPeriod, "sync-period", 10*time.Hour, "The resync period of the informers.")
 	flag.Parse()
 	logf.SetLogger(logf.ZapLogger(false))
 	log := logf.Log.WithName("entrypoint")
@@ -69,9 +79,16 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Create a new Cmd to provide shared dependencies and start components
+	// Create a new Cmd to provide shared dependencies and start components. The informers of the manager watch all the
+	// namespaces and cache the objects whole: the cache of controller-runtime 0.6 has no label selector or transform to
+	// strip the managed fields, and its single namespace option would hide the configmaps of the KFServing namespace
+	// from the controllers of the other namespaces.
 	log.Info("Setting up manager")
-	mgr, err := manager.New(cfg, manager.Options{MetricsBindAddress: metricsAddr, Port: 9443})
+	mgr, err := manager.New(cfg, manager.Options{
+		MetricsBindAddress: metricsAddr,
+		Port:               9443,
+		SyncPeriod:         &syncPeriod,
+	})
 	if err != nil {
 		log.Error(err, "unable to set up overall controller manager")
 		os.Exit(1)
@@ -97,12 +114,24 @@ func main() {
 		os.Exit(1)
 	}
 
+	log.Info("Setting up KFServing v1 scheme")
+	if err := servingv1.AddToScheme(mgr.GetScheme()); err != nil {
+		log.Error(err, "unable to add KFServing v1 to scheme")
+		os.Exit(1)
+	}
+
 	log.Info("Setting up Knative scheme")
 	if err := knservingv1.AddToScheme(mgr.GetScheme()); err != nil {
 		log.Error(err, "unable to add Knative APIs to scheme")
 		os.Exit(1)
 	}
 
+	log.Info("Setting up Knative autoscaling scheme")
+	if err := autoscalingv1alpha1.AddToScheme(mgr.GetScheme()); err != nil {
+		log.Error(err, "unable to add Knative autoscaling APIs to scheme")
+		os.Exit(1)
+	}
+
 	log.Info("Setting up Istio schemes")
 	if err := v1alpha3.AddToScheme(mgr.GetScheme()); err != nil {
 		log.Error(err, "unable to add Istio v1alpha3 APIs to scheme")
@@ -124,6 +153,8 @@ func main() {
 		os.Exit(1)
 	}
 	eventBroadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: clientSet.CoreV1().Events("")})
+	// The stale revisions are read directly to avoid caching the revisions of all the namespaces
+	knative.GarbageCollectionReader = mgr.GetAPIReader()
 	if err = (&v1beta1controller.InferenceServiceReconciler{
 		Client: mgr.GetClient(),
 		Log:    ctrl.Log.WithName("v1beta1Controllers").WithName("InferenceService"),
@@ -150,11 +181,47 @@ func main() {
 		os.Exit(1)
 	}
 
+	//Setup BatchInferenceJob controller
+	setupLog.Info("Setting up v1alpha1 BatchInferenceJob controller")
+	if err = (&batchinferencejobcontroller.BatchInferenceJobReconciler{
+		Client:   mgr.GetClient(),
+		Log:      ctrl.Log.WithName("v1alpha1Controllers").WithName("BatchInferenceJob"),
+		Scheme:   mgr.GetScheme(),
+		Recorder: eventBroadcaster.NewRecorder(mgr.GetScheme(), v1.EventSource{Component: "v1alpha1Controllers"}),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "v1alpha1Controllers", "BatchInferenceJob")
+		os.Exit(1)
+	}
+
+	//Setup InferenceQuota controller
+	setupLog.Info("Setting up v1alpha1 InferenceQuota controller")
+	if err = (&inferencequotacontroller.InferenceQuotaReconciler{
+		Client: mgr.GetClient(),
+		Log:    ctrl.Log.WithName("v1alpha1Controllers").WithName("InferenceQuota"),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "v1alpha1Controllers", "InferenceQuota")
+		os.Exit(1)
+	}
+
+	//Setup multi-cluster InferenceService controller
+	setupLog.Info("Setting up v1beta1 multi-cluster InferenceService controller")
+	if err = (&multiclustercontroller.MultiClusterReconciler{
+		Client:    mgr.GetClient(),
+		Log:       ctrl.Log.WithName("v1beta1Controllers").WithName("MultiCluster"),
+		Scheme:    mgr.GetScheme(),
+		Recorder:  eventBroadcaster.NewRecorder(mgr.GetScheme(), v1.EventSource{Component: "v1beta1Controllers"}),
+		NewClient: multiclustercontroller.NewClusterClient,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "v1beta1Controllers", "MultiCluster")
+		os.Exit(1)
+	}
+
 	log.Info("setting up webhook server")
 	hookServer := mgr.GetWebhookServer()
 
 	log.Info("registering webhooks to the webhook server")
-	hookServer.Register("/mutate-pods", &webhook.Admission{Handler: &pod.Mutator{}})
+	hookServer.Register("/mutate-pods", &webhook.Admission{Handler: &pod.Mutator{Reader: mgr.GetAPIReader()}})
 
 	if err = ctrl.NewWebhookManagedBy(mgr).
 		For(&v1alpha2.InferenceService{}).
@@ -162,12 +229,21 @@ func main() {
 		setupLog.Error(err, "unable to create webhook", "webhook", "v1alpha2")
 		os.Exit(1)
 	}
+	// The priority classes referenced by the InferenceServices are read directly to avoid caching them
+	v1beta1.ValidationReader = mgr.GetAPIReader()
 	if err = ctrl.NewWebhookManagedBy(mgr).
 		For(&v1beta1.InferenceService{}).
 		Complete(); err != nil {
 		setupLog.Error(err, "unable to create webhook", "webhook", "v1beta1")
 		os.Exit(1)
 	}
+	// The v1 InferenceServices are defaulted and validated as v1beta1, only their conversion is served
+	if err = ctrl.NewWebhookManagedBy(mgr).
+		For(&servingv1.InferenceService{}).
+		Complete(); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "v1")
+		os.Exit(1)
+	}
 
 	// Start the Cmd
 	log.Info("Starting the Cmd.")
@@ -0,0 +1,109 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/kubeflow/kfserving/pkg/replay"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+var (
+	inputDir           = flag.String("input-dir", "/mnt/models", "Directory of the JSON lines files of the archived log events")
+	record             = flag.Bool("record", false, "Archive the log events sent by the payload logger in the input directory instead of replaying them")
+	port               = flag.Int("port", 8080, "Port the log events are recorded on")
+	output             = flag.String("output", "", "File the results are written to, the standard output by default")
+	targetURL          = flag.String("target-url", "", "Endpoint of the InferenceService the requests are replayed against")
+	inferenceService   = flag.String("inference-service", "", "Name of the InferenceService whose logged requests are replayed, all by default")
+	namespace          = flag.String("namespace", "", "Namespace of the InferenceService whose logged requests are replayed, all by default")
+	rate               = flag.Float64("rate", 10, "Number of requests sent per second, 0 sends them without throttling")
+	workers            = flag.Int("workers", 1, "Number of concurrent requests")
+	tolerance          = flag.Float64("tolerance", 0, "Largest difference of the numbers of a response and its recorded response")
+	timeout            = flag.Duration("timeout", 60*time.Second, "Timeout of a replayed request")
+	terminationMessage = flag.String("termination-message-path", "/dev/termination-log", "File the replay summary is written to")
+)
+
+func main() {
+	flag.Parse()
+
+	logf.SetLogger(logf.ZapLogger(false))
+	log := logf.Log.WithName("entrypoint")
+
+	if *record {
+		if err := os.MkdirAll(*inputDir, 0755); err != nil {
+			log.Error(err, "Failed to create archive directory", "dir", *inputDir)
+			os.Exit(1)
+		}
+		recorder := replay.NewRecorder(*inputDir, log)
+		defer recorder.Close()
+		log.Info("Recording", "outputDir", *inputDir, "port", *port)
+		if err := http.ListenAndServe(":"+strconv.Itoa(*port), recorder); err != nil {
+			log.Error(err, "Failed to record the log events")
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *targetURL == "" {
+		log.Info("target-url argument must not be empty.")
+		os.Exit(-1)
+	}
+
+	var out io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			log.Error(err, "Failed to create output file", "file", *output)
+			os.Exit(1)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	replayer := &replay.Replayer{
+		InputDir:         *inputDir,
+		TargetURL:        *targetURL,
+		InferenceService: *inferenceService,
+		Namespace:        *namespace,
+		Rate:             *rate,
+		Workers:          *workers,
+		Tolerance:        *tolerance,
+		Client:           &http.Client{Timeout: *timeout},
+		Log:              log,
+	}
+	log.Info("Starting", "inputDir", *inputDir, "target", *targetURL, "rate", *rate)
+	summary, err := replayer.Run(out)
+	if err != nil {
+		log.Error(err, "Failed to replay the archive")
+		os.Exit(1)
+	}
+
+	log.Info("Completed", "replayed", summary.Replayed, "matched", summary.Matched, "mismatched", summary.Mismatched,
+		"unrecorded", summary.Unrecorded, "failed", summary.Failed)
+	data, _ := json.Marshal(summary)
+	if err := ioutil.WriteFile(*terminationMessage, data, 0644); err != nil {
+		log.Error(err, "Failed to write termination message")
+	}
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package storage
 
 import (
+	"crypto/sha256"
 	"fmt"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/s3"
@@ -26,6 +27,7 @@ import (
 	"os"
 	"path/filepath"
 	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+	"sort"
 	"strings"
 )
 
@@ -124,3 +126,35 @@ func (s *S3ObjectDownloader) Download(objects []s3manager.BatchDownloadObject) e
 	}
 	return nil
 }
+
+// ModelDigest returns a digest of the model objects under the storage uri, computed over the sorted keys and ETags
+// of the objects so that a changed, added or removed object changes the digest without downloading the model.
+func (m *S3Provider) ModelDigest(storageUri string) (string, error) {
+	tokens := strings.SplitN(strings.TrimPrefix(storageUri, string(S3)), "/", 2)
+	prefix := ""
+	if len(tokens) == 2 {
+		prefix = tokens[1]
+	}
+	entries := []string{}
+	err := m.Client.ListObjectsPages(&s3.ListObjectsInput{
+		Bucket: aws.String(tokens[0]),
+		Prefix: aws.String(prefix),
+	}, func(page *s3.ListObjectsOutput, lastPage bool) bool {
+		for _, object := range page.Contents {
+			entries = append(entries, strings.TrimPrefix(*object.Key, prefix)+" "+aws.StringValue(object.ETag))
+		}
+		return true
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(entries) == 0 {
+		return "", fmt.Errorf("%s has no objects or does not exist", storageUri)
+	}
+	sort.Strings(entries)
+	hash := sha256.New()
+	for _, entry := range entries {
+		fmt.Fprintln(hash, entry)
+	}
+	return fmt.Sprintf("sha256:%x", hash.Sum(nil)), nil
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// InferenceServiceSpec is the top level type for this resource, the component specs are the v1beta1 ones
+type InferenceServiceSpec struct {
+	// Predictor defines the model serving spec
+	// +required
+	Predictor v1beta1.PredictorSpec `json:"predictor"`
+	// Explainer defines the model explanation service spec,
+	// explainer service calls to predictor or transformer if it is specified.
+	// +optional
+	Explainer *v1beta1.ExplainerSpec `json:"explainer,omitempty"`
+	// Transformer defines the pre/post processing before and after the predictor call,
+	// transformer service calls to predictor service.
+	// +optional
+	Transformer *v1beta1.TransformerSpec `json:"transformer,omitempty"`
+	// DriftDetector defines the drift detection service, the payloads of the predictor are logged to it.
+	// +optional
+	DriftDetector *v1beta1.DriftDetectorSpec `json:"driftDetector,omitempty"`
+	// OutlierDetector defines the outlier detection service, the requests of the predictor are scored by it.
+	// +optional
+	OutlierDetector *v1beta1.OutlierDetectorSpec `json:"outlierDetector,omitempty"`
+	// Routing defines the retries and the fallback of the ingress routes
+	// +optional
+	Routing *v1beta1.RoutingSpec `json:"routing,omitempty"`
+	// ImagePullSecrets are the secrets the images of all the components are pulled with, in addition to the
+	// imagePullSecrets of each component.
+	// +optional
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+	// InferenceServiceClassName is the name of the InferenceServiceClass which sets the defaults of the components,
+	// it is the className of v1beta1
+	// +optional
+	InferenceServiceClassName string `json:"inferenceServiceClassName,omitempty"`
+	// TTLSecondsAfterCreation is the lifetime of the InferenceService, it is scaled down and deleted once the TTL has
+	// elapsed since its creation
+	// +optional
+	TTLSecondsAfterCreation *int64 `json:"ttlSecondsAfterCreation,omitempty"`
+	// ExpireAt is the time the InferenceService is scaled down and deleted, the earliest of expireAt and the TTL applies
+	// +optional
+	ExpireAt *metav1.Time `json:"expireAt,omitempty"`
+}
+
+// InferenceService is the Schema for the InferenceServices API
+// +k8s:openapi-gen=true
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:subresource:scale:specpath=.spec.predictor.minReplicas,statuspath=.status.components.predictor.replicas,selectorpath=.status.components.predictor.selector
+// +kubebuilder:printcolumn:name="URL",type="string",JSONPath=".status.url"
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:resource:path=inferenceservices,shortName=isvc
+type InferenceService struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   InferenceServiceSpec   `json:"spec,omitempty"`
+	Status InferenceServiceStatus `json:"status,omitempty"`
+}
+
+// InferenceServiceList contains a list of Service
+// +kubebuilder:object:root=true
+type InferenceServiceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	// +listType=set
+	Items []InferenceService `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&InferenceService{}, &InferenceServiceList{})
+}
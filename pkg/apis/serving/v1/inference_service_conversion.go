@@ -0,0 +1,132 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+// Convert to hub version from v1 to v1beta1
+func (src *InferenceService) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1beta1.InferenceService)
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	spec := src.Spec.DeepCopy()
+	dst.Spec = v1beta1.InferenceServiceSpec{
+		Predictor:               spec.Predictor,
+		Explainer:               spec.Explainer,
+		Transformer:             spec.Transformer,
+		DriftDetector:           spec.DriftDetector,
+		OutlierDetector:         spec.OutlierDetector,
+		Routing:                 spec.Routing,
+		ImagePullSecrets:        spec.ImagePullSecrets,
+		ClassName:               spec.InferenceServiceClassName,
+		TTLSecondsAfterCreation: spec.TTLSecondsAfterCreation,
+		ExpireAt:                spec.ExpireAt,
+	}
+	status := src.Status.DeepCopy()
+	dst.Status = v1beta1.InferenceServiceStatus{
+		Status:             status.Status,
+		Address:            status.Address,
+		URL:                status.URL,
+		Clusters:           status.Clusters,
+		Cost:               status.Cost,
+		ResolvedModel:      status.ResolvedModel,
+		AdmittedGeneration: status.AdmittedGeneration,
+	}
+	renameConditions(dst.Status.Conditions, v1beta1Conditions)
+	if status.Components != nil {
+		dst.Status.Components = make(map[v1beta1.ComponentType]v1beta1.ComponentStatusSpec, len(status.Components))
+		for component, componentStatus := range status.Components {
+			dst.Status.Components[component] = v1beta1.ComponentStatusSpec{
+				LatestReadyRevision:   componentStatus.LatestReadyRevision,
+				PreviousReadyRevision: componentStatus.PreviousReadyRevision,
+				LatestCreatedRevision: componentStatus.LatestCreatedRevision,
+				WarmedUpRevision:      componentStatus.WarmedUpRevision,
+				Rollout:               componentStatus.Rollout,
+				SchemaRevision:        componentStatus.SchemaRevision,
+				CanaryAnalysis:        componentStatus.CanaryAnalysis,
+				PredictiveScaling:     componentStatus.PredictiveScaling,
+				DriftAlert:            componentStatus.DriftAlert,
+				RevisionCollection:    componentStatus.RevisionCollection,
+				Pool:                  componentStatus.Pool,
+				Versions:              componentStatus.Versions,
+				TrafficPercent:        componentStatus.TrafficPercent,
+				URL:                   componentStatus.URL,
+				Address:               componentStatus.Address,
+				Replicas:              componentStatus.Replicas,
+				Selector:              componentStatus.Selector,
+			}
+		}
+	}
+	return nil
+}
+
+// Convert from hub version v1beta1 to v1
+func (dst *InferenceService) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1beta1.InferenceService)
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	spec := src.Spec.DeepCopy()
+	dst.Spec = InferenceServiceSpec{
+		Predictor:                 spec.Predictor,
+		Explainer:                 spec.Explainer,
+		Transformer:               spec.Transformer,
+		DriftDetector:             spec.DriftDetector,
+		OutlierDetector:           spec.OutlierDetector,
+		Routing:                   spec.Routing,
+		ImagePullSecrets:          spec.ImagePullSecrets,
+		InferenceServiceClassName: spec.ClassName,
+		TTLSecondsAfterCreation:   spec.TTLSecondsAfterCreation,
+		ExpireAt:                  spec.ExpireAt,
+	}
+	status := src.Status.DeepCopy()
+	dst.Status = InferenceServiceStatus{
+		Status:             status.Status,
+		Address:            status.Address,
+		URL:                status.URL,
+		Clusters:           status.Clusters,
+		Cost:               status.Cost,
+		ResolvedModel:      status.ResolvedModel,
+		AdmittedGeneration: status.AdmittedGeneration,
+	}
+	renameConditions(dst.Status.Conditions, v1Conditions)
+	if status.Components != nil {
+		dst.Status.Components = make(map[v1beta1.ComponentType]ComponentStatus, len(status.Components))
+		for component, componentStatus := range status.Components {
+			dst.Status.Components[component] = ComponentStatus{
+				LatestReadyRevision:   componentStatus.LatestReadyRevision,
+				PreviousReadyRevision: componentStatus.PreviousReadyRevision,
+				LatestCreatedRevision: componentStatus.LatestCreatedRevision,
+				WarmedUpRevision:      componentStatus.WarmedUpRevision,
+				Rollout:               componentStatus.Rollout,
+				SchemaRevision:        componentStatus.SchemaRevision,
+				CanaryAnalysis:        componentStatus.CanaryAnalysis,
+				PredictiveScaling:     componentStatus.PredictiveScaling,
+				DriftAlert:            componentStatus.DriftAlert,
+				RevisionCollection:    componentStatus.RevisionCollection,
+				Pool:                  componentStatus.Pool,
+				Versions:              componentStatus.Versions,
+				TrafficPercent:        componentStatus.TrafficPercent,
+				URL:                   componentStatus.URL,
+				Address:               componentStatus.Address,
+				Replicas:              componentStatus.Replicas,
+				Selector:              componentStatus.Selector,
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+func TestInferenceServiceConversion(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	hub := &v1beta1.InferenceService{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: "default",
+			Labels:    map[string]string{"team": "fraud"},
+		},
+		Spec: v1beta1.InferenceServiceSpec{
+			Predictor: v1beta1.PredictorSpec{
+				ComponentExtensionSpec: v1beta1.ComponentExtensionSpec{
+					MinReplicas: v1beta1.GetIntReference(2),
+				},
+				SKLearn: &v1beta1.SKLearnSpec{
+					PredictorExtensionSpec: v1beta1.PredictorExtensionSpec{
+						StorageURI: proto.String("gs://kfserving-samples/models/sklearn/iris"),
+					},
+				},
+			},
+			Transformer: &v1beta1.TransformerSpec{
+				PodSpec: v1beta1.PodSpec{
+					Containers: []corev1.Container{{Image: "transformer:v1"}},
+				},
+			},
+			ImagePullSecrets:        []corev1.LocalObjectReference{{Name: "registry"}},
+			ClassName:               "gpu",
+			TTLSecondsAfterCreation: proto.Int64(3600),
+		},
+		Status: v1beta1.InferenceServiceStatus{
+			Status: duckv1.Status{
+				Conditions: duckv1.Conditions{
+					{Type: v1beta1.PredictorReady, Status: corev1.ConditionTrue},
+					{Type: v1beta1.ExplainerRoutesReady, Status: corev1.ConditionTrue},
+					{Type: v1beta1.TransformerConfigurationeReady, Status: corev1.ConditionFalse},
+				},
+			},
+			Components: map[v1beta1.ComponentType]v1beta1.ComponentStatusSpec{
+				v1beta1.PredictorComponent: {
+					LatestReadyRevision: "foo-predictor-default-00002",
+					Rollout:             &v1beta1.RolloutStatus{ServingRevision: "foo-predictor-default-00001"},
+					TrafficPercent:      proto.Int64(100),
+					Replicas:            2,
+				},
+			},
+			AdmittedGeneration: 3,
+		},
+	}
+
+	isvc := &InferenceService{}
+	g.Expect(isvc.ConvertFrom(hub)).To(gomega.Succeed())
+	g.Expect(isvc.ObjectMeta).To(gomega.Equal(hub.ObjectMeta))
+	g.Expect(isvc.Spec.Predictor).To(gomega.Equal(hub.Spec.Predictor))
+	g.Expect(isvc.Spec.Transformer).To(gomega.Equal(hub.Spec.Transformer))
+	g.Expect(isvc.Spec.ImagePullSecrets).To(gomega.Equal(hub.Spec.ImagePullSecrets))
+	g.Expect(isvc.Spec.InferenceServiceClassName).To(gomega.Equal("gpu"))
+	g.Expect(isvc.Spec.TTLSecondsAfterCreation).To(gomega.Equal(proto.Int64(3600)))
+	g.Expect(isvc.Status.AdmittedGeneration).To(gomega.Equal(int64(3)))
+	g.Expect(isvc.Status.Components).To(gomega.Equal(map[v1beta1.ComponentType]ComponentStatus{
+		v1beta1.PredictorComponent: {
+			LatestReadyRevision: "foo-predictor-default-00002",
+			Rollout:             &v1beta1.RolloutStatus{ServingRevision: "foo-predictor-default-00001"},
+			TrafficPercent:      proto.Int64(100),
+			Replicas:            2,
+		},
+	}))
+	// the conversion copies the hub
+	isvc.Status.Components[v1beta1.PredictorComponent].Rollout.ServingRevision = "foo-predictor-default-00002"
+	g.Expect(hub.Status.Components[v1beta1.PredictorComponent].Rollout.ServingRevision).To(
+		gomega.Equal("foo-predictor-default-00001"))
+	isvc.Status.Components[v1beta1.PredictorComponent].Rollout.ServingRevision = "foo-predictor-default-00001"
+	var types []apis.ConditionType
+	for _, condition := range isvc.Status.Conditions {
+		types = append(types, condition.Type)
+	}
+	g.Expect(types).To(gomega.Equal([]apis.ConditionType{
+		v1beta1.PredictorReady, ExplainerRouteReady, TransformerConfigurationReady}))
+	// the hub keeps its condition names
+	g.Expect(hub.Status.Conditions[2].Type).To(gomega.Equal(v1beta1.TransformerConfigurationeReady))
+
+	roundTrip := &v1beta1.InferenceService{}
+	g.Expect(isvc.ConvertTo(roundTrip)).To(gomega.Succeed())
+	g.Expect(roundTrip).To(gomega.Equal(hub))
+}
+
+func TestInferenceServiceConversionTo(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := &InferenceService{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: InferenceServiceSpec{
+			Predictor: v1beta1.PredictorSpec{
+				SKLearn: &v1beta1.SKLearnSpec{
+					PredictorExtensionSpec: v1beta1.PredictorExtensionSpec{
+						StorageURI: proto.String("gs://kfserving-samples/models/sklearn/iris"),
+					},
+				},
+			},
+			InferenceServiceClassName: "gpu",
+		},
+		Status: InferenceServiceStatus{
+			Status: duckv1.Status{
+				Conditions: duckv1.Conditions{
+					{Type: ExplainerRouteReady, Status: corev1.ConditionTrue},
+					{Type: TransformerConfigurationReady, Status: corev1.ConditionTrue},
+				},
+			},
+		},
+	}
+
+	hub := &v1beta1.InferenceService{}
+	g.Expect(isvc.ConvertTo(hub)).To(gomega.Succeed())
+	g.Expect(hub.Spec.ClassName).To(gomega.Equal("gpu"))
+	g.Expect(hub.Spec.Predictor).To(gomega.Equal(isvc.Spec.Predictor))
+	g.Expect(hub.Status.Components).To(gomega.BeNil())
+	g.Expect(hub.Status.Conditions[0].Type).To(gomega.Equal(v1beta1.ExplainerRoutesReady))
+	g.Expect(hub.Status.Conditions[1].Type).To(gomega.Equal(v1beta1.TransformerConfigurationeReady))
+	// the v1 InferenceService keeps its condition names
+	g.Expect(isvc.Status.Conditions[1].Type).To(gomega.Equal(TransformerConfigurationReady))
+
+	roundTrip := &InferenceService{}
+	g.Expect(roundTrip.ConvertFrom(hub)).To(gomega.Succeed())
+	g.Expect(roundTrip).To(gomega.Equal(isvc))
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+// InferenceServiceStatus defines the observed state of InferenceService
+type InferenceServiceStatus struct {
+	// Conditions for the InferenceService, named after the v1 condition types
+	duckv1.Status `json:",inline"`
+	// Addressable endpoint for the InferenceService
+	// +optional
+	Address *duckv1.Addressable `json:"address,omitempty"`
+	// URL holds the url that will distribute traffic over the provided traffic targets.
+	// It generally has the form http[s]://{route-name}.{route-namespace}.{cluster-level-suffix}
+	// +optional
+	URL *apis.URL `json:"url,omitempty"`
+	// Statuses for the components of the InferenceService
+	Components map[v1beta1.ComponentType]ComponentStatus `json:"components,omitempty"`
+	// Statuses of a multi-cluster InferenceService in its member clusters
+	// +optional
+	Clusters map[string]v1beta1.ClusterStatus `json:"clusters,omitempty"`
+	// Approximate cost of the InferenceService, set when the cost estimation is configured
+	// +optional
+	Cost *v1beta1.CostStatus `json:"cost,omitempty"`
+	// Model version the model registry reference of the predictor was resolved to
+	// +optional
+	ResolvedModel *v1beta1.ResolvedModelStatus `json:"resolvedModel,omitempty"`
+	// Generation of the spec last admitted by the InferenceQuotas of the namespace
+	// +optional
+	AdmittedGeneration int64 `json:"admittedGeneration,omitempty"`
+}
+
+// ComponentStatus describes the state of the component, it is the ComponentStatusSpec of v1beta1
+type ComponentStatus struct {
+	// Latest revision name that is in ready state
+	// +optional
+	LatestReadyRevision string `json:"latestReadyRevision,omitempty"`
+	// Previous revision name that is in ready state
+	// +optional
+	PreviousReadyRevision string `json:"previousReadyRevision,omitempty"`
+	// Latest revision name that is in created
+	// +optional
+	LatestCreatedRevision string `json:"latestCreatedRevision,omitempty"`
+	// Latest revision name that answered the warm-up requests, the traffic is not shifted to the revisions which
+	// are not warmed up
+	// +optional
+	WarmedUpRevision string `json:"warmedUpRevision,omitempty"`
+	// Progress of the revision switch of a component with a rollout
+	// +optional
+	Rollout *v1beta1.RolloutStatus `json:"rollout,omitempty"`
+	// Latest ready revision the model metadata validating the inference requests was fetched from
+	// +optional
+	SchemaRevision string `json:"schemaRevision,omitempty"`
+	// Progress of the canary analysis of the latest ready revision
+	// +optional
+	CanaryAnalysis *v1beta1.CanaryAnalysisStatus `json:"canaryAnalysis,omitempty"`
+	// Last request rate forecast of a component with predictive scaling
+	// +optional
+	PredictiveScaling *v1beta1.PredictiveScalingStatus `json:"predictiveScaling,omitempty"`
+	// Last evaluation of the alert of the drift detector
+	// +optional
+	DriftAlert *v1beta1.DriftAlertStatus `json:"driftAlert,omitempty"`
+	// Last garbage collection of the revisions of a component with a revision retention
+	// +optional
+	RevisionCollection *v1beta1.RevisionCollectionStatus `json:"revisionCollection,omitempty"`
+	// Traffic split with the replica pool of the predictor
+	// +optional
+	Pool *v1beta1.PoolStatus `json:"pool,omitempty"`
+	// Pinned model versions of the predictor
+	// +optional
+	Versions []v1beta1.ModelVersionStatus `json:"versions,omitempty"`
+	// Traffic percent on the latest ready revision
+	// +optional
+	TrafficPercent *int64 `json:"trafficPercent,omitempty"`
+	// URL holds the url that will distribute traffic over the provided traffic targets.
+	// It generally has the form http[s]://{route-name}.{route-namespace}.{cluster-level-suffix}
+	// +optional
+	URL *apis.URL `json:"url,omitempty"`
+	// Addressable endpoint for the InferenceService
+	// +optional
+	Address *duckv1.Addressable `json:"address,omitempty"`
+	// Total number of pods across the revisions of the component, reported for the scale subresource
+	// +optional
+	Replicas int32 `json:"replicas,omitempty"`
+	// Label selector of the component pods in serialized form, reported for the scale subresource
+	// +optional
+	Selector string `json:"selector,omitempty"`
+}
+
+// ConditionType represents a Service condition value, the route and configuration conditions of all the components
+// are named <Component>RouteReady and <Component>ConfigurationReady
+const (
+	// PredictorRouteReady is set when network configuration has completed.
+	PredictorRouteReady apis.ConditionType = "PredictorRouteReady"
+	// TransformerRouteReady is set when network configuration has completed.
+	TransformerRouteReady apis.ConditionType = "TransformerRouteReady"
+	// ExplainerRouteReady is set when network configuration has completed.
+	ExplainerRouteReady apis.ConditionType = "ExplainerRouteReady"
+	// PredictorConfigurationReady is set when predictor pods are ready.
+	PredictorConfigurationReady apis.ConditionType = "PredictorConfigurationReady"
+	// TransformerConfigurationReady is set when transformer pods are ready.
+	TransformerConfigurationReady apis.ConditionType = "TransformerConfigurationReady"
+	// ExplainerConfigurationReady is set when explainer pods are ready.
+	ExplainerConfigurationReady apis.ConditionType = "ExplainerConfigurationReady"
+)
+
+// v1beta1Conditions maps the v1 condition types renamed from v1beta1 to their v1beta1 names
+var v1beta1Conditions = map[apis.ConditionType]apis.ConditionType{
+	ExplainerRouteReady:           v1beta1.ExplainerRoutesReady,
+	TransformerConfigurationReady: v1beta1.TransformerConfigurationeReady,
+}
+
+// v1Conditions maps the v1beta1 condition types renamed in v1 to their v1 names
+var v1Conditions = map[apis.ConditionType]apis.ConditionType{
+	v1beta1.ExplainerRoutesReady:           ExplainerRouteReady,
+	v1beta1.TransformerConfigurationeReady: TransformerConfigurationReady,
+}
+
+// renameConditions renames the condition types, the other conditions keep their names
+func renameConditions(conditions duckv1.Conditions, names map[apis.ConditionType]apis.ConditionType) {
+	for i := range conditions {
+		if name, ok := names[conditions[i].Type]; ok {
+			conditions[i].Type = name
+		}
+	}
+}
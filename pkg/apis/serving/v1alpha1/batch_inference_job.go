@@ -0,0 +1,77 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// BatchInferenceJob is the Schema for the BatchInferenceJob API
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="InferenceService",type="string",JSONPath=".spec.inferenceService"
+// +kubebuilder:printcolumn:name="Succeeded",type="string",JSONPath=".status.conditions[?(@.type=='Succeeded')].status"
+// +kubebuilder:printcolumn:name="Processed",type="integer",JSONPath=".status.processedRecords"
+// +kubebuilder:printcolumn:name="Failed",type="integer",JSONPath=".status.failedRecords"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:resource:path=batchinferencejobs,shortName=bij,singular=batchinferencejob
+type BatchInferenceJob struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              BatchInferenceJobSpec   `json:"spec,omitempty"`
+	Status            BatchInferenceJobStatus `json:"status,omitempty"`
+}
+
+// BatchInferenceJobList contains a list of BatchInferenceJob
+// +kubebuilder:object:root=true
+type BatchInferenceJobList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	// +listType=set
+	Items []BatchInferenceJob `json:"items"`
+}
+
+// BatchInferenceJobSpec defines the batch inference job spec
+type BatchInferenceJobSpec struct {
+	// InferenceService in the same namespace whose predict endpoint the records are sent to
+	// +required
+	InferenceService string `json:"inferenceService"`
+	// Storage URI of the input dataset, a JSON lines file or a directory of JSON lines files.
+	// Each line is sent as a single instance of a v1 predict request.
+	// +required
+	InputURI string `json:"inputUri"`
+	// Storage URI the results are written to, one JSON lines file per shard.
+	// The values could be: "s3://<bucket>/<path>", "pvc://<pvcname>/<path>"
+	// +required
+	OutputURI string `json:"outputUri"`
+	// Number of shards the records are split into, each shard is processed by its own job.
+	// Defaults to 1.
+	// +optional
+	Parallelism *int32 `json:"parallelism,omitempty"`
+	// Number of concurrent requests sent by each shard. Defaults to 1.
+	// +optional
+	Workers *int32 `json:"workers,omitempty"`
+	// Number of times a failed request is retried before the record is counted as failed. Defaults to 3.
+	// +optional
+	Retries *int32 `json:"retries,omitempty"`
+	// Number of times a shard is restarted after a fatal error before the job fails. Defaults to 3.
+	// +optional
+	BackoffLimit *int32 `json:"backoffLimit,omitempty"`
+	// Service account of the job pods, its secrets provide the credentials to read the input and write the output.
+	// +optional
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+}
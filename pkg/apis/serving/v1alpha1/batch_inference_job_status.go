@@ -0,0 +1,118 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+// BatchInferenceJobStatus defines the observed state of BatchInferenceJob
+type BatchInferenceJobStatus struct {
+	// Conditions for the batch inference job
+	// - InferenceServiceReady: the InferenceService was ready when the shards were started;
+	// - ShardsCompleted: all the shards have completed;
+	// - Succeeded: aggregated condition;
+	duckv1.Status `json:",inline"`
+	// Predict endpoint of the InferenceService the records are sent to
+	// +optional
+	PredictorURL string `json:"predictorUrl,omitempty"`
+	// Time the shards were started
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// Time all the shards completed
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// Number of shards running
+	// +optional
+	ActiveShards int32 `json:"activeShards,omitempty"`
+	// Number of shards which processed all their records
+	// +optional
+	SucceededShards int32 `json:"succeededShards,omitempty"`
+	// Number of shards which failed after exhausting the backoff limit
+	// +optional
+	FailedShards int32 `json:"failedShards,omitempty"`
+	// Number of records processed by the succeeded shards, including the failed records
+	// +optional
+	ProcessedRecords int64 `json:"processedRecords,omitempty"`
+	// Number of records whose predict request failed after all the retries
+	// +optional
+	FailedRecords int64 `json:"failedRecords,omitempty"`
+}
+
+// ConditionType represents a BatchInferenceJob condition value
+const (
+	// InferenceServiceReady is set when the InferenceService is ready to receive the records
+	InferenceServiceReady apis.ConditionType = "InferenceServiceReady"
+	// ShardsCompleted is set when all the shards have completed
+	ShardsCompleted apis.ConditionType = "ShardsCompleted"
+)
+
+// BatchInferenceJob Succeeded condition is depending on the InferenceService and the shards
+var batchConditionSet = apis.NewBatchConditionSet(
+	InferenceServiceReady,
+	ShardsCompleted,
+)
+
+var _ apis.ConditionsAccessor = (*BatchInferenceJobStatus)(nil)
+
+// InitializeConditions sets the initial values to the conditions.
+func (ss *BatchInferenceJobStatus) InitializeConditions() {
+	batchConditionSet.Manage(ss).InitializeConditions()
+}
+
+// GetCondition returns the condition by name.
+func (ss *BatchInferenceJobStatus) GetCondition(t apis.ConditionType) *apis.Condition {
+	return batchConditionSet.Manage(ss).GetCondition(t)
+}
+
+// IsDone returns true once the job has either succeeded or failed
+func (ss *BatchInferenceJobStatus) IsDone() bool {
+	condition := ss.GetCondition(apis.ConditionSucceeded)
+	return condition != nil && !condition.IsUnknown()
+}
+
+// MarkInferenceServiceReady records that the InferenceService is ready to receive the records
+func (ss *BatchInferenceJobStatus) MarkInferenceServiceReady() {
+	batchConditionSet.Manage(ss).MarkTrue(InferenceServiceReady)
+}
+
+// MarkInferenceServiceNotReady records that the shards are waiting for the InferenceService
+func (ss *BatchInferenceJobStatus) MarkInferenceServiceNotReady(reason, message string) {
+	batchConditionSet.Manage(ss).MarkUnknown(InferenceServiceReady, reason, message)
+}
+
+// MarkShardsRunning records that some of the shards are still running
+func (ss *BatchInferenceJobStatus) MarkShardsRunning(message string) {
+	batchConditionSet.Manage(ss).MarkUnknown(ShardsCompleted, "Running", message)
+}
+
+// MarkShardsSucceeded records that all the shards processed their records
+func (ss *BatchInferenceJobStatus) MarkShardsSucceeded() {
+	batchConditionSet.Manage(ss).MarkTrue(ShardsCompleted)
+}
+
+// MarkShardsFailed records that some of the shards failed
+func (ss *BatchInferenceJobStatus) MarkShardsFailed(message string) {
+	batchConditionSet.Manage(ss).MarkFalse(ShardsCompleted, "ShardFailed", message)
+}
+
+// MarkInvalidSpec fails the job without starting the shards
+func (ss *BatchInferenceJobStatus) MarkInvalidSpec(message string) {
+	batchConditionSet.Manage(ss).MarkFalse(ShardsCompleted, "InvalidSpec", message)
+}
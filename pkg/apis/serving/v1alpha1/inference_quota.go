@@ -0,0 +1,83 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// InferenceQuota is the Schema for the InferenceQuota API. It limits the InferenceServices of its namespace, the
+// InferenceServices which would exceed one of the limits are kept pending until the usage allows them.
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="InferenceServices",type="integer",JSONPath=".status.used.inferenceServices"
+// +kubebuilder:printcolumn:name="GPUs",type="integer",JSONPath=".status.used.gpus"
+// +kubebuilder:printcolumn:name="Replicas",type="integer",JSONPath=".status.used.replicas"
+// +kubebuilder:printcolumn:name="Pending",type="string",JSONPath=".status.pending"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:resource:path=inferencequotas,shortName=iquota,singular=inferencequota
+type InferenceQuota struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   InferenceQuotaSpec   `json:"spec,omitempty"`
+	Status InferenceQuotaStatus `json:"status,omitempty"`
+}
+
+// InferenceQuotaList contains a list of InferenceQuota
+// +kubebuilder:object:root=true
+type InferenceQuotaList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	// +listType=set
+	Items []InferenceQuota `json:"items"`
+}
+
+// InferenceQuotaSpec defines the limits of the InferenceServices of the namespace, a limit which is not set is not
+// enforced
+type InferenceQuotaSpec struct {
+	// Maximum number of InferenceServices
+	// +optional
+	MaxInferenceServices *int32 `json:"maxInferenceServices,omitempty"`
+	// Maximum number of GPUs requested by the component replicas
+	// +optional
+	MaxGPUs *int64 `json:"maxGPUs,omitempty"`
+	// Maximum number of component replicas. A component counts its maximum replicas, or its minimum replicas when
+	// it has no maximum.
+	// +optional
+	MaxReplicas *int32 `json:"maxReplicas,omitempty"`
+}
+
+// QuotaUsage is the capacity used by InferenceServices
+type QuotaUsage struct {
+	// Number of InferenceServices
+	InferenceServices int32 `json:"inferenceServices"`
+	// Number of GPUs requested by the component replicas
+	GPUs int64 `json:"gpus"`
+	// Number of component replicas
+	Replicas int32 `json:"replicas"`
+}
+
+// InferenceQuotaStatus defines the observed state of InferenceQuota
+type InferenceQuotaStatus struct {
+	// Capacity used by the admitted InferenceServices of the namespace
+	// +optional
+	Used QuotaUsage `json:"used,omitempty"`
+	// InferenceServices waiting for capacity, in the order they are admitted
+	// +optional
+	Pending []string `json:"pending,omitempty"`
+}
@@ -50,5 +50,6 @@ func Resource(resource string) schema.GroupResource {
 }
 
 func init() {
-	SchemeBuilder.Register(&TrainedModel{}, &TrainedModelList{})
+	SchemeBuilder.Register(&TrainedModel{}, &TrainedModelList{}, &BatchInferenceJob{}, &BatchInferenceJobList{},
+		&InferenceQuota{}, &InferenceQuotaList{})
 }
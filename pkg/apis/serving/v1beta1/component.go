@@ -20,9 +20,11 @@ import (
 	"fmt"
 	"reflect"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/kubeflow/kfserving/pkg/schedule"
 	"github.com/kubeflow/kfserving/pkg/utils"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -36,11 +38,72 @@ const (
 	ParallelismLowerBoundExceededError  = "Parallelism cannot be less than 0."
 	UnsupportedStorageURIFormatError    = "storageUri, must be one of: [%s] or match https://{}.blob.core.windows.net/{}/{} or be an absolute or relative local path. StorageUri [%s] is not supported."
 	InvalidLoggerType                   = "Invalid logger type"
+	LoggerRetriesLowerBoundError        = "Logger retries cannot be less than 0."
 	InvalidISVCNameFormatError          = "The InferenceService \"%s\" is invalid: a InferenceService name must consist of lower case alphanumeric characters or '-', and must start with alphabetical character. (e.g. \"my-name\" or \"abc-123\", regex used for validation is '%s')"
+	InvalidDeploymentModeError          = "Deployment mode %q is not supported, must be one of: [%s]."
+	ModelMeshComponentsError            = "ModelMesh deployment mode only supports a predictor, transformer, explainer and detectors are not allowed."
+	ModelMeshPredictorError             = "ModelMesh deployment mode requires a sklearn, xgboost, tensorflow, pytorch, onnx or triton predictor with an s3:// storageUri."
+	DeploymentModeImmutableError        = "Deployment mode can not be changed from %q to %q, delete and recreate the InferenceService instead."
+	InvalidScalingScheduleError         = "Invalid scaling schedule: %v"
+	ScheduledMinReplicasError           = "Scaling schedule %q minReplicas must be between 0 and MaxReplicas."
+	InvalidMIGResourceError             = "MIG resource %s is invalid, must be nvidia.com/mig-<profile> with a profile such as 1g.5gb or 3g.20gb."
+	MultipleGPUResourcesError           = "A container can only request one GPU resource type but requests [%s]."
+	GPUResourceRequestLimitError        = "GPU resource %s requests must be equal to limits."
+	InvalidGPUSharingError              = "GPU sharing %q is not supported, must be one of: [%s]."
+	PriorityClassNotFoundError          = "PriorityClass %q of the %s does not exist."
+	RuntimeClassNotFoundError           = "RuntimeClass %q of the %s does not exist."
+	InferenceServiceClassNotFoundError  = "InferenceServiceClass %q does not exist."
+	InvalidPlacementPolicyError         = "Placement policy %q is not supported, must be one of: [%s]."
+	WarmUpPayloadError                  = "Warm-up must set exactly one of configMapKeyRef or uri."
+	WarmUpRequestsLowerBoundError       = "Warm-up requests cannot be less than 0."
+	RolloutLowerBoundError              = "Rollout soak and drain seconds cannot be less than 0."
+	RevisionRetentionLowerBoundError    = "Revision retention keepReadyRevisions cannot be less than 0."
+	PredictiveScalingTargetError        = "Predictive scaling targetRequestsPerSecond must be greater than 0."
+	PredictiveScalingWindowError        = "Predictive scaling lookahead and periods cannot be less than 0, the lookahead must be shorter than the period."
+	CanaryAnalysisStepsError            = "Canary analysis steps must be increasing traffic percents between 1 and 99."
+	CanaryAnalysisMetricError           = "Canary analysis metrics must have a name and a query."
+	CanaryAnalysisLowerBoundError       = "Canary analysis interval and failure threshold cannot be less than 0."
+	DetectorAlertQueryError             = "Detector alert must have a query."
+	InvalidOutlierDetectionModeError    = "Outlier detection mode %q is not supported, must be one of: [%s]."
+	FeastTransformerError               = "Feast transformer must set featureServerUrl, featureService and at least one entity."
+	FeastEntityError                    = "Feast transformer entities must have a name and a field."
+	DuplicateFeastEntityError           = "Feast entity %q is mapped more than once."
+	ModelRefStorageURIError             = "Predictor can not set both storageUri and modelRef."
+	ModelRefError                       = "Model registry reference must have a registry and a model."
+	RetryPolicyLowerBoundError          = "Retry attempts and per try timeout cannot be less than 0."
+	InvalidFallbackError                = "Fallback InferenceService %q must be a valid name of another InferenceService."
+	SessionAffinityError                = "Session affinity must set exactly one of header or cookie, the cookie must have a name and ttlSeconds cannot be less than 0."
+	ReplicaPoolTransformerError         = "Replica pool can not be used with a transformer, the transformer calls the predictor replicas directly."
+	ReplicaPoolWeightError              = "Replica pool weight must be between 0 and 100."
+	ReplicaPoolCapacityError            = "Replica pool with Overflow routing must set capacityRequestsPerSecond greater than 0."
+	InvalidReplicaPoolRoutingError      = "Replica pool routing %q is not supported, must be one of: [%s]."
+	EnsemblePredictorError              = "Ensemble requires a sklearn, xgboost or triton predictor without storageUri or modelRef."
+	EnsembleModelsError                 = "Ensemble must have at least two models."
+	InvalidEnsembleModelNameError       = "Ensemble model name %q is invalid, it must be a unique DNS label different from the InferenceService name."
+	EnsembleModelStorageURIError        = "Ensemble model %q must have a storageUri."
+	EnsembleWeightError                 = "Ensemble model weights cannot be less than 0 and at least one must be greater than 0."
+	InvalidEnsembleStrategyError        = "Ensemble strategy %q is not supported, must be one of: [%s]."
+	ModelVersionRoutingError            = "Model versions can not be used with a transformer or a replica pool, the ingress routes the versions to the predictor replicas."
+	ModelVersionPredictorError          = "Model versions require a predictor with a storageUri."
+	InvalidModelVersionNameError        = "Model version name %q is invalid, it must be a unique DNS label."
+	ModelVersionStorageURIError         = "Model version %q must have a storageUri."
+	DefaultModelVersionError            = "Default version %q is not one of the model versions of the predictor."
+	EnvValueFromError                   = "Environment variable %q can not set both value and valueFrom."
+	EnvValueSourceError                 = "Environment variable %q valueFrom must set exactly one of configMapKeyRef or secretKeyRef."
+	EnvKeyRefError                      = "Environment variable %q must reference a key of a named configmap or secret."
+	EnvFromSourceError                  = "EnvFrom must set exactly one of configMapRef or secretRef with a name."
+	ExpireAtPastError                   = "ExpireAt %s is in the past."
+	PreStopSleepLowerBoundError         = "PreStopSleepSeconds cannot be less than 0."
+	StartupLowerBoundError              = "Startup modelSize and timeoutSeconds cannot be less than 0."
+	TTLLowerBoundError                  = "TTLSecondsAfterCreation cannot be less than 0."
+	TopologySpreadConstraintError       = "Topology spread constraints must have a topologyKey, a maxSkew of at least 1 and whenUnsatisfiable DoNotSchedule or ScheduleAnyway."
 )
 
 // Constants
 var (
+	// MIGResourceRegEx matches the MIG slices advertised by the device plugin, with optional compute instance and media
+	// extensions profiles, e.g. nvidia.com/mig-1g.5gb, nvidia.com/mig-1c.3g.20gb or nvidia.com/mig-1g.10gb+me
+	MIGResourceRegEx              = regexp.MustCompile(`^nvidia\.com/mig-([0-9]+c\.)?[0-9]+g\.[0-9]+gb(\+me)?(\.shared)?$`)
 	SupportedStorageURIPrefixList = []string{"gs://", "s3://", "pvc://", "file://", "https://", "http://"}
 	AzureBlobURL                  = "blob.core.windows.net"
 	AzureBlobURIRegEx             = "https://(.+?).blob.core.windows.net/(.+)"
@@ -81,14 +144,175 @@ type ComponentExtensionSpec struct {
 	// CanaryTrafficPercent defines the traffic split percentage between the candidate revision and the last ready revision
 	// +optional
 	CanaryTrafficPercent *int64 `json:"canaryTrafficPercent,omitempty"`
+	// CanaryAnalysis promotes the candidate revision through traffic steps while its metrics stay within their thresholds
+	// and rolls it back when they don't, it overrides CanaryTrafficPercent.
+	// +optional
+	CanaryAnalysis *CanaryAnalysis `json:"canaryAnalysis,omitempty"`
 	// Activate request/response logging and logger configurations
 	// +optional
 	Logger *LoggerSpec `json:"logger,omitempty"`
 	// Activate request batching and batching configurations
 	// +optional
 	Batcher *Batcher `json:"batcher,omitempty"`
+	// Scaling schedules overriding MinReplicas, the schedule which fired last sets the minimum number of replicas.
+	// MinReplicas applies until one of the schedules fires.
+	// +optional
+	ScalingSchedules []ScalingSchedule `json:"scalingSchedules,omitempty"`
+	// Predictive scaling raises the minimum number of replicas ahead of the recurring traffic ramps forecast from the
+	// request history of the component, it never lowers the minimum set by MinReplicas or the scaling schedules.
+	// +optional
+	PredictiveScaling *PredictiveScalingSpec `json:"predictiveScaling,omitempty"`
+	// Placement policy of the component pods, PreferSpot schedules them on the spot node pools configured in the
+	// inferenceservice configmap with a fallback to on-demand nodes. Defaults to OnDemand.
+	// +optional
+	PlacementPolicy PlacementPolicy `json:"placementPolicy,omitempty"`
+	// Warm-up requests sent to a new revision before the traffic is shifted to it, the traffic stays on the last
+	// warmed up revision until the new revision answers the requests successfully.
+	// +optional
+	WarmUp *WarmUpSpec `json:"warmUp,omitempty"`
+	// Rollout delays the traffic shift to a new revision until it soaked and keeps the previous revision routable
+	// while it drains, so that the revision switch does not fail the requests in flight.
+	// +optional
+	Rollout *RolloutSpec `json:"rollout,omitempty"`
+	// Revision retention garbage collects the old revisions of the component, the stale tagged routes of its knative
+	// service and the configurations and routes its knative service no longer owns
+	// +optional
+	RevisionRetention *RevisionRetentionSpec `json:"revisionRetention,omitempty"`
+	// Seconds the containers of a terminating pod keep serving before they are sent SIGTERM, while the pod is removed
+	// from the endpoints of the activator and the gateways. The terminationGracePeriodSeconds of the pod must cover
+	// the sleep and the requests in flight. The logger, batcher and agent sidecars are not delayed.
+	// +optional
+	PreStopSleepSeconds *int64 `json:"preStopSleepSeconds,omitempty"`
+}
+
+// WarmUpSpec defines the sample request posted to a new revision of the component to load the model before it
+// receives traffic
+type WarmUpSpec struct {
+	// Key of a configmap in the InferenceService namespace holding the sample request payload
+	// +optional
+	ConfigMapKeyRef *v1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+	// http(s) URI of the sample request payload
+	// +optional
+	URI string `json:"uri,omitempty"`
+	// Path the payload is posted to, defaults to the predict path of the component, or the explain path of the explainer
+	// +optional
+	Path string `json:"path,omitempty"`
+	// Number of successful responses required before the traffic is shifted, defaults to 1
+	// +optional
+	Requests int `json:"requests,omitempty"`
+}
+
+// RolloutSpec defines how the traffic of the component is switched from a revision to the next one
+type RolloutSpec struct {
+	// Seconds a new revision must stay ready, and warmed up when the component has warm-up requests, before the
+	// traffic is shifted to it
+	// +optional
+	SoakSeconds int64 `json:"soakSeconds,omitempty"`
+	// Seconds the previous revision stays routable by its drain tag after the traffic was shifted away from it, so that
+	// it is not garbage collected while the requests routed to it before the switch complete. The revision receives no
+	// new traffic and keeps the min replicas it was created with, a revision of a component scaling to zero scales
+	// down with the autoscaler once it is idle, before the drain period ends.
+	// +optional
+	DrainSeconds int64 `json:"drainSeconds,omitempty"`
 }
 
+// DefaultKeepReadyRevisions is the number of the latest ready revisions kept by the revision retention
+const DefaultKeepReadyRevisions = 3
+
+// RevisionRetentionSpec defines which revisions of the component are kept, the revisions routed by the knative service
+// or referenced by the component status are always kept
+type RevisionRetentionSpec struct {
+	// Number of the latest ready revisions kept, defaults to 3. The revisions which fail to become ready are deleted once
+	// a later revision is ready.
+	// +optional
+	KeepReadyRevisions *int `json:"keepReadyRevisions,omitempty"`
+}
+
+// GetKeepReadyRevisions returns the number of the latest ready revisions kept, 3 by default
+func (s *RevisionRetentionSpec) GetKeepReadyRevisions() int {
+	if s.KeepReadyRevisions == nil {
+		return DefaultKeepReadyRevisions
+	}
+	return *s.KeepReadyRevisions
+}
+
+// ScalingSchedule sets the minimum number of replicas of the component from the time its cron schedule fires until
+// another schedule of the component fires, e.g. "0 8 * * 1-5" with 3 replicas and "0 18 * * 1-5" with 1 replica.
+type ScalingSchedule struct {
+	// Cron expression in the standard five field format, evaluated in UTC
+	Schedule string `json:"schedule"`
+	// Minimum number of replicas from the time the schedule fires
+	MinReplicas int `json:"minReplicas"`
+}
+
+// PredictiveScalingSpec forecasts the request rate of the component from the same time of the previous periods, e.g.
+// the previous days, and scales the component to serve the peak expected within the lookahead
+type PredictiveScalingSpec struct {
+	// Request rate a replica serves, the forecast rate divided by it sets the minimum number of replicas
+	TargetRequestsPerSecond float64 `json:"targetRequestsPerSecond"`
+	// PromQL query returning the request rate of the component. The query is a template of the Namespace, the Name of
+	// the InferenceService, the Component and the knative Service. Defaults to the rate of the queue proxy request count.
+	// +optional
+	Query string `json:"query,omitempty"`
+	// Seconds ahead of the traffic peak the replicas are scaled up, defaults to 900
+	// +optional
+	LookaheadSeconds int64 `json:"lookaheadSeconds,omitempty"`
+	// Seconds between the recurring traffic patterns, defaults to 86400 for daily patterns
+	// +optional
+	PeriodSeconds int64 `json:"periodSeconds,omitempty"`
+	// Number of previous periods the forecast averages, defaults to 7
+	// +optional
+	Periods int `json:"periods,omitempty"`
+}
+
+// PredictiveScalingSpec defaults
+const (
+	DefaultPredictiveLookaheadSeconds = 900
+	DefaultPredictivePeriodSeconds    = 86400
+	DefaultPredictivePeriods          = 7
+)
+
+// CanaryAnalysis defines the traffic steps of a progressive rollout and the metrics gating the promotion between them
+type CanaryAnalysis struct {
+	// Traffic percents of the candidate revision, e.g. [10, 25, 50]. The candidate is promoted to 100 percent after
+	// the metrics were checked at the last step.
+	Steps []int64 `json:"steps"`
+	// Seconds between the metric checks, defaults to 60
+	// +optional
+	IntervalSeconds int64 `json:"intervalSeconds,omitempty"`
+	// Number of consecutive failed checks after which the candidate revision is rolled back, defaults to 3
+	// +optional
+	FailureThreshold int `json:"failureThreshold,omitempty"`
+	// Metrics checked before each promotion, all of them must be within their thresholds
+	// +optional
+	Metrics []CanaryMetric `json:"metrics,omitempty"`
+}
+
+// CanaryMetric is a Prometheus query evaluated for the candidate revision
+type CanaryMetric struct {
+	// Name of the metric, e.g. error-rate or p99-latency
+	Name string `json:"name"`
+	// PromQL query returning a single value. The query is a template of the Namespace, the Name of the InferenceService,
+	// the Component and the candidate Revision, e.g.
+	// sum(rate(revision_app_request_count{revision_name="{{.Revision}}",response_code_class="5xx"}[1m]))
+	Query string `json:"query"`
+	// Maximum value of the query result for the check to pass
+	Threshold float64 `json:"threshold"`
+}
+
+// PlacementPolicy selects the capacity the component pods are scheduled on
+// +kubebuilder:validation:Enum=OnDemand;PreferSpot
+type PlacementPolicy string
+
+// PlacementPolicy Enum
+const (
+	// OnDemandPlacement schedules the pods without a capacity preference
+	OnDemandPlacement PlacementPolicy = "OnDemand"
+	// PreferSpotPlacement prefers the spot nodes, the pods fall back to on-demand nodes when no spot capacity is
+	// available or when pods of the component were recently preempted
+	PreferSpotPlacement PlacementPolicy = "PreferSpot"
+)
+
 // Default the ComponentExtensionSpec
 func (s *ComponentExtensionSpec) Default(config *InferenceServicesConfig) {}
 
@@ -97,10 +321,108 @@ func (s *ComponentExtensionSpec) Validate() error {
 	return utils.FirstNonNilError([]error{
 		validateContainerConcurrency(s.ContainerConcurrency),
 		validateReplicas(s.MinReplicas, s.MaxReplicas),
+		validateScalingSchedules(s.ScalingSchedules, s.MaxReplicas),
+		validatePredictiveScaling(s.PredictiveScaling),
 		validateLogger(s.Logger),
+		validatePlacementPolicy(s.PlacementPolicy),
+		validateWarmUp(s.WarmUp),
+		validateRollout(s.Rollout),
+		validateRevisionRetention(s.RevisionRetention),
+		validatePreStopSleep(s.PreStopSleepSeconds),
+		validateCanaryAnalysis(s.CanaryAnalysis),
 	})
 }
 
+func validateCanaryAnalysis(analysis *CanaryAnalysis) error {
+	if analysis == nil {
+		return nil
+	}
+	if len(analysis.Steps) == 0 {
+		return fmt.Errorf(CanaryAnalysisStepsError)
+	}
+	for i, step := range analysis.Steps {
+		if step < 1 || step > 99 || (i > 0 && step <= analysis.Steps[i-1]) {
+			return fmt.Errorf(CanaryAnalysisStepsError)
+		}
+	}
+	if analysis.IntervalSeconds < 0 || analysis.FailureThreshold < 0 {
+		return fmt.Errorf(CanaryAnalysisLowerBoundError)
+	}
+	for _, metric := range analysis.Metrics {
+		if metric.Name == "" || metric.Query == "" {
+			return fmt.Errorf(CanaryAnalysisMetricError)
+		}
+	}
+	return nil
+}
+
+func validatePredictiveScaling(scaling *PredictiveScalingSpec) error {
+	if scaling == nil {
+		return nil
+	}
+	if scaling.TargetRequestsPerSecond <= 0 {
+		return fmt.Errorf(PredictiveScalingTargetError)
+	}
+	if scaling.LookaheadSeconds < 0 || scaling.PeriodSeconds < 0 || scaling.Periods < 0 {
+		return fmt.Errorf(PredictiveScalingWindowError)
+	}
+	// the defaults are applied by the controller, a custom period must be longer than the effective lookahead
+	lookahead, period := scaling.LookaheadSeconds, scaling.PeriodSeconds
+	if lookahead == 0 {
+		lookahead = DefaultPredictiveLookaheadSeconds
+	}
+	if period == 0 {
+		period = DefaultPredictivePeriodSeconds
+	}
+	if lookahead >= period {
+		return fmt.Errorf(PredictiveScalingWindowError)
+	}
+	return nil
+}
+
+func validateWarmUp(warmUp *WarmUpSpec) error {
+	if warmUp == nil {
+		return nil
+	}
+	if (warmUp.ConfigMapKeyRef == nil) == (warmUp.URI == "") {
+		return fmt.Errorf(WarmUpPayloadError)
+	}
+	if warmUp.Requests < 0 {
+		return fmt.Errorf(WarmUpRequestsLowerBoundError)
+	}
+	return nil
+}
+
+func validateRollout(rollout *RolloutSpec) error {
+	if rollout != nil && (rollout.SoakSeconds < 0 || rollout.DrainSeconds < 0) {
+		return fmt.Errorf(RolloutLowerBoundError)
+	}
+	return nil
+}
+
+func validateRevisionRetention(retention *RevisionRetentionSpec) error {
+	if retention != nil && retention.GetKeepReadyRevisions() < 0 {
+		return fmt.Errorf(RevisionRetentionLowerBoundError)
+	}
+	return nil
+}
+
+func validatePreStopSleep(preStopSleepSeconds *int64) error {
+	if preStopSleepSeconds != nil && *preStopSleepSeconds < 0 {
+		return fmt.Errorf(PreStopSleepLowerBoundError)
+	}
+	return nil
+}
+
+func validatePlacementPolicy(policy PlacementPolicy) error {
+	switch policy {
+	case "", OnDemandPlacement, PreferSpotPlacement:
+		return nil
+	}
+	return fmt.Errorf(InvalidPlacementPolicyError, policy, strings.Join([]string{string(OnDemandPlacement),
+		string(PreferSpotPlacement)}, ", "))
+}
+
 func validateStorageURI(storageURI *string) error {
 	if storageURI == nil {
 		return nil
@@ -144,6 +466,187 @@ func validateReplicas(minReplicas *int, maxReplicas int) error {
 	return nil
 }
 
+func validateScalingSchedules(schedules []ScalingSchedule, maxReplicas int) error {
+	for _, scalingSchedule := range schedules {
+		if _, err := schedule.Parse(scalingSchedule.Schedule); err != nil {
+			return fmt.Errorf(InvalidScalingScheduleError, err)
+		}
+		if scalingSchedule.MinReplicas < 0 || (maxReplicas != 0 && scalingSchedule.MinReplicas > maxReplicas) {
+			return fmt.Errorf(ScheduledMinReplicasError, scalingSchedule.Schedule)
+		}
+	}
+	return nil
+}
+
+// validateGPUResources checks the GPU resources of the implementation containers, the framework implementations
+// inline the model server container and the custom implementations inline a PodSpec
+func validateGPUResources(implementation ComponentImplementation) error {
+	for _, requirements := range GetResourceRequirements(implementation) {
+		if err := validateGPUResourceRequirements(requirements); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetRuntimeName returns the name of the runtime of the component implementation in the inferenceservice configmap, or
+// an empty name for the custom implementations
+func GetRuntimeName(implementation ComponentImplementation) string {
+	switch implementation.(type) {
+	case *TFServingSpec:
+		return "tensorflow"
+	case *TritonSpec:
+		return "triton"
+	case *XGBoostSpec:
+		return "xgboost"
+	case *SKLearnSpec:
+		return "sklearn"
+	case *TorchServeSpec:
+		return "pytorch"
+	case *ONNXRuntimeSpec:
+		return "onnx"
+	case *PMMLSpec:
+		return "pmml"
+	case *FeastTransformerSpec:
+		return "feast"
+	case *AlibiExplainerSpec:
+		return "alibi"
+	case *AIXExplainerSpec:
+		return "aix"
+	case *AlibiDriftDetectorSpec, *AlibiOutlierDetectorSpec:
+		return "alibiDetect"
+	}
+	return ""
+}
+
+// GetResourceRequirements returns the resources of the containers of the component implementation
+func GetResourceRequirements(implementation ComponentImplementation) []v1.ResourceRequirements {
+	resources := []v1.ResourceRequirements{}
+	switch impl := implementation.(type) {
+	case *CustomPredictor:
+		for _, container := range impl.Containers {
+			resources = append(resources, container.Resources)
+		}
+	case *CustomExplainer:
+		for _, container := range impl.Containers {
+			resources = append(resources, container.Resources)
+		}
+	case *CustomTransformer:
+		for _, container := range impl.Containers {
+			resources = append(resources, container.Resources)
+		}
+	default:
+		if field := reflect.ValueOf(implementation).Elem().FieldByName("Resources"); field.IsValid() {
+			if requirements, ok := field.Interface().(v1.ResourceRequirements); ok {
+				resources = append(resources, requirements)
+			}
+		}
+	}
+	return resources
+}
+
+// validateEnvironment checks the env and envFrom of the implementation containers, knative only allows the
+// environment to be sourced from configmaps and secrets
+func validateEnvironment(implementation ComponentImplementation) error {
+	for _, container := range getContainers(implementation) {
+		for _, env := range container.Env {
+			if err := validateEnvVar(env); err != nil {
+				return err
+			}
+		}
+		for _, envFrom := range container.EnvFrom {
+			if err := validateEnvFromSource(envFrom); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// getContainers returns the containers of the component implementation
+func getContainers(implementation ComponentImplementation) []v1.Container {
+	switch impl := implementation.(type) {
+	case *CustomPredictor:
+		return impl.Containers
+	case *CustomExplainer:
+		return impl.Containers
+	case *CustomTransformer:
+		return impl.Containers
+	default:
+		if field := reflect.ValueOf(implementation).Elem().FieldByName("Container"); field.IsValid() {
+			if container, ok := field.Interface().(v1.Container); ok {
+				return []v1.Container{container}
+			}
+		}
+	}
+	return nil
+}
+
+func validateEnvVar(env v1.EnvVar) error {
+	if env.ValueFrom == nil {
+		return nil
+	}
+	if env.Value != "" {
+		return fmt.Errorf(EnvValueFromError, env.Name)
+	}
+	source := env.ValueFrom
+	if source.FieldRef != nil || source.ResourceFieldRef != nil ||
+		(source.ConfigMapKeyRef == nil) == (source.SecretKeyRef == nil) {
+		return fmt.Errorf(EnvValueSourceError, env.Name)
+	}
+	if ref := source.ConfigMapKeyRef; ref != nil && (ref.Name == "" || ref.Key == "") {
+		return fmt.Errorf(EnvKeyRefError, env.Name)
+	}
+	if ref := source.SecretKeyRef; ref != nil && (ref.Name == "" || ref.Key == "") {
+		return fmt.Errorf(EnvKeyRefError, env.Name)
+	}
+	return nil
+}
+
+func validateEnvFromSource(envFrom v1.EnvFromSource) error {
+	if (envFrom.ConfigMapRef == nil) == (envFrom.SecretRef == nil) {
+		return fmt.Errorf(EnvFromSourceError)
+	}
+	if ref := envFrom.ConfigMapRef; ref != nil && ref.Name == "" {
+		return fmt.Errorf(EnvFromSourceError)
+	}
+	if ref := envFrom.SecretRef; ref != nil && ref.Name == "" {
+		return fmt.Errorf(EnvFromSourceError)
+	}
+	return nil
+}
+
+func validateGPUResourceRequirements(requirements v1.ResourceRequirements) error {
+	gpuResources := map[v1.ResourceName]bool{}
+	for _, list := range []v1.ResourceList{requirements.Limits, requirements.Requests} {
+		for name := range list {
+			if !utils.IsGPUResource(name) {
+				continue
+			}
+			if strings.HasPrefix(string(name), constants.NvidiaMIGResourcePrefix) && !MIGResourceRegEx.MatchString(string(name)) {
+				return fmt.Errorf(InvalidMIGResourceError, name)
+			}
+			gpuResources[name] = true
+		}
+	}
+	if len(gpuResources) > 1 {
+		names := []string{}
+		for name := range gpuResources {
+			names = append(names, string(name))
+		}
+		sort.Strings(names)
+		return fmt.Errorf(MultipleGPUResourcesError, strings.Join(names, ", "))
+	}
+	for name := range gpuResources {
+		// GPUs can not be overcommitted, the scheduler requires the requests to be equal to the limits
+		limit, hasLimit := requirements.Limits[name]
+		if request, ok := requirements.Requests[name]; ok && (!hasLimit || request.Cmp(limit) != 0) {
+			return fmt.Errorf(GPUResourceRequestLimitError, name)
+		}
+	}
+	return nil
+}
+
 func validateContainerConcurrency(containerConcurrency *int64) error {
 	if containerConcurrency == nil {
 		return nil
@@ -159,6 +662,9 @@ func validateLogger(logger *LoggerSpec) error {
 		if !(logger.Mode == LogAll || logger.Mode == LogRequest || logger.Mode == LogResponse) {
 			return fmt.Errorf(InvalidLoggerType)
 		}
+		if logger.Retries != nil && *logger.Retries < 0 {
+			return fmt.Errorf(LoggerRetriesLowerBoundError)
+		}
 	}
 	return nil
 }
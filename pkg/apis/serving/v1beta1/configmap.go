@@ -20,18 +20,40 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/kubeflow/kfserving/pkg/constants"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // ConfigMap Keys
 const (
-	PredictorConfigKeyName   = "predictors"
-	TransformerConfigKeyName = "transformers"
-	ExplainerConfigKeyName   = "explainers"
+	PredictorConfigKeyName     = "predictors"
+	TransformerConfigKeyName   = "transformers"
+	ExplainerConfigKeyName     = "explainers"
+	PropagationConfigKeyName   = "propagation"
+	DriftConfigKeyName         = "drift"
+	CostConfigKeyName          = "cost"
+	MetricsConfigKeyName       = "metrics"
+	DetectorsConfigKeyName     = "detectors"
+	RegistriesConfigKeyName    = "registries"
+	ImagesConfigKeyName        = "images"
+	StartupConfigKeyName       = "startup"
+	DigestPinningConfigKeyName = "digestPinning"
+)
+
+// DriftPolicy is the action taken on out of band changes to the generated resources
+type DriftPolicy string
+
+// DriftPolicy Enum
+const (
+	// DriftPolicyRevert overwrites the out of band changes with the desired spec
+	DriftPolicyRevert DriftPolicy = "Revert"
+	// DriftPolicyReport keeps the out of band changes and reports them with the ChildResourceDrifted condition
+	DriftPolicyReport DriftPolicy = "Report"
 )
 
 const (
@@ -52,6 +74,19 @@ type ExplainersConfig struct {
 	AIXExplainer   ExplainerConfig `json:"aix,omitempty"`
 }
 
+// +kubebuilder:object:generate=false
+type DetectorConfig struct {
+	// detector docker image name
+	ContainerImage string `json:"image"`
+	// default detector docker image version
+	DefaultImageVersion string `json:"defaultImageVersion"`
+}
+
+// +kubebuilder:object:generate=false
+type DetectorsConfig struct {
+	AlibiDetect DetectorConfig `json:"alibiDetect,omitempty"`
+}
+
 // +kubebuilder:object:generate=false
 type PredictorConfig struct {
 	// predictor docker image name
@@ -92,6 +127,101 @@ type TransformersConfig struct {
 	Feast TransformerConfig `json:"feast,omitempty"`
 }
 
+// +kubebuilder:object:generate=false
+type PropagationRules struct {
+	// keys which propagate, a key ending with "*" matches by prefix, all keys propagate when empty
+	Allow []string `json:"allow,omitempty"`
+	// keys which never propagate, takes precedence over allow
+	Deny []string `json:"deny,omitempty"`
+}
+
+// +kubebuilder:object:generate=false
+type PropagationConfig struct {
+	// rules for the InferenceService labels propagated to the knative services, routes, revisions and pods
+	Labels PropagationRules `json:"labels,omitempty"`
+	// rules for the InferenceService annotations propagated to the revisions and pods
+	Annotations PropagationRules `json:"annotations,omitempty"`
+}
+
+// +kubebuilder:object:generate=false
+type DriftConfig struct {
+	// action taken on out of band changes to the generated resources, defaults to Revert
+	Policy DriftPolicy `json:"policy,omitempty"`
+}
+
+// +kubebuilder:object:generate=false
+type CostConfig struct {
+	// price of a requested CPU per hour
+	CPUPerHour float64 `json:"cpuPerHour,omitempty"`
+	// price of a requested GB (2^30 bytes) of memory per hour
+	MemoryGBPerHour float64 `json:"memoryGBPerHour,omitempty"`
+	// price of a requested GPU, MIG slice or shared GPU per hour
+	GPUPerHour float64 `json:"gpuPerHour,omitempty"`
+	// currency of the prices, reported with the estimated cost
+	Currency string `json:"currency,omitempty"`
+}
+
+// +kubebuilder:object:generate=false
+type MetricsConfig struct {
+	// address of the Prometheus server queried by the canary analysis, e.g. http://prometheus.istio-system:9090
+	PrometheusURL string `json:"prometheusUrl,omitempty"`
+}
+
+// +kubebuilder:object:generate=false
+type RegistryConfig struct {
+	// type of the registry, selects the plugin resolving the model references, e.g. mlflow
+	Type string `json:"type"`
+	// address of the registry server, e.g. http://mlflow.mlflow:5000
+	URL string `json:"url"`
+}
+
+// +kubebuilder:object:generate=false
+type ImageMirror struct {
+	// registry or repository prefix of the images which are rewritten, e.g. docker.io/kfserving
+	Source string `json:"source"`
+	// prefix replacing the source, e.g. registry.internal/kfserving
+	Mirror string `json:"mirror"`
+}
+
+// +kubebuilder:object:generate=false
+type ImagesConfig struct {
+	// rewrite rules of the runtime images, the first mirror whose source matches an image rewrites it
+	Mirrors []ImageMirror `json:"mirrors,omitempty"`
+}
+
+// StartupConfig defaults
+const (
+	DefaultStartupMinTimeoutSeconds = 300
+	DefaultStartupLoadRatePerSecond = "100Mi"
+)
+
+// +kubebuilder:object:generate=false
+type StartupConfig struct {
+	// minimum seconds the predictor model servers are given to start, defaults to 300
+	MinTimeoutSeconds int64 `json:"minTimeoutSeconds,omitempty"`
+	// bytes of a model the model servers load per second, the startup timeout of a predictor grows with its model
+	// size. Defaults to 100Mi.
+	LoadRatePerSecond resource.Quantity `json:"loadRatePerSecond,omitempty"`
+}
+
+// DigestPinning defaults
+const (
+	DefaultDigestTimeoutSeconds = 10
+)
+
+// +kubebuilder:object:generate=false
+type DigestPinningConfig struct {
+	// Pin the container images of the knative service templates to the digests of their tags, the digests are
+	// resolved when the images of a template change and a component is not updated when they cannot be resolved
+	Enabled bool `json:"enabled"`
+	// Registries whose images are left untouched, e.g. the local registries of a development cluster
+	SkipRegistries []string `json:"skipRegistries,omitempty"`
+	// Registries which are served over http
+	InsecureRegistries []string `json:"insecureRegistries,omitempty"`
+	// Timeout of the requests to the registries
+	TimeoutSeconds int64 `json:"timeoutSeconds,omitempty"`
+}
+
 // +kubebuilder:object:generate=false
 type InferenceServicesConfig struct {
 	// Transformer configurations
@@ -100,6 +230,56 @@ type InferenceServicesConfig struct {
 	Predictors PredictorsConfig `json:"predictors"`
 	// Explainer configurations
 	Explainers ExplainersConfig `json:"explainers"`
+	// Detector configurations
+	Detectors DetectorsConfig `json:"detectors"`
+	// Label and annotation propagation configurations
+	Propagation PropagationConfig `json:"propagation"`
+	// Drift detection configurations
+	Drift DriftConfig `json:"drift"`
+	// Price table of the cost estimation, the cost is not estimated when it is not configured
+	Cost *CostConfig `json:"cost,omitempty"`
+	// Metrics server queried by the controller, the canary analysis does not promote canaries when it is not configured
+	Metrics *MetricsConfig `json:"metrics,omitempty"`
+	// Model registries the predictor model references are resolved with, by name
+	Registries map[string]RegistryConfig `json:"registries,omitempty"`
+	// Registry mirrors the runtime images are pulled from
+	Images ImagesConfig `json:"images,omitempty"`
+	// Startup probe configurations of the predictors
+	Startup StartupConfig `json:"startup,omitempty"`
+	// Digest pinning of the component images
+	DigestPinning DigestPinningConfig `json:"digestPinning,omitempty"`
+}
+
+// Propagates returns true if the key is allowed and not denied by the rules
+func (r *PropagationRules) Propagates(key string) bool {
+	if matchesAny(r.Deny, key) {
+		return false
+	}
+	return len(r.Allow) == 0 || matchesAny(r.Allow, key)
+}
+
+// PropagatesAnnotation returns true if the annotation propagates to the generated resources, the annotations the
+// controller reads back from them always propagate so that the rules cannot disable rollbacks or GPU sharing
+func (c *PropagationConfig) PropagatesAnnotation(key string) bool {
+	for _, annotation := range constants.ControllerAnnotations {
+		if key == annotation {
+			return true
+		}
+	}
+	return c.Annotations.Propagates(key)
+}
+
+func matchesAny(patterns []string, key string) bool {
+	for _, pattern := range patterns {
+		if strings.HasSuffix(pattern, "*") {
+			if strings.HasPrefix(key, strings.TrimSuffix(pattern, "*")) {
+				return true
+			}
+		} else if pattern == key {
+			return true
+		}
+	}
+	return false
 }
 
 // +kubebuilder:object:generate=false
@@ -114,25 +294,123 @@ func NewInferenceServicesConfig(cli client.Client) (*InferenceServicesConfig, er
 	if err != nil {
 		return nil, err
 	}
+	return NewInferenceServicesConfigFromConfigMap(configMap)
+}
+
+// NewInferenceServicesConfigFromConfigMap parses the component configurations from the inferenceservice configmap
+func NewInferenceServicesConfigFromConfigMap(configMap *v1.ConfigMap) (*InferenceServicesConfig, error) {
 	icfg := &InferenceServicesConfig{}
 	for _, err := range []error{
 		getComponentConfig(PredictorConfigKeyName, configMap, &icfg.Predictors),
 		getComponentConfig(ExplainerConfigKeyName, configMap, &icfg.Explainers),
 		getComponentConfig(TransformerConfigKeyName, configMap, &icfg.Transformers),
+		getComponentConfig(DetectorsConfigKeyName, configMap, &icfg.Detectors),
+		getComponentConfig(PropagationConfigKeyName, configMap, &icfg.Propagation),
+		getComponentConfig(DriftConfigKeyName, configMap, &icfg.Drift),
+		getComponentConfig(CostConfigKeyName, configMap, &icfg.Cost),
+		getComponentConfig(MetricsConfigKeyName, configMap, &icfg.Metrics),
+		getComponentConfig(RegistriesConfigKeyName, configMap, &icfg.Registries),
+		getComponentConfig(ImagesConfigKeyName, configMap, &icfg.Images),
+		getComponentConfig(StartupConfigKeyName, configMap, &icfg.Startup),
+		getComponentConfig(DigestPinningConfigKeyName, configMap, &icfg.DigestPinning),
 	} {
 		if err != nil {
 			return nil, err
 		}
 	}
+	switch icfg.Drift.Policy {
+	case "":
+		icfg.Drift.Policy = DriftPolicyRevert
+	case DriftPolicyRevert, DriftPolicyReport:
+	default:
+		return nil, fmt.Errorf("Invalid drift config, policy must be one of %s or %s.", DriftPolicyRevert, DriftPolicyReport)
+	}
+	for _, mirror := range icfg.Images.Mirrors {
+		if mirror.Source == "" || mirror.Mirror == "" {
+			return nil, fmt.Errorf("Invalid images config, mirrors must have a source and a mirror.")
+		}
+	}
+	for _, image := range icfg.runtimeImages() {
+		*image = icfg.Images.Rewrite(*image)
+	}
+	if icfg.Startup.MinTimeoutSeconds == 0 {
+		icfg.Startup.MinTimeoutSeconds = DefaultStartupMinTimeoutSeconds
+	}
+	if icfg.Startup.LoadRatePerSecond.IsZero() {
+		icfg.Startup.LoadRatePerSecond = resource.MustParse(DefaultStartupLoadRatePerSecond)
+	}
+	if icfg.Startup.MinTimeoutSeconds < 0 || icfg.Startup.LoadRatePerSecond.Sign() < 0 {
+		return nil, fmt.Errorf("Invalid startup config, minTimeoutSeconds and loadRatePerSecond must be greater than 0.")
+	}
+	if icfg.DigestPinning.TimeoutSeconds == 0 {
+		icfg.DigestPinning.TimeoutSeconds = DefaultDigestTimeoutSeconds
+	}
+	if icfg.DigestPinning.TimeoutSeconds < 0 {
+		return nil, fmt.Errorf("Invalid digest pinning config, timeoutSeconds cannot be less than 0.")
+	}
 	return icfg, nil
 }
 
+// runtimeImages returns the images of the runtimes the components default to
+func (c *InferenceServicesConfig) runtimeImages() []*string {
+	images := []*string{
+		&c.Predictors.Tensorflow.ContainerImage,
+		&c.Predictors.Triton.ContainerImage,
+		&c.Predictors.PyTorch.ContainerImage,
+		&c.Predictors.ONNX.ContainerImage,
+		&c.Predictors.PMML.ContainerImage,
+		&c.Transformers.Feast.ContainerImage,
+		&c.Explainers.AlibiExplainer.ContainerImage,
+		&c.Explainers.AIXExplainer.ContainerImage,
+		&c.Detectors.AlibiDetect.ContainerImage,
+	}
+	for _, protocols := range []*PredictorProtocols{&c.Predictors.XGBoost, &c.Predictors.SKlearn} {
+		for _, predictor := range []*PredictorConfig{protocols.V1, protocols.V2} {
+			if predictor != nil {
+				images = append(images, &predictor.ContainerImage)
+			}
+		}
+	}
+	return images
+}
+
+// Rewrite returns the image pulled from the first mirror whose source matches it, an image without registry is
+// matched as a docker.io image.
+func (c *ImagesConfig) Rewrite(image string) string {
+	name := normalizeImage(image)
+	for _, mirror := range c.Mirrors {
+		source := strings.TrimSuffix(mirror.Source, "/")
+		if strings.HasPrefix(name, source+"/") {
+			return strings.TrimSuffix(mirror.Mirror, "/") + strings.TrimPrefix(name, source)
+		}
+	}
+	return image
+}
+
+// normalizeImage prefixes the image with the docker.io registry and the library repository it is pulled from
+// when they are implicit
+func normalizeImage(image string) string {
+	parts := strings.SplitN(image, "/", 2)
+	if len(parts) == 1 {
+		return "docker.io/library/" + image
+	}
+	if !strings.ContainsAny(parts[0], ".:") && parts[0] != "localhost" {
+		return "docker.io/" + image
+	}
+	return image
+}
+
 func NewIngressConfig(cli client.Client) (*IngressConfig, error) {
 	configMap := &v1.ConfigMap{}
 	err := cli.Get(context.TODO(), types.NamespacedName{Name: constants.InferenceServiceConfigMapName, Namespace: constants.KFServingNamespace}, configMap)
 	if err != nil {
 		return nil, err
 	}
+	return NewIngressConfigFromConfigMap(configMap)
+}
+
+// NewIngressConfigFromConfigMap parses the ingress configuration from the inferenceservice configmap
+func NewIngressConfigFromConfigMap(configMap *v1.ConfigMap) (*IngressConfig, error) {
 	ingressConfig := &IngressConfig{}
 	if ingress, ok := configMap.Data[IngressConfigKeyName]; ok {
 		err := json.Unmarshal([]byte(ingress), &ingressConfig)
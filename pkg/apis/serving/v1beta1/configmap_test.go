@@ -0,0 +1,175 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestPropagationRules(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	scenarios := map[string]struct {
+		rules    PropagationRules
+		key      string
+		expected bool
+	}{
+		"AllowAllByDefault": {
+			rules:    PropagationRules{},
+			key:      "team",
+			expected: true,
+		},
+		"Denied": {
+			rules:    PropagationRules{Deny: []string{"team"}},
+			key:      "team",
+			expected: false,
+		},
+		"DeniedByPrefix": {
+			rules:    PropagationRules{Deny: []string{"serving.knative.dev/*"}},
+			key:      "serving.knative.dev/creator",
+			expected: false,
+		},
+		"AllowedByPrefix": {
+			rules:    PropagationRules{Allow: []string{"example.com/*"}},
+			key:      "example.com/cost-center",
+			expected: true,
+		},
+		"NotAllowed": {
+			rules:    PropagationRules{Allow: []string{"example.com/*"}},
+			key:      "team",
+			expected: false,
+		},
+		"DenyTakesPrecedence": {
+			rules:    PropagationRules{Allow: []string{"example.com/*"}, Deny: []string{"example.com/secret"}},
+			key:      "example.com/secret",
+			expected: false,
+		},
+	}
+
+	for name, scenario := range scenarios {
+		g.Expect(scenario.rules.Propagates(scenario.key)).To(gomega.Equal(scenario.expected), name)
+	}
+}
+
+func TestPropagatesAnnotation(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	config := PropagationConfig{Annotations: PropagationRules{Allow: []string{"example.com/*"}, Deny: []string{"serving.kubeflow.org/*"}}}
+	g.Expect(config.PropagatesAnnotation("example.com/team")).To(gomega.BeTrue())
+	g.Expect(config.PropagatesAnnotation("other.com/team")).To(gomega.BeFalse())
+	g.Expect(config.PropagatesAnnotation(constants.PausedAnnotationKey)).To(gomega.BeFalse())
+	g.Expect(config.PropagatesAnnotation(constants.RollbackAnnotationKey)).To(gomega.BeTrue())
+	g.Expect(config.PropagatesAnnotation(constants.GPUSharingAnnotationKey)).To(gomega.BeTrue())
+}
+
+func TestNewInferenceServicesConfigFromConfigMap(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	configMap := &v1.ConfigMap{
+		Data: map[string]string{
+			PropagationConfigKeyName: `{
+				"labels": {"deny": ["internal/*"]},
+				"annotations": {"allow": ["example.com/*"]}
+			}`,
+		},
+	}
+	config, err := NewInferenceServicesConfigFromConfigMap(configMap)
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(config.Propagation).To(gomega.Equal(PropagationConfig{
+		Labels:      PropagationRules{Deny: []string{"internal/*"}},
+		Annotations: PropagationRules{Allow: []string{"example.com/*"}},
+	}))
+}
+
+func TestDriftConfig(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	config, err := NewInferenceServicesConfigFromConfigMap(&v1.ConfigMap{})
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(config.Drift.Policy).To(gomega.Equal(DriftPolicyRevert))
+
+	config, err = NewInferenceServicesConfigFromConfigMap(&v1.ConfigMap{
+		Data: map[string]string{DriftConfigKeyName: `{"policy": "Report"}`},
+	})
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(config.Drift.Policy).To(gomega.Equal(DriftPolicyReport))
+
+	_, err = NewInferenceServicesConfigFromConfigMap(&v1.ConfigMap{
+		Data: map[string]string{DriftConfigKeyName: `{"policy": "Ignore"}`},
+	})
+	g.Expect(err).ShouldNot(gomega.BeNil())
+}
+
+func TestCostConfig(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	config, err := NewInferenceServicesConfigFromConfigMap(&v1.ConfigMap{})
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(config.Cost).To(gomega.BeNil())
+
+	config, err = NewInferenceServicesConfigFromConfigMap(&v1.ConfigMap{
+		Data: map[string]string{CostConfigKeyName: `{"cpuPerHour": 0.0316, "memoryGBPerHour": 0.0042, "gpuPerHour": 2.48, "currency": "USD"}`},
+	})
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(config.Cost).To(gomega.Equal(&CostConfig{
+		CPUPerHour:      0.0316,
+		MemoryGBPerHour: 0.0042,
+		GPUPerHour:      2.48,
+		Currency:        "USD",
+	}))
+}
+
+func TestMetricsConfig(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	config, err := NewInferenceServicesConfigFromConfigMap(&v1.ConfigMap{})
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(config.Metrics).To(gomega.BeNil())
+
+	config, err = NewInferenceServicesConfigFromConfigMap(&v1.ConfigMap{
+		Data: map[string]string{MetricsConfigKeyName: `{"prometheusUrl": "http://prometheus.istio-system:9090"}`},
+	})
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(config.Metrics).To(gomega.Equal(&MetricsConfig{PrometheusURL: "http://prometheus.istio-system:9090"}))
+}
+
+func TestImagesConfig(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	config, err := NewInferenceServicesConfigFromConfigMap(&v1.ConfigMap{
+		Data: map[string]string{
+			PredictorConfigKeyName: `{
+				"tensorflow": {"image": "tensorflow/serving"},
+				"sklearn": {"v1": {"image": "gcr.io/kfserving/sklearnserver"}},
+				"triton": {"image": "nvcr.io/nvidia/tritonserver"}
+			}`,
+			ImagesConfigKeyName: `{"mirrors": [
+				{"source": "docker.io", "mirror": "registry.internal/dockerhub"},
+				{"source": "gcr.io/kfserving", "mirror": "registry.internal/kfserving/"}
+			]}`,
+		},
+	})
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(config.Predictors.Tensorflow.ContainerImage).To(gomega.Equal("registry.internal/dockerhub/tensorflow/serving"))
+	g.Expect(config.Predictors.SKlearn.V1.ContainerImage).To(gomega.Equal("registry.internal/kfserving/sklearnserver"))
+	g.Expect(config.Predictors.Triton.ContainerImage).To(gomega.Equal("nvcr.io/nvidia/tritonserver"))
+	g.Expect(config.Images.Rewrite("python:3.7")).To(gomega.Equal("registry.internal/dockerhub/library/python:3.7"))
+	g.Expect(config.Images.Rewrite("gcr.io/kfserving-dev/agent")).To(gomega.Equal("gcr.io/kfserving-dev/agent"))
+
+	_, err = NewInferenceServicesConfigFromConfigMap(&v1.ConfigMap{
+		Data: map[string]string{ImagesConfigKeyName: `{"mirrors": [{"source": "docker.io"}]}`},
+	})
+	g.Expect(err).ShouldNot(gomega.BeNil())
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"sort"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/kubeflow/kfserving/pkg/utils"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Alibi Detect server detector types
+const (
+	AlibiDriftDetector   = "DriftDetector"
+	AlibiOutlierDetector = "OutlierDetector"
+)
+
+// AlibiDriftDetectorSpec defines the arguments for configuring an Alibi Detect drift detection server
+type AlibiDriftDetectorSpec struct {
+	// The location of a trained drift detector
+	StorageURI string `json:"storageUri,omitempty"`
+	// Alibi Detect server docker image version, defaults to latest Alibi Detect server version
+	RuntimeVersion *string `json:"runtimeVersion,omitempty"`
+	// Inline custom parameter settings for the detector, e.g. drift_batch_size
+	Config map[string]string `json:"config,omitempty"`
+	// Container enables overrides for the detector.
+	// +optional
+	v1.Container `json:",inline"`
+}
+
+var _ ComponentImplementation = &AlibiDriftDetectorSpec{}
+
+func (s *AlibiDriftDetectorSpec) GetStorageUri() *string {
+	return &s.StorageURI
+}
+
+func (s *AlibiDriftDetectorSpec) GetContainer(metadata metav1.ObjectMeta, extensions *ComponentExtensionSpec, config *InferenceServicesConfig) *v1.Container {
+	if s.Container.Image == "" {
+		s.Image = config.Detectors.AlibiDetect.ContainerImage + ":" + *s.RuntimeVersion
+	}
+	s.Name = constants.InferenceServiceContainerName
+	s.Args = alibiDetectorArgs(metadata, AlibiDriftDetector, constants.DriftEventType, s.StorageURI, s.Config)
+	return &s.Container
+}
+
+func (s *AlibiDriftDetectorSpec) Default(config *InferenceServicesConfig) {
+	s.Name = constants.InferenceServiceContainerName
+	if s.RuntimeVersion == nil {
+		s.RuntimeVersion = proto.String(config.Detectors.AlibiDetect.DefaultImageVersion)
+	}
+	setResourceRequirementDefaults(&s.Resources)
+}
+
+// Validate the spec
+func (s *AlibiDriftDetectorSpec) Validate() error {
+	return utils.FirstNonNilError([]error{
+		validateStorageURI(s.GetStorageUri()),
+	})
+}
+
+// AlibiOutlierDetectorSpec defines the arguments for configuring an Alibi Detect outlier detection server
+type AlibiOutlierDetectorSpec struct {
+	// The location of a trained outlier detector
+	StorageURI string `json:"storageUri,omitempty"`
+	// Alibi Detect server docker image version, defaults to latest Alibi Detect server version
+	RuntimeVersion *string `json:"runtimeVersion,omitempty"`
+	// Inline custom parameter settings for the detector, e.g. threshold
+	Config map[string]string `json:"config,omitempty"`
+	// Container enables overrides for the detector.
+	// +optional
+	v1.Container `json:",inline"`
+}
+
+var _ ComponentImplementation = &AlibiOutlierDetectorSpec{}
+
+func (s *AlibiOutlierDetectorSpec) GetStorageUri() *string {
+	return &s.StorageURI
+}
+
+func (s *AlibiOutlierDetectorSpec) GetContainer(metadata metav1.ObjectMeta, extensions *ComponentExtensionSpec, config *InferenceServicesConfig) *v1.Container {
+	if s.Container.Image == "" {
+		s.Image = config.Detectors.AlibiDetect.ContainerImage + ":" + *s.RuntimeVersion
+	}
+	s.Name = constants.InferenceServiceContainerName
+	s.Args = alibiDetectorArgs(metadata, AlibiOutlierDetector, constants.OutlierEventType, s.StorageURI, s.Config)
+	return &s.Container
+}
+
+func (s *AlibiOutlierDetectorSpec) Default(config *InferenceServicesConfig) {
+	s.Name = constants.InferenceServiceContainerName
+	if s.RuntimeVersion == nil {
+		s.RuntimeVersion = proto.String(config.Detectors.AlibiDetect.DefaultImageVersion)
+	}
+	setResourceRequirementDefaults(&s.Resources)
+}
+
+// Validate the spec
+func (s *AlibiOutlierDetectorSpec) Validate() error {
+	return utils.FirstNonNilError([]error{
+		validateStorageURI(s.GetStorageUri()),
+	})
+}
+
+// alibiDetectorArgs returns the arguments of the Alibi Detect server, the server replies to the logged payloads with
+// CloudEvents of the event type
+func alibiDetectorArgs(metadata metav1.ObjectMeta, detectorType string, eventType string, storageURI string,
+	config map[string]string) []string {
+	args := []string{
+		constants.ArgumentModelName, metadata.Name,
+		constants.ArgumentHttpPort, constants.InferenceServiceDefaultHttpPort,
+		"--protocol", "tensorflow.http",
+		"--event_type", eventType,
+		"--event_source", constants.DetectorEventSource(metadata),
+	}
+	if storageURI != "" {
+		args = append(args, "--storage_uri", constants.DefaultModelLocalMountPath)
+	}
+	args = append(args, detectorType)
+
+	// Order detector config map keys
+	var keys []string
+	for k := range config {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		args = append(args, "--"+k, config[k])
+	}
+	return args
+}
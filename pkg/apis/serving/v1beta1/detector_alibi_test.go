@@ -0,0 +1,153 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/onsi/gomega"
+	"github.com/onsi/gomega/types"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAlibiDriftDetectorValidation(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	config := InferenceServicesConfig{
+		Detectors: DetectorsConfig{
+			AlibiDetect: DetectorConfig{
+				ContainerImage:      "alibi-detect",
+				DefaultImageVersion: "1.5.0",
+			},
+		},
+	}
+	scenarios := map[string]struct {
+		spec    DriftDetectorSpec
+		matcher types.GomegaMatcher
+	}{
+		"ValidStorageUri": {
+			spec: DriftDetectorSpec{
+				Alibi: &AlibiDriftDetectorSpec{
+					StorageURI: "s3://detectors/cifar10",
+				},
+			},
+			matcher: gomega.BeNil(),
+		},
+		"InvalidStorageUri": {
+			spec: DriftDetectorSpec{
+				Alibi: &AlibiDriftDetectorSpec{
+					StorageURI: "hdfs://detectors/cifar10",
+				},
+			},
+			matcher: gomega.Not(gomega.BeNil()),
+		},
+	}
+
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			scenario.spec.Alibi.Default(&config)
+			res := scenario.spec.Alibi.Validate()
+			if !g.Expect(res).To(scenario.matcher) {
+				t.Errorf("got %q, want %q", res, scenario.matcher)
+			}
+		})
+	}
+}
+
+func TestCreateAlibiDriftDetectorContainer(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	config := InferenceServicesConfig{
+		Detectors: DetectorsConfig{
+			AlibiDetect: DetectorConfig{
+				ContainerImage:      "alibi-detect",
+				DefaultImageVersion: "1.5.0",
+			},
+		},
+	}
+	spec := &DriftDetectorSpec{
+		Alibi: &AlibiDriftDetectorSpec{
+			StorageURI:     "s3://detectors/cifar10",
+			RuntimeVersion: proto.String("1.4.0"),
+			Config: map[string]string{
+				"drift_batch_size": "500",
+				"p_val":            "0.05",
+			},
+		},
+	}
+	detector := spec.GetImplementation()
+	detector.Default(&config)
+	res := detector.GetContainer(metav1.ObjectMeta{Name: "cifar10", Namespace: "default"}, spec.GetExtensions(), &config)
+	g.Expect(res.Name).To(gomega.Equal(constants.InferenceServiceContainerName))
+	g.Expect(res.Image).To(gomega.Equal("alibi-detect:1.4.0"))
+	g.Expect(res.Args).To(gomega.Equal([]string{
+		"--model_name", "cifar10",
+		"--http_port", "8080",
+		"--protocol", "tensorflow.http",
+		"--event_type", "org.kubeflow.serving.inference.drift",
+		"--event_source", "org.kubeflow.serving.default.cifar10",
+		"--storage_uri", "/mnt/models",
+		"DriftDetector",
+		"--drift_batch_size", "500",
+		"--p_val", "0.05",
+	}))
+}
+
+func TestCustomDriftDetector(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	spec := &DriftDetectorSpec{
+		PodSpec: PodSpec{
+			Containers: []v1.Container{{Image: "detector:0.1.0"}},
+		},
+	}
+	g.Expect(spec.GetImplementations()).To(gomega.HaveLen(1))
+	g.Expect(spec.GetImplementation().GetContainer(metav1.ObjectMeta{Name: "cifar10"}, spec.GetExtensions(),
+		&InferenceServicesConfig{}).Image).To(gomega.Equal("detector:0.1.0"))
+}
+
+func TestCreateAlibiOutlierDetectorContainer(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	config := InferenceServicesConfig{
+		Detectors: DetectorsConfig{
+			AlibiDetect: DetectorConfig{
+				ContainerImage:      "alibi-detect",
+				DefaultImageVersion: "1.5.0",
+			},
+		},
+	}
+	spec := &OutlierDetectorSpec{
+		Alibi: &AlibiOutlierDetectorSpec{
+			StorageURI: "s3://detectors/vae",
+		},
+		Mode: OutlierDetectionInline,
+	}
+	detector := spec.GetImplementation()
+	detector.Default(&config)
+	res := detector.GetContainer(metav1.ObjectMeta{Name: "cifar10", Namespace: "default"}, spec.GetExtensions(), &config)
+	g.Expect(spec.IsInline()).To(gomega.BeTrue())
+	g.Expect(res.Image).To(gomega.Equal("alibi-detect:1.5.0"))
+	g.Expect(res.Args).To(gomega.Equal([]string{
+		"--model_name", "cifar10",
+		"--http_port", "8080",
+		"--protocol", "tensorflow.http",
+		"--event_type", "org.kubeflow.serving.inference.outlier",
+		"--event_source", "org.kubeflow.serving.default.cifar10",
+		"--storage_uri", "/mnt/models",
+		"OutlierDetector",
+	}))
+}
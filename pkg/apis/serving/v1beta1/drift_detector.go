@@ -0,0 +1,69 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+// DriftDetectorSpec defines the drift detection service which is sent the payloads logged by the predictor.
+// The following fields follow a "1-of" semantic. Users must specify exactly one spec.
+type DriftDetectorSpec struct {
+	// Spec for the Alibi Detect drift detector
+	Alibi *AlibiDriftDetectorSpec `json:"alibi,omitempty"`
+	// Alert raised when the drift reported by the detector is above a threshold, the DriftDetected condition of the
+	// InferenceService is set while the alert is firing
+	// +optional
+	Alert *DetectorAlert `json:"alert,omitempty"`
+	// This spec is dual purpose.
+	// 1) Users may choose to provide a full PodSpec for their custom drift detector.
+	// The field PodSpec.Containers is mutually exclusive with other detectors (i.e. Alibi).
+	// 2) Users may choose to provide a detector (i.e. Alibi) and specify PodSpec
+	// overrides in the PodSpec. They must not provide PodSpec.Containers in this case.
+	PodSpec `json:",inline"`
+	// Extensions available in all components
+	ComponentExtensionSpec `json:",inline"`
+}
+
+// DetectorAlert is a Prometheus query of the metrics reported by a detector
+type DetectorAlert struct {
+	// PromQL query returning a single value. The query is a template of the Namespace, the Name of the
+	// InferenceService and the Component, like the canary analysis metrics.
+	Query string `json:"query"`
+	// The alert fires while the query result is above the threshold
+	Threshold float64 `json:"threshold"`
+}
+
+var _ Component = &DriftDetectorSpec{}
+
+// GetImplementations returns the implementations for the component
+func (s *DriftDetectorSpec) GetImplementations() []ComponentImplementation {
+	implementations := NonNilComponents([]ComponentImplementation{
+		s.Alibi,
+	})
+	// This struct is not a pointer, so it will never be nil; include if containers are specified
+	if len(s.PodSpec.Containers) != 0 {
+		implementations = append(implementations, NewCustomDetector(&s.PodSpec))
+	}
+	return implementations
+}
+
+// GetImplementation returns the implementation for the component
+func (s *DriftDetectorSpec) GetImplementation() ComponentImplementation {
+	return s.GetImplementations()[0]
+}
+
+// GetExtensions returns the extensions for the component
+func (s *DriftDetectorSpec) GetExtensions() *ComponentExtensionSpec {
+	return &s.ComponentExtensionSpec
+}
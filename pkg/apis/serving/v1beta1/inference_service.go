@@ -17,6 +17,7 @@ limitations under the License.
 package v1beta1
 
 import (
+	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -33,6 +34,29 @@ type InferenceServiceSpec struct {
 	// transformer service calls to predictor service.
 	// +optional
 	Transformer *TransformerSpec `json:"transformer,omitempty"`
+	// DriftDetector defines the drift detection service, the payloads of the predictor are logged to it.
+	// +optional
+	DriftDetector *DriftDetectorSpec `json:"driftDetector,omitempty"`
+	// OutlierDetector defines the outlier detection service, the requests of the predictor are scored by it.
+	// +optional
+	OutlierDetector *OutlierDetectorSpec `json:"outlierDetector,omitempty"`
+	// Routing defines the retries and the fallback of the ingress routes
+	// +optional
+	Routing *RoutingSpec `json:"routing,omitempty"`
+	// ImagePullSecrets are the secrets the images of all the components are pulled with, in addition to the
+	// imagePullSecrets of each component.
+	// +optional
+	ImagePullSecrets []v1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+	// ClassName is the name of the InferenceServiceClass which sets the defaults of the components
+	// +optional
+	ClassName string `json:"className,omitempty"`
+	// TTLSecondsAfterCreation is the lifetime of the InferenceService, it is scaled down and deleted once the TTL has
+	// elapsed since its creation
+	// +optional
+	TTLSecondsAfterCreation *int64 `json:"ttlSecondsAfterCreation,omitempty"`
+	// ExpireAt is the time the InferenceService is scaled down and deleted, the earliest of expireAt and the TTL applies
+	// +optional
+	ExpireAt *metav1.Time `json:"expireAt,omitempty"`
 }
 
 // LoggerType controls the scope of log publishing
@@ -61,6 +85,12 @@ type LoggerSpec struct {
 	// - "response": log only response
 	// +optional
 	Mode LoggerType `json:"mode,omitempty"`
+	// Number of retries of the logging events which failed to be delivered to the url, defaults to 2
+	// +optional
+	Retries *int `json:"retries,omitempty"`
+	// URL the logging events are sent to once their retries are exhausted, the events are dropped when it is not set
+	// +optional
+	DeadLetterURL *string `json:"deadLetterUrl,omitempty"`
 }
 
 // Batcher specifies optional payload batching available for all components
@@ -80,6 +110,7 @@ type Batcher struct {
 // +k8s:openapi-gen=true
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:subresource:scale:specpath=.spec.predictor.minReplicas,statuspath=.status.components.predictor.replicas,selectorpath=.status.components.predictor.selector
 // +kubebuilder:printcolumn:name="URL",type="string",JSONPath=".status.url"
 // +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
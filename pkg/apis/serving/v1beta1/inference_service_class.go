@@ -0,0 +1,211 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/kubeflow/kfserving/pkg/constants"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// InferenceServiceClass is the Schema for the InferenceServiceClass API. It defines the defaults of the components
+// of the InferenceServices which select the class by name, the fields set in an InferenceService take precedence.
+// +k8s:openapi-gen=true
+// +kubebuilder:object:root=true
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:resource:path=inferenceserviceclasses,scope=Cluster,shortName=isvcclass,singular=inferenceserviceclass
+type InferenceServiceClass struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec InferenceServiceClassSpec `json:"spec,omitempty"`
+}
+
+// InferenceServiceClassList contains a list of InferenceServiceClass
+// +kubebuilder:object:root=true
+type InferenceServiceClassList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	// +listType=set
+	Items []InferenceServiceClass `json:"items"`
+}
+
+// InferenceServiceClassSpec defines the defaults of all the components of the InferenceServices of the class. They
+// are set when an InferenceService is created or selects the class, the InferenceServices are not changed when the
+// class is.
+type InferenceServiceClassSpec struct {
+	// Resources of the containers of the components, each request and limit applies to the containers which do not
+	// set it
+	// +optional
+	Resources v1.ResourceRequirements `json:"resources,omitempty"`
+	// Minimum number of replicas of the components
+	// +optional
+	MinReplicas *int `json:"minReplicas,omitempty"`
+	// Maximum number of replicas of the components for autoscaling
+	// +optional
+	MaxReplicas int `json:"maxReplicas,omitempty"`
+	// ContainerConcurrency of the components
+	// +optional
+	ContainerConcurrency *int64 `json:"containerConcurrency,omitempty"`
+	// Logger of the components
+	// +optional
+	Logger *LoggerSpec `json:"logger,omitempty"`
+	// NodeSelector of the component pods, each label applies to the pods which do not select it
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// Tolerations of the component pods which have no tolerations
+	// +optional
+	Tolerations []v1.Toleration `json:"tolerations,omitempty"`
+	// Affinity of the component pods which have no affinity
+	// +optional
+	Affinity *v1.Affinity `json:"affinity,omitempty"`
+}
+
+func init() {
+	SchemeBuilder.Register(&InferenceServiceClass{}, &InferenceServiceClassList{})
+}
+
+// getInferenceServiceClass reads the class of the InferenceService, it returns nil when the InferenceService has no
+// class or the classes cannot be read
+func (isvc *InferenceService) getInferenceServiceClass() (*InferenceServiceClass, error) {
+	if isvc.Spec.ClassName == "" || ValidationReader == nil {
+		return nil, nil
+	}
+	class := &InferenceServiceClass{}
+	if err := ValidationReader.Get(context.TODO(), types.NamespacedName{Name: isvc.Spec.ClassName}, class); err != nil {
+		return nil, err
+	}
+	return class, nil
+}
+
+// applyClassDefaults sets the defaults of the class the InferenceService selects. The class is only looked up when the
+// InferenceService is created or selects another class, the class whose defaults were set is recorded in an annotation
+// so that the other updates are admitted without reading it.
+func (isvc *InferenceService) applyClassDefaults() {
+	if isvc.Spec.ClassName == "" {
+		delete(isvc.Annotations, constants.ClassDefaultsInternalAnnotationKey)
+		return
+	}
+	if !lookupsEnabled(isvc) || isvc.Annotations[constants.ClassDefaultsInternalAnnotationKey] == isvc.Spec.ClassName {
+		return
+	}
+	class, err := isvc.getInferenceServiceClass()
+	if err != nil {
+		mutatorLogger.Error(err, "Failed to read InferenceServiceClass", "class", isvc.Spec.ClassName)
+		return
+	}
+	isvc.setClassDefaults(&class.Spec)
+	if isvc.Annotations == nil {
+		isvc.Annotations = map[string]string{}
+	}
+	isvc.Annotations[constants.ClassDefaultsInternalAnnotationKey] = isvc.Spec.ClassName
+}
+
+// setClassDefaults sets the fields of the components which are not set to the defaults of the class
+func (isvc *InferenceService) setClassDefaults(class *InferenceServiceClassSpec) {
+	for _, component := range []Component{
+		&isvc.Spec.Predictor,
+		isvc.Spec.Transformer,
+		isvc.Spec.Explainer,
+		isvc.Spec.DriftDetector,
+		isvc.Spec.OutlierDetector,
+	} {
+		if reflect.ValueOf(component).IsNil() {
+			continue
+		}
+		extensions := component.GetExtensions()
+		if extensions.MinReplicas == nil && class.MinReplicas != nil {
+			minReplicas := *class.MinReplicas
+			extensions.MinReplicas = &minReplicas
+		}
+		if extensions.MaxReplicas == 0 {
+			extensions.MaxReplicas = class.MaxReplicas
+		}
+		if extensions.ContainerConcurrency == nil && class.ContainerConcurrency != nil {
+			containerConcurrency := *class.ContainerConcurrency
+			extensions.ContainerConcurrency = &containerConcurrency
+		}
+		if extensions.Logger == nil && class.Logger != nil {
+			extensions.Logger = class.Logger.DeepCopy()
+		}
+		if err := validateExactlyOneImplementation(component); err == nil {
+			for _, container := range implementationContainers(component.GetImplementation()) {
+				container.Resources.Requests = mergeResourceList(container.Resources.Requests, class.Resources.Requests)
+				container.Resources.Limits = mergeResourceList(container.Resources.Limits, class.Resources.Limits)
+			}
+		}
+	}
+	for _, podSpec := range isvc.componentPodSpecs() {
+		for key, value := range class.NodeSelector {
+			if _, ok := podSpec.NodeSelector[key]; !ok {
+				if podSpec.NodeSelector == nil {
+					podSpec.NodeSelector = map[string]string{}
+				}
+				podSpec.NodeSelector[key] = value
+			}
+		}
+		if len(podSpec.Tolerations) == 0 && len(class.Tolerations) != 0 {
+			podSpec.Tolerations = append([]v1.Toleration{}, class.Tolerations...)
+		}
+		if podSpec.Affinity == nil && class.Affinity != nil {
+			podSpec.Affinity = class.Affinity.DeepCopy()
+		}
+	}
+}
+
+// implementationContainers returns the containers of the component implementation which can be modified
+func implementationContainers(implementation ComponentImplementation) []*v1.Container {
+	containers := []*v1.Container{}
+	switch impl := implementation.(type) {
+	case *CustomPredictor:
+		for i := range impl.Containers {
+			containers = append(containers, &impl.Containers[i])
+		}
+	case *CustomExplainer:
+		for i := range impl.Containers {
+			containers = append(containers, &impl.Containers[i])
+		}
+	case *CustomTransformer:
+		for i := range impl.Containers {
+			containers = append(containers, &impl.Containers[i])
+		}
+	default:
+		if field := reflect.ValueOf(implementation).Elem().FieldByName("Container"); field.IsValid() {
+			if container, ok := field.Addr().Interface().(*v1.Container); ok {
+				containers = append(containers, container)
+			}
+		}
+	}
+	return containers
+}
+
+// mergeResourceList adds the resources of the defaults which are not in the list
+func mergeResourceList(list v1.ResourceList, defaults v1.ResourceList) v1.ResourceList {
+	for name, quantity := range defaults {
+		if _, ok := list[name]; !ok {
+			if list == nil {
+				list = v1.ResourceList{}
+			}
+			list[name] = quantity.DeepCopy()
+		}
+	}
+	return list
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestClassDefaults(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	class := &InferenceServiceClassSpec{
+		Resources: v1.ResourceRequirements{
+			Requests: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("2"),
+				v1.ResourceMemory: resource.MustParse("4Gi"),
+			},
+		},
+		MinReplicas:  GetIntReference(2),
+		MaxReplicas:  10,
+		Logger:       &LoggerSpec{URL: proto.String("http://logger.kfserving"), Mode: LogAll},
+		NodeSelector: map[string]string{"pool": "inference", "zone": "a"},
+		Tolerations:  []v1.Toleration{{Key: "inference", Operator: v1.TolerationOpExists}},
+	}
+	isvc := makeTestInferenceService()
+	isvc.Spec.Predictor.MinReplicas = GetIntReference(0)
+	isvc.Spec.Predictor.NodeSelector = map[string]string{"zone": "b"}
+	isvc.Spec.Predictor.Tensorflow.Resources = v1.ResourceRequirements{
+		Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")},
+	}
+	isvc.Spec.Transformer = &TransformerSpec{
+		PodSpec: PodSpec{
+			Containers: []v1.Container{{Image: "transformer:v1"}},
+		},
+	}
+	isvc.setClassDefaults(class)
+
+	g.Expect(*isvc.Spec.Predictor.MinReplicas).To(gomega.Equal(0))
+	g.Expect(isvc.Spec.Predictor.MaxReplicas).To(gomega.Equal(10))
+	g.Expect(isvc.Spec.Predictor.Logger).To(gomega.Equal(class.Logger))
+	g.Expect(isvc.Spec.Predictor.NodeSelector).To(gomega.Equal(map[string]string{"pool": "inference", "zone": "b"}))
+	g.Expect(isvc.Spec.Predictor.Tensorflow.Resources.Requests).To(gomega.Equal(v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse("1"),
+		v1.ResourceMemory: resource.MustParse("4Gi"),
+	}))
+	g.Expect(*isvc.Spec.Transformer.MinReplicas).To(gomega.Equal(2))
+	g.Expect(isvc.Spec.Transformer.Tolerations).To(gomega.Equal(class.Tolerations))
+	g.Expect(isvc.Spec.Transformer.Containers[0].Resources.Requests).To(gomega.Equal(class.Resources.Requests))
+}
+
+func TestInferenceServiceClassValidation(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scheme := runtime.NewScheme()
+	g.Expect(AddToScheme(scheme)).To(gomega.Succeed())
+	ValidationReader = fake.NewFakeClientWithScheme(scheme, &InferenceServiceClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "gpu"},
+	})
+	defer func() { ValidationReader = nil }()
+
+	isvc := makeTestInferenceService()
+	isvc.Spec.ClassName = "gpu"
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+	isvc.Spec.ClassName = "cpu"
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(fmt.Sprintf(InferenceServiceClassNotFoundError, "cpu")))
+
+	// the class is only looked up when it changes
+	old := isvc.DeepCopy()
+	isvc.Spec.Predictor.MinReplicas = GetIntReference(2)
+	g.Expect(isvc.ValidateUpdate(old)).Should(gomega.Succeed())
+	isvc.Spec.ClassName = "tpu"
+	g.Expect(isvc.ValidateUpdate(old)).Should(gomega.MatchError(fmt.Sprintf(InferenceServiceClassNotFoundError, "tpu")))
+
+	// the finalizer of a deleted InferenceService is removed after its class was deleted
+	isvc.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	g.Expect(isvc.ValidateUpdate(old)).Should(gomega.Succeed())
+}
+
+func TestApplyClassDefaults(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scheme := runtime.NewScheme()
+	g.Expect(AddToScheme(scheme)).To(gomega.Succeed())
+	ValidationReader = fake.NewFakeClientWithScheme(scheme,
+		&InferenceServiceClass{
+			ObjectMeta: metav1.ObjectMeta{Name: "gpu"},
+			Spec:       InferenceServiceClassSpec{MinReplicas: GetIntReference(2)},
+		},
+		&InferenceServiceClass{
+			ObjectMeta: metav1.ObjectMeta{Name: "cpu"},
+			Spec:       InferenceServiceClassSpec{MinReplicas: GetIntReference(1)},
+		},
+	)
+	defer func() { ValidationReader = nil }()
+
+	isvc := makeTestInferenceService()
+	isvc.Spec.ClassName = "gpu"
+	isvc.applyClassDefaults()
+	g.Expect(*isvc.Spec.Predictor.MinReplicas).To(gomega.Equal(2))
+	g.Expect(isvc.Annotations[constants.ClassDefaultsInternalAnnotationKey]).To(gomega.Equal("gpu"))
+
+	// the class is not looked up again until the InferenceService selects another class
+	isvc.Spec.Predictor.MinReplicas = nil
+	isvc.applyClassDefaults()
+	g.Expect(isvc.Spec.Predictor.MinReplicas).To(gomega.BeNil())
+	isvc.Spec.ClassName = "cpu"
+	isvc.applyClassDefaults()
+	g.Expect(*isvc.Spec.Predictor.MinReplicas).To(gomega.Equal(1))
+	g.Expect(isvc.Annotations[constants.ClassDefaultsInternalAnnotationKey]).To(gomega.Equal("cpu"))
+
+	// the class of a deleted InferenceService is not looked up
+	isvc.Spec.Predictor.MinReplicas = nil
+	isvc.Spec.ClassName = "gpu"
+	isvc.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	isvc.applyClassDefaults()
+	g.Expect(isvc.Spec.Predictor.MinReplicas).To(gomega.BeNil())
+
+	// the annotation is removed with the class
+	isvc.Spec.ClassName = ""
+	isvc.applyClassDefaults()
+	g.Expect(isvc.Annotations).NotTo(gomega.HaveKey(constants.ClassDefaultsInternalAnnotationKey))
+}
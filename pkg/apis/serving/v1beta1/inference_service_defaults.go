@@ -68,6 +68,7 @@ func (isvc *InferenceService) Default() {
 	if err != nil {
 		panic(err)
 	}
+	isvc.applyClassDefaults()
 	isvc.DefaultInferenceService(configMap)
 }
 
@@ -76,6 +77,8 @@ func (isvc *InferenceService) DefaultInferenceService(config *InferenceServicesC
 		&isvc.Spec.Predictor,
 		isvc.Spec.Transformer,
 		isvc.Spec.Explainer,
+		isvc.Spec.DriftDetector,
+		isvc.Spec.OutlierDetector,
 	} {
 		if !reflect.ValueOf(component).IsNil() {
 			if err := validateExactlyOneImplementation(component); err != nil {
@@ -86,4 +89,45 @@ func (isvc *InferenceService) DefaultInferenceService(config *InferenceServicesC
 			}
 		}
 	}
+	isvc.setImagePullSecretDefaults()
+	isvc.setTopologySpreadDefaults()
+}
+
+// setImagePullSecretDefaults adds the image pull secrets of the InferenceService to the pods of all the components
+func (isvc *InferenceService) setImagePullSecretDefaults() {
+	for _, podSpec := range isvc.componentPodSpecs() {
+		for _, secret := range isvc.Spec.ImagePullSecrets {
+			if !hasLocalObjectReference(podSpec.ImagePullSecrets, secret) {
+				podSpec.ImagePullSecrets = append(podSpec.ImagePullSecrets, secret)
+			}
+		}
+	}
+}
+
+// setTopologySpreadDefaults spreads the pods across the zones with a maximum skew of 1 unless the constraints say
+// otherwise, the pod mutator selects the pods of the component when a constraint has no label selector
+func (isvc *InferenceService) setTopologySpreadDefaults() {
+	for _, podSpec := range isvc.componentPodSpecs() {
+		for i := range podSpec.TopologySpreadConstraints {
+			constraint := &podSpec.TopologySpreadConstraints[i]
+			if constraint.TopologyKey == "" {
+				constraint.TopologyKey = v1.LabelZoneFailureDomainStable
+			}
+			if constraint.MaxSkew == 0 {
+				constraint.MaxSkew = 1
+			}
+			if constraint.WhenUnsatisfiable == "" {
+				constraint.WhenUnsatisfiable = v1.ScheduleAnyway
+			}
+		}
+	}
+}
+
+func hasLocalObjectReference(references []v1.LocalObjectReference, reference v1.LocalObjectReference) bool {
+	for _, r := range references {
+		if r.Name == reference.Name {
+			return true
+		}
+	}
+	return false
 }
@@ -132,3 +132,38 @@ func TestCustomPredictorDefaults(t *testing.T) {
 	isvc.DefaultInferenceService(config)
 	g.Expect(isvc.Spec.Predictor.PodSpec.Containers[0].Resources).To(gomega.Equal(resources))
 }
+
+func TestImagePullSecretDefaults(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := InferenceService{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: "default",
+		},
+		Spec: InferenceServiceSpec{
+			Predictor: PredictorSpec{
+				Tensorflow: &TFServingSpec{
+					PredictorExtensionSpec: PredictorExtensionSpec{
+						StorageURI: proto.String("gs://testbucket/testmodel"),
+					},
+				},
+			},
+			Transformer: &TransformerSpec{
+				PodSpec: PodSpec{
+					Containers:       []v1.Container{{Image: "transformer:v1"}},
+					ImagePullSecrets: []v1.LocalObjectReference{{Name: "transformer-registry"}, {Name: "mirror"}},
+				},
+			},
+			ImagePullSecrets: []v1.LocalObjectReference{{Name: "mirror"}},
+		},
+	}
+	isvc.DefaultInferenceService(&InferenceServicesConfig{
+		Predictors: PredictorsConfig{
+			Tensorflow: PredictorConfig{ContainerImage: "tfserving", DefaultImageVersion: "1.14.0"},
+		},
+	})
+	g.Expect(isvc.Spec.Predictor.PodSpec.ImagePullSecrets).To(gomega.Equal([]v1.LocalObjectReference{{Name: "mirror"}}))
+	g.Expect(isvc.Spec.Predictor.GetImplementations()).To(gomega.HaveLen(1))
+	g.Expect(isvc.Spec.Transformer.PodSpec.ImagePullSecrets).To(gomega.Equal(
+		[]v1.LocalObjectReference{{Name: "transformer-registry"}, {Name: "mirror"}}))
+}
@@ -0,0 +1,37 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GetExpirationTime returns the time the InferenceService expires, the earliest of its expireAt and of the end of its
+// TTL, or nil when it does not expire
+func (isvc *InferenceService) GetExpirationTime() *metav1.Time {
+	var expiration *metav1.Time
+	if ttl := isvc.Spec.TTLSecondsAfterCreation; ttl != nil && !isvc.CreationTimestamp.IsZero() {
+		end := metav1.NewTime(isvc.CreationTimestamp.Add(time.Duration(*ttl) * time.Second))
+		expiration = &end
+	}
+	if expireAt := isvc.Spec.ExpireAt; expireAt != nil && (expiration == nil || expireAt.Before(expiration)) {
+		expiration = expireAt.DeepCopy()
+	}
+	return expiration
+}
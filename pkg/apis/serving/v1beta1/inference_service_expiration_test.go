@@ -0,0 +1,47 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetExpirationTime(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	created := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+	isvc := makeTestInferenceService()
+	isvc.CreationTimestamp = metav1.NewTime(created)
+	g.Expect(isvc.GetExpirationTime()).To(gomega.BeNil())
+
+	isvc.Spec.TTLSecondsAfterCreation = proto.Int64(7200)
+	g.Expect(isvc.GetExpirationTime().Time).To(gomega.Equal(created.Add(2 * time.Hour)))
+
+	// the earliest of the TTL and expireAt applies
+	expireAt := metav1.NewTime(created.Add(time.Hour))
+	isvc.Spec.ExpireAt = &expireAt
+	g.Expect(isvc.GetExpirationTime().Time).To(gomega.Equal(created.Add(time.Hour)))
+	isvc.Spec.TTLSecondsAfterCreation = proto.Int64(60)
+	g.Expect(isvc.GetExpirationTime().Time).To(gomega.Equal(created.Add(time.Minute)))
+
+	isvc.Spec.TTLSecondsAfterCreation = nil
+	g.Expect(isvc.GetExpirationTime().Time).To(gomega.Equal(created.Add(time.Hour)))
+}
@@ -17,7 +17,12 @@ limitations under the License.
 package v1beta1
 
 import (
+	"fmt"
+	"sort"
+	"strings"
+
 	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"knative.dev/pkg/apis"
 	duckv1 "knative.dev/pkg/apis/duck/v1"
 	knservingv1 "knative.dev/serving/pkg/apis/serving/v1"
@@ -41,6 +46,39 @@ type InferenceServiceStatus struct {
 	URL *apis.URL `json:"url,omitempty"`
 	// Statuses for the components of the InferenceService
 	Components map[ComponentType]ComponentStatusSpec `json:"components,omitempty"`
+	// Statuses of a multi-cluster InferenceService in its member clusters
+	// +optional
+	Clusters map[string]ClusterStatus `json:"clusters,omitempty"`
+	// Approximate cost of the InferenceService, set when the cost estimation is configured
+	// +optional
+	Cost *CostStatus `json:"cost,omitempty"`
+	// Model version the model registry reference of the predictor was resolved to
+	// +optional
+	ResolvedModel *ResolvedModelStatus `json:"resolvedModel,omitempty"`
+	// Generation of the spec last admitted by the InferenceQuotas of the namespace
+	// +optional
+	AdmittedGeneration int64 `json:"admittedGeneration,omitempty"`
+}
+
+// CostStatus is the approximate cost of the resources requested by the InferenceService components
+type CostStatus struct {
+	// Hourly cost of the requested resources of all the component replicas, in the currency of the price table
+	HourlyCost string `json:"hourlyCost"`
+	// Currency of the price table
+	// +optional
+	Currency string `json:"currency,omitempty"`
+}
+
+// ClusterStatus describes the state of a multi-cluster InferenceService in one of its member clusters
+type ClusterStatus struct {
+	// Ready is true once the InferenceService is ready in the member cluster
+	Ready bool `json:"ready"`
+	// URL of the InferenceService in the member cluster
+	// +optional
+	URL *apis.URL `json:"url,omitempty"`
+	// Message explains why the InferenceService is not ready in the member cluster
+	// +optional
+	Message string `json:"message,omitempty"`
 }
 
 // ComponentStatusSpec describes the state of the component
@@ -54,6 +92,34 @@ type ComponentStatusSpec struct {
 	// Latest revision name that is in created
 	// +optional
 	LatestCreatedRevision string `json:"latestCreatedRevision,omitempty"`
+	// Latest revision name that answered the warm-up requests, the traffic is not shifted to the revisions which
+	// are not warmed up
+	// +optional
+	WarmedUpRevision string `json:"warmedUpRevision,omitempty"`
+	// Progress of the revision switch of a component with a rollout
+	// +optional
+	Rollout *RolloutStatus `json:"rollout,omitempty"`
+	// Latest ready revision the model metadata validating the inference requests was fetched from
+	// +optional
+	SchemaRevision string `json:"schemaRevision,omitempty"`
+	// Progress of the canary analysis of the latest ready revision
+	// +optional
+	CanaryAnalysis *CanaryAnalysisStatus `json:"canaryAnalysis,omitempty"`
+	// Last request rate forecast of a component with predictive scaling
+	// +optional
+	PredictiveScaling *PredictiveScalingStatus `json:"predictiveScaling,omitempty"`
+	// Last evaluation of the alert of the drift detector
+	// +optional
+	DriftAlert *DriftAlertStatus `json:"driftAlert,omitempty"`
+	// Last garbage collection of the revisions of a component with a revision retention
+	// +optional
+	RevisionCollection *RevisionCollectionStatus `json:"revisionCollection,omitempty"`
+	// Traffic split with the replica pool of the predictor
+	// +optional
+	Pool *PoolStatus `json:"pool,omitempty"`
+	// Pinned model versions of the predictor
+	// +optional
+	Versions []ModelVersionStatus `json:"versions,omitempty"`
 	// Traffic percent on the latest ready revision
 	// +optional
 	TrafficPercent *int64 `json:"trafficPercent,omitempty"`
@@ -64,6 +130,171 @@ type ComponentStatusSpec struct {
 	// Addressable endpoint for the InferenceService
 	// +optional
 	Address *duckv1.Addressable `json:"address,omitempty"`
+	// Total number of pods across the revisions of the component, reported for the scale subresource
+	// +optional
+	Replicas int32 `json:"replicas,omitempty"`
+	// Label selector of the component pods in serialized form, reported for the scale subresource
+	// +optional
+	Selector string `json:"selector,omitempty"`
+}
+
+// RolloutStatus reports the revision the traffic of the component is routed to while a new revision soaks and the
+// previous one drains
+type RolloutStatus struct {
+	// Latest ready revision name
+	// +optional
+	ReadyRevision string `json:"readyRevision,omitempty"`
+	// Time the latest ready revision was first observed ready, its soak starts then
+	// +optional
+	ReadyTime metav1.Time `json:"readyTime,omitempty"`
+	// Revision name the traffic of the latest revision is routed to, the latest ready revision once it soaked
+	// +optional
+	ServingRevision string `json:"servingRevision,omitempty"`
+	// Previous serving revision name, it stays routable until its drain period elapses
+	// +optional
+	DrainingRevision string `json:"drainingRevision,omitempty"`
+	// Time the traffic was shifted away from the draining revision
+	// +optional
+	DrainStartTime metav1.Time `json:"drainStartTime,omitempty"`
+}
+
+// PredictiveScalingStatus reports the request rate forecast and the minimum number of replicas it requires
+type PredictiveScalingStatus struct {
+	// Time of the forecast
+	ForecastTime metav1.Time `json:"forecastTime"`
+	// Peak request rate forecast within the lookahead, empty when no period of the request history was available
+	// +optional
+	RequestsPerSecond string `json:"requestsPerSecond,omitempty"`
+	// Minimum number of replicas serving the forecast request rate, capped by MaxReplicas
+	// +optional
+	MinReplicas int `json:"minReplicas,omitempty"`
+	// Error of the last forecast
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// RevisionCollectionStatus reports the last garbage collection of the revisions, the revisions are collected again
+// once the latest ready revision changes or the collection interval elapsed
+type RevisionCollectionStatus struct {
+	// Latest ready revision of the component when the revisions were collected
+	// +optional
+	LatestReadyRevision string `json:"latestReadyRevision,omitempty"`
+	// Time of the collection
+	CollectionTime metav1.Time `json:"collectionTime"`
+}
+
+// DriftAlertStatus reports the last evaluation of the drift alert, the alert is evaluated at most once per interval
+type DriftAlertStatus struct {
+	// Time of the evaluation
+	EvaluationTime metav1.Time `json:"evaluationTime"`
+	// Value of the alert query, empty when the query has not been answered yet
+	// +optional
+	Value string `json:"value,omitempty"`
+	// Error of the last evaluation
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// PoolStatus reports the readiness of the replica pool of the predictor and the percent of the traffic routed to it
+type PoolStatus struct {
+	// Latest ready revision of the pool, the traffic is only routed to the pool once it has a ready revision
+	// +optional
+	LatestReadyRevision string `json:"latestReadyRevision,omitempty"`
+	// Percent of the predictor traffic routed to the pool
+	// +optional
+	TrafficPercent int64 `json:"trafficPercent,omitempty"`
+	// Time of the last check of the request rate of the predictor with Overflow routing
+	// +optional
+	CheckTime metav1.Time `json:"checkTime,omitempty"`
+	// Request rate of the predictor and its pool at the last check
+	// +optional
+	RequestsPerSecond string `json:"requestsPerSecond,omitempty"`
+	// Error of the last check
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// ModelVersionStatus reports the readiness and the url of a pinned model version of the predictor
+type ModelVersionStatus struct {
+	// Name of the version
+	Name string `json:"name"`
+	// Latest ready revision of the version, the requests of the InferenceService url are only routed to the default
+	// version once it has a ready revision
+	// +optional
+	LatestReadyRevision string `json:"latestReadyRevision,omitempty"`
+	// URL of the version
+	// +optional
+	URL *apis.URL `json:"url,omitempty"`
+}
+
+// CanaryPhase is the state of the canary analysis of a revision
+type CanaryPhase string
+
+// CanaryPhase Enum
+const (
+	// CanaryProgressing routes the traffic percent of the current step to the candidate revision
+	CanaryProgressing CanaryPhase = "Progressing"
+	// CanaryPromoted routes all the traffic to the candidate revision
+	CanaryPromoted CanaryPhase = "Promoted"
+	// CanaryRolledBack routes all the traffic to the previous ready revision
+	CanaryRolledBack CanaryPhase = "RolledBack"
+)
+
+// CanaryAnalysisStatus reports the progressive rollout of a candidate revision
+type CanaryAnalysisStatus struct {
+	// Candidate revision name
+	Revision string `json:"revision"`
+	// State of the analysis
+	Phase CanaryPhase `json:"phase"`
+	// Index of the current traffic step
+	// +optional
+	Step int `json:"step,omitempty"`
+	// Number of consecutive failed checks
+	// +optional
+	FailedChecks int `json:"failedChecks,omitempty"`
+	// Time of the last check, or of the start of the analysis
+	// +optional
+	LastCheckTime metav1.Time `json:"lastCheckTime,omitempty"`
+	// Result of the last check
+	// +optional
+	Message string `json:"message,omitempty"`
+	// Stable revision name the metrics of the candidate revision are compared with
+	// +optional
+	StableRevision string `json:"stableRevision,omitempty"`
+	// Metrics of the last checks, the most recent last
+	// +optional
+	Reports []CanaryReport `json:"reports,omitempty"`
+}
+
+// CanaryReport records the metrics of a check of the canary analysis and the decision taken with them
+type CanaryReport struct {
+	// Time of the check
+	Time metav1.Time `json:"time"`
+	// Index of the traffic step the check was run at
+	// +optional
+	Step int `json:"step,omitempty"`
+	// True if all the metrics of the candidate revision were within their thresholds
+	Passed bool `json:"passed"`
+	// Values of the metrics for the candidate and the stable revisions
+	// +optional
+	Metrics []CanaryMetricReport `json:"metrics,omitempty"`
+}
+
+// CanaryMetricReport compares a metric of the candidate revision with the stable revision
+type CanaryMetricReport struct {
+	// Name of the metric
+	Name string `json:"name"`
+	// Value of the metric for the candidate revision, empty when the query failed
+	// +optional
+	Canary string `json:"canary,omitempty"`
+	// Value of the metric for the stable revision, empty when the query failed
+	// +optional
+	Stable string `json:"stable,omitempty"`
+	// Maximum value of the metric for the candidate revision
+	Threshold string `json:"threshold"`
+	// Error of the query of the candidate revision
+	// +optional
+	Error string `json:"error,omitempty"`
 }
 
 // ComponentType contains the different types of components of the service
@@ -74,6 +305,10 @@ const (
 	PredictorComponent   ComponentType = "predictor"
 	ExplainerComponent   ComponentType = "explainer"
 	TransformerComponent ComponentType = "transformer"
+	// DriftDetectorComponent is sent the payloads logged by the predictor
+	DriftDetectorComponent ComponentType = "driftDetector"
+	// OutlierDetectorComponent scores the requests of the predictor
+	OutlierDetectorComponent ComponentType = "outlierDetector"
 )
 
 // ConditionType represents a Service condition value
@@ -98,24 +333,96 @@ const (
 	ExplainerReady apis.ConditionType = "ExplainerReady"
 	// Ingress is created
 	IngressReady apis.ConditionType = "IngressReady"
+	// ChildResourceDrifted is set when generated resources were modified out of band and the changes are kept.
+	ChildResourceDrifted apis.ConditionType = "ChildResourceDrifted"
+	// DriftDetectorRouteReady is set when network configuration has completed.
+	DriftDetectorRouteReady apis.ConditionType = "DriftDetectorRouteReady"
+	// DriftDetectorConfigurationReady is set when drift detector pods are ready.
+	DriftDetectorConfigurationReady apis.ConditionType = "DriftDetectorConfigurationReady"
+	// DriftDetectorReady is set when drift detector has reported readiness
+	DriftDetectorReady apis.ConditionType = "DriftDetectorReady"
+	// OutlierDetectorRouteReady is set when network configuration has completed.
+	OutlierDetectorRouteReady apis.ConditionType = "OutlierDetectorRouteReady"
+	// OutlierDetectorConfigurationReady is set when outlier detector pods are ready.
+	OutlierDetectorConfigurationReady apis.ConditionType = "OutlierDetectorConfigurationReady"
+	// OutlierDetectorReady is set when outlier detector has reported readiness
+	OutlierDetectorReady apis.ConditionType = "OutlierDetectorReady"
+	// DriftDetected is set while the alert of the drift detector is firing
+	DriftDetected apis.ConditionType = "DriftDetected"
+	// Pending is set while the InferenceService waits for the capacity of an InferenceQuota of its namespace
+	Pending apis.ConditionType = "Pending"
+	// Expiring is set once the InferenceService is about to be deleted by its expiration
+	Expiring apis.ConditionType = "Expiring"
+	// PredictorWarmedUp is set when the latest ready revision of the predictor answered its warm-up requests
+	PredictorWarmedUp apis.ConditionType = "PredictorWarmedUp"
+	// TransformerWarmedUp is set when the latest ready revision of the transformer answered its warm-up requests
+	TransformerWarmedUp apis.ConditionType = "TransformerWarmedUp"
+	// ExplainerWarmedUp is set when the latest ready revision of the explainer answered its warm-up requests
+	ExplainerWarmedUp apis.ConditionType = "ExplainerWarmedUp"
+	// DriftDetectorWarmedUp is set when the latest ready revision of the drift detector answered its warm-up requests
+	DriftDetectorWarmedUp apis.ConditionType = "DriftDetectorWarmedUp"
+	// OutlierDetectorWarmedUp is set when the latest ready revision of the outlier detector answered its warm-up requests
+	OutlierDetectorWarmedUp apis.ConditionType = "OutlierDetectorWarmedUp"
 )
 
+// OutOfBandChangeReason is the reason of the ChildResourceDrifted condition
+const OutOfBandChangeReason = "OutOfBandChange"
+
+// PausedReason is the reason of the component conditions while the InferenceService is paused
+const PausedReason = "Paused"
+
+// DriftAlertReason is the reason of the DriftDetected condition
+const DriftAlertReason = "DriftAlert"
+
+// QuotaExceededReason is the reason of the Pending condition
+const QuotaExceededReason = "QuotaExceeded"
+
+// ExpiringReason is the reason of the Expiring condition and of the event warning about the expiration
+const ExpiringReason = "Expiring"
+
+// ExpiredReason is the reason of the event of an InferenceService deleted by its expiration
+const ExpiredReason = "Expired"
+
+// WarmingUpReason is the reason of the warm-up conditions while the warm-up requests are sent to a new revision
+const WarmingUpReason = "WarmingUp"
+
+// WarmUpFailedReason is the reason of the warm-up conditions when a new revision failed a warm-up request
+const WarmUpFailedReason = "WarmUpFailed"
+
+// MemberClustersNotReadyReason is the reason of the conditions of a multi-cluster InferenceService which is not
+// ready in all its member clusters
+const MemberClustersNotReadyReason = "MemberClustersNotReady"
+
 var conditionsMap = map[ComponentType]apis.ConditionType{
-	PredictorComponent:   PredictorReady,
-	ExplainerComponent:   ExplainerReady,
-	TransformerComponent: TransformerReady,
+	PredictorComponent:       PredictorReady,
+	ExplainerComponent:       ExplainerReady,
+	TransformerComponent:     TransformerReady,
+	DriftDetectorComponent:   DriftDetectorReady,
+	OutlierDetectorComponent: OutlierDetectorReady,
+}
+
+var warmUpConditionsMap = map[ComponentType]apis.ConditionType{
+	PredictorComponent:       PredictorWarmedUp,
+	ExplainerComponent:       ExplainerWarmedUp,
+	TransformerComponent:     TransformerWarmedUp,
+	DriftDetectorComponent:   DriftDetectorWarmedUp,
+	OutlierDetectorComponent: OutlierDetectorWarmedUp,
 }
 
 var routeConditionsMap = map[ComponentType]apis.ConditionType{
-	PredictorComponent:   PredictorRouteReady,
-	ExplainerComponent:   ExplainerRoutesReady,
-	TransformerComponent: TransformerRouteReady,
+	PredictorComponent:       PredictorRouteReady,
+	ExplainerComponent:       ExplainerRoutesReady,
+	TransformerComponent:     TransformerRouteReady,
+	DriftDetectorComponent:   DriftDetectorRouteReady,
+	OutlierDetectorComponent: OutlierDetectorRouteReady,
 }
 
 var configurationConditionsMap = map[ComponentType]apis.ConditionType{
-	PredictorComponent:   PredictorConfigurationReady,
-	ExplainerComponent:   ExplainerConfigurationReady,
-	TransformerComponent: TransformerConfigurationeReady,
+	PredictorComponent:       PredictorConfigurationReady,
+	ExplainerComponent:       ExplainerConfigurationReady,
+	TransformerComponent:     TransformerConfigurationeReady,
+	DriftDetectorComponent:   DriftDetectorConfigurationReady,
+	OutlierDetectorComponent: OutlierDetectorConfigurationReady,
 }
 
 // InferenceService Ready condition is depending on predictor and route readiness condition
@@ -189,6 +496,215 @@ func (ss *InferenceServiceStatus) PropagateStatus(component ComponentType, servi
 	ss.Components[component] = statusSpec
 }
 
+// ClearComponent removes the status and the conditions of a component which was removed from the spec
+func (ss *InferenceServiceStatus) ClearComponent(component ComponentType) {
+	delete(ss.Components, component)
+	for _, conditions := range []map[ComponentType]apis.ConditionType{conditionsMap, routeConditionsMap,
+		configurationConditionsMap, warmUpConditionsMap} {
+		if conditionType, ok := conditions[component]; ok {
+			_ = conditionSet.Manage(ss).ClearCondition(conditionType)
+		}
+	}
+}
+
+// PropagateModelMeshStatus reflects the status of the ModelMesh predictor which serves the predictor in the
+// ModelMesh deployment mode, the ModelMesh endpoint is the address of the InferenceService so it doubles as the ingress.
+func (ss *InferenceServiceStatus) PropagateModelMeshStatus(available bool, reason string, message string, url *apis.URL) {
+	if len(ss.Components) == 0 {
+		ss.Components = make(map[ComponentType]ComponentStatusSpec)
+	}
+	statusSpec := ss.Components[PredictorComponent]
+	if available {
+		ss.SetCondition(PredictorReady, &apis.Condition{
+			Status: v1.ConditionTrue,
+		})
+	} else {
+		ss.SetCondition(PredictorReady, &apis.Condition{
+			Status:  v1.ConditionFalse,
+			Reason:  reason,
+			Message: message,
+		})
+	}
+	if url != nil {
+		statusSpec.URL = url
+		statusSpec.Address = &duckv1.Addressable{URL: url}
+		ss.URL = url
+		ss.Address = &duckv1.Addressable{URL: url}
+		ss.SetCondition(IngressReady, &apis.Condition{
+			Status: v1.ConditionTrue,
+		})
+	} else {
+		ss.SetCondition(IngressReady, &apis.Condition{
+			Status: v1.ConditionUnknown,
+			Reason: "EndpointMissing",
+		})
+	}
+	ss.Components[PredictorComponent] = statusSpec
+}
+
+// PropagateClusterStatus reflects the statuses of a multi-cluster InferenceService in its member clusters, it is
+// ready once it is ready in all of them.
+func (ss *InferenceServiceStatus) PropagateClusterStatus(clusters map[string]ClusterStatus) {
+	ss.Clusters = clusters
+	notReady := []string{}
+	for name, cluster := range clusters {
+		if !cluster.Ready {
+			notReady = append(notReady, name)
+		}
+	}
+	sort.Strings(notReady)
+	switch {
+	case len(clusters) == 0:
+		for _, conditionType := range []apis.ConditionType{PredictorReady, IngressReady} {
+			ss.SetCondition(conditionType, &apis.Condition{
+				Status:  v1.ConditionFalse,
+				Reason:  MemberClustersNotReadyReason,
+				Message: "No member cluster is selected",
+			})
+		}
+	case len(notReady) > 0:
+		message := fmt.Sprintf("%d of %d member clusters are not ready: %s", len(notReady), len(clusters),
+			strings.Join(notReady, ", "))
+		for _, conditionType := range []apis.ConditionType{PredictorReady, IngressReady} {
+			ss.SetCondition(conditionType, &apis.Condition{
+				Status:  v1.ConditionFalse,
+				Reason:  MemberClustersNotReadyReason,
+				Message: message,
+			})
+		}
+	default:
+		ss.SetCondition(PredictorReady, &apis.Condition{Status: v1.ConditionTrue})
+		ss.SetCondition(IngressReady, &apis.Condition{Status: v1.ConditionTrue})
+	}
+}
+
+// PropagatePaused marks the component not ready as its knative service is removed while the InferenceService is paused.
+// The revisions are removed with the knative service, so the component status forgets them and the recreated knative
+// service starts from its first revision when the InferenceService is resumed, only the url is kept with the ingress.
+func (ss *InferenceServiceStatus) PropagatePaused(component ComponentType, message string) {
+	ss.SetCondition(conditionsMap[component], &apis.Condition{
+		Status:  v1.ConditionFalse,
+		Reason:  PausedReason,
+		Message: message,
+	})
+	if statusSpec, ok := ss.Components[component]; ok {
+		ss.Components[component] = ComponentStatusSpec{
+			PredictiveScaling: statusSpec.PredictiveScaling,
+			URL:               statusSpec.URL,
+			Address:           statusSpec.Address,
+			Selector:          statusSpec.Selector,
+		}
+	}
+}
+
+// PropagateDrift adds or removes the resource from the ChildResourceDrifted condition, the condition is removed
+// once none of the resources has drifted.
+func (ss *InferenceServiceStatus) PropagateDrift(resource string, drifted bool) {
+	resources := []string{}
+	if condition := ss.GetCondition(ChildResourceDrifted); condition != nil && condition.Message != "" {
+		for _, r := range strings.Split(condition.Message, ", ") {
+			if r != resource {
+				resources = append(resources, r)
+			}
+		}
+	}
+	if drifted {
+		resources = append(resources, resource)
+		sort.Strings(resources)
+	}
+	if len(resources) == 0 {
+		_ = conditionSet.Manage(ss).ClearCondition(ChildResourceDrifted)
+		return
+	}
+	conditionSet.Manage(ss).SetCondition(apis.Condition{
+		Type:     ChildResourceDrifted,
+		Status:   v1.ConditionTrue,
+		Severity: apis.ConditionSeverityWarning,
+		Reason:   OutOfBandChangeReason,
+		Message:  strings.Join(resources, ", "),
+	})
+}
+
+// PropagateDriftDetection sets the DriftDetected condition while the drift detector alert is firing, the condition is
+// removed once it stops firing
+func (ss *InferenceServiceStatus) PropagateDriftDetection(detected bool, message string) {
+	if !detected {
+		_ = conditionSet.Manage(ss).ClearCondition(DriftDetected)
+		return
+	}
+	conditionSet.Manage(ss).SetCondition(apis.Condition{
+		Type:     DriftDetected,
+		Status:   v1.ConditionTrue,
+		Severity: apis.ConditionSeverityWarning,
+		Reason:   DriftAlertReason,
+		Message:  message,
+	})
+}
+
+// PropagatePending sets the Pending condition and keeps the predictor not ready while the InferenceService waits for
+// quota, the condition is removed once the InferenceService is admitted. An InferenceService whose updated spec waits
+// for quota keeps serving the spec admitted before, so its predictor condition is left unchanged.
+func (ss *InferenceServiceStatus) PropagatePending(message string) {
+	if message == "" {
+		_ = conditionSet.Manage(ss).ClearCondition(Pending)
+		return
+	}
+	conditionSet.Manage(ss).SetCondition(apis.Condition{
+		Type:     Pending,
+		Status:   v1.ConditionTrue,
+		Severity: apis.ConditionSeverityInfo,
+		Reason:   QuotaExceededReason,
+		Message:  message,
+	})
+	if ss.AdmittedGeneration != 0 {
+		return
+	}
+	ss.SetCondition(PredictorReady, &apis.Condition{
+		Status:  v1.ConditionUnknown,
+		Reason:  QuotaExceededReason,
+		Message: message,
+	})
+}
+
+// PropagateWarmUp sets the warm-up condition of the component, the condition does not affect the readiness of the
+// InferenceService as the traffic stays on the previous revision until the new one is warmed up. An empty status
+// removes the condition.
+func (ss *InferenceServiceStatus) PropagateWarmUp(component ComponentType, status v1.ConditionStatus, message string) {
+	conditionType := warmUpConditionsMap[component]
+	condition := apis.Condition{
+		Type:     conditionType,
+		Status:   status,
+		Severity: apis.ConditionSeverityInfo,
+		Message:  message,
+	}
+	switch status {
+	case "":
+		_ = conditionSet.Manage(ss).ClearCondition(conditionType)
+		return
+	case v1.ConditionUnknown:
+		condition.Reason = WarmingUpReason
+	case v1.ConditionFalse:
+		condition.Reason = WarmUpFailedReason
+	}
+	conditionSet.Manage(ss).SetCondition(condition)
+}
+
+// PropagateExpiring sets the Expiring condition before the InferenceService expires, the condition is removed when
+// the expiration is moved out of the warning period
+func (ss *InferenceServiceStatus) PropagateExpiring(message string) {
+	if message == "" {
+		_ = conditionSet.Manage(ss).ClearCondition(Expiring)
+		return
+	}
+	conditionSet.Manage(ss).SetCondition(apis.Condition{
+		Type:     Expiring,
+		Status:   v1.ConditionTrue,
+		Severity: apis.ConditionSeverityWarning,
+		Reason:   ExpiringReason,
+		Message:  message,
+	})
+}
+
 func (ss *InferenceServiceStatus) SetCondition(conditionType apis.ConditionType, condition *apis.Condition) {
 	switch {
 	case condition == nil:
@@ -18,10 +18,12 @@ package v1beta1
 
 import (
 	"k8s.io/api/core/v1"
+	"knative.dev/pkg/apis"
 	"knative.dev/pkg/apis/duck"
 	duckv1 "knative.dev/pkg/apis/duck/v1"
 	duckv1beta1 "knative.dev/pkg/apis/duck/v1beta1"
 	knservingv1 "knative.dev/serving/pkg/apis/serving/v1"
+	"reflect"
 	"testing"
 )
 
@@ -172,3 +174,197 @@ func TestInferenceServiceIsReady(t *testing.T) {
 		})
 	}
 }
+
+func TestPropagateDrift(t *testing.T) {
+	status := &InferenceServiceStatus{}
+	status.InitializeConditions()
+
+	status.PropagateDrift("virtual service foo", true)
+	status.PropagateDrift("knative service foo-predictor-default", true)
+	condition := status.GetCondition(ChildResourceDrifted)
+	if condition == nil || condition.Status != v1.ConditionTrue ||
+		condition.Message != "knative service foo-predictor-default, virtual service foo" {
+		t.Errorf("PropagateDrift() = %v, wanted both resources drifted", condition)
+	}
+
+	status.PropagateDrift("virtual service foo", false)
+	condition = status.GetCondition(ChildResourceDrifted)
+	if condition == nil || condition.Message != "knative service foo-predictor-default" {
+		t.Errorf("PropagateDrift() = %v, wanted knative service drifted", condition)
+	}
+
+	status.PropagateDrift("knative service foo-predictor-default", false)
+	if condition := status.GetCondition(ChildResourceDrifted); condition != nil {
+		t.Errorf("PropagateDrift() = %v, wanted no drift condition", condition)
+	}
+}
+
+func TestPropagateDriftDetection(t *testing.T) {
+	status := &InferenceServiceStatus{}
+	status.InitializeConditions()
+
+	status.PropagateDriftDetection(true, "drift alert value 0.7, threshold 0.5")
+	condition := status.GetCondition(DriftDetected)
+	if condition == nil || condition.Status != v1.ConditionTrue || condition.Reason != DriftAlertReason {
+		t.Errorf("PropagateDriftDetection() = %v, wanted drift detected", condition)
+	}
+
+	status.PropagateDriftDetection(false, "")
+	if condition := status.GetCondition(DriftDetected); condition != nil {
+		t.Errorf("PropagateDriftDetection() = %v, wanted no drift condition", condition)
+	}
+}
+
+func TestPropagatePending(t *testing.T) {
+	status := &InferenceServiceStatus{}
+	status.InitializeConditions()
+
+	status.PropagatePending("InferenceQuota team allows 2 GPUs")
+	condition := status.GetCondition(Pending)
+	if condition == nil || condition.Status != v1.ConditionTrue || condition.Reason != QuotaExceededReason {
+		t.Errorf("PropagatePending() = %v, wanted pending", condition)
+	}
+	if status.GetCondition(PredictorReady).Reason != QuotaExceededReason || status.IsReady() {
+		t.Errorf("PropagatePending() = %v, wanted predictor not ready", status.Conditions)
+	}
+
+	status.PropagatePending("")
+	if condition := status.GetCondition(Pending); condition != nil {
+		t.Errorf("PropagatePending() = %v, wanted no pending condition", condition)
+	}
+
+	// an admitted InferenceService keeps serving while its update is pending
+	status.AdmittedGeneration = 1
+	status.SetCondition(PredictorReady, &apis.Condition{Status: v1.ConditionTrue})
+	status.SetCondition(IngressReady, &apis.Condition{Status: v1.ConditionTrue})
+	status.PropagatePending("InferenceQuota team allows 2 GPUs")
+	if status.GetCondition(Pending) == nil || !status.IsReady() {
+		t.Errorf("PropagatePending() = %v, wanted pending and ready", status.Conditions)
+	}
+}
+
+func TestPropagatePaused(t *testing.T) {
+	status := &InferenceServiceStatus{}
+	status.InitializeConditions()
+	url, _ := apis.ParseURL("http://sklearn-iris-predictor-default.default.example.com")
+	traffic := int64(100)
+	status.Components = map[ComponentType]ComponentStatusSpec{
+		PredictorComponent: {
+			LatestReadyRevision:   "sklearn-iris-predictor-default-00002",
+			PreviousReadyRevision: "sklearn-iris-predictor-default-00001",
+			LatestCreatedRevision: "sklearn-iris-predictor-default-00002",
+			WarmedUpRevision:      "sklearn-iris-predictor-default-00002",
+			Rollout:               &RolloutStatus{ReadyRevision: "sklearn-iris-predictor-default-00002"},
+			Versions:              []ModelVersionStatus{{Name: "v1", LatestReadyRevision: "sklearn-iris-predictor-version-v1-00001"}},
+			TrafficPercent:        &traffic,
+			URL:                   url,
+			Replicas:              2,
+		},
+	}
+
+	status.PropagatePaused(PredictorComponent, "InferenceService is paused")
+	if condition := status.GetCondition(PredictorReady); condition.Reason != PausedReason || status.IsReady() {
+		t.Errorf("PropagatePaused() = %v, wanted predictor paused", condition)
+	}
+	if predictor := status.Components[PredictorComponent]; !reflect.DeepEqual(predictor, ComponentStatusSpec{URL: url}) {
+		t.Errorf("PropagatePaused() = %v, wanted only the url kept", predictor)
+	}
+}
+
+func TestPropagateWarmUp(t *testing.T) {
+	status := &InferenceServiceStatus{}
+	status.InitializeConditions()
+	status.SetCondition(PredictorReady, &apis.Condition{Status: v1.ConditionTrue})
+	status.SetCondition(IngressReady, &apis.Condition{Status: v1.ConditionTrue})
+
+	status.PropagateWarmUp(PredictorComponent, v1.ConditionFalse, "warm-up request 1/2 failed: status 503")
+	condition := status.GetCondition(PredictorWarmedUp)
+	if condition == nil || condition.Reason != WarmUpFailedReason || condition.Severity != apis.ConditionSeverityInfo {
+		t.Errorf("PropagateWarmUp() = %v, wanted warm-up failed", condition)
+	}
+	if !status.IsReady() {
+		t.Errorf("PropagateWarmUp() = %v, wanted ready", status.Conditions)
+	}
+
+	status.PropagateWarmUp(PredictorComponent, "", "")
+	if condition := status.GetCondition(PredictorWarmedUp); condition != nil {
+		t.Errorf("PropagateWarmUp() = %v, wanted no warm-up condition", condition)
+	}
+}
+
+func TestClearComponent(t *testing.T) {
+	status := &InferenceServiceStatus{}
+	status.InitializeConditions()
+	status.SetCondition(PredictorReady, &apis.Condition{Status: v1.ConditionTrue})
+	status.SetCondition(IngressReady, &apis.Condition{Status: v1.ConditionTrue})
+	status.PropagateStatus(OutlierDetectorComponent, &knservingv1.ServiceStatus{
+		Status: duckv1.Status{
+			Conditions: duckv1.Conditions{
+				{Type: knservingv1.ServiceConditionReady, Status: v1.ConditionFalse},
+				{Type: "ConfigurationsReady", Status: v1.ConditionFalse},
+				{Type: "RoutesReady", Status: v1.ConditionFalse},
+			},
+		},
+	})
+	status.PropagateWarmUp(OutlierDetectorComponent, v1.ConditionUnknown, "")
+
+	status.ClearComponent(OutlierDetectorComponent)
+	if _, ok := status.Components[OutlierDetectorComponent]; ok {
+		t.Errorf("ClearComponent() = %v, wanted no outlier detector status", status.Components)
+	}
+	for _, conditionType := range []apis.ConditionType{OutlierDetectorReady, OutlierDetectorRouteReady,
+		OutlierDetectorConfigurationReady, OutlierDetectorWarmedUp} {
+		if condition := status.GetCondition(conditionType); condition != nil {
+			t.Errorf("ClearComponent() = %v, wanted no %s condition", condition, conditionType)
+		}
+	}
+	if !status.IsReady() {
+		t.Errorf("ClearComponent() = %v, wanted ready", status.Conditions)
+	}
+}
+
+func TestPropagateModelMeshStatus(t *testing.T) {
+	status := &InferenceServiceStatus{}
+	status.InitializeConditions()
+
+	status.PropagateModelMeshStatus(false, "Loading", "", nil)
+	if status.IsReady() || status.GetCondition(PredictorReady).Reason != "Loading" {
+		t.Errorf("PropagateModelMeshStatus() = %v, wanted predictor loading", status.Conditions)
+	}
+
+	url, _ := apis.ParseURL("http://modelmesh-serving.default:8008")
+	status.PropagateModelMeshStatus(true, "Loaded", "", url)
+	if !status.IsReady() {
+		t.Errorf("PropagateModelMeshStatus() = %v, wanted ready", status.Conditions)
+	}
+	if status.URL != url || status.Components[PredictorComponent].URL != url {
+		t.Errorf("PropagateModelMeshStatus() url = %v, wanted %v", status.URL, url)
+	}
+}
+
+func TestPropagateClusterStatus(t *testing.T) {
+	status := &InferenceServiceStatus{}
+	status.InitializeConditions()
+
+	status.PropagateClusterStatus(map[string]ClusterStatus{})
+	if status.IsReady() || status.GetCondition(PredictorReady).Reason != MemberClustersNotReadyReason {
+		t.Errorf("PropagateClusterStatus() = %v, wanted not ready without member clusters", status.Conditions)
+	}
+
+	status.PropagateClusterStatus(map[string]ClusterStatus{
+		"east": {Ready: true},
+		"west": {Ready: false, Message: "RevisionMissing"},
+	})
+	want := "1 of 2 member clusters are not ready: west"
+	if status.IsReady() || status.GetCondition(IngressReady).Message != want {
+		t.Errorf("PropagateClusterStatus() = %v, wanted %q", status.Conditions, want)
+	}
+
+	status.PropagateClusterStatus(map[string]ClusterStatus{
+		"east": {Ready: true},
+		"west": {Ready: true},
+	})
+	if !status.IsReady() || len(status.Clusters) != 2 {
+		t.Errorf("PropagateClusterStatus() = %v, wanted ready", status.Conditions)
+	}
+}
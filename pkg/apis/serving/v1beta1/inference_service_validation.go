@@ -17,12 +17,22 @@ limitations under the License.
 package v1beta1
 
 import (
+	"context"
 	"fmt"
 	"reflect"
+	"strings"
+	"time"
 
+	"github.com/kubeflow/kfserving/pkg/constants"
 	"github.com/kubeflow/kfserving/pkg/utils"
+	v1 "k8s.io/api/core/v1"
+	nodev1beta1 "k8s.io/api/node/v1beta1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"regexp"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 )
@@ -37,6 +47,9 @@ var (
 	validatorLogger = logf.Log.WithName("inferenceservice-v1beta1-validation-webhook")
 	// regular expressions for validation of isvc name
 	IsvcRegexp = regexp.MustCompile("^" + IsvcNameFmt + "$")
+	// ValidationReader reads the cluster resources referenced by an InferenceService, e.g. the priority classes,
+	// it is set by the manager and the references are not checked when it is nil.
+	ValidationReader client.Reader
 )
 
 // +kubebuilder:webhook:verbs=create;update,path=/validate-inferenceservices,mutating=false,failurePolicy=fail,groups=serving.kubeflow.org,resources=inferenceservices,versions=v1beta1,name=inferenceservice.kfserving-webhook-server.validator
@@ -46,14 +59,66 @@ var _ webhook.Validator = &InferenceService{}
 func (isvc *InferenceService) ValidateCreate() error {
 	validatorLogger.Info("validate create", "name", isvc.Name)
 
+	return isvc.validate(nil)
+}
+
+// validate validates the InferenceService, the old InferenceService is nil on create. The cluster resources referenced
+// by the InferenceService are only looked up when it is created or the reference changes.
+func (isvc *InferenceService) validate(old *InferenceService) error {
 	if err := validateInferenceServiceName(isvc); err != nil {
 		return err
 	}
 
+	if err := validateDeploymentMode(isvc); err != nil {
+		return err
+	}
+
+	if err := validateGPUSharing(isvc); err != nil {
+		return err
+	}
+
+	if err := validateModelRef(&isvc.Spec.Predictor); err != nil {
+		return err
+	}
+
+	if err := validateRouting(isvc); err != nil {
+		return err
+	}
+
+	if err := validateReplicaPool(isvc); err != nil {
+		return err
+	}
+
+	if err := validateEnsemble(isvc); err != nil {
+		return err
+	}
+
+	if err := validateStartup(isvc.Spec.Predictor.Startup); err != nil {
+		return err
+	}
+
+	if err := validateModelVersions(isvc); err != nil {
+		return err
+	}
+
+	if isvc.Spec.DriftDetector != nil {
+		if err := validateDetectorAlert(isvc.Spec.DriftDetector.Alert); err != nil {
+			return err
+		}
+	}
+
+	if isvc.Spec.OutlierDetector != nil {
+		if err := validateOutlierDetectionMode(isvc.Spec.OutlierDetector.Mode); err != nil {
+			return err
+		}
+	}
+
 	for _, component := range []Component{
 		&isvc.Spec.Predictor,
 		isvc.Spec.Transformer,
 		isvc.Spec.Explainer,
+		isvc.Spec.DriftDetector,
+		isvc.Spec.OutlierDetector,
 	} {
 		if !reflect.ValueOf(component).IsNil() {
 			if err := validateExactlyOneImplementation(component); err != nil {
@@ -62,11 +127,44 @@ func (isvc *InferenceService) ValidateCreate() error {
 			if err := utils.FirstNonNilError([]error{
 				component.GetImplementation().Validate(),
 				component.GetExtensions().Validate(),
+				validateGPUResources(component.GetImplementation()),
+				validateEnvironment(component.GetImplementation()),
 			}); err != nil {
 				return err
 			}
 		}
 	}
+	if err := validateTopologySpreadConstraints(isvc); err != nil {
+		return err
+	}
+	if err := validatePriorityClasses(isvc, old); err != nil {
+		return err
+	}
+	if err := validateRuntimeClasses(isvc, old); err != nil {
+		return err
+	}
+	if err := validateExpiration(isvc, old); err != nil {
+		return err
+	}
+	return validateInferenceServiceClass(isvc, old)
+}
+
+// validateExpiration rejects a negative TTL and an expireAt in the past. The expireAt is only checked on create or
+// when it changes, so an InferenceService which is about to be deleted on expiration can still be updated.
+func validateExpiration(isvc *InferenceService, old *InferenceService) error {
+	if isvc.Spec.TTLSecondsAfterCreation != nil && *isvc.Spec.TTLSecondsAfterCreation < 0 {
+		return fmt.Errorf(TTLLowerBoundError)
+	}
+	expireAt := isvc.Spec.ExpireAt
+	if expireAt == nil || isvc.DeletionTimestamp != nil {
+		return nil
+	}
+	if old != nil && old.Spec.ExpireAt != nil && old.Spec.ExpireAt.Equal(expireAt) {
+		return nil
+	}
+	if !expireAt.After(time.Now()) {
+		return fmt.Errorf(ExpireAtPastError, expireAt.UTC().Format(time.RFC3339))
+	}
 	return nil
 }
 
@@ -74,7 +172,14 @@ func (isvc *InferenceService) ValidateCreate() error {
 func (isvc *InferenceService) ValidateUpdate(old runtime.Object) error {
 	validatorLogger.Info("validate update", "name", isvc.Name)
 
-	return isvc.ValidateCreate()
+	oldIsvc, ok := old.(*InferenceService)
+	if !ok {
+		return isvc.validate(nil)
+	}
+	if err := validateDeploymentModeUpdate(isvc, oldIsvc); err != nil {
+		return err
+	}
+	return isvc.validate(oldIsvc)
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type
@@ -96,3 +201,191 @@ func validateInferenceServiceName(isvc *InferenceService) error {
 	}
 	return nil
 }
+
+// Validation of the deployment mode, the ModelMesh deployment mode only serves predictors ModelMesh has a runtime for
+func validateDeploymentMode(isvc *InferenceService) error {
+	mode, ok := isvc.Annotations[constants.DeploymentMode]
+	if !ok {
+		return nil
+	}
+	switch constants.DeploymentModeType(mode) {
+	case constants.Serverless:
+		return nil
+	case constants.ModelMeshDeployment:
+		if isvc.Spec.Transformer != nil || isvc.Spec.Explainer != nil || isvc.Spec.DriftDetector != nil ||
+			isvc.Spec.OutlierDetector != nil {
+			return fmt.Errorf(ModelMeshComponentsError)
+		}
+		predictor := isvc.Spec.Predictor
+		if predictor.PMML != nil || len(predictor.PodSpec.Containers) != 0 {
+			return fmt.Errorf(ModelMeshPredictorError)
+		}
+		for _, implementation := range predictor.GetImplementations() {
+			if storageURI := implementation.GetStorageUri(); storageURI == nil || !strings.HasPrefix(*storageURI, "s3://") {
+				return fmt.Errorf(ModelMeshPredictorError)
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf(InvalidDeploymentModeError, mode, strings.Join([]string{
+		string(constants.Serverless), string(constants.ModelMeshDeployment)}, ", "))
+}
+
+// Validation that the deployment mode is not changed, the resources of the previous deployment mode are not
+// removed by the controller
+func validateDeploymentModeUpdate(isvc *InferenceService, old *InferenceService) error {
+	mode, oldMode := deploymentMode(isvc), deploymentMode(old)
+	if mode != oldMode {
+		return fmt.Errorf(DeploymentModeImmutableError, oldMode, mode)
+	}
+	return nil
+}
+
+// deploymentMode returns the deployment mode selected by the annotation, Serverless by default
+func deploymentMode(isvc *InferenceService) constants.DeploymentModeType {
+	if mode, ok := isvc.Annotations[constants.DeploymentMode]; ok {
+		return constants.DeploymentModeType(mode)
+	}
+	return constants.Serverless
+}
+
+// Validation of the alert of a detector
+func validateDetectorAlert(alert *DetectorAlert) error {
+	if alert != nil && alert.Query == "" {
+		return fmt.Errorf(DetectorAlertQueryError)
+	}
+	return nil
+}
+
+// Validation of the outlier detection mode
+func validateOutlierDetectionMode(mode OutlierDetectionMode) error {
+	switch mode {
+	case "", OutlierDetectionAsync, OutlierDetectionInline:
+		return nil
+	}
+	return fmt.Errorf(InvalidOutlierDetectionModeError, mode, strings.Join([]string{string(OutlierDetectionAsync),
+		string(OutlierDetectionInline)}, ", "))
+}
+
+// Validation of the GPU sharing scheme
+func validateGPUSharing(isvc *InferenceService) error {
+	if sharing, ok := isvc.Annotations[constants.GPUSharingAnnotationKey]; ok && sharing != constants.GPUSharingTimeSlicing {
+		return fmt.Errorf(InvalidGPUSharingError, sharing, constants.GPUSharingTimeSlicing)
+	}
+	return nil
+}
+
+// componentPodSpecs returns the pod specs of the components of the InferenceService
+func (isvc *InferenceService) componentPodSpecs() map[ComponentType]*PodSpec {
+	podSpecs := map[ComponentType]*PodSpec{PredictorComponent: &isvc.Spec.Predictor.PodSpec}
+	if isvc.Spec.Transformer != nil {
+		podSpecs[TransformerComponent] = &isvc.Spec.Transformer.PodSpec
+	}
+	if isvc.Spec.Explainer != nil {
+		podSpecs[ExplainerComponent] = &isvc.Spec.Explainer.PodSpec
+	}
+	if isvc.Spec.DriftDetector != nil {
+		podSpecs[DriftDetectorComponent] = &isvc.Spec.DriftDetector.PodSpec
+	}
+	if isvc.Spec.OutlierDetector != nil {
+		podSpecs[OutlierDetectorComponent] = &isvc.Spec.OutlierDetector.PodSpec
+	}
+	return podSpecs
+}
+
+// Validation of the topology spread constraints of the components, after their defaults are set
+func validateTopologySpreadConstraints(isvc *InferenceService) error {
+	for _, podSpec := range isvc.componentPodSpecs() {
+		for _, constraint := range podSpec.TopologySpreadConstraints {
+			if constraint.TopologyKey == "" || constraint.MaxSkew < 1 ||
+				(constraint.WhenUnsatisfiable != v1.DoNotSchedule && constraint.WhenUnsatisfiable != v1.ScheduleAnyway) {
+				return fmt.Errorf(TopologySpreadConstraintError)
+			}
+		}
+	}
+	return nil
+}
+
+// lookupsEnabled returns if the cluster resources referenced by the InferenceService are looked up. They are not once
+// the InferenceService is deleted, the update removing its finalizer would otherwise be rejected after a referenced
+// resource was deleted.
+func lookupsEnabled(isvc *InferenceService) bool {
+	return ValidationReader != nil && isvc.DeletionTimestamp == nil
+}
+
+// Validation that the priority classes of the components exist, the pods of a component would otherwise be rejected
+// by the priority admission controller
+func validatePriorityClasses(isvc *InferenceService, old *InferenceService) error {
+	if !lookupsEnabled(isvc) {
+		return nil
+	}
+	podSpecs := isvc.componentPodSpecs()
+	oldPodSpecs := map[ComponentType]*PodSpec{}
+	if old != nil {
+		oldPodSpecs = old.componentPodSpecs()
+	}
+	for _, component := range []ComponentType{PredictorComponent, TransformerComponent, ExplainerComponent,
+		DriftDetectorComponent, OutlierDetectorComponent} {
+		podSpec, ok := podSpecs[component]
+		if !ok || podSpec.PriorityClassName == "" {
+			continue
+		}
+		if oldPodSpec, ok := oldPodSpecs[component]; ok && oldPodSpec.PriorityClassName == podSpec.PriorityClassName {
+			continue
+		}
+		priorityClass := &schedulingv1.PriorityClass{}
+		if err := ValidationReader.Get(context.TODO(), types.NamespacedName{Name: podSpec.PriorityClassName}, priorityClass); err != nil {
+			if apierr.IsNotFound(err) {
+				return fmt.Errorf(PriorityClassNotFoundError, podSpec.PriorityClassName, component)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// Validation that the InferenceServiceClass exists, its defaults would otherwise not be set
+func validateInferenceServiceClass(isvc *InferenceService, old *InferenceService) error {
+	if !lookupsEnabled(isvc) || (old != nil && old.Spec.ClassName == isvc.Spec.ClassName) {
+		return nil
+	}
+	if _, err := isvc.getInferenceServiceClass(); err != nil {
+		if apierr.IsNotFound(err) {
+			return fmt.Errorf(InferenceServiceClassNotFoundError, isvc.Spec.ClassName)
+		}
+		return err
+	}
+	return nil
+}
+
+// Validation that the runtime classes of the components exist, the pods of a component would otherwise be rejected
+// by the runtime class admission controller
+func validateRuntimeClasses(isvc *InferenceService, old *InferenceService) error {
+	if !lookupsEnabled(isvc) {
+		return nil
+	}
+	podSpecs := isvc.componentPodSpecs()
+	oldPodSpecs := map[ComponentType]*PodSpec{}
+	if old != nil {
+		oldPodSpecs = old.componentPodSpecs()
+	}
+	for _, component := range []ComponentType{PredictorComponent, TransformerComponent, ExplainerComponent,
+		DriftDetectorComponent, OutlierDetectorComponent} {
+		podSpec, ok := podSpecs[component]
+		if !ok || podSpec.RuntimeClassName == nil {
+			continue
+		}
+		if oldPodSpec, ok := oldPodSpecs[component]; ok && oldPodSpec.RuntimeClassName != nil &&
+			*oldPodSpec.RuntimeClassName == *podSpec.RuntimeClassName {
+			continue
+		}
+		runtimeClass := &nodev1beta1.RuntimeClass{}
+		if err := ValidationReader.Get(context.TODO(), types.NamespacedName{Name: *podSpec.RuntimeClassName}, runtimeClass); err != nil {
+			if apierr.IsNotFound(err) {
+				return fmt.Errorf(RuntimeClassNotFoundError, *podSpec.RuntimeClassName, component)
+			}
+			return err
+		}
+	}
+	return nil
+}
@@ -19,12 +19,19 @@ package v1beta1
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/golang/protobuf/proto"
+	"github.com/kubeflow/kfserving/pkg/constants"
 
 	"github.com/onsi/gomega"
 	v1 "k8s.io/api/core/v1"
+	nodev1beta1 "k8s.io/api/node/v1beta1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 func makeTestInferenceService() InferenceService {
@@ -257,3 +264,459 @@ func TestRejectBadNameIncludeDot(t *testing.T) {
 	isvc.Name = "abc.de"
 	g.Expect(isvc.ValidateCreate()).ShouldNot(gomega.Succeed())
 }
+
+func TestModelMeshDeploymentMode(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := makeTestInferenceService()
+	isvc.Annotations = map[string]string{constants.DeploymentMode: string(constants.ModelMeshDeployment)}
+	isvc.Spec.Predictor.Tensorflow.StorageURI = proto.String("s3://testbucket/testmodel")
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+
+	isvc.Spec.Predictor.Tensorflow.StorageURI = proto.String("gs://testbucket/testmodel")
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(ModelMeshPredictorError))
+
+	isvc.Spec.Predictor.Tensorflow.StorageURI = proto.String("s3://testbucket/testmodel")
+	isvc.Spec.Transformer = &TransformerSpec{}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(ModelMeshComponentsError))
+}
+
+func TestDeploymentModeUpdate(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	old := makeTestInferenceService()
+	isvc := old.DeepCopy()
+	isvc.Annotations = map[string]string{constants.DeploymentMode: string(constants.Serverless)}
+	g.Expect(isvc.ValidateUpdate(&old)).Should(gomega.Succeed())
+
+	isvc.Annotations[constants.DeploymentMode] = string(constants.ModelMeshDeployment)
+	isvc.Spec.Predictor.Tensorflow.StorageURI = proto.String("s3://testbucket/testmodel")
+	g.Expect(isvc.ValidateUpdate(&old)).Should(gomega.MatchError(fmt.Sprintf(DeploymentModeImmutableError,
+		constants.Serverless, constants.ModelMeshDeployment)))
+}
+
+func TestInvalidDeploymentMode(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := makeTestInferenceService()
+	isvc.Annotations = map[string]string{constants.DeploymentMode: "Raw"}
+	g.Expect(isvc.ValidateCreate()).ShouldNot(gomega.Succeed())
+}
+
+func TestBadScalingSchedules(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := makeTestInferenceService()
+	isvc.Spec.Predictor.ScalingSchedules = []ScalingSchedule{{Schedule: "0 8 * * 1-5", MinReplicas: 3}}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+	isvc.Spec.Predictor.ScalingSchedules = []ScalingSchedule{{Schedule: "0 25 * * *", MinReplicas: 3}}
+	g.Expect(isvc.ValidateCreate()).ShouldNot(gomega.Succeed())
+	isvc.Spec.Predictor.ScalingSchedules = []ScalingSchedule{{Schedule: "0 8 * *", MinReplicas: 3}}
+	g.Expect(isvc.ValidateCreate()).ShouldNot(gomega.Succeed())
+	isvc.Spec.Predictor.ScalingSchedules = []ScalingSchedule{{Schedule: "0 8 * * 1-5", MinReplicas: -1}}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(fmt.Sprintf(ScheduledMinReplicasError, "0 8 * * 1-5")))
+	isvc.Spec.Predictor.MaxReplicas = 2
+	isvc.Spec.Predictor.ScalingSchedules = []ScalingSchedule{{Schedule: "0 8 * * 1-5", MinReplicas: 3}}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(fmt.Sprintf(ScheduledMinReplicasError, "0 8 * * 1-5")))
+}
+
+func TestBadPlacementPolicy(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := makeTestInferenceService()
+	isvc.Spec.Predictor.PlacementPolicy = PreferSpotPlacement
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+	isvc.Spec.Predictor.PlacementPolicy = "Spot"
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(fmt.Sprintf(InvalidPlacementPolicyError, "Spot",
+		"OnDemand, PreferSpot")))
+}
+
+func TestBadWarmUp(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := makeTestInferenceService()
+	isvc.Spec.Predictor.WarmUp = &WarmUpSpec{URI: "https://example.com/sample.json", Requests: 3}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+	isvc.Spec.Predictor.WarmUp = &WarmUpSpec{}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(WarmUpPayloadError))
+	isvc.Spec.Predictor.WarmUp = &WarmUpSpec{URI: "https://example.com/sample.json", Requests: -1}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(WarmUpRequestsLowerBoundError))
+}
+
+func TestBadCanaryAnalysis(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := makeTestInferenceService()
+	isvc.Spec.Predictor.CanaryAnalysis = &CanaryAnalysis{
+		Steps:   []int64{10, 50},
+		Metrics: []CanaryMetric{{Name: "error-rate", Query: "vector(0)", Threshold: 0.01}},
+	}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+	isvc.Spec.Predictor.CanaryAnalysis.Steps = []int64{50, 10}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(CanaryAnalysisStepsError))
+	isvc.Spec.Predictor.CanaryAnalysis.Steps = []int64{100}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(CanaryAnalysisStepsError))
+	isvc.Spec.Predictor.CanaryAnalysis.Steps = []int64{10}
+	isvc.Spec.Predictor.CanaryAnalysis.Metrics = []CanaryMetric{{Name: "error-rate"}}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(CanaryAnalysisMetricError))
+}
+
+func TestGPUResources(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := makeTestInferenceService()
+	isvc.Spec.Predictor.Tensorflow.RuntimeVersion = proto.String("2.3.0-gpu")
+	isvc.Spec.Predictor.Tensorflow.Resources = v1.ResourceRequirements{
+		Limits:   v1.ResourceList{"nvidia.com/mig-3g.20gb": resource.MustParse("1")},
+		Requests: v1.ResourceList{"nvidia.com/mig-3g.20gb": resource.MustParse("1")},
+	}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+
+	isvc.Spec.Predictor.Tensorflow.Resources = v1.ResourceRequirements{
+		Limits: v1.ResourceList{"nvidia.com/mig-large": resource.MustParse("1")},
+	}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(fmt.Sprintf(InvalidMIGResourceError, "nvidia.com/mig-large")))
+
+	isvc.Spec.Predictor.Tensorflow.Resources = v1.ResourceRequirements{
+		Limits: v1.ResourceList{
+			"nvidia.com/mig-1g.5gb":         resource.MustParse("1"),
+			constants.NvidiaGPUResourceType: resource.MustParse("1"),
+		},
+	}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(fmt.Sprintf(MultipleGPUResourcesError,
+		"nvidia.com/gpu, nvidia.com/mig-1g.5gb")))
+
+	isvc.Spec.Predictor.Tensorflow.Resources = v1.ResourceRequirements{
+		Limits:   v1.ResourceList{constants.NvidiaGPUResourceType: resource.MustParse("2")},
+		Requests: v1.ResourceList{constants.NvidiaGPUResourceType: resource.MustParse("1")},
+	}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(fmt.Sprintf(GPUResourceRequestLimitError,
+		constants.NvidiaGPUResourceType)))
+
+	isvc.Spec.Predictor.Tensorflow.Resources = v1.ResourceRequirements{
+		Limits: v1.ResourceList{constants.NvidiaGPUResourceType: resource.MustParse("1")},
+	}
+	isvc.Annotations = map[string]string{constants.GPUSharingAnnotationKey: "mps"}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(fmt.Sprintf(InvalidGPUSharingError, "mps",
+		constants.GPUSharingTimeSlicing)))
+	isvc.Annotations[constants.GPUSharingAnnotationKey] = constants.GPUSharingTimeSlicing
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+}
+
+func TestPriorityClasses(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	ValidationReader = fake.NewFakeClientWithScheme(clientgoscheme.Scheme, &schedulingv1.PriorityClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "production"},
+		Value:      1000,
+	})
+	defer func() { ValidationReader = nil }()
+
+	isvc := makeTestInferenceService()
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+	isvc.Spec.Predictor.PriorityClassName = "production"
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+	isvc.Spec.Transformer = &TransformerSpec{
+		PodSpec: PodSpec{
+			Containers:        []v1.Container{{Image: "some-image"}},
+			PriorityClassName: "experimental",
+		},
+	}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(fmt.Sprintf(PriorityClassNotFoundError, "experimental",
+		TransformerComponent)))
+
+	// the priority class is only looked up when it changes
+	old := isvc.DeepCopy()
+	isvc.Spec.Transformer.MinReplicas = GetIntReference(2)
+	g.Expect(isvc.ValidateUpdate(old)).Should(gomega.Succeed())
+	isvc.Spec.Predictor.PriorityClassName = "batch"
+	g.Expect(isvc.ValidateUpdate(old)).Should(gomega.MatchError(fmt.Sprintf(PriorityClassNotFoundError, "batch",
+		PredictorComponent)))
+
+	// the finalizer of a deleted InferenceService is removed after its priority class was deleted
+	isvc.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	g.Expect(isvc.ValidateUpdate(old)).Should(gomega.Succeed())
+}
+
+func TestRuntimeClasses(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	ValidationReader = fake.NewFakeClientWithScheme(clientgoscheme.Scheme, &nodev1beta1.RuntimeClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "gvisor"},
+		Handler:    "runsc",
+	})
+	defer func() { ValidationReader = nil }()
+
+	isvc := makeTestInferenceService()
+	isvc.Spec.Predictor.RuntimeClassName = proto.String("gvisor")
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+	isvc.Spec.Transformer = &TransformerSpec{
+		PodSpec: PodSpec{
+			Containers:       []v1.Container{{Image: "some-image"}},
+			RuntimeClassName: proto.String("kata"),
+		},
+	}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(fmt.Sprintf(RuntimeClassNotFoundError, "kata",
+		TransformerComponent)))
+
+	// the runtime class is only looked up when it changes
+	old := isvc.DeepCopy()
+	isvc.Spec.Transformer.MinReplicas = GetIntReference(2)
+	g.Expect(isvc.ValidateUpdate(old)).Should(gomega.Succeed())
+	isvc.Spec.Predictor.RuntimeClassName = proto.String("kata")
+	g.Expect(isvc.ValidateUpdate(old)).Should(gomega.MatchError(fmt.Sprintf(RuntimeClassNotFoundError, "kata",
+		PredictorComponent)))
+
+	// the finalizer of a deleted InferenceService is removed after its runtime class was deleted
+	isvc.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	g.Expect(isvc.ValidateUpdate(old)).Should(gomega.Succeed())
+}
+
+func TestBadDriftDetector(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := makeTestInferenceService()
+	isvc.Spec.DriftDetector = &DriftDetectorSpec{
+		Alibi: &AlibiDriftDetectorSpec{StorageURI: "s3://detectors/cifar10"},
+		Alert: &DetectorAlert{Query: "drift_score", Threshold: 0.5},
+	}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+	isvc.Spec.DriftDetector.Alert = &DetectorAlert{Threshold: 0.5}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(DetectorAlertQueryError))
+	isvc.Spec.DriftDetector = &DriftDetectorSpec{}
+	g.Expect(isvc.ValidateCreate()).ShouldNot(gomega.Succeed())
+}
+
+func TestBadOutlierDetector(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := makeTestInferenceService()
+	isvc.Spec.OutlierDetector = &OutlierDetectorSpec{
+		Alibi: &AlibiOutlierDetectorSpec{StorageURI: "s3://detectors/vae"},
+		Mode:  OutlierDetectionInline,
+	}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+	isvc.Spec.OutlierDetector.Mode = "Sync"
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(fmt.Sprintf(InvalidOutlierDetectionModeError, "Sync",
+		"Async, Inline")))
+}
+
+func TestBadModelRef(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := makeTestInferenceService()
+	isvc.Spec.Predictor.Tensorflow.ModelRef = &ModelRegistryRef{Registry: "mlflow", Model: "flowers"}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(ModelRefStorageURIError))
+	isvc.Spec.Predictor.Tensorflow.StorageURI = nil
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+	isvc.Spec.Predictor.Tensorflow.ModelRef.Model = ""
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(ModelRefError))
+}
+
+func TestBadRouting(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := makeTestInferenceService()
+	isvc.Spec.Routing = &RoutingSpec{Retries: &RetryPolicy{Attempts: 3, PerTryTimeoutSeconds: 2}, Fallback: "flowers-stable"}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+	isvc.Spec.Routing.Retries.Attempts = -1
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(RetryPolicyLowerBoundError))
+	isvc.Spec.Routing.Retries.Attempts = 3
+	isvc.Spec.Routing.Fallback = isvc.Name
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(fmt.Sprintf(InvalidFallbackError, isvc.Name)))
+}
+
+func TestBadSessionAffinity(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := makeTestInferenceService()
+	isvc.Spec.Routing = &RoutingSpec{SessionAffinity: &SessionAffinitySpec{Header: "x-session-id"}}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+	isvc.Spec.Routing.SessionAffinity.Cookie = &SessionCookie{Name: "session", TTLSeconds: 3600}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(SessionAffinityError))
+	isvc.Spec.Routing.SessionAffinity.Header = ""
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+	isvc.Spec.Routing.SessionAffinity.Cookie.Name = ""
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(SessionAffinityError))
+}
+
+func TestBadRollout(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := makeTestInferenceService()
+	isvc.Spec.Predictor.Rollout = &RolloutSpec{SoakSeconds: 60, DrainSeconds: 30}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+	isvc.Spec.Predictor.Rollout = &RolloutSpec{DrainSeconds: -1}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(RolloutLowerBoundError))
+}
+
+func TestBadRevisionRetention(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := makeTestInferenceService()
+	isvc.Spec.Predictor.RevisionRetention = &RevisionRetentionSpec{}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+	isvc.Spec.Predictor.RevisionRetention = &RevisionRetentionSpec{KeepReadyRevisions: GetIntReference(-1)}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(RevisionRetentionLowerBoundError))
+}
+
+func TestBadPredictiveScaling(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := makeTestInferenceService()
+	isvc.Spec.Predictor.PredictiveScaling = &PredictiveScalingSpec{TargetRequestsPerSecond: 10}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+	isvc.Spec.Predictor.PredictiveScaling = &PredictiveScalingSpec{}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(PredictiveScalingTargetError))
+	isvc.Spec.Predictor.PredictiveScaling = &PredictiveScalingSpec{TargetRequestsPerSecond: 10, PeriodSeconds: 600}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(PredictiveScalingWindowError))
+}
+
+func TestBadReplicaPool(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := makeTestInferenceService()
+	isvc.Spec.Predictor.Pool = &ReplicaPoolSpec{CapacityRequestsPerSecond: 100}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+	isvc.Spec.Predictor.Pool = &ReplicaPoolSpec{}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(ReplicaPoolCapacityError))
+	isvc.Spec.Predictor.Pool = &ReplicaPoolSpec{Routing: WeightedPoolRouting, Weight: 120}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(ReplicaPoolWeightError))
+	isvc.Spec.Predictor.Pool = &ReplicaPoolSpec{Routing: WeightedPoolRouting, Weight: 20}
+	isvc.Spec.Transformer = &TransformerSpec{}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(ReplicaPoolTransformerError))
+}
+
+func TestBadEnsemble(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := makeTestInferenceService()
+	isvc.Spec.Predictor.Tensorflow = nil
+	isvc.Spec.Predictor.SKLearn = &SKLearnSpec{}
+	isvc.Spec.Predictor.Ensemble = &EnsembleSpec{
+		Strategy: MajorityVoteEnsemble,
+		Models: []EnsembleModelSpec{
+			{Name: "forest", StorageURI: "gs://testbucket/forest"},
+			{Name: "linear", StorageURI: "gs://testbucket/linear", Weight: proto.Int64(2)},
+		},
+	}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+	isvc.Spec.Predictor.Ensemble.Models[1].Weight = proto.Int64(-1)
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(EnsembleWeightError))
+	isvc.Spec.Predictor.Ensemble.Models[1].Name = "forest"
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(fmt.Sprintf(InvalidEnsembleModelNameError, "forest")))
+	isvc.Spec.Predictor.Ensemble.Models = isvc.Spec.Predictor.Ensemble.Models[:1]
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(EnsembleModelsError))
+	isvc.Spec.Predictor.SKLearn.StorageURI = proto.String("gs://testbucket/testmodel")
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(EnsemblePredictorError))
+}
+
+func TestBadLoggerRetries(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := makeTestInferenceService()
+	isvc.Spec.Predictor.Logger = &LoggerSpec{Mode: LogAll, Retries: GetIntReference(3),
+		DeadLetterURL: proto.String("http://dead-letter.default")}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+	isvc.Spec.Predictor.Logger.Retries = GetIntReference(-1)
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(LoggerRetriesLowerBoundError))
+}
+
+func TestBadEnvironment(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := makeTestInferenceService()
+	isvc.Spec.Predictor.Tensorflow.Env = []v1.EnvVar{{
+		Name: "API_KEY",
+		ValueFrom: &v1.EnvVarSource{SecretKeyRef: &v1.SecretKeySelector{
+			LocalObjectReference: v1.LocalObjectReference{Name: "model-secrets"}, Key: "apiKey"}},
+	}}
+	isvc.Spec.Predictor.Tensorflow.EnvFrom = []v1.EnvFromSource{{
+		ConfigMapRef: &v1.ConfigMapEnvSource{LocalObjectReference: v1.LocalObjectReference{Name: "model-config"}},
+	}}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+	isvc.Spec.Predictor.Tensorflow.EnvFrom[0].SecretRef = &v1.SecretEnvSource{}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(EnvFromSourceError))
+	isvc.Spec.Predictor.Tensorflow.EnvFrom = nil
+	isvc.Spec.Predictor.Tensorflow.Env[0].ValueFrom.SecretKeyRef.Key = ""
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(fmt.Sprintf(EnvKeyRefError, "API_KEY")))
+	isvc.Spec.Predictor.Tensorflow.Env[0].ValueFrom.FieldRef = &v1.ObjectFieldSelector{FieldPath: "metadata.name"}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(fmt.Sprintf(EnvValueSourceError, "API_KEY")))
+	isvc.Spec.Predictor.Tensorflow.Env[0].Value = "secret"
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(fmt.Sprintf(EnvValueFromError, "API_KEY")))
+
+	isvc = makeTestInferenceService()
+	isvc.Spec.Transformer = &TransformerSpec{
+		PodSpec: PodSpec{
+			Containers: []v1.Container{{
+				Image:   "transformer:v1",
+				EnvFrom: []v1.EnvFromSource{{SecretRef: &v1.SecretEnvSource{}}},
+			}},
+		},
+	}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(EnvFromSourceError))
+}
+
+func TestBadTopologySpreadConstraints(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := makeTestInferenceService()
+	isvc.Spec.Predictor.TopologySpreadConstraints = []v1.TopologySpreadConstraint{{}}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(TopologySpreadConstraintError))
+	isvc.setTopologySpreadDefaults()
+	g.Expect(isvc.Spec.Predictor.TopologySpreadConstraints).To(gomega.Equal([]v1.TopologySpreadConstraint{{
+		MaxSkew:           1,
+		TopologyKey:       v1.LabelZoneFailureDomainStable,
+		WhenUnsatisfiable: v1.ScheduleAnyway,
+	}}))
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+	isvc.Spec.Predictor.TopologySpreadConstraints[0].WhenUnsatisfiable = "Ignore"
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(TopologySpreadConstraintError))
+}
+
+func TestBadStartup(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := makeTestInferenceService()
+	modelSize := resource.MustParse("40Gi")
+	isvc.Spec.Predictor.Startup = &StartupSpec{ModelSize: &modelSize}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+	isvc.Spec.Predictor.Startup.TimeoutSeconds = proto.Int64(-1)
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(StartupLowerBoundError))
+}
+
+func TestBadPreStopSleep(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := makeTestInferenceService()
+	isvc.Spec.Predictor.PreStopSleepSeconds = proto.Int64(15)
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+	isvc.Spec.Predictor.PreStopSleepSeconds = proto.Int64(-1)
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(PreStopSleepLowerBoundError))
+}
+
+func TestBadModelVersions(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := makeTestInferenceService()
+	isvc.Spec.Predictor.Versions = []ModelVersionSpec{
+		{Name: "v2", StorageURI: "gs://testbucket/testmodel/2"},
+		{Name: "v3", StorageURI: "gs://testbucket/testmodel/3"},
+	}
+	isvc.Spec.Predictor.DefaultVersion = "v3"
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+	isvc.Spec.Predictor.DefaultVersion = "v4"
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(fmt.Sprintf(DefaultModelVersionError, "v4")))
+	isvc.Spec.Predictor.DefaultVersion = ""
+	isvc.Spec.Predictor.Versions[1].Name = "v2"
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(fmt.Sprintf(InvalidModelVersionNameError, "v2")))
+	isvc.Spec.Predictor.Versions[1] = ModelVersionSpec{Name: "v3"}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(fmt.Sprintf(ModelVersionStorageURIError, "v3")))
+	isvc.Spec.Predictor.Versions = isvc.Spec.Predictor.Versions[:1]
+	isvc.Spec.Transformer = &TransformerSpec{PodSpec: PodSpec{Containers: []v1.Container{{Image: "transformer:v1"}}}}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(ModelVersionRoutingError))
+}
+
+func TestBadTTL(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := makeTestInferenceService()
+	isvc.Spec.TTLSecondsAfterCreation = proto.Int64(3600)
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+	isvc.Spec.TTLSecondsAfterCreation = proto.Int64(-1)
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(TTLLowerBoundError))
+	g.Expect(isvc.ValidateUpdate(makeTestInferenceService())).Should(gomega.MatchError(TTLLowerBoundError))
+}
+
+func TestBadExpireAt(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := makeTestInferenceService()
+	expireAt := metav1.NewTime(time.Now().Add(time.Hour))
+	isvc.Spec.ExpireAt = &expireAt
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+
+	expired := metav1.NewTime(time.Date(2020, 10, 1, 8, 0, 0, 0, time.UTC))
+	isvc.Spec.ExpireAt = &expired
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(fmt.Sprintf(ExpireAtPastError, "2020-10-01T08:00:00Z")))
+	g.Expect(isvc.ValidateUpdate(makeTestInferenceService())).Should(
+		gomega.MatchError(fmt.Sprintf(ExpireAtPastError, "2020-10-01T08:00:00Z")))
+
+	// the InferenceService can still be updated once its unchanged expireAt has passed
+	old := isvc.DeepCopy()
+	g.Expect(isvc.ValidateUpdate(old)).Should(gomega.Succeed())
+	isvc.Spec.ExpireAt = nil
+	g.Expect(isvc.ValidateUpdate(old)).Should(gomega.Succeed())
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ModelRegistryRef references a version of a model of a model registry
+type ModelRegistryRef struct {
+	// Name of the registry in the registries of the inferenceservice configmap
+	Registry string `json:"registry"`
+	// Name of the registered model
+	Model string `json:"model"`
+	// Version of the model, the latest version is served and followed when it is not set
+	// +optional
+	Version string `json:"version,omitempty"`
+}
+
+// ResolvedModelStatus is the model version a model registry reference was resolved to
+type ResolvedModelStatus struct {
+	// Name of the registry the reference was resolved with
+	Registry string `json:"registry"`
+	// Name of the registered model
+	Model string `json:"model"`
+	// Resolved version of the model
+	Version string `json:"version"`
+	// Storage URI of the artifact of the model version
+	StorageURI string `json:"storageUri"`
+	// Time of the resolution, a reference to the latest version is resolved again once the refresh interval elapsed
+	// +optional
+	ResolveTime metav1.Time `json:"resolveTime,omitempty"`
+}
+
+// Matches returns true if the status is a resolution of the reference, a reference to the latest version matches
+// any version of the model
+func (s *ResolvedModelStatus) Matches(ref *ModelRegistryRef) bool {
+	return s != nil && ref != nil && s.Registry == ref.Registry && s.Model == ref.Model &&
+		(ref.Version == "" || ref.Version == s.Version)
+}
+
+// Validation of the model registry reference of the predictor
+func validateModelRef(predictor *PredictorSpec) error {
+	extensions := predictor.GetPredictorExtensions()
+	if extensions == nil || extensions.ModelRef == nil {
+		return nil
+	}
+	if extensions.StorageURI != nil {
+		return fmt.Errorf(ModelRefStorageURIError)
+	}
+	if extensions.ModelRef.Registry == "" || extensions.ModelRef.Model == "" {
+		return fmt.Errorf(ModelRefError)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+// OutlierDetectionMode defines when the requests of the predictor are scored by the outlier detector
+// +kubebuilder:validation:Enum=Async;Inline
+type OutlierDetectionMode string
+
+// OutlierDetectionMode enums
+const (
+	// The requests logged by the predictor are scored asynchronously
+	OutlierDetectionAsync OutlierDetectionMode = "Async"
+	// The requests are scored before the predictor responds, the response is tagged with the outlier header
+	OutlierDetectionInline OutlierDetectionMode = "Inline"
+)
+
+// OutlierDetectorSpec defines the outlier detection service which scores the requests of the predictor.
+// The following fields follow a "1-of" semantic. Users must specify exactly one spec.
+type OutlierDetectorSpec struct {
+	// Spec for the Alibi Detect outlier detector
+	Alibi *AlibiOutlierDetectorSpec `json:"alibi,omitempty"`
+	// Mode of the detection, defaults to Async where the requests are logged to the detector. In Inline mode the
+	// predictor waits for the detector and sets the X-Outlier header of its responses.
+	// +optional
+	Mode OutlierDetectionMode `json:"mode,omitempty"`
+	// This spec is dual purpose.
+	// 1) Users may choose to provide a full PodSpec for their custom outlier detector.
+	// The field PodSpec.Containers is mutually exclusive with other detectors (i.e. Alibi).
+	// 2) Users may choose to provide a detector (i.e. Alibi) and specify PodSpec
+	// overrides in the PodSpec. They must not provide PodSpec.Containers in this case.
+	PodSpec `json:",inline"`
+	// Extensions available in all components
+	ComponentExtensionSpec `json:",inline"`
+}
+
+var _ Component = &OutlierDetectorSpec{}
+
+// GetImplementations returns the implementations for the component
+func (s *OutlierDetectorSpec) GetImplementations() []ComponentImplementation {
+	implementations := NonNilComponents([]ComponentImplementation{
+		s.Alibi,
+	})
+	// This struct is not a pointer, so it will never be nil; include if containers are specified
+	if len(s.PodSpec.Containers) != 0 {
+		implementations = append(implementations, NewCustomDetector(&s.PodSpec))
+	}
+	return implementations
+}
+
+// GetImplementation returns the implementation for the component
+func (s *OutlierDetectorSpec) GetImplementation() ComponentImplementation {
+	return s.GetImplementations()[0]
+}
+
+// GetExtensions returns the extensions for the component
+func (s *OutlierDetectorSpec) GetExtensions() *ComponentExtensionSpec {
+	return &s.ComponentExtensionSpec
+}
+
+// IsInline returns true if the requests are scored before the predictor responds
+func (s *OutlierDetectorSpec) IsInline() bool {
+	return s.Mode == OutlierDetectionInline
+}
@@ -38,6 +38,32 @@ type PredictorSpec struct {
 	ONNX *ONNXRuntimeSpec `json:"onnx,omitempty"`
 	// Spec for PMML
 	PMML *PMMLSpec `json:"pmml,omitempty"`
+	// Validates the v2 protocol inference requests against the model metadata fetched from the latest ready
+	// revision, the malformed requests are rejected with 400 before they reach the model server. The metadata is
+	// exposed at /v2/models/{name}/schema.
+	// +optional
+	RequestValidation bool `json:"requestValidation,omitempty"`
+	// Secondary pool of replicas the ingress routes part of the predictor traffic to, e.g. CPU replicas serving
+	// the traffic beyond the capacity of GPU replicas
+	// +optional
+	Pool *ReplicaPoolSpec `json:"pool,omitempty"`
+	// Models served behind the endpoint of the predictor with their predictions combined, the predictor must be a
+	// multi-model server without a storageUri
+	// +optional
+	Ensemble *EnsembleSpec `json:"ensemble,omitempty"`
+	// Startup probe of the model server container, it holds off the liveness probe until the model server loaded the
+	// model so that it is not restarted while it loads a large model. A startup probe is generated for the predictors
+	// with a startup spec or a liveness probe, unless the container sets its own.
+	// +optional
+	Startup *StartupSpec `json:"startup,omitempty"`
+	// Pinned versions of the model served next to the predictor model, each one at the /versions/<name> path of the
+	// InferenceService url
+	// +optional
+	Versions []ModelVersionSpec `json:"versions,omitempty"`
+	// Version receiving the requests of the InferenceService url which do not name a version, defaults to the model
+	// of the predictor
+	// +optional
+	DefaultVersion string `json:"defaultVersion,omitempty"`
 	// This spec is dual purpose.
 	// 1) Users may choose to provide a full PodSpec for their predictor.
 	// The field PodSpec.Containers is mutually exclusive with other Predictors (i.e. TFServing).
@@ -55,6 +81,10 @@ type PredictorExtensionSpec struct {
 	// This field points to the location of the trained model which is mounted onto the pod.
 	// +optional
 	StorageURI *string `json:"storageUri,omitempty"`
+	// Reference to a model version of a model registry, the controller resolves it to the storage URI of the model
+	// artifact. Mutually exclusive with storageUri.
+	// +optional
+	ModelRef *ModelRegistryRef `json:"modelRef,omitempty"`
 	// Runtime version of the predictor docker image
 	// +optional
 	RuntimeVersion *string `json:"runtimeVersion,omitempty"`
@@ -93,3 +123,25 @@ func (s *PredictorSpec) GetImplementation() ComponentImplementation {
 func (s *PredictorSpec) GetExtensions() *ComponentExtensionSpec {
 	return &s.ComponentExtensionSpec
 }
+
+// GetPredictorExtensions returns the configuration shared across the predictor frameworks, or nil for a custom
+// predictor
+func (s *PredictorSpec) GetPredictorExtensions() *PredictorExtensionSpec {
+	switch {
+	case s.SKLearn != nil:
+		return &s.SKLearn.PredictorExtensionSpec
+	case s.XGBoost != nil:
+		return &s.XGBoost.PredictorExtensionSpec
+	case s.Tensorflow != nil:
+		return &s.Tensorflow.PredictorExtensionSpec
+	case s.PyTorch != nil:
+		return &s.PyTorch.PredictorExtensionSpec
+	case s.Triton != nil:
+		return &s.Triton.PredictorExtensionSpec
+	case s.ONNX != nil:
+		return &s.ONNX.PredictorExtensionSpec
+	case s.PMML != nil:
+		return &s.PMML.PredictorExtensionSpec
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// EnsembleStrategy selects how the predictions of the ensemble models are combined
+// +kubebuilder:validation:Enum=WeightedAverage;MajorityVote
+type EnsembleStrategy string
+
+// EnsembleStrategy Enum
+const (
+	// WeightedAverageEnsemble averages the numeric predictions of the models with their weights
+	WeightedAverageEnsemble EnsembleStrategy = "WeightedAverage"
+	// MajorityVoteEnsemble returns the prediction with the largest sum of the weights of the models voting for it
+	MajorityVoteEnsemble EnsembleStrategy = "MajorityVote"
+)
+
+// EnsembleSpec defines the models served by the predictor behind a single endpoint. The models are loaded in the
+// multi-model server of the predictor, the predict requests of the InferenceService are sent to each model and the
+// predictions are combined by the logger sidecar.
+type EnsembleSpec struct {
+	// Strategy combining the predictions of the models, WeightedAverage or MajorityVote. Defaults to WeightedAverage.
+	// +optional
+	Strategy EnsembleStrategy `json:"strategy,omitempty"`
+	// Models of the ensemble
+	Models []EnsembleModelSpec `json:"models"`
+}
+
+// EnsembleModelSpec defines a model of the ensemble
+type EnsembleModelSpec struct {
+	// Name of the model in the model server
+	Name string `json:"name"`
+	// Storage URI of the model artifact
+	StorageURI string `json:"storageUri"`
+	// Maximum memory the model consumes in the model server
+	// +optional
+	Memory resource.Quantity `json:"memory,omitempty"`
+	// Weight of the predictions of the model, defaults to 1
+	// +optional
+	Weight *int64 `json:"weight,omitempty"`
+}
+
+// GetStrategy returns the strategy of the ensemble, WeightedAverage by default
+func (s *EnsembleSpec) GetStrategy() EnsembleStrategy {
+	if s.Strategy == "" {
+		return WeightedAverageEnsemble
+	}
+	return s.Strategy
+}
+
+// GetWeight returns the weight of the model, 1 by default
+func (m *EnsembleModelSpec) GetWeight() int64 {
+	if m.Weight == nil {
+		return 1
+	}
+	return *m.Weight
+}
+
+var ensembleModelNameRegexp = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// Validation of the ensemble of the predictor, the models are loaded by the model agent so the predictor must be
+// a multi-model server without a model of its own
+func validateEnsemble(isvc *InferenceService) error {
+	ensemble := isvc.Spec.Predictor.Ensemble
+	if ensemble == nil {
+		return nil
+	}
+	predictor := &isvc.Spec.Predictor
+	if predictor.SKLearn == nil && predictor.XGBoost == nil && predictor.Triton == nil {
+		return fmt.Errorf(EnsemblePredictorError)
+	}
+	if extensions := predictor.GetPredictorExtensions(); extensions != nil &&
+		(extensions.StorageURI != nil || extensions.ModelRef != nil) {
+		return fmt.Errorf(EnsemblePredictorError)
+	}
+	if len(ensemble.Models) < 2 {
+		return fmt.Errorf(EnsembleModelsError)
+	}
+	names := map[string]bool{}
+	var weights int64
+	for _, model := range ensemble.Models {
+		if !ensembleModelNameRegexp.MatchString(model.Name) || model.Name == isvc.Name || names[model.Name] {
+			return fmt.Errorf(InvalidEnsembleModelNameError, model.Name)
+		}
+		names[model.Name] = true
+		if model.StorageURI == "" {
+			return fmt.Errorf(EnsembleModelStorageURIError, model.Name)
+		}
+		if err := validateStorageURI(&model.StorageURI); err != nil {
+			return err
+		}
+		if model.GetWeight() < 0 {
+			return fmt.Errorf(EnsembleWeightError)
+		}
+		weights += model.GetWeight()
+	}
+	if weights == 0 {
+		return fmt.Errorf(EnsembleWeightError)
+	}
+	switch ensemble.GetStrategy() {
+	case WeightedAverageEnsemble, MajorityVoteEnsemble:
+	default:
+		return fmt.Errorf(InvalidEnsembleStrategyError, ensemble.Strategy, strings.Join([]string{
+			string(WeightedAverageEnsemble), string(MajorityVoteEnsemble)}, ", "))
+	}
+	return nil
+}
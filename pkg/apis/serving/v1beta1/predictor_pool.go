@@ -0,0 +1,113 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// PoolRouting selects how the traffic of the predictor is split between its replicas and the replica pool
+// +kubebuilder:validation:Enum=Weighted;Overflow
+type PoolRouting string
+
+// PoolRouting Enum
+const (
+	// WeightedPoolRouting routes a fixed percent of the traffic to the pool
+	WeightedPoolRouting PoolRouting = "Weighted"
+	// OverflowPoolRouting routes the traffic above the capacity of the predictor replicas to the pool
+	OverflowPoolRouting PoolRouting = "Overflow"
+)
+
+// ReplicaPoolSpec defines a secondary pool of predictor replicas serving the same model with a different container,
+// e.g. CPU replicas absorbing the traffic spikes beyond the capacity of the GPU replicas of the predictor. The pool
+// replicas are copies of the predictor replicas with the overrides of the pool.
+type ReplicaPoolSpec struct {
+	// Image of the pool container, defaults to the image of the predictor container
+	// +optional
+	Image string `json:"image,omitempty"`
+	// Arguments of the pool container, they replace the arguments of the predictor container when set
+	// +optional
+	Args []string `json:"args,omitempty"`
+	// Environment variables of the pool container, they override the predictor variables with the same name
+	// +optional
+	Env []v1.EnvVar `json:"env,omitempty"`
+	// Resources of the pool container, they replace the resources of the predictor container
+	// +optional
+	Resources v1.ResourceRequirements `json:"resources,omitempty"`
+	// Node selector of the pool pods, it replaces the node selector of the predictor pods so that the pool is not
+	// scheduled on the nodes of the predictor
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// Tolerations of the pool pods, they replace the tolerations of the predictor pods
+	// +optional
+	Tolerations []v1.Toleration `json:"tolerations,omitempty"`
+	// Minimum number of replicas of the pool, defaults to 1
+	// +optional
+	MinReplicas *int `json:"minReplicas,omitempty"`
+	// Maximum number of replicas of the pool
+	// +optional
+	MaxReplicas int `json:"maxReplicas,omitempty"`
+	// Routing of the traffic to the pool, Weighted or Overflow. Defaults to Overflow.
+	// +optional
+	Routing PoolRouting `json:"routing,omitempty"`
+	// Percent of the traffic routed to the pool with Weighted routing
+	// +optional
+	Weight int64 `json:"weight,omitempty"`
+	// Request rate the predictor replicas serve at their maximum scale, the traffic above it is routed to the pool
+	// with Overflow routing
+	// +optional
+	CapacityRequestsPerSecond float64 `json:"capacityRequestsPerSecond,omitempty"`
+}
+
+// GetRouting returns the routing of the pool, Overflow by default
+func (s *ReplicaPoolSpec) GetRouting() PoolRouting {
+	if s.Routing == "" {
+		return OverflowPoolRouting
+	}
+	return s.Routing
+}
+
+// Validation of the replica pool of the predictor
+func validateReplicaPool(isvc *InferenceService) error {
+	pool := isvc.Spec.Predictor.Pool
+	if pool == nil {
+		return nil
+	}
+	if isvc.Spec.Transformer != nil {
+		return fmt.Errorf(ReplicaPoolTransformerError)
+	}
+	if err := validateReplicas(pool.MinReplicas, pool.MaxReplicas); err != nil {
+		return err
+	}
+	switch pool.GetRouting() {
+	case WeightedPoolRouting:
+		if pool.Weight < 0 || pool.Weight > 100 {
+			return fmt.Errorf(ReplicaPoolWeightError)
+		}
+	case OverflowPoolRouting:
+		if pool.CapacityRequestsPerSecond <= 0 {
+			return fmt.Errorf(ReplicaPoolCapacityError)
+		}
+	default:
+		return fmt.Errorf(InvalidReplicaPoolRoutingError, pool.Routing, strings.Join([]string{
+			string(WeightedPoolRouting), string(OverflowPoolRouting)}, ", "))
+	}
+	return nil
+}
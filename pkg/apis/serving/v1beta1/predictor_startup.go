@@ -0,0 +1,60 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// StartupSpec defines how long the predictor model server is given to load the model before its liveness probe applies.
+// The startup timeout is the minimum startup timeout of the inferenceservice configmap plus the time the model server
+// takes to load the model at the load rate of the configmap, unless the timeout is set.
+type StartupSpec struct {
+	// Size of the model loaded by the model server, e.g. 40Gi
+	// +optional
+	ModelSize *resource.Quantity `json:"modelSize,omitempty"`
+	// Seconds the model server is given to start, it overrides the timeout computed from the model size
+	// +optional
+	TimeoutSeconds *int64 `json:"timeoutSeconds,omitempty"`
+}
+
+// GetStartupTimeoutSeconds returns the seconds the predictor model server is given to start
+func GetStartupTimeoutSeconds(startup *StartupSpec, config StartupConfig) int64 {
+	if startup != nil && startup.TimeoutSeconds != nil {
+		return *startup.TimeoutSeconds
+	}
+	timeout := config.MinTimeoutSeconds
+	if startup != nil && startup.ModelSize != nil && config.LoadRatePerSecond.Value() > 0 {
+		rate := config.LoadRatePerSecond.Value()
+		timeout += (startup.ModelSize.Value() + rate - 1) / rate
+	}
+	return timeout
+}
+
+// Validation of the startup of the predictor
+func validateStartup(startup *StartupSpec) error {
+	if startup == nil {
+		return nil
+	}
+	if (startup.ModelSize != nil && startup.ModelSize.Sign() < 0) ||
+		(startup.TimeoutSeconds != nil && *startup.TimeoutSeconds < 0) {
+		return fmt.Errorf(StartupLowerBoundError)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"testing"
+
+	"github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestGetStartupTimeoutSeconds(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	config, err := NewInferenceServicesConfigFromConfigMap(&v1.ConfigMap{})
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(config.Startup.MinTimeoutSeconds).To(gomega.Equal(int64(DefaultStartupMinTimeoutSeconds)))
+
+	g.Expect(GetStartupTimeoutSeconds(nil, config.Startup)).To(gomega.Equal(int64(300)))
+	modelSize := resource.MustParse("40Gi")
+	g.Expect(GetStartupTimeoutSeconds(&StartupSpec{ModelSize: &modelSize}, config.Startup)).To(gomega.Equal(int64(710)))
+	timeout := int64(1800)
+	g.Expect(GetStartupTimeoutSeconds(&StartupSpec{ModelSize: &modelSize, TimeoutSeconds: &timeout},
+		config.Startup)).To(gomega.Equal(int64(1800)))
+
+	_, err = NewInferenceServicesConfigFromConfigMap(&v1.ConfigMap{
+		Data: map[string]string{StartupConfigKeyName: `{"minTimeoutSeconds": -1}`},
+	})
+	g.Expect(err).ShouldNot(gomega.BeNil())
+}
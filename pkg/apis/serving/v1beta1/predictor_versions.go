@@ -0,0 +1,89 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+)
+
+// ModelVersionSpec defines a pinned version of the predictor model. Each version is served by its own replicas,
+// copies of the predictor replicas loading the model of the version, at the /versions/<name> path of the
+// InferenceService url.
+type ModelVersionSpec struct {
+	// Name of the version, a DNS label
+	Name string `json:"name"`
+	// Storage URI of the model of the version
+	StorageURI string `json:"storageUri"`
+	// Minimum number of replicas of the version, defaults to the minimum replicas of the predictor
+	// +optional
+	MinReplicas *int `json:"minReplicas,omitempty"`
+	// Maximum number of replicas of the version, defaults to the maximum replicas of the predictor
+	// +optional
+	MaxReplicas int `json:"maxReplicas,omitempty"`
+}
+
+// GetModelVersion returns the model version with the name, or nil when the predictor does not declare it
+func (s *PredictorSpec) GetModelVersion(name string) *ModelVersionSpec {
+	for i := range s.Versions {
+		if s.Versions[i].Name == name {
+			return &s.Versions[i]
+		}
+	}
+	return nil
+}
+
+// Validation of the model versions of the predictor, the versions replace the model of the predictor so it must have
+// a storageUri, and the ingress must route the requests to the predictor replicas
+func validateModelVersions(isvc *InferenceService) error {
+	predictor := &isvc.Spec.Predictor
+	if len(predictor.Versions) == 0 {
+		if predictor.DefaultVersion != "" {
+			return fmt.Errorf(DefaultModelVersionError, predictor.DefaultVersion)
+		}
+		return nil
+	}
+	if isvc.Spec.Transformer != nil || predictor.Pool != nil {
+		return fmt.Errorf(ModelVersionRoutingError)
+	}
+	if extensions := predictor.GetPredictorExtensions(); extensions == nil || extensions.StorageURI == nil {
+		return fmt.Errorf(ModelVersionPredictorError)
+	}
+	names := map[string]bool{}
+	for _, version := range predictor.Versions {
+		if !ensembleModelNameRegexp.MatchString(version.Name) || names[version.Name] {
+			return fmt.Errorf(InvalidModelVersionNameError, version.Name)
+		}
+		names[version.Name] = true
+		if version.StorageURI == "" {
+			return fmt.Errorf(ModelVersionStorageURIError, version.Name)
+		}
+		if err := validateStorageURI(&version.StorageURI); err != nil {
+			return err
+		}
+		maxReplicas := version.MaxReplicas
+		if maxReplicas == 0 {
+			maxReplicas = predictor.MaxReplicas
+		}
+		if err := validateReplicas(version.MinReplicas, maxReplicas); err != nil {
+			return err
+		}
+	}
+	if predictor.DefaultVersion != "" && !names[predictor.DefaultVersion] {
+		return fmt.Errorf(DefaultModelVersionError, predictor.DefaultVersion)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import "fmt"
+
+// DefaultRetryOn are the conditions the requests are retried on when the retry policy does not set them
+const DefaultRetryOn = "503,connect-failure,refused-stream,reset"
+
+// RoutingSpec defines how the ingress of the InferenceService handles the failures of its backends
+type RoutingSpec struct {
+	// Retries of the requests which failed on a transient backend failure
+	// +optional
+	Retries *RetryPolicy `json:"retries,omitempty"`
+	// Name of an InferenceService in the same namespace the requests are routed to while the components of this
+	// InferenceService are not ready
+	// +optional
+	Fallback string `json:"fallback,omitempty"`
+	// Session affinity of the predictor replicas, the requests with the same session key are routed to the same
+	// replica so that the model servers keeping per-session caches serve the whole session
+	// +optional
+	SessionAffinity *SessionAffinitySpec `json:"sessionAffinity,omitempty"`
+}
+
+// RetryPolicy defines the retries of the ingress routes, only idempotent requests should be retried on the
+// upstream failures
+type RetryPolicy struct {
+	// Number of retries of a request
+	Attempts int32 `json:"attempts"`
+	// Seconds before a try times out, the route timeout applies when it is not set
+	// +optional
+	PerTryTimeoutSeconds int64 `json:"perTryTimeoutSeconds,omitempty"`
+	// Comma separated conditions the requests are retried on, status codes or the Envoy retry policies, defaults to
+	// 503,connect-failure,refused-stream,reset
+	// +optional
+	RetryOn string `json:"retryOn,omitempty"`
+}
+
+// SessionAffinitySpec defines the session key the predictor replicas are selected by with consistent hashing,
+// exactly one of header or cookie must be set. The replica of a session changes when the predictor scales.
+type SessionAffinitySpec struct {
+	// Name of the request header holding the session key
+	// +optional
+	Header string `json:"header,omitempty"`
+	// Cookie holding the session key, the gateway sets it on the response when the request does not have it
+	// +optional
+	Cookie *SessionCookie `json:"cookie,omitempty"`
+}
+
+// SessionCookie defines the cookie holding the session key
+type SessionCookie struct {
+	// Name of the cookie
+	Name string `json:"name"`
+	// Path of the cookie
+	// +optional
+	Path string `json:"path,omitempty"`
+	// Lifetime of the cookie, the cookie expires with the browser session when it is not set
+	// +optional
+	TTLSeconds int64 `json:"ttlSeconds,omitempty"`
+}
+
+// Validation of the routing of the InferenceService
+func validateRouting(isvc *InferenceService) error {
+	routing := isvc.Spec.Routing
+	if routing == nil {
+		return nil
+	}
+	if routing.Retries != nil && (routing.Retries.Attempts < 0 || routing.Retries.PerTryTimeoutSeconds < 0) {
+		return fmt.Errorf(RetryPolicyLowerBoundError)
+	}
+	if routing.Fallback != "" && (routing.Fallback == isvc.Name || !IsvcRegexp.MatchString(routing.Fallback)) {
+		return fmt.Errorf(InvalidFallbackError, routing.Fallback)
+	}
+	if affinity := routing.SessionAffinity; affinity != nil {
+		if (affinity.Header == "") == (affinity.Cookie == nil) {
+			return fmt.Errorf(SessionAffinityError)
+		}
+		if affinity.Cookie != nil && (affinity.Cookie.Name == "" || affinity.Cookie.TTLSeconds < 0) {
+			return fmt.Errorf(SessionAffinityError)
+		}
+	}
+	return nil
+}
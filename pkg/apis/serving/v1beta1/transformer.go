@@ -16,8 +16,11 @@ limitations under the License.
 
 package v1beta1
 
-// TransformerSpec defines transformer service for pre/post processing
+// TransformerSpec defines transformer service for pre/post processing,
+// The following fields follow a "1-of" semantic. Users must specify exactly one spec.
 type TransformerSpec struct {
+	// Spec for the Feast enrichment transformer
+	Feast *FeastTransformerSpec `json:"feast,omitempty"`
 	// This spec is dual purpose.
 	// 1) Users may choose to provide a full PodSpec for their transformer.
 	// The field PodSpec.Containers is mutually exclusive with other Transformer (i.e. Feast).
@@ -30,7 +33,9 @@ type TransformerSpec struct {
 
 // GetImplementations returns the implementations for the component
 func (s *TransformerSpec) GetImplementations() []ComponentImplementation {
-	implementations := []ComponentImplementation{}
+	implementations := NonNilComponents([]ComponentImplementation{
+		s.Feast,
+	})
 	// This struct is not a pointer, so it will never be nil; include if containers are specified
 	if len(s.PodSpec.Containers) != 0 {
 		implementations = append(implementations, NewCustomTransformer(&s.PodSpec))
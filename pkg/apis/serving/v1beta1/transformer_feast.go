@@ -0,0 +1,110 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// FeastEntity maps a field of the request instances to the join key of a Feast entity
+type FeastEntity struct {
+	// Join key of the entity in the feature store
+	Name string `json:"name"`
+	// Field of the request instances holding the entity key
+	Field string `json:"field"`
+}
+
+// FeastTransformerSpec defines the arguments for configuring the Feast enrichment transformer, which looks up the
+// online features of the request entities and joins them into the instances before calling the predictor
+type FeastTransformerSpec struct {
+	// Address of the HTTP endpoint of the Feast online feature server started by `feast serve`, e.g.
+	// feast-feature-server.feast:6566
+	FeatureServerURL string `json:"featureServerUrl"`
+	// Name of the feature service listing the features joined into the requests
+	FeatureService string `json:"featureService"`
+	// Entities looked up for each instance of the request
+	Entities []FeastEntity `json:"entities"`
+	// Feast transformer docker image version, defaults to latest Feast transformer version
+	RuntimeVersion *string `json:"runtimeVersion,omitempty"`
+	// Container enables overrides for the transformer.
+	// +optional
+	v1.Container `json:",inline"`
+}
+
+var _ ComponentImplementation = &FeastTransformerSpec{}
+
+// GetStorageUri returns nil, the features are read from the feature server
+func (s *FeastTransformerSpec) GetStorageUri() *string {
+	return nil
+}
+
+func (s *FeastTransformerSpec) GetContainer(metadata metav1.ObjectMeta, extensions *ComponentExtensionSpec, config *InferenceServicesConfig) *v1.Container {
+	var args = []string{
+		constants.ArgumentModelName, metadata.Name,
+		constants.ArgumentPredictorHost, fmt.Sprintf("%s.%s", constants.DefaultPredictorServiceName(metadata.Name), metadata.Namespace),
+		constants.ArgumentHttpPort, constants.InferenceServiceDefaultHttpPort,
+	}
+	if extensions.ContainerConcurrency != nil {
+		args = append(args, constants.ArgumentWorkers, strconv.FormatInt(*extensions.ContainerConcurrency, 10))
+	}
+	args = append(args,
+		"--feast_serving_url", s.FeatureServerURL,
+		"--feature_service", s.FeatureService,
+	)
+	// The entities keep the order of the spec, the transformer builds the entity rows with it
+	for _, entity := range s.Entities {
+		args = append(args, "--entity", entity.Name+"="+entity.Field)
+	}
+	if s.Container.Image == "" {
+		s.Image = config.Transformers.Feast.ContainerImage + ":" + *s.RuntimeVersion
+	}
+	s.Name = constants.InferenceServiceContainerName
+	s.Args = args
+	return &s.Container
+}
+
+func (s *FeastTransformerSpec) Default(config *InferenceServicesConfig) {
+	s.Name = constants.InferenceServiceContainerName
+	if s.RuntimeVersion == nil {
+		s.RuntimeVersion = proto.String(config.Transformers.Feast.DefaultImageVersion)
+	}
+	setResourceRequirementDefaults(&s.Resources)
+}
+
+// Validate the spec
+func (s *FeastTransformerSpec) Validate() error {
+	if s.FeatureServerURL == "" || s.FeatureService == "" || len(s.Entities) == 0 {
+		return fmt.Errorf(FeastTransformerError)
+	}
+	names := map[string]bool{}
+	for _, entity := range s.Entities {
+		if entity.Name == "" || entity.Field == "" {
+			return fmt.Errorf(FeastEntityError)
+		}
+		if names[entity.Name] {
+			return fmt.Errorf(DuplicateFeastEntityError, entity.Name)
+		}
+		names[entity.Name] = true
+	}
+	return nil
+}
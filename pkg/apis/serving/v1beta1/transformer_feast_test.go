@@ -0,0 +1,129 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/onsi/gomega"
+	"github.com/onsi/gomega/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestFeastTransformerValidation(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scenarios := map[string]struct {
+		spec    FeastTransformerSpec
+		matcher types.GomegaMatcher
+	}{
+		"Valid": {
+			spec: FeastTransformerSpec{
+				FeatureServerURL: "feast-serving.feast:6566",
+				FeatureService:   "driver_ranking",
+				Entities:         []FeastEntity{{Name: "driver_id", Field: "driver"}},
+			},
+			matcher: gomega.BeNil(),
+		},
+		"MissingFeatureService": {
+			spec: FeastTransformerSpec{
+				FeatureServerURL: "feast-serving.feast:6566",
+				Entities:         []FeastEntity{{Name: "driver_id", Field: "driver"}},
+			},
+			matcher: gomega.MatchError(FeastTransformerError),
+		},
+		"MissingEntities": {
+			spec: FeastTransformerSpec{
+				FeatureServerURL: "feast-serving.feast:6566",
+				FeatureService:   "driver_ranking",
+			},
+			matcher: gomega.MatchError(FeastTransformerError),
+		},
+		"EntityWithoutField": {
+			spec: FeastTransformerSpec{
+				FeatureServerURL: "feast-serving.feast:6566",
+				FeatureService:   "driver_ranking",
+				Entities:         []FeastEntity{{Name: "driver_id"}},
+			},
+			matcher: gomega.MatchError(FeastEntityError),
+		},
+		"DuplicateEntity": {
+			spec: FeastTransformerSpec{
+				FeatureServerURL: "feast-serving.feast:6566",
+				FeatureService:   "driver_ranking",
+				Entities: []FeastEntity{
+					{Name: "driver_id", Field: "driver"},
+					{Name: "driver_id", Field: "driver_2"},
+				},
+			},
+			matcher: gomega.MatchError(fmt.Sprintf(DuplicateFeastEntityError, "driver_id")),
+		},
+	}
+
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			res := scenario.spec.Validate()
+			if !g.Expect(res).To(scenario.matcher) {
+				t.Errorf("got %q, want %q", res, scenario.matcher)
+			}
+		})
+	}
+}
+
+func TestCreateFeastTransformerContainer(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	config := InferenceServicesConfig{
+		Transformers: TransformersConfig{
+			Feast: TransformerConfig{
+				ContainerImage:      "feast-transformer",
+				DefaultImageVersion: "v0.5.0",
+			},
+		},
+	}
+	spec := &TransformerSpec{
+		Feast: &FeastTransformerSpec{
+			FeatureServerURL: "feast-serving.feast:6566",
+			FeatureService:   "driver_ranking",
+			Entities: []FeastEntity{
+				{Name: "driver_id", Field: "driver"},
+				{Name: "customer_id", Field: "customer"},
+			},
+		},
+		ComponentExtensionSpec: ComponentExtensionSpec{
+			ContainerConcurrency: proto.Int64(2),
+		},
+	}
+	g.Expect(spec.GetImplementations()).To(gomega.HaveLen(1))
+	transformer := spec.GetImplementation()
+	transformer.Default(&config)
+	g.Expect(transformer.GetStorageUri()).To(gomega.BeNil())
+	res := transformer.GetContainer(metav1.ObjectMeta{Name: "driver", Namespace: "default"}, spec.GetExtensions(), &config)
+	g.Expect(res.Name).To(gomega.Equal(constants.InferenceServiceContainerName))
+	g.Expect(res.Image).To(gomega.Equal("feast-transformer:v0.5.0"))
+	g.Expect(res.Args).To(gomega.Equal([]string{
+		"--model_name", "driver",
+		"--predictor_host", "driver-predictor-default.default",
+		"--http_port", "8080",
+		"--workers", "2",
+		"--feast_serving_url", "feast-serving.feast:6566",
+		"--feature_service", "driver_ranking",
+		"--entity", "driver_id=driver",
+		"--entity", "customer_id=customer",
+	}))
+}
@@ -56,6 +56,34 @@ func (in *AIXExplainerSpec) DeepCopy() *AIXExplainerSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AlibiDriftDetectorSpec) DeepCopyInto(out *AlibiDriftDetectorSpec) {
+	*out = *in
+	if in.RuntimeVersion != nil {
+		in, out := &in.RuntimeVersion, &out.RuntimeVersion
+		*out = new(string)
+		**out = **in
+	}
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.Container.DeepCopyInto(&out.Container)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlibiDriftDetectorSpec.
+func (in *AlibiDriftDetectorSpec) DeepCopy() *AlibiDriftDetectorSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AlibiDriftDetectorSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AlibiExplainerSpec) DeepCopyInto(out *AlibiExplainerSpec) {
 	*out = *in
@@ -84,6 +112,34 @@ func (in *AlibiExplainerSpec) DeepCopy() *AlibiExplainerSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AlibiOutlierDetectorSpec) DeepCopyInto(out *AlibiOutlierDetectorSpec) {
+	*out = *in
+	if in.RuntimeVersion != nil {
+		in, out := &in.RuntimeVersion, &out.RuntimeVersion
+		*out = new(string)
+		**out = **in
+	}
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.Container.DeepCopyInto(&out.Container)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlibiOutlierDetectorSpec.
+func (in *AlibiOutlierDetectorSpec) DeepCopy() *AlibiOutlierDetectorSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AlibiOutlierDetectorSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Batcher) DeepCopyInto(out *Batcher) {
 	*out = *in
@@ -114,6 +170,125 @@ func (in *Batcher) DeepCopy() *Batcher {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryAnalysis) DeepCopyInto(out *CanaryAnalysis) {
+	*out = *in
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make([]int64, len(*in))
+		copy(*out, *in)
+	}
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = make([]CanaryMetric, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryAnalysis.
+func (in *CanaryAnalysis) DeepCopy() *CanaryAnalysis {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryAnalysis)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryAnalysisStatus) DeepCopyInto(out *CanaryAnalysisStatus) {
+	*out = *in
+	in.LastCheckTime.DeepCopyInto(&out.LastCheckTime)
+	if in.Reports != nil {
+		in, out := &in.Reports, &out.Reports
+		*out = make([]CanaryReport, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryAnalysisStatus.
+func (in *CanaryAnalysisStatus) DeepCopy() *CanaryAnalysisStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryAnalysisStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryMetric) DeepCopyInto(out *CanaryMetric) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryMetric.
+func (in *CanaryMetric) DeepCopy() *CanaryMetric {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryMetric)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryMetricReport) DeepCopyInto(out *CanaryMetricReport) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryMetricReport.
+func (in *CanaryMetricReport) DeepCopy() *CanaryMetricReport {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryMetricReport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryReport) DeepCopyInto(out *CanaryReport) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = make([]CanaryMetricReport, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryReport.
+func (in *CanaryReport) DeepCopy() *CanaryReport {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryReport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterStatus) DeepCopyInto(out *ClusterStatus) {
+	*out = *in
+	if in.URL != nil {
+		in, out := &in.URL, &out.URL
+		*out = new(apis.URL)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterStatus.
+func (in *ClusterStatus) DeepCopy() *ClusterStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ComponentExtensionSpec) DeepCopyInto(out *ComponentExtensionSpec) {
 	*out = *in
@@ -137,6 +312,11 @@ func (in *ComponentExtensionSpec) DeepCopyInto(out *ComponentExtensionSpec) {
 		*out = new(int64)
 		**out = **in
 	}
+	if in.CanaryAnalysis != nil {
+		in, out := &in.CanaryAnalysis, &out.CanaryAnalysis
+		*out = new(CanaryAnalysis)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Logger != nil {
 		in, out := &in.Logger, &out.Logger
 		*out = new(LoggerSpec)
@@ -147,6 +327,36 @@ func (in *ComponentExtensionSpec) DeepCopyInto(out *ComponentExtensionSpec) {
 		*out = new(Batcher)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ScalingSchedules != nil {
+		in, out := &in.ScalingSchedules, &out.ScalingSchedules
+		*out = make([]ScalingSchedule, len(*in))
+		copy(*out, *in)
+	}
+	if in.PredictiveScaling != nil {
+		in, out := &in.PredictiveScaling, &out.PredictiveScaling
+		*out = new(PredictiveScalingSpec)
+		**out = **in
+	}
+	if in.WarmUp != nil {
+		in, out := &in.WarmUp, &out.WarmUp
+		*out = new(WarmUpSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Rollout != nil {
+		in, out := &in.Rollout, &out.Rollout
+		*out = new(RolloutSpec)
+		**out = **in
+	}
+	if in.RevisionRetention != nil {
+		in, out := &in.RevisionRetention, &out.RevisionRetention
+		*out = new(RevisionRetentionSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PreStopSleepSeconds != nil {
+		in, out := &in.PreStopSleepSeconds, &out.PreStopSleepSeconds
+		*out = new(int64)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentExtensionSpec.
@@ -162,6 +372,43 @@ func (in *ComponentExtensionSpec) DeepCopy() *ComponentExtensionSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ComponentStatusSpec) DeepCopyInto(out *ComponentStatusSpec) {
 	*out = *in
+	if in.Rollout != nil {
+		in, out := &in.Rollout, &out.Rollout
+		*out = new(RolloutStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CanaryAnalysis != nil {
+		in, out := &in.CanaryAnalysis, &out.CanaryAnalysis
+		*out = new(CanaryAnalysisStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PredictiveScaling != nil {
+		in, out := &in.PredictiveScaling, &out.PredictiveScaling
+		*out = new(PredictiveScalingStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DriftAlert != nil {
+		in, out := &in.DriftAlert, &out.DriftAlert
+		*out = new(DriftAlertStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RevisionCollection != nil {
+		in, out := &in.RevisionCollection, &out.RevisionCollection
+		*out = new(RevisionCollectionStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Pool != nil {
+		in, out := &in.Pool, &out.Pool
+		*out = new(PoolStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Versions != nil {
+		in, out := &in.Versions, &out.Versions
+		*out = make([]ModelVersionStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.TrafficPercent != nil {
 		in, out := &in.TrafficPercent, &out.TrafficPercent
 		*out = new(int64)
@@ -189,6 +436,37 @@ func (in *ComponentStatusSpec) DeepCopy() *ComponentStatusSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CostStatus) DeepCopyInto(out *CostStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CostStatus.
+func (in *CostStatus) DeepCopy() *CostStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CostStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CustomDetector) DeepCopyInto(out *CustomDetector) {
+	*out = *in
+	in.PodSpec.DeepCopyInto(&out.PodSpec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomDetector.
+func (in *CustomDetector) DeepCopy() *CustomDetector {
+	if in == nil {
+		return nil
+	}
+	out := new(CustomDetector)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CustomExplainer) DeepCopyInto(out *CustomExplainer) {
 	*out = *in
@@ -237,6 +515,107 @@ func (in *CustomTransformer) DeepCopy() *CustomTransformer {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DetectorAlert) DeepCopyInto(out *DetectorAlert) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DetectorAlert.
+func (in *DetectorAlert) DeepCopy() *DetectorAlert {
+	if in == nil {
+		return nil
+	}
+	out := new(DetectorAlert)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DriftAlertStatus) DeepCopyInto(out *DriftAlertStatus) {
+	*out = *in
+	in.EvaluationTime.DeepCopyInto(&out.EvaluationTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DriftAlertStatus.
+func (in *DriftAlertStatus) DeepCopy() *DriftAlertStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DriftAlertStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DriftDetectorSpec) DeepCopyInto(out *DriftDetectorSpec) {
+	*out = *in
+	if in.Alibi != nil {
+		in, out := &in.Alibi, &out.Alibi
+		*out = new(AlibiDriftDetectorSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Alert != nil {
+		in, out := &in.Alert, &out.Alert
+		*out = new(DetectorAlert)
+		**out = **in
+	}
+	in.PodSpec.DeepCopyInto(&out.PodSpec)
+	in.ComponentExtensionSpec.DeepCopyInto(&out.ComponentExtensionSpec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DriftDetectorSpec.
+func (in *DriftDetectorSpec) DeepCopy() *DriftDetectorSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DriftDetectorSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EnsembleModelSpec) DeepCopyInto(out *EnsembleModelSpec) {
+	*out = *in
+	out.Memory = in.Memory.DeepCopy()
+	if in.Weight != nil {
+		in, out := &in.Weight, &out.Weight
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnsembleModelSpec.
+func (in *EnsembleModelSpec) DeepCopy() *EnsembleModelSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(EnsembleModelSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EnsembleSpec) DeepCopyInto(out *EnsembleSpec) {
+	*out = *in
+	if in.Models != nil {
+		in, out := &in.Models, &out.Models
+		*out = make([]EnsembleModelSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnsembleSpec.
+func (in *EnsembleSpec) DeepCopy() *EnsembleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(EnsembleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ExplainerSpec) DeepCopyInto(out *ExplainerSpec) {
 	*out = *in
@@ -264,6 +643,47 @@ func (in *ExplainerSpec) DeepCopy() *ExplainerSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FeastEntity) DeepCopyInto(out *FeastEntity) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FeastEntity.
+func (in *FeastEntity) DeepCopy() *FeastEntity {
+	if in == nil {
+		return nil
+	}
+	out := new(FeastEntity)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FeastTransformerSpec) DeepCopyInto(out *FeastTransformerSpec) {
+	*out = *in
+	if in.Entities != nil {
+		in, out := &in.Entities, &out.Entities
+		*out = make([]FeastEntity, len(*in))
+		copy(*out, *in)
+	}
+	if in.RuntimeVersion != nil {
+		in, out := &in.RuntimeVersion, &out.RuntimeVersion
+		*out = new(string)
+		**out = **in
+	}
+	in.Container.DeepCopyInto(&out.Container)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FeastTransformerSpec.
+func (in *FeastTransformerSpec) DeepCopy() *FeastTransformerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(FeastTransformerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *InferenceService) DeepCopyInto(out *InferenceService) {
 	*out = *in
@@ -291,6 +711,114 @@ func (in *InferenceService) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InferenceServiceClass) DeepCopyInto(out *InferenceServiceClass) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InferenceServiceClass.
+func (in *InferenceServiceClass) DeepCopy() *InferenceServiceClass {
+	if in == nil {
+		return nil
+	}
+	out := new(InferenceServiceClass)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *InferenceServiceClass) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InferenceServiceClassList) DeepCopyInto(out *InferenceServiceClassList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]InferenceServiceClass, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InferenceServiceClassList.
+func (in *InferenceServiceClassList) DeepCopy() *InferenceServiceClassList {
+	if in == nil {
+		return nil
+	}
+	out := new(InferenceServiceClassList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *InferenceServiceClassList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InferenceServiceClassSpec) DeepCopyInto(out *InferenceServiceClassSpec) {
+	*out = *in
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.MinReplicas != nil {
+		in, out := &in.MinReplicas, &out.MinReplicas
+		*out = new(int)
+		**out = **in
+	}
+	if in.ContainerConcurrency != nil {
+		in, out := &in.ContainerConcurrency, &out.ContainerConcurrency
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Logger != nil {
+		in, out := &in.Logger, &out.Logger
+		*out = new(LoggerSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]corev1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(corev1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InferenceServiceClassSpec.
+func (in *InferenceServiceClassSpec) DeepCopy() *InferenceServiceClassSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(InferenceServiceClassSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *InferenceServiceList) DeepCopyInto(out *InferenceServiceList) {
 	*out = *in
@@ -337,6 +865,35 @@ func (in *InferenceServiceSpec) DeepCopyInto(out *InferenceServiceSpec) {
 		*out = new(TransformerSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.DriftDetector != nil {
+		in, out := &in.DriftDetector, &out.DriftDetector
+		*out = new(DriftDetectorSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OutlierDetector != nil {
+		in, out := &in.OutlierDetector, &out.OutlierDetector
+		*out = new(OutlierDetectorSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Routing != nil {
+		in, out := &in.Routing, &out.Routing
+		*out = new(RoutingSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]corev1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.TTLSecondsAfterCreation != nil {
+		in, out := &in.TTLSecondsAfterCreation, &out.TTLSecondsAfterCreation
+		*out = new(int64)
+		**out = **in
+	}
+	if in.ExpireAt != nil {
+		in, out := &in.ExpireAt, &out.ExpireAt
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InferenceServiceSpec.
@@ -370,6 +927,23 @@ func (in *InferenceServiceStatus) DeepCopyInto(out *InferenceServiceStatus) {
 			(*out)[key] = *val.DeepCopy()
 		}
 	}
+	if in.Clusters != nil {
+		in, out := &in.Clusters, &out.Clusters
+		*out = make(map[string]ClusterStatus, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.Cost != nil {
+		in, out := &in.Cost, &out.Cost
+		*out = new(CostStatus)
+		**out = **in
+	}
+	if in.ResolvedModel != nil {
+		in, out := &in.ResolvedModel, &out.ResolvedModel
+		*out = new(ResolvedModelStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InferenceServiceStatus.
@@ -387,17 +961,82 @@ func (in *LoggerSpec) DeepCopyInto(out *LoggerSpec) {
 	*out = *in
 	if in.URL != nil {
 		in, out := &in.URL, &out.URL
-		*out = new(string)
-		**out = **in
+		*out = new(string)
+		**out = **in
+	}
+	if in.Retries != nil {
+		in, out := &in.Retries, &out.Retries
+		*out = new(int)
+		**out = **in
+	}
+	if in.DeadLetterURL != nil {
+		in, out := &in.DeadLetterURL, &out.DeadLetterURL
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoggerSpec.
+func (in *LoggerSpec) DeepCopy() *LoggerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LoggerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelRegistryRef) DeepCopyInto(out *ModelRegistryRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelRegistryRef.
+func (in *ModelRegistryRef) DeepCopy() *ModelRegistryRef {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelRegistryRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelVersionSpec) DeepCopyInto(out *ModelVersionSpec) {
+	*out = *in
+	if in.MinReplicas != nil {
+		in, out := &in.MinReplicas, &out.MinReplicas
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelVersionSpec.
+func (in *ModelVersionSpec) DeepCopy() *ModelVersionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelVersionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelVersionStatus) DeepCopyInto(out *ModelVersionStatus) {
+	*out = *in
+	if in.URL != nil {
+		in, out := &in.URL, &out.URL
+		*out = new(apis.URL)
+		(*in).DeepCopyInto(*out)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoggerSpec.
-func (in *LoggerSpec) DeepCopy() *LoggerSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelVersionStatus.
+func (in *ModelVersionStatus) DeepCopy() *ModelVersionStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(LoggerSpec)
+	out := new(ModelVersionStatus)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -418,6 +1057,28 @@ func (in *ONNXRuntimeSpec) DeepCopy() *ONNXRuntimeSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OutlierDetectorSpec) DeepCopyInto(out *OutlierDetectorSpec) {
+	*out = *in
+	if in.Alibi != nil {
+		in, out := &in.Alibi, &out.Alibi
+		*out = new(AlibiOutlierDetectorSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	in.PodSpec.DeepCopyInto(&out.PodSpec)
+	in.ComponentExtensionSpec.DeepCopyInto(&out.ComponentExtensionSpec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OutlierDetectorSpec.
+func (in *OutlierDetectorSpec) DeepCopy() *OutlierDetectorSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OutlierDetectorSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PMMLSpec) DeepCopyInto(out *PMMLSpec) {
 	*out = *in
@@ -577,6 +1238,53 @@ func (in *PodSpec) DeepCopy() *PodSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PoolStatus) DeepCopyInto(out *PoolStatus) {
+	*out = *in
+	in.CheckTime.DeepCopyInto(&out.CheckTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PoolStatus.
+func (in *PoolStatus) DeepCopy() *PoolStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PoolStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PredictiveScalingSpec) DeepCopyInto(out *PredictiveScalingSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PredictiveScalingSpec.
+func (in *PredictiveScalingSpec) DeepCopy() *PredictiveScalingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PredictiveScalingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PredictiveScalingStatus) DeepCopyInto(out *PredictiveScalingStatus) {
+	*out = *in
+	in.ForecastTime.DeepCopyInto(&out.ForecastTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PredictiveScalingStatus.
+func (in *PredictiveScalingStatus) DeepCopy() *PredictiveScalingStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PredictiveScalingStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PredictorExtensionSpec) DeepCopyInto(out *PredictorExtensionSpec) {
 	*out = *in
@@ -585,6 +1293,11 @@ func (in *PredictorExtensionSpec) DeepCopyInto(out *PredictorExtensionSpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.ModelRef != nil {
+		in, out := &in.ModelRef, &out.ModelRef
+		*out = new(ModelRegistryRef)
+		**out = **in
+	}
 	if in.RuntimeVersion != nil {
 		in, out := &in.RuntimeVersion, &out.RuntimeVersion
 		*out = new(string)
@@ -646,6 +1359,28 @@ func (in *PredictorSpec) DeepCopyInto(out *PredictorSpec) {
 		*out = new(PMMLSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Pool != nil {
+		in, out := &in.Pool, &out.Pool
+		*out = new(ReplicaPoolSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Ensemble != nil {
+		in, out := &in.Ensemble, &out.Ensemble
+		*out = new(EnsembleSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Startup != nil {
+		in, out := &in.Startup, &out.Startup
+		*out = new(StartupSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Versions != nil {
+		in, out := &in.Versions, &out.Versions
+		*out = make([]ModelVersionSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	in.PodSpec.DeepCopyInto(&out.PodSpec)
 	in.ComponentExtensionSpec.DeepCopyInto(&out.ComponentExtensionSpec)
 }
@@ -660,6 +1395,177 @@ func (in *PredictorSpec) DeepCopy() *PredictorSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplicaPoolSpec) DeepCopyInto(out *ReplicaPoolSpec) {
+	*out = *in
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]corev1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]corev1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.MinReplicas != nil {
+		in, out := &in.MinReplicas, &out.MinReplicas
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicaPoolSpec.
+func (in *ReplicaPoolSpec) DeepCopy() *ReplicaPoolSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplicaPoolSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResolvedModelStatus) DeepCopyInto(out *ResolvedModelStatus) {
+	*out = *in
+	in.ResolveTime.DeepCopyInto(&out.ResolveTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResolvedModelStatus.
+func (in *ResolvedModelStatus) DeepCopy() *ResolvedModelStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ResolvedModelStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RetryPolicy) DeepCopyInto(out *RetryPolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetryPolicy.
+func (in *RetryPolicy) DeepCopy() *RetryPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(RetryPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RevisionCollectionStatus) DeepCopyInto(out *RevisionCollectionStatus) {
+	*out = *in
+	in.CollectionTime.DeepCopyInto(&out.CollectionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RevisionCollectionStatus.
+func (in *RevisionCollectionStatus) DeepCopy() *RevisionCollectionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RevisionCollectionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RevisionRetentionSpec) DeepCopyInto(out *RevisionRetentionSpec) {
+	*out = *in
+	if in.KeepReadyRevisions != nil {
+		in, out := &in.KeepReadyRevisions, &out.KeepReadyRevisions
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RevisionRetentionSpec.
+func (in *RevisionRetentionSpec) DeepCopy() *RevisionRetentionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RevisionRetentionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutSpec) DeepCopyInto(out *RolloutSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutSpec.
+func (in *RolloutSpec) DeepCopy() *RolloutSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutStatus) DeepCopyInto(out *RolloutStatus) {
+	*out = *in
+	in.ReadyTime.DeepCopyInto(&out.ReadyTime)
+	in.DrainStartTime.DeepCopyInto(&out.DrainStartTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutStatus.
+func (in *RolloutStatus) DeepCopy() *RolloutStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RoutingSpec) DeepCopyInto(out *RoutingSpec) {
+	*out = *in
+	if in.Retries != nil {
+		in, out := &in.Retries, &out.Retries
+		*out = new(RetryPolicy)
+		**out = **in
+	}
+	if in.SessionAffinity != nil {
+		in, out := &in.SessionAffinity, &out.SessionAffinity
+		*out = new(SessionAffinitySpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RoutingSpec.
+func (in *RoutingSpec) DeepCopy() *RoutingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RoutingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SKLearnSpec) DeepCopyInto(out *SKLearnSpec) {
 	*out = *in
@@ -676,6 +1582,81 @@ func (in *SKLearnSpec) DeepCopy() *SKLearnSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScalingSchedule) DeepCopyInto(out *ScalingSchedule) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScalingSchedule.
+func (in *ScalingSchedule) DeepCopy() *ScalingSchedule {
+	if in == nil {
+		return nil
+	}
+	out := new(ScalingSchedule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SessionAffinitySpec) DeepCopyInto(out *SessionAffinitySpec) {
+	*out = *in
+	if in.Cookie != nil {
+		in, out := &in.Cookie, &out.Cookie
+		*out = new(SessionCookie)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SessionAffinitySpec.
+func (in *SessionAffinitySpec) DeepCopy() *SessionAffinitySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SessionAffinitySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SessionCookie) DeepCopyInto(out *SessionCookie) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SessionCookie.
+func (in *SessionCookie) DeepCopy() *SessionCookie {
+	if in == nil {
+		return nil
+	}
+	out := new(SessionCookie)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StartupSpec) DeepCopyInto(out *StartupSpec) {
+	*out = *in
+	if in.ModelSize != nil {
+		in, out := &in.ModelSize, &out.ModelSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.TimeoutSeconds != nil {
+		in, out := &in.TimeoutSeconds, &out.TimeoutSeconds
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StartupSpec.
+func (in *StartupSpec) DeepCopy() *StartupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(StartupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TFServingSpec) DeepCopyInto(out *TFServingSpec) {
 	*out = *in
@@ -711,6 +1692,11 @@ func (in *TorchServeSpec) DeepCopy() *TorchServeSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TransformerSpec) DeepCopyInto(out *TransformerSpec) {
 	*out = *in
+	if in.Feast != nil {
+		in, out := &in.Feast, &out.Feast
+		*out = new(FeastTransformerSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	in.PodSpec.DeepCopyInto(&out.PodSpec)
 	in.ComponentExtensionSpec.DeepCopyInto(&out.ComponentExtensionSpec)
 }
@@ -741,6 +1727,26 @@ func (in *TritonSpec) DeepCopy() *TritonSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WarmUpSpec) DeepCopyInto(out *WarmUpSpec) {
+	*out = *in
+	if in.ConfigMapKeyRef != nil {
+		in, out := &in.ConfigMapKeyRef, &out.ConfigMapKeyRef
+		*out = new(corev1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WarmUpSpec.
+func (in *WarmUpSpec) DeepCopy() *WarmUpSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(WarmUpSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *XGBoostSpec) DeepCopyInto(out *XGBoostSpec) {
 	*out = *in
@@ -0,0 +1,251 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package batch streams the records of a BatchInferenceJob shard through the predict endpoint of an InferenceService.
+package batch
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+)
+
+const (
+	// maxRecordSize is the largest input line accepted
+	maxRecordSize = 16 * 1024 * 1024
+)
+
+// Summary is the record accounting of a shard, the runner writes it as the termination message of its container
+type Summary struct {
+	// Processed is the number of records of the shard, including the failed ones
+	Processed int64 `json:"processed"`
+	// Failed is the number of records whose predict request failed after all the retries
+	Failed int64 `json:"failed"`
+}
+
+// Result is a line of the output, the predictions are not in the input order so the result refers to its record
+type Result struct {
+	// Source is the input file and line number of the record, e.g. "part-0.jsonl:12"
+	Source string `json:"source"`
+	// Prediction returned for the record
+	Prediction json.RawMessage `json:"prediction,omitempty"`
+	// Error is the last failure of the predict request
+	Error string `json:"error,omitempty"`
+}
+
+// Runner sends each record of its shard as a single instance v1 predict request
+type Runner struct {
+	// InputDir contains the JSON lines files of the dataset
+	InputDir string
+	// PredictorURL is the predict endpoint, e.g. http://sklearn-iris.default.svc.cluster.local/v1/models/sklearn-iris:predict
+	PredictorURL string
+	// ShardIndex and ShardCount select the records of the shard, the records are assigned round robin
+	ShardIndex int
+	ShardCount int
+	// Workers is the number of concurrent requests
+	Workers int
+	// Retries is the number of times a failed request is retried
+	Retries int
+	// Backoff is the delay before the first retry, it doubles on every retry
+	Backoff time.Duration
+	Client  *http.Client
+	Log     logr.Logger
+}
+
+type record struct {
+	source string
+	data   []byte
+}
+
+// Run processes the records of the shard and writes one Result per record to out. An error is only returned when the
+// input can not be read or the output can not be written, failed predict requests are accounted in the summary.
+func (r *Runner) Run(out io.Writer) (*Summary, error) {
+	if r.ShardCount < 1 || r.ShardIndex < 0 || r.ShardIndex >= r.ShardCount {
+		return nil, fmt.Errorf("invalid shard %d of %d", r.ShardIndex, r.ShardCount)
+	}
+	workers := r.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	records := make(chan record)
+	results := make(chan Result)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for rec := range records {
+				results <- r.predict(rec)
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	readErr := make(chan error, 1)
+	go func() {
+		defer close(records)
+		readErr <- r.read(records)
+	}()
+
+	summary := &Summary{}
+	encoder := json.NewEncoder(out)
+	var writeErr error
+	for result := range results {
+		summary.Processed++
+		if result.Error != "" {
+			summary.Failed++
+		}
+		if writeErr == nil {
+			writeErr = encoder.Encode(result)
+		}
+	}
+	if err := <-readErr; err != nil {
+		return summary, err
+	}
+	return summary, writeErr
+}
+
+// read sends the records of the shard from the input files in lexical order
+func (r *Runner) read(records chan<- record) error {
+	files := []string{}
+	if err := filepath.Walk(r.InputDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		// skip the hidden files and directories left by the storage initializer
+		if strings.HasPrefix(info.Name(), ".") && path != r.InputDir {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.Mode().IsRegular() {
+			files = append(files, path)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	sort.Strings(files)
+
+	index := 0
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		name, _ := filepath.Rel(r.InputDir, file)
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 64*1024), maxRecordSize)
+		line := 0
+		for scanner.Scan() {
+			line++
+			data := bytes.TrimSpace(scanner.Bytes())
+			if len(data) == 0 {
+				continue
+			}
+			if index%r.ShardCount == r.ShardIndex {
+				records <- record{
+					source: fmt.Sprintf("%s:%d", name, line),
+					data:   append([]byte{}, data...),
+				}
+			}
+			index++
+		}
+		err = scanner.Err()
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("fails to read %s: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// predict sends the record and retries on connection errors, throttling and server errors
+func (r *Runner) predict(rec record) Result {
+	result := Result{Source: rec.source}
+	if !json.Valid(rec.data) {
+		result.Error = "record is not valid JSON"
+		return result
+	}
+	body := []byte(`{"instances":[` + string(rec.data) + `]}`)
+	backoff := r.Backoff
+	for attempt := 0; ; attempt++ {
+		prediction, retryable, err := r.post(body)
+		if err == nil {
+			result.Prediction = prediction
+			return result
+		}
+		result.Error = err.Error()
+		if !retryable || attempt >= r.Retries {
+			r.Log.Info("Failed to predict record", "source", rec.source, "error", result.Error)
+			return result
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+func (r *Runner) post(body []byte) (json.RawMessage, bool, error) {
+	client := r.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	response, err := client.Post(r.PredictorURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, true, err
+	}
+	defer response.Body.Close()
+	data, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, true, err
+	}
+	if response.StatusCode != http.StatusOK {
+		retryable := response.StatusCode >= http.StatusInternalServerError || response.StatusCode == http.StatusTooManyRequests
+		return nil, retryable, fmt.Errorf("predict returned %d: %s", response.StatusCode, strings.TrimSpace(string(data)))
+	}
+	predictions := struct {
+		Predictions []json.RawMessage `json:"predictions"`
+	}{}
+	if err := json.Unmarshal(data, &predictions); err != nil {
+		return nil, false, fmt.Errorf("fails to parse predict response: %v", err)
+	}
+	if len(predictions.Predictions) != 1 {
+		return nil, false, fmt.Errorf("predict returned %d predictions for a single instance", len(predictions.Predictions))
+	}
+	return predictions.Predictions[0], false, nil
+}
+
+// OutputFileName is the name of the output file of a shard
+func OutputFileName(shardIndex int) string {
+	return fmt.Sprintf("part-%05d.jsonl", shardIndex)
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package batch
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/onsi/gomega"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func writeInput(g *gomega.GomegaWithT, dir string, name string, lines ...string) {
+	g.Expect(ioutil.WriteFile(filepath.Join(dir, name), []byte(strings.Join(lines, "\n")), 0644)).Should(gomega.Succeed())
+}
+
+func readResults(g *gomega.GomegaWithT, out *bytes.Buffer) []Result {
+	results := []Result{}
+	decoder := json.NewDecoder(out)
+	for decoder.More() {
+		result := Result{}
+		g.Expect(decoder.Decode(&result)).Should(gomega.Succeed())
+		results = append(results, result)
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Source < results[j].Source })
+	return results
+}
+
+func TestRunShard(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request := struct {
+			Instances []json.RawMessage `json:"instances"`
+		}{}
+		g.Expect(json.NewDecoder(r.Body).Decode(&request)).Should(gomega.Succeed())
+		w.Write([]byte(`{"predictions": [` + string(request.Instances[0]) + `]}`))
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "input")
+	g.Expect(err).Should(gomega.BeNil())
+	defer os.RemoveAll(dir)
+	writeInput(g, dir, "a.jsonl", "[1]", "", "[2]", "[3]")
+	writeInput(g, dir, "b.jsonl", "[4]", "not json")
+
+	out := &bytes.Buffer{}
+	runner := &Runner{InputDir: dir, PredictorURL: server.URL, ShardIndex: 1, ShardCount: 2, Workers: 2,
+		Log: logf.Log}
+	summary, err := runner.Run(out)
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(*summary).To(gomega.Equal(Summary{Processed: 2, Failed: 0}))
+	g.Expect(readResults(g, out)).To(gomega.Equal([]Result{
+		{Source: "a.jsonl:3", Prediction: json.RawMessage("[2]")},
+		{Source: "b.jsonl:1", Prediction: json.RawMessage("[4]")},
+	}))
+
+	out = &bytes.Buffer{}
+	runner.ShardIndex = 0
+	summary, err = runner.Run(out)
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(*summary).To(gomega.Equal(Summary{Processed: 3, Failed: 1}))
+	g.Expect(readResults(g, out)).To(gomega.Equal([]Result{
+		{Source: "a.jsonl:1", Prediction: json.RawMessage("[1]")},
+		{Source: "a.jsonl:4", Prediction: json.RawMessage("[3]")},
+		{Source: "b.jsonl:2", Error: "record is not valid JSON"},
+	}))
+}
+
+func TestRunRetries(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"predictions": [0]}`))
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "input")
+	g.Expect(err).Should(gomega.BeNil())
+	defer os.RemoveAll(dir)
+	writeInput(g, dir, "input.jsonl", "[1]")
+
+	out := &bytes.Buffer{}
+	runner := &Runner{InputDir: dir, PredictorURL: server.URL, ShardCount: 1, Retries: 1, Log: logf.Log}
+	summary, err := runner.Run(out)
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(*summary).To(gomega.Equal(Summary{Processed: 1, Failed: 1}))
+	g.Expect(readResults(g, out)[0].Error).To(gomega.HavePrefix("predict returned 503"))
+
+	out = &bytes.Buffer{}
+	runner.Retries = 3
+	summary, err = runner.Run(out)
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(*summary).To(gomega.Equal(Summary{Processed: 1, Failed: 0}))
+	g.Expect(readResults(g, out)[0].Prediction).To(gomega.Equal(json.RawMessage("0")))
+}
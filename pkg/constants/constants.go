@@ -42,6 +42,9 @@ var (
 	InferenceServiceAPIName       = "inferenceservices"
 	InferenceServicePodLabelKey   = KFServingAPIGroupName + "/" + InferenceServiceName
 	InferenceServiceConfigMapName = "inferenceservice-config"
+	// ModelVersionLabelKey is set on the knative services of the pinned model versions of the predictor to the name of
+	// the version
+	ModelVersionLabelKey = KFServingAPIGroupName + "/model-version"
 )
 
 // InferenceService MultiModel Constants
@@ -60,6 +63,34 @@ const (
 // InferenceService Annotations
 var (
 	InferenceServiceGKEAcceleratorAnnotationKey = KFServingAPIGroupName + "/gke-accelerator"
+	RollbackAnnotationKey                       = KFServingAPIGroupName + "/rollback"
+	// PausedAnnotationKey scales down an InferenceService, or all the InferenceServices of a namespace when set on the namespace
+	PausedAnnotationKey = KFServingAPIGroupName + "/paused"
+	// DeploymentMode selects how the predictor is deployed, one of the DeploymentModeType values
+	DeploymentMode = KFServingAPIGroupName + "/deploymentMode"
+	// ModelMeshStorageSecretKeyAnnotationKey selects the entry of the ModelMesh storage config secret used to pull the model
+	ModelMeshStorageSecretKeyAnnotationKey = KFServingAPIGroupName + "/storage-secret-key"
+	// GPUSharingAnnotationKey requests shared GPUs for the component containers, the supported scheme is time-slicing
+	GPUSharingAnnotationKey = KFServingAPIGroupName + "/gpu-sharing"
+	// MemberClustersAnnotationKey restricts a multi-cluster InferenceService to a comma separated list of member clusters
+	MemberClustersAnnotationKey = KFServingAPIGroupName + "/member-clusters"
+	// ModelDigestsAnnotationKey records the digests of the component models of an exported InferenceService as a comma
+	// separated list of component=digest, they are verified when the InferenceService is imported
+	ModelDigestsAnnotationKey = KFServingAPIGroupName + "/model-digests"
+	// ImageTagsAnnotationKey records the images of the revision containers pinned to digests as a comma separated list
+	// of container=image
+	ImageTagsAnnotationKey = KFServingAPIGroupName + "/image-tags"
+)
+
+// Multi-cluster Constants
+var (
+	// MultiClusterLabel set to "true" pushes the InferenceService to the member clusters instead of deploying it
+	MultiClusterLabel = KFServingAPIGroupName + "/multi-cluster"
+	// MemberClusterLabel set to "true" marks the secrets of the KFServing namespace holding the kubeconfig of a member
+	// cluster, the name of the secret is the name of the member cluster
+	MemberClusterLabel = KFServingAPIGroupName + "/member-cluster"
+	// MemberClusterKubeconfigKey is the entry of the member cluster secrets holding the kubeconfig
+	MemberClusterKubeconfigKey = "kubeconfig"
 )
 
 // InferenceService Internal Annotations
@@ -69,6 +100,11 @@ var (
 	LoggerInternalAnnotationKey                      = InferenceServiceInternalAnnotationsPrefix + "/logger"
 	LoggerSinkUrlInternalAnnotationKey               = InferenceServiceInternalAnnotationsPrefix + "/logger-sink-url"
 	LoggerModeInternalAnnotationKey                  = InferenceServiceInternalAnnotationsPrefix + "/logger-mode"
+	LoggerRetriesInternalAnnotationKey               = InferenceServiceInternalAnnotationsPrefix + "/logger-retries"
+	LoggerDeadLetterUrlInternalAnnotationKey         = InferenceServiceInternalAnnotationsPrefix + "/logger-dead-letter-url"
+	LoggerOutlierUrlInternalAnnotationKey            = InferenceServiceInternalAnnotationsPrefix + "/logger-outlier-url"
+	LoggerSchemaInternalAnnotationKey                = InferenceServiceInternalAnnotationsPrefix + "/logger-schema-configmap"
+	LoggerEnsembleInternalAnnotationKey              = InferenceServiceInternalAnnotationsPrefix + "/logger-ensemble"
 	BatcherInternalAnnotationKey                     = InferenceServiceInternalAnnotationsPrefix + "/batcher"
 	BatcherMaxBatchSizeInternalAnnotationKey         = InferenceServiceInternalAnnotationsPrefix + "/batcher-max-batchsize"
 	BatcherMaxLatencyInternalAnnotationKey           = InferenceServiceInternalAnnotationsPrefix + "/batcher-max-latency"
@@ -77,6 +113,18 @@ var (
 	AgentModelConfigVolumeNameAnnotationKey          = InferenceServiceInternalAnnotationsPrefix + "/configVolumeName"
 	AgentModelConfigMountPathAnnotationKey           = InferenceServiceInternalAnnotationsPrefix + "/configMountPath"
 	AgentModelDirAnnotationKey                       = InferenceServiceInternalAnnotationsPrefix + "/modelDir"
+	DesiredSpecHashInternalAnnotationKey             = InferenceServiceInternalAnnotationsPrefix + "/desired-spec-hash"
+	PlacementPolicyInternalAnnotationKey             = InferenceServiceInternalAnnotationsPrefix + "/placement-policy"
+	EnsembleModelsInternalAnnotationKey              = InferenceServiceInternalAnnotationsPrefix + "/ensemble-models"
+	TopologySpreadInternalAnnotationKey              = InferenceServiceInternalAnnotationsPrefix + "/topology-spread-constraints"
+	StartupProbeInternalAnnotationKey                = InferenceServiceInternalAnnotationsPrefix + "/startup-probe"
+	RuntimeInternalAnnotationKey                     = InferenceServiceInternalAnnotationsPrefix + "/runtime"
+	PreStopSleepInternalAnnotationKey                = InferenceServiceInternalAnnotationsPrefix + "/pre-stop-sleep-seconds"
+	TerminationGracePeriodInternalAnnotationKey      = InferenceServiceInternalAnnotationsPrefix + "/termination-grace-period-seconds"
+	RuntimeClassInternalAnnotationKey                = InferenceServiceInternalAnnotationsPrefix + "/runtime-class-name"
+	// ClassDefaultsInternalAnnotationKey records the InferenceServiceClass whose defaults the mutating webhook set, the
+	// class is looked up again once the InferenceService selects another class
+	ClassDefaultsInternalAnnotationKey = InferenceServiceInternalAnnotationsPrefix + "/class-defaults"
 )
 
 // Controller Constants
@@ -86,6 +134,7 @@ var (
 	DefaultTransformerTimeout int64 = 120
 	DefaultExplainerTimeout   int64 = 300
 	DefaultReadinessTimeout   int32 = 600
+	StartupProbePeriodSeconds int32 = 10
 	DefaultScalingTarget            = "1"
 	DefaultMinReplicas        int   = 1
 )
@@ -102,6 +151,13 @@ var (
 // GPU Constants
 const (
 	NvidiaGPUResourceType = "nvidia.com/gpu"
+	// NvidiaMIGResourcePrefix prefixes the MIG slices advertised with the mixed MIG strategy, e.g. nvidia.com/mig-1g.5gb
+	NvidiaMIGResourcePrefix = "nvidia.com/mig-"
+	// NvidiaSharedGPUResourceSuffix is appended by the device plugin to the GPUs and MIG slices shared by time-slicing
+	// when the shared resources are renamed, e.g. nvidia.com/gpu.shared
+	NvidiaSharedGPUResourceSuffix = ".shared"
+	// GPUSharingTimeSlicing is the GPUSharingAnnotationKey value requesting time-sliced GPUs
+	GPUSharingTimeSlicing = "time-slicing"
 )
 
 // InferenceService Environment Variables
@@ -115,11 +171,15 @@ type InferenceServiceVerb string
 
 type InferenceServiceProtocol string
 
+type DeploymentModeType string
+
 // Knative constants
 const (
 	KnativeLocalGateway   = "knative-serving/cluster-local-gateway"
 	KnativeIngressGateway = "knative-serving/knative-ingress-gateway"
 	VisibilityLabel       = "serving.knative.dev/visibility"
+	// ClusterLocalVisibility is the visibility of the knative services which are only reachable in the cluster
+	ClusterLocalVisibility = "cluster-local"
 )
 
 var (
@@ -131,6 +191,9 @@ const (
 	Predictor   InferenceServiceComponent = "predictor"
 	Explainer   InferenceServiceComponent = "explainer"
 	Transformer InferenceServiceComponent = "transformer"
+	// Knative service names of the detectors, the component types are not valid DNS labels
+	DriftDetector   InferenceServiceComponent = "drift-detector"
+	OutlierDetector InferenceServiceComponent = "outlier-detector"
 )
 
 // InferenceService verb enums
@@ -145,6 +208,14 @@ const (
 	ProtocolV2 InferenceServiceProtocol = "v2"
 )
 
+// InferenceService deployment mode enums
+const (
+	// Serverless deploys each component as a knative service
+	Serverless DeploymentModeType = "Serverless"
+	// ModelMeshDeployment delegates the predictor to a ModelMesh serving cluster
+	ModelMeshDeployment DeploymentModeType = "ModelMesh"
+)
+
 // InferenceService Endpoint Ports
 const (
 	InferenceServiceDefaultHttpPort    = "8080"
@@ -160,11 +231,30 @@ const (
 	KServiceEndpointLabel  = "endpoint"
 )
 
+// PredictorPoolComponentLabel is the component label of the replica pool of the predictor, the pool pods are not
+// selected by the pod selector of the predictor
+const PredictorPoolComponentLabel = "predictor-pool"
+
+// PredictorVersionComponentLabel is the component label of the model versions of the predictor, the version pods are
+// not selected by the pod selector of the predictor
+const PredictorVersionComponentLabel = "predictor-version"
+
 // Labels for TrainedModel
 const (
 	ParentInferenceServiceLabel = "inferenceservice"
 )
 
+// BatchInferenceJob Constants
+var (
+	BatchInferenceJobLabel          = KFServingAPIGroupName + "/batchinferencejob"
+	BatchInferenceJobComponentLabel = "batch"
+)
+
+// BatchInferenceJobShardName returns the name of the job processing a shard of the BatchInferenceJob
+func BatchInferenceJobShardName(name string, shardIndex int) string {
+	return fmt.Sprintf("%s-shard-%d", name, shardIndex)
+}
+
 // InferenceService default/canary constants
 const (
 	InferenceServiceDefault = "default"
@@ -181,6 +271,12 @@ const (
 	ArgumentWorkers        = "--workers"
 )
 
+// Types of the CloudEvents replied by the detectors
+const (
+	DriftEventType   = "org.kubeflow.serving.inference.drift"
+	OutlierEventType = "org.kubeflow.serving.inference.outlier"
+)
+
 // InferenceService container name
 const (
 	InferenceServiceContainerName = "kfserving-container"
@@ -197,13 +293,32 @@ const (
 	ModelDir              = DefaultModelLocalMountPath
 )
 
+// Request validation
+const (
+	ModelSchemaVolumeName = "model-schema"
+	ModelSchemaDir        = "/mnt/schema"
+	ModelSchemaFileName   = "schema.json"
+)
+
 var (
 	ServiceAnnotationDisallowedList = []string{
 		autoscaling.MinScaleAnnotationKey,
 		autoscaling.MaxScaleAnnotationKey,
 		StorageInitializerSourceUriInternalAnnotationKey,
+		PausedAnnotationKey,
+		ClassDefaultsInternalAnnotationKey,
 		"kubectl.kubernetes.io/last-applied-configuration",
 	}
+
+	// ControllerAnnotations are the InferenceService annotations read back from the generated resources by the
+	// controller and the pod mutator, they propagate regardless of the propagation rules
+	ControllerAnnotations = []string{
+		InferenceServiceGKEAcceleratorAnnotationKey,
+		RollbackAnnotationKey,
+		DeploymentMode,
+		ModelMeshStorageSecretKeyAnnotationKey,
+		GPUSharingAnnotationKey,
+	}
 )
 
 func (e InferenceServiceComponent) String() string {
@@ -257,6 +372,29 @@ func CanaryTransformerServiceName(name string) string {
 	return name + "-" + string(Transformer) + "-" + InferenceServiceCanary
 }
 
+func DefaultDriftDetectorServiceName(name string) string {
+	return name + "-" + string(DriftDetector) + "-" + InferenceServiceDefault
+}
+
+func DefaultOutlierDetectorServiceName(name string) string {
+	return name + "-" + string(OutlierDetector) + "-" + InferenceServiceDefault
+}
+
+// PredictorPoolServiceName is the knative service of the replica pool of the predictor
+func PredictorPoolServiceName(name string) string {
+	return name + "-" + string(Predictor) + "-pool"
+}
+
+// PredictorVersionServiceName is the knative service of a pinned model version of the predictor
+func PredictorVersionServiceName(name string, version string) string {
+	return name + "-" + string(Predictor) + "-version-" + version
+}
+
+// ModelVersionPath is the path prefix of the InferenceService url routed to a pinned model version of the predictor
+func ModelVersionPath(version string) string {
+	return "/versions/" + version
+}
+
 func DefaultServiceName(name string, component InferenceServiceComponent) string {
 	return name + "-" + component.String() + "-" + InferenceServiceDefault
 }
@@ -269,6 +407,11 @@ func ModelConfigName(inferenceserviceName string, shardId int) string {
 	return fmt.Sprintf("modelconfig-%s-%d", inferenceserviceName, shardId)
 }
 
+// ModelSchemaConfigMapName returns the name of the configmap holding the v2 metadata of the predictor model
+func ModelSchemaConfigMapName(name string) string {
+	return name + "-model-schema"
+}
+
 func InferenceServicePrefix(name string) string {
 	return fmt.Sprintf("/v1/models/%s", name)
 }
@@ -310,6 +453,23 @@ func TransformerURL(metadata v1.ObjectMeta, isCanary bool) string {
 	return fmt.Sprintf("%s.%s", serviceName, metadata.Namespace)
 }
 
+// DriftDetectorURL returns the cluster local address of the drift detector the predictor payloads are logged to
+func DriftDetectorURL(metadata v1.ObjectMeta) string {
+	return fmt.Sprintf("http://%s.%s.svc.%s", DefaultDriftDetectorServiceName(metadata.Name), metadata.Namespace,
+		network.GetClusterDomainName())
+}
+
+// OutlierDetectorURL returns the cluster local address of the outlier detector scoring the predictor requests
+func OutlierDetectorURL(metadata v1.ObjectMeta) string {
+	return fmt.Sprintf("http://%s.%s.svc.%s", DefaultOutlierDetectorServiceName(metadata.Name), metadata.Namespace,
+		network.GetClusterDomainName())
+}
+
+// DetectorEventSource returns the source of the CloudEvents replied by the detectors of the InferenceService
+func DetectorEventSource(metadata v1.ObjectMeta) string {
+	return fmt.Sprintf("org.kubeflow.serving.%s.%s", metadata.Namespace, metadata.Name)
+}
+
 // Should only match 1..65535, but for simplicity it matches 0-99999.
 const portMatch = `(?::\d{1,5})?`
 
@@ -0,0 +1,426 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// +kubebuilder:rbac:groups=serving.kubeflow.org,resources=batchinferencejobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=serving.kubeflow.org,resources=batchinferencejobs/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
+package batchinferencejob
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	v1alpha1api "github.com/kubeflow/kfserving/pkg/apis/serving/v1alpha1"
+	v1beta1api "github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/batch"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/kubeflow/kfserving/pkg/credentials"
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	// BatchInferenceConfigMapKeyName is the key of the batch runner configuration in the inferenceservice configmap
+	BatchInferenceConfigMapKeyName = "batchInference"
+	// OutputMountPath is where a pvc:// output is mounted in the runner container
+	OutputMountPath = "/mnt/output"
+	// outputVolumeName is the volume of a pvc:// output
+	outputVolumeName = "kfserving-batch-output"
+)
+
+// BatchInferenceConfig is the configuration of the batch runner container
+type BatchInferenceConfig struct {
+	Image         string `json:"image"`
+	CpuRequest    string `json:"cpuRequest"`
+	CpuLimit      string `json:"cpuLimit"`
+	MemoryRequest string `json:"memoryRequest"`
+	MemoryLimit   string `json:"memoryLimit"`
+}
+
+// BatchInferenceJobReconciler reconciles a BatchInferenceJob object
+type BatchInferenceJobReconciler struct {
+	client.Client
+	Log      logr.Logger
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+}
+
+func (r *BatchInferenceJobReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	job := &v1alpha1api.BatchInferenceJob{}
+	if err := r.Get(context.TODO(), req.NamespacedName, job); err != nil {
+		if apierr.IsNotFound(err) {
+			// Object not found, return. The shard jobs are garbage collected.
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+	if job.Status.IsDone() {
+		return reconcile.Result{}, nil
+	}
+	job.Status.InitializeConditions()
+	if _, _, _, err := outputVolume(job.Spec.OutputURI); err != nil {
+		job.Status.MarkInvalidSpec(err.Error())
+		return reconcile.Result{}, r.updateStatus(job)
+	}
+
+	// The shards are started once, the InferenceService readiness is not checked again while they are running
+	if job.Status.StartTime == nil {
+		predictorURL, err := r.predictorURL(job)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		if predictorURL == "" {
+			return reconcile.Result{}, r.updateStatus(job)
+		}
+		job.Status.MarkInferenceServiceReady()
+		job.Status.PredictorURL = predictorURL
+		now := metav1.Now()
+		job.Status.StartTime = &now
+		r.Recorder.Eventf(job, v1.EventTypeNormal, "Started", "Sending records to %s", predictorURL)
+	}
+
+	shards, err := r.reconcileShards(job)
+	if err != nil {
+		r.Log.Error(err, "Failed to reconcile shards", "BatchInferenceJob", job.Name)
+		r.Recorder.Eventf(job, v1.EventTypeWarning, "InternalError", err.Error())
+		return reconcile.Result{}, err
+	}
+	pods := &v1.PodList{}
+	if err := r.List(context.TODO(), pods, client.InNamespace(job.Namespace),
+		client.MatchingLabels{constants.BatchInferenceJobLabel: job.Name}); err != nil {
+		return reconcile.Result{}, err
+	}
+	propagateShardStatus(&job.Status, parallelism(job), shards, pods.Items)
+	if job.Status.IsDone() {
+		r.Recorder.Eventf(job, v1.EventTypeNormal, "Completed", "Processed %d records, %d failed",
+			job.Status.ProcessedRecords, job.Status.FailedRecords)
+	}
+	return reconcile.Result{}, r.updateStatus(job)
+}
+
+// predictorURL returns the predict endpoint of the InferenceService, or an empty string after marking the job as
+// waiting when the InferenceService is not ready
+func (r *BatchInferenceJobReconciler) predictorURL(job *v1alpha1api.BatchInferenceJob) (string, error) {
+	isvc := &v1beta1api.InferenceService{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Name: job.Spec.InferenceService, Namespace: job.Namespace}, isvc); err != nil {
+		if apierr.IsNotFound(err) {
+			job.Status.MarkInferenceServiceNotReady("InferenceServiceNotFound",
+				fmt.Sprintf("InferenceService %s does not exist", job.Spec.InferenceService))
+			return "", nil
+		}
+		return "", err
+	}
+	if !isvc.Status.IsReady() || isvc.Status.Address == nil || isvc.Status.Address.URL == nil {
+		job.Status.MarkInferenceServiceNotReady("InferenceServiceNotReady",
+			fmt.Sprintf("Waiting for InferenceService %s to be ready", isvc.Name))
+		return "", nil
+	}
+	return strings.TrimSuffix(isvc.Status.Address.URL.String(), "/") + constants.PredictPath(isvc.Name), nil
+}
+
+func parallelism(job *v1alpha1api.BatchInferenceJob) int {
+	if job.Spec.Parallelism == nil || *job.Spec.Parallelism < 1 {
+		return 1
+	}
+	return int(*job.Spec.Parallelism)
+}
+
+// reconcileShards creates the missing shard jobs and returns all of them, the shard jobs are never updated as a
+// restarted shard would process its records again.
+func (r *BatchInferenceJobReconciler) reconcileShards(job *v1alpha1api.BatchInferenceJob) ([]batchv1.Job, error) {
+	configMap := &v1.ConfigMap{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Name: constants.InferenceServiceConfigMapName,
+		Namespace: constants.KFServingNamespace}, configMap); err != nil {
+		return nil, errors.Wrapf(err, "fails to find config map %s", constants.InferenceServiceConfigMapName)
+	}
+	config, err := getBatchInferenceConfig(configMap)
+	if err != nil {
+		return nil, err
+	}
+	shards := []batchv1.Job{}
+	for i := 0; i < parallelism(job); i++ {
+		existing := &batchv1.Job{}
+		name := constants.BatchInferenceJobShardName(job.Name, i)
+		err := r.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: job.Namespace}, existing)
+		if err == nil {
+			shards = append(shards, *existing)
+			continue
+		}
+		if !apierr.IsNotFound(err) {
+			return nil, err
+		}
+		desired, err := createShard(job, i, config)
+		if err != nil {
+			return nil, err
+		}
+		if err := credentials.NewCredentialBulder(r.Client, configMap).CreateSecretVolumeAndEnv(job.Namespace,
+			job.Spec.ServiceAccountName, &desired.Spec.Template.Spec.Containers[0], &desired.Spec.Template.Spec.Volumes); err != nil {
+			return nil, err
+		}
+		if err := controllerutil.SetControllerReference(job, desired, r.Scheme); err != nil {
+			return nil, err
+		}
+		r.Log.Info("Creating shard job", "namespace", desired.Namespace, "name", desired.Name)
+		if err := r.Create(context.TODO(), desired); err != nil {
+			return nil, err
+		}
+		shards = append(shards, *desired)
+	}
+	return shards, nil
+}
+
+func getBatchInferenceConfig(configMap *v1.ConfigMap) (*BatchInferenceConfig, error) {
+	config := &BatchInferenceConfig{}
+	if data, ok := configMap.Data[BatchInferenceConfigMapKeyName]; ok {
+		if err := json.Unmarshal([]byte(data), config); err != nil {
+			return nil, fmt.Errorf("Unable to unmarshall %v json string due to %v ", BatchInferenceConfigMapKeyName, err)
+		}
+	}
+	if config.Image == "" {
+		return nil, fmt.Errorf("%s image is not configured in config map %s", BatchInferenceConfigMapKeyName,
+			constants.InferenceServiceConfigMapName)
+	}
+	for _, quantity := range []string{config.CpuRequest, config.CpuLimit, config.MemoryRequest, config.MemoryLimit} {
+		if _, err := resource.ParseQuantity(quantity); err != nil {
+			return nil, fmt.Errorf("Failed to parse resource configuration for %q: %q", BatchInferenceConfigMapKeyName, err.Error())
+		}
+	}
+	return config, nil
+}
+
+// outputVolume returns the volume the runner writes a pvc:// output to and the local output path, s3:// outputs
+// are uploaded by the runner.
+func outputVolume(outputURI string) ([]v1.Volume, []v1.VolumeMount, string, error) {
+	switch {
+	case strings.HasPrefix(outputURI, "s3://"):
+		return nil, nil, outputURI, nil
+	case strings.HasPrefix(outputURI, "pvc://"):
+		parts := strings.SplitN(strings.TrimPrefix(outputURI, "pvc://"), "/", 2)
+		if parts[0] == "" {
+			return nil, nil, "", fmt.Errorf("invalid outputUri %s, must be pvc://<pvcname>/[path]", outputURI)
+		}
+		volumes := []v1.Volume{{
+			Name: outputVolumeName,
+			VolumeSource: v1.VolumeSource{
+				PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{ClaimName: parts[0]},
+			},
+		}}
+		volumeMounts := []v1.VolumeMount{{Name: outputVolumeName, MountPath: OutputMountPath}}
+		outputPath := OutputMountPath
+		if len(parts) == 2 && parts[1] != "" {
+			outputPath += "/" + parts[1]
+		}
+		return volumes, volumeMounts, outputPath, nil
+	}
+	return nil, nil, "", fmt.Errorf("outputUri %s is not supported, must be one of: [s3://, pvc://]", outputURI)
+}
+
+// createShard builds the job of a shard. The pod carries the InferenceService pod label and the storage initializer
+// annotation so that the pod mutator downloads the input dataset the same way it downloads models.
+func createShard(job *v1alpha1api.BatchInferenceJob, shardIndex int, config *BatchInferenceConfig) (*batchv1.Job, error) {
+	volumes, volumeMounts, outputURI, err := outputVolume(job.Spec.OutputURI)
+	if err != nil {
+		return nil, err
+	}
+
+	workers, retries, backoffLimit := int32(1), int32(3), int32(3)
+	if job.Spec.Workers != nil {
+		workers = *job.Spec.Workers
+	}
+	if job.Spec.Retries != nil {
+		retries = *job.Spec.Retries
+	}
+	if job.Spec.BackoffLimit != nil {
+		backoffLimit = *job.Spec.BackoffLimit
+	}
+	labels := map[string]string{
+		constants.BatchInferenceJobLabel: job.Name,
+	}
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      constants.BatchInferenceJobShardName(job.Name, shardIndex),
+			Namespace: job.Namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						constants.BatchInferenceJobLabel:      job.Name,
+						constants.InferenceServicePodLabelKey: job.Spec.InferenceService,
+						constants.KServiceComponentLabel:      constants.BatchInferenceJobComponentLabel,
+					},
+					Annotations: map[string]string{
+						constants.StorageInitializerSourceUriInternalAnnotationKey: job.Spec.InputURI,
+					},
+				},
+				Spec: v1.PodSpec{
+					RestartPolicy:      v1.RestartPolicyNever,
+					ServiceAccountName: job.Spec.ServiceAccountName,
+					Containers: []v1.Container{
+						{
+							Name:  constants.InferenceServiceContainerName,
+							Image: config.Image,
+							Args: []string{
+								"-input-dir", constants.DefaultModelLocalMountPath,
+								"-output-uri", outputURI,
+								"-predictor-url", job.Status.PredictorURL,
+								"-shard-index", fmt.Sprint(shardIndex),
+								"-shard-count", fmt.Sprint(parallelism(job)),
+								"-workers", fmt.Sprint(workers),
+								"-retries", fmt.Sprint(retries),
+							},
+							VolumeMounts: volumeMounts,
+							Resources: v1.ResourceRequirements{
+								Limits: v1.ResourceList{
+									v1.ResourceCPU:    resource.MustParse(config.CpuLimit),
+									v1.ResourceMemory: resource.MustParse(config.MemoryLimit),
+								},
+								Requests: v1.ResourceList{
+									v1.ResourceCPU:    resource.MustParse(config.CpuRequest),
+									v1.ResourceMemory: resource.MustParse(config.MemoryRequest),
+								},
+							},
+						},
+					},
+					Volumes: volumes,
+				},
+			},
+		},
+	}, nil
+}
+
+// propagateShardStatus aggregates the status of the shard jobs, the record accounting is read from the termination
+// message of the succeeded runner pods.
+func propagateShardStatus(status *v1alpha1api.BatchInferenceJobStatus, parallelism int, shards []batchv1.Job, pods []v1.Pod) {
+	summaries := map[string]batch.Summary{}
+	for _, pod := range pods {
+		if pod.Status.Phase != v1.PodSucceeded {
+			continue
+		}
+		for _, container := range pod.Status.ContainerStatuses {
+			if container.Name != constants.InferenceServiceContainerName || container.State.Terminated == nil {
+				continue
+			}
+			summary := batch.Summary{}
+			if err := json.Unmarshal([]byte(container.State.Terminated.Message), &summary); err == nil {
+				summaries[pod.Labels["job-name"]] = summary
+			}
+		}
+	}
+
+	status.ActiveShards, status.SucceededShards, status.FailedShards = 0, 0, 0
+	status.ProcessedRecords, status.FailedRecords = 0, 0
+	for _, shard := range shards {
+		switch {
+		case shard.Status.Succeeded > 0:
+			status.SucceededShards++
+			summary := summaries[shard.Name]
+			status.ProcessedRecords += summary.Processed
+			status.FailedRecords += summary.Failed
+		case isJobFailed(&shard):
+			status.FailedShards++
+		default:
+			status.ActiveShards++
+		}
+	}
+
+	switch {
+	case status.ActiveShards > 0 || len(shards) < parallelism:
+		status.MarkShardsRunning(fmt.Sprintf("%d of %d shards completed", status.SucceededShards+status.FailedShards, parallelism))
+	case status.FailedShards > 0:
+		status.MarkShardsFailed(fmt.Sprintf("%d of %d shards failed", status.FailedShards, parallelism))
+	default:
+		status.MarkShardsSucceeded()
+	}
+	if status.IsDone() && status.CompletionTime == nil {
+		now := metav1.Now()
+		status.CompletionTime = &now
+	}
+}
+
+func isJobFailed(job *batchv1.Job) bool {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == batchv1.JobFailed && condition.Status == v1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *BatchInferenceJobReconciler) updateStatus(desired *v1alpha1api.BatchInferenceJob) error {
+	existing := &v1alpha1api.BatchInferenceJob{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Name: desired.Name, Namespace: desired.Namespace}, existing); err != nil {
+		return err
+	}
+	if equality.Semantic.DeepEqual(existing.Status, desired.Status) {
+		return nil
+	}
+	existing.Status = desired.Status
+	if err := r.Status().Update(context.TODO(), existing); err != nil {
+		r.Log.Error(err, "Failed to update BatchInferenceJob status", "BatchInferenceJob", desired.Name)
+		return errors.Wrapf(err, "fails to update BatchInferenceJob status")
+	}
+	return nil
+}
+
+func (r *BatchInferenceJobReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1api.BatchInferenceJob{}).
+		Owns(&batchv1.Job{}).
+		// Jobs waiting for their InferenceService are started once it becomes ready
+		Watches(&source.Kind{Type: &v1beta1api.InferenceService{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(r.batchInferenceJobRequestsForInferenceService),
+		}).
+		Complete(r)
+}
+
+// batchInferenceJobRequestsForInferenceService maps an InferenceService to the jobs which have not started yet
+func (r *BatchInferenceJobReconciler) batchInferenceJobRequestsForInferenceService(obj handler.MapObject) []reconcile.Request {
+	jobs := &v1alpha1api.BatchInferenceJobList{}
+	if err := r.List(context.TODO(), jobs, client.InNamespace(obj.Meta.GetNamespace())); err != nil {
+		r.Log.Error(err, "unable to list BatchInferenceJobs", "namespace", obj.Meta.GetNamespace())
+		return nil
+	}
+	requests := []reconcile.Request{}
+	for _, job := range jobs.Items {
+		if job.Spec.InferenceService == obj.Meta.GetName() && job.Status.StartTime == nil {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: job.Name, Namespace: job.Namespace},
+			})
+		}
+	}
+	return requests
+}
@@ -0,0 +1,181 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package batchinferencejob
+
+import (
+	"testing"
+
+	v1alpha1api "github.com/kubeflow/kfserving/pkg/apis/serving/v1alpha1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+)
+
+var config = &BatchInferenceConfig{
+	Image:         "kfserving/batch-runner:latest",
+	CpuRequest:    "100m",
+	CpuLimit:      "1",
+	MemoryRequest: "100Mi",
+	MemoryLimit:   "1Gi",
+}
+
+func newJob(outputURI string) *v1alpha1api.BatchInferenceJob {
+	parallelism := int32(2)
+	return &v1alpha1api.BatchInferenceJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "scoring", Namespace: "default"},
+		Spec: v1alpha1api.BatchInferenceJobSpec{
+			InferenceService: "sklearn",
+			InputURI:         "s3://data/input",
+			OutputURI:        outputURI,
+			Parallelism:      &parallelism,
+		},
+		Status: v1alpha1api.BatchInferenceJobStatus{
+			PredictorURL: "http://sklearn.default.svc.cluster.local/v1/models/sklearn:predict",
+		},
+	}
+}
+
+func TestCreateShard(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	shard, err := createShard(newJob("pvc://results/scoring"), 1, config)
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(shard.Name).To(gomega.Equal("scoring-shard-1"))
+	g.Expect(shard.Spec.Template.Labels).To(gomega.HaveKeyWithValue(constants.InferenceServicePodLabelKey, "sklearn"))
+	g.Expect(shard.Spec.Template.Annotations).To(gomega.HaveKeyWithValue(
+		constants.StorageInitializerSourceUriInternalAnnotationKey, "s3://data/input"))
+	g.Expect(shard.Spec.Template.Spec.Volumes[0].PersistentVolumeClaim.ClaimName).To(gomega.Equal("results"))
+	container := shard.Spec.Template.Spec.Containers[0]
+	g.Expect(container.Name).To(gomega.Equal(constants.InferenceServiceContainerName))
+	g.Expect(container.VolumeMounts[0].MountPath).To(gomega.Equal(OutputMountPath))
+	g.Expect(container.Args).To(gomega.Equal([]string{
+		"-input-dir", constants.DefaultModelLocalMountPath,
+		"-output-uri", "/mnt/output/scoring",
+		"-predictor-url", "http://sklearn.default.svc.cluster.local/v1/models/sklearn:predict",
+		"-shard-index", "1",
+		"-shard-count", "2",
+		"-workers", "1",
+		"-retries", "3",
+	}))
+
+	shard, err = createShard(newJob("s3://results/scoring"), 0, config)
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(shard.Spec.Template.Spec.Volumes).To(gomega.BeEmpty())
+	g.Expect(shard.Spec.Template.Spec.Containers[0].Args[3]).To(gomega.Equal("s3://results/scoring"))
+
+	_, err = createShard(newJob("gs://results/scoring"), 0, config)
+	g.Expect(err).ShouldNot(gomega.BeNil())
+}
+
+func TestPropagateShardStatus(t *testing.T) {
+	succeeded := batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: "scoring-shard-0"},
+		Status:     batchv1.JobStatus{Succeeded: 1},
+	}
+	running := batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: "scoring-shard-1"},
+		Status:     batchv1.JobStatus{Active: 1},
+	}
+	failed := batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: "scoring-shard-1"},
+		Status: batchv1.JobStatus{
+			Failed:     4,
+			Conditions: []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: v1.ConditionTrue}},
+		},
+	}
+	succeededPod := func(job string, message string) v1.Pod {
+		return v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"job-name": job}},
+			Status: v1.PodStatus{
+				Phase: v1.PodSucceeded,
+				ContainerStatuses: []v1.ContainerStatus{{
+					Name:  constants.InferenceServiceContainerName,
+					State: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{Message: message}},
+				}},
+			},
+		}
+	}
+	secondSucceeded := *succeeded.DeepCopy()
+	secondSucceeded.Name = "scoring-shard-1"
+
+	scenarios := map[string]struct {
+		shards                    []batchv1.Job
+		pods                      []v1.Pod
+		expectedStatus            apis.ConditionStatus
+		expectedActive            int32
+		expectedSucceeded         int32
+		expectedFailed            int32
+		expectedProcessed         int64
+		expectedFailedRecords     int64
+		expectedCompletionTimeSet bool
+	}{
+		"Running": {
+			shards:                []batchv1.Job{succeeded, running},
+			pods:                  []v1.Pod{succeededPod("scoring-shard-0", `{"processed":10,"failed":1}`)},
+			expectedStatus:        v1.ConditionUnknown,
+			expectedActive:        1,
+			expectedSucceeded:     1,
+			expectedProcessed:     10,
+			expectedFailedRecords: 1,
+		},
+		"MissingShard": {
+			shards:            []batchv1.Job{succeeded},
+			expectedStatus:    v1.ConditionUnknown,
+			expectedSucceeded: 1,
+		},
+		"Succeeded": {
+			shards: []batchv1.Job{succeeded, secondSucceeded},
+			pods: []v1.Pod{
+				succeededPod("scoring-shard-0", `{"processed":10,"failed":1}`),
+				succeededPod("scoring-shard-1", `{"processed":9,"failed":0}`),
+			},
+			expectedStatus:            v1.ConditionTrue,
+			expectedSucceeded:         2,
+			expectedProcessed:         19,
+			expectedFailedRecords:     1,
+			expectedCompletionTimeSet: true,
+		},
+		"Failed": {
+			shards:                    []batchv1.Job{succeeded, failed},
+			pods:                      []v1.Pod{succeededPod("scoring-shard-0", `{"processed":10,"failed":0}`)},
+			expectedStatus:            v1.ConditionFalse,
+			expectedSucceeded:         1,
+			expectedFailed:            1,
+			expectedProcessed:         10,
+			expectedCompletionTimeSet: true,
+		},
+	}
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			g := gomega.NewGomegaWithT(t)
+			status := &v1alpha1api.BatchInferenceJobStatus{}
+			status.InitializeConditions()
+			status.MarkInferenceServiceReady()
+			propagateShardStatus(status, 2, scenario.shards, scenario.pods)
+			g.Expect(status.GetCondition(apis.ConditionSucceeded).Status).To(gomega.Equal(scenario.expectedStatus))
+			g.Expect(status.ActiveShards).To(gomega.Equal(scenario.expectedActive))
+			g.Expect(status.SucceededShards).To(gomega.Equal(scenario.expectedSucceeded))
+			g.Expect(status.FailedShards).To(gomega.Equal(scenario.expectedFailed))
+			g.Expect(status.ProcessedRecords).To(gomega.Equal(scenario.expectedProcessed))
+			g.Expect(status.FailedRecords).To(gomega.Equal(scenario.expectedFailedRecords))
+			g.Expect(status.CompletionTime != nil).To(gomega.Equal(scenario.expectedCompletionTimeSet))
+		})
+	}
+}
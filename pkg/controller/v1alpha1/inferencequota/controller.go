@@ -0,0 +1,100 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// +kubebuilder:rbac:groups=serving.kubeflow.org,resources=inferencequotas,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=serving.kubeflow.org,resources=inferencequotas/status,verbs=get;update;patch
+package inferencequota
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	v1alpha1api "github.com/kubeflow/kfserving/pkg/apis/serving/v1alpha1"
+	v1beta1api "github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/quota"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// InferenceQuotaReconciler reports the usage of an InferenceQuota object, the quota is enforced by the
+// InferenceService controller
+type InferenceQuotaReconciler struct {
+	client.Client
+	Log    logr.Logger
+	Scheme *runtime.Scheme
+}
+
+func (r *InferenceQuotaReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	inferenceQuota := &v1alpha1api.InferenceQuota{}
+	if err := r.Get(context.TODO(), req.NamespacedName, inferenceQuota); err != nil {
+		if apierr.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+	isvcs := &v1beta1api.InferenceServiceList{}
+	if err := r.List(context.TODO(), isvcs, client.InNamespace(inferenceQuota.Namespace)); err != nil {
+		return reconcile.Result{}, errors.Wrapf(err, "fails to list InferenceServices")
+	}
+	used, pending := quota.Usage(isvcs.Items)
+	status := v1alpha1api.InferenceQuotaStatus{Used: used}
+	if len(pending) != 0 {
+		status.Pending = pending
+	}
+	if equality.Semantic.DeepEqual(inferenceQuota.Status, status) {
+		return reconcile.Result{}, nil
+	}
+	inferenceQuota.Status = status
+	if err := r.Status().Update(context.TODO(), inferenceQuota); err != nil {
+		r.Log.Error(err, "Failed to update InferenceQuota status", "InferenceQuota", inferenceQuota.Name)
+		return reconcile.Result{}, errors.Wrapf(err, "fails to update InferenceQuota status")
+	}
+	return reconcile.Result{}, nil
+}
+
+func (r *InferenceQuotaReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1api.InferenceQuota{}).
+		// The usage changes with the InferenceServices of the namespace
+		Watches(&source.Kind{Type: &v1beta1api.InferenceService{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(r.inferenceQuotaRequestsForInferenceService),
+		}).
+		Complete(r)
+}
+
+// inferenceQuotaRequestsForInferenceService maps an InferenceService to the quotas of its namespace
+func (r *InferenceQuotaReconciler) inferenceQuotaRequestsForInferenceService(obj handler.MapObject) []reconcile.Request {
+	quotas := &v1alpha1api.InferenceQuotaList{}
+	if err := r.List(context.TODO(), quotas, client.InNamespace(obj.Meta.GetNamespace())); err != nil {
+		r.Log.Error(err, "unable to list InferenceQuotas", "namespace", obj.Meta.GetNamespace())
+		return nil
+	}
+	requests := []reconcile.Request{}
+	for _, inferenceQuota := range quotas.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: inferenceQuota.Name, Namespace: inferenceQuota.Namespace},
+		})
+	}
+	return requests
+}
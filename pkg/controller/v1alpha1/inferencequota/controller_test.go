@@ -0,0 +1,160 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inferencequota
+
+import (
+	"context"
+	"time"
+
+	v1alpha1api "github.com/kubeflow/kfserving/pkg/apis/serving/v1alpha1"
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("v1alpha1 InferenceQuota controller", func() {
+	// Define utility constants for object names and testing timeouts/durations and intervals.
+	const (
+		timeout  = time.Second * 20
+		interval = time.Millisecond * 250
+	)
+
+	var (
+		storageUri = "s3://test/sklearn/model"
+	)
+
+	newInferenceService := func(name string, namespace string) *v1beta1.InferenceService {
+		return &v1beta1.InferenceService{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+			Spec: v1beta1.InferenceServiceSpec{
+				Predictor: v1beta1.PredictorSpec{
+					ComponentExtensionSpec: v1beta1.ComponentExtensionSpec{
+						MinReplicas: v1beta1.GetIntReference(1),
+						MaxReplicas: 2,
+					},
+					SKLearn: &v1beta1.SKLearnSpec{
+						PredictorExtensionSpec: v1beta1.PredictorExtensionSpec{
+							StorageURI: &storageUri,
+						},
+					},
+				},
+			},
+		}
+	}
+
+	newInferenceQuota := func(name string, namespace string) *v1alpha1api.InferenceQuota {
+		maxReplicas := int32(10)
+		return &v1alpha1api.InferenceQuota{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+			Spec: v1alpha1api.InferenceQuotaSpec{
+				MaxReplicas: &maxReplicas,
+			},
+		}
+	}
+
+	getStatus := func(key types.NamespacedName) func() v1alpha1api.InferenceQuotaStatus {
+		return func() v1alpha1api.InferenceQuotaStatus {
+			inferenceQuota := &v1alpha1api.InferenceQuota{}
+			if err := k8sClient.Get(context.TODO(), key, inferenceQuota); err != nil {
+				return v1alpha1api.InferenceQuotaStatus{}
+			}
+			return inferenceQuota.Status
+		}
+	}
+
+	Context("When the InferenceServices of the namespace change", func() {
+		It("Should report the usage of the admitted InferenceServices", func() {
+			ctx := context.Background()
+			quotaKey := types.NamespacedName{Name: "quota-usage", Namespace: "default"}
+			inferenceQuota := newInferenceQuota(quotaKey.Name, quotaKey.Namespace)
+			Expect(k8sClient.Create(ctx, inferenceQuota)).Should(Succeed())
+			defer k8sClient.Delete(ctx, inferenceQuota)
+
+			// The InferenceService is pending until the InferenceService controller admits it
+			isvc := newInferenceService("quota-usage-isvc", quotaKey.Namespace)
+			Expect(k8sClient.Create(ctx, isvc)).Should(Succeed())
+			Eventually(getStatus(quotaKey), timeout, interval).Should(Equal(v1alpha1api.InferenceQuotaStatus{
+				Pending: []string{isvc.Name},
+			}))
+
+			// Admitting the InferenceService counts its replicas
+			isvc.Status.AdmittedGeneration = isvc.Generation
+			Expect(k8sClient.Status().Update(ctx, isvc)).Should(Succeed())
+			Eventually(getStatus(quotaKey), timeout, interval).Should(Equal(v1alpha1api.InferenceQuotaStatus{
+				Used: v1alpha1api.QuotaUsage{InferenceServices: 1, Replicas: 2},
+			}))
+
+			// Scaling the admitted InferenceService updates the usage
+			Eventually(func() error {
+				updated := &v1beta1.InferenceService{}
+				if err := k8sClient.Get(ctx, types.NamespacedName{Name: isvc.Name, Namespace: isvc.Namespace}, updated); err != nil {
+					return err
+				}
+				updated.Spec.Predictor.MaxReplicas = 4
+				return k8sClient.Update(ctx, updated)
+			}, timeout, interval).Should(Succeed())
+			Eventually(getStatus(quotaKey), timeout, interval).Should(Equal(v1alpha1api.InferenceQuotaStatus{
+				Used: v1alpha1api.QuotaUsage{InferenceServices: 1, Replicas: 4},
+			}))
+
+			// Deleting the InferenceService frees its capacity
+			Expect(k8sClient.Delete(ctx, isvc)).Should(Succeed())
+			Eventually(getStatus(quotaKey), timeout, interval).Should(Equal(v1alpha1api.InferenceQuotaStatus{}))
+		})
+	})
+
+	Context("When an InferenceService changes", func() {
+		It("Should map it to the InferenceQuotas of its namespace", func() {
+			ctx := context.Background()
+			otherNamespace := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "quota-other"}}
+			Expect(k8sClient.Create(ctx, otherNamespace)).Should(Succeed())
+			defer k8sClient.Delete(ctx, otherNamespace)
+			for _, inferenceQuota := range []*v1alpha1api.InferenceQuota{
+				newInferenceQuota("quota-gpus", "default"),
+				newInferenceQuota("quota-replicas", "default"),
+				newInferenceQuota("quota-other", otherNamespace.Name),
+			} {
+				Expect(k8sClient.Create(ctx, inferenceQuota)).Should(Succeed())
+				defer k8sClient.Delete(ctx, inferenceQuota)
+			}
+
+			reconciler := &InferenceQuotaReconciler{
+				Client: k8sClient,
+				Log:    ctrl.Log.WithName("v1alpha1InferenceQuotaController"),
+			}
+			isvc := newInferenceService("quota-mapped-isvc", "default")
+			Eventually(func() []reconcile.Request {
+				return reconciler.inferenceQuotaRequestsForInferenceService(handler.MapObject{Meta: isvc, Object: isvc})
+			}, timeout, interval).Should(ConsistOf(
+				reconcile.Request{NamespacedName: types.NamespacedName{Name: "quota-gpus", Namespace: "default"}},
+				reconcile.Request{NamespacedName: types.NamespacedName{Name: "quota-replicas", Namespace: "default"}},
+			))
+		})
+	})
+})
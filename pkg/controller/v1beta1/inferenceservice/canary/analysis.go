@@ -0,0 +1,216 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package canary runs the canary analysis of the components, the candidate revision is promoted through the traffic
+// steps while its metrics are within their thresholds and rolled back after consecutive failed checks. The knative
+// reconciler routes the traffic according to the analysis status.
+package canary
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+var log = logf.Log.WithName("CanaryAnalysis")
+
+const (
+	DefaultIntervalSeconds  = 60
+	DefaultFailureThreshold = 3
+	// MaxReports is the number of checks whose metrics are kept in the analysis status
+	MaxReports = 10
+)
+
+// QueryParameters are the values of the canary metric query templates
+type QueryParameters struct {
+	Namespace string
+	Name      string
+	Component string
+	Revision  string
+}
+
+func interval(analysis *v1beta1.CanaryAnalysis) time.Duration {
+	if analysis.IntervalSeconds == 0 {
+		return DefaultIntervalSeconds * time.Second
+	}
+	return time.Duration(analysis.IntervalSeconds) * time.Second
+}
+
+func failureThreshold(analysis *v1beta1.CanaryAnalysis) int {
+	if analysis.FailureThreshold == 0 {
+		return DefaultFailureThreshold
+	}
+	return analysis.FailureThreshold
+}
+
+// Analyze starts the analysis of a new candidate revision of the component, or checks the metrics of the candidate
+// once the interval has elapsed since the last check. The metrics client is nil when no metrics server is configured.
+func Analyze(isvc *v1beta1.InferenceService, component v1beta1.ComponentType, extension *v1beta1.ComponentExtensionSpec,
+	metrics MetricsClient, now time.Time) {
+	statusSpec, ok := isvc.Status.Components[component]
+	if !ok {
+		return
+	}
+	analysis := extension.CanaryAnalysis
+	// The first revision has no previous revision to compare with, it receives all the traffic
+	if analysis == nil || statusSpec.PreviousReadyRevision == "" || statusSpec.LatestReadyRevision == "" {
+		statusSpec.CanaryAnalysis = nil
+		isvc.Status.Components[component] = statusSpec
+		return
+	}
+	// The traffic is not shifted to a revision which is not warmed up yet, so its analysis has not started
+	if extension.WarmUp != nil && statusSpec.WarmedUpRevision != statusSpec.LatestReadyRevision {
+		return
+	}
+	status := statusSpec.CanaryAnalysis
+	if status == nil || status.Revision != statusSpec.LatestReadyRevision {
+		log.Info("Starting canary analysis", "namespace", isvc.Namespace, "revision", statusSpec.LatestReadyRevision)
+		statusSpec.CanaryAnalysis = &v1beta1.CanaryAnalysisStatus{
+			Revision:       statusSpec.LatestReadyRevision,
+			Phase:          v1beta1.CanaryProgressing,
+			LastCheckTime:  metav1.NewTime(now),
+			StableRevision: statusSpec.PreviousReadyRevision,
+		}
+		isvc.Status.Components[component] = statusSpec
+		return
+	}
+	if status.Phase != v1beta1.CanaryProgressing || now.Sub(status.LastCheckTime.Time) < interval(analysis) {
+		return
+	}
+	status = status.DeepCopy()
+	status.LastCheckTime = metav1.NewTime(now)
+	if metrics == nil && len(analysis.Metrics) != 0 {
+		status.Message = "No metrics server is configured in the metrics config of the inferenceservice configmap"
+		statusSpec.CanaryAnalysis = status
+		isvc.Status.Components[component] = statusSpec
+		return
+	}
+	parameters := QueryParameters{
+		Namespace: isvc.Namespace,
+		Name:      isvc.Name,
+		Component: string(component),
+		Revision:  status.Revision,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), QueryTimeout)
+	defer cancel()
+	report, violations := check(ctx, analysis.Metrics, metrics, parameters, status.StableRevision)
+	report.Time = status.LastCheckTime
+	report.Step = status.Step
+	report.Passed = len(violations) == 0
+	status.Reports = append(status.Reports, report)
+	if len(status.Reports) > MaxReports {
+		status.Reports = status.Reports[len(status.Reports)-MaxReports:]
+	}
+	if len(violations) != 0 {
+		status.FailedChecks++
+		status.Message = strings.Join(violations, ", ")
+		if status.FailedChecks >= failureThreshold(analysis) {
+			log.Info("Rolling back canary", "namespace", isvc.Namespace, "revision", status.Revision, "violations", status.Message)
+			status.Phase = v1beta1.CanaryRolledBack
+		}
+	} else {
+		status.FailedChecks = 0
+		status.Message = ""
+		status.Step++
+		if status.Step >= len(analysis.Steps) {
+			log.Info("Promoting canary", "namespace", isvc.Namespace, "revision", status.Revision)
+			status.Step = len(analysis.Steps) - 1
+			status.Phase = v1beta1.CanaryPromoted
+		}
+	}
+	statusSpec.CanaryAnalysis = status
+	isvc.Status.Components[component] = statusSpec
+}
+
+// check returns the report of the metrics of the candidate and the stable revisions, and the metrics which failed
+// or are above their thresholds for the candidate revision. The stable revision is only reported, its query errors
+// do not fail the check. The queries of all the metrics share the deadline of the context.
+func check(ctx context.Context, canaryMetrics []v1beta1.CanaryMetric, metrics MetricsClient, parameters QueryParameters,
+	stableRevision string) (v1beta1.CanaryReport, []string) {
+	report := v1beta1.CanaryReport{}
+	violations := []string{}
+	stableParameters := parameters
+	stableParameters.Revision = stableRevision
+	for _, metric := range canaryMetrics {
+		metricReport := v1beta1.CanaryMetricReport{
+			Name:      metric.Name,
+			Threshold: formatValue(metric.Threshold),
+		}
+		if value, err := query(ctx, metric.Query, metrics, parameters); err != nil {
+			metricReport.Error = err.Error()
+			violations = append(violations, fmt.Sprintf("%s: %v", metric.Name, err))
+		} else {
+			metricReport.Canary = formatValue(value)
+			if value > metric.Threshold {
+				violations = append(violations, fmt.Sprintf("%s: %g is above %g", metric.Name, value, metric.Threshold))
+			}
+		}
+		if stableRevision != "" {
+			if value, err := query(ctx, metric.Query, metrics, stableParameters); err == nil {
+				metricReport.Stable = formatValue(value)
+			}
+		}
+		report.Metrics = append(report.Metrics, metricReport)
+	}
+	return report, violations
+}
+
+func query(ctx context.Context, queryTemplate string, metrics MetricsClient, parameters QueryParameters) (float64, error) {
+	query, err := RenderQuery(queryTemplate, parameters)
+	if err != nil {
+		return 0, err
+	}
+	return metrics.Query(ctx, query)
+}
+
+func formatValue(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// RenderQuery executes the query template with the parameters
+func RenderQuery(query string, parameters QueryParameters) (string, error) {
+	tmpl, err := template.New("query").Parse(query)
+	if err != nil {
+		return "", err
+	}
+	buf := &bytes.Buffer{}
+	if err := tmpl.Execute(buf, parameters); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// NextCheck returns the delay until the next check of the canary analysis of the component, or zero if the analysis
+// is not progressing
+func NextCheck(extension *v1beta1.ComponentExtensionSpec, statusSpec v1beta1.ComponentStatusSpec, now time.Time) time.Duration {
+	status := statusSpec.CanaryAnalysis
+	if extension.CanaryAnalysis == nil || status == nil || status.Phase != v1beta1.CanaryProgressing {
+		return 0
+	}
+	next := status.LastCheckTime.Add(interval(extension.CanaryAnalysis)).Sub(now)
+	if next < time.Second {
+		return time.Second
+	}
+	return next
+}
@@ -0,0 +1,214 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package canary
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// fakeMetrics returns the value of the queries, or an error for the unknown ones
+type fakeMetrics map[string]float64
+
+func (m fakeMetrics) Query(ctx context.Context, query string) (float64, error) {
+	if value, ok := m[query]; ok {
+		return value, nil
+	}
+	return 0, fmt.Errorf("no data")
+}
+
+func newInferenceService() *v1beta1.InferenceService {
+	return &v1beta1.InferenceService{
+		ObjectMeta: metav1.ObjectMeta{Name: "sklearn", Namespace: "default"},
+		Spec: v1beta1.InferenceServiceSpec{
+			Predictor: v1beta1.PredictorSpec{
+				ComponentExtensionSpec: v1beta1.ComponentExtensionSpec{
+					CanaryAnalysis: &v1beta1.CanaryAnalysis{
+						Steps:            []int64{10, 50},
+						FailureThreshold: 2,
+						Metrics: []v1beta1.CanaryMetric{{
+							Name:      "error-rate",
+							Query:     `errors{namespace="{{.Namespace}}",revision="{{.Revision}}"}`,
+							Threshold: 0.01,
+						}},
+					},
+				},
+			},
+		},
+		Status: v1beta1.InferenceServiceStatus{
+			Components: map[v1beta1.ComponentType]v1beta1.ComponentStatusSpec{
+				v1beta1.PredictorComponent: {
+					LatestReadyRevision:   "sklearn-predictor-default-00002",
+					PreviousReadyRevision: "sklearn-predictor-default-00001",
+				},
+			},
+		},
+	}
+}
+
+func analyze(isvc *v1beta1.InferenceService, metrics MetricsClient, now time.Time) *v1beta1.CanaryAnalysisStatus {
+	Analyze(isvc, v1beta1.PredictorComponent, &isvc.Spec.Predictor.ComponentExtensionSpec, metrics, now)
+	return isvc.Status.Components[v1beta1.PredictorComponent].CanaryAnalysis
+}
+
+func TestAnalyzePromotes(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := newInferenceService()
+	metrics := fakeMetrics{`errors{namespace="default",revision="sklearn-predictor-default-00002"}`: 0}
+	now := time.Now()
+
+	status := analyze(isvc, metrics, now)
+	g.Expect(status.Phase).To(gomega.Equal(v1beta1.CanaryProgressing))
+	g.Expect(status.Step).To(gomega.Equal(0))
+
+	// the metrics are not checked before the interval elapsed
+	status = analyze(isvc, metrics, now.Add(30*time.Second))
+	g.Expect(status.Step).To(gomega.Equal(0))
+	g.Expect(NextCheck(&isvc.Spec.Predictor.ComponentExtensionSpec, isvc.Status.Components[v1beta1.PredictorComponent],
+		now.Add(30*time.Second))).To(gomega.Equal(30 * time.Second))
+
+	status = analyze(isvc, metrics, now.Add(time.Minute))
+	g.Expect(status.Step).To(gomega.Equal(1))
+	status = analyze(isvc, metrics, now.Add(2*time.Minute))
+	g.Expect(status.Phase).To(gomega.Equal(v1beta1.CanaryPromoted))
+	g.Expect(NextCheck(&isvc.Spec.Predictor.ComponentExtensionSpec, isvc.Status.Components[v1beta1.PredictorComponent],
+		now.Add(2*time.Minute))).To(gomega.BeZero())
+}
+
+func TestAnalyzeRollsBack(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := newInferenceService()
+	metrics := fakeMetrics{`errors{namespace="default",revision="sklearn-predictor-default-00002"}`: 0.2}
+	now := time.Now()
+
+	analyze(isvc, metrics, now)
+	status := analyze(isvc, metrics, now.Add(time.Minute))
+	g.Expect(status.Phase).To(gomega.Equal(v1beta1.CanaryProgressing))
+	g.Expect(status.FailedChecks).To(gomega.Equal(1))
+	g.Expect(status.Message).To(gomega.Equal("error-rate: 0.2 is above 0.01"))
+
+	// query errors fail the check as well
+	status = analyze(isvc, fakeMetrics{}, now.Add(2*time.Minute))
+	g.Expect(status.Phase).To(gomega.Equal(v1beta1.CanaryRolledBack))
+	g.Expect(status.Message).To(gomega.Equal("error-rate: no data"))
+
+	// a new revision starts a new analysis
+	statusSpec := isvc.Status.Components[v1beta1.PredictorComponent]
+	statusSpec.PreviousReadyRevision = statusSpec.LatestReadyRevision
+	statusSpec.LatestReadyRevision = "sklearn-predictor-default-00003"
+	isvc.Status.Components[v1beta1.PredictorComponent] = statusSpec
+	status = analyze(isvc, metrics, now.Add(3*time.Minute))
+	g.Expect(status.Revision).To(gomega.Equal("sklearn-predictor-default-00003"))
+	g.Expect(status.Phase).To(gomega.Equal(v1beta1.CanaryProgressing))
+}
+
+func TestAnalyzeReportsMetrics(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := newInferenceService()
+	metrics := fakeMetrics{
+		`errors{namespace="default",revision="sklearn-predictor-default-00002"}`: 0.02,
+		`errors{namespace="default",revision="sklearn-predictor-default-00001"}`: 0.005,
+	}
+	now := time.Now()
+
+	status := analyze(isvc, metrics, now)
+	g.Expect(status.StableRevision).To(gomega.Equal("sklearn-predictor-default-00001"))
+	g.Expect(status.Reports).To(gomega.BeEmpty())
+
+	status = analyze(isvc, metrics, now.Add(time.Minute))
+	g.Expect(status.Reports).To(gomega.Equal([]v1beta1.CanaryReport{{
+		Time:   metav1.NewTime(now.Add(time.Minute)),
+		Passed: false,
+		Metrics: []v1beta1.CanaryMetricReport{{
+			Name:      "error-rate",
+			Canary:    "0.02",
+			Stable:    "0.005",
+			Threshold: "0.01",
+		}},
+	}}))
+
+	// the stable revision is reported without failing the check when its query fails
+	delete(metrics, `errors{namespace="default",revision="sklearn-predictor-default-00001"}`)
+	metrics[`errors{namespace="default",revision="sklearn-predictor-default-00002"}`] = 0
+	status = analyze(isvc, metrics, now.Add(2*time.Minute))
+	g.Expect(status.Reports).To(gomega.HaveLen(2))
+	g.Expect(status.Reports[1].Passed).To(gomega.BeTrue())
+	g.Expect(status.Reports[1].Metrics[0].Stable).To(gomega.BeEmpty())
+	g.Expect(status.Step).To(gomega.Equal(1))
+
+	// only the last reports are kept
+	isvc.Spec.Predictor.CanaryAnalysis.Steps = []int64{10, 20, 30, 40, 50, 60, 70, 80, 90, 95, 99}
+	for i := 3; i < 3+MaxReports; i++ {
+		status = analyze(isvc, metrics, now.Add(time.Duration(i)*time.Minute))
+	}
+	g.Expect(status.Reports).To(gomega.HaveLen(MaxReports))
+	g.Expect(status.Reports[MaxReports-1].Time).To(gomega.Equal(metav1.NewTime(now.Add(time.Duration(2+MaxReports) * time.Minute))))
+}
+
+func TestAnalyzeWithoutPreviousRevision(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := newInferenceService()
+	isvc.Status.Components[v1beta1.PredictorComponent] = v1beta1.ComponentStatusSpec{
+		LatestReadyRevision: "sklearn-predictor-default-00001",
+	}
+	g.Expect(analyze(isvc, fakeMetrics{}, time.Now())).To(gomega.BeNil())
+}
+
+func TestPrometheusClient(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Query().Get("query") {
+		case "scalar(up)":
+			fmt.Fprint(w, `{"status":"success","data":{"resultType":"scalar","result":[1609459200,"0.5"]}}`)
+		case "up":
+			fmt.Fprint(w, `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1609459200,"2"]}]}}`)
+		case "slow":
+			<-req.Context().Done()
+		default:
+			fmt.Fprint(w, `{"status":"success","data":{"resultType":"vector","result":[]}}`)
+		}
+	}))
+	defer server.Close()
+	client := NewPrometheusClient(server.URL + "/")
+	ctx := context.Background()
+
+	g.Expect(client.Query(ctx, "scalar(up)")).To(gomega.Equal(0.5))
+	g.Expect(client.Query(ctx, "up")).To(gomega.Equal(2.0))
+	_, err := client.Query(ctx, "absent")
+	g.Expect(err).To(gomega.MatchError("query returned 0 series instead of one"))
+
+	// the queries of a check share the deadline of the context
+	ctx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	parameters := QueryParameters{Namespace: "default", Name: "sklearn", Component: "predictor", Revision: "sklearn-predictor-default-00002"}
+	start := time.Now()
+	report, violations := check(ctx, []v1beta1.CanaryMetric{
+		{Name: "slow", Query: "slow", Threshold: 1},
+		{Name: "up", Query: "up", Threshold: 1},
+	}, client, parameters, "sklearn-predictor-default-00001")
+	g.Expect(time.Since(start)).To(gomega.BeNumerically("<", time.Second))
+	g.Expect(violations).To(gomega.HaveLen(2))
+	g.Expect(report.Metrics[0].Error).To(gomega.ContainSubstring("context deadline exceeded"))
+}
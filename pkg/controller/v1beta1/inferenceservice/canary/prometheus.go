@@ -0,0 +1,111 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package canary
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// QueryTimeout bounds all the queries of an evaluation, e.g. the queries of all the metrics of a canary check, so that
+// a slow metrics server does not block the reconcile
+const QueryTimeout = 5 * time.Second
+
+// MetricsClient evaluates the queries of the canary metrics
+type MetricsClient interface {
+	// Query returns the single value of an instant query, the query is canceled with the context
+	Query(ctx context.Context, query string) (float64, error)
+}
+
+// PrometheusClient queries the instant query API of a Prometheus server
+type PrometheusClient struct {
+	URL        string
+	HTTPClient *http.Client
+}
+
+var _ MetricsClient = &PrometheusClient{}
+
+func NewPrometheusClient(url string) *PrometheusClient {
+	return &PrometheusClient{
+		URL:        strings.TrimSuffix(url, "/"),
+		HTTPClient: &http.Client{},
+	}
+}
+
+type queryResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Data   struct {
+		ResultType string          `json:"resultType"`
+		Result     json.RawMessage `json:"result"`
+	} `json:"data"`
+}
+
+type vectorSample struct {
+	Value []interface{} `json:"value"`
+}
+
+func (c *PrometheusClient) Query(ctx context.Context, query string) (float64, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, c.URL+"/api/v1/query?query="+url.QueryEscape(query), nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := c.HTTPClient.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	response := &queryResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(response); err != nil {
+		return 0, fmt.Errorf("fails to decode the response of prometheus: %v", err)
+	}
+	if response.Status != "success" {
+		return 0, fmt.Errorf("prometheus query failed: %s", response.Error)
+	}
+	var value []interface{}
+	switch response.Data.ResultType {
+	case "scalar":
+		if err := json.Unmarshal(response.Data.Result, &value); err != nil {
+			return 0, err
+		}
+	case "vector":
+		samples := []vectorSample{}
+		if err := json.Unmarshal(response.Data.Result, &samples); err != nil {
+			return 0, err
+		}
+		if len(samples) != 1 {
+			return 0, fmt.Errorf("query returned %d series instead of one", len(samples))
+		}
+		value = samples[0].Value
+	default:
+		return 0, fmt.Errorf("query returned a %s instead of a scalar or a vector", response.Data.ResultType)
+	}
+	if len(value) != 2 {
+		return 0, fmt.Errorf("query returned an invalid sample")
+	}
+	sample, ok := value[1].(string)
+	if !ok {
+		return 0, fmt.Errorf("query returned an invalid sample")
+	}
+	return strconv.ParseFloat(sample, 64)
+}
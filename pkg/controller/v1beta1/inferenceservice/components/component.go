@@ -13,9 +13,40 @@ limitations under the License.
 
 package components
 
-import "github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+import (
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/canary"
+	"k8s.io/apimachinery/pkg/runtime"
+)
 
 // Component can be reconciled to create underlying resources for an InferenceService
 type Component interface {
 	Reconcile(isvc *v1beta1.InferenceService) error
+	// Render returns the underlying resources of the component without applying them
+	Render(isvc *v1beta1.InferenceService) ([]runtime.Object, error)
+}
+
+// addPlacementAnnotations passes the placement policy of the component to the pod mutator
+func addPlacementAnnotations(extension *v1beta1.ComponentExtensionSpec, annotations map[string]string) {
+	if extension.PlacementPolicy == v1beta1.PreferSpotPlacement {
+		annotations[constants.PlacementPolicyInternalAnnotationKey] = string(extension.PlacementPolicy)
+	}
+}
+
+// addRuntimeAnnotations passes the runtime of the component to the pod mutator, which mounts the scratch volumes of
+// the runtime in the read-only model server containers
+func addRuntimeAnnotations(implementation v1beta1.ComponentImplementation, annotations map[string]string) {
+	if name := v1beta1.GetRuntimeName(implementation); name != "" {
+		annotations[constants.RuntimeInternalAnnotationKey] = name
+	}
+}
+
+// newMetricsClient returns the client of the metrics server queried by the canary analysis and the predictive scaling,
+// or nil if none is configured
+func newMetricsClient(config *v1beta1.InferenceServicesConfig) canary.MetricsClient {
+	if config.Metrics == nil || config.Metrics.PrometheusURL == "" {
+		return nil
+	}
+	return canary.NewPrometheusClient(config.Metrics.PrometheusURL)
 }
@@ -0,0 +1,159 @@
+/*
+Copyright 2020 kubeflow.org.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package components
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/canary"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/predictive"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/reconcilers/knative"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/rollout"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/warmup"
+	"github.com/kubeflow/kfserving/pkg/digest"
+	"github.com/kubeflow/kfserving/pkg/utils"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	knservingv1 "knative.dev/serving/pkg/apis/serving/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// detectorServiceNames are the names of the knative services of the detectors
+var detectorServiceNames = map[v1beta1.ComponentType]func(name string) string{
+	v1beta1.DriftDetectorComponent:   constants.DefaultDriftDetectorServiceName,
+	v1beta1.OutlierDetectorComponent: constants.DefaultOutlierDetectorServiceName,
+}
+
+// detector reconciles the knative service shared by the drift and outlier detectors, which is only reachable in
+// the cluster
+type detector struct {
+	client                 client.Client
+	scheme                 *runtime.Scheme
+	inferenceServiceConfig *v1beta1.InferenceServicesConfig
+	component              v1beta1.ComponentType
+	// description of the detector in the errors, e.g. "drift detector"
+	description string
+	Log         logr.Logger
+}
+
+// reconcile applies the knative service of the detector and propagates its status, the metrics client is returned
+// for the checks specific to the detector
+func (d *detector) reconcile(isvc *v1beta1.InferenceService, spec v1beta1.Component, podSpec *v1beta1.PodSpec) (canary.MetricsClient, error) {
+	extension := spec.GetExtensions()
+	// The new revision is warmed up in the background, the knative service routes the traffic to it once it is warmed up
+	warmup.NewWarmUpReconciler(d.client).Reconcile(isvc, d.component, extension.WarmUp, "/")
+	rollout.Reconcile(isvc, d.component, extension, time.Now())
+	metrics := newMetricsClient(d.inferenceServiceConfig)
+	canary.Analyze(isvc, d.component, extension, metrics, time.Now())
+	predictive.Forecast(isvc, d.component, extension, metrics, time.Now())
+	r, err := d.newKsvcReconciler(isvc, spec, podSpec)
+	if err != nil {
+		return nil, err
+	}
+	status, err := r.Reconcile()
+	if err != nil {
+		return nil, errors.Wrapf(err, "fails to reconcile %s", d.description)
+	}
+	isvc.Status.PropagateStatus(d.component, status)
+	// The stale revisions are collected again on the next reconcile when the collection fails
+	statusSpec := isvc.Status.Components[d.component]
+	if err := r.CollectGarbage(&statusSpec, time.Now()); err != nil {
+		d.Log.Error(err, "Failed to collect "+d.description+" revisions", "service", r.Service.Name)
+	}
+	isvc.Status.Components[d.component] = statusSpec
+	isvc.Status.PropagateDrift("knative service "+r.Service.Name, r.Drifted)
+	return metrics, nil
+}
+
+// render returns the knative service of the detector without applying it
+func (d *detector) render(isvc *v1beta1.InferenceService, spec v1beta1.Component, podSpec *v1beta1.PodSpec) ([]runtime.Object, error) {
+	r, err := d.newKsvcReconciler(isvc, spec, podSpec)
+	if err != nil {
+		return nil, err
+	}
+	return []runtime.Object{r.Service}, nil
+}
+
+// newKsvcReconciler builds the desired knative service of the detector
+func (d *detector) newKsvcReconciler(isvc *v1beta1.InferenceService, spec v1beta1.Component,
+	podSpec *v1beta1.PodSpec) (*knative.KsvcReconciler, error) {
+	implementation := spec.GetImplementation()
+	propagation := d.inferenceServiceConfig.Propagation
+	annotations := utils.Filter(isvc.Annotations, func(key string) bool {
+		return !utils.Includes(constants.ServiceAnnotationDisallowedList, key) && propagation.PropagatesAnnotation(key)
+	})
+	// KNative does not support INIT containers or mounting, so we add annotations that trigger the
+	// StorageInitializer injector to mutate the underlying deployment to provision the detector
+	if sourceURI := implementation.GetStorageUri(); sourceURI != nil && *sourceURI != "" {
+		annotations[constants.StorageInitializerSourceUriInternalAnnotationKey] = *sourceURI
+	}
+	addPlacementAnnotations(spec.GetExtensions(), annotations)
+	addRuntimeAnnotations(implementation, annotations)
+	objectMeta := metav1.ObjectMeta{
+		Name:      detectorServiceNames[d.component](isvc.Name),
+		Namespace: isvc.Namespace,
+		Labels: utils.Union(utils.Filter(isvc.Labels, propagation.Labels.Propagates), map[string]string{
+			constants.InferenceServicePodLabelKey: isvc.Name,
+			constants.KServiceComponentLabel:      string(d.component),
+			constants.VisibilityLabel:             constants.ClusterLocalVisibility,
+		}),
+		Annotations: annotations,
+	}
+	container := implementation.GetContainer(isvc.ObjectMeta, spec.GetExtensions(), d.inferenceServiceConfig)
+	if len(podSpec.Containers) == 0 {
+		podSpec.Containers = []corev1.Container{
+			*container,
+		}
+	} else {
+		podSpec.Containers[0] = *container
+	}
+
+	knativePodSpec := corev1.PodSpec(*podSpec)
+	r := knative.NewKsvcReconciler(d.client, d.scheme, objectMeta, spec.GetExtensions(), &knativePodSpec,
+		isvc.Status.Components[d.component], d.inferenceServiceConfig.Drift.Policy)
+	r.Digests = digest.NewPinner(d.client, &d.inferenceServiceConfig.DigestPinning)
+
+	if err := controllerutil.SetControllerReference(isvc, r.Service, d.scheme); err != nil {
+		return nil, errors.Wrapf(err, "fails to set owner reference for %s", d.description)
+	}
+	return r, nil
+}
+
+// RemoveDetector deletes the knative service of a detector which was removed from the spec of the InferenceService
+// and clears its status
+func RemoveDetector(cli client.Client, isvc *v1beta1.InferenceService, component v1beta1.ComponentType) error {
+	existing := &knservingv1.Service{}
+	err := cli.Get(context.TODO(), types.NamespacedName{Name: detectorServiceNames[component](isvc.Name),
+		Namespace: isvc.Namespace}, existing)
+	if err == nil && metav1.IsControlledBy(existing, isvc) {
+		if err := cli.Delete(context.TODO(), existing); client.IgnoreNotFound(err) != nil {
+			return errors.Wrapf(err, "fails to delete %s knative service", component)
+		}
+	} else if client.IgnoreNotFound(err) != nil {
+		return err
+	}
+	isvc.Status.ClearComponent(component)
+	if component == v1beta1.DriftDetectorComponent {
+		isvc.Status.PropagateDriftDetection(false, "")
+	}
+	return nil
+}
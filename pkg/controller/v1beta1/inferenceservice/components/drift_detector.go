@@ -0,0 +1,135 @@
+/*
+Copyright 2020 kubeflow.org.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package components
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/canary"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ Component = &DriftDetector{}
+
+// DriftAlertInterval is the period of the evaluation of the drift alerts, the metrics of the detector do not trigger
+// a reconcile
+const DriftAlertInterval = time.Minute
+
+// DriftDetector reconciles resources for this component.
+type DriftDetector struct {
+	detector
+}
+
+func NewDriftDetector(client client.Client, scheme *runtime.Scheme, inferenceServiceConfig *v1beta1.InferenceServicesConfig) Component {
+	return &DriftDetector{
+		detector{
+			client:                 client,
+			scheme:                 scheme,
+			inferenceServiceConfig: inferenceServiceConfig,
+			component:              v1beta1.DriftDetectorComponent,
+			description:            "drift detector",
+			Log:                    ctrl.Log.WithName("DriftDetectorReconciler"),
+		},
+	}
+}
+
+// Reconcile observes the world and attempts to drive the status towards the desired state.
+func (p *DriftDetector) Reconcile(isvc *v1beta1.InferenceService) error {
+	p.Log.Info("Reconciling DriftDetector", "DriftDetectorSpec", isvc.Spec.DriftDetector)
+	metrics, err := p.reconcile(isvc, isvc.Spec.DriftDetector, &isvc.Spec.DriftDetector.PodSpec)
+	if err != nil {
+		return err
+	}
+	p.checkAlert(isvc, metrics, time.Now())
+	return nil
+}
+
+// Render returns the drift detector knative service without applying it.
+func (p *DriftDetector) Render(isvc *v1beta1.InferenceService) ([]runtime.Object, error) {
+	return p.render(isvc, isvc.Spec.DriftDetector, &isvc.Spec.DriftDetector.PodSpec)
+}
+
+// checkAlert evaluates the drift alert of the detector once the drift alert interval elapsed since the last
+// evaluation, the condition is left unchanged when the alert can not be evaluated so that a flaky metrics server does
+// not flip it
+func (p *DriftDetector) checkAlert(isvc *v1beta1.InferenceService, metrics canary.MetricsClient, now time.Time) {
+	statusSpec, ok := isvc.Status.Components[v1beta1.DriftDetectorComponent]
+	if !ok {
+		return
+	}
+	alert := isvc.Spec.DriftDetector.Alert
+	if alert == nil {
+		statusSpec.DriftAlert = nil
+		isvc.Status.Components[v1beta1.DriftDetectorComponent] = statusSpec
+		isvc.Status.PropagateDriftDetection(false, "")
+		return
+	}
+	if status := statusSpec.DriftAlert; status != nil && now.Sub(status.EvaluationTime.Time) < DriftAlertInterval {
+		return
+	}
+	status := &v1beta1.DriftAlertStatus{EvaluationTime: metav1.NewTime(now)}
+	if previous := statusSpec.DriftAlert; previous != nil {
+		status.Value = previous.Value
+	}
+	statusSpec.DriftAlert = status
+	isvc.Status.Components[v1beta1.DriftDetectorComponent] = statusSpec
+	if metrics == nil {
+		status.Message = "No metrics server is configured in the metrics config of the inferenceservice configmap"
+		return
+	}
+	query, err := canary.RenderQuery(alert.Query, canary.QueryParameters{
+		Namespace: isvc.Namespace,
+		Name:      isvc.Name,
+		Component: string(v1beta1.DriftDetectorComponent),
+	})
+	if err != nil {
+		status.Message = fmt.Sprintf("Invalid drift alert query: %v", err)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), canary.QueryTimeout)
+	defer cancel()
+	value, err := metrics.Query(ctx, query)
+	if err != nil {
+		p.Log.Error(err, "Failed to evaluate the drift alert", "namespace", isvc.Namespace, "name", isvc.Name)
+		status.Message = fmt.Sprintf("Failed to evaluate the drift alert: %v", err)
+		return
+	}
+	status.Value = strconv.FormatFloat(value, 'g', -1, 64)
+	isvc.Status.PropagateDriftDetection(value > alert.Threshold,
+		fmt.Sprintf("drift alert value %g, threshold %g", value, alert.Threshold))
+}
+
+// NextDriftAlertCheck returns the delay until the next evaluation of the drift alert, zero if the drift detector has
+// no alert
+func NextDriftAlertCheck(isvc *v1beta1.InferenceService, now time.Time) time.Duration {
+	if isvc.Spec.DriftDetector == nil || isvc.Spec.DriftDetector.Alert == nil {
+		return 0
+	}
+	status := isvc.Status.Components[v1beta1.DriftDetectorComponent].DriftAlert
+	if status == nil {
+		return DriftAlertInterval
+	}
+	next := status.EvaluationTime.Add(DriftAlertInterval).Sub(now)
+	if next < time.Second {
+		return time.Second
+	}
+	return next
+}
@@ -16,8 +16,13 @@ package components
 import (
 	"github.com/go-logr/logr"
 	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/canary"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/predictive"
 	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/reconcilers/knative"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/rollout"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/warmup"
 	"github.com/kubeflow/kfserving/pkg/credentials"
+	"github.com/kubeflow/kfserving/pkg/digest"
 	"github.com/kubeflow/kfserving/pkg/utils"
 	"github.com/pkg/errors"
 	v1 "k8s.io/api/core/v1"
@@ -26,6 +31,7 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"time"
 
 	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
 )
@@ -53,19 +59,59 @@ func NewExplainer(client client.Client, scheme *runtime.Scheme, inferenceService
 // Reconcile observes the explainer and attempts to drive the status towards the desired state.
 func (p *Explainer) Reconcile(isvc *v1beta1.InferenceService) error {
 	p.Log.Info("Reconciling Explainer", "ExplainerSpec", isvc.Spec.Explainer)
+	// The new revision is warmed up in the background, the knative service routes the traffic to it once it is warmed up
+	warmup.NewWarmUpReconciler(p.client).Reconcile(isvc, v1beta1.ExplainerComponent, isvc.Spec.Explainer.WarmUp,
+		constants.ExplainPath(isvc.Name))
+	rollout.Reconcile(isvc, v1beta1.ExplainerComponent, &isvc.Spec.Explainer.ComponentExtensionSpec, time.Now())
+	metrics := newMetricsClient(p.inferenceServiceConfig)
+	canary.Analyze(isvc, v1beta1.ExplainerComponent, &isvc.Spec.Explainer.ComponentExtensionSpec, metrics, time.Now())
+	predictive.Forecast(isvc, v1beta1.ExplainerComponent, &isvc.Spec.Explainer.ComponentExtensionSpec, metrics, time.Now())
+	r, err := p.newKsvcReconciler(isvc)
+	if err != nil {
+		return err
+	}
+	status, err := r.Reconcile()
+	if err != nil {
+		return errors.Wrapf(err, "fails to reconcile explainer")
+	}
+	isvc.Status.PropagateStatus(v1beta1.ExplainerComponent, status)
+	// The stale revisions are collected again on the next reconcile when the collection fails
+	statusSpec := isvc.Status.Components[v1beta1.ExplainerComponent]
+	if err := r.CollectGarbage(&statusSpec, time.Now()); err != nil {
+		p.Log.Error(err, "Failed to collect explainer revisions", "service", r.Service.Name)
+	}
+	isvc.Status.Components[v1beta1.ExplainerComponent] = statusSpec
+	isvc.Status.PropagateDrift("knative service "+r.Service.Name, r.Drifted)
+	return nil
+}
+
+// Render returns the explainer knative service without applying it.
+func (p *Explainer) Render(isvc *v1beta1.InferenceService) ([]runtime.Object, error) {
+	r, err := p.newKsvcReconciler(isvc)
+	if err != nil {
+		return nil, err
+	}
+	return []runtime.Object{r.Service}, nil
+}
+
+// newKsvcReconciler builds the desired knative service of the explainer
+func (p *Explainer) newKsvcReconciler(isvc *v1beta1.InferenceService) (*knative.KsvcReconciler, error) {
 	explainer := isvc.Spec.Explainer.GetImplementation()
+	propagation := p.inferenceServiceConfig.Propagation
 	annotations := utils.Filter(isvc.Annotations, func(key string) bool {
-		return !utils.Includes(constants.ServiceAnnotationDisallowedList, key)
+		return !utils.Includes(constants.ServiceAnnotationDisallowedList, key) && propagation.PropagatesAnnotation(key)
 	})
 	// KNative does not support INIT containers or mounting, so we add annotations that trigger the
 	// StorageInitializer injector to mutate the underlying deployment to provision model data
 	if sourceURI := explainer.GetStorageUri(); sourceURI != nil {
 		annotations[constants.StorageInitializerSourceUriInternalAnnotationKey] = *sourceURI
 	}
+	addPlacementAnnotations(&isvc.Spec.Explainer.ComponentExtensionSpec, annotations)
+	addRuntimeAnnotations(explainer, annotations)
 	objectMeta := metav1.ObjectMeta{
 		Name:      constants.DefaultExplainerServiceName(isvc.Name),
 		Namespace: isvc.Namespace,
-		Labels: utils.Union(isvc.Labels, map[string]string{
+		Labels: utils.Union(utils.Filter(isvc.Labels, propagation.Labels.Propagates), map[string]string{
 			constants.InferenceServicePodLabelKey: isvc.Name,
 			constants.KServiceComponentLabel:      string(v1beta1.ExplainerComponent),
 		}),
@@ -85,15 +131,11 @@ func (p *Explainer) Reconcile(isvc *v1beta1.InferenceService) error {
 
 	podSpec := v1.PodSpec(isvc.Spec.Explainer.PodSpec)
 	r := knative.NewKsvcReconciler(p.client, p.scheme, objectMeta, &isvc.Spec.Explainer.ComponentExtensionSpec,
-		&podSpec, isvc.Status.Components[v1beta1.ExplainerComponent])
+		&podSpec, isvc.Status.Components[v1beta1.ExplainerComponent], p.inferenceServiceConfig.Drift.Policy)
+	r.Digests = digest.NewPinner(p.client, &p.inferenceServiceConfig.DigestPinning)
 
 	if err := controllerutil.SetControllerReference(isvc, r.Service, p.scheme); err != nil {
-		return errors.Wrapf(err, "fails to set owner reference for explainer")
-	}
-	status, err := r.Reconcile()
-	if err != nil {
-		return errors.Wrapf(err, "fails to reconcile explainer")
+		return nil, errors.Wrapf(err, "fails to set owner reference for explainer")
 	}
-	isvc.Status.PropagateStatus(v1beta1.ExplainerComponent, status)
-	return nil
+	return r, nil
 }
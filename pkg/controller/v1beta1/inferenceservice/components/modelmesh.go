@@ -0,0 +1,107 @@
+/*
+Copyright 2020 kubeflow.org.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package components
+
+import (
+	"github.com/go-logr/logr"
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/reconcilers/modelmesh"
+	"github.com/kubeflow/kfserving/pkg/utils"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"knative.dev/pkg/apis"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+var _ Component = &ModelMesh{}
+
+// ModelMesh reconciles the predictor of an InferenceService in the ModelMesh deployment mode, the model is
+// loaded by a ModelMesh serving cluster instead of running in its own knative service.
+type ModelMesh struct {
+	client                 client.Client
+	scheme                 *runtime.Scheme
+	inferenceServiceConfig *v1beta1.InferenceServicesConfig
+	Log                    logr.Logger
+}
+
+func NewModelMesh(client client.Client, scheme *runtime.Scheme, inferenceServiceConfig *v1beta1.InferenceServicesConfig) Component {
+	return &ModelMesh{
+		client:                 client,
+		scheme:                 scheme,
+		inferenceServiceConfig: inferenceServiceConfig,
+		Log:                    ctrl.Log.WithName("ModelMeshReconciler"),
+	}
+}
+
+// Reconcile creates the ModelMesh predictor and reflects its status back to the InferenceService
+func (m *ModelMesh) Reconcile(isvc *v1beta1.InferenceService) error {
+	m.Log.Info("Reconciling ModelMesh predictor", "PredictorSpec", isvc.Spec.Predictor)
+	r, err := m.newPredictorReconciler(isvc)
+	if err != nil {
+		return err
+	}
+	status, err := r.Reconcile()
+	if err != nil {
+		return errors.Wrapf(err, "fails to reconcile modelmesh predictor")
+	}
+	reason, message := status.ActiveModelState, status.TransitionStatus
+	if status.LastFailureInfo != nil {
+		reason, message = status.LastFailureInfo.Reason, status.LastFailureInfo.Message
+	}
+	var url *apis.URL
+	if status.HTTPEndpoint != "" {
+		if url, err = apis.ParseURL(status.HTTPEndpoint); err != nil {
+			return errors.Wrapf(err, "fails to parse modelmesh predictor endpoint")
+		}
+	}
+	isvc.Status.PropagateModelMeshStatus(status.Available, reason, message, url)
+	return nil
+}
+
+// Render returns the ModelMesh predictor without applying it.
+func (m *ModelMesh) Render(isvc *v1beta1.InferenceService) ([]runtime.Object, error) {
+	r, err := m.newPredictorReconciler(isvc)
+	if err != nil {
+		return nil, err
+	}
+	return []runtime.Object{r.Predictor}, nil
+}
+
+// newPredictorReconciler builds the desired ModelMesh predictor, it is named after the InferenceService as the
+// models of a ModelMesh cluster are addressed by the predictor name.
+func (m *ModelMesh) newPredictorReconciler(isvc *v1beta1.InferenceService) (*modelmesh.PredictorReconciler, error) {
+	propagation := m.inferenceServiceConfig.Propagation
+	objectMeta := metav1.ObjectMeta{
+		Name:      isvc.Name,
+		Namespace: isvc.Namespace,
+		Labels: utils.Union(utils.Filter(isvc.Labels, propagation.Labels.Propagates), map[string]string{
+			constants.InferenceServicePodLabelKey: isvc.Name,
+		}),
+		Annotations: utils.Filter(isvc.Annotations, func(key string) bool {
+			return !utils.Includes(constants.ServiceAnnotationDisallowedList, key) && propagation.PropagatesAnnotation(key)
+		}),
+	}
+	r, err := modelmesh.NewPredictorReconciler(m.client, m.scheme, objectMeta, &isvc.Spec.Predictor)
+	if err != nil {
+		return nil, err
+	}
+	if err := controllerutil.SetControllerReference(isvc, r.Predictor, m.scheme); err != nil {
+		return nil, errors.Wrapf(err, "fails to set owner reference for modelmesh predictor")
+	}
+	return r, nil
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2020 kubeflow.org.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package components
+
+import (
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ Component = &OutlierDetector{}
+
+// OutlierDetector reconciles resources for this component.
+type OutlierDetector struct {
+	detector
+}
+
+func NewOutlierDetector(client client.Client, scheme *runtime.Scheme, inferenceServiceConfig *v1beta1.InferenceServicesConfig) Component {
+	return &OutlierDetector{
+		detector{
+			client:                 client,
+			scheme:                 scheme,
+			inferenceServiceConfig: inferenceServiceConfig,
+			component:              v1beta1.OutlierDetectorComponent,
+			description:            "outlier detector",
+			Log:                    ctrl.Log.WithName("OutlierDetectorReconciler"),
+		},
+	}
+}
+
+// Reconcile observes the world and attempts to drive the status towards the desired state.
+func (p *OutlierDetector) Reconcile(isvc *v1beta1.InferenceService) error {
+	p.Log.Info("Reconciling OutlierDetector", "OutlierDetectorSpec", isvc.Spec.OutlierDetector)
+	_, err := p.reconcile(isvc, isvc.Spec.OutlierDetector, &isvc.Spec.OutlierDetector.PodSpec)
+	return err
+}
+
+// Render returns the outlier detector knative service without applying it.
+func (p *OutlierDetector) Render(isvc *v1beta1.InferenceService) ([]runtime.Object, error) {
+	return p.render(isvc, isvc.Spec.OutlierDetector, &isvc.Spec.OutlierDetector.PodSpec)
+}
@@ -14,22 +14,40 @@ limitations under the License.
 package components
 
 import (
+	"context"
+	"encoding/json"
 	"github.com/go-logr/logr"
+	"github.com/golang/protobuf/proto"
 	"github.com/kubeflow/kfserving/pkg/constants"
 	"github.com/kubeflow/kfserving/pkg/controller/v1alpha1/trainedmodel/sharding/memory"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/canary"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/pool"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/predictive"
 	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/reconcilers/knative"
 	modelconfig "github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/reconcilers/modelconfig"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/rollout"
 	v1beta1utils "github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/utils"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/warmup"
 	"github.com/kubeflow/kfserving/pkg/credentials"
+	"github.com/kubeflow/kfserving/pkg/digest"
+	kfsmodelconfig "github.com/kubeflow/kfserving/pkg/modelconfig"
 	"github.com/kubeflow/kfserving/pkg/utils"
 	"github.com/pkg/errors"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/serving/pkg/apis/autoscaling"
+	autoscalingv1alpha1 "knative.dev/serving/pkg/apis/autoscaling/v1alpha1"
+	"knative.dev/serving/pkg/apis/serving"
+	knservingv1 "knative.dev/serving/pkg/apis/serving/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
 )
@@ -57,24 +75,292 @@ func NewPredictor(client client.Client, scheme *runtime.Scheme, inferenceService
 // Reconcile observes the predictor and attempts to drive the status towards the desired state.
 func (p *Predictor) Reconcile(isvc *v1beta1.InferenceService) error {
 	p.Log.Info("Reconciling Predictor", "PredictorSpec", isvc.Spec.Predictor)
+	// The new revision is warmed up in the background, the knative service routes the traffic to it once it is warmed up
+	warmup.NewWarmUpReconciler(p.client).Reconcile(isvc, v1beta1.PredictorComponent, isvc.Spec.Predictor.WarmUp,
+		constants.PredictPath(isvc.Name))
+	rollout.Reconcile(isvc, v1beta1.PredictorComponent, &isvc.Spec.Predictor.ComponentExtensionSpec, time.Now())
+	metrics := newMetricsClient(p.inferenceServiceConfig)
+	canary.Analyze(isvc, v1beta1.PredictorComponent, &isvc.Spec.Predictor.ComponentExtensionSpec, metrics, time.Now())
+	predictive.Forecast(isvc, v1beta1.PredictorComponent, &isvc.Spec.Predictor.ComponentExtensionSpec, metrics, time.Now())
+	r, err := p.newKsvcReconciler(isvc)
+	if err != nil {
+		return err
+	}
+
+	// Reconcile modelConfig
+	configMapReconciler := modelconfig.NewModelConfigReconciler(p.client, p.scheme)
+	if err := configMapReconciler.Reconcile(isvc); err != nil {
+		return err
+	}
+
+	status, err := r.Reconcile()
+	if err != nil {
+		return errors.Wrapf(err, "fails to reconcile predictor")
+	}
+	isvc.Status.PropagateStatus(v1beta1.PredictorComponent, status)
+	// The stale revisions are collected again on the next reconcile when the collection fails
+	statusSpec := isvc.Status.Components[v1beta1.PredictorComponent]
+	if err := r.CollectGarbage(&statusSpec, time.Now()); err != nil {
+		p.Log.Error(err, "Failed to collect predictor revisions", "service", r.Service.Name)
+	}
+	isvc.Status.Components[v1beta1.PredictorComponent] = statusSpec
+	isvc.Status.PropagateDrift("knative service "+r.Service.Name, r.Drifted)
+	if err := p.propagateScaleStatus(isvc, r.Service.Name); err != nil {
+		return errors.Wrapf(err, "fails to propagate predictor scale status")
+	}
+	if err := p.reconcilePool(isvc, r.Service); err != nil {
+		return errors.Wrapf(err, "fails to reconcile predictor pool")
+	}
+	pool.Route(isvc, metrics, time.Now())
+	if err := p.reconcileVersions(isvc, r.Service); err != nil {
+		return errors.Wrapf(err, "fails to reconcile predictor versions")
+	}
+	return nil
+}
+
+// reconcilePool applies the knative service of the replica pool of the predictor, or removes it when the predictor
+// has no pool
+func (p *Predictor) reconcilePool(isvc *v1beta1.InferenceService, predictorService *knservingv1.Service) error {
+	if isvc.Spec.Predictor.Pool == nil {
+		existing := &knservingv1.Service{}
+		err := p.client.Get(context.TODO(), types.NamespacedName{Name: constants.PredictorPoolServiceName(isvc.Name),
+			Namespace: isvc.Namespace}, existing)
+		if err != nil || !metav1.IsControlledBy(existing, isvc) {
+			return client.IgnoreNotFound(err)
+		}
+		p.Log.Info("Deleting predictor pool knative service", "namespace", existing.Namespace, "name", existing.Name)
+		return client.IgnoreNotFound(p.client.Delete(context.TODO(), existing))
+	}
+	r, err := p.newPoolKsvcReconciler(isvc, predictorService)
+	if err != nil {
+		return err
+	}
+	status, err := r.Reconcile()
+	if err != nil {
+		return err
+	}
+	statusSpec := isvc.Status.Components[v1beta1.PredictorComponent]
+	if statusSpec.Pool == nil {
+		statusSpec.Pool = &v1beta1.PoolStatus{}
+	}
+	statusSpec.Pool.LatestReadyRevision = status.LatestReadyRevisionName
+	isvc.Status.Components[v1beta1.PredictorComponent] = statusSpec
+	isvc.Status.PropagateDrift("knative service "+r.Service.Name, r.Drifted)
+	return nil
+}
+
+// newPoolKsvcReconciler builds the desired knative service of the replica pool from the revision template of the
+// predictor with the overrides of the pool
+func (p *Predictor) newPoolKsvcReconciler(isvc *v1beta1.InferenceService,
+	predictorService *knservingv1.Service) (*knative.KsvcReconciler, error) {
+	replicaPool := isvc.Spec.Predictor.Pool
+	template := predictorService.Spec.Template.DeepCopy()
+	podSpec := template.Spec.PodSpec
+	container := &podSpec.Containers[0]
+	if replicaPool.Image != "" {
+		container.Image = replicaPool.Image
+	}
+	if len(replicaPool.Args) != 0 {
+		container.Args = replicaPool.Args
+	}
+	for _, env := range replicaPool.Env {
+		container.Env = setEnv(container.Env, env)
+	}
+	container.Resources = replicaPool.Resources
+	podSpec.NodeSelector = replicaPool.NodeSelector
+	podSpec.Tolerations = replicaPool.Tolerations
+	// The scale of the pool is set by the pool replicas
+	delete(template.Annotations, autoscaling.MaxScaleAnnotationKey)
+	objectMeta := metav1.ObjectMeta{
+		Name:      constants.PredictorPoolServiceName(isvc.Name),
+		Namespace: isvc.Namespace,
+		Labels: utils.Union(template.Labels, map[string]string{
+			constants.KServiceComponentLabel: constants.PredictorPoolComponentLabel,
+		}),
+		Annotations: template.Annotations,
+	}
+	predictorExtension := isvc.Spec.Predictor.ComponentExtensionSpec
+	extension := &v1beta1.ComponentExtensionSpec{
+		MinReplicas:          replicaPool.MinReplicas,
+		MaxReplicas:          replicaPool.MaxReplicas,
+		ContainerConcurrency: predictorExtension.ContainerConcurrency,
+		TimeoutSeconds:       predictorExtension.TimeoutSeconds,
+	}
+	r := knative.NewKsvcReconciler(p.client, p.scheme, objectMeta, extension, &podSpec, v1beta1.ComponentStatusSpec{},
+		p.inferenceServiceConfig.Drift.Policy)
+	r.Digests = digest.NewPinner(p.client, &p.inferenceServiceConfig.DigestPinning)
+	if err := controllerutil.SetControllerReference(isvc, r.Service, p.scheme); err != nil {
+		return nil, errors.Wrapf(err, "fails to set owner reference for predictor pool")
+	}
+	return r, nil
+}
+
+// setEnv sets the environment variable, replacing the variable with the same name
+func setEnv(envs []v1.EnvVar, env v1.EnvVar) []v1.EnvVar {
+	for i := range envs {
+		if envs[i].Name == env.Name {
+			envs[i] = env
+			return envs
+		}
+	}
+	return append(envs, env)
+}
+
+// reconcileVersions applies the knative services of the model versions of the predictor and removes the knative
+// services of the versions which are no longer declared
+func (p *Predictor) reconcileVersions(isvc *v1beta1.InferenceService, predictorService *knservingv1.Service) error {
+	services := &knservingv1.ServiceList{}
+	if err := p.client.List(context.TODO(), services, client.InNamespace(isvc.Namespace),
+		client.MatchingLabels{constants.InferenceServicePodLabelKey: isvc.Name}); err != nil {
+		return err
+	}
+	for i := range services.Items {
+		existing := &services.Items[i]
+		version, ok := existing.Labels[constants.ModelVersionLabelKey]
+		if !ok || isvc.Spec.Predictor.GetModelVersion(version) != nil || !metav1.IsControlledBy(existing, isvc) {
+			continue
+		}
+		p.Log.Info("Deleting predictor version knative service", "namespace", existing.Namespace, "name", existing.Name)
+		if err := p.client.Delete(context.TODO(), existing); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	versions := []v1beta1.ModelVersionStatus{}
+	for i := range isvc.Spec.Predictor.Versions {
+		version := &isvc.Spec.Predictor.Versions[i]
+		r, err := p.newVersionKsvcReconciler(isvc, predictorService, version)
+		if err != nil {
+			return err
+		}
+		status, err := r.Reconcile()
+		if err != nil {
+			return err
+		}
+		versions = append(versions, v1beta1.ModelVersionStatus{
+			Name:                version.Name,
+			LatestReadyRevision: status.LatestReadyRevisionName,
+		})
+		isvc.Status.PropagateDrift("knative service "+r.Service.Name, r.Drifted)
+	}
+	statusSpec := isvc.Status.Components[v1beta1.PredictorComponent]
+	statusSpec.Versions = nil
+	if len(versions) != 0 {
+		statusSpec.Versions = versions
+	}
+	isvc.Status.Components[v1beta1.PredictorComponent] = statusSpec
+	return nil
+}
+
+// newVersionKsvcReconciler builds the desired knative service of a model version from the revision template of the
+// predictor, the storage initializer downloads the model of the version instead of the model of the predictor
+func (p *Predictor) newVersionKsvcReconciler(isvc *v1beta1.InferenceService, predictorService *knservingv1.Service,
+	version *v1beta1.ModelVersionSpec) (*knative.KsvcReconciler, error) {
+	template := predictorService.Spec.Template.DeepCopy()
+	podSpec := template.Spec.PodSpec
+	template.Annotations[constants.StorageInitializerSourceUriInternalAnnotationKey] = version.StorageURI
+	// The scale of the version is set by the version replicas
+	delete(template.Annotations, autoscaling.MaxScaleAnnotationKey)
+	objectMeta := metav1.ObjectMeta{
+		Name:      constants.PredictorVersionServiceName(isvc.Name, version.Name),
+		Namespace: isvc.Namespace,
+		Labels: utils.Union(template.Labels, map[string]string{
+			constants.KServiceComponentLabel: constants.PredictorVersionComponentLabel,
+			constants.ModelVersionLabelKey:   version.Name,
+		}),
+		Annotations: template.Annotations,
+	}
+	predictorExtension := isvc.Spec.Predictor.ComponentExtensionSpec
+	extension := &v1beta1.ComponentExtensionSpec{
+		MinReplicas:          predictorExtension.MinReplicas,
+		MaxReplicas:          predictorExtension.MaxReplicas,
+		ContainerConcurrency: predictorExtension.ContainerConcurrency,
+		TimeoutSeconds:       predictorExtension.TimeoutSeconds,
+	}
+	if version.MinReplicas != nil {
+		extension.MinReplicas = version.MinReplicas
+	}
+	if version.MaxReplicas != 0 {
+		extension.MaxReplicas = version.MaxReplicas
+	}
+	r := knative.NewKsvcReconciler(p.client, p.scheme, objectMeta, extension, &podSpec, v1beta1.ComponentStatusSpec{},
+		p.inferenceServiceConfig.Drift.Policy)
+	r.Digests = digest.NewPinner(p.client, &p.inferenceServiceConfig.DigestPinning)
+	if err := controllerutil.SetControllerReference(isvc, r.Service, p.scheme); err != nil {
+		return nil, errors.Wrapf(err, "fails to set owner reference for predictor version")
+	}
+	return r, nil
+}
+
+// Render returns the predictor knative services and the multi-model configs without applying them.
+func (p *Predictor) Render(isvc *v1beta1.InferenceService) ([]runtime.Object, error) {
+	r, err := p.newKsvcReconciler(isvc)
+	if err != nil {
+		return nil, err
+	}
+	objects := []runtime.Object{r.Service}
+	if isvc.Spec.Predictor.Pool != nil {
+		poolReconciler, err := p.newPoolKsvcReconciler(isvc, r.Service)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, poolReconciler.Service)
+	}
+	for i := range isvc.Spec.Predictor.Versions {
+		versionReconciler, err := p.newVersionKsvcReconciler(isvc, r.Service, &isvc.Spec.Predictor.Versions[i])
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, versionReconciler.Service)
+	}
+	if v1beta1utils.IsMMSPredictor(&isvc.Spec.Predictor) {
+		shardStrategy := memory.MemoryStrategy{}
+		for _, id := range shardStrategy.GetShard(isvc) {
+			modelConfig, err := kfsmodelconfig.CreateEmptyModelConfig(isvc, id)
+			if err != nil {
+				return nil, err
+			}
+			if err := controllerutil.SetControllerReference(isvc, modelConfig, p.scheme); err != nil {
+				return nil, errors.Wrapf(err, "fails to set owner reference for model config")
+			}
+			objects = append(objects, modelConfig)
+		}
+	}
+	return objects, nil
+}
+
+// newKsvcReconciler builds the desired knative service of the predictor
+func (p *Predictor) newKsvcReconciler(isvc *v1beta1.InferenceService) (*knative.KsvcReconciler, error) {
 	predictor := isvc.Spec.Predictor.GetImplementation()
+	propagation := p.inferenceServiceConfig.Propagation
 	annotations := utils.Filter(isvc.Annotations, func(key string) bool {
-		return !utils.Includes(constants.ServiceAnnotationDisallowedList, key)
+		return !utils.Includes(constants.ServiceAnnotationDisallowedList, key) && propagation.PropagatesAnnotation(key)
 	})
 	// KNative does not support INIT containers or mounting, so we add annotations that trigger the
 	// StorageInitializer injector to mutate the underlying deployment to provision model data
 	if sourceURI := predictor.GetStorageUri(); sourceURI != nil {
 		annotations[constants.StorageInitializerSourceUriInternalAnnotationKey] = *sourceURI
 	}
-	hasInferenceLogging := addLoggerAnnotations(isvc.Spec.Predictor.Logger, annotations)
+	hasInferenceLogging := addLoggerAnnotations(predictorLogger(isvc), annotations)
+	if addOutlierDetectorAnnotations(isvc.Spec.OutlierDetector, isvc.ObjectMeta, annotations) {
+		hasInferenceLogging = true
+	}
+	if addRequestValidationAnnotations(isvc, annotations) {
+		hasInferenceLogging = true
+	}
+	if addEnsembleAnnotations(isvc.Spec.Predictor.Ensemble, annotations) {
+		hasInferenceLogging = true
+	}
 	hasInferenceBatcher := addBatcherAnnotations(isvc.Spec.Predictor.Batcher, annotations)
 	// Add agent annotations so mutator will mount model agent to multi-model InferenceService's predictor
 	addAgentAnnotations(isvc, annotations)
+	addPlacementAnnotations(&isvc.Spec.Predictor.ComponentExtensionSpec, annotations)
+	addRuntimeAnnotations(predictor, annotations)
+	addSessionAffinityAnnotations(isvc.Spec.Routing, annotations)
 
 	objectMeta := metav1.ObjectMeta{
 		Name:      constants.DefaultPredictorServiceName(isvc.Name),
 		Namespace: isvc.Namespace,
-		Labels: utils.Union(isvc.Labels, map[string]string{
+		Labels: utils.Union(utils.Filter(isvc.Labels, propagation.Labels.Propagates), map[string]string{
 			constants.InferenceServicePodLabelKey: isvc.Name,
 			constants.KServiceComponentLabel:      string(v1beta1.PredictorComponent),
 		}),
@@ -97,30 +383,141 @@ func (p *Predictor) Reconcile(isvc *v1beta1.InferenceService) error {
 	if hasInferenceBatcher {
 		addBatcherContainerPort(&isvc.Spec.Predictor.PodSpec.Containers[0])
 	}
+	addStartupProbeAnnotations(&isvc.Spec.Predictor, &isvc.Spec.Predictor.PodSpec.Containers[0],
+		p.inferenceServiceConfig.Startup, annotations)
 
 	podSpec := v1.PodSpec(isvc.Spec.Predictor.PodSpec)
 
-	// Reconcile modelConfig
-	configMapReconciler := modelconfig.NewModelConfigReconciler(p.client, p.scheme)
-	if err := configMapReconciler.Reconcile(isvc); err != nil {
-		return err
-	}
-
 	// Here we allow switch between knative and vanilla deployment
 	r := knative.NewKsvcReconciler(p.client, p.scheme, objectMeta, &isvc.Spec.Predictor.ComponentExtensionSpec,
-		&podSpec, isvc.Status.Components[v1beta1.PredictorComponent])
+		&podSpec, isvc.Status.Components[v1beta1.PredictorComponent], p.inferenceServiceConfig.Drift.Policy)
+	r.Digests = digest.NewPinner(p.client, &p.inferenceServiceConfig.DigestPinning)
 
 	if err := controllerutil.SetControllerReference(isvc, r.Service, p.scheme); err != nil {
-		return errors.Wrapf(err, "fails to set owner reference for predictor")
+		return nil, errors.Wrapf(err, "fails to set owner reference for predictor")
 	}
-	status, err := r.Reconcile()
-	if err != nil {
-		return errors.Wrapf(err, "fails to reconcile predictor")
+	return r, nil
+}
+
+// propagateScaleStatus reports the predictor pod selector and the number of replicas across all its revisions,
+// these are read by the scale subresource of the InferenceService. The replicas are the actual scale of the
+// autoscalers of the revisions, which knative updates as the revision deployments scale.
+func (p *Predictor) propagateScaleStatus(isvc *v1beta1.InferenceService, serviceName string) error {
+	podAutoscalers := &autoscalingv1alpha1.PodAutoscalerList{}
+	if err := p.client.List(context.TODO(), podAutoscalers, client.InNamespace(isvc.Namespace),
+		client.MatchingLabels{serving.ServiceLabelKey: serviceName}); err != nil {
+		return err
 	}
-	isvc.Status.PropagateStatus(v1beta1.PredictorComponent, status)
+	var replicas int32
+	for _, podAutoscaler := range podAutoscalers.Items {
+		if podAutoscaler.Status.ActualScale != nil {
+			replicas += *podAutoscaler.Status.ActualScale
+		}
+	}
+	statusSpec := isvc.Status.Components[v1beta1.PredictorComponent]
+	statusSpec.Replicas = replicas
+	statusSpec.Selector = labels.SelectorFromSet(map[string]string{
+		constants.InferenceServicePodLabelKey: isvc.Name,
+		constants.KServiceComponentLabel:      string(v1beta1.PredictorComponent),
+	}).String()
+	isvc.Status.Components[v1beta1.PredictorComponent] = statusSpec
 	return nil
 }
 
+// predictorLogger returns the logger of the predictor, the requests are logged to the detectors of the
+// InferenceService when the logger does not have a sink already
+func predictorLogger(isvc *v1beta1.InferenceService) *v1beta1.LoggerSpec {
+	var sinks []string
+	if isvc.Spec.DriftDetector != nil {
+		sinks = append(sinks, constants.DriftDetectorURL(isvc.ObjectMeta))
+	}
+	if isvc.Spec.OutlierDetector != nil && !isvc.Spec.OutlierDetector.IsInline() {
+		sinks = append(sinks, constants.OutlierDetectorURL(isvc.ObjectMeta))
+	}
+	if len(sinks) == 0 {
+		return isvc.Spec.Predictor.Logger
+	}
+	logger := &v1beta1.LoggerSpec{Mode: v1beta1.LogRequest}
+	if isvc.Spec.Predictor.Logger != nil {
+		logger = isvc.Spec.Predictor.Logger.DeepCopy()
+	}
+	if logger.URL == nil {
+		logger.URL = proto.String(strings.Join(sinks, ","))
+	}
+	return logger
+}
+
+// addOutlierDetectorAnnotations makes the logger score the requests with the outlier detector before responding
+// when the detection is inline
+func addOutlierDetectorAnnotations(outlierDetector *v1beta1.OutlierDetectorSpec, metadata metav1.ObjectMeta,
+	annotations map[string]string) bool {
+	if outlierDetector == nil || !outlierDetector.IsInline() {
+		return false
+	}
+	annotations[constants.LoggerOutlierUrlInternalAnnotationKey] = constants.OutlierDetectorURL(metadata)
+	return true
+}
+
+// addRequestValidationAnnotations makes the logger validate the requests against the model metadata mounted from
+// the schema configmap
+func addRequestValidationAnnotations(isvc *v1beta1.InferenceService, annotations map[string]string) bool {
+	if !isvc.Spec.Predictor.RequestValidation {
+		return false
+	}
+	annotations[constants.LoggerSchemaInternalAnnotationKey] = constants.ModelSchemaConfigMapName(isvc.Name)
+	return true
+}
+
+// addEnsembleAnnotations makes the logger send the predict requests of the InferenceService to the ensemble models
+// of the model server and combine their predictions
+func addEnsembleAnnotations(ensemble *v1beta1.EnsembleSpec, annotations map[string]string) bool {
+	if ensemble == nil {
+		return false
+	}
+	data, err := json.Marshal(ensemble)
+	if err != nil {
+		return false
+	}
+	annotations[constants.LoggerEnsembleInternalAnnotationKey] = string(data)
+	return true
+}
+
+// addStartupProbeAnnotations passes the startup probe of the model server container to the pod mutator, knative does
+// not allow startup probes in the revision containers. The probe is generated for the predictors with a startup spec
+// or a liveness probe, its failure threshold covers the startup timeout of the predictor.
+func addStartupProbeAnnotations(predictor *v1beta1.PredictorSpec, container *v1.Container, config v1beta1.StartupConfig,
+	annotations map[string]string) {
+	probe := container.StartupProbe
+	container.StartupProbe = nil
+	if probe == nil {
+		if predictor.Startup == nil && container.LivenessProbe == nil {
+			return
+		}
+		timeout := v1beta1.GetStartupTimeoutSeconds(predictor.Startup, config)
+		period := int64(constants.StartupProbePeriodSeconds)
+		probe = &v1.Probe{
+			PeriodSeconds:    constants.StartupProbePeriodSeconds,
+			FailureThreshold: int32((timeout + period - 1) / period),
+		}
+	}
+	data, err := json.Marshal(probe)
+	if err != nil {
+		return
+	}
+	annotations[constants.StartupProbeInternalAnnotationKey] = string(data)
+}
+
+// addSessionAffinityAnnotations keeps the activator out of the request path once the revision has replicas, so that
+// the gateway selects the replica of a session by consistent hashing
+func addSessionAffinityAnnotations(routing *v1beta1.RoutingSpec, annotations map[string]string) {
+	if routing == nil || routing.SessionAffinity == nil {
+		return
+	}
+	if _, ok := annotations[autoscaling.TargetBurstCapacityKey]; !ok {
+		annotations[autoscaling.TargetBurstCapacityKey] = "0"
+	}
+}
+
 func addLoggerAnnotations(logger *v1beta1.LoggerSpec, annotations map[string]string) bool {
 	if logger != nil {
 		annotations[constants.LoggerInternalAnnotationKey] = "true"
@@ -128,6 +525,12 @@ func addLoggerAnnotations(logger *v1beta1.LoggerSpec, annotations map[string]str
 			annotations[constants.LoggerSinkUrlInternalAnnotationKey] = *logger.URL
 		}
 		annotations[constants.LoggerModeInternalAnnotationKey] = string(logger.Mode)
+		if logger.Retries != nil {
+			annotations[constants.LoggerRetriesInternalAnnotationKey] = strconv.Itoa(*logger.Retries)
+		}
+		if logger.DeadLetterURL != nil {
+			annotations[constants.LoggerDeadLetterUrlInternalAnnotationKey] = *logger.DeadLetterURL
+		}
 		return true
 	}
 	return false
@@ -16,8 +16,13 @@ package components
 import (
 	"github.com/go-logr/logr"
 	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/canary"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/predictive"
 	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/reconcilers/knative"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/rollout"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/warmup"
 	"github.com/kubeflow/kfserving/pkg/credentials"
+	"github.com/kubeflow/kfserving/pkg/digest"
 	"github.com/kubeflow/kfserving/pkg/utils"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
@@ -26,6 +31,7 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"time"
 
 	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
 )
@@ -53,19 +59,59 @@ func NewTransformer(client client.Client, scheme *runtime.Scheme, inferenceServi
 // Reconcile observes the world and attempts to drive the status towards the desired state.
 func (p *Transformer) Reconcile(isvc *v1beta1.InferenceService) error {
 	p.Log.Info("Reconciling Transformer", "TranformerSpec", isvc.Spec.Transformer)
+	// The new revision is warmed up in the background, the knative service routes the traffic to it once it is warmed up
+	warmup.NewWarmUpReconciler(p.client).Reconcile(isvc, v1beta1.TransformerComponent, isvc.Spec.Transformer.WarmUp,
+		constants.PredictPath(isvc.Name))
+	rollout.Reconcile(isvc, v1beta1.TransformerComponent, &isvc.Spec.Transformer.ComponentExtensionSpec, time.Now())
+	metrics := newMetricsClient(p.inferenceServiceConfig)
+	canary.Analyze(isvc, v1beta1.TransformerComponent, &isvc.Spec.Transformer.ComponentExtensionSpec, metrics, time.Now())
+	predictive.Forecast(isvc, v1beta1.TransformerComponent, &isvc.Spec.Transformer.ComponentExtensionSpec, metrics, time.Now())
+	r, err := p.newKsvcReconciler(isvc)
+	if err != nil {
+		return err
+	}
+	status, err := r.Reconcile()
+	if err != nil {
+		return errors.Wrapf(err, "fails to reconcile transformer")
+	}
+	isvc.Status.PropagateStatus(v1beta1.TransformerComponent, status)
+	// The stale revisions are collected again on the next reconcile when the collection fails
+	statusSpec := isvc.Status.Components[v1beta1.TransformerComponent]
+	if err := r.CollectGarbage(&statusSpec, time.Now()); err != nil {
+		p.Log.Error(err, "Failed to collect transformer revisions", "service", r.Service.Name)
+	}
+	isvc.Status.Components[v1beta1.TransformerComponent] = statusSpec
+	isvc.Status.PropagateDrift("knative service "+r.Service.Name, r.Drifted)
+	return nil
+}
+
+// Render returns the transformer knative service without applying it.
+func (p *Transformer) Render(isvc *v1beta1.InferenceService) ([]runtime.Object, error) {
+	r, err := p.newKsvcReconciler(isvc)
+	if err != nil {
+		return nil, err
+	}
+	return []runtime.Object{r.Service}, nil
+}
+
+// newKsvcReconciler builds the desired knative service of the transformer
+func (p *Transformer) newKsvcReconciler(isvc *v1beta1.InferenceService) (*knative.KsvcReconciler, error) {
 	transformer := isvc.Spec.Transformer.GetImplementation()
+	propagation := p.inferenceServiceConfig.Propagation
 	annotations := utils.Filter(isvc.Annotations, func(key string) bool {
-		return !utils.Includes(constants.ServiceAnnotationDisallowedList, key)
+		return !utils.Includes(constants.ServiceAnnotationDisallowedList, key) && propagation.PropagatesAnnotation(key)
 	})
 	// KNative does not support INIT containers or mounting, so we add annotations that trigger the
 	// StorageInitializer injector to mutate the underlying deployment to provision model data
 	if sourceURI := transformer.GetStorageUri(); sourceURI != nil {
 		annotations[constants.StorageInitializerSourceUriInternalAnnotationKey] = *sourceURI
 	}
+	addPlacementAnnotations(&isvc.Spec.Transformer.ComponentExtensionSpec, annotations)
+	addRuntimeAnnotations(transformer, annotations)
 	objectMeta := metav1.ObjectMeta{
 		Name:      constants.DefaultTransformerServiceName(isvc.Name),
 		Namespace: isvc.Namespace,
-		Labels: utils.Union(isvc.Labels, map[string]string{
+		Labels: utils.Union(utils.Filter(isvc.Labels, propagation.Labels.Propagates), map[string]string{
 			constants.InferenceServicePodLabelKey: isvc.Name,
 			constants.KServiceComponentLabel:      string(v1beta1.TransformerComponent),
 		}),
@@ -85,15 +131,11 @@ func (p *Transformer) Reconcile(isvc *v1beta1.InferenceService) error {
 
 	podSpec := corev1.PodSpec(isvc.Spec.Transformer.PodSpec)
 	r := knative.NewKsvcReconciler(p.client, p.scheme, objectMeta, &isvc.Spec.Transformer.ComponentExtensionSpec,
-		&podSpec, isvc.Status.Components[v1beta1.TransformerComponent])
+		&podSpec, isvc.Status.Components[v1beta1.TransformerComponent], p.inferenceServiceConfig.Drift.Policy)
+	r.Digests = digest.NewPinner(p.client, &p.inferenceServiceConfig.DigestPinning)
 
 	if err := controllerutil.SetControllerReference(isvc, r.Service, p.scheme); err != nil {
-		return errors.Wrapf(err, "fails to set owner reference for transformer")
-	}
-	status, err := r.Reconcile()
-	if err != nil {
-		return errors.Wrapf(err, "fails to reconcile transformer")
+		return nil, errors.Wrapf(err, "fails to set owner reference for transformer")
 	}
-	isvc.Status.PropagateStatus(v1beta1.TransformerComponent, status)
-	return nil
+	return r, nil
 }
@@ -21,9 +21,20 @@ import (
 	"github.com/kubeflow/kfserving/pkg/apis/serving/v1alpha2"
 	v1beta1api "github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
 	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/canary"
 	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/components"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/cost"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/pool"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/predictive"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/quota"
 	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/reconcilers/ingress"
 	modelconfig "github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/reconcilers/modelconfig"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/reconcilers/modelmesh"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/reconcilers/schema"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/rollout"
+	isvcutils "github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/utils"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/warmup"
+	"github.com/kubeflow/kfserving/pkg/registry"
 	"github.com/kubeflow/kfserving/pkg/utils"
 	"github.com/pkg/errors"
 	"istio.io/client-go/pkg/apis/networking/v1alpha3"
@@ -31,30 +42,53 @@ import (
 	"k8s.io/apimachinery/pkg/api/equality"
 	apierr "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	"knative.dev/pkg/apis"
+	autoscalingv1alpha1 "knative.dev/serving/pkg/apis/autoscaling/v1alpha1"
 	knservingv1 "knative.dev/serving/pkg/apis/serving/v1"
 	"reflect"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	"time"
 )
 
 // +kubebuilder:rbac:groups=serving.kubeflow.org,resources=inferenceservices;inferenceservices/finalizers,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=serving.kubeflow.org,resources=inferenceservices/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=serving.kubeflow.org,resources=inferencequotas,verbs=get;list;watch
+// +kubebuilder:rbac:groups=serving.kubeflow.org,resources=inferenceserviceclasses,verbs=get;list;watch
 // +kubebuilder:rbac:groups=serving.knative.dev,resources=services,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=serving.knative.dev,resources=services/finalizers,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=serving.knative.dev,resources=services/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=serving.knative.dev,resources=configurations;revisions;routes,verbs=get;list;delete
 // +kubebuilder:rbac:groups=networking.istio.io,resources=virtualservices,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=networking.istio.io,resources=virtualservices/finalizers,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=networking.istio.io,resources=destinationrules,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=serving.kserve.io,resources=predictors,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=autoscaling.internal.knative.dev,resources=podautoscalers,verbs=get;list;watch
 // +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=serviceaccounts,verbs=get;list;watch
-// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update
 // +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
 // +kubebuilder:rbac:groups=core,resources=events,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=scheduling.k8s.io,resources=priorityclasses,verbs=get;list;watch
+// +kubebuilder:rbac:groups=node.k8s.io,resources=runtimeclasses,verbs=get;list;watch
+
+// schemaRetryInterval is the delay before fetching the model metadata again from a revision which did not answer it
+const schemaRetryInterval = 30 * time.Second
+
+// expirationWarningPeriod is how long before its expiration the InferenceService is warned about being deleted
+const expirationWarningPeriod = time.Hour
+
+// quotaRetryInterval is the period of the admission of a pending InferenceService, the capacity of the quota is freed
+// when other InferenceServices are deleted or scaled down
+const quotaRetryInterval = 30 * time.Second
 
 // InferenceServiceReconciler reconciles a InferenceService object
 type InferenceServiceReconciler struct {
@@ -102,6 +136,8 @@ func (r *InferenceServiceReconciler) Reconcile(req ctrl.Request) (ctrl.Result, e
 				return ctrl.Result{}, err
 			}
 
+			cost.Forget(isvc)
+
 			// remove our finalizer from the list and update it.
 			isvc.ObjectMeta.Finalizers = utils.RemoveString(isvc.ObjectMeta.Finalizers, finalizerName)
 			if err := r.Update(context.Background(), isvc); err != nil {
@@ -113,11 +149,72 @@ func (r *InferenceServiceReconciler) Reconcile(req ctrl.Request) (ctrl.Result, e
 		return ctrl.Result{}, nil
 	}
 
+	// The expired InferenceServices are scaled down and deleted, a warning is raised before they expire
+	now := time.Now()
+	if expiration := isvc.GetExpirationTime(); expiration != nil && !now.Before(expiration.Time) {
+		return ctrl.Result{}, r.expire(isvc, expiration)
+	}
+
+	if isvc.Labels[constants.MultiClusterLabel] == "true" {
+		// The multi-cluster controller pushes the InferenceService to the member clusters and reports their status
+		r.Log.Info("Skipping multi-cluster inference service", "isvc", isvc.Name)
+		return ctrl.Result{}, nil
+	}
+
 	r.Log.Info("Reconciling inference service", "apiVersion", isvc.APIVersion, "isvc", isvc.Name)
+	expiringMessage := expirationWarning(isvc, now)
+	if expiringMessage != "" && isvc.Status.GetCondition(v1beta1api.Expiring) == nil {
+		r.Recorder.Eventf(isvc, v1.EventTypeWarning, v1beta1api.ExpiringReason, expiringMessage)
+	}
+	isvc.Status.PropagateExpiring(expiringMessage)
+
+	pausedMessage, err := r.pausedMessage(isvc)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if pausedMessage != "" {
+		if err := r.pause(isvc, pausedMessage); err != nil {
+			return reconcile.Result{}, errors.Wrapf(err, "fails to pause InferenceService")
+		}
+		return ctrl.Result{RequeueAfter: nextExpirationCheck(isvc, now)}, r.updateStatus(isvc)
+	}
+	pendingMessage, err := r.quotaMessage(isvc)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrapf(err, "fails to evaluate InferenceQuotas")
+	}
+	if pendingMessage != "" && isvc.Status.GetCondition(v1beta1api.Pending) == nil {
+		r.Recorder.Eventf(isvc, v1.EventTypeNormal, v1beta1api.QuotaExceededReason, pendingMessage)
+	}
+	isvc.Status.PropagatePending(pendingMessage)
+	if pendingMessage != "" {
+		r.Log.Info("Inference service is pending", "isvc", isvc.Name, "reason", pendingMessage)
+		return ctrl.Result{RequeueAfter: minRequeue(quotaRetryInterval, nextExpirationCheck(isvc, now))}, r.updateStatus(isvc)
+	}
+	isvc.Status.AdmittedGeneration = isvc.Generation
 	isvcConfig, err := v1beta1api.NewInferenceServicesConfig(r.Client)
 	if err != nil {
 		return reconcile.Result{}, errors.Wrapf(err, "fails to create InferenceServicesConfig")
 	}
+	// The components read the storage URI the model registry reference of the predictor resolves to
+	if err := registry.Resolve(isvc, isvcConfig.Registries, now); err != nil {
+		r.Log.Error(err, "Failed to resolve model registry reference", "Name", isvc.Name)
+		r.Recorder.Eventf(isvc, v1.EventTypeWarning, "ModelResolutionFailed", err.Error())
+		return reconcile.Result{}, errors.Wrapf(err, "fails to resolve model registry reference")
+	}
+	if isvcutils.GetDeploymentMode(isvc) == constants.ModelMeshDeployment {
+		// The ModelMesh serving cluster loads the model and routes the requests to it, so there are no knative
+		// services or ingress to reconcile
+		if err := components.NewModelMesh(r.Client, r.Scheme, isvcConfig).Reconcile(isvc); err != nil {
+			r.Log.Error(err, "Failed to reconcile modelmesh predictor", "Name", isvc.Name)
+			r.Recorder.Eventf(isvc, v1.EventTypeWarning, "InternalError", err.Error())
+			return reconcile.Result{}, errors.Wrapf(err, "fails to reconcile modelmesh predictor")
+		}
+		if err = r.updateStatus(isvc); err != nil {
+			r.Recorder.Eventf(isvc, v1.EventTypeWarning, "InternalError", err.Error())
+			return reconcile.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: nextExpirationCheck(isvc, now)}, nil
+	}
 	reconcilers := []components.Component{
 		components.NewPredictor(r.Client, r.Scheme, isvcConfig),
 	}
@@ -127,6 +224,12 @@ func (r *InferenceServiceReconciler) Reconcile(req ctrl.Request) (ctrl.Result, e
 	if isvc.Spec.Explainer != nil {
 		reconcilers = append(reconcilers, components.NewExplainer(r.Client, r.Scheme, isvcConfig))
 	}
+	if isvc.Spec.DriftDetector != nil {
+		reconcilers = append(reconcilers, components.NewDriftDetector(r.Client, r.Scheme, isvcConfig))
+	}
+	if isvc.Spec.OutlierDetector != nil {
+		reconcilers = append(reconcilers, components.NewOutlierDetector(r.Client, r.Scheme, isvcConfig))
+	}
 	for _, reconciler := range reconcilers {
 		if err := reconciler.Reconcile(isvc); err != nil {
 			r.Log.Error(err, "Failed to reconcile", "reconciler", reflect.ValueOf(reconciler), "Name", isvc.Name)
@@ -134,12 +237,26 @@ func (r *InferenceServiceReconciler) Reconcile(req ctrl.Request) (ctrl.Result, e
 			return reconcile.Result{}, errors.Wrapf(err, "fails to reconcile component")
 		}
 	}
+	// The detectors are optional, the knative service of a detector removed from the spec is deleted
+	removedDetectors := []v1beta1api.ComponentType{}
+	if isvc.Spec.DriftDetector == nil {
+		removedDetectors = append(removedDetectors, v1beta1api.DriftDetectorComponent)
+	}
+	if isvc.Spec.OutlierDetector == nil {
+		removedDetectors = append(removedDetectors, v1beta1api.OutlierDetectorComponent)
+	}
+	for _, component := range removedDetectors {
+		if err := components.RemoveDetector(r.Client, isvc, component); err != nil {
+			r.Recorder.Eventf(isvc, v1.EventTypeWarning, "InternalError", err.Error())
+			return reconcile.Result{}, errors.Wrapf(err, "fails to remove %s", component)
+		}
+	}
 	//Reconcile ingress
 	ingressConfig, err := v1beta1api.NewIngressConfig(r.Client)
 	if err != nil {
 		return reconcile.Result{}, errors.Wrapf(err, "fails to create IngressConfig")
 	}
-	reconciler := ingress.NewIngressReconciler(r.Client, r.Scheme, ingressConfig)
+	reconciler := ingress.NewIngressReconciler(r.Client, r.Scheme, ingressConfig, isvcConfig.Drift.Policy)
 	r.Log.Info("Reconciling ingress for inference service", "isvc", isvc.Name)
 	if err := reconciler.Reconcile(isvc); err != nil {
 		return reconcile.Result{}, errors.Wrapf(err, "fails to reconcile ingress")
@@ -151,12 +268,98 @@ func (r *InferenceServiceReconciler) Reconcile(req ctrl.Request) (ctrl.Result, e
 		return reconcile.Result{}, err
 	}
 
+	// The model metadata is fetched once the revision is ready, a failure does not block the status of the
+	// InferenceService as the requests are not validated until then
+	schemaFetched := true
+	if err := schema.NewSchemaReconciler(r.Client, r.Scheme).Reconcile(isvc); err != nil {
+		r.Log.Error(err, "Failed to reconcile model schema", "Name", isvc.Name)
+		r.Recorder.Eventf(isvc, v1.EventTypeWarning, "SchemaFetchFailed", err.Error())
+		schemaFetched = false
+	}
+
+	cost.PropagateCost(isvc, isvcConfig.Cost, time.Now())
+
 	if err = r.updateStatus(isvc); err != nil {
 		r.Recorder.Eventf(isvc, v1.EventTypeWarning, "InternalError", err.Error())
 		return reconcile.Result{}, err
 	}
 
-	return ctrl.Result{}, nil
+	now = time.Now()
+	requeueAfter := minRequeue(nextScalingScheduleActivation(isvc, now), nextComponentCheck(isvc, now))
+	requeueAfter = minRequeue(requeueAfter, nextExpirationCheck(isvc, now))
+	requeueAfter = minRequeue(requeueAfter, components.NextDriftAlertCheck(isvc, now))
+	if !schemaFetched {
+		requeueAfter = minRequeue(requeueAfter, schemaRetryInterval)
+	}
+	requeueAfter = minRequeue(requeueAfter, pool.NextCheck(isvc, now))
+	requeueAfter = minRequeue(requeueAfter, registry.NextRefresh(isvc, now))
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// nextComponentCheck returns the delay until the next check of a progressing canary analysis, revision rollout or
+// warm-up, or the next request rate forecast, zero if no component is analyzed, rolled out, warmed up or predictively
+// scaled
+func nextComponentCheck(isvc *v1beta1api.InferenceService, now time.Time) time.Duration {
+	extensions := map[v1beta1api.ComponentType]*v1beta1api.ComponentExtensionSpec{
+		v1beta1api.PredictorComponent: &isvc.Spec.Predictor.ComponentExtensionSpec,
+	}
+	if isvc.Spec.Transformer != nil {
+		extensions[v1beta1api.TransformerComponent] = &isvc.Spec.Transformer.ComponentExtensionSpec
+	}
+	if isvc.Spec.Explainer != nil {
+		extensions[v1beta1api.ExplainerComponent] = &isvc.Spec.Explainer.ComponentExtensionSpec
+	}
+	if isvc.Spec.DriftDetector != nil {
+		extensions[v1beta1api.DriftDetectorComponent] = &isvc.Spec.DriftDetector.ComponentExtensionSpec
+	}
+	if isvc.Spec.OutlierDetector != nil {
+		extensions[v1beta1api.OutlierDetectorComponent] = &isvc.Spec.OutlierDetector.ComponentExtensionSpec
+	}
+	var next time.Duration
+	for component, extension := range extensions {
+		next = minRequeue(next, canary.NextCheck(extension, isvc.Status.Components[component], now))
+		next = minRequeue(next, rollout.NextCheck(extension, isvc.Status.Components[component], now))
+		next = minRequeue(next, predictive.NextForecast(extension, isvc.Status.Components[component], now))
+		next = minRequeue(next, warmup.NextCheck(extension, isvc.Status.Components[component]))
+	}
+	return next
+}
+
+// minRequeue returns the shortest of the delays, zero delays do not requeue
+func minRequeue(a time.Duration, b time.Duration) time.Duration {
+	if a == 0 || (b != 0 && b < a) {
+		return b
+	}
+	return a
+}
+
+// nextScalingScheduleActivation returns the delay until the next scaling schedule of a component fires, or zero if
+// no component has a scaling schedule
+func nextScalingScheduleActivation(isvc *v1beta1api.InferenceService, now time.Time) time.Duration {
+	extensions := []*v1beta1api.ComponentExtensionSpec{&isvc.Spec.Predictor.ComponentExtensionSpec}
+	if isvc.Spec.Transformer != nil {
+		extensions = append(extensions, &isvc.Spec.Transformer.ComponentExtensionSpec)
+	}
+	if isvc.Spec.Explainer != nil {
+		extensions = append(extensions, &isvc.Spec.Explainer.ComponentExtensionSpec)
+	}
+	if isvc.Spec.DriftDetector != nil {
+		extensions = append(extensions, &isvc.Spec.DriftDetector.ComponentExtensionSpec)
+	}
+	if isvc.Spec.OutlierDetector != nil {
+		extensions = append(extensions, &isvc.Spec.OutlierDetector.ComponentExtensionSpec)
+	}
+	var next time.Time
+	for _, extension := range extensions {
+		if _, activation := isvcutils.GetMinReplicas(extension, now); !activation.IsZero() &&
+			(next.IsZero() || activation.Before(next)) {
+			next = activation
+		}
+	}
+	if next.IsZero() {
+		return 0
+	}
+	return next.Sub(now)
 }
 
 func (r *InferenceServiceReconciler) updateStatus(desiredService *v1beta1api.InferenceService) error {
@@ -186,10 +389,19 @@ func (r *InferenceServiceReconciler) updateStatus(desiredService *v1beta1api.Inf
 			r.Recorder.Eventf(desiredService, v1.EventTypeNormal, string(v1alpha2.InferenceServiceReadyState),
 				fmt.Sprintf("InferenceService [%v] is Ready", desiredService.GetName()))
 		}
+		if !driftDetected(existingService.Status) && driftDetected(desiredService.Status) {
+			r.Recorder.Eventf(desiredService, v1.EventTypeWarning, v1beta1api.DriftAlertReason,
+				desiredService.Status.GetCondition(v1beta1api.DriftDetected).Message)
+		}
 	}
 	return nil
 }
 
+func driftDetected(status v1beta1api.InferenceServiceStatus) bool {
+	condition := status.GetCondition(v1beta1api.DriftDetected)
+	return condition != nil && condition.Status == v1.ConditionTrue
+}
+
 func inferenceServiceReadiness(status v1beta1api.InferenceServiceStatus) bool {
 	return status.Conditions != nil &&
 		status.GetCondition(apis.ConditionReady) != nil &&
@@ -197,11 +409,226 @@ func inferenceServiceReadiness(status v1beta1api.InferenceServiceStatus) bool {
 }
 
 func (r *InferenceServiceReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	builder := ctrl.NewControllerManagedBy(mgr).
 		For(&v1beta1api.InferenceService{}).
 		Owns(&knservingv1.Service{}).
 		Owns(&v1alpha3.VirtualService{}).
-		Complete(r)
+		// The revision autoscalers are owned by knative, watch them to keep the replicas reported for the scale
+		// subresource up to date
+		Watches(&source.Kind{Type: &autoscalingv1alpha1.PodAutoscaler{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(inferenceServiceRequestsForPodAutoscaler),
+		}).
+		// The paused annotation on a namespace applies to all its InferenceServices
+		Watches(&source.Kind{Type: &v1.Namespace{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(r.inferenceServiceRequestsForNamespace),
+		}).
+		// The pending InferenceServices of the namespace are admitted again when a quota changes
+		Watches(&source.Kind{Type: &v1alpha1api.InferenceQuota{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(r.inferenceServiceRequestsForQuota),
+		})
+	// The session affinity destination rules are only watched when the CRD is installed, the istio installations
+	// of some knative clusters do not ship it
+	destinationRuleGVK := v1alpha3.SchemeGroupVersion.WithKind("DestinationRule")
+	if _, err := mgr.GetRESTMapper().RESTMapping(destinationRuleGVK.GroupKind(), destinationRuleGVK.Version); err == nil {
+		builder = builder.Owns(&v1alpha3.DestinationRule{})
+	} else {
+		r.Log.Info("DestinationRule CRD is not installed, the session affinity is not watched", "error", err.Error())
+	}
+	// ModelMesh is optional, its predictors are only watched when the CRD is installed
+	if _, err := mgr.GetRESTMapper().RESTMapping(modelmesh.PredictorGVK.GroupKind(), modelmesh.PredictorGVK.Version); err == nil {
+		predictor := &unstructured.Unstructured{}
+		predictor.SetGroupVersionKind(modelmesh.PredictorGVK)
+		builder = builder.Owns(predictor)
+	} else {
+		r.Log.Info("ModelMesh predictor CRD is not installed, the ModelMesh deployment mode is not watched", "error", err.Error())
+	}
+	return builder.Complete(r)
+}
+
+// inferenceServiceRequestsForNamespace maps a namespace to all the InferenceServices in it
+func (r *InferenceServiceReconciler) inferenceServiceRequestsForNamespace(obj handler.MapObject) []reconcile.Request {
+	return r.inferenceServiceRequestsInNamespace(obj.Meta.GetName())
+}
+
+// inferenceServiceRequestsForQuota maps an InferenceQuota to all the InferenceServices in its namespace
+func (r *InferenceServiceReconciler) inferenceServiceRequestsForQuota(obj handler.MapObject) []reconcile.Request {
+	return r.inferenceServiceRequestsInNamespace(obj.Meta.GetNamespace())
+}
+
+func (r *InferenceServiceReconciler) inferenceServiceRequestsInNamespace(namespace string) []reconcile.Request {
+	isvcs := &v1beta1api.InferenceServiceList{}
+	if err := r.List(context.TODO(), isvcs, client.InNamespace(namespace)); err != nil {
+		r.Log.Error(err, "unable to list InferenceServices", "namespace", namespace)
+		return nil
+	}
+	requests := []reconcile.Request{}
+	for _, isvc := range isvcs.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: isvc.Name, Namespace: isvc.Namespace},
+		})
+	}
+	return requests
+}
+
+// expirationWarning returns the warning of an InferenceService which expires within the warning period, or an empty
+// string if it does not expire soon
+func expirationWarning(isvc *v1beta1api.InferenceService, now time.Time) string {
+	expiration := isvc.GetExpirationTime()
+	if expiration == nil || expiration.Sub(now) > expirationWarningPeriod {
+		return ""
+	}
+	return fmt.Sprintf("InferenceService will be deleted at %s", expiration.UTC().Format(time.RFC3339))
+}
+
+// nextExpirationCheck returns the delay until the InferenceService is warned about its expiration or expires, or zero
+// if it does not expire
+func nextExpirationCheck(isvc *v1beta1api.InferenceService, now time.Time) time.Duration {
+	expiration := isvc.GetExpirationTime()
+	if expiration == nil {
+		return 0
+	}
+	next := expiration.Sub(now)
+	if next > expirationWarningPeriod {
+		next -= expirationWarningPeriod
+	}
+	if next <= 0 {
+		return time.Second
+	}
+	return next
+}
+
+// expire scales down the expired InferenceService and deletes it. The knative services are removed first so that
+// their replicas are freed while the deletion waits for the finalizers.
+func (r *InferenceServiceReconciler) expire(isvc *v1beta1api.InferenceService, expiration *metav1.Time) error {
+	message := fmt.Sprintf("InferenceService expired at %s", expiration.UTC().Format(time.RFC3339))
+	r.Recorder.Eventf(isvc, v1.EventTypeNormal, v1beta1api.ExpiredReason, message)
+	if err := r.pause(isvc, message); err != nil {
+		return errors.Wrapf(err, "fails to scale down expired InferenceService")
+	}
+	if err := r.updateStatus(isvc); err != nil {
+		return err
+	}
+	r.Log.Info("Deleting expired inference service", "isvc", isvc.Name, "expiration", expiration.UTC())
+	return client.IgnoreNotFound(r.Delete(context.TODO(), isvc))
+}
+
+// pausedMessage returns why the InferenceService is paused, or an empty string if it is not paused
+func (r *InferenceServiceReconciler) pausedMessage(isvc *v1beta1api.InferenceService) (string, error) {
+	if isvc.Annotations[constants.PausedAnnotationKey] == "true" {
+		return fmt.Sprintf("InferenceService is paused by the %s annotation", constants.PausedAnnotationKey), nil
+	}
+	namespace := &v1.Namespace{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Name: isvc.Namespace}, namespace); err != nil {
+		return "", err
+	}
+	if namespace.Annotations[constants.PausedAnnotationKey] == "true" {
+		return fmt.Sprintf("InferenceServices in namespace %s are paused by the %s annotation", isvc.Namespace,
+			constants.PausedAnnotationKey), nil
+	}
+	return "", nil
+}
+
+// quotaMessage returns the limit of the InferenceQuotas of the namespace the InferenceService waits for, or an empty
+// string when it can be reconciled
+func (r *InferenceServiceReconciler) quotaMessage(isvc *v1beta1api.InferenceService) (string, error) {
+	quotas := &v1alpha1api.InferenceQuotaList{}
+	if err := r.List(context.TODO(), quotas, client.InNamespace(isvc.Namespace)); err != nil {
+		return "", err
+	}
+	if len(quotas.Items) == 0 {
+		return "", nil
+	}
+	isvcs := &v1beta1api.InferenceServiceList{}
+	if err := r.List(context.TODO(), isvcs, client.InNamespace(isvc.Namespace)); err != nil {
+		return "", err
+	}
+	return quota.Admit(isvc, quotas.Items, isvcs.Items), nil
+}
+
+// pause scales down the InferenceService by removing the knative services of its components or its ModelMesh
+// predictor, they are recreated when the InferenceService is resumed. The ingress is kept so that the InferenceService url does not change.
+func (r *InferenceServiceReconciler) pause(isvc *v1beta1api.InferenceService, message string) error {
+	r.Log.Info("Pausing inference service", "isvc", isvc.Name, "reason", message)
+	components := map[v1beta1api.ComponentType]string{
+		v1beta1api.PredictorComponent:       constants.DefaultPredictorServiceName(isvc.Name),
+		v1beta1api.TransformerComponent:     constants.DefaultTransformerServiceName(isvc.Name),
+		v1beta1api.ExplainerComponent:       constants.DefaultExplainerServiceName(isvc.Name),
+		v1beta1api.DriftDetectorComponent:   constants.DefaultDriftDetectorServiceName(isvc.Name),
+		v1beta1api.OutlierDetectorComponent: constants.DefaultOutlierDetectorServiceName(isvc.Name),
+	}
+	for component, serviceName := range components {
+		service := &knservingv1.Service{}
+		if err := r.Get(context.TODO(), types.NamespacedName{Name: serviceName, Namespace: isvc.Namespace}, service); err != nil {
+			if apierr.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		if !metav1.IsControlledBy(service, isvc) {
+			continue
+		}
+		if err := r.Delete(context.TODO(), service); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		r.Recorder.Eventf(isvc, v1.EventTypeNormal, v1beta1api.PausedReason, "Removed knative service %s", serviceName)
+		isvc.Status.PropagatePaused(component, message)
+	}
+	// The replica pool of the predictor is recreated with the predictor
+	poolService := &knservingv1.Service{}
+	poolName := constants.PredictorPoolServiceName(isvc.Name)
+	if err := r.Get(context.TODO(), types.NamespacedName{Name: poolName, Namespace: isvc.Namespace}, poolService); err == nil {
+		if metav1.IsControlledBy(poolService, isvc) {
+			if err := r.Delete(context.TODO(), poolService); client.IgnoreNotFound(err) != nil {
+				return err
+			}
+			r.Recorder.Eventf(isvc, v1.EventTypeNormal, v1beta1api.PausedReason, "Removed knative service %s", poolName)
+		}
+	} else if !apierr.IsNotFound(err) {
+		return err
+	}
+	// The model versions of the predictor are recreated with the predictor
+	for _, version := range isvc.Spec.Predictor.Versions {
+		versionService := &knservingv1.Service{}
+		versionName := constants.PredictorVersionServiceName(isvc.Name, version.Name)
+		if err := r.Get(context.TODO(), types.NamespacedName{Name: versionName, Namespace: isvc.Namespace}, versionService); err == nil {
+			if metav1.IsControlledBy(versionService, isvc) {
+				if err := r.Delete(context.TODO(), versionService); client.IgnoreNotFound(err) != nil {
+					return err
+				}
+				r.Recorder.Eventf(isvc, v1.EventTypeNormal, v1beta1api.PausedReason, "Removed knative service %s", versionName)
+			}
+		} else if !apierr.IsNotFound(err) {
+			return err
+		}
+	}
+	if isvcutils.GetDeploymentMode(isvc) == constants.ModelMeshDeployment {
+		predictor := &unstructured.Unstructured{}
+		predictor.SetGroupVersionKind(modelmesh.PredictorGVK)
+		if err := r.Get(context.TODO(), types.NamespacedName{Name: isvc.Name, Namespace: isvc.Namespace}, predictor); err == nil {
+			if metav1.IsControlledBy(predictor, isvc) {
+				if err := r.Delete(context.TODO(), predictor); client.IgnoreNotFound(err) != nil {
+					return err
+				}
+				r.Recorder.Eventf(isvc, v1.EventTypeNormal, v1beta1api.PausedReason, "Removed modelmesh predictor %s", isvc.Name)
+			}
+		} else if !apierr.IsNotFound(err) {
+			return err
+		}
+	}
+	isvc.Status.PropagatePaused(v1beta1api.PredictorComponent, message)
+	return nil
+}
+
+// inferenceServiceRequestsForPodAutoscaler maps the autoscaler of a revision to its InferenceService, the autoscalers
+// carry the labels of the revision template
+func inferenceServiceRequestsForPodAutoscaler(obj handler.MapObject) []reconcile.Request {
+	name, ok := obj.Meta.GetLabels()[constants.InferenceServicePodLabelKey]
+	if !ok {
+		return nil
+	}
+	return []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Name: name, Namespace: obj.Meta.GetNamespace()}},
+	}
 }
 
 func (r *InferenceServiceReconciler) deleteExternalResources(isvc *v1beta1api.InferenceService) error {
@@ -26,6 +26,7 @@ import (
 	istiov1alpha3 "istio.io/api/networking/v1alpha3"
 	"istio.io/client-go/pkg/apis/networking/v1alpha3"
 	v1 "k8s.io/api/core/v1"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -460,6 +461,7 @@ var _ = Describe("v1beta1 inference service controller", func() {
 						LatestReadyRevision:   "revision-v1",
 						LatestCreatedRevision: "revision-v1",
 						URL:                   predictorUrl,
+						Selector:              "component=predictor,serving.kubeflow.org/inferenceservice=" + serviceName,
 					},
 					v1beta1.TransformerComponent: {
 						LatestReadyRevision:   "t-revision-v1",
@@ -677,6 +679,7 @@ var _ = Describe("v1beta1 inference service controller", func() {
 						LatestReadyRevision:   "revision-v1",
 						LatestCreatedRevision: "revision-v1",
 						URL:                   predictorUrl,
+						Selector:              "component=predictor,serving.kubeflow.org/inferenceservice=" + serviceName,
 					},
 					v1beta1.ExplainerComponent: {
 						LatestReadyRevision:   "exp-revision-v1",
@@ -934,4 +937,250 @@ var _ = Describe("v1beta1 inference service controller", func() {
 			}, timeout, interval).Should(BeTrue())
 		})
 	})
+
+	Context("When pausing and resuming inference service", func() {
+		It("Should remove and recreate the knative service", func() {
+			By("By creating a new InferenceService")
+			var configMap = &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      constants.InferenceServiceConfigMapName,
+					Namespace: constants.KFServingNamespace,
+				},
+				Data: configs,
+			}
+			Expect(k8sClient.Create(context.TODO(), configMap)).NotTo(HaveOccurred())
+			defer k8sClient.Delete(context.TODO(), configMap)
+
+			serviceKey := types.NamespacedName{Name: "paused-isvc", Namespace: "default"}
+			predictorServiceKey := types.NamespacedName{Name: constants.DefaultPredictorServiceName(serviceKey.Name),
+				Namespace: serviceKey.Namespace}
+			storageUri := "s3://test/mnist/export"
+			ctx := context.Background()
+			isvc := &v1beta1.InferenceService{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      serviceKey.Name,
+					Namespace: serviceKey.Namespace,
+				},
+				Spec: v1beta1.InferenceServiceSpec{
+					Predictor: v1beta1.PredictorSpec{
+						Tensorflow: &v1beta1.TFServingSpec{
+							PredictorExtensionSpec: v1beta1.PredictorExtensionSpec{
+								StorageURI:     &storageUri,
+								RuntimeVersion: proto.String("1.14.0"),
+								Container: v1.Container{
+									Name:      "kfs",
+									Resources: defaultResource,
+								},
+							},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, isvc)).Should(Succeed())
+			defer k8sClient.Delete(ctx, isvc)
+
+			actualService := &knservingv1.Service{}
+			Eventually(func() error { return k8sClient.Get(ctx, predictorServiceKey, actualService) }, timeout).
+				Should(Succeed())
+
+			By("By pausing the InferenceService")
+			Expect(retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+				updated := &v1beta1.InferenceService{}
+				if err := k8sClient.Get(ctx, serviceKey, updated); err != nil {
+					return err
+				}
+				updated.Annotations = map[string]string{constants.PausedAnnotationKey: "true"}
+				return k8sClient.Update(ctx, updated)
+			})).Should(Succeed())
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, predictorServiceKey, &knservingv1.Service{})
+				return apierr.IsNotFound(err)
+			}, timeout).Should(BeTrue())
+			Eventually(func() string {
+				updated := &v1beta1.InferenceService{}
+				if err := k8sClient.Get(ctx, serviceKey, updated); err != nil {
+					return ""
+				}
+				if condition := updated.Status.GetCondition(v1beta1.PredictorReady); condition != nil {
+					return condition.Reason
+				}
+				return ""
+			}, timeout).Should(Equal(v1beta1.PausedReason))
+
+			By("By resuming the InferenceService")
+			Expect(retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+				updated := &v1beta1.InferenceService{}
+				if err := k8sClient.Get(ctx, serviceKey, updated); err != nil {
+					return err
+				}
+				delete(updated.Annotations, constants.PausedAnnotationKey)
+				return k8sClient.Update(ctx, updated)
+			})).Should(Succeed())
+			Eventually(func() error { return k8sClient.Get(ctx, predictorServiceKey, &knservingv1.Service{}) }, timeout).
+				Should(Succeed())
+		})
+	})
+
+	Context("When removing the outlier detector of an inference service", func() {
+		It("Should delete the knative service of the detector and clear its status", func() {
+			By("By creating a new InferenceService with an outlier detector")
+			var configMap = &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      constants.InferenceServiceConfigMapName,
+					Namespace: constants.KFServingNamespace,
+				},
+				Data: configs,
+			}
+			Expect(k8sClient.Create(context.TODO(), configMap)).NotTo(HaveOccurred())
+			defer k8sClient.Delete(context.TODO(), configMap)
+
+			serviceKey := types.NamespacedName{Name: "detector-isvc", Namespace: "default"}
+			detectorServiceKey := types.NamespacedName{Name: constants.DefaultOutlierDetectorServiceName(serviceKey.Name),
+				Namespace: serviceKey.Namespace}
+			storageUri := "s3://test/mnist/export"
+			ctx := context.Background()
+			isvc := &v1beta1.InferenceService{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      serviceKey.Name,
+					Namespace: serviceKey.Namespace,
+				},
+				Spec: v1beta1.InferenceServiceSpec{
+					Predictor: v1beta1.PredictorSpec{
+						Tensorflow: &v1beta1.TFServingSpec{
+							PredictorExtensionSpec: v1beta1.PredictorExtensionSpec{
+								StorageURI:     &storageUri,
+								RuntimeVersion: proto.String("1.14.0"),
+								Container: v1.Container{
+									Name:      "kfs",
+									Resources: defaultResource,
+								},
+							},
+						},
+					},
+					OutlierDetector: &v1beta1.OutlierDetectorSpec{
+						PodSpec: v1beta1.PodSpec{
+							Containers: []v1.Container{
+								{
+									Name:      constants.InferenceServiceContainerName,
+									Image:     "outlier-detector:0.1.0",
+									Resources: defaultResource,
+								},
+							},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, isvc)).Should(Succeed())
+			defer k8sClient.Delete(ctx, isvc)
+
+			Eventually(func() error { return k8sClient.Get(ctx, detectorServiceKey, &knservingv1.Service{}) }, timeout).
+				Should(Succeed())
+			Eventually(func() bool {
+				updated := &v1beta1.InferenceService{}
+				if err := k8sClient.Get(ctx, serviceKey, updated); err != nil {
+					return false
+				}
+				return updated.Status.GetCondition(v1beta1.OutlierDetectorReady) != nil
+			}, timeout).Should(BeTrue())
+
+			By("By removing the outlier detector")
+			Expect(retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+				updated := &v1beta1.InferenceService{}
+				if err := k8sClient.Get(ctx, serviceKey, updated); err != nil {
+					return err
+				}
+				updated.Spec.OutlierDetector = nil
+				return k8sClient.Update(ctx, updated)
+			})).Should(Succeed())
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, detectorServiceKey, &knservingv1.Service{})
+				return apierr.IsNotFound(err)
+			}, timeout).Should(BeTrue())
+			Eventually(func() bool {
+				updated := &v1beta1.InferenceService{}
+				if err := k8sClient.Get(ctx, serviceKey, updated); err != nil {
+					return false
+				}
+				_, ok := updated.Status.Components[v1beta1.OutlierDetectorComponent]
+				return !ok && updated.Status.GetCondition(v1beta1.OutlierDetectorReady) == nil
+			}, timeout).Should(BeTrue())
+		})
+	})
+
+	Context("When an inference service expires", func() {
+		It("Should warn about the expiration and delete the inference service", func() {
+			By("By creating a new InferenceService")
+			var configMap = &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      constants.InferenceServiceConfigMapName,
+					Namespace: constants.KFServingNamespace,
+				},
+				Data: configs,
+			}
+			Expect(k8sClient.Create(context.TODO(), configMap)).NotTo(HaveOccurred())
+			defer k8sClient.Delete(context.TODO(), configMap)
+
+			serviceKey := types.NamespacedName{Name: "expiring-isvc", Namespace: "default"}
+			predictorServiceKey := types.NamespacedName{Name: constants.DefaultPredictorServiceName(serviceKey.Name),
+				Namespace: serviceKey.Namespace}
+			storageUri := "s3://test/mnist/export"
+			ctx := context.Background()
+			expireAt := metav1.NewTime(time.Now().Add(30 * time.Minute))
+			isvc := &v1beta1.InferenceService{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      serviceKey.Name,
+					Namespace: serviceKey.Namespace,
+				},
+				Spec: v1beta1.InferenceServiceSpec{
+					Predictor: v1beta1.PredictorSpec{
+						Tensorflow: &v1beta1.TFServingSpec{
+							PredictorExtensionSpec: v1beta1.PredictorExtensionSpec{
+								StorageURI:     &storageUri,
+								RuntimeVersion: proto.String("1.14.0"),
+								Container: v1.Container{
+									Name:      "kfs",
+									Resources: defaultResource,
+								},
+							},
+						},
+					},
+					ExpireAt: &expireAt,
+				},
+			}
+			Expect(k8sClient.Create(ctx, isvc)).Should(Succeed())
+			defer k8sClient.Delete(ctx, isvc)
+
+			Eventually(func() error { return k8sClient.Get(ctx, predictorServiceKey, &knservingv1.Service{}) }, timeout).
+				Should(Succeed())
+			Eventually(func() string {
+				updated := &v1beta1.InferenceService{}
+				if err := k8sClient.Get(ctx, serviceKey, updated); err != nil {
+					return ""
+				}
+				if condition := updated.Status.GetCondition(v1beta1.Expiring); condition != nil {
+					return condition.Reason
+				}
+				return ""
+			}, timeout).Should(Equal(v1beta1.ExpiringReason))
+
+			By("By moving the expiration to the past")
+			Expect(retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+				updated := &v1beta1.InferenceService{}
+				if err := k8sClient.Get(ctx, serviceKey, updated); err != nil {
+					return err
+				}
+				expired := metav1.NewTime(time.Now().Add(-time.Minute))
+				updated.Spec.ExpireAt = &expired
+				return k8sClient.Update(ctx, updated)
+			})).Should(Succeed())
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, predictorServiceKey, &knservingv1.Service{})
+				return apierr.IsNotFound(err)
+			}, timeout).Should(BeTrue())
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, serviceKey, &v1beta1.InferenceService{})
+				return apierr.IsNotFound(err)
+			}, timeout).Should(BeTrue())
+		})
+	})
 })
@@ -0,0 +1,166 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cost estimates the hourly cost of an InferenceService from the resources requested by its components
+// and the price table of the inferenceservice configmap.
+package cost
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	v1beta1utils "github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/utils"
+	"github.com/kubeflow/kfserving/pkg/utils"
+	"github.com/prometheus/client_golang/prometheus"
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const bytesPerGB = 1 << 30
+
+// hourlyCost is exported on the metrics endpoint of the manager
+var hourlyCost = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "kfserving_inferenceservice_hourly_cost",
+	Help: "Approximate hourly cost of the resources requested by the InferenceService components",
+}, []string{"namespace", "inferenceservice", "currency"})
+
+func init() {
+	metrics.Registry.MustRegister(hourlyCost)
+}
+
+// requested returns the requested quantity of the resource, or its limit when it is not requested
+func requested(resources v1.ResourceRequirements, name v1.ResourceName) float64 {
+	if quantity, ok := resources.Requests[name]; ok {
+		return float64(quantity.MilliValue()) / 1000
+	}
+	if quantity, ok := resources.Limits[name]; ok {
+		return float64(quantity.MilliValue()) / 1000
+	}
+	return 0
+}
+
+// containerCost returns the hourly cost of one replica of the container
+func containerCost(container *v1.Container, prices *v1beta1.CostConfig) float64 {
+	cost := requested(container.Resources, v1.ResourceCPU)*prices.CPUPerHour +
+		requested(container.Resources, v1.ResourceMemory)/bytesPerGB*prices.MemoryGBPerHour
+	gpuResources := map[v1.ResourceName]bool{}
+	for _, resources := range []v1.ResourceList{container.Resources.Limits, container.Resources.Requests} {
+		for name := range resources {
+			if utils.IsGPUResource(name) {
+				gpuResources[name] = true
+			}
+		}
+	}
+	for name := range gpuResources {
+		cost += requested(container.Resources, name) * prices.GPUPerHour
+	}
+	return cost
+}
+
+// componentCost returns the hourly cost of the component replicas, which are the reported replicas but at least the
+// minimum replicas in effect
+func componentCost(podSpec *v1beta1.PodSpec, extension *v1beta1.ComponentExtensionSpec, status v1beta1.ComponentStatusSpec,
+	prices *v1beta1.CostConfig, now time.Time) float64 {
+	replicas, _ := v1beta1utils.GetMinReplicas(extension, now)
+	if int(status.Replicas) > replicas {
+		replicas = int(status.Replicas)
+	}
+	cost := 0.0
+	for i := range podSpec.Containers {
+		cost += containerCost(&podSpec.Containers[i], prices)
+	}
+	return cost * float64(replicas)
+}
+
+// poolCost returns the hourly cost of the replica pool of the predictor, the pool replicas run the predictor
+// containers with the resources of the pool
+func poolCost(isvc *v1beta1.InferenceService, prices *v1beta1.CostConfig, now time.Time) float64 {
+	replicaPool := isvc.Spec.Predictor.Pool
+	if replicaPool == nil || len(isvc.Spec.Predictor.PodSpec.Containers) == 0 {
+		return 0
+	}
+	podSpec := isvc.Spec.Predictor.PodSpec.DeepCopy()
+	podSpec.Containers[0].Resources = replicaPool.Resources
+	extension := &v1beta1.ComponentExtensionSpec{MinReplicas: replicaPool.MinReplicas}
+	return componentCost(podSpec, extension, v1beta1.ComponentStatusSpec{}, prices, now)
+}
+
+// versionsCost returns the hourly cost of the model versions of the predictor, the versions run the predictor
+// containers with the replicas of the predictor unless they set their own minimum replicas
+func versionsCost(isvc *v1beta1.InferenceService, prices *v1beta1.CostConfig, now time.Time) float64 {
+	cost := 0.0
+	for _, version := range isvc.Spec.Predictor.Versions {
+		extension := &v1beta1.ComponentExtensionSpec{MinReplicas: version.MinReplicas}
+		if version.MinReplicas == nil {
+			extension = &isvc.Spec.Predictor.ComponentExtensionSpec
+		}
+		cost += componentCost(&isvc.Spec.Predictor.PodSpec, extension, v1beta1.ComponentStatusSpec{}, prices, now)
+	}
+	return cost
+}
+
+// Estimate returns the hourly cost of the InferenceService. The component containers are read from the pod specs,
+// so the InferenceService is expected to have been reconciled by the components.
+func Estimate(isvc *v1beta1.InferenceService, prices *v1beta1.CostConfig, now time.Time) float64 {
+	cost := componentCost(&isvc.Spec.Predictor.PodSpec, &isvc.Spec.Predictor.ComponentExtensionSpec,
+		isvc.Status.Components[v1beta1.PredictorComponent], prices, now)
+	cost += poolCost(isvc, prices, now)
+	cost += versionsCost(isvc, prices, now)
+	if isvc.Spec.Transformer != nil {
+		cost += componentCost(&isvc.Spec.Transformer.PodSpec, &isvc.Spec.Transformer.ComponentExtensionSpec,
+			isvc.Status.Components[v1beta1.TransformerComponent], prices, now)
+	}
+	if isvc.Spec.Explainer != nil {
+		cost += componentCost(&isvc.Spec.Explainer.PodSpec, &isvc.Spec.Explainer.ComponentExtensionSpec,
+			isvc.Status.Components[v1beta1.ExplainerComponent], prices, now)
+	}
+	if isvc.Spec.DriftDetector != nil {
+		cost += componentCost(&isvc.Spec.DriftDetector.PodSpec, &isvc.Spec.DriftDetector.ComponentExtensionSpec,
+			isvc.Status.Components[v1beta1.DriftDetectorComponent], prices, now)
+	}
+	if isvc.Spec.OutlierDetector != nil {
+		cost += componentCost(&isvc.Spec.OutlierDetector.PodSpec, &isvc.Spec.OutlierDetector.ComponentExtensionSpec,
+			isvc.Status.Components[v1beta1.OutlierDetectorComponent], prices, now)
+	}
+	return cost
+}
+
+// PropagateCost sets the estimated cost in the status and the metric, the estimation is removed when no price table
+// is configured
+func PropagateCost(isvc *v1beta1.InferenceService, prices *v1beta1.CostConfig, now time.Time) {
+	if prices == nil {
+		Forget(isvc)
+		isvc.Status.Cost = nil
+		return
+	}
+	if isvc.Status.Cost != nil && isvc.Status.Cost.Currency != prices.Currency {
+		Forget(isvc)
+	}
+	cost := Estimate(isvc, prices, now)
+	isvc.Status.Cost = &v1beta1.CostStatus{
+		HourlyCost: fmt.Sprintf("%.4f", cost),
+		Currency:   prices.Currency,
+	}
+	hourlyCost.WithLabelValues(isvc.Namespace, isvc.Name, prices.Currency).Set(cost)
+}
+
+// Forget removes the metric of the InferenceService reported in its status
+func Forget(isvc *v1beta1.InferenceService) {
+	if isvc.Status.Cost != nil {
+		hourlyCost.DeleteLabelValues(isvc.Namespace, isvc.Name, isvc.Status.Cost.Currency)
+	}
+}
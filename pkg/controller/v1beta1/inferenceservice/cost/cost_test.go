@@ -0,0 +1,119 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cost
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var prices = &v1beta1.CostConfig{
+	CPUPerHour:      0.04,
+	MemoryGBPerHour: 0.005,
+	GPUPerHour:      2.5,
+	Currency:        "USD",
+}
+
+func newInferenceService() *v1beta1.InferenceService {
+	minReplicas := 2
+	return &v1beta1.InferenceService{
+		ObjectMeta: metav1.ObjectMeta{Name: "sklearn", Namespace: "default"},
+		Spec: v1beta1.InferenceServiceSpec{
+			Predictor: v1beta1.PredictorSpec{
+				ComponentExtensionSpec: v1beta1.ComponentExtensionSpec{MinReplicas: &minReplicas},
+				PodSpec: v1beta1.PodSpec{
+					Containers: []v1.Container{{
+						Resources: v1.ResourceRequirements{
+							Requests: v1.ResourceList{
+								v1.ResourceCPU:    resource.MustParse("500m"),
+								v1.ResourceMemory: resource.MustParse("2Gi"),
+							},
+							Limits: v1.ResourceList{
+								v1.ResourceCPU:          resource.MustParse("1"),
+								"nvidia.com/mig-1g.5gb": resource.MustParse("1"),
+							},
+						},
+					}},
+				},
+			},
+			Transformer: &v1beta1.TransformerSpec{
+				PodSpec: v1beta1.PodSpec{
+					Containers: []v1.Container{{
+						Resources: v1.ResourceRequirements{
+							Limits: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")},
+						},
+					}},
+				},
+			},
+		},
+	}
+}
+
+func TestEstimate(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := newInferenceService()
+
+	// 2 predictor replicas of 0.5 CPU, 2GB and a MIG slice, 1 transformer replica of 1 CPU
+	g.Expect(Estimate(isvc, prices, time.Now())).To(gomega.BeNumerically("~", 2*(0.02+0.01+2.5)+0.04, 1e-9))
+
+	// the reported replicas are used once the predictor scales above its minimum replicas
+	isvc.Status.Components = map[v1beta1.ComponentType]v1beta1.ComponentStatusSpec{
+		v1beta1.PredictorComponent: {Replicas: 3},
+	}
+	g.Expect(Estimate(isvc, prices, time.Now())).To(gomega.BeNumerically("~", 3*(0.02+0.01+2.5)+0.04, 1e-9))
+
+	// the replica pool runs the predictor containers with its own resources
+	poolReplicas := 2
+	isvc.Spec.Predictor.Pool = &v1beta1.ReplicaPoolSpec{
+		MinReplicas: &poolReplicas,
+		Resources: v1.ResourceRequirements{
+			Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")},
+		},
+	}
+	g.Expect(Estimate(isvc, prices, time.Now())).To(gomega.BeNumerically("~", 3*(0.02+0.01+2.5)+0.04+2*0.08, 1e-9))
+	g.Expect(isvc.Spec.Predictor.PodSpec.Containers[0].Resources.Limits).To(gomega.HaveKey(v1.ResourceName("nvidia.com/mig-1g.5gb")))
+
+	// the model versions run the predictor containers with the minimum replicas of the predictor unless they set their own
+	isvc.Spec.Predictor.Pool = nil
+	versionReplicas := 1
+	isvc.Spec.Predictor.Versions = []v1beta1.ModelVersionSpec{
+		{Name: "v1", StorageURI: "gs://models/sklearn/v1"},
+		{Name: "v2", StorageURI: "gs://models/sklearn/v2", MinReplicas: &versionReplicas},
+	}
+	g.Expect(Estimate(isvc, prices, time.Now())).To(gomega.BeNumerically("~", (3+2+1)*(0.02+0.01+2.5)+0.04, 1e-9))
+}
+
+func TestPropagateCost(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := newInferenceService()
+
+	PropagateCost(isvc, prices, time.Now())
+	g.Expect(isvc.Status.Cost).To(gomega.Equal(&v1beta1.CostStatus{HourlyCost: "5.1000", Currency: "USD"}))
+	g.Expect(testutil.ToFloat64(hourlyCost.WithLabelValues("default", "sklearn", "USD"))).To(
+		gomega.BeNumerically("~", 5.1, 1e-9))
+
+	PropagateCost(isvc, nil, time.Now())
+	g.Expect(isvc.Status.Cost).To(gomega.BeNil())
+	g.Expect(hourlyCost.DeleteLabelValues("default", "sklearn", "USD")).To(gomega.BeFalse())
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package pool splits the traffic of the predictor with its replica pool. The Weighted routing sends a fixed percent
+// of the traffic to the pool, the Overflow routing sends the share of the request rate above the capacity of the
+// predictor replicas. The ingress reconciler routes the traffic according to the pool status.
+package pool
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/canary"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+var log = logf.Log.WithName("ReplicaPool")
+
+// CheckInterval is the time between the checks of the request rate of the predictor with Overflow routing
+const CheckInterval = time.Minute
+
+// RequestRateQuery returns the query of the request rate of the predictor and its pool reported by the queue proxies
+func RequestRateQuery(isvc *v1beta1.InferenceService) string {
+	return fmt.Sprintf(`sum(rate(revision_request_count{namespace_name="%s",service_name=~"%s|%s"}[1m]))`,
+		isvc.Namespace, constants.DefaultPredictorServiceName(isvc.Name), constants.PredictorPoolServiceName(isvc.Name))
+}
+
+// OverflowPercent returns the percent of the request rate above the capacity of the predictor replicas
+func OverflowPercent(requestsPerSecond float64, capacityRequestsPerSecond float64) int64 {
+	if requestsPerSecond <= capacityRequestsPerSecond {
+		return 0
+	}
+	return int64(math.Ceil((requestsPerSecond - capacityRequestsPerSecond) / requestsPerSecond * 100))
+}
+
+// Route sets the percent of the predictor traffic routed to the pool. With Overflow routing the request rate is
+// checked once the interval elapsed since the last check, the metrics client is nil when no metrics server is
+// configured. The latest ready revision of the pool is kept, it is reported by the predictor reconciler.
+func Route(isvc *v1beta1.InferenceService, metrics canary.MetricsClient, now time.Time) {
+	statusSpec, ok := isvc.Status.Components[v1beta1.PredictorComponent]
+	if !ok {
+		return
+	}
+	pool := isvc.Spec.Predictor.Pool
+	if pool == nil {
+		statusSpec.Pool = nil
+		isvc.Status.Components[v1beta1.PredictorComponent] = statusSpec
+		return
+	}
+	status := &v1beta1.PoolStatus{}
+	if statusSpec.Pool != nil {
+		status = statusSpec.Pool.DeepCopy()
+	}
+	statusSpec.Pool = status
+	isvc.Status.Components[v1beta1.PredictorComponent] = statusSpec
+	if pool.GetRouting() == v1beta1.WeightedPoolRouting {
+		status.TrafficPercent = pool.Weight
+		status.CheckTime = metav1.Time{}
+		status.RequestsPerSecond = ""
+		status.Message = ""
+		return
+	}
+	if !status.CheckTime.IsZero() && now.Sub(status.CheckTime.Time) < CheckInterval {
+		return
+	}
+	status.CheckTime = metav1.NewTime(now)
+	if metrics == nil {
+		status.TrafficPercent = 0
+		status.Message = "No metrics server is configured in the metrics config of the inferenceservice configmap"
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), canary.QueryTimeout)
+	defer cancel()
+	requestsPerSecond, err := metrics.Query(ctx, RequestRateQuery(isvc))
+	if err != nil {
+		// the last split applies until the request rate is available again
+		status.Message = err.Error()
+		return
+	}
+	status.Message = ""
+	status.RequestsPerSecond = strconv.FormatFloat(requestsPerSecond, 'g', -1, 64)
+	if percent := OverflowPercent(requestsPerSecond, pool.CapacityRequestsPerSecond); percent != status.TrafficPercent {
+		log.Info("Updating replica pool traffic", "namespace", isvc.Namespace, "name", isvc.Name,
+			"requestsPerSecond", status.RequestsPerSecond, "percent", percent)
+		status.TrafficPercent = percent
+	}
+}
+
+// NextCheck returns the delay until the next check of the request rate of the predictor, or zero if the predictor
+// has no pool with Overflow routing
+func NextCheck(isvc *v1beta1.InferenceService, now time.Time) time.Duration {
+	pool := isvc.Spec.Predictor.Pool
+	if pool == nil || pool.GetRouting() != v1beta1.OverflowPoolRouting {
+		return 0
+	}
+	status := isvc.Status.Components[v1beta1.PredictorComponent].Pool
+	if status == nil || status.CheckTime.IsZero() {
+		return 0
+	}
+	next := status.CheckTime.Add(CheckInterval).Sub(now)
+	if next < time.Second {
+		return time.Second
+	}
+	return next
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pool
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// fakeMetrics returns the value of the queries, or an error for the unknown ones
+type fakeMetrics map[string]float64
+
+func (m fakeMetrics) Query(ctx context.Context, query string) (float64, error) {
+	if value, ok := m[query]; ok {
+		return value, nil
+	}
+	return 0, fmt.Errorf("no data")
+}
+
+func newInferenceService(pool *v1beta1.ReplicaPoolSpec) *v1beta1.InferenceService {
+	return &v1beta1.InferenceService{
+		ObjectMeta: metav1.ObjectMeta{Name: "sklearn", Namespace: "default"},
+		Spec: v1beta1.InferenceServiceSpec{
+			Predictor: v1beta1.PredictorSpec{Pool: pool},
+		},
+		Status: v1beta1.InferenceServiceStatus{
+			Components: map[v1beta1.ComponentType]v1beta1.ComponentStatusSpec{
+				v1beta1.PredictorComponent: {},
+			},
+		},
+	}
+}
+
+func TestOverflowPercent(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	g.Expect(OverflowPercent(80, 100)).To(gomega.BeZero())
+	g.Expect(OverflowPercent(125, 100)).To(gomega.Equal(int64(20)))
+	g.Expect(OverflowPercent(301, 100)).To(gomega.Equal(int64(67)))
+}
+
+func TestRouteOverflow(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	now := time.Date(2020, 10, 1, 8, 0, 0, 0, time.UTC)
+	isvc := newInferenceService(&v1beta1.ReplicaPoolSpec{CapacityRequestsPerSecond: 100})
+	metrics := fakeMetrics{RequestRateQuery(isvc): 125}
+
+	Route(isvc, metrics, now)
+	status := isvc.Status.Components[v1beta1.PredictorComponent].Pool
+	g.Expect(status.TrafficPercent).To(gomega.Equal(int64(20)))
+	g.Expect(status.RequestsPerSecond).To(gomega.Equal("125"))
+	g.Expect(NextCheck(isvc, now)).To(gomega.Equal(CheckInterval))
+
+	// the split is not updated before the interval elapsed
+	metrics[RequestRateQuery(isvc)] = 50
+	Route(isvc, metrics, now.Add(time.Second))
+	g.Expect(isvc.Status.Components[v1beta1.PredictorComponent].Pool.TrafficPercent).To(gomega.Equal(int64(20)))
+
+	Route(isvc, metrics, now.Add(CheckInterval))
+	g.Expect(isvc.Status.Components[v1beta1.PredictorComponent].Pool.TrafficPercent).To(gomega.BeZero())
+
+	// the last split applies while the request rate is not available
+	metrics[RequestRateQuery(isvc)] = 200
+	Route(isvc, metrics, now.Add(2*CheckInterval))
+	Route(isvc, fakeMetrics{}, now.Add(3*CheckInterval))
+	status = isvc.Status.Components[v1beta1.PredictorComponent].Pool
+	g.Expect(status.TrafficPercent).To(gomega.Equal(int64(50)))
+	g.Expect(status.Message).To(gomega.Equal("no data"))
+}
+
+func TestRouteWeighted(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := newInferenceService(&v1beta1.ReplicaPoolSpec{Routing: v1beta1.WeightedPoolRouting, Weight: 30})
+	statusSpec := isvc.Status.Components[v1beta1.PredictorComponent]
+	statusSpec.Pool = &v1beta1.PoolStatus{LatestReadyRevision: "sklearn-predictor-pool-00001"}
+	isvc.Status.Components[v1beta1.PredictorComponent] = statusSpec
+
+	Route(isvc, nil, time.Now())
+	status := isvc.Status.Components[v1beta1.PredictorComponent].Pool
+	g.Expect(status.TrafficPercent).To(gomega.Equal(int64(30)))
+	g.Expect(status.LatestReadyRevision).To(gomega.Equal("sklearn-predictor-pool-00001"))
+	g.Expect(NextCheck(isvc, time.Now())).To(gomega.BeZero())
+
+	isvc.Spec.Predictor.Pool = nil
+	Route(isvc, nil, time.Now())
+	g.Expect(isvc.Status.Components[v1beta1.PredictorComponent].Pool).To(gomega.BeNil())
+}
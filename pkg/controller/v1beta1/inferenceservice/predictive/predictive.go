@@ -0,0 +1,191 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package predictive forecasts the request rate of the components from the same time of the previous periods of
+// their request history, so that the minimum number of replicas is raised ahead of the recurring traffic ramps
+// instead of cold starting the replicas at the peak onset. The knative reconciler applies the minimum replicas of
+// the forecast status.
+package predictive
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/canary"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+var log = logf.Log.WithName("PredictiveScaling")
+
+const (
+	// DefaultQuery is the request rate of the knative service of the component reported by the queue proxy
+	DefaultQuery = `sum(rate(revision_request_count{namespace_name="{{.Namespace}}",service_name="{{.Service}}"}[1m]))`
+	// ForecastInterval is the time between the forecasts of a component
+	ForecastInterval = 5 * time.Minute
+)
+
+// QueryParameters are the values of the request rate query template
+type QueryParameters struct {
+	Namespace string
+	Name      string
+	Component string
+	Service   string
+}
+
+func lookahead(scaling *v1beta1.PredictiveScalingSpec) time.Duration {
+	if scaling.LookaheadSeconds == 0 {
+		return v1beta1.DefaultPredictiveLookaheadSeconds * time.Second
+	}
+	return time.Duration(scaling.LookaheadSeconds) * time.Second
+}
+
+func period(scaling *v1beta1.PredictiveScalingSpec) time.Duration {
+	if scaling.PeriodSeconds == 0 {
+		return v1beta1.DefaultPredictivePeriodSeconds * time.Second
+	}
+	return time.Duration(scaling.PeriodSeconds) * time.Second
+}
+
+func periods(scaling *v1beta1.PredictiveScalingSpec) int {
+	if scaling.Periods == 0 {
+		return v1beta1.DefaultPredictivePeriods
+	}
+	return scaling.Periods
+}
+
+// serviceName returns the name of the knative service of the component
+func serviceName(name string, component v1beta1.ComponentType) string {
+	switch component {
+	case v1beta1.TransformerComponent:
+		return constants.DefaultTransformerServiceName(name)
+	case v1beta1.ExplainerComponent:
+		return constants.DefaultExplainerServiceName(name)
+	case v1beta1.DriftDetectorComponent:
+		return constants.DefaultDriftDetectorServiceName(name)
+	case v1beta1.OutlierDetectorComponent:
+		return constants.DefaultOutlierDetectorServiceName(name)
+	}
+	return constants.DefaultPredictorServiceName(name)
+}
+
+// peakQuery returns the query of the peak request rate of the lookahead window starting at the same time of the
+// previous k-th period
+func peakQuery(rate string, scaling *v1beta1.PredictiveScalingSpec, k int) string {
+	window := lookahead(scaling)
+	offset := time.Duration(k)*period(scaling) - window
+	return fmt.Sprintf("max_over_time((%s)[%ds:1m] offset %ds)", rate, int64(window.Seconds()), int64(offset.Seconds()))
+}
+
+// Forecast averages the peak request rates of the lookahead window over the previous periods and records the
+// minimum number of replicas serving it, once the forecast interval elapsed since the last forecast. The metrics
+// client is nil when no metrics server is configured.
+func Forecast(isvc *v1beta1.InferenceService, component v1beta1.ComponentType, extension *v1beta1.ComponentExtensionSpec,
+	metrics canary.MetricsClient, now time.Time) {
+	statusSpec, ok := isvc.Status.Components[component]
+	if !ok {
+		return
+	}
+	scaling := extension.PredictiveScaling
+	if scaling == nil {
+		statusSpec.PredictiveScaling = nil
+		isvc.Status.Components[component] = statusSpec
+		return
+	}
+	if status := statusSpec.PredictiveScaling; status != nil && now.Sub(status.ForecastTime.Time) < ForecastInterval {
+		return
+	}
+	status := &v1beta1.PredictiveScalingStatus{ForecastTime: metav1.NewTime(now)}
+	if previous := statusSpec.PredictiveScaling; previous != nil {
+		// the previous forecast applies until a period of the request history is available again
+		status.RequestsPerSecond = previous.RequestsPerSecond
+		status.MinReplicas = previous.MinReplicas
+	}
+	statusSpec.PredictiveScaling = status
+	isvc.Status.Components[component] = statusSpec
+	if metrics == nil {
+		status.Message = "No metrics server is configured in the metrics config of the inferenceservice configmap"
+		return
+	}
+	queryTemplate := scaling.Query
+	if queryTemplate == "" {
+		queryTemplate = DefaultQuery
+	}
+	rate, err := canary.RenderQuery(queryTemplate, QueryParameters{
+		Namespace: isvc.Namespace,
+		Name:      isvc.Name,
+		Component: string(component),
+		Service:   serviceName(isvc.Name, component),
+	})
+	if err != nil {
+		status.Message = err.Error()
+		return
+	}
+	// the queries of all the periods share the deadline
+	ctx, cancel := context.WithTimeout(context.Background(), canary.QueryTimeout)
+	defer cancel()
+	sum, count := 0.0, 0
+	for k := 1; k <= periods(scaling); k++ {
+		peak, err := metrics.Query(ctx, peakQuery(rate, scaling, k))
+		if err != nil {
+			// the request history does not cover the older periods yet
+			status.Message = err.Error()
+			continue
+		}
+		sum += peak
+		count++
+	}
+	if count == 0 {
+		return
+	}
+	status.Message = ""
+	forecast := sum / float64(count)
+	status.RequestsPerSecond = strconv.FormatFloat(forecast, 'g', -1, 64)
+	status.MinReplicas = int(math.Ceil(forecast / scaling.TargetRequestsPerSecond))
+	if extension.MaxReplicas != 0 && status.MinReplicas > extension.MaxReplicas {
+		status.MinReplicas = extension.MaxReplicas
+	}
+	log.Info("Forecast request rate", "namespace", isvc.Namespace, "name", isvc.Name, "component", component,
+		"requestsPerSecond", status.RequestsPerSecond, "minReplicas", status.MinReplicas)
+}
+
+// MinReplicas returns the minimum number of replicas of the component raised to the forecast minimum replicas
+func MinReplicas(extension *v1beta1.ComponentExtensionSpec, statusSpec v1beta1.ComponentStatusSpec, minReplicas int) int {
+	if extension.PredictiveScaling == nil || statusSpec.PredictiveScaling == nil ||
+		statusSpec.PredictiveScaling.MinReplicas <= minReplicas {
+		return minReplicas
+	}
+	return statusSpec.PredictiveScaling.MinReplicas
+}
+
+// NextForecast returns the delay until the next forecast of the component, or zero if the component has no
+// predictive scaling
+func NextForecast(extension *v1beta1.ComponentExtensionSpec, statusSpec v1beta1.ComponentStatusSpec, now time.Time) time.Duration {
+	status := statusSpec.PredictiveScaling
+	if extension.PredictiveScaling == nil || status == nil {
+		return 0
+	}
+	next := status.ForecastTime.Add(ForecastInterval).Sub(now)
+	if next < time.Second {
+		return time.Second
+	}
+	return next
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package predictive
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// fakeMetrics returns the value of the queries, or an error for the unknown ones
+type fakeMetrics map[string]float64
+
+func (m fakeMetrics) Query(ctx context.Context, query string) (float64, error) {
+	if value, ok := m[query]; ok {
+		return value, nil
+	}
+	return 0, fmt.Errorf("no data")
+}
+
+func newInferenceService() *v1beta1.InferenceService {
+	return &v1beta1.InferenceService{
+		ObjectMeta: metav1.ObjectMeta{Name: "sklearn", Namespace: "default"},
+		Spec: v1beta1.InferenceServiceSpec{
+			Predictor: v1beta1.PredictorSpec{
+				ComponentExtensionSpec: v1beta1.ComponentExtensionSpec{
+					MaxReplicas: 8,
+					PredictiveScaling: &v1beta1.PredictiveScalingSpec{
+						TargetRequestsPerSecond: 10,
+						Query:                   `rate{service="{{.Service}}"}`,
+						LookaheadSeconds:        600,
+						Periods:                 3,
+					},
+				},
+			},
+		},
+		Status: v1beta1.InferenceServiceStatus{
+			Components: map[v1beta1.ComponentType]v1beta1.ComponentStatusSpec{
+				v1beta1.PredictorComponent: {},
+			},
+		},
+	}
+}
+
+func TestForecast(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	now := time.Date(2020, 10, 1, 8, 0, 0, 0, time.UTC)
+	isvc := newInferenceService()
+	extension := &isvc.Spec.Predictor.ComponentExtensionSpec
+	rate := `rate{service="sklearn-predictor-default"}`
+	// the third day is not in the request history yet
+	metrics := fakeMetrics{
+		fmt.Sprintf("max_over_time((%s)[600s:1m] offset 85800s)", rate):  40,
+		fmt.Sprintf("max_over_time((%s)[600s:1m] offset 172200s)", rate): 30,
+	}
+
+	Forecast(isvc, v1beta1.PredictorComponent, extension, metrics, now)
+	status := isvc.Status.Components[v1beta1.PredictorComponent]
+	g.Expect(status.PredictiveScaling.RequestsPerSecond).To(gomega.Equal("35"))
+	g.Expect(status.PredictiveScaling.MinReplicas).To(gomega.Equal(4))
+	g.Expect(status.PredictiveScaling.Message).To(gomega.BeEmpty())
+	g.Expect(MinReplicas(extension, status, 1)).To(gomega.Equal(4))
+	g.Expect(MinReplicas(extension, status, 6)).To(gomega.Equal(6))
+	g.Expect(NextForecast(extension, status, now)).To(gomega.Equal(ForecastInterval))
+
+	// the forecast is not refreshed before the interval elapsed
+	metrics[fmt.Sprintf("max_over_time((%s)[600s:1m] offset 85800s)", rate)] = 200
+	Forecast(isvc, v1beta1.PredictorComponent, extension, metrics, now.Add(time.Minute))
+	g.Expect(isvc.Status.Components[v1beta1.PredictorComponent].PredictiveScaling.MinReplicas).To(gomega.Equal(4))
+
+	// the minimum replicas are capped by the maximum replicas
+	Forecast(isvc, v1beta1.PredictorComponent, extension, metrics, now.Add(ForecastInterval))
+	g.Expect(isvc.Status.Components[v1beta1.PredictorComponent].PredictiveScaling.MinReplicas).To(gomega.Equal(8))
+
+	// the last forecast applies while the request history is not available
+	Forecast(isvc, v1beta1.PredictorComponent, extension, fakeMetrics{}, now.Add(2*ForecastInterval))
+	status = isvc.Status.Components[v1beta1.PredictorComponent]
+	g.Expect(status.PredictiveScaling.MinReplicas).To(gomega.Equal(8))
+	g.Expect(status.PredictiveScaling.Message).To(gomega.Equal("no data"))
+
+	extension.PredictiveScaling = nil
+	Forecast(isvc, v1beta1.PredictorComponent, extension, metrics, now.Add(3*ForecastInterval))
+	status = isvc.Status.Components[v1beta1.PredictorComponent]
+	g.Expect(status.PredictiveScaling).To(gomega.BeNil())
+	g.Expect(MinReplicas(extension, status, 1)).To(gomega.Equal(1))
+	g.Expect(NextForecast(extension, status, now)).To(gomega.BeZero())
+}
+
+func TestForecastWithoutMetricsServer(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := newInferenceService()
+	Forecast(isvc, v1beta1.PredictorComponent, &isvc.Spec.Predictor.ComponentExtensionSpec, nil, time.Now())
+	status := isvc.Status.Components[v1beta1.PredictorComponent].PredictiveScaling
+	g.Expect(status.MinReplicas).To(gomega.BeZero())
+	g.Expect(status.Message).To(gomega.ContainSubstring("No metrics server"))
+}
@@ -0,0 +1,210 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package quota enforces the InferenceQuotas of a namespace. The InferenceServices are admitted in the order they
+// were created while the capacity they use stays within the limits of the quotas, the InferenceServices which would
+// exceed a limit are kept pending.
+package quota
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1alpha1"
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/kubeflow/kfserving/pkg/utils"
+	v1 "k8s.io/api/core/v1"
+)
+
+// gpus returns the number of GPUs of the container resources, the limits are read first as the GPUs can not be
+// overcommitted
+func gpus(requirements v1.ResourceRequirements) int64 {
+	count := int64(0)
+	for name, quantity := range requirements.Limits {
+		if utils.IsGPUResource(name) {
+			count += quantity.Value()
+		}
+	}
+	for name, quantity := range requirements.Requests {
+		if _, ok := requirements.Limits[name]; !ok && utils.IsGPUResource(name) {
+			count += quantity.Value()
+		}
+	}
+	return count
+}
+
+// replicas returns the maximum replicas, or the minimum replicas when there is no maximum
+func replicas(minReplicas *int, maxReplicas int) int {
+	if maxReplicas != 0 {
+		return maxReplicas
+	}
+	if minReplicas != nil {
+		return *minReplicas
+	}
+	return constants.DefaultMinReplicas
+}
+
+// Footprint returns the capacity the InferenceService uses. A component counts its maximum replicas, or its minimum
+// replicas when it has no maximum, the replica pool and the model versions of the predictor are counted the same way.
+func Footprint(isvc *v1beta1.InferenceService) v1alpha1.QuotaUsage {
+	usage := v1alpha1.QuotaUsage{InferenceServices: 1}
+	if pool := isvc.Spec.Predictor.Pool; pool != nil {
+		poolReplicas := replicas(pool.MinReplicas, pool.MaxReplicas)
+		usage.Replicas += int32(poolReplicas)
+		usage.GPUs += gpus(pool.Resources) * int64(poolReplicas)
+	}
+	for _, component := range []v1beta1.Component{
+		&isvc.Spec.Predictor,
+		isvc.Spec.Transformer,
+		isvc.Spec.Explainer,
+		isvc.Spec.DriftDetector,
+		isvc.Spec.OutlierDetector,
+	} {
+		if reflect.ValueOf(component).IsNil() {
+			continue
+		}
+		extension := component.GetExtensions()
+		componentReplicas := replicas(extension.MinReplicas, extension.MaxReplicas)
+		usage.Replicas += int32(componentReplicas)
+		for _, implementation := range component.GetImplementations() {
+			for _, requirements := range v1beta1.GetResourceRequirements(implementation) {
+				usage.GPUs += gpus(requirements) * int64(componentReplicas)
+			}
+		}
+	}
+	// the model versions run the predictor with the replicas of the predictor unless they set their own
+	for _, version := range isvc.Spec.Predictor.Versions {
+		minReplicas, maxReplicas := version.MinReplicas, version.MaxReplicas
+		if minReplicas == nil {
+			minReplicas = isvc.Spec.Predictor.MinReplicas
+		}
+		if maxReplicas == 0 {
+			maxReplicas = isvc.Spec.Predictor.MaxReplicas
+		}
+		versionReplicas := replicas(minReplicas, maxReplicas)
+		usage.Replicas += int32(versionReplicas)
+		for _, implementation := range isvc.Spec.Predictor.GetImplementations() {
+			for _, requirements := range v1beta1.GetResourceRequirements(implementation) {
+				usage.GPUs += gpus(requirements) * int64(versionReplicas)
+			}
+		}
+	}
+	return usage
+}
+
+// IsAdmitted returns true if the InferenceService was reconciled without waiting for quota, it keeps running when
+// the quotas change. The InferenceServices reconciled before the admitted generation was recorded are admitted
+// until they wait for quota.
+func IsAdmitted(isvc *v1beta1.InferenceService) bool {
+	if isvc.Status.AdmittedGeneration != 0 {
+		return true
+	}
+	return len(isvc.Status.Components) != 0 && isvc.Status.GetCondition(v1beta1.Pending) == nil
+}
+
+// isCurrent returns true if the spec of the admitted InferenceService has not changed since it was admitted
+func isCurrent(isvc *v1beta1.InferenceService) bool {
+	return IsAdmitted(isvc) && isvc.Status.AdmittedGeneration == isvc.Generation
+}
+
+// queue returns the InferenceServices in the order they are admitted, the admitted InferenceServices first and then
+// the pending InferenceServices from the oldest
+func queue(isvcs []v1beta1.InferenceService) []*v1beta1.InferenceService {
+	queued := []*v1beta1.InferenceService{}
+	for i := range isvcs {
+		if isvcs[i].DeletionTimestamp.IsZero() {
+			queued = append(queued, &isvcs[i])
+		}
+	}
+	sort.SliceStable(queued, func(i, j int) bool {
+		if admitted := IsAdmitted(queued[i]); admitted != IsAdmitted(queued[j]) {
+			return admitted
+		}
+		if !queued[i].CreationTimestamp.Equal(&queued[j].CreationTimestamp) {
+			return queued[i].CreationTimestamp.Before(&queued[j].CreationTimestamp)
+		}
+		return queued[i].Name < queued[j].Name
+	})
+	return queued
+}
+
+func add(usage *v1alpha1.QuotaUsage, footprint v1alpha1.QuotaUsage) {
+	usage.InferenceServices += footprint.InferenceServices
+	usage.GPUs += footprint.GPUs
+	usage.Replicas += footprint.Replicas
+}
+
+// exceeded returns the limit of the quota the usage exceeds, or an empty string
+func exceeded(quota *v1alpha1.InferenceQuota, usage v1alpha1.QuotaUsage) string {
+	spec := quota.Spec
+	switch {
+	case spec.MaxInferenceServices != nil && usage.InferenceServices > *spec.MaxInferenceServices:
+		return fmt.Sprintf("InferenceQuota %s allows %d InferenceServices", quota.Name, *spec.MaxInferenceServices)
+	case spec.MaxGPUs != nil && usage.GPUs > *spec.MaxGPUs:
+		return fmt.Sprintf("InferenceQuota %s allows %d GPUs", quota.Name, *spec.MaxGPUs)
+	case spec.MaxReplicas != nil && usage.Replicas > *spec.MaxReplicas:
+		return fmt.Sprintf("InferenceQuota %s allows %d replicas", quota.Name, *spec.MaxReplicas)
+	}
+	return ""
+}
+
+// Admit returns an empty message if the InferenceService can be reconciled, otherwise the message names the limit
+// it waits for. The InferenceServices ahead of it in the queue are counted, so that a pending InferenceService is not
+// overtaken by the InferenceServices created after it. The spec of an admitted InferenceService is checked again when
+// it changes, with the capacity of all the other admitted InferenceServices, as the update may request more capacity.
+func Admit(isvc *v1beta1.InferenceService, quotas []v1alpha1.InferenceQuota, isvcs []v1beta1.InferenceService) string {
+	if len(quotas) == 0 || isCurrent(isvc) {
+		return ""
+	}
+	usage := Footprint(isvc)
+	admitted := IsAdmitted(isvc)
+	for _, queued := range queue(isvcs) {
+		if queued.Name == isvc.Name {
+			if !admitted {
+				break
+			}
+			continue
+		}
+		// the admitted InferenceServices are queued first
+		if admitted && !IsAdmitted(queued) {
+			break
+		}
+		add(&usage, Footprint(queued))
+	}
+	for i := range quotas {
+		if message := exceeded(&quotas[i], usage); message != "" {
+			return message
+		}
+	}
+	return ""
+}
+
+// Usage returns the capacity used by the admitted InferenceServices and the names of the pending InferenceServices
+// in the order they are admitted
+func Usage(isvcs []v1beta1.InferenceService) (v1alpha1.QuotaUsage, []string) {
+	usage := v1alpha1.QuotaUsage{}
+	pending := []string{}
+	for _, queued := range queue(isvcs) {
+		if IsAdmitted(queued) {
+			add(&usage, Footprint(queued))
+		} else {
+			pending = append(pending, queued.Name)
+		}
+	}
+	return usage, pending
+}
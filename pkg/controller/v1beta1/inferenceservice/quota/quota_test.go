@@ -0,0 +1,136 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package quota
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1alpha1"
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+)
+
+var created = time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC)
+
+func newInferenceService(name string, age time.Duration, gpus string, maxReplicas int, admitted bool) v1beta1.InferenceService {
+	isvc := v1beta1.InferenceService{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "team",
+			CreationTimestamp: metav1.NewTime(created.Add(-age)),
+		},
+		Spec: v1beta1.InferenceServiceSpec{
+			Predictor: v1beta1.PredictorSpec{
+				ComponentExtensionSpec: v1beta1.ComponentExtensionSpec{MaxReplicas: maxReplicas},
+				Tensorflow: &v1beta1.TFServingSpec{
+					PredictorExtensionSpec: v1beta1.PredictorExtensionSpec{
+						StorageURI: proto.String("gs://models/flowers"),
+						Container: v1.Container{
+							Resources: v1.ResourceRequirements{
+								Limits: v1.ResourceList{"nvidia.com/gpu": resource.MustParse(gpus)},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	if admitted {
+		isvc.Status.Components = map[v1beta1.ComponentType]v1beta1.ComponentStatusSpec{
+			v1beta1.PredictorComponent: {},
+		}
+	}
+	return isvc
+}
+
+func TestFootprint(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := newInferenceService("flowers", 0, "2", 3, false)
+	isvc.Spec.Transformer = &v1beta1.TransformerSpec{
+		PodSpec: v1beta1.PodSpec{Containers: []v1.Container{{Image: "transformer:0.1.0"}}},
+	}
+	g.Expect(Footprint(&isvc)).To(gomega.Equal(v1alpha1.QuotaUsage{InferenceServices: 1, GPUs: 6, Replicas: 4}))
+
+	isvc.Spec.Transformer = nil
+	isvc.Spec.Predictor.Pool = &v1beta1.ReplicaPoolSpec{MaxReplicas: 5}
+	g.Expect(Footprint(&isvc)).To(gomega.Equal(v1alpha1.QuotaUsage{InferenceServices: 1, GPUs: 6, Replicas: 8}))
+
+	isvc.Spec.Predictor.Pool = nil
+	isvc.Spec.Predictor.Versions = []v1beta1.ModelVersionSpec{{Name: "v1"}, {Name: "v2", MaxReplicas: 1}}
+	g.Expect(Footprint(&isvc)).To(gomega.Equal(v1alpha1.QuotaUsage{InferenceServices: 1, GPUs: 14, Replicas: 7}))
+}
+
+func TestAdmit(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	maxGPUs := int64(4)
+	quotas := []v1alpha1.InferenceQuota{{
+		ObjectMeta: metav1.ObjectMeta{Name: "team", Namespace: "team"},
+		Spec:       v1alpha1.InferenceQuotaSpec{MaxGPUs: &maxGPUs},
+	}}
+	running := newInferenceService("running", 3*time.Hour, "1", 2, true)
+	large := newInferenceService("large", 2*time.Hour, "1", 3, false)
+	small := newInferenceService("small", time.Hour, "1", 1, false)
+	isvcs := []v1beta1.InferenceService{small, large, running}
+
+	g.Expect(Admit(&running, quotas, isvcs)).To(gomega.BeEmpty())
+	g.Expect(Admit(&large, quotas, isvcs)).To(gomega.Equal("InferenceQuota team allows 4 GPUs"))
+	// the small InferenceService would fit but waits for the large one created before it
+	g.Expect(Admit(&small, quotas, isvcs)).To(gomega.Equal("InferenceQuota team allows 4 GPUs"))
+	g.Expect(Admit(&large, nil, isvcs)).To(gomega.BeEmpty())
+
+	usage, pending := Usage(isvcs)
+	g.Expect(usage).To(gomega.Equal(v1alpha1.QuotaUsage{InferenceServices: 1, GPUs: 2, Replicas: 2}))
+	g.Expect(pending).To(gomega.Equal([]string{"large", "small"}))
+
+	maxGPUs = 5
+	g.Expect(Admit(&large, quotas, isvcs)).To(gomega.BeEmpty())
+}
+
+func TestAdmitUpdate(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	maxGPUs := int64(4)
+	quotas := []v1alpha1.InferenceQuota{{
+		ObjectMeta: metav1.ObjectMeta{Name: "team", Namespace: "team"},
+		Spec:       v1alpha1.InferenceQuotaSpec{MaxGPUs: &maxGPUs},
+	}}
+	running := newInferenceService("running", 3*time.Hour, "1", 2, true)
+	running.Generation, running.Status.AdmittedGeneration = 1, 1
+	updated := newInferenceService("updated", 2*time.Hour, "1", 1, true)
+	updated.Generation, updated.Status.AdmittedGeneration = 1, 1
+	pending := newInferenceService("pending", time.Hour, "1", 1, false)
+	isvcs := []v1beta1.InferenceService{pending, updated, running}
+
+	// the admitted spec is not checked again
+	updated.Spec.Predictor.MaxReplicas = 3
+	g.Expect(Admit(&updated, quotas, isvcs)).To(gomega.BeEmpty())
+
+	// the changed spec is checked with the other admitted InferenceServices, the pending ones are not counted
+	updated.Generation = 2
+	g.Expect(Admit(&updated, quotas, isvcs)).To(gomega.Equal("InferenceQuota team allows 4 GPUs"))
+	updated.Spec.Predictor.MaxReplicas = 2
+	g.Expect(Admit(&updated, quotas, isvcs)).To(gomega.BeEmpty())
+
+	// an admitted InferenceService waiting for quota for its update stays admitted
+	updated.Status.SetCondition(v1beta1.Pending, &apis.Condition{Status: v1.ConditionTrue})
+	g.Expect(IsAdmitted(&updated)).To(gomega.BeTrue())
+}
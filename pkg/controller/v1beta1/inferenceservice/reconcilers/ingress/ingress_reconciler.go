@@ -19,8 +19,12 @@ package ingress
 import (
 	"context"
 	"fmt"
+	"time"
+
+	gogotypes "github.com/gogo/protobuf/types"
 	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
 	"github.com/kubeflow/kfserving/pkg/constants"
+	v1beta1utils "github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/utils"
 	"github.com/pkg/errors"
 	istiov1alpha3 "istio.io/api/networking/v1alpha3"
 	"istio.io/client-go/pkg/apis/networking/v1alpha3"
@@ -49,13 +53,16 @@ type IngressReconciler struct {
 	client        client.Client
 	scheme        *runtime.Scheme
 	ingressConfig *v1beta1.IngressConfig
+	driftPolicy   v1beta1.DriftPolicy
 }
 
-func NewIngressReconciler(client client.Client, scheme *runtime.Scheme, ingressConfig *v1beta1.IngressConfig) *IngressReconciler {
+func NewIngressReconciler(client client.Client, scheme *runtime.Scheme, ingressConfig *v1beta1.IngressConfig,
+	driftPolicy v1beta1.DriftPolicy) *IngressReconciler {
 	return &IngressReconciler{
 		client:        client,
 		scheme:        scheme,
 		ingressConfig: ingressConfig,
+		driftPolicy:   driftPolicy,
 	}
 }
 
@@ -109,7 +116,8 @@ func getServiceUrl(isvc *v1beta1.InferenceService) string {
 	}
 }
 
-func (r *IngressReconciler) reconcileExternalService(isvc *v1beta1.InferenceService) error {
+// createExternalService returns the external name service which points to the local gateway
+func (r *IngressReconciler) createExternalService(isvc *v1beta1.InferenceService) (*corev1.Service, error) {
 	desired := &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      isvc.Name,
@@ -122,12 +130,20 @@ func (r *IngressReconciler) reconcileExternalService(isvc *v1beta1.InferenceServ
 		},
 	}
 	if err := controllerutil.SetControllerReference(isvc, desired, r.scheme); err != nil {
+		return nil, err
+	}
+	return desired, nil
+}
+
+func (r *IngressReconciler) reconcileExternalService(isvc *v1beta1.InferenceService) error {
+	desired, err := r.createExternalService(isvc)
+	if err != nil {
 		return err
 	}
 
 	// Create service if does not exist
 	existing := &corev1.Service{}
-	err := r.client.Get(context.TODO(), types.NamespacedName{Name: desired.Name, Namespace: desired.Namespace}, existing)
+	err = r.client.Get(context.TODO(), types.NamespacedName{Name: desired.Name, Namespace: desired.Namespace}, existing)
 	if err != nil {
 		if apierr.IsNotFound(err) {
 			log.Info("Creating external name service", "namespace", desired.Namespace, "name", desired.Name)
@@ -213,33 +229,96 @@ func (ir *IngressReconciler) createHTTPMatchRequest(prefix, targetHost, internal
 	return matchRequests
 }
 
-func (ir *IngressReconciler) Reconcile(isvc *v1beta1.InferenceService) error {
-	if !isvc.Status.IsConditionReady(v1beta1.PredictorReady) {
-		isvc.Status.SetCondition(v1beta1.IngressReady, &apis.Condition{
-			Type:   v1beta1.IngressReady,
-			Status: corev1.ConditionFalse,
-			Reason: "Predictor ingress not created",
-		})
+// createHTTPRetry returns the retries of the routes, the requests are not retried when the InferenceService has no
+// retry policy
+func createHTTPRetry(isvc *v1beta1.InferenceService) *istiov1alpha3.HTTPRetry {
+	if isvc.Spec.Routing == nil || isvc.Spec.Routing.Retries == nil {
 		return nil
 	}
-	serviceHost := getServiceHost(isvc)
-	serviceUrl := getServiceUrl(isvc)
-	if serviceHost == "" || serviceUrl == "" {
-		return nil
+	retries := isvc.Spec.Routing.Retries
+	retry := &istiov1alpha3.HTTPRetry{
+		Attempts: retries.Attempts,
+		RetryOn:  retries.RetryOn,
 	}
-	backend := constants.DefaultPredictorServiceName(isvc.Name)
+	if retry.RetryOn == "" {
+		retry.RetryOn = v1beta1.DefaultRetryOn
+	}
+	if retries.PerTryTimeoutSeconds > 0 {
+		retry.PerTryTimeout = gogotypes.DurationProto(time.Duration(retries.PerTryTimeoutSeconds) * time.Second)
+	}
+	return retry
+}
+
+// notReadyReason returns the reason the ingress can not route to the components, or an empty string when all the
+// components are ready
+func notReadyReason(isvc *v1beta1.InferenceService) string {
+	if !isvc.Status.IsConditionReady(v1beta1.PredictorReady) {
+		return "Predictor ingress not created"
+	}
+	if isvc.Spec.Transformer != nil && !isvc.Status.IsConditionReady(v1beta1.TransformerReady) {
+		return "Transformer ingress not created"
+	}
+	if isvc.Spec.Explainer != nil && !isvc.Status.IsConditionReady(v1beta1.ExplainerReady) {
+		return "Explainer ingress not created"
+	}
+	return ""
+}
 
+// poolTrafficPercent returns the percent of the predictor traffic routed to its replica pool, the traffic is not
+// routed to the pool until it has a ready revision
+func poolTrafficPercent(isvc *v1beta1.InferenceService) int64 {
+	if isvc.Spec.Predictor.Pool == nil || isvc.Spec.Transformer != nil {
+		return 0
+	}
+	status := isvc.Status.Components[v1beta1.PredictorComponent].Pool
+	if status == nil || status.LatestReadyRevision == "" {
+		return 0
+	}
+	return status.TrafficPercent
+}
+
+// defaultVersionBackend returns the knative service of the default model version of the predictor, the requests
+// which do not name a version are routed to the predictor until the default version has a ready revision
+func defaultVersionBackend(isvc *v1beta1.InferenceService) string {
+	if isvc.Spec.Predictor.DefaultVersion == "" {
+		return ""
+	}
+	for _, version := range isvc.Status.Components[v1beta1.PredictorComponent].Versions {
+		if version.Name == isvc.Spec.Predictor.DefaultVersion && version.LatestReadyRevision != "" {
+			return constants.PredictorVersionServiceName(isvc.Name, version.Name)
+		}
+	}
+	return ""
+}
+
+// setVersionURLs reports the urls of the model versions of the predictor under the InferenceService url
+func setVersionURLs(isvc *v1beta1.InferenceService, url *apis.URL) {
+	statusSpec, ok := isvc.Status.Components[v1beta1.PredictorComponent]
+	if !ok {
+		return
+	}
+	for i := range statusSpec.Versions {
+		versionURL := *url
+		versionURL.Path = constants.ModelVersionPath(statusSpec.Versions[i].Name)
+		statusSpec.Versions[i].URL = &versionURL
+	}
+	isvc.Status.Components[v1beta1.PredictorComponent] = statusSpec
+}
+
+// createIngress returns the virtual service which routes the InferenceService host to its components, or to the
+// fallback InferenceService when fallback is true
+func (ir *IngressReconciler) createIngress(isvc *v1beta1.InferenceService, serviceHost string, fallback bool) (*v1alpha3.VirtualService, error) {
+	backend := constants.DefaultPredictorServiceName(isvc.Name)
 	if isvc.Spec.Transformer != nil {
 		backend = constants.DefaultTransformerServiceName(isvc.Name)
-		if !isvc.Status.IsConditionReady(v1beta1.TransformerReady) {
-			isvc.Status.SetCondition(v1beta1.IngressReady, &apis.Condition{
-				Type:   v1beta1.IngressReady,
-				Status: corev1.ConditionFalse,
-				Reason: "Transformer ingress not created",
-			})
-			return nil
-		}
 	}
+	if version := defaultVersionBackend(isvc); version != "" {
+		backend = version
+	}
+	if fallback {
+		backend = isvc.Spec.Routing.Fallback
+	}
+	retries := createHTTPRetry(isvc)
 	isInternal := false
 	//if service is labelled with cluster local or knative domain is configured as internal
 	if val, ok := isvc.Labels[constants.VisibilityLabel]; ok && val == "ClusterLocal" {
@@ -250,43 +329,59 @@ func (ir *IngressReconciler) Reconcile(isvc *v1beta1.InferenceService) error {
 		isInternal = true
 	}
 	httpRoutes := []*istiov1alpha3.HTTPRoute{}
-	// Build explain route
-	if isvc.Spec.Explainer != nil {
-		if !isvc.Status.IsConditionReady(v1beta1.ExplainerReady) {
-			isvc.Status.SetCondition(v1beta1.IngressReady, &apis.Condition{
-				Type:   v1beta1.IngressReady,
-				Status: corev1.ConditionFalse,
-				Reason: "Explainer ingress not created",
-			})
-			return nil
-		}
+	// Build explain route, the explain requests of the fallback go to the fallback InferenceService
+	if isvc.Spec.Explainer != nil && !fallback {
 		explainerRouter := istiov1alpha3.HTTPRoute{
 			Match: ir.createHTTPMatchRequest(constants.ExplainPrefix(), serviceHost,
 				network.GetServiceHostname(isvc.Name, isvc.Namespace), isInternal),
 			Route: []*istiov1alpha3.HTTPRouteDestination{
 				ir.createHTTPRouteDestination(constants.DefaultExplainerServiceName(isvc.Name), isvc.Namespace, constants.LocalGatewayHost),
 			},
+			Retries: retries,
 		}
 		httpRoutes = append(httpRoutes, &explainerRouter)
 	}
-	// Add predict route
+	// Add the routes of the model versions of the predictor, the version prefix is removed from the path
+	for _, version := range isvc.Spec.Predictor.Versions {
+		match := ir.createHTTPMatchRequest("", serviceHost, network.GetServiceHostname(isvc.Name, isvc.Namespace), isInternal)
+		for _, request := range match {
+			request.Uri = &istiov1alpha3.StringMatch{
+				MatchType: &istiov1alpha3.StringMatch_Prefix{Prefix: constants.ModelVersionPath(version.Name) + "/"},
+			}
+		}
+		httpRoutes = append(httpRoutes, &istiov1alpha3.HTTPRoute{
+			Match:   match,
+			Rewrite: &istiov1alpha3.HTTPRewrite{Uri: "/"},
+			Route: []*istiov1alpha3.HTTPRouteDestination{
+				ir.createHTTPRouteDestination(constants.PredictorVersionServiceName(isvc.Name, version.Name), isvc.Namespace,
+					constants.LocalGatewayHost),
+			},
+			Retries: retries,
+		})
+	}
+	// Add predict route, the predictor traffic is split with its replica pool
+	predictDestinations := []*istiov1alpha3.HTTPRouteDestination{
+		ir.createHTTPRouteDestination(backend, isvc.Namespace, constants.LocalGatewayHost),
+	}
+	if percent := poolTrafficPercent(isvc); percent > 0 && !fallback {
+		predictDestinations[0].Weight = int32(100 - percent)
+		poolDestination := ir.createHTTPRouteDestination(constants.PredictorPoolServiceName(isvc.Name), isvc.Namespace,
+			constants.LocalGatewayHost)
+		poolDestination.Weight = int32(percent)
+		predictDestinations = append(predictDestinations, poolDestination)
+	}
 	httpRoutes = append(httpRoutes, &istiov1alpha3.HTTPRoute{
 		Match: ir.createHTTPMatchRequest("", serviceHost,
 			network.GetServiceHostname(isvc.Name, isvc.Namespace), isInternal),
-		Route: []*istiov1alpha3.HTTPRouteDestination{
-			ir.createHTTPRouteDestination(backend, isvc.Namespace, constants.LocalGatewayHost),
-		},
+		Route:   predictDestinations,
+		Retries: retries,
 	})
 
-	//Create external service which points to local gateway
-	if err := ir.reconcileExternalService(isvc); err != nil {
-		return errors.Wrapf(err, "fails to reconcile external name service")
-	}
-	//Create ingress
 	desiredIngress := &v1alpha3.VirtualService{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      isvc.Name,
-			Namespace: isvc.Namespace,
+			Name:        isvc.Name,
+			Namespace:   isvc.Namespace,
+			Annotations: map[string]string{},
 		},
 		Spec: istiov1alpha3.VirtualService{
 			Hosts: []string{
@@ -300,23 +395,104 @@ func (ir *IngressReconciler) Reconcile(isvc *v1beta1.InferenceService) error {
 			Http: httpRoutes,
 		},
 	}
+	desiredIngress.Annotations[constants.DesiredSpecHashInternalAnnotationKey] = v1beta1utils.HashSpec(&desiredIngress.Spec)
 	if err := controllerutil.SetControllerReference(isvc, desiredIngress, ir.scheme); err != nil {
-		return errors.Wrapf(err, "fails to set owner reference for ingress")
+		return nil, errors.Wrapf(err, "fails to set owner reference for ingress")
+	}
+	return desiredIngress, nil
+}
+
+// Render returns the external name service, the virtual service and the session affinity destination rules of the
+// InferenceService without applying them.
+// The host is derived from the component urls when the InferenceService has been reconciled, otherwise from the given domain.
+func (ir *IngressReconciler) Render(isvc *v1beta1.InferenceService, domain string) ([]runtime.Object, error) {
+	serviceHost := getServiceHost(isvc)
+	if serviceHost == "" {
+		if val, ok := isvc.Labels[constants.VisibilityLabel]; ok && val == "ClusterLocal" {
+			serviceHost = network.GetServiceHostname(isvc.Name, isvc.Namespace)
+		} else {
+			serviceHost = fmt.Sprintf("%s.%s.%s", isvc.Name, isvc.Namespace, domain)
+		}
+	}
+	externalService, err := ir.createExternalService(isvc)
+	if err != nil {
+		return nil, err
+	}
+	desiredIngress, err := ir.createIngress(isvc, serviceHost, false)
+	if err != nil {
+		return nil, err
+	}
+	objects := []runtime.Object{externalService, desiredIngress}
+	rules, err := ir.createDestinationRules(isvc)
+	if err != nil {
+		return nil, err
+	}
+	for _, rule := range rules {
+		objects = append(objects, rule)
+	}
+	return objects, nil
+}
+
+// Reconcile routes the InferenceService host to its components once they are ready. While they are not ready the
+// host is routed to the fallback InferenceService if there is one, and the ingress is kept not ready.
+func (ir *IngressReconciler) Reconcile(isvc *v1beta1.InferenceService) error {
+	if err := ir.reconcileSessionAffinity(isvc); err != nil {
+		return err
+	}
+	reason := notReadyReason(isvc)
+	serviceHost := getServiceHost(isvc)
+	serviceUrl := getServiceUrl(isvc)
+	fallback := reason != "" && isvc.Spec.Routing != nil && isvc.Spec.Routing.Fallback != ""
+	if reason != "" && (!fallback || serviceHost == "" || serviceUrl == "") {
+		isvc.Status.SetCondition(v1beta1.IngressReady, &apis.Condition{
+			Type:   v1beta1.IngressReady,
+			Status: corev1.ConditionFalse,
+			Reason: reason,
+		})
+		return nil
+	}
+	if serviceHost == "" || serviceUrl == "" {
+		return nil
+	}
+	//Create external service which points to local gateway
+	if err := ir.reconcileExternalService(isvc); err != nil {
+		return errors.Wrapf(err, "fails to reconcile external name service")
+	}
+	//Create ingress
+	desiredIngress, err := ir.createIngress(isvc, serviceHost, fallback)
+	if err != nil {
+		return err
 	}
 
 	existing := &v1alpha3.VirtualService{}
-	err := ir.client.Get(context.TODO(), types.NamespacedName{Name: desiredIngress.Name, Namespace: desiredIngress.Namespace}, existing)
+	err = ir.client.Get(context.TODO(), types.NamespacedName{Name: desiredIngress.Name, Namespace: desiredIngress.Namespace}, existing)
 	if err != nil {
 		if apierr.IsNotFound(err) {
 			log.Info("Creating Ingress for isvc", "namespace", desiredIngress.Namespace, "name", desiredIngress.Name)
 			err = ir.client.Create(context.TODO(), desiredIngress)
 		}
 	} else {
-		if !equality.Semantic.DeepEqual(desiredIngress.Spec, existing.Spec) {
+		desiredHash := desiredIngress.Annotations[constants.DesiredSpecHashInternalAnnotationKey]
+		observedHash := existing.Annotations[constants.DesiredSpecHashInternalAnnotationKey]
+		// Only the fields set by the controller are compared, the desired spec hash tells when the controller unsets a field
+		specEquals := v1beta1utils.FieldsMatch(desiredIngress.Spec, existing.Spec)
+		// The desired spec has not changed since the last update, so the differences are out of band changes
+		drifted := desiredHash == observedHash && !specEquals
+		if drifted && ir.driftPolicy == v1beta1.DriftPolicyReport {
+			log.Info("Keeping out of band changes to Ingress for isvc", "namespace", desiredIngress.Namespace, "name", desiredIngress.Name)
+		} else if !specEquals || desiredHash != observedHash {
+			if drifted {
+				log.Info("Reverting out of band changes to Ingress for isvc", "namespace", desiredIngress.Namespace, "name", desiredIngress.Name)
+			}
 			existing.Spec = desiredIngress.Spec
+			if existing.Annotations == nil {
+				existing.Annotations = map[string]string{}
+			}
+			existing.Annotations[constants.DesiredSpecHashInternalAnnotationKey] = desiredHash
 			log.Info("Update Ingress for isvc", "namespace", desiredIngress.Namespace, "name", desiredIngress.Name)
 			err = ir.client.Update(context.TODO(), existing)
 		}
+		isvc.Status.PropagateDrift("virtual service "+existing.Name, drifted && ir.driftPolicy == v1beta1.DriftPolicyReport)
 	}
 	if err != nil {
 		return errors.Wrapf(err, "fails to create or update ingress")
@@ -324,12 +500,23 @@ func (ir *IngressReconciler) Reconcile(isvc *v1beta1.InferenceService) error {
 
 	if url, err := apis.ParseURL(serviceUrl); err == nil {
 		isvc.Status.URL = url
+		setVersionURLs(isvc, url)
 		isvc.Status.Address = &duckv1.Addressable{
 			URL: &apis.URL{
 				Host:   network.GetServiceHostname(isvc.Name, isvc.Namespace),
 				Scheme: "http",
 			},
 		}
+		if fallback {
+			log.Info("Routing isvc to fallback", "namespace", isvc.Namespace, "name", isvc.Name, "fallback", isvc.Spec.Routing.Fallback)
+			isvc.Status.SetCondition(v1beta1.IngressReady, &apis.Condition{
+				Type:    v1beta1.IngressReady,
+				Status:  corev1.ConditionFalse,
+				Reason:  reason,
+				Message: fmt.Sprintf("Routing to fallback InferenceService %s", isvc.Spec.Routing.Fallback),
+			})
+			return nil
+		}
 		isvc.Status.SetCondition(v1beta1.IngressReady, &apis.Condition{
 			Type:   v1beta1.IngressReady,
 			Status: corev1.ConditionTrue,
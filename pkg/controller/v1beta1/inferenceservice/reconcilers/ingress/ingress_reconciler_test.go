@@ -0,0 +1,341 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	gogotypes "github.com/gogo/protobuf/types"
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/pool"
+	"github.com/onsi/gomega"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	"istio.io/client-go/pkg/apis/networking/v1alpha3"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/apis"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newScheme(g *gomega.GomegaWithT) *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	g.Expect(v1.AddToScheme(scheme)).Should(gomega.Succeed())
+	g.Expect(v1beta1.AddToScheme(scheme)).Should(gomega.Succeed())
+	g.Expect(v1alpha3.AddToScheme(scheme)).Should(gomega.Succeed())
+	return scheme
+}
+
+func newReconciler(g *gomega.GomegaWithT) *IngressReconciler {
+	scheme := newScheme(g)
+	return NewIngressReconciler(fake.NewFakeClientWithScheme(scheme), scheme,
+		&v1beta1.IngressConfig{IngressGateway: "knative-serving/knative-ingress-gateway"}, v1beta1.DriftPolicyRevert)
+}
+
+// newInferenceService returns a sklearn InferenceService whose predictor url is reported, the ingress conditions
+// are left to the reconciler
+func newInferenceService(predictorReady bool) *v1beta1.InferenceService {
+	isvc := &v1beta1.InferenceService{
+		ObjectMeta: metav1.ObjectMeta{Name: "sklearn", Namespace: "default", UID: "1234"},
+		Spec: v1beta1.InferenceServiceSpec{
+			Predictor: v1beta1.PredictorSpec{
+				SKLearn: &v1beta1.SKLearnSpec{},
+			},
+		},
+	}
+	url, _ := apis.ParseURL("http://sklearn-predictor-default.default.example.com")
+	isvc.Status.Components = map[v1beta1.ComponentType]v1beta1.ComponentStatusSpec{
+		v1beta1.PredictorComponent: {URL: url},
+	}
+	isvc.Status.InitializeConditions()
+	if predictorReady {
+		isvc.Status.SetCondition(v1beta1.PredictorReady, &apis.Condition{Status: v1.ConditionTrue})
+	}
+	return isvc
+}
+
+// fakeMetrics returns the value of the queries, or an error for the unknown ones
+type fakeMetrics map[string]float64
+
+func (m fakeMetrics) Query(ctx context.Context, query string) (float64, error) {
+	if value, ok := m[query]; ok {
+		return value, nil
+	}
+	return 0, fmt.Errorf("no data")
+}
+
+// routeHosts returns the Host header set by each destination of a route
+func routeHosts(route *istiov1alpha3.HTTPRoute) []string {
+	var hosts []string
+	for _, destination := range route.Route {
+		hosts = append(hosts, destination.Headers.Request.Set["Host"])
+	}
+	return hosts
+}
+
+func TestCreateHTTPRetry(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scenarios := map[string]struct {
+		routing  *v1beta1.RoutingSpec
+		expected *istiov1alpha3.HTTPRetry
+	}{
+		"NoRouting": {},
+		"NoRetries": {
+			routing: &v1beta1.RoutingSpec{Fallback: "sklearn-stable"},
+		},
+		"DefaultRetryOn": {
+			routing: &v1beta1.RoutingSpec{Retries: &v1beta1.RetryPolicy{Attempts: 3}},
+			expected: &istiov1alpha3.HTTPRetry{
+				Attempts: 3,
+				RetryOn:  v1beta1.DefaultRetryOn,
+			},
+		},
+		"PerTryTimeout": {
+			routing: &v1beta1.RoutingSpec{Retries: &v1beta1.RetryPolicy{Attempts: 2, PerTryTimeoutSeconds: 5,
+				RetryOn: "5xx"}},
+			expected: &istiov1alpha3.HTTPRetry{
+				Attempts:      2,
+				RetryOn:       "5xx",
+				PerTryTimeout: gogotypes.DurationProto(5 * time.Second),
+			},
+		},
+	}
+	for name, scenario := range scenarios {
+		isvc := newInferenceService(true)
+		isvc.Spec.Routing = scenario.routing
+		g.Expect(createHTTPRetry(isvc)).To(gomega.Equal(scenario.expected), name)
+	}
+}
+
+func TestCreateIngressRetries(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	reconciler := newReconciler(g)
+	isvc := newInferenceService(true)
+	isvc.Spec.Explainer = &v1beta1.ExplainerSpec{}
+	isvc.Spec.Routing = &v1beta1.RoutingSpec{Retries: &v1beta1.RetryPolicy{Attempts: 3}}
+
+	ingress, err := reconciler.createIngress(isvc, "sklearn.default.example.com", false)
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(ingress.Spec.Http).To(gomega.HaveLen(2))
+	for _, route := range ingress.Spec.Http {
+		g.Expect(route.Retries).To(gomega.Equal(&istiov1alpha3.HTTPRetry{Attempts: 3, RetryOn: v1beta1.DefaultRetryOn}))
+	}
+	g.Expect(routeHosts(ingress.Spec.Http[0])).To(gomega.Equal([]string{"sklearn-explainer-default.default.svc.cluster.local"}))
+	g.Expect(routeHosts(ingress.Spec.Http[1])).To(gomega.Equal([]string{"sklearn-predictor-default.default.svc.cluster.local"}))
+
+	// the routes are not retried without a retry policy
+	isvc.Spec.Routing = nil
+	ingress, err = reconciler.createIngress(isvc, "sklearn.default.example.com", false)
+	g.Expect(err).Should(gomega.BeNil())
+	for _, route := range ingress.Spec.Http {
+		g.Expect(route.Retries).To(gomega.BeNil())
+	}
+}
+
+func TestCreateIngressFallback(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	reconciler := newReconciler(g)
+	isvc := newInferenceService(false)
+	isvc.Spec.Explainer = &v1beta1.ExplainerSpec{}
+	isvc.Spec.Routing = &v1beta1.RoutingSpec{Fallback: "sklearn-stable"}
+
+	// the explain requests are routed with the predict requests to the fallback InferenceService
+	ingress, err := reconciler.createIngress(isvc, "sklearn.default.example.com", true)
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(ingress.Spec.Http).To(gomega.HaveLen(1))
+	g.Expect(routeHosts(ingress.Spec.Http[0])).To(gomega.Equal([]string{"sklearn-stable.default.svc.cluster.local"}))
+	g.Expect(ingress.Spec.Http[0].Route[0].Destination.Host).To(gomega.Equal(constants.LocalGatewayHost))
+}
+
+func TestReconcileFallback(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	reconciler := newReconciler(g)
+	key := types.NamespacedName{Name: "sklearn", Namespace: "default"}
+
+	// the ingress is kept not ready without a fallback while the predictor is not ready
+	isvc := newInferenceService(false)
+	g.Expect(reconciler.Reconcile(isvc)).To(gomega.Succeed())
+	g.Expect(isvc.Status.IsConditionReady(v1beta1.IngressReady)).To(gomega.BeFalse())
+	err := reconciler.client.Get(context.TODO(), key, &v1alpha3.VirtualService{})
+	g.Expect(err).ShouldNot(gomega.BeNil())
+
+	// the host is routed to the fallback while the predictor is not ready
+	isvc.Spec.Routing = &v1beta1.RoutingSpec{Fallback: "sklearn-stable"}
+	g.Expect(reconciler.Reconcile(isvc)).To(gomega.Succeed())
+	condition := isvc.Status.GetCondition(v1beta1.IngressReady)
+	g.Expect(condition.Status).To(gomega.Equal(v1.ConditionFalse))
+	g.Expect(condition.Reason).To(gomega.Equal("Predictor ingress not created"))
+	g.Expect(condition.Message).To(gomega.Equal("Routing to fallback InferenceService sklearn-stable"))
+	g.Expect(isvc.Status.URL.String()).To(gomega.Equal("http://sklearn.default.example.com"))
+	ingress := &v1alpha3.VirtualService{}
+	g.Expect(reconciler.client.Get(context.TODO(), key, ingress)).To(gomega.Succeed())
+	g.Expect(routeHosts(ingress.Spec.Http[0])).To(gomega.Equal([]string{"sklearn-stable.default.svc.cluster.local"}))
+	g.Expect(reconciler.client.Get(context.TODO(), key, &v1.Service{})).To(gomega.Succeed())
+
+	// the host is routed back to the predictor once it is ready
+	isvc.Status.SetCondition(v1beta1.PredictorReady, &apis.Condition{Status: v1.ConditionTrue})
+	g.Expect(reconciler.Reconcile(isvc)).To(gomega.Succeed())
+	g.Expect(isvc.Status.IsConditionReady(v1beta1.IngressReady)).To(gomega.BeTrue())
+	g.Expect(reconciler.client.Get(context.TODO(), key, ingress)).To(gomega.Succeed())
+	g.Expect(routeHosts(ingress.Spec.Http[0])).To(gomega.Equal([]string{"sklearn-predictor-default.default.svc.cluster.local"}))
+}
+
+// routeWeights returns the weight of each destination of a route
+func routeWeights(route *istiov1alpha3.HTTPRoute) []int32 {
+	var weights []int32
+	for _, destination := range route.Route {
+		weights = append(weights, destination.Weight)
+	}
+	return weights
+}
+
+func TestCreateIngressPool(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	reconciler := newReconciler(g)
+	now := time.Date(2020, 10, 1, 8, 0, 0, 0, time.UTC)
+	poolHosts := []string{"sklearn-predictor-default.default.svc.cluster.local",
+		"sklearn-predictor-pool.default.svc.cluster.local"}
+	scenarios := map[string]struct {
+		pool            *v1beta1.ReplicaPoolSpec
+		requestsPerSec  float64
+		poolRevision    string
+		expectedHosts   []string
+		expectedWeights []int32
+	}{
+		"Weighted": {
+			pool:            &v1beta1.ReplicaPoolSpec{Routing: v1beta1.WeightedPoolRouting, Weight: 30},
+			poolRevision:    "sklearn-predictor-pool-00001",
+			expectedHosts:   poolHosts,
+			expectedWeights: []int32{70, 30},
+		},
+		"WeightedPoolNotReady": {
+			pool:            &v1beta1.ReplicaPoolSpec{Routing: v1beta1.WeightedPoolRouting, Weight: 30},
+			expectedHosts:   poolHosts[:1],
+			expectedWeights: []int32{0},
+		},
+		"Overflow": {
+			pool:            &v1beta1.ReplicaPoolSpec{CapacityRequestsPerSecond: 100},
+			requestsPerSec:  125,
+			poolRevision:    "sklearn-predictor-pool-00001",
+			expectedHosts:   poolHosts,
+			expectedWeights: []int32{80, 20},
+		},
+		"OverflowWithinCapacity": {
+			pool:            &v1beta1.ReplicaPoolSpec{CapacityRequestsPerSecond: 100},
+			requestsPerSec:  80,
+			poolRevision:    "sklearn-predictor-pool-00001",
+			expectedHosts:   poolHosts[:1],
+			expectedWeights: []int32{0},
+		},
+	}
+	for name, scenario := range scenarios {
+		isvc := newInferenceService(true)
+		isvc.Spec.Predictor.Pool = scenario.pool
+		statusSpec := isvc.Status.Components[v1beta1.PredictorComponent]
+		statusSpec.Pool = &v1beta1.PoolStatus{LatestReadyRevision: scenario.poolRevision}
+		isvc.Status.Components[v1beta1.PredictorComponent] = statusSpec
+		pool.Route(isvc, fakeMetrics{pool.RequestRateQuery(isvc): scenario.requestsPerSec}, now)
+
+		ingress, err := reconciler.createIngress(isvc, "sklearn.default.example.com", false)
+		g.Expect(err).Should(gomega.BeNil(), name)
+		g.Expect(ingress.Spec.Http).To(gomega.HaveLen(1), name)
+		g.Expect(routeHosts(ingress.Spec.Http[0])).To(gomega.Equal(scenario.expectedHosts), name)
+		g.Expect(routeWeights(ingress.Spec.Http[0])).To(gomega.Equal(scenario.expectedWeights), name)
+
+		// the fallback does not split the traffic with the pool
+		isvc.Spec.Routing = &v1beta1.RoutingSpec{Fallback: "sklearn-stable"}
+		ingress, err = reconciler.createIngress(isvc, "sklearn.default.example.com", true)
+		g.Expect(err).Should(gomega.BeNil(), name)
+		g.Expect(ingress.Spec.Http[0].Route).To(gomega.HaveLen(1), name)
+	}
+}
+
+func TestCreateIngressVersions(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	reconciler := newReconciler(g)
+	versions := []v1beta1.ModelVersionSpec{
+		{Name: "v1", StorageURI: "gs://models/sklearn/v1"},
+		{Name: "v2", StorageURI: "gs://models/sklearn/v2"},
+	}
+	scenarios := map[string]struct {
+		defaultVersion  string
+		versionStatus   []v1beta1.ModelVersionStatus
+		expectedBackend string
+	}{
+		"NoDefaultVersion": {
+			versionStatus:   []v1beta1.ModelVersionStatus{{Name: "v1", LatestReadyRevision: "sklearn-predictor-version-v1-00001"}},
+			expectedBackend: "sklearn-predictor-default.default.svc.cluster.local",
+		},
+		"DefaultVersionNotReady": {
+			defaultVersion:  "v2",
+			versionStatus:   []v1beta1.ModelVersionStatus{{Name: "v1", LatestReadyRevision: "sklearn-predictor-version-v1-00001"}, {Name: "v2"}},
+			expectedBackend: "sklearn-predictor-default.default.svc.cluster.local",
+		},
+		"DefaultVersionReady": {
+			defaultVersion: "v2",
+			versionStatus: []v1beta1.ModelVersionStatus{{Name: "v1"},
+				{Name: "v2", LatestReadyRevision: "sklearn-predictor-version-v2-00001"}},
+			expectedBackend: "sklearn-predictor-version-v2.default.svc.cluster.local",
+		},
+	}
+	for name, scenario := range scenarios {
+		isvc := newInferenceService(true)
+		isvc.Spec.Predictor.Versions = versions
+		isvc.Spec.Predictor.DefaultVersion = scenario.defaultVersion
+		statusSpec := isvc.Status.Components[v1beta1.PredictorComponent]
+		statusSpec.Versions = scenario.versionStatus
+		isvc.Status.Components[v1beta1.PredictorComponent] = statusSpec
+
+		ingress, err := reconciler.createIngress(isvc, "sklearn.default.example.com", false)
+		g.Expect(err).Should(gomega.BeNil(), name)
+		g.Expect(ingress.Spec.Http).To(gomega.HaveLen(3), name)
+		// the version routes match the version prefix of both hosts and remove it from the path
+		for i, version := range versions {
+			route := ingress.Spec.Http[i]
+			g.Expect(route.Match).To(gomega.HaveLen(2), name)
+			for _, match := range route.Match {
+				g.Expect(match.Uri.GetPrefix()).To(gomega.Equal("/versions/"+version.Name+"/"), name)
+			}
+			g.Expect(route.Rewrite).To(gomega.Equal(&istiov1alpha3.HTTPRewrite{Uri: "/"}), name)
+			g.Expect(routeHosts(route)).To(gomega.Equal(
+				[]string{"sklearn-predictor-version-" + version.Name + ".default.svc.cluster.local"}), name)
+		}
+		// the requests which do not name a version go to the default version once it is ready
+		g.Expect(ingress.Spec.Http[2].Match[0].Uri).To(gomega.BeNil(), name)
+		g.Expect(ingress.Spec.Http[2].Rewrite).To(gomega.BeNil(), name)
+		g.Expect(routeHosts(ingress.Spec.Http[2])).To(gomega.Equal([]string{scenario.expectedBackend}), name)
+	}
+}
+
+func TestSetVersionURLs(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := newInferenceService(true)
+	statusSpec := isvc.Status.Components[v1beta1.PredictorComponent]
+	statusSpec.Versions = []v1beta1.ModelVersionStatus{{Name: "v1"}, {Name: "v2"}}
+	isvc.Status.Components[v1beta1.PredictorComponent] = statusSpec
+	url, _ := apis.ParseURL("http://sklearn.default.example.com")
+	setVersionURLs(isvc, url)
+	versions := isvc.Status.Components[v1beta1.PredictorComponent].Versions
+	g.Expect(versions[0].URL.String()).To(gomega.Equal("http://sklearn.default.example.com/versions/v1"))
+	g.Expect(versions[1].URL.String()).To(gomega.Equal("http://sklearn.default.example.com/versions/v2"))
+	g.Expect(url.Path).To(gomega.BeEmpty())
+}
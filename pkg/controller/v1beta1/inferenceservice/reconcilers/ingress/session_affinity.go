@@ -0,0 +1,169 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	gogotypes "github.com/gogo/protobuf/types"
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/pkg/errors"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	"istio.io/client-go/pkg/apis/networking/v1alpha3"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/network"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// SessionAffinityLabel marks the destination rules selecting the predictor replicas by the session key
+const SessionAffinityLabel = "serving.kubeflow.org/session-affinity"
+
+// sessionAffinityRevisions returns the predictor revisions which may receive traffic, the latest and previous
+// ready revisions of a canary, the revisions of a rollout and the revision of the replica pool
+func sessionAffinityRevisions(isvc *v1beta1.InferenceService) []string {
+	if isvc.Spec.Routing == nil || isvc.Spec.Routing.SessionAffinity == nil {
+		return nil
+	}
+	status, ok := isvc.Status.Components[v1beta1.PredictorComponent]
+	if !ok {
+		return nil
+	}
+	revisions := []string{status.LatestReadyRevision, status.PreviousReadyRevision}
+	if status.Rollout != nil {
+		revisions = append(revisions, status.Rollout.ServingRevision, status.Rollout.DrainingRevision)
+	}
+	if status.Pool != nil {
+		revisions = append(revisions, status.Pool.LatestReadyRevision)
+	}
+	unique := map[string]bool{}
+	var names []string
+	for _, revision := range revisions {
+		if revision != "" && !unique[revision] {
+			unique[revision] = true
+			names = append(names, revision)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// createLoadBalancer returns the consistent hash load balancer on the session key
+func createLoadBalancer(affinity *v1beta1.SessionAffinitySpec) *istiov1alpha3.LoadBalancerSettings {
+	hash := &istiov1alpha3.LoadBalancerSettings_ConsistentHashLB{}
+	if affinity.Cookie != nil {
+		hash.HashKey = &istiov1alpha3.LoadBalancerSettings_ConsistentHashLB_HttpCookie{
+			HttpCookie: &istiov1alpha3.LoadBalancerSettings_ConsistentHashLB_HTTPCookie{
+				Name: affinity.Cookie.Name,
+				Path: affinity.Cookie.Path,
+				Ttl:  gogotypes.DurationProto(time.Duration(affinity.Cookie.TTLSeconds) * time.Second),
+			},
+		}
+	} else {
+		hash.HashKey = &istiov1alpha3.LoadBalancerSettings_ConsistentHashLB_HttpHeaderName{
+			HttpHeaderName: affinity.Header,
+		}
+	}
+	return &istiov1alpha3.LoadBalancerSettings{
+		LbPolicy: &istiov1alpha3.LoadBalancerSettings_ConsistentHash{ConsistentHash: hash},
+	}
+}
+
+// createDestinationRules returns the destination rules hashing the requests of the predictor revisions on the
+// session key, the knative gateway routes the requests to the services of the revisions which select the replicas
+// once the activator is out of the request path
+func (ir *IngressReconciler) createDestinationRules(isvc *v1beta1.InferenceService) ([]*v1alpha3.DestinationRule, error) {
+	var rules []*v1alpha3.DestinationRule
+	for _, revision := range sessionAffinityRevisions(isvc) {
+		rule := &v1alpha3.DestinationRule{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      revision + "-session-affinity",
+				Namespace: isvc.Namespace,
+				Labels: map[string]string{
+					constants.InferenceServicePodLabelKey: isvc.Name,
+					SessionAffinityLabel:                  "true",
+				},
+			},
+			Spec: istiov1alpha3.DestinationRule{
+				Host: network.GetServiceHostname(revision, isvc.Namespace),
+				TrafficPolicy: &istiov1alpha3.TrafficPolicy{
+					LoadBalancer: createLoadBalancer(isvc.Spec.Routing.SessionAffinity),
+				},
+			},
+		}
+		if err := controllerutil.SetControllerReference(isvc, rule, ir.scheme); err != nil {
+			return nil, errors.Wrapf(err, "fails to set owner reference for destination rule")
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// reconcileSessionAffinity creates the destination rules of the predictor revisions and deletes the rules of the
+// revisions which no longer receive traffic, or all of them when the session affinity is removed
+func (ir *IngressReconciler) reconcileSessionAffinity(isvc *v1beta1.InferenceService) error {
+	desired, err := ir.createDestinationRules(isvc)
+	if err != nil {
+		return err
+	}
+	existing := &v1alpha3.DestinationRuleList{}
+	if err := ir.client.List(context.TODO(), existing, client.InNamespace(isvc.Namespace), client.MatchingLabels{
+		constants.InferenceServicePodLabelKey: isvc.Name,
+		SessionAffinityLabel:                  "true",
+	}); err != nil {
+		// There are no destination rules to clean up when the CRD is not installed
+		if meta.IsNoMatchError(err) && len(desired) == 0 {
+			return nil
+		}
+		return errors.Wrapf(err, "fails to list destination rules")
+	}
+	observed := map[string]*v1alpha3.DestinationRule{}
+	for i := range existing.Items {
+		observed[existing.Items[i].Name] = &existing.Items[i]
+	}
+	for _, rule := range desired {
+		current, ok := observed[rule.Name]
+		delete(observed, rule.Name)
+		if !ok {
+			log.Info("Creating session affinity destination rule", "namespace", rule.Namespace, "name", rule.Name)
+			if err := ir.client.Create(context.TODO(), rule); err != nil {
+				return errors.Wrapf(err, "fails to create destination rule")
+			}
+			continue
+		}
+		if equality.Semantic.DeepEqual(current.Spec, rule.Spec) {
+			continue
+		}
+		current.Spec = rule.Spec
+		log.Info("Updating session affinity destination rule", "namespace", rule.Namespace, "name", rule.Name)
+		if err := ir.client.Update(context.TODO(), current); err != nil {
+			return errors.Wrapf(err, "fails to update destination rule")
+		}
+	}
+	for _, stale := range observed {
+		log.Info("Deleting session affinity destination rule", "namespace", stale.Namespace, "name", stale.Name)
+		if err := ir.client.Delete(context.TODO(), stale); err != nil {
+			return errors.Wrapf(err, "fails to delete destination rule")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	gogotypes "github.com/gogo/protobuf/types"
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/onsi/gomega"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	"istio.io/client-go/pkg/apis/networking/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// newSessionAffinityService returns an InferenceService with the header session affinity which rolls out its
+// predictor from the revision 00001 to 00002 while the replica pool runs the revision 00001 of the pool
+func newSessionAffinityService() *v1beta1.InferenceService {
+	isvc := newInferenceService(true)
+	isvc.Spec.Routing = &v1beta1.RoutingSpec{
+		SessionAffinity: &v1beta1.SessionAffinitySpec{Header: "x-session-id"},
+	}
+	statusSpec := isvc.Status.Components[v1beta1.PredictorComponent]
+	statusSpec.LatestReadyRevision = "sklearn-predictor-default-00002"
+	statusSpec.PreviousReadyRevision = "sklearn-predictor-default-00001"
+	statusSpec.Rollout = &v1beta1.RolloutStatus{
+		ReadyRevision:    "sklearn-predictor-default-00002",
+		ServingRevision:  "sklearn-predictor-default-00002",
+		DrainingRevision: "sklearn-predictor-default-00001",
+	}
+	statusSpec.Pool = &v1beta1.PoolStatus{LatestReadyRevision: "sklearn-predictor-pool-00001"}
+	isvc.Status.Components[v1beta1.PredictorComponent] = statusSpec
+	return isvc
+}
+
+func listDestinationRules(g *gomega.GomegaWithT, reconciler *IngressReconciler) map[string]*istiov1alpha3.DestinationRule {
+	rules := &v1alpha3.DestinationRuleList{}
+	g.Expect(reconciler.client.List(context.TODO(), rules, client.InNamespace("default"))).Should(gomega.Succeed())
+	specs := map[string]*istiov1alpha3.DestinationRule{}
+	for i := range rules.Items {
+		specs[rules.Items[i].Name] = &rules.Items[i].Spec
+	}
+	return specs
+}
+
+func TestSessionAffinityRevisions(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := newSessionAffinityService()
+	g.Expect(sessionAffinityRevisions(isvc)).To(gomega.Equal([]string{
+		"sklearn-predictor-default-00001",
+		"sklearn-predictor-default-00002",
+		"sklearn-predictor-pool-00001",
+	}))
+
+	isvc.Spec.Routing.SessionAffinity = nil
+	g.Expect(sessionAffinityRevisions(isvc)).To(gomega.BeEmpty())
+}
+
+func TestCreateLoadBalancer(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scenarios := map[string]struct {
+		affinity *v1beta1.SessionAffinitySpec
+		expected *istiov1alpha3.LoadBalancerSettings_ConsistentHashLB
+	}{
+		"Header": {
+			affinity: &v1beta1.SessionAffinitySpec{Header: "x-session-id"},
+			expected: &istiov1alpha3.LoadBalancerSettings_ConsistentHashLB{
+				HashKey: &istiov1alpha3.LoadBalancerSettings_ConsistentHashLB_HttpHeaderName{
+					HttpHeaderName: "x-session-id",
+				},
+			},
+		},
+		"Cookie": {
+			affinity: &v1beta1.SessionAffinitySpec{
+				Cookie: &v1beta1.SessionCookie{Name: "session", Path: "/", TTLSeconds: 3600},
+			},
+			expected: &istiov1alpha3.LoadBalancerSettings_ConsistentHashLB{
+				HashKey: &istiov1alpha3.LoadBalancerSettings_ConsistentHashLB_HttpCookie{
+					HttpCookie: &istiov1alpha3.LoadBalancerSettings_ConsistentHashLB_HTTPCookie{
+						Name: "session",
+						Path: "/",
+						Ttl:  gogotypes.DurationProto(time.Hour),
+					},
+				},
+			},
+		},
+	}
+	for name, scenario := range scenarios {
+		g.Expect(createLoadBalancer(scenario.affinity)).To(gomega.Equal(&istiov1alpha3.LoadBalancerSettings{
+			LbPolicy: &istiov1alpha3.LoadBalancerSettings_ConsistentHash{ConsistentHash: scenario.expected},
+		}), name)
+	}
+}
+
+func TestCreateDestinationRules(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	reconciler := newReconciler(g)
+	isvc := newSessionAffinityService()
+
+	rules, err := reconciler.createDestinationRules(isvc)
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(rules).To(gomega.HaveLen(3))
+	rule := rules[0]
+	g.Expect(rule.Name).To(gomega.Equal("sklearn-predictor-default-00001-session-affinity"))
+	g.Expect(rule.Labels).To(gomega.Equal(map[string]string{
+		constants.InferenceServicePodLabelKey: "sklearn",
+		SessionAffinityLabel:                  "true",
+	}))
+	g.Expect(rule.OwnerReferences).To(gomega.HaveLen(1))
+	g.Expect(rule.Spec.Host).To(gomega.Equal("sklearn-predictor-default-00001.default.svc.cluster.local"))
+	g.Expect(rule.Spec.TrafficPolicy.LoadBalancer).To(gomega.Equal(createLoadBalancer(isvc.Spec.Routing.SessionAffinity)))
+	g.Expect(rules[2].Spec.Host).To(gomega.Equal("sklearn-predictor-pool-00001.default.svc.cluster.local"))
+}
+
+func TestReconcileSessionAffinity(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	reconciler := newReconciler(g)
+	isvc := newSessionAffinityService()
+
+	g.Expect(reconciler.reconcileSessionAffinity(isvc)).Should(gomega.Succeed())
+	rules := listDestinationRules(g, reconciler)
+	g.Expect(rules).To(gomega.HaveLen(3))
+	g.Expect(rules).To(gomega.HaveKey("sklearn-predictor-default-00001-session-affinity"))
+
+	// the rule of the drained revision is deleted and the session key change updates the remaining rules
+	statusSpec := isvc.Status.Components[v1beta1.PredictorComponent]
+	statusSpec.PreviousReadyRevision = ""
+	statusSpec.Rollout.DrainingRevision = ""
+	isvc.Status.Components[v1beta1.PredictorComponent] = statusSpec
+	isvc.Spec.Routing.SessionAffinity = &v1beta1.SessionAffinitySpec{Cookie: &v1beta1.SessionCookie{Name: "session"}}
+	g.Expect(reconciler.reconcileSessionAffinity(isvc)).Should(gomega.Succeed())
+	rules = listDestinationRules(g, reconciler)
+	g.Expect(rules).To(gomega.HaveLen(2))
+	g.Expect(rules).NotTo(gomega.HaveKey("sklearn-predictor-default-00001-session-affinity"))
+	hash := rules["sklearn-predictor-default-00002-session-affinity"].TrafficPolicy.LoadBalancer.GetConsistentHash()
+	g.Expect(hash.GetHttpCookie().GetName()).To(gomega.Equal("session"))
+
+	// all the rules are deleted when the session affinity is removed
+	isvc.Spec.Routing = nil
+	g.Expect(reconciler.reconcileSessionAffinity(isvc)).Should(gomega.Succeed())
+	g.Expect(listDestinationRules(g, reconciler)).To(gomega.BeEmpty())
+}
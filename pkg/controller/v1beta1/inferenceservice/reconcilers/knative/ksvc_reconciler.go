@@ -15,10 +15,17 @@ package knative
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strings"
+	"time"
+
 	"github.com/golang/protobuf/proto"
 	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
 	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/predictive"
+	v1beta1utils "github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/utils"
+	"github.com/kubeflow/kfserving/pkg/digest"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
@@ -43,6 +50,12 @@ type KsvcReconciler struct {
 	Service         *knservingv1.Service
 	componentExt    *v1beta1.ComponentExtensionSpec
 	componentStatus v1beta1.ComponentStatusSpec
+	rollback        bool
+	driftPolicy     v1beta1.DriftPolicy
+	// Drifted is set by Reconcile when the knative service was modified out of band and the changes are kept
+	Drifted bool
+	// Digests pins the images of the knative service template to digests, the images are not pinned when it is nil
+	Digests *digest.Pinner
 }
 
 func NewKsvcReconciler(client client.Client,
@@ -50,30 +63,45 @@ func NewKsvcReconciler(client client.Client,
 	componentMeta metav1.ObjectMeta,
 	componentExt *v1beta1.ComponentExtensionSpec,
 	podSpec *corev1.PodSpec,
-	componentStatus v1beta1.ComponentStatusSpec) *KsvcReconciler {
+	componentStatus v1beta1.ComponentStatusSpec,
+	driftPolicy v1beta1.DriftPolicy) *KsvcReconciler {
 	return &KsvcReconciler{
 		client:          client,
 		scheme:          scheme,
 		Service:         createKnativeService(componentMeta, componentExt, podSpec, componentStatus),
 		componentExt:    componentExt,
 		componentStatus: componentStatus,
+		rollback:        isRollbackRequested(componentMeta, componentStatus),
+		driftPolicy:     driftPolicy,
 	}
 }
 
+// isRollbackRequested returns true if the rollback annotation is set and there is a previous ready revision to roll back to
+func isRollbackRequested(componentMeta metav1.ObjectMeta, componentStatus v1beta1.ComponentStatusSpec) bool {
+	return componentMeta.Annotations[constants.RollbackAnnotationKey] == "true" && componentStatus.PreviousReadyRevision != ""
+}
+
 func createKnativeService(componentMeta metav1.ObjectMeta,
 	componentExtension *v1beta1.ComponentExtensionSpec,
 	podSpec *corev1.PodSpec,
 	componentStatus v1beta1.ComponentStatusSpec) *knservingv1.Service {
 	annotations := componentMeta.GetAnnotations()
+	rollback := isRollbackRequested(componentMeta, componentStatus)
+	// The rollback annotation only affects routing, it is not propagated to the revision template
+	// so that requesting a rollback does not create a new revision
+	delete(annotations, constants.RollbackAnnotationKey)
 
-	if componentExtension.MinReplicas == nil {
-		annotations[autoscaling.MinScaleAnnotationKey] = fmt.Sprint(constants.DefaultMinReplicas)
-	} else {
-		annotations[autoscaling.MinScaleAnnotationKey] = fmt.Sprint(*componentExtension.MinReplicas)
-	}
+	minReplicas, _ := v1beta1utils.GetMinReplicas(componentExtension, time.Now())
+	minReplicas = predictive.MinReplicas(componentExtension, componentStatus, minReplicas)
+	annotations[autoscaling.MinScaleAnnotationKey] = fmt.Sprint(minReplicas)
 
 	if componentExtension.MaxReplicas != 0 {
-		annotations[autoscaling.MaxScaleAnnotationKey] = fmt.Sprint(componentExtension.MaxReplicas)
+		maxReplicas := componentExtension.MaxReplicas
+		// The scale subresource only updates minReplicas, so never cap the component below the requested scale
+		if maxReplicas < minReplicas {
+			maxReplicas = minReplicas
+		}
+		annotations[autoscaling.MaxScaleAnnotationKey] = fmt.Sprint(maxReplicas)
 	}
 
 	// User can pass down scaling class annotation to overwrite the default scaling KPA
@@ -82,15 +110,30 @@ func createKnativeService(componentMeta metav1.ObjectMeta,
 	}
 
 	trafficTargets := []knservingv1.TrafficTarget{}
-	if componentExtension.CanaryTrafficPercent != nil && componentStatus.PreviousReadyRevision != "" {
+	if rollback {
+		//rollback pins all the traffic to the previous ready revision
+		trafficTargets = append(trafficTargets,
+			knservingv1.TrafficTarget{
+				Tag:            "latest",
+				LatestRevision: proto.Bool(true),
+				Percent:        proto.Int64(0),
+			},
+			knservingv1.TrafficTarget{
+				Tag:            "prev",
+				RevisionName:   componentStatus.PreviousReadyRevision,
+				LatestRevision: proto.Bool(false),
+				Percent:        proto.Int64(100),
+			})
+	} else if canaryPercent := canaryTrafficPercent(componentExtension, componentStatus,
+		componentStatus.LatestReadyRevision); canaryPercent != nil && componentStatus.PreviousReadyRevision != "" {
 		//canary rollout
 		trafficTargets = append(trafficTargets,
 			knservingv1.TrafficTarget{
 				Tag:            "latest",
 				LatestRevision: proto.Bool(true),
-				Percent:        proto.Int64(*componentExtension.CanaryTrafficPercent),
+				Percent:        proto.Int64(*canaryPercent),
 			})
-		remainingTraffic := 100 - *componentExtension.CanaryTrafficPercent
+		remainingTraffic := 100 - *canaryPercent
 		trafficTargets = append(trafficTargets,
 			knservingv1.TrafficTarget{
 				Tag:            "prev",
@@ -107,6 +150,29 @@ func createKnativeService(componentMeta metav1.ObjectMeta,
 				Percent:        proto.Int64(100),
 			})
 	}
+	trafficTargets = pinServingRevision(componentExtension, componentStatus, trafficTargets)
+
+	// Knative does not allow topology spread constraints and termination grace periods in the revision pod spec, and
+	// drops the runtime class unless its kubernetes.podspec-runtimeclassname feature is enabled, they are passed to the
+	// pod mutator which injects them in the revision pods with the pre-stop sleep
+	revisionPodSpec := *podSpec
+	if len(revisionPodSpec.TopologySpreadConstraints) != 0 {
+		if constraints, err := json.Marshal(revisionPodSpec.TopologySpreadConstraints); err == nil {
+			annotations[constants.TopologySpreadInternalAnnotationKey] = string(constraints)
+		}
+		revisionPodSpec.TopologySpreadConstraints = nil
+	}
+	if revisionPodSpec.TerminationGracePeriodSeconds != nil {
+		annotations[constants.TerminationGracePeriodInternalAnnotationKey] = fmt.Sprint(*revisionPodSpec.TerminationGracePeriodSeconds)
+		revisionPodSpec.TerminationGracePeriodSeconds = nil
+	}
+	if revisionPodSpec.RuntimeClassName != nil {
+		annotations[constants.RuntimeClassInternalAnnotationKey] = *revisionPodSpec.RuntimeClassName
+		revisionPodSpec.RuntimeClassName = nil
+	}
+	if componentExtension.PreStopSleepSeconds != nil {
+		annotations[constants.PreStopSleepInternalAnnotationKey] = fmt.Sprint(*componentExtension.PreStopSleepSeconds)
+	}
 
 	service := &knservingv1.Service{
 		ObjectMeta: metav1.ObjectMeta{
@@ -124,7 +190,7 @@ func createKnativeService(componentMeta metav1.ObjectMeta,
 					Spec: knservingv1.RevisionSpec{
 						TimeoutSeconds:       componentExtension.TimeoutSeconds,
 						ContainerConcurrency: componentExtension.ContainerConcurrency,
-						PodSpec:              *podSpec,
+						PodSpec:              revisionPodSpec,
 					},
 				},
 			},
@@ -136,6 +202,13 @@ func createKnativeService(componentMeta metav1.ObjectMeta,
 	//Call setDefaults on desired knative service here to avoid diffs generated because knative defaulter webhook is
 	//called when creating or updating the knative service
 	service.SetDefaults(context.TODO())
+	// The traffic is not part of the hash as it is updated by the controller during canary rollouts
+	service.Annotations = map[string]string{
+		constants.DesiredSpecHashInternalAnnotationKey: v1beta1utils.HashSpec([]interface{}{
+			service.Spec.ConfigurationSpec,
+			service.Labels,
+		}),
+	}
 	return service
 }
 
@@ -146,37 +219,62 @@ func (r *KsvcReconciler) Reconcile() (*knservingv1.ServiceStatus, error) {
 	err := r.client.Get(context.TODO(), types.NamespacedName{Name: desired.Name, Namespace: desired.Namespace}, existing)
 	if err != nil {
 		if apierr.IsNotFound(err) {
+			if err := r.pinDigests(desired, nil); err != nil {
+				return nil, err
+			}
 			log.Info("Creating knative service", "namespace", desired.Namespace, "name", desired.Name)
 			return &desired.Status, r.client.Create(context.TODO(), desired)
 		}
 		return nil, err
 	}
+	if err := r.pinDigests(desired, existing); err != nil {
+		return &existing.Status, err
+	}
+	desiredHash := desired.Annotations[constants.DesiredSpecHashInternalAnnotationKey]
+	observedHash := existing.Annotations[constants.DesiredSpecHashInternalAnnotationKey]
+	r.Drifted = false
 	// Return if no differences to reconcile.
-	if semanticEquals(desired, existing) {
+	if semanticEquals(desired, existing) && desiredHash == observedHash {
 		return &existing.Status, nil
 	}
 
-	// Reconcile differences and update
-	diff, err := kmp.SafeDiff(desired.Spec.ConfigurationSpec, existing.Spec.ConfigurationSpec)
-	if err != nil {
-		return &existing.Status, errors.Wrapf(err, "failed to diff knative service configuration spec")
+	// The desired spec has not changed since the last update, so the differences are out of band changes
+	drifted := desiredHash == observedHash && !configurationEquals(desired, existing)
+	if drifted && r.driftPolicy == v1beta1.DriftPolicyReport {
+		log.Info("Keeping out of band changes to knative service", "namespace", desired.Namespace, "name", desired.Name)
+		r.Drifted = true
+	} else {
+		if drifted {
+			log.Info("Reverting out of band changes to knative service", "namespace", desired.Namespace, "name", desired.Name)
+		}
+		// Reconcile differences and update
+		diff, err := kmp.SafeDiff(desired.Spec.ConfigurationSpec, existing.Spec.ConfigurationSpec)
+		if err != nil {
+			return &existing.Status, errors.Wrapf(err, "failed to diff knative service configuration spec")
+		}
+		log.Info("knative service configuration diff (-desired, +observed):", "diff", diff)
+		existing.Spec.ConfigurationSpec = desired.Spec.ConfigurationSpec
+		existing.ObjectMeta.Labels = desired.ObjectMeta.Labels
+		if existing.ObjectMeta.Annotations == nil {
+			existing.ObjectMeta.Annotations = map[string]string{}
+		}
+		existing.ObjectMeta.Annotations[constants.DesiredSpecHashInternalAnnotationKey] = desiredHash
 	}
-	log.Info("knative service configuration diff (-desired, +observed):", "diff", diff)
-	existing.Spec.ConfigurationSpec = desired.Spec.ConfigurationSpec
-	existing.ObjectMeta.Labels = desired.ObjectMeta.Labels
 
-	if r.componentExt.CanaryTrafficPercent != nil && r.componentStatus.LatestReadyRevision != "" &&
+	// A new revision became ready since the status was reported, it starts with the canary traffic
+	canaryPercent := canaryTrafficPercent(r.componentExt, r.componentStatus, existing.Status.LatestReadyRevisionName)
+	if !r.rollback && canaryPercent != nil && r.componentStatus.LatestReadyRevision != "" &&
 		r.componentStatus.LatestReadyRevision != existing.Status.LatestReadyRevisionName {
 		log.Info("Updating knative service traffic target", "namespace", desired.Namespace, "name", desired.Name, "canaryPercent",
-			r.componentExt.CanaryTrafficPercent)
+			*canaryPercent)
 		trafficTargets := []knservingv1.TrafficTarget{}
 		trafficTargets = append(trafficTargets,
 			knservingv1.TrafficTarget{
 				Tag:            "latest",
 				LatestRevision: proto.Bool(true),
-				Percent:        r.componentExt.CanaryTrafficPercent,
+				Percent:        canaryPercent,
 			})
-		remainingTraffic := 100 - *r.componentExt.CanaryTrafficPercent
+		remainingTraffic := 100 - *canaryPercent
 		trafficTargets = append(trafficTargets,
 			knservingv1.TrafficTarget{
 				Tag:            "prev",
@@ -184,7 +282,7 @@ func (r *KsvcReconciler) Reconcile() (*knservingv1.ServiceStatus, error) {
 				LatestRevision: proto.Bool(false),
 				Percent:        proto.Int64(remainingTraffic),
 			})
-		existing.Spec.Traffic = trafficTargets
+		existing.Spec.Traffic = pinServingRevision(r.componentExt, r.componentStatus, trafficTargets)
 	} else {
 		diff, err := kmp.SafeDiff(desired.Spec.RouteSpec, existing.Spec.RouteSpec)
 		if err != nil {
@@ -205,8 +303,99 @@ func (r *KsvcReconciler) Reconcile() (*knservingv1.ServiceStatus, error) {
 	return &existing.Status, nil
 }
 
+// pinDigests pins the images of the desired template to the digests of their tags, the tags are recorded in the image
+// tags annotation of the revision. The images the existing knative service was pinned from keep their digests, so the
+// registries are only queried when the images of the template change.
+func (r *KsvcReconciler) pinDigests(desired *knservingv1.Service, existing *knservingv1.Service) error {
+	if r.Digests == nil {
+		return nil
+	}
+	var pinned map[string]string
+	if existing != nil {
+		if tags, ok := existing.Spec.Template.Annotations[constants.ImageTagsAnnotationKey]; ok {
+			pinned = digest.PinnedImages(strings.Split(tags, ","), &existing.Spec.Template.Spec.PodSpec)
+		}
+	}
+	tags, err := r.Digests.Pin(desired.Namespace, &desired.Spec.Template.Spec.PodSpec, pinned)
+	if err != nil {
+		return errors.Wrapf(err, "fails to pin the images of knative service %s", desired.Name)
+	}
+	if len(tags) != 0 {
+		if desired.Spec.Template.Annotations == nil {
+			desired.Spec.Template.Annotations = map[string]string{}
+		}
+		desired.Spec.Template.Annotations[constants.ImageTagsAnnotationKey] = strings.Join(tags, ",")
+	}
+	return nil
+}
+
+// canaryTrafficPercent returns the traffic percent of the latest ready revision during a canary rollout, which is set
+// by the canary analysis status of the revision when the component has a canary analysis. A revision which is not
+// analyzed yet starts at the first step.
+func canaryTrafficPercent(componentExtension *v1beta1.ComponentExtensionSpec, componentStatus v1beta1.ComponentStatusSpec,
+	latestReadyRevision string) *int64 {
+	analysis := componentExtension.CanaryAnalysis
+	if analysis == nil {
+		return componentExtension.CanaryTrafficPercent
+	}
+	status := componentStatus.CanaryAnalysis
+	if status == nil || status.Revision != latestReadyRevision {
+		return proto.Int64(analysis.Steps[0])
+	}
+	switch status.Phase {
+	case v1beta1.CanaryPromoted:
+		return proto.Int64(100)
+	case v1beta1.CanaryRolledBack:
+		return proto.Int64(0)
+	}
+	if status.Step >= len(analysis.Steps) {
+		return proto.Int64(analysis.Steps[len(analysis.Steps)-1])
+	}
+	return proto.Int64(analysis.Steps[status.Step])
+}
+
+// pinServingRevision routes the traffic of the latest revision to the last warmed up revision when the component has
+// warm-up requests, or to the serving revision of the rollout when the component has a rollout. The traffic is shifted
+// once the new revision is warmed up, or soaked, and recorded in the component status. The draining revision of the
+// rollout is kept as a tagged target without traffic, the tag keeps it reachable so that the autoscaler does not
+// scale it below the min replicas of its template.
+func pinServingRevision(componentExtension *v1beta1.ComponentExtensionSpec, componentStatus v1beta1.ComponentStatusSpec,
+	trafficTargets []knservingv1.TrafficTarget) []knservingv1.TrafficTarget {
+	revision := ""
+	if componentExtension.WarmUp != nil {
+		revision = componentStatus.WarmedUpRevision
+	}
+	rollout := componentStatus.Rollout
+	if componentExtension.Rollout != nil && rollout != nil {
+		revision = rollout.ServingRevision
+	}
+	if revision != "" {
+		for i := range trafficTargets {
+			if trafficTargets[i].LatestRevision != nil && *trafficTargets[i].LatestRevision {
+				trafficTargets[i].LatestRevision = proto.Bool(false)
+				trafficTargets[i].RevisionName = revision
+			}
+		}
+	}
+	if componentExtension.Rollout != nil && rollout != nil && rollout.DrainingRevision != "" {
+		trafficTargets = append(trafficTargets, knservingv1.TrafficTarget{
+			Tag:            "drain",
+			RevisionName:   rollout.DrainingRevision,
+			LatestRevision: proto.Bool(false),
+			Percent:        proto.Int64(0),
+		})
+	}
+	return trafficTargets
+}
+
 func semanticEquals(desiredService, service *knservingv1.Service) bool {
-	return equality.Semantic.DeepEqual(desiredService.Spec.ConfigurationSpec, service.Spec.ConfigurationSpec) &&
-		equality.Semantic.DeepEqual(desiredService.ObjectMeta.Labels, service.ObjectMeta.Labels) &&
+	return configurationEquals(desiredService, service) &&
 		equality.Semantic.DeepEqual(desiredService.Spec.RouteSpec, service.Spec.RouteSpec)
 }
+
+// configurationEquals compares the fields set by the controller only, the desired spec hash tells when the
+// controller unsets a field
+func configurationEquals(desiredService, service *knservingv1.Service) bool {
+	return v1beta1utils.FieldsMatch(desiredService.Spec.ConfigurationSpec, service.Spec.ConfigurationSpec) &&
+		v1beta1utils.FieldsMatch(desiredService.ObjectMeta.Labels, service.ObjectMeta.Labels)
+}
@@ -0,0 +1,352 @@
+/*
+Copyright 2020 kubeflow.org.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package knative
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/kubeflow/kfserving/pkg/digest"
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/serving/pkg/apis/autoscaling"
+	knservingv1 "knative.dev/serving/pkg/apis/serving/v1"
+)
+
+func TestKnativeServiceTraffic(t *testing.T) {
+	podSpec := &corev1.PodSpec{
+		Containers: []corev1.Container{
+			{
+				Name:  constants.InferenceServiceContainerName,
+				Image: "tensorflow/serving:1.14.0",
+			},
+		},
+	}
+	scenarios := map[string]struct {
+		annotations     map[string]string
+		componentExt    *v1beta1.ComponentExtensionSpec
+		componentStatus v1beta1.ComponentStatusSpec
+		expected        []knservingv1.TrafficTarget
+	}{
+		"BlueGreen": {
+			annotations:  map[string]string{},
+			componentExt: &v1beta1.ComponentExtensionSpec{},
+			componentStatus: v1beta1.ComponentStatusSpec{
+				LatestReadyRevision:   "revision-v2",
+				PreviousReadyRevision: "revision-v1",
+			},
+			expected: []knservingv1.TrafficTarget{
+				{
+					Tag:            "latest",
+					LatestRevision: proto.Bool(true),
+					Percent:        proto.Int64(100),
+				},
+			},
+		},
+		"Canary": {
+			annotations: map[string]string{},
+			componentExt: &v1beta1.ComponentExtensionSpec{
+				CanaryTrafficPercent: proto.Int64(20),
+			},
+			componentStatus: v1beta1.ComponentStatusSpec{
+				LatestReadyRevision:   "revision-v2",
+				PreviousReadyRevision: "revision-v1",
+			},
+			expected: []knservingv1.TrafficTarget{
+				{
+					Tag:            "latest",
+					LatestRevision: proto.Bool(true),
+					Percent:        proto.Int64(20),
+				},
+				{
+					Tag:            "prev",
+					RevisionName:   "revision-v1",
+					LatestRevision: proto.Bool(false),
+					Percent:        proto.Int64(80),
+				},
+			},
+		},
+		"Rollback": {
+			annotations: map[string]string{
+				constants.RollbackAnnotationKey: "true",
+			},
+			componentExt: &v1beta1.ComponentExtensionSpec{
+				CanaryTrafficPercent: proto.Int64(20),
+			},
+			componentStatus: v1beta1.ComponentStatusSpec{
+				LatestReadyRevision:   "revision-v2",
+				PreviousReadyRevision: "revision-v1",
+			},
+			expected: []knservingv1.TrafficTarget{
+				{
+					Tag:            "latest",
+					LatestRevision: proto.Bool(true),
+					Percent:        proto.Int64(0),
+				},
+				{
+					Tag:            "prev",
+					RevisionName:   "revision-v1",
+					LatestRevision: proto.Bool(false),
+					Percent:        proto.Int64(100),
+				},
+			},
+		},
+		"RollbackWithoutPreviousRevision": {
+			annotations: map[string]string{
+				constants.RollbackAnnotationKey: "true",
+			},
+			componentExt: &v1beta1.ComponentExtensionSpec{},
+			componentStatus: v1beta1.ComponentStatusSpec{
+				LatestReadyRevision: "revision-v1",
+			},
+			expected: []knservingv1.TrafficTarget{
+				{
+					Tag:            "latest",
+					LatestRevision: proto.Bool(true),
+					Percent:        proto.Int64(100),
+				},
+			},
+		},
+		"WarmingUp": {
+			annotations: map[string]string{},
+			componentExt: &v1beta1.ComponentExtensionSpec{
+				WarmUp: &v1beta1.WarmUpSpec{URI: "https://example.com/sample.json"},
+			},
+			componentStatus: v1beta1.ComponentStatusSpec{
+				LatestReadyRevision:   "revision-v2",
+				PreviousReadyRevision: "revision-v1",
+				WarmedUpRevision:      "revision-v1",
+			},
+			expected: []knservingv1.TrafficTarget{
+				{
+					Tag:            "latest",
+					RevisionName:   "revision-v1",
+					LatestRevision: proto.Bool(false),
+					Percent:        proto.Int64(100),
+				},
+			},
+		},
+		"RolloutDraining": {
+			annotations: map[string]string{},
+			componentExt: &v1beta1.ComponentExtensionSpec{
+				Rollout: &v1beta1.RolloutSpec{SoakSeconds: 60, DrainSeconds: 30},
+			},
+			componentStatus: v1beta1.ComponentStatusSpec{
+				LatestReadyRevision:   "revision-v2",
+				PreviousReadyRevision: "revision-v1",
+				Rollout: &v1beta1.RolloutStatus{
+					ReadyRevision:    "revision-v2",
+					ServingRevision:  "revision-v2",
+					DrainingRevision: "revision-v1",
+				},
+			},
+			expected: []knservingv1.TrafficTarget{
+				{
+					Tag:            "latest",
+					RevisionName:   "revision-v2",
+					LatestRevision: proto.Bool(false),
+					Percent:        proto.Int64(100),
+				},
+				{
+					Tag:            "drain",
+					RevisionName:   "revision-v1",
+					LatestRevision: proto.Bool(false),
+					Percent:        proto.Int64(0),
+				},
+			},
+		},
+		"CanaryWarmedUp": {
+			annotations: map[string]string{},
+			componentExt: &v1beta1.ComponentExtensionSpec{
+				CanaryTrafficPercent: proto.Int64(20),
+				WarmUp:               &v1beta1.WarmUpSpec{URI: "https://example.com/sample.json"},
+			},
+			componentStatus: v1beta1.ComponentStatusSpec{
+				LatestReadyRevision:   "revision-v2",
+				PreviousReadyRevision: "revision-v1",
+				WarmedUpRevision:      "revision-v2",
+			},
+			expected: []knservingv1.TrafficTarget{
+				{
+					Tag:            "latest",
+					RevisionName:   "revision-v2",
+					LatestRevision: proto.Bool(false),
+					Percent:        proto.Int64(20),
+				},
+				{
+					Tag:            "prev",
+					RevisionName:   "revision-v1",
+					LatestRevision: proto.Bool(false),
+					Percent:        proto.Int64(80),
+				},
+			},
+		},
+		"CanaryAnalysisProgressing": {
+			annotations: map[string]string{},
+			componentExt: &v1beta1.ComponentExtensionSpec{
+				CanaryAnalysis: &v1beta1.CanaryAnalysis{Steps: []int64{10, 50}},
+			},
+			componentStatus: v1beta1.ComponentStatusSpec{
+				LatestReadyRevision:   "revision-v2",
+				PreviousReadyRevision: "revision-v1",
+				CanaryAnalysis: &v1beta1.CanaryAnalysisStatus{
+					Revision: "revision-v2",
+					Phase:    v1beta1.CanaryProgressing,
+					Step:     1,
+				},
+			},
+			expected: []knservingv1.TrafficTarget{
+				{
+					Tag:            "latest",
+					LatestRevision: proto.Bool(true),
+					Percent:        proto.Int64(50),
+				},
+				{
+					Tag:            "prev",
+					RevisionName:   "revision-v1",
+					LatestRevision: proto.Bool(false),
+					Percent:        proto.Int64(50),
+				},
+			},
+		},
+		"CanaryAnalysisRolledBack": {
+			annotations: map[string]string{},
+			componentExt: &v1beta1.ComponentExtensionSpec{
+				CanaryAnalysis: &v1beta1.CanaryAnalysis{Steps: []int64{10, 50}},
+			},
+			componentStatus: v1beta1.ComponentStatusSpec{
+				LatestReadyRevision:   "revision-v2",
+				PreviousReadyRevision: "revision-v1",
+				CanaryAnalysis: &v1beta1.CanaryAnalysisStatus{
+					Revision: "revision-v2",
+					Phase:    v1beta1.CanaryRolledBack,
+				},
+			},
+			expected: []knservingv1.TrafficTarget{
+				{
+					Tag:            "latest",
+					LatestRevision: proto.Bool(true),
+					Percent:        proto.Int64(0),
+				},
+				{
+					Tag:            "prev",
+					RevisionName:   "revision-v1",
+					LatestRevision: proto.Bool(false),
+					Percent:        proto.Int64(100),
+				},
+			},
+		},
+	}
+
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			g := gomega.NewGomegaWithT(t)
+			componentMeta := metav1.ObjectMeta{
+				Name:        "foo-predictor-default",
+				Namespace:   "default",
+				Annotations: scenario.annotations,
+			}
+			service := createKnativeService(componentMeta, scenario.componentExt, podSpec, scenario.componentStatus)
+			g.Expect(service.Spec.Traffic).To(gomega.Equal(scenario.expected))
+			g.Expect(service.Spec.Template.Annotations).NotTo(gomega.HaveKey(constants.RollbackAnnotationKey))
+		})
+	}
+}
+
+func TestKnativeServiceScale(t *testing.T) {
+	podSpec := &corev1.PodSpec{
+		Containers: []corev1.Container{
+			{
+				Name:  constants.InferenceServiceContainerName,
+				Image: "tensorflow/serving:1.14.0",
+			},
+		},
+	}
+	scenarios := map[string]struct {
+		componentExt *v1beta1.ComponentExtensionSpec
+		minScale     string
+		maxScale     string
+	}{
+		"WithinRange": {
+			componentExt: &v1beta1.ComponentExtensionSpec{
+				MinReplicas: v1beta1.GetIntReference(2),
+				MaxReplicas: 3,
+			},
+			minScale: "2",
+			maxScale: "3",
+		},
+		"ScaledAboveMaxReplicas": {
+			componentExt: &v1beta1.ComponentExtensionSpec{
+				MinReplicas: v1beta1.GetIntReference(5),
+				MaxReplicas: 3,
+			},
+			minScale: "5",
+			maxScale: "5",
+		},
+	}
+
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			g := gomega.NewGomegaWithT(t)
+			componentMeta := metav1.ObjectMeta{
+				Name:        "foo-predictor-default",
+				Namespace:   "default",
+				Annotations: map[string]string{},
+			}
+			service := createKnativeService(componentMeta, scenario.componentExt, podSpec, v1beta1.ComponentStatusSpec{})
+			g.Expect(service.Spec.Template.Annotations).To(gomega.HaveKeyWithValue(autoscaling.MinScaleAnnotationKey, scenario.minScale))
+			g.Expect(service.Spec.Template.Annotations).To(gomega.HaveKeyWithValue(autoscaling.MaxScaleAnnotationKey, scenario.maxScale))
+		})
+	}
+}
+
+func TestKnativeServicePinDigests(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	pinned := "gcr.io/models/sklearn@sha256:4b1a0d8dd3e6b5a8f4d1e0b2c4d5e6f708192a3b4c5d6e7f8091a2b3c4d5e6f7"
+	newService := func(image string, annotations map[string]string) *knservingv1.Service {
+		return &knservingv1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "sklearn-predictor-default", Namespace: "default"},
+			Spec: knservingv1.ServiceSpec{
+				ConfigurationSpec: knservingv1.ConfigurationSpec{
+					Template: knservingv1.RevisionTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{Annotations: annotations},
+						Spec: knservingv1.RevisionSpec{
+							PodSpec: corev1.PodSpec{
+								Containers: []corev1.Container{{Name: constants.InferenceServiceContainerName, Image: image}},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+	// the registry is not reachable, the digest of the unchanged image is reused
+	reconciler := &KsvcReconciler{Digests: digest.NewPinner(nil, &v1beta1.DigestPinningConfig{Enabled: true,
+		TimeoutSeconds: 1})}
+	tags := map[string]string{constants.ImageTagsAnnotationKey: constants.InferenceServiceContainerName + "=gcr.io/models/sklearn:v0.5.0"}
+	desired := newService("gcr.io/models/sklearn:v0.5.0", nil)
+	g.Expect(reconciler.pinDigests(desired, newService(pinned, tags))).Should(gomega.Succeed())
+	g.Expect(desired.Spec.Template.Spec.Containers[0].Image).To(gomega.Equal(pinned))
+	g.Expect(desired.Spec.Template.Annotations).To(gomega.Equal(tags))
+
+	// the digests are left untouched when the digest pinning is not enabled
+	reconciler = &KsvcReconciler{}
+	desired = newService("gcr.io/models/sklearn:v0.5.0", nil)
+	g.Expect(reconciler.pinDigests(desired, newService(pinned, tags))).Should(gomega.Succeed())
+	g.Expect(desired.Spec.Template.Spec.Containers[0].Image).To(gomega.Equal("gcr.io/models/sklearn:v0.5.0"))
+	g.Expect(desired.Spec.Template.Annotations).To(gomega.BeNil())
+}
@@ -0,0 +1,238 @@
+/*
+Copyright 2020 kubeflow.org.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package knative
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/apis"
+	"knative.dev/serving/pkg/apis/serving"
+	knservingv1 "knative.dev/serving/pkg/apis/serving/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// GarbageCollectionReader reads the revisions, configurations, routes and route services collected in the namespace of
+// the knative service, it is set by the manager to read them directly rather than with informers of all the namespaces.
+// The client of the reconciler is used when it is nil.
+var GarbageCollectionReader client.Reader
+
+// GarbageCollectionInterval is the period of the garbage collection of the revisions while the latest ready revision
+// does not change, the revisions released by a rollout or a canary analysis and the stale tags are collected on the next
+// reconcile after it
+const GarbageCollectionInterval = 10 * time.Minute
+
+// CollectGarbage applies the revision retention of the component. The old revisions beyond the retention are deleted,
+// with the tagged routes of the knative service which no longer have a traffic target, and the configuration and
+// route named after the knative service when it does not own them, e.g. after the service was deleted with orphaned
+// dependents, as knative can not adopt them. The revisions are collected once the latest ready revision of the
+// component status changed or the garbage collection interval elapsed since the collection recorded in the status.
+func (r *KsvcReconciler) CollectGarbage(statusSpec *v1beta1.ComponentStatusSpec, now time.Time) error {
+	retention := r.componentExt.RevisionRetention
+	if retention == nil {
+		statusSpec.RevisionCollection = nil
+		return nil
+	}
+	if collection := statusSpec.RevisionCollection; collection != nil &&
+		collection.LatestReadyRevision == statusSpec.LatestReadyRevision &&
+		now.Sub(collection.CollectionTime.Time) < GarbageCollectionInterval {
+		return nil
+	}
+	if err := r.collectGarbage(retention); err != nil {
+		return err
+	}
+	statusSpec.RevisionCollection = &v1beta1.RevisionCollectionStatus{
+		LatestReadyRevision: statusSpec.LatestReadyRevision,
+		CollectionTime:      metav1.NewTime(now),
+	}
+	return nil
+}
+
+func (r *KsvcReconciler) collectGarbage(retention *v1beta1.RevisionRetentionSpec) error {
+	service := &knservingv1.Service{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: r.Service.Name, Namespace: r.Service.Namespace},
+		service); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if err := r.deleteOrphans(service); err != nil {
+		return err
+	}
+	if err := r.deleteStaleTags(service); err != nil {
+		return err
+	}
+	revisions := &knservingv1.RevisionList{}
+	if err := r.reader().List(context.TODO(), revisions, client.InNamespace(service.Namespace),
+		client.MatchingLabels{serving.ServiceLabelKey: service.Name}); err != nil {
+		return errors.Wrapf(err, "fails to list revisions")
+	}
+	for _, revision := range staleRevisions(revisions.Items, retention.GetKeepReadyRevisions(),
+		r.protectedRevisions(service)) {
+		log.Info("Deleting stale revision", "namespace", revision.Namespace, "name", revision.Name)
+		if err := r.client.Delete(context.TODO(), revision); client.IgnoreNotFound(err) != nil {
+			return errors.Wrapf(err, "fails to delete revision")
+		}
+	}
+	return nil
+}
+
+// reader returns the reader of the collected resources
+func (r *KsvcReconciler) reader() client.Reader {
+	if GarbageCollectionReader != nil {
+		return GarbageCollectionReader
+	}
+	return r.client
+}
+
+// protectedRevisions returns the revisions routed by the knative service or referenced by the component status
+func (r *KsvcReconciler) protectedRevisions(service *knservingv1.Service) map[string]bool {
+	names := []string{
+		service.Status.LatestReadyRevisionName,
+		service.Status.LatestCreatedRevisionName,
+		r.componentStatus.LatestReadyRevision,
+		r.componentStatus.PreviousReadyRevision,
+		r.componentStatus.LatestCreatedRevision,
+		r.componentStatus.WarmedUpRevision,
+		r.componentStatus.SchemaRevision,
+	}
+	if rollout := r.componentStatus.Rollout; rollout != nil {
+		names = append(names, rollout.ReadyRevision, rollout.ServingRevision, rollout.DrainingRevision)
+	}
+	if analysis := r.componentStatus.CanaryAnalysis; analysis != nil {
+		names = append(names, analysis.Revision)
+	}
+	for _, target := range append(service.Spec.Traffic, service.Status.Traffic...) {
+		names = append(names, target.RevisionName)
+	}
+	protected := map[string]bool{}
+	for _, name := range names {
+		if name != "" {
+			protected[name] = true
+		}
+	}
+	return protected
+}
+
+// staleRevisions returns the revisions beyond the latest ready revisions kept, the revisions which are not ready
+// are stale once a later revision is ready, the later ones may still become ready
+func staleRevisions(revisions []knservingv1.Revision, keep int, protected map[string]bool) []*knservingv1.Revision {
+	sorted := make([]*knservingv1.Revision, len(revisions))
+	for i := range revisions {
+		sorted[i] = &revisions[i]
+	}
+	// newest first
+	sort.SliceStable(sorted, func(i, j int) bool {
+		gi, gj := revisionGeneration(sorted[i]), revisionGeneration(sorted[j])
+		if gi != gj {
+			return gi > gj
+		}
+		return sorted[j].CreationTimestamp.Before(&sorted[i].CreationTimestamp)
+	})
+	var stale []*knservingv1.Revision
+	readySeen, kept := false, 0
+	for _, revision := range sorted {
+		ready := revision.Status.GetCondition(apis.ConditionReady).IsTrue()
+		keepRevision := false
+		switch {
+		case protected[revision.Name]:
+			keepRevision = true
+		case ready:
+			keepRevision = kept < keep
+		default:
+			keepRevision = !readySeen
+		}
+		if ready {
+			readySeen = true
+			if keepRevision {
+				kept++
+			}
+		}
+		if !keepRevision {
+			stale = append(stale, revision)
+		}
+	}
+	return stale
+}
+
+// revisionGeneration returns the generation of the configuration the revision was created from
+func revisionGeneration(revision *knservingv1.Revision) int64 {
+	generation, err := strconv.ParseInt(revision.Labels[serving.ConfigurationGenerationLabelKey], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return generation
+}
+
+// deleteOrphans deletes the configuration and the route named after the knative service which it does not control,
+// knative recreates them for the service
+func (r *KsvcReconciler) deleteOrphans(service *knservingv1.Service) error {
+	dependents := map[string]runtime.Object{
+		"configuration": &knservingv1.Configuration{},
+		"route":         &knservingv1.Route{},
+	}
+	for kind, orphan := range dependents {
+		if err := r.reader().Get(context.TODO(), types.NamespacedName{Name: service.Name, Namespace: service.Namespace},
+			orphan); err != nil {
+			if client.IgnoreNotFound(err) != nil {
+				return errors.Wrapf(err, "fails to get knative %s", kind)
+			}
+			continue
+		}
+		object, ok := orphan.(metav1.Object)
+		if !ok || metav1.IsControlledBy(object, service) {
+			continue
+		}
+		log.Info("Deleting orphaned knative "+kind, "namespace", object.GetNamespace(), "name", object.GetName())
+		if err := r.client.Delete(context.TODO(), orphan); client.IgnoreNotFound(err) != nil {
+			return errors.Wrapf(err, "fails to delete orphaned knative %s", kind)
+		}
+	}
+	return nil
+}
+
+// deleteStaleTags deletes the placeholder services of the tagged routes of the knative service which no longer have
+// a traffic target, the services of the tags are named <tag>-<route>
+func (r *KsvcReconciler) deleteStaleTags(service *knservingv1.Service) error {
+	tags := map[string]bool{}
+	for _, target := range append(service.Spec.Traffic, service.Status.Traffic...) {
+		if target.Tag != "" {
+			tags[target.Tag] = true
+		}
+	}
+	services := &corev1.ServiceList{}
+	if err := r.reader().List(context.TODO(), services, client.InNamespace(service.Namespace),
+		client.MatchingLabels{serving.RouteLabelKey: service.Name}); err != nil {
+		return errors.Wrapf(err, "fails to list route services")
+	}
+	for i := range services.Items {
+		tagged := &services.Items[i]
+		tag := strings.TrimSuffix(tagged.Name, "-"+service.Name)
+		if tag == tagged.Name || tags[tag] {
+			continue
+		}
+		log.Info("Deleting stale tagged route service", "namespace", tagged.Namespace, "name", tagged.Name, "tag", tag)
+		if err := r.client.Delete(context.TODO(), tagged); client.IgnoreNotFound(err) != nil {
+			return errors.Wrapf(err, "fails to delete tagged route service")
+		}
+	}
+	return nil
+}
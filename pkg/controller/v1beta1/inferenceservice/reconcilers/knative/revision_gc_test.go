@@ -0,0 +1,167 @@
+/*
+Copyright 2020 kubeflow.org.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package knative
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	"knative.dev/serving/pkg/apis/serving"
+	knservingv1 "knative.dev/serving/pkg/apis/serving/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestStaleRevisions(t *testing.T) {
+	newRevision := func(generation int, ready corev1.ConditionStatus) knservingv1.Revision {
+		return knservingv1.Revision{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   fmt.Sprintf("foo-predictor-default-%05d", generation),
+				Labels: map[string]string{serving.ConfigurationGenerationLabelKey: fmt.Sprint(generation)},
+			},
+			Status: knservingv1.RevisionStatus{
+				Status: duckv1.Status{
+					Conditions: duckv1.Conditions{{Type: apis.ConditionReady, Status: ready}},
+				},
+			},
+		}
+	}
+	scenarios := map[string]struct {
+		revisions []knservingv1.Revision
+		keep      int
+		protected map[string]bool
+		expected  []string
+	}{
+		"KeepLatestReady": {
+			revisions: []knservingv1.Revision{
+				newRevision(1, corev1.ConditionTrue),
+				newRevision(4, corev1.ConditionTrue),
+				newRevision(2, corev1.ConditionTrue),
+				newRevision(3, corev1.ConditionTrue),
+			},
+			keep:      2,
+			protected: map[string]bool{},
+			expected:  []string{"foo-predictor-default-00002", "foo-predictor-default-00001"},
+		},
+		"KeepProtected": {
+			revisions: []knservingv1.Revision{
+				newRevision(1, corev1.ConditionTrue),
+				newRevision(2, corev1.ConditionTrue),
+				newRevision(3, corev1.ConditionTrue),
+			},
+			keep:      1,
+			protected: map[string]bool{"foo-predictor-default-00001": true},
+			expected:  []string{"foo-predictor-default-00002"},
+		},
+		"DeleteFailedBeforeLatestReady": {
+			revisions: []knservingv1.Revision{
+				newRevision(1, corev1.ConditionTrue),
+				newRevision(2, corev1.ConditionFalse),
+				newRevision(3, corev1.ConditionTrue),
+				newRevision(4, corev1.ConditionUnknown),
+			},
+			keep:      3,
+			protected: map[string]bool{},
+			expected:  []string{"foo-predictor-default-00002"},
+		},
+	}
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			g := gomega.NewGomegaWithT(t)
+			var stale []string
+			for _, revision := range staleRevisions(scenario.revisions, scenario.keep, scenario.protected) {
+				stale = append(stale, revision.Name)
+			}
+			g.Expect(stale).To(gomega.Equal(scenario.expected))
+		})
+	}
+}
+
+func TestCollectGarbageInterval(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scheme := runtime.NewScheme()
+	g.Expect(corev1.AddToScheme(scheme)).Should(gomega.Succeed())
+	g.Expect(knservingv1.AddToScheme(scheme)).Should(gomega.Succeed())
+	newRevision := func(generation int) *knservingv1.Revision {
+		return &knservingv1.Revision{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("foo-predictor-default-%05d", generation),
+				Namespace: "default",
+				Labels: map[string]string{
+					serving.ServiceLabelKey:                 "foo-predictor-default",
+					serving.ConfigurationGenerationLabelKey: fmt.Sprint(generation),
+				},
+			},
+			Status: knservingv1.RevisionStatus{
+				Status: duckv1.Status{
+					Conditions: duckv1.Conditions{{Type: apis.ConditionReady, Status: corev1.ConditionTrue}},
+				},
+			},
+		}
+	}
+	service := &knservingv1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo-predictor-default", Namespace: "default"},
+		Status: knservingv1.ServiceStatus{
+			ConfigurationStatusFields: knservingv1.ConfigurationStatusFields{
+				LatestReadyRevisionName:   "foo-predictor-default-00002",
+				LatestCreatedRevisionName: "foo-predictor-default-00002",
+			},
+		},
+	}
+	c := fake.NewFakeClientWithScheme(scheme, service, newRevision(1), newRevision(2))
+	keep := 1
+	r := &KsvcReconciler{
+		client:       c,
+		Service:      service,
+		componentExt: &v1beta1.ComponentExtensionSpec{RevisionRetention: &v1beta1.RevisionRetentionSpec{KeepReadyRevisions: &keep}},
+	}
+	revisionExists := func(generation int) bool {
+		return c.Get(context.TODO(), types.NamespacedName{Name: newRevision(generation).Name, Namespace: "default"},
+			&knservingv1.Revision{}) == nil
+	}
+
+	// the revisions are collected once the latest ready revision changed
+	now := time.Now()
+	statusSpec := v1beta1.ComponentStatusSpec{LatestReadyRevision: "foo-predictor-default-00002"}
+	g.Expect(r.CollectGarbage(&statusSpec, now)).Should(gomega.Succeed())
+	g.Expect(revisionExists(1)).To(gomega.BeFalse())
+	g.Expect(statusSpec.RevisionCollection).To(gomega.Equal(&v1beta1.RevisionCollectionStatus{
+		LatestReadyRevision: "foo-predictor-default-00002",
+		CollectionTime:      metav1.NewTime(now),
+	}))
+
+	// the revisions are not collected again within the interval
+	g.Expect(c.Create(context.TODO(), newRevision(1))).Should(gomega.Succeed())
+	g.Expect(r.CollectGarbage(&statusSpec, now.Add(time.Minute))).Should(gomega.Succeed())
+	g.Expect(revisionExists(1)).To(gomega.BeTrue())
+
+	// the revisions are collected again once the interval elapsed
+	g.Expect(r.CollectGarbage(&statusSpec, now.Add(GarbageCollectionInterval))).Should(gomega.Succeed())
+	g.Expect(revisionExists(1)).To(gomega.BeFalse())
+	g.Expect(revisionExists(2)).To(gomega.BeTrue())
+
+	// the collection is cleared once the component has no revision retention
+	r.componentExt.RevisionRetention = nil
+	g.Expect(r.CollectGarbage(&statusSpec, now)).Should(gomega.Succeed())
+	g.Expect(statusSpec.RevisionCollection).To(gomega.BeNil())
+}
@@ -18,6 +18,9 @@ package multimodelconfig
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	v1alpha1api "github.com/kubeflow/kfserving/pkg/apis/serving/v1alpha1"
 	v1beta1api "github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
 	"github.com/kubeflow/kfserving/pkg/constants"
 	"github.com/kubeflow/kfserving/pkg/controller/v1alpha1/trainedmodel/sharding/memory"
@@ -30,6 +33,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+	"sort"
+	"strings"
 )
 
 var log = logf.Log.WithName("Reconciler")
@@ -75,6 +80,100 @@ func (c *ModelConfigReconciler) Reconcile(isvc *v1beta1api.InferenceService) err
 				}
 			}
 		}
+		return c.reconcileEnsemble(isvc, constants.ModelConfigName(isvc.Name, shardStrategy.GetShard(isvc)[0]))
 	}
 	return nil
 }
+
+// reconcileEnsemble adds the models of the ensemble to the modelConfig so that the agent loads them in the model
+// server, the models are recorded on the modelConfig to remove the ones dropped from the ensemble without removing
+// the trained models
+func (c *ModelConfigReconciler) reconcileEnsemble(isvc *v1beta1api.InferenceService, name string) error {
+	modelConfig := &corev1.ConfigMap{}
+	if err := c.client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: isvc.Namespace}, modelConfig); err != nil {
+		return err
+	}
+	previous := modelConfig.Annotations[constants.EnsembleModelsInternalAnnotationKey]
+	var updatedConfigs modelconfig.ModelConfigs
+	var names []string
+	if ensemble := isvc.Spec.Predictor.Ensemble; ensemble != nil {
+		for _, model := range ensemble.Models {
+			updatedConfigs = append(updatedConfigs, modelconfig.ModelConfig{
+				Name: model.Name,
+				Spec: v1alpha1api.ModelSpec{
+					StorageURI: model.StorageURI,
+					Framework:  ensembleFramework(&isvc.Spec.Predictor),
+					Memory:     model.Memory,
+				},
+			})
+			names = append(names, model.Name)
+		}
+	}
+	sort.Strings(names)
+	current := strings.Join(names, ",")
+	if previous == current && loaded(modelConfig, updatedConfigs) {
+		return nil
+	}
+	var deletedConfigs []string
+	for _, model := range strings.Split(previous, ",") {
+		if model != "" && !contains(names, model) {
+			deletedConfigs = append(deletedConfigs, model)
+		}
+	}
+	if err := modelconfig.NewConfigsDelta(updatedConfigs, deletedConfigs).Process(modelConfig); err != nil {
+		return fmt.Errorf("can not update the ensemble models of %s because of error %v", name, err)
+	}
+	if current == "" {
+		delete(modelConfig.Annotations, constants.EnsembleModelsInternalAnnotationKey)
+	} else {
+		if modelConfig.Annotations == nil {
+			modelConfig.Annotations = map[string]string{}
+		}
+		modelConfig.Annotations[constants.EnsembleModelsInternalAnnotationKey] = current
+	}
+	log.Info("Updating ensemble models", "configmap", name, "models", current, "removed", deletedConfigs)
+	return c.client.Update(context.TODO(), modelConfig)
+}
+
+// ensembleFramework returns the framework of the ensemble models served by the predictor
+func ensembleFramework(predictor *v1beta1api.PredictorSpec) string {
+	switch {
+	case predictor.SKLearn != nil:
+		return "sklearn"
+	case predictor.XGBoost != nil:
+		return "xgboost"
+	}
+	return "triton"
+}
+
+// loaded returns true when the modelConfig has the model configs already
+func loaded(modelConfig *corev1.ConfigMap, configs modelconfig.ModelConfigs) bool {
+	if len(configs) == 0 {
+		return true
+	}
+	existing := modelconfig.ModelConfigs{}
+	if err := json.Unmarshal([]byte(modelConfig.Data[constants.ModelConfigFileName]), &existing); err != nil {
+		return false
+	}
+	specs := map[string]v1alpha1api.ModelSpec{}
+	for _, config := range existing {
+		specs[config.Name] = config.Spec
+	}
+	for _, config := range configs {
+		spec, ok := specs[config.Name]
+		if !ok || spec.StorageURI != config.Spec.StorageURI || spec.Framework != config.Spec.Framework ||
+			spec.Memory.Cmp(config.Spec.Memory) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
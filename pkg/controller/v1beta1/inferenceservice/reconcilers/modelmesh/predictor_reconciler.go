@@ -0,0 +1,180 @@
+/*
+Copyright 2020 kubeflow.org.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package modelmesh
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+var log = logf.Log.WithName("ModelMeshReconciler")
+
+// PredictorGVK is the kind of the ModelMesh serving predictor, the types are not vendored so the
+// predictor is handled as an unstructured object.
+var PredictorGVK = schema.GroupVersionKind{
+	Group:   "serving.kserve.io",
+	Version: "v1alpha1",
+	Kind:    "Predictor",
+}
+
+// PredictorStatus is the subset of the ModelMesh predictor status reflected on the InferenceService
+type PredictorStatus struct {
+	Available        bool         `json:"available"`
+	ActiveModelState string       `json:"activeModelState,omitempty"`
+	TargetModelState string       `json:"targetModelState,omitempty"`
+	TransitionStatus string       `json:"transitionStatus,omitempty"`
+	LastFailureInfo  *FailureInfo `json:"lastFailureInfo,omitempty"`
+	HTTPEndpoint     string       `json:"httpEndpoint,omitempty"`
+	GrpcEndpoint     string       `json:"grpcEndpoint,omitempty"`
+}
+
+// FailureInfo is the last loading failure of the ModelMesh predictor
+type FailureInfo struct {
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// PredictorReconciler reconciles the ModelMesh predictor of an InferenceService
+type PredictorReconciler struct {
+	client    client.Client
+	scheme    *runtime.Scheme
+	Predictor *unstructured.Unstructured
+}
+
+func NewPredictorReconciler(client client.Client, scheme *runtime.Scheme, componentMeta metav1.ObjectMeta,
+	predictor *v1beta1.PredictorSpec) (*PredictorReconciler, error) {
+	desired, err := createPredictor(componentMeta, predictor)
+	if err != nil {
+		return nil, err
+	}
+	return &PredictorReconciler{
+		client:    client,
+		scheme:    scheme,
+		Predictor: desired,
+	}, nil
+}
+
+// modelType maps the predictor framework to the model type served by the ModelMesh runtimes
+func modelType(predictor *v1beta1.PredictorSpec) (string, error) {
+	switch {
+	case predictor.SKLearn != nil:
+		return "sklearn", nil
+	case predictor.XGBoost != nil:
+		return "xgboost", nil
+	case predictor.Tensorflow != nil:
+		return "tensorflow", nil
+	case predictor.PyTorch != nil:
+		return "pytorch", nil
+	case predictor.ONNX != nil:
+		return "onnx", nil
+	case predictor.Triton != nil:
+		return "triton", nil
+	}
+	return "", fmt.Errorf("the predictor framework is not supported by %s deployment mode", constants.ModelMeshDeployment)
+}
+
+func createPredictor(componentMeta metav1.ObjectMeta, predictor *v1beta1.PredictorSpec) (*unstructured.Unstructured, error) {
+	name, err := modelType(predictor)
+	if err != nil {
+		return nil, err
+	}
+	storageURI := predictor.GetImplementation().GetStorageUri()
+	if storageURI == nil || !strings.HasPrefix(*storageURI, "s3://") {
+		return nil, fmt.Errorf("%s deployment mode requires an s3:// storageUri", constants.ModelMeshDeployment)
+	}
+	// s3://bucket/path/to/model
+	bucketAndPath := strings.SplitN(strings.TrimPrefix(*storageURI, "s3://"), "/", 2)
+	if len(bucketAndPath) != 2 || bucketAndPath[0] == "" || bucketAndPath[1] == "" {
+		return nil, fmt.Errorf("storageUri %s must include the bucket and the model path", *storageURI)
+	}
+	s3 := map[string]interface{}{
+		"bucket": bucketAndPath[0],
+	}
+	if secretKey, ok := componentMeta.Annotations[constants.ModelMeshStorageSecretKeyAnnotationKey]; ok {
+		s3["secretKey"] = secretKey
+	}
+
+	desired := &unstructured.Unstructured{}
+	desired.SetGroupVersionKind(PredictorGVK)
+	desired.SetName(componentMeta.Name)
+	desired.SetNamespace(componentMeta.Namespace)
+	desired.SetLabels(componentMeta.Labels)
+	desired.SetAnnotations(componentMeta.Annotations)
+	desired.Object["spec"] = map[string]interface{}{
+		"modelType": map[string]interface{}{
+			"name": name,
+		},
+		"path": bucketAndPath[1],
+		"storage": map[string]interface{}{
+			"s3": s3,
+		},
+	}
+	return desired, nil
+}
+
+// Reconcile creates or updates the ModelMesh predictor and returns its observed status
+func (r *PredictorReconciler) Reconcile() (*PredictorStatus, error) {
+	desired := r.Predictor
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(PredictorGVK)
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: desired.GetName(), Namespace: desired.GetNamespace()}, existing)
+	if err != nil {
+		if apierr.IsNotFound(err) {
+			log.Info("Creating modelmesh predictor", "namespace", desired.GetNamespace(), "name", desired.GetName())
+			return &PredictorStatus{}, r.client.Create(context.TODO(), desired)
+		}
+		return nil, err
+	}
+	if !equality.Semantic.DeepEqual(desired.Object["spec"], existing.Object["spec"]) ||
+		!equality.Semantic.DeepEqual(desired.GetLabels(), existing.GetLabels()) {
+		existing.Object["spec"] = desired.Object["spec"]
+		existing.SetLabels(desired.GetLabels())
+		err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			log.Info("Updating modelmesh predictor", "namespace", desired.GetNamespace(), "name", desired.GetName())
+			return r.client.Update(context.TODO(), existing)
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "fails to update modelmesh predictor")
+		}
+	}
+	return predictorStatus(existing)
+}
+
+// predictorStatus converts the status of the unstructured predictor
+func predictorStatus(predictor *unstructured.Unstructured) (*PredictorStatus, error) {
+	status := &PredictorStatus{}
+	object, ok, err := unstructured.NestedMap(predictor.Object, "status")
+	if err != nil || !ok {
+		return status, err
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object, status); err != nil {
+		return nil, errors.Wrapf(err, "fails to parse modelmesh predictor status")
+	}
+	return status, nil
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2020 kubeflow.org.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package modelmesh
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestCreatePredictor(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	componentMeta := metav1.ObjectMeta{
+		Name:        "sklearn-iris",
+		Namespace:   "default",
+		Annotations: map[string]string{constants.ModelMeshStorageSecretKeyAnnotationKey: "localMinIO"},
+	}
+	predictor := &v1beta1.PredictorSpec{
+		SKLearn: &v1beta1.SKLearnSpec{
+			PredictorExtensionSpec: v1beta1.PredictorExtensionSpec{
+				StorageURI: proto.String("s3://modelmesh-example-models/sklearn/mnist-svm.joblib"),
+			},
+		},
+	}
+	desired, err := createPredictor(componentMeta, predictor)
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(desired.GroupVersionKind()).To(gomega.Equal(PredictorGVK))
+	g.Expect(desired.GetName()).To(gomega.Equal("sklearn-iris"))
+	g.Expect(desired.Object["spec"]).To(gomega.Equal(map[string]interface{}{
+		"modelType": map[string]interface{}{
+			"name": "sklearn",
+		},
+		"path": "sklearn/mnist-svm.joblib",
+		"storage": map[string]interface{}{
+			"s3": map[string]interface{}{
+				"bucket":    "modelmesh-example-models",
+				"secretKey": "localMinIO",
+			},
+		},
+	}))
+}
+
+func TestCreatePredictorErrors(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scenarios := map[string]*v1beta1.PredictorSpec{
+		"UnsupportedFramework": {
+			PMML: &v1beta1.PMMLSpec{
+				PredictorExtensionSpec: v1beta1.PredictorExtensionSpec{
+					StorageURI: proto.String("s3://bucket/model.pmml"),
+				},
+			},
+		},
+		"UnsupportedStorage": {
+			SKLearn: &v1beta1.SKLearnSpec{
+				PredictorExtensionSpec: v1beta1.PredictorExtensionSpec{
+					StorageURI: proto.String("gs://bucket/model.joblib"),
+				},
+			},
+		},
+		"MissingPath": {
+			SKLearn: &v1beta1.SKLearnSpec{
+				PredictorExtensionSpec: v1beta1.PredictorExtensionSpec{
+					StorageURI: proto.String("s3://bucket"),
+				},
+			},
+		},
+	}
+	for name, predictor := range scenarios {
+		_, err := createPredictor(metav1.ObjectMeta{Name: "foo", Namespace: "default"}, predictor)
+		g.Expect(err).ShouldNot(gomega.BeNil(), name)
+	}
+}
+
+func TestPredictorStatus(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	predictor := &unstructured.Unstructured{Object: map[string]interface{}{
+		"status": map[string]interface{}{
+			"available":        true,
+			"activeModelState": "Loaded",
+			"targetModelState": "",
+			"transitionStatus": "UpToDate",
+			"grpcEndpoint":     "grpc://modelmesh-serving.default:8033",
+			"httpEndpoint":     "http://modelmesh-serving.default:8008",
+		},
+	}}
+	status, err := predictorStatus(predictor)
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(*status).To(gomega.Equal(PredictorStatus{
+		Available:        true,
+		ActiveModelState: "Loaded",
+		TransitionStatus: "UpToDate",
+		GrpcEndpoint:     "grpc://modelmesh-serving.default:8033",
+		HTTPEndpoint:     "http://modelmesh-serving.default:8008",
+	}))
+
+	status, err = predictorStatus(&unstructured.Unstructured{Object: map[string]interface{}{}})
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(status.Available).To(gomega.BeFalse())
+}
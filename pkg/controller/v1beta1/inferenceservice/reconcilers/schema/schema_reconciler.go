@@ -0,0 +1,148 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package schema fetches the v2 metadata of the predictor model into the configmap mounted by the logger, which
+// validates the inference requests against it.
+package schema
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/kubeflow/kfserving/pkg/modelschema"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+var log = logf.Log.WithName("SchemaReconciler")
+
+const requestTimeout = 10 * time.Second
+
+type SchemaReconciler struct {
+	client     client.Client
+	scheme     *runtime.Scheme
+	httpClient *http.Client
+	// RevisionURL returns the base URL of a revision, the cluster local address of its service by default
+	RevisionURL func(revision string, namespace string) string
+}
+
+func NewSchemaReconciler(client client.Client, scheme *runtime.Scheme) *SchemaReconciler {
+	return &SchemaReconciler{
+		client:     client,
+		scheme:     scheme,
+		httpClient: &http.Client{Timeout: requestTimeout},
+		RevisionURL: func(revision string, namespace string) string {
+			return fmt.Sprintf("http://%s.%s.svc.cluster.local", revision, namespace)
+		},
+	}
+}
+
+// Reconcile fetches the model metadata from the latest ready revision of the predictor and records the revision in
+// the predictor status once the schema configmap is up to date. The metadata is fetched again for every new revision
+// as the model may have changed.
+func (r *SchemaReconciler) Reconcile(isvc *v1beta1.InferenceService) error {
+	statusSpec, ok := isvc.Status.Components[v1beta1.PredictorComponent]
+	if !ok {
+		return nil
+	}
+	if !isvc.Spec.Predictor.RequestValidation {
+		statusSpec.SchemaRevision = ""
+		isvc.Status.Components[v1beta1.PredictorComponent] = statusSpec
+		return nil
+	}
+	revision := statusSpec.LatestReadyRevision
+	if revision == "" || revision == statusSpec.SchemaRevision {
+		return nil
+	}
+	url := r.RevisionURL(revision, isvc.Namespace) + "/v2/models/" + isvc.Name
+	log.Info("Fetching model metadata", "namespace", isvc.Namespace, "revision", revision)
+	metadata, err := r.fetch(url)
+	if err != nil {
+		return fmt.Errorf("fails to fetch the model metadata from revision %s: %v", revision, err)
+	}
+	if err := r.apply(isvc, metadata); err != nil {
+		return err
+	}
+	statusSpec.SchemaRevision = revision
+	isvc.Status.Components[v1beta1.PredictorComponent] = statusSpec
+	return nil
+}
+
+// fetch returns the model metadata answered by the v2 protocol metadata endpoint, the response is validated so that
+// the logger does not fail to load it
+func (r *SchemaReconciler) fetch(url string) ([]byte, error) {
+	resp, err := r.httpClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned status %d", url, resp.StatusCode)
+	}
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := modelschema.Parse(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (r *SchemaReconciler) apply(isvc *v1beta1.InferenceService, metadata []byte) error {
+	desired := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      constants.ModelSchemaConfigMapName(isvc.Name),
+			Namespace: isvc.Namespace,
+			Labels: map[string]string{
+				constants.InferenceServicePodLabelKey: isvc.Name,
+			},
+		},
+		Data: map[string]string{
+			constants.ModelSchemaFileName: string(metadata),
+		},
+	}
+	if err := controllerutil.SetControllerReference(isvc, desired, r.scheme); err != nil {
+		return err
+	}
+	existing := &corev1.ConfigMap{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: desired.Name, Namespace: desired.Namespace}, existing)
+	if errors.IsNotFound(err) {
+		log.Info("Creating model schema", "namespace", desired.Namespace, "name", desired.Name)
+		return r.client.Create(context.TODO(), desired)
+	}
+	if err != nil {
+		return err
+	}
+	if equality.Semantic.DeepEqual(desired.Data, existing.Data) {
+		return nil
+	}
+	existing.Data = desired.Data
+	log.Info("Updating model schema", "namespace", desired.Namespace, "name", desired.Name)
+	return r.client.Update(context.TODO(), existing)
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schema
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newInferenceService(latestReadyRevision string, schemaRevision string) *v1beta1.InferenceService {
+	return &v1beta1.InferenceService{
+		ObjectMeta: metav1.ObjectMeta{Name: "sklearn", Namespace: "default", UID: "1234"},
+		Spec: v1beta1.InferenceServiceSpec{
+			Predictor: v1beta1.PredictorSpec{RequestValidation: true},
+		},
+		Status: v1beta1.InferenceServiceStatus{
+			Components: map[v1beta1.ComponentType]v1beta1.ComponentStatusSpec{
+				v1beta1.PredictorComponent: {
+					LatestReadyRevision: latestReadyRevision,
+					SchemaRevision:      schemaRevision,
+				},
+			},
+		},
+	}
+}
+
+func TestSchemaReconciler(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scheme := runtime.NewScheme()
+	g.Expect(v1.AddToScheme(scheme)).Should(gomega.Succeed())
+	g.Expect(v1beta1.AddToScheme(scheme)).Should(gomega.Succeed())
+
+	var requests []string
+	metadata := `{"name":"sklearn","platform":"sklearn","inputs":[{"name":"input-0","datatype":"FP32","shape":[-1,4]}]}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		requests = append(requests, req.URL.Path)
+		if metadata == "" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(metadata))
+	}))
+	defer server.Close()
+
+	c := fake.NewFakeClientWithScheme(scheme)
+	reconciler := NewSchemaReconciler(c, scheme)
+	reconciler.RevisionURL = func(revision string, namespace string) string {
+		return server.URL + "/" + revision
+	}
+	key := types.NamespacedName{Name: constants.ModelSchemaConfigMapName("sklearn"), Namespace: "default"}
+
+	// the metadata of the latest ready revision is written to the schema configmap
+	isvc := newInferenceService("sklearn-predictor-default-00001", "")
+	g.Expect(reconciler.Reconcile(isvc)).To(gomega.Succeed())
+	g.Expect(isvc.Status.Components[v1beta1.PredictorComponent].SchemaRevision).To(gomega.Equal("sklearn-predictor-default-00001"))
+	g.Expect(requests).To(gomega.Equal([]string{"/sklearn-predictor-default-00001/v2/models/sklearn"}))
+	configMap := &v1.ConfigMap{}
+	g.Expect(c.Get(context.TODO(), key, configMap)).To(gomega.Succeed())
+	g.Expect(configMap.Data[constants.ModelSchemaFileName]).To(gomega.Equal(metadata))
+	g.Expect(configMap.OwnerReferences).To(gomega.HaveLen(1))
+
+	// the metadata is not fetched again for the same revision
+	requests = nil
+	g.Expect(reconciler.Reconcile(isvc)).To(gomega.Succeed())
+	g.Expect(requests).To(gomega.BeEmpty())
+
+	// a new revision updates the schema
+	metadata = `{"name":"sklearn","platform":"sklearn","inputs":[{"name":"input-0","datatype":"FP64","shape":[-1,4]}]}`
+	isvc = newInferenceService("sklearn-predictor-default-00002", "sklearn-predictor-default-00001")
+	g.Expect(reconciler.Reconcile(isvc)).To(gomega.Succeed())
+	g.Expect(c.Get(context.TODO(), key, configMap)).To(gomega.Succeed())
+	g.Expect(configMap.Data[constants.ModelSchemaFileName]).To(gomega.Equal(metadata))
+
+	// the schema is kept while the new revision does not answer the metadata request
+	metadata = ""
+	isvc = newInferenceService("sklearn-predictor-default-00003", "sklearn-predictor-default-00002")
+	g.Expect(reconciler.Reconcile(isvc)).NotTo(gomega.Succeed())
+	g.Expect(isvc.Status.Components[v1beta1.PredictorComponent].SchemaRevision).To(gomega.Equal("sklearn-predictor-default-00002"))
+
+	// disabling the validation forgets the schema revision
+	isvc.Spec.Predictor.RequestValidation = false
+	g.Expect(reconciler.Reconcile(isvc)).To(gomega.Succeed())
+	g.Expect(isvc.Status.Components[v1beta1.PredictorComponent].SchemaRevision).To(gomega.BeEmpty())
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package rollout switches the traffic of the components between their revisions. A new revision serves once it has
+// been ready for the soak period, and the previous serving revision stays routable until its drain period elapsed. The
+// knative reconciler routes the traffic according to the rollout status.
+package rollout
+
+import (
+	"time"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+var log = logf.Log.WithName("Rollout")
+
+func soak(rollout *v1beta1.RolloutSpec) time.Duration {
+	return time.Duration(rollout.SoakSeconds) * time.Second
+}
+
+func drain(rollout *v1beta1.RolloutSpec) time.Duration {
+	return time.Duration(rollout.DrainSeconds) * time.Second
+}
+
+// Reconcile records when the latest ready revision of the component became ready, switches the serving revision to
+// it once it soaked and ends the drain of the previous serving revision
+func Reconcile(isvc *v1beta1.InferenceService, component v1beta1.ComponentType, extension *v1beta1.ComponentExtensionSpec,
+	now time.Time) {
+	statusSpec, ok := isvc.Status.Components[component]
+	if !ok {
+		return
+	}
+	rollout := extension.Rollout
+	if rollout == nil {
+		statusSpec.Rollout = nil
+		isvc.Status.Components[component] = statusSpec
+		return
+	}
+	if statusSpec.LatestReadyRevision == "" {
+		return
+	}
+	status := statusSpec.Rollout
+	if status == nil {
+		status = &v1beta1.RolloutStatus{}
+	}
+	revision := statusSpec.LatestReadyRevision
+	if status.ReadyRevision != revision {
+		status.ReadyRevision = revision
+		status.ReadyTime = metav1.NewTime(now)
+	}
+	warmedUp := extension.WarmUp == nil || statusSpec.WarmedUpRevision == revision
+	if status.ServingRevision != revision && warmedUp && !now.Before(status.ReadyTime.Add(soak(rollout))) {
+		log.Info("Switching serving revision", "namespace", isvc.Namespace, "component", component,
+			"revision", revision, "previous", status.ServingRevision)
+		if status.ServingRevision != "" && rollout.DrainSeconds > 0 {
+			status.DrainingRevision = status.ServingRevision
+			status.DrainStartTime = metav1.NewTime(now)
+		}
+		status.ServingRevision = revision
+	}
+	if status.DrainingRevision != "" && !now.Before(status.DrainStartTime.Add(drain(rollout))) {
+		status.DrainingRevision = ""
+		status.DrainStartTime = metav1.Time{}
+	}
+	statusSpec.Rollout = status
+	isvc.Status.Components[component] = statusSpec
+}
+
+// NextCheck returns the delay until the soak of the latest ready revision or the drain of the previous revision ends,
+// or zero if the component has no revision switch in progress
+func NextCheck(extension *v1beta1.ComponentExtensionSpec, statusSpec v1beta1.ComponentStatusSpec, now time.Time) time.Duration {
+	status := statusSpec.Rollout
+	if extension.Rollout == nil || status == nil {
+		return 0
+	}
+	var next time.Duration
+	if status.ServingRevision != status.ReadyRevision {
+		next = status.ReadyTime.Add(soak(extension.Rollout)).Sub(now)
+		if next < time.Second {
+			next = time.Second
+		}
+	}
+	if status.DrainingRevision != "" {
+		drained := status.DrainStartTime.Add(drain(extension.Rollout)).Sub(now)
+		if drained < time.Second {
+			drained = time.Second
+		}
+		if next == 0 || drained < next {
+			next = drained
+		}
+	}
+	return next
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rollout
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newInferenceService(revision string) *v1beta1.InferenceService {
+	return &v1beta1.InferenceService{
+		ObjectMeta: metav1.ObjectMeta{Name: "sklearn", Namespace: "default"},
+		Spec: v1beta1.InferenceServiceSpec{
+			Predictor: v1beta1.PredictorSpec{
+				ComponentExtensionSpec: v1beta1.ComponentExtensionSpec{
+					Rollout: &v1beta1.RolloutSpec{SoakSeconds: 60, DrainSeconds: 30},
+				},
+			},
+		},
+		Status: v1beta1.InferenceServiceStatus{
+			Components: map[v1beta1.ComponentType]v1beta1.ComponentStatusSpec{
+				v1beta1.PredictorComponent: {LatestReadyRevision: revision},
+			},
+		},
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	now := time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC)
+	isvc := newInferenceService("sklearn-predictor-default-00001")
+	extension := &isvc.Spec.Predictor.ComponentExtensionSpec
+
+	// the first revision soaks before it serves
+	Reconcile(isvc, v1beta1.PredictorComponent, extension, now)
+	status := isvc.Status.Components[v1beta1.PredictorComponent]
+	g.Expect(status.Rollout.ReadyRevision).To(gomega.Equal("sklearn-predictor-default-00001"))
+	g.Expect(status.Rollout.ServingRevision).To(gomega.BeEmpty())
+	g.Expect(NextCheck(extension, status, now)).To(gomega.Equal(time.Minute))
+
+	now = now.Add(time.Minute)
+	Reconcile(isvc, v1beta1.PredictorComponent, extension, now)
+	status = isvc.Status.Components[v1beta1.PredictorComponent]
+	g.Expect(status.Rollout.ServingRevision).To(gomega.Equal("sklearn-predictor-default-00001"))
+	g.Expect(status.Rollout.DrainingRevision).To(gomega.BeEmpty())
+	g.Expect(NextCheck(extension, status, now)).To(gomega.BeZero())
+
+	// the new revision soaks while the previous one serves, then the previous one drains
+	status.LatestReadyRevision = "sklearn-predictor-default-00002"
+	isvc.Status.Components[v1beta1.PredictorComponent] = status
+	Reconcile(isvc, v1beta1.PredictorComponent, extension, now)
+	status = isvc.Status.Components[v1beta1.PredictorComponent]
+	g.Expect(status.Rollout.ServingRevision).To(gomega.Equal("sklearn-predictor-default-00001"))
+
+	now = now.Add(time.Minute)
+	Reconcile(isvc, v1beta1.PredictorComponent, extension, now)
+	status = isvc.Status.Components[v1beta1.PredictorComponent]
+	g.Expect(status.Rollout.ServingRevision).To(gomega.Equal("sklearn-predictor-default-00002"))
+	g.Expect(status.Rollout.DrainingRevision).To(gomega.Equal("sklearn-predictor-default-00001"))
+	g.Expect(NextCheck(extension, status, now)).To(gomega.Equal(30 * time.Second))
+
+	now = now.Add(30 * time.Second)
+	Reconcile(isvc, v1beta1.PredictorComponent, extension, now)
+	status = isvc.Status.Components[v1beta1.PredictorComponent]
+	g.Expect(status.Rollout.DrainingRevision).To(gomega.BeEmpty())
+	g.Expect(NextCheck(extension, status, now)).To(gomega.BeZero())
+
+	// the rollout status is cleared when the rollout is removed
+	extension.Rollout = nil
+	Reconcile(isvc, v1beta1.PredictorComponent, extension, now)
+	g.Expect(isvc.Status.Components[v1beta1.PredictorComponent].Rollout).To(gomega.BeNil())
+}
+
+func TestReconcileWaitsForWarmUp(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	now := time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC)
+	isvc := newInferenceService("sklearn-predictor-default-00001")
+	extension := &isvc.Spec.Predictor.ComponentExtensionSpec
+	extension.Rollout.SoakSeconds = 0
+	extension.WarmUp = &v1beta1.WarmUpSpec{URI: "https://example.com/sample.json"}
+
+	Reconcile(isvc, v1beta1.PredictorComponent, extension, now)
+	status := isvc.Status.Components[v1beta1.PredictorComponent]
+	g.Expect(status.Rollout.ServingRevision).To(gomega.BeEmpty())
+	g.Expect(NextCheck(extension, status, now)).To(gomega.Equal(time.Second))
+
+	status.WarmedUpRevision = "sklearn-predictor-default-00001"
+	isvc.Status.Components[v1beta1.PredictorComponent] = status
+	Reconcile(isvc, v1beta1.PredictorComponent, extension, now)
+	g.Expect(isvc.Status.Components[v1beta1.PredictorComponent].Rollout.ServingRevision).
+		To(gomega.Equal("sklearn-predictor-default-00001"))
+}
@@ -1,6 +1,24 @@
 package utils
 
-import v1beta1api "github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+import (
+	"encoding/json"
+	"hash/fnv"
+	"reflect"
+	"strconv"
+	"time"
+
+	v1beta1api "github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/kubeflow/kfserving/pkg/schedule"
+)
+
+// GetDeploymentMode returns the deployment mode selected by the InferenceService annotation, Serverless by default
+func GetDeploymentMode(isvc *v1beta1api.InferenceService) constants.DeploymentModeType {
+	if mode, ok := isvc.Annotations[constants.DeploymentMode]; ok {
+		return constants.DeploymentModeType(mode)
+	}
+	return constants.Serverless
+}
 
 // Only enable MMS predictor for sklearn and xgboost model server
 // TODO should read the InferenceService configmap to decide if MMS should be enabled for this predictor
@@ -10,3 +28,84 @@ func IsMMSPredictor(predictor *v1beta1api.PredictorSpec) bool {
 	}
 	return false
 }
+
+// HashSpec returns a hash of the json serialized spec, it is stored on the generated resources to tell
+// the changes made by the controller apart from out of band changes.
+func HashSpec(spec interface{}) string {
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return ""
+	}
+	hash := fnv.New64a()
+	hash.Write(data)
+	return strconv.FormatUint(hash.Sum64(), 16)
+}
+
+// FieldsMatch returns true if every field set on the desired spec has the same value on the observed spec. The fields
+// only set on the observed spec are ignored, so that the defaults added by the api server and the knative and istio
+// webhooks are not mistaken for out of band changes. Lists must have the same length and match element by element.
+func FieldsMatch(desired interface{}, observed interface{}) bool {
+	var desiredFields, observedFields interface{}
+	if data, err := json.Marshal(desired); err != nil || json.Unmarshal(data, &desiredFields) != nil {
+		return false
+	}
+	if data, err := json.Marshal(observed); err != nil || json.Unmarshal(data, &observedFields) != nil {
+		return false
+	}
+	return fieldsMatch(desiredFields, observedFields)
+}
+
+func fieldsMatch(desired interface{}, observed interface{}) bool {
+	switch desired := desired.(type) {
+	case map[string]interface{}:
+		observed, ok := observed.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for key, value := range desired {
+			if !fieldsMatch(value, observed[key]) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		observed, ok := observed.([]interface{})
+		if !ok || len(observed) != len(desired) {
+			return false
+		}
+		for i := range desired {
+			if !fieldsMatch(desired[i], observed[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return reflect.DeepEqual(desired, observed)
+	}
+}
+
+// GetMinReplicas returns the minimum number of replicas of the component at the given time, the scaling schedule
+// which fired last overrides MinReplicas. The time of the next schedule activation is returned so that the
+// component is reconciled again when it fires, it is zero when the component has no scaling schedule.
+func GetMinReplicas(componentExt *v1beta1api.ComponentExtensionSpec, now time.Time) (int, time.Time) {
+	minReplicas := constants.DefaultMinReplicas
+	if componentExt.MinReplicas != nil {
+		minReplicas = *componentExt.MinReplicas
+	}
+	var lastActivation, nextActivation time.Time
+	for _, scalingSchedule := range componentExt.ScalingSchedules {
+		s, err := schedule.Parse(scalingSchedule.Schedule)
+		if err != nil {
+			// rejected by the validating webhook
+			continue
+		}
+		if prev, ok := s.Prev(now); ok && prev.After(lastActivation) {
+			lastActivation = prev
+			minReplicas = scalingSchedule.MinReplicas
+		}
+		if next, ok := s.Next(now); ok && (nextActivation.IsZero() || next.Before(nextActivation)) {
+			nextActivation = next
+		}
+	}
+	return minReplicas, nextActivation
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	v1beta1api "github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestGetMinReplicas(t *testing.T) {
+	businessHours := []v1beta1api.ScalingSchedule{
+		{Schedule: "0 8 * * 1-5", MinReplicas: 3},
+		{Schedule: "0 18 * * 1-5", MinReplicas: 1},
+	}
+	scenarios := map[string]struct {
+		componentExt        *v1beta1api.ComponentExtensionSpec
+		now                 time.Time
+		expectedMinReplicas int
+		expectedNext        time.Time
+	}{
+		"NoSchedule": {
+			componentExt:        &v1beta1api.ComponentExtensionSpec{MinReplicas: v1beta1api.GetIntReference(2)},
+			now:                 time.Date(2020, time.November, 4, 12, 0, 0, 0, time.UTC),
+			expectedMinReplicas: 2,
+		},
+		"BusinessHours": {
+			componentExt:        &v1beta1api.ComponentExtensionSpec{ScalingSchedules: businessHours},
+			now:                 time.Date(2020, time.November, 4, 12, 0, 0, 0, time.UTC),
+			expectedMinReplicas: 3,
+			expectedNext:        time.Date(2020, time.November, 4, 18, 0, 0, 0, time.UTC),
+		},
+		"Weekend": {
+			componentExt:        &v1beta1api.ComponentExtensionSpec{ScalingSchedules: businessHours},
+			now:                 time.Date(2020, time.November, 7, 12, 0, 0, 0, time.UTC),
+			expectedMinReplicas: 1,
+			expectedNext:        time.Date(2020, time.November, 9, 8, 0, 0, 0, time.UTC),
+		},
+		"NotFiredYet": {
+			componentExt: &v1beta1api.ComponentExtensionSpec{
+				MinReplicas:      v1beta1api.GetIntReference(0),
+				ScalingSchedules: []v1beta1api.ScalingSchedule{{Schedule: "0 0 29 2 *", MinReplicas: 5}},
+			},
+			now:                 time.Date(2021, time.March, 1, 0, 0, 0, 0, time.UTC),
+			expectedMinReplicas: 0,
+		},
+	}
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			g := gomega.NewGomegaWithT(t)
+			minReplicas, next := GetMinReplicas(scenario.componentExt, scenario.now)
+			g.Expect(minReplicas).To(gomega.Equal(scenario.expectedMinReplicas))
+			g.Expect(next).To(gomega.Equal(scenario.expectedNext))
+		})
+	}
+}
+
+func TestFieldsMatch(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	desired := v1.PodSpec{Containers: []v1.Container{{
+		Image: "kfserving/sklearnserver:latest",
+		Args:  []string{"--model_name=iris"},
+	}}}
+	scenarios := map[string]struct {
+		observed v1.PodSpec
+		expected bool
+	}{
+		"Equal": {
+			observed: desired,
+			expected: true,
+		},
+		"Defaulted": {
+			observed: v1.PodSpec{
+				Containers: []v1.Container{{
+					Name:                     "kfserving-container",
+					Image:                    "kfserving/sklearnserver:latest",
+					Args:                     []string{"--model_name=iris"},
+					TerminationMessagePolicy: v1.TerminationMessageReadFile,
+				}},
+				EnableServiceLinks: proto.Bool(false),
+			},
+			expected: true,
+		},
+		"Changed": {
+			observed: v1.PodSpec{Containers: []v1.Container{{
+				Image: "kfserving/sklearnserver:debug",
+				Args:  []string{"--model_name=iris"},
+			}}},
+			expected: false,
+		},
+		"Removed": {
+			observed: v1.PodSpec{Containers: []v1.Container{{Image: "kfserving/sklearnserver:latest"}}},
+			expected: false,
+		},
+		"Added": {
+			observed: v1.PodSpec{Containers: []v1.Container{
+				{Image: "kfserving/sklearnserver:latest", Args: []string{"--model_name=iris"}},
+				{Image: "busybox"},
+			}},
+			expected: false,
+		},
+	}
+	for name, scenario := range scenarios {
+		g.Expect(FieldsMatch(desired, scenario.observed)).To(gomega.Equal(scenario.expected), name)
+	}
+}
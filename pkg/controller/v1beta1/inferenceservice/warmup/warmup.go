@@ -0,0 +1,221 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package warmup sends the warm-up requests of a component to its new revisions, the knative reconciler keeps the
+// traffic on the last warmed up revision until the new revision answers them. The requests are sent in the background
+// so that a slow or failing revision does not hold the reconcile of the InferenceService, the progress is reported by
+// the warm-up condition of the component.
+package warmup
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+var log = logf.Log.WithName("WarmUpReconciler")
+
+const (
+	requestTimeout = 60 * time.Second
+	// CheckInterval is how often a component is reconciled while its new revision is warmed up
+	CheckInterval = 5 * time.Second
+	// RetryInterval is how long a revision which failed the warm-up waits before the requests are sent again
+	RetryInterval = 30 * time.Second
+	// resultTTL is how long the result of a warm-up is kept for the reconcile of its InferenceService
+	resultTTL = 10 * time.Minute
+)
+
+// result is the outcome of the warm-up of a revision, done is false while the requests are sent
+type result struct {
+	done     bool
+	err      error
+	finished time.Time
+}
+
+// tracker records the warm-ups in progress and their results by namespace and revision, it is shared by the
+// reconcilers created for each reconcile of an InferenceService
+type tracker struct {
+	mu      sync.Mutex
+	results map[string]*result
+}
+
+var warmUps = &tracker{results: map[string]*result{}}
+
+type WarmUpReconciler struct {
+	client     client.Client
+	httpClient *http.Client
+	warmUps    *tracker
+	// RevisionURL returns the base URL of a revision, the cluster local address of its service by default
+	RevisionURL func(revision string, namespace string) string
+}
+
+func NewWarmUpReconciler(client client.Client) *WarmUpReconciler {
+	return &WarmUpReconciler{
+		client:     client,
+		httpClient: &http.Client{Timeout: requestTimeout},
+		warmUps:    warmUps,
+		RevisionURL: func(revision string, namespace string) string {
+			return fmt.Sprintf("http://%s.%s.svc.cluster.local", revision, namespace)
+		},
+	}
+}
+
+// Reconcile starts the warm-up of the latest ready revision of the component in the background and records it in
+// the component status once all the requests succeeded, the warm-up condition reports the progress and the failures.
+// The revision is warmed up again after RetryInterval when a request failed. defaultPath is used when the warm-up does
+// not set a path.
+func (r *WarmUpReconciler) Reconcile(isvc *v1beta1.InferenceService, component v1beta1.ComponentType,
+	warmUp *v1beta1.WarmUpSpec, defaultPath string) {
+	statusSpec, ok := isvc.Status.Components[component]
+	if !ok {
+		return
+	}
+	if warmUp == nil {
+		statusSpec.WarmedUpRevision = ""
+		isvc.Status.Components[component] = statusSpec
+		isvc.Status.PropagateWarmUp(component, "", "")
+		return
+	}
+	revision := statusSpec.LatestReadyRevision
+	if revision == "" || revision == statusSpec.WarmedUpRevision {
+		return
+	}
+	key := isvc.Namespace + "/" + revision
+	r.warmUps.mu.Lock()
+	defer r.warmUps.mu.Unlock()
+	r.warmUps.prune(time.Now())
+	warmUpResult, ok := r.warmUps.results[key]
+	switch {
+	case !ok || (warmUpResult.done && warmUpResult.err != nil && time.Since(warmUpResult.finished) > RetryInterval):
+		warmUpResult = &result{}
+		r.warmUps.results[key] = warmUpResult
+		go r.warmUp(warmUpResult, isvc.Namespace, revision, warmUp.DeepCopy(), defaultPath)
+		isvc.Status.PropagateWarmUp(component, v1.ConditionUnknown, fmt.Sprintf("Warming up revision %s", revision))
+	case !warmUpResult.done:
+		isvc.Status.PropagateWarmUp(component, v1.ConditionUnknown, fmt.Sprintf("Warming up revision %s", revision))
+	case warmUpResult.err != nil:
+		isvc.Status.PropagateWarmUp(component, v1.ConditionFalse, warmUpResult.err.Error())
+	default:
+		delete(r.warmUps.results, key)
+		statusSpec.WarmedUpRevision = revision
+		isvc.Status.Components[component] = statusSpec
+		isvc.Status.PropagateWarmUp(component, v1.ConditionTrue, "")
+	}
+}
+
+// NextCheck returns the delay until the component is reconciled again to collect the result of the warm-up of its
+// latest ready revision, zero if the revision is warmed up or the component has no warm-up
+func NextCheck(extension *v1beta1.ComponentExtensionSpec, statusSpec v1beta1.ComponentStatusSpec) time.Duration {
+	if extension.WarmUp == nil || statusSpec.LatestReadyRevision == "" ||
+		statusSpec.LatestReadyRevision == statusSpec.WarmedUpRevision {
+		return 0
+	}
+	return CheckInterval
+}
+
+// warmUp sends the warm-up requests to the revision and records the result, it runs in the background
+func (r *WarmUpReconciler) warmUp(warmUpResult *result, namespace string, revision string, warmUp *v1beta1.WarmUpSpec,
+	defaultPath string) {
+	err := r.send(namespace, revision, warmUp, defaultPath)
+	if err != nil {
+		log.Error(err, "Failed to warm up revision", "namespace", namespace, "revision", revision)
+	}
+	r.warmUps.mu.Lock()
+	defer r.warmUps.mu.Unlock()
+	warmUpResult.done = true
+	warmUpResult.err = err
+	warmUpResult.finished = time.Now()
+}
+
+func (r *WarmUpReconciler) send(namespace string, revision string, warmUp *v1beta1.WarmUpSpec, defaultPath string) error {
+	payload, err := r.payload(namespace, warmUp)
+	if err != nil {
+		return fmt.Errorf("fails to read the warm-up payload: %v", err)
+	}
+	path := warmUp.Path
+	if path == "" {
+		path = defaultPath
+	}
+	requests := warmUp.Requests
+	if requests == 0 {
+		requests = 1
+	}
+	url := r.RevisionURL(revision, namespace) + path
+	log.Info("Warming up revision", "namespace", namespace, "revision", revision, "requests", requests)
+	for i := 0; i < requests; i++ {
+		if err := r.post(url, payload); err != nil {
+			return fmt.Errorf("warm-up request %d/%d to revision %s failed: %v", i+1, requests, revision, err)
+		}
+	}
+	return nil
+}
+
+// prune forgets the results which were not collected, e.g. of a revision replaced during its warm-up
+func (t *tracker) prune(now time.Time) {
+	for key, warmUpResult := range t.results {
+		if warmUpResult.done && now.Sub(warmUpResult.finished) > resultTTL {
+			delete(t.results, key)
+		}
+	}
+}
+
+func (r *WarmUpReconciler) payload(namespace string, warmUp *v1beta1.WarmUpSpec) ([]byte, error) {
+	if warmUp.ConfigMapKeyRef != nil {
+		configMap := &v1.ConfigMap{}
+		if err := r.client.Get(context.TODO(), types.NamespacedName{Name: warmUp.ConfigMapKeyRef.Name,
+			Namespace: namespace}, configMap); err != nil {
+			return nil, err
+		}
+		if data, ok := configMap.Data[warmUp.ConfigMapKeyRef.Key]; ok {
+			return []byte(data), nil
+		}
+		if data, ok := configMap.BinaryData[warmUp.ConfigMapKeyRef.Key]; ok {
+			return data, nil
+		}
+		return nil, fmt.Errorf("key %s not found in configmap %s", warmUp.ConfigMapKeyRef.Key, warmUp.ConfigMapKeyRef.Name)
+	}
+	resp, err := r.httpClient.Get(warmUp.URI)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned status %d", warmUp.URI, resp.StatusCode)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+func (r *WarmUpReconciler) post(url string, payload []byte) error {
+	resp, err := r.httpClient.Post(url, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package warmup
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newInferenceService(latestReadyRevision string, warmedUpRevision string) *v1beta1.InferenceService {
+	return &v1beta1.InferenceService{
+		ObjectMeta: metav1.ObjectMeta{Name: "sklearn", Namespace: "default"},
+		Status: v1beta1.InferenceServiceStatus{
+			Components: map[v1beta1.ComponentType]v1beta1.ComponentStatusSpec{
+				v1beta1.PredictorComponent: {
+					LatestReadyRevision: latestReadyRevision,
+					WarmedUpRevision:    warmedUpRevision,
+				},
+			},
+		},
+	}
+}
+
+func TestWarmUpReconciler(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	var requests []string
+	failing := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := ioutil.ReadAll(req.Body)
+		requests = append(requests, req.URL.Path+" "+string(body))
+		if failing {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	configMap := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "samples", Namespace: "default"},
+		Data:       map[string]string{"sklearn.json": `{"instances": [[1, 2]]}`},
+	}
+	reconciler := NewWarmUpReconciler(fake.NewFakeClientWithScheme(scheme.Scheme, configMap))
+	reconciler.warmUps = &tracker{results: map[string]*result{}}
+	reconciler.RevisionURL = func(revision string, namespace string) string {
+		return server.URL + "/" + revision
+	}
+	warmUp := &v1beta1.WarmUpSpec{
+		ConfigMapKeyRef: &v1.ConfigMapKeySelector{
+			LocalObjectReference: v1.LocalObjectReference{Name: "samples"},
+			Key:                  "sklearn.json",
+		},
+		Requests: 2,
+	}
+
+	path := constants.PredictPath("sklearn")
+	// reconcile returns the warm-up condition once the background requests are done
+	reconcile := func(isvc *v1beta1.InferenceService, warmUp *v1beta1.WarmUpSpec) v1.ConditionStatus {
+		reconciler.Reconcile(isvc, v1beta1.PredictorComponent, warmUp, path)
+		g.Eventually(func() v1.ConditionStatus {
+			reconciler.Reconcile(isvc, v1beta1.PredictorComponent, warmUp, path)
+			if condition := isvc.Status.GetCondition(v1beta1.PredictorWarmedUp); condition != nil {
+				return condition.Status
+			}
+			return ""
+		}).ShouldNot(gomega.Equal(v1.ConditionUnknown))
+		if condition := isvc.Status.GetCondition(v1beta1.PredictorWarmedUp); condition != nil {
+			return condition.Status
+		}
+		return ""
+	}
+
+	// the new revision is recorded once it answered all the warm-up requests
+	isvc := newInferenceService("sklearn-predictor-default-00002", "sklearn-predictor-default-00001")
+	g.Expect(NextCheck(&v1beta1.ComponentExtensionSpec{WarmUp: warmUp}, isvc.Status.Components[v1beta1.PredictorComponent])).
+		To(gomega.Equal(CheckInterval))
+	g.Expect(reconcile(isvc, warmUp)).To(gomega.Equal(v1.ConditionTrue))
+	g.Expect(isvc.Status.Components[v1beta1.PredictorComponent].WarmedUpRevision).To(gomega.Equal("sklearn-predictor-default-00002"))
+	g.Expect(requests).To(gomega.Equal([]string{
+		`/sklearn-predictor-default-00002/v1/models/sklearn:predict {"instances": [[1, 2]]}`,
+		`/sklearn-predictor-default-00002/v1/models/sklearn:predict {"instances": [[1, 2]]}`,
+	}))
+	g.Expect(NextCheck(&v1beta1.ComponentExtensionSpec{WarmUp: warmUp}, isvc.Status.Components[v1beta1.PredictorComponent])).
+		To(gomega.BeZero())
+
+	// a warmed up revision is not sent the requests again
+	requests = nil
+	reconciler.Reconcile(isvc, v1beta1.PredictorComponent, warmUp, path)
+	g.Expect(requests).To(gomega.BeEmpty())
+
+	// the traffic stays on the warmed up revision while the new revision fails the requests
+	failing = true
+	isvc = newInferenceService("sklearn-predictor-default-00003", "sklearn-predictor-default-00002")
+	g.Expect(reconcile(isvc, warmUp)).To(gomega.Equal(v1.ConditionFalse))
+	g.Expect(isvc.Status.GetCondition(v1beta1.PredictorWarmedUp).Reason).To(gomega.Equal(v1beta1.WarmUpFailedReason))
+	g.Expect(isvc.Status.Components[v1beta1.PredictorComponent].WarmedUpRevision).To(gomega.Equal("sklearn-predictor-default-00002"))
+
+	// the failed requests are not sent again before the retry interval
+	requests = nil
+	reconciler.Reconcile(isvc, v1beta1.PredictorComponent, warmUp, path)
+	g.Expect(requests).To(gomega.BeEmpty())
+
+	// removing the warm-up forgets the warmed up revision
+	reconciler.Reconcile(isvc, v1beta1.PredictorComponent, nil, path)
+	g.Expect(isvc.Status.Components[v1beta1.PredictorComponent].WarmedUpRevision).To(gomega.BeEmpty())
+	g.Expect(isvc.Status.GetCondition(v1beta1.PredictorWarmedUp)).To(gomega.BeNil())
+}
@@ -0,0 +1,374 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package multicluster pushes the InferenceServices labeled for multi-cluster from the hub cluster to the member
+// clusters and aggregates their per-cluster readiness in the hub status.
+//
+// A member cluster is registered by a secret of the KFServing namespace labeled with
+// serving.kubeflow.org/member-cluster=true, holding the kubeconfig of the cluster under the "kubeconfig" key. The name
+// of the secret is the name of the member cluster.
+package multicluster
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	v1beta1api "github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/kubeflow/kfserving/pkg/utils"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	// finalizerName removes the copies of the InferenceService from the member clusters
+	finalizerName = "multicluster.finalizers"
+	// SyncPeriod is how often the status of the member clusters is polled, their changes are not watched
+	SyncPeriod = time.Minute
+)
+
+// ClientFactory creates the client of a member cluster from its secret
+type ClientFactory func(secret *v1.Secret, scheme *runtime.Scheme) (client.Client, error)
+
+// NewClusterClient creates the client of a member cluster from the kubeconfig of its secret
+func NewClusterClient(secret *v1.Secret, scheme *runtime.Scheme) (client.Client, error) {
+	kubeconfig, ok := secret.Data[constants.MemberClusterKubeconfigKey]
+	if !ok {
+		return nil, fmt.Errorf("member cluster secret %s has no %s entry", secret.Name, constants.MemberClusterKubeconfigKey)
+	}
+	config, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+	if err != nil {
+		return nil, errors.Wrapf(err, "fails to load the kubeconfig of member cluster %s", secret.Name)
+	}
+	return client.New(config, client.Options{Scheme: scheme})
+}
+
+type cachedClient struct {
+	resourceVersion string
+	client          client.Client
+}
+
+// MultiClusterReconciler reconciles the InferenceServices labeled for multi-cluster
+type MultiClusterReconciler struct {
+	client.Client
+	Log      logr.Logger
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	// NewClient creates the clients of the member clusters
+	NewClient ClientFactory
+
+	mu      sync.Mutex
+	clients map[string]cachedClient
+	// secrets reads the member cluster secrets, it is a cache of the KFServing namespace set up with the manager
+	secrets client.Reader
+}
+
+func (r *MultiClusterReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	isvc := &v1beta1api.InferenceService{}
+	if err := r.Get(context.TODO(), req.NamespacedName, isvc); err != nil {
+		if apierr.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	if isvc.Labels[constants.MultiClusterLabel] != "true" || !isvc.ObjectMeta.DeletionTimestamp.IsZero() {
+		// The InferenceService is no longer replicated, remove the copies it left in the member clusters
+		if !utils.ContainsString(isvc.ObjectMeta.Finalizers, finalizerName) {
+			return ctrl.Result{}, nil
+		}
+		// The copies are removed from all the registered member clusters, the status may miss a copy created
+		// before the status update failed
+		secrets, err := r.memberClusters(nil)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		for name, secret := range secrets {
+			if err := r.deleteCopy(secret, name, isvc); err != nil {
+				return reconcile.Result{}, err
+			}
+		}
+		for name := range isvc.Status.Clusters {
+			if _, ok := secrets[name]; !ok {
+				r.Log.Info("Member cluster is no longer registered", "cluster", name, "isvc", isvc.Name)
+			}
+		}
+		if isvc.ObjectMeta.DeletionTimestamp.IsZero() && isvc.Status.Clusters != nil {
+			// The InferenceService is deployed by the hub from now on, it no longer reports the member clusters
+			isvc.Status.Clusters = nil
+			if err := r.Status().Update(context.TODO(), isvc); err != nil {
+				return reconcile.Result{}, err
+			}
+		}
+		isvc.ObjectMeta.Finalizers = utils.RemoveString(isvc.ObjectMeta.Finalizers, finalizerName)
+		return ctrl.Result{}, r.Update(context.TODO(), isvc)
+	}
+
+	if !utils.ContainsString(isvc.ObjectMeta.Finalizers, finalizerName) {
+		isvc.ObjectMeta.Finalizers = append(isvc.ObjectMeta.Finalizers, finalizerName)
+		if err := r.Update(context.TODO(), isvc); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	r.Log.Info("Reconciling multi-cluster inference service", "isvc", isvc.Name)
+	selected, err := r.memberClusters(selectedClusters(isvc))
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	allClusters, err := r.memberClusters(nil)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	clusters := map[string]v1beta1api.ClusterStatus{}
+	for name, secret := range selected {
+		clusters[name] = r.propagate(secret, isvc)
+	}
+	// Clusters which are no longer selected keep their status until the copy is removed
+	for name, status := range isvc.Status.Clusters {
+		if _, ok := selected[name]; ok {
+			continue
+		}
+		if err := r.deleteCopy(allClusters[name], name, isvc); err != nil {
+			r.Log.Error(err, "Failed to delete copy", "cluster", name, "isvc", isvc.Name)
+			clusters[name] = status
+		}
+	}
+
+	original := isvc.Status.DeepCopy()
+	isvc.Status.PropagateClusterStatus(clusters)
+	if !equality.Semantic.DeepEqual(original, &isvc.Status) {
+		if err := r.Status().Update(context.TODO(), isvc); err != nil {
+			r.Recorder.Eventf(isvc, v1.EventTypeWarning, "UpdateFailed",
+				"Failed to update status for InferenceService %q: %v", isvc.Name, err)
+			return reconcile.Result{}, err
+		}
+	}
+	return ctrl.Result{RequeueAfter: SyncPeriod}, nil
+}
+
+// selectedClusters returns the names of the member-clusters annotation, or nil to select all the member clusters
+func selectedClusters(isvc *v1beta1api.InferenceService) []string {
+	annotation, ok := isvc.Annotations[constants.MemberClustersAnnotationKey]
+	if !ok {
+		return nil
+	}
+	names := []string{}
+	for _, name := range strings.Split(annotation, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// memberClusters returns the secrets of the member clusters by name, restricted to names unless it is nil
+func (r *MultiClusterReconciler) memberClusters(names []string) (map[string]*v1.Secret, error) {
+	reader := r.secrets
+	if reader == nil {
+		reader = r.Client
+	}
+	secrets := &v1.SecretList{}
+	if err := reader.List(context.TODO(), secrets, client.InNamespace(constants.KFServingNamespace),
+		client.MatchingLabels{constants.MemberClusterLabel: "true"}); err != nil {
+		return nil, errors.Wrapf(err, "fails to list member clusters")
+	}
+	clusters := map[string]*v1.Secret{}
+	for i := range secrets.Items {
+		secret := &secrets.Items[i]
+		if names == nil || utils.ContainsString(names, secret.Name) {
+			clusters[secret.Name] = secret
+		}
+	}
+	return clusters, nil
+}
+
+// clusterClient returns the cached client of a member cluster, it is recreated once the secret changes
+func (r *MultiClusterReconciler) clusterClient(secret *v1.Secret) (client.Client, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if cached, ok := r.clients[secret.Name]; ok && cached.resourceVersion == secret.ResourceVersion {
+		return cached.client, nil
+	}
+	c, err := r.NewClient(secret, r.Scheme)
+	if err != nil {
+		return nil, err
+	}
+	if r.clients == nil {
+		r.clients = map[string]cachedClient{}
+	}
+	r.clients[secret.Name] = cachedClient{resourceVersion: secret.ResourceVersion, client: c}
+	return c, nil
+}
+
+// memberCopy is the InferenceService created in the member clusters, it carries the spec and the labels and
+// annotations of the hub InferenceService except the multi-cluster ones
+func memberCopy(isvc *v1beta1api.InferenceService) *v1beta1api.InferenceService {
+	labels := map[string]string{}
+	for key, value := range isvc.Labels {
+		if key != constants.MultiClusterLabel {
+			labels[key] = value
+		}
+	}
+	annotations := map[string]string{}
+	for key, value := range isvc.Annotations {
+		if key != constants.MemberClustersAnnotationKey && key != v1.LastAppliedConfigAnnotation {
+			annotations[key] = value
+		}
+	}
+	remote := &v1beta1api.InferenceService{}
+	remote.Name = isvc.Name
+	remote.Namespace = isvc.Namespace
+	remote.Labels = labels
+	remote.Annotations = annotations
+	isvc.Spec.DeepCopyInto(&remote.Spec)
+	return remote
+}
+
+// propagate creates or updates the copy of the InferenceService in a member cluster and returns its status there
+func (r *MultiClusterReconciler) propagate(secret *v1.Secret, isvc *v1beta1api.InferenceService) v1beta1api.ClusterStatus {
+	c, err := r.clusterClient(secret)
+	if err != nil {
+		return v1beta1api.ClusterStatus{Message: err.Error()}
+	}
+	desired := memberCopy(isvc)
+	existing := &v1beta1api.InferenceService{}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: isvc.Name, Namespace: isvc.Namespace}, existing)
+	if apierr.IsNotFound(err) {
+		r.Log.Info("Creating inference service in member cluster", "cluster", secret.Name, "isvc", isvc.Name)
+		if err := c.Create(context.TODO(), desired); err != nil {
+			return v1beta1api.ClusterStatus{Message: fmt.Sprintf("fails to create InferenceService: %v", err)}
+		}
+		return v1beta1api.ClusterStatus{Message: "InferenceService created"}
+	}
+	if err != nil {
+		return v1beta1api.ClusterStatus{Message: fmt.Sprintf("fails to get InferenceService: %v", err)}
+	}
+	if !equality.Semantic.DeepEqual(desired.Spec, existing.Spec) ||
+		!equality.Semantic.DeepEqual(desired.Labels, existing.Labels) ||
+		!equality.Semantic.DeepEqual(desired.Annotations, existing.Annotations) {
+		r.Log.Info("Updating inference service in member cluster", "cluster", secret.Name, "isvc", isvc.Name)
+		existing.Spec = desired.Spec
+		existing.Labels = desired.Labels
+		existing.Annotations = desired.Annotations
+		if err := c.Update(context.TODO(), existing); err != nil {
+			return v1beta1api.ClusterStatus{Message: fmt.Sprintf("fails to update InferenceService: %v", err)}
+		}
+	}
+	status := v1beta1api.ClusterStatus{Ready: existing.Status.IsReady(), URL: existing.Status.URL}
+	if !status.Ready {
+		status.Message = notReadyMessage(existing)
+	}
+	return status
+}
+
+// notReadyMessage summarizes the conditions which are not ready in a member cluster
+func notReadyMessage(isvc *v1beta1api.InferenceService) string {
+	messages := []string{}
+	for _, condition := range isvc.Status.Conditions {
+		if !condition.IsTrue() && condition.Message != "" {
+			messages = append(messages, fmt.Sprintf("%s: %s", condition.Type, condition.Message))
+		}
+	}
+	if len(messages) == 0 {
+		return "InferenceService is not ready"
+	}
+	sort.Strings(messages)
+	return strings.Join(messages, "; ")
+}
+
+// deleteCopy deletes the copy of the InferenceService from a member cluster, a cluster whose secret was removed is
+// no longer reachable so its copy is left behind
+func (r *MultiClusterReconciler) deleteCopy(secret *v1.Secret, name string, isvc *v1beta1api.InferenceService) error {
+	if secret == nil {
+		r.Log.Info("Member cluster is no longer registered", "cluster", name, "isvc", isvc.Name)
+		return nil
+	}
+	c, err := r.clusterClient(secret)
+	if err != nil {
+		return err
+	}
+	r.Log.Info("Deleting inference service from member cluster", "cluster", name, "isvc", isvc.Name)
+	remote := &v1beta1api.InferenceService{}
+	remote.Name = isvc.Name
+	remote.Namespace = isvc.Namespace
+	if err := c.Delete(context.TODO(), remote); err != nil && !apierr.IsNotFound(err) {
+		return errors.Wrapf(err, "fails to delete InferenceService from member cluster %s", name)
+	}
+	return nil
+}
+
+func (r *MultiClusterReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// The member cluster secrets are watched and read with a cache of the KFServing namespace, the manager cache
+	// would otherwise watch the secrets of all the namespaces
+	secrets, err := cache.New(mgr.GetConfig(), cache.Options{
+		Scheme:    mgr.GetScheme(),
+		Mapper:    mgr.GetRESTMapper(),
+		Namespace: constants.KFServingNamespace,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "fails to create the member cluster secret cache")
+	}
+	if err := mgr.Add(secrets); err != nil {
+		return err
+	}
+	r.secrets = secrets
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("multicluster-inferenceservice").
+		For(&v1beta1api.InferenceService{}).
+		// The multi-cluster InferenceServices are propagated again once a member cluster is registered or changed
+		Watches(source.NewKindWithCache(&v1.Secret{}, secrets), &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(r.inferenceServiceRequestsForSecret),
+		}).
+		Complete(r)
+}
+
+// inferenceServiceRequestsForSecret maps a member cluster secret to the multi-cluster InferenceServices
+func (r *MultiClusterReconciler) inferenceServiceRequestsForSecret(obj handler.MapObject) []reconcile.Request {
+	if obj.Meta.GetNamespace() != constants.KFServingNamespace ||
+		obj.Meta.GetLabels()[constants.MemberClusterLabel] != "true" {
+		return nil
+	}
+	isvcs := &v1beta1api.InferenceServiceList{}
+	if err := r.List(context.TODO(), isvcs, client.MatchingLabels{constants.MultiClusterLabel: "true"}); err != nil {
+		r.Log.Error(err, "unable to list multi-cluster InferenceServices")
+		return nil
+	}
+	requests := []reconcile.Request{}
+	for _, isvc := range isvcs.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: isvc.Name, Namespace: isvc.Namespace},
+		})
+	}
+	return requests
+}
@@ -0,0 +1,190 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package multicluster
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func memberSecret(name string) *v1.Secret {
+	return &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: constants.KFServingNamespace,
+			Labels:    map[string]string{constants.MemberClusterLabel: "true"},
+		},
+	}
+}
+
+func TestMultiClusterReconcile(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scheme := runtime.NewScheme()
+	g.Expect(v1.AddToScheme(scheme)).Should(gomega.Succeed())
+	g.Expect(v1beta1.AddToScheme(scheme)).Should(gomega.Succeed())
+
+	key := types.NamespacedName{Name: "sklearn", Namespace: "default"}
+	isvc := &v1beta1.InferenceService{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        key.Name,
+			Namespace:   key.Namespace,
+			Labels:      map[string]string{constants.MultiClusterLabel: "true", "team": "fraud"},
+			Annotations: map[string]string{constants.MemberClustersAnnotationKey: "east, west"},
+		},
+		Spec: v1beta1.InferenceServiceSpec{
+			Predictor: v1beta1.PredictorSpec{
+				SKLearn: &v1beta1.SKLearnSpec{
+					PredictorExtensionSpec: v1beta1.PredictorExtensionSpec{StorageURI: proto.String("gs://models/sklearn")},
+				},
+			},
+		},
+	}
+	hub := fake.NewFakeClientWithScheme(scheme, isvc, memberSecret("east"), memberSecret("west"), memberSecret("north"))
+	readyURL, _ := apis.ParseURL("http://sklearn.default.east.example.com")
+	members := map[string]client.Client{
+		"east": fake.NewFakeClientWithScheme(scheme, &v1beta1.InferenceService{
+			ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+			Status: v1beta1.InferenceServiceStatus{
+				Status: duckv1.Status{Conditions: duckv1.Conditions{
+					{Type: apis.ConditionReady, Status: v1.ConditionTrue},
+				}},
+				URL: readyURL,
+			},
+		}),
+		"west":  fake.NewFakeClientWithScheme(scheme),
+		"north": fake.NewFakeClientWithScheme(scheme),
+	}
+	r := &MultiClusterReconciler{
+		Client:   hub,
+		Log:      logf.Log,
+		Scheme:   scheme,
+		Recorder: record.NewFakeRecorder(10),
+		NewClient: func(secret *v1.Secret, scheme *runtime.Scheme) (client.Client, error) {
+			return members[secret.Name], nil
+		},
+	}
+
+	result, err := r.Reconcile(ctrl.Request{NamespacedName: key})
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(result.RequeueAfter).To(gomega.Equal(SyncPeriod))
+
+	for _, name := range []string{"east", "west"} {
+		remote := &v1beta1.InferenceService{}
+		g.Expect(members[name].Get(context.TODO(), key, remote)).Should(gomega.Succeed())
+		g.Expect(remote.Spec).To(gomega.Equal(isvc.Spec))
+		g.Expect(remote.Labels).To(gomega.Equal(map[string]string{"team": "fraud"}))
+		g.Expect(remote.Annotations).NotTo(gomega.HaveKey(constants.MemberClustersAnnotationKey))
+	}
+	err = members["north"].Get(context.TODO(), key, &v1beta1.InferenceService{})
+	g.Expect(apierr.IsNotFound(err)).To(gomega.BeTrue())
+
+	updated := &v1beta1.InferenceService{}
+	g.Expect(hub.Get(context.TODO(), key, updated)).Should(gomega.Succeed())
+	g.Expect(updated.Finalizers).To(gomega.ContainElement(finalizerName))
+	g.Expect(updated.Status.Clusters).To(gomega.Equal(map[string]v1beta1.ClusterStatus{
+		"east": {Ready: true, URL: readyURL},
+		"west": {Message: "InferenceService created"},
+	}))
+	g.Expect(updated.Status.IsReady()).To(gomega.BeFalse())
+
+	// Deselecting a member cluster removes the copy from it
+	updated.Annotations[constants.MemberClustersAnnotationKey] = "east"
+	g.Expect(hub.Update(context.TODO(), updated)).Should(gomega.Succeed())
+	_, err = r.Reconcile(ctrl.Request{NamespacedName: key})
+	g.Expect(err).Should(gomega.BeNil())
+	err = members["west"].Get(context.TODO(), key, &v1beta1.InferenceService{})
+	g.Expect(apierr.IsNotFound(err)).To(gomega.BeTrue())
+	g.Expect(hub.Get(context.TODO(), key, updated)).Should(gomega.Succeed())
+	g.Expect(updated.Status.Clusters).To(gomega.HaveLen(1))
+	g.Expect(updated.Status.IsReady()).To(gomega.BeTrue())
+
+	// Removing the multi-cluster label removes the remaining copies and the finalizer
+	delete(updated.Labels, constants.MultiClusterLabel)
+	g.Expect(hub.Update(context.TODO(), updated)).Should(gomega.Succeed())
+	_, err = r.Reconcile(ctrl.Request{NamespacedName: key})
+	g.Expect(err).Should(gomega.BeNil())
+	err = members["east"].Get(context.TODO(), key, &v1beta1.InferenceService{})
+	g.Expect(apierr.IsNotFound(err)).To(gomega.BeTrue())
+	g.Expect(hub.Get(context.TODO(), key, updated)).Should(gomega.Succeed())
+	g.Expect(updated.Finalizers).NotTo(gomega.ContainElement(finalizerName))
+	g.Expect(updated.Status.Clusters).To(gomega.BeNil())
+}
+
+func TestMultiClusterRemovesUnrecordedCopies(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scheme := runtime.NewScheme()
+	g.Expect(v1.AddToScheme(scheme)).Should(gomega.Succeed())
+	g.Expect(v1beta1.AddToScheme(scheme)).Should(gomega.Succeed())
+
+	key := types.NamespacedName{Name: "sklearn", Namespace: "default"}
+	// the copy in the west cluster was created but the status update recording it failed
+	isvc := &v1beta1.InferenceService{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       key.Name,
+			Namespace:  key.Namespace,
+			Finalizers: []string{finalizerName},
+		},
+		Status: v1beta1.InferenceServiceStatus{
+			Clusters: map[string]v1beta1.ClusterStatus{"east": {Ready: true}, "south": {Ready: true}},
+		},
+	}
+	copyOf := func() *v1beta1.InferenceService {
+		return &v1beta1.InferenceService{ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace}}
+	}
+	hub := fake.NewFakeClientWithScheme(scheme, isvc, memberSecret("east"), memberSecret("west"))
+	members := map[string]client.Client{
+		"east": fake.NewFakeClientWithScheme(scheme, copyOf()),
+		"west": fake.NewFakeClientWithScheme(scheme, copyOf()),
+	}
+	r := &MultiClusterReconciler{
+		Client:   hub,
+		Log:      logf.Log,
+		Scheme:   scheme,
+		Recorder: record.NewFakeRecorder(10),
+		NewClient: func(secret *v1.Secret, scheme *runtime.Scheme) (client.Client, error) {
+			return members[secret.Name], nil
+		},
+	}
+
+	_, err := r.Reconcile(ctrl.Request{NamespacedName: key})
+	g.Expect(err).Should(gomega.BeNil())
+	for name, member := range members {
+		err = member.Get(context.TODO(), key, &v1beta1.InferenceService{})
+		g.Expect(apierr.IsNotFound(err)).To(gomega.BeTrue(), name)
+	}
+	updated := &v1beta1.InferenceService{}
+	g.Expect(hub.Get(context.TODO(), key, updated)).Should(gomega.Succeed())
+	g.Expect(updated.Finalizers).To(gomega.BeEmpty())
+	g.Expect(updated.Status.Clusters).To(gomega.BeNil())
+}
@@ -0,0 +1,312 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package diagnose explains why an InferenceService is not ready by walking its conditions,
+// child resources and recent warning events.
+package diagnose
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	v1 "k8s.io/api/core/v1"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	"knative.dev/serving/pkg/apis/serving"
+	knservingv1 "knative.dev/serving/pkg/apis/serving/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// MaxEvents is the maximum number of warning events reported
+	MaxEvents = 10
+)
+
+// Finding is a single cause of an InferenceService not being ready
+type Finding struct {
+	// Component the finding belongs to, empty for the InferenceService itself
+	Component v1beta1.ComponentType
+	// Resource is the kind and name of the resource the finding was observed on
+	Resource string
+	// Reason is a short machine readable cause
+	Reason string
+	// Message is the detailed explanation
+	Message string
+}
+
+// String returns the finding in a human readable form, e.g.
+// "predictor pod sklearn-iris-predictor-default-00001-deployment-5f8d: ImagePullBackOff: container kfserving-container image foo: ..."
+func (f Finding) String() string {
+	s := f.Resource
+	if f.Component != "" {
+		s = string(f.Component) + " " + s
+	}
+	s += ": " + f.Reason
+	if f.Message != "" {
+		s += ": " + f.Message
+	}
+	return s
+}
+
+// Diagnose returns the findings explaining why the InferenceService is not ready, the most specific ones come
+// from the child resources of each component followed by the recent warning events. No findings are returned
+// for a ready InferenceService whose detectors, replica pool and model versions are ready as well.
+func Diagnose(ctx context.Context, cli client.Client, isvc *v1beta1.InferenceService) ([]Finding, error) {
+	if isvc.Status.IsReady() && !degraded(isvc) {
+		return nil, nil
+	}
+	findings := conditionFindings("", "InferenceService "+isvc.Name, isvc.Status.Conditions)
+	// names of the resources whose events are reported
+	involved := map[string]bool{isvc.Name: true}
+	// the knative services are removed while the InferenceService is paused and not created while it waits for
+	// quota, the conditions above already explain it
+	if paused(isvc) || pending(isvc) {
+		eventFindings, err := diagnoseEvents(ctx, cli, isvc.Namespace, involved)
+		if err != nil {
+			return nil, err
+		}
+		return append(findings, eventFindings...), nil
+	}
+
+	services := []componentService{{v1beta1.PredictorComponent, constants.DefaultPredictorServiceName(isvc.Name)}}
+	if isvc.Spec.Predictor.Pool != nil {
+		services = append(services, componentService{v1beta1.PredictorComponent, constants.PredictorPoolServiceName(isvc.Name)})
+	}
+	for _, version := range isvc.Spec.Predictor.Versions {
+		services = append(services, componentService{v1beta1.PredictorComponent,
+			constants.PredictorVersionServiceName(isvc.Name, version.Name)})
+	}
+	if isvc.Spec.Transformer != nil {
+		services = append(services, componentService{v1beta1.TransformerComponent, constants.DefaultTransformerServiceName(isvc.Name)})
+	}
+	if isvc.Spec.Explainer != nil {
+		services = append(services, componentService{v1beta1.ExplainerComponent, constants.DefaultExplainerServiceName(isvc.Name)})
+	}
+	if isvc.Spec.DriftDetector != nil {
+		services = append(services, componentService{v1beta1.DriftDetectorComponent, constants.DefaultDriftDetectorServiceName(isvc.Name)})
+	}
+	if isvc.Spec.OutlierDetector != nil {
+		services = append(services, componentService{v1beta1.OutlierDetectorComponent, constants.DefaultOutlierDetectorServiceName(isvc.Name)})
+	}
+	for _, service := range services {
+		componentFindings, err := diagnoseComponent(ctx, cli, service.component,
+			types.NamespacedName{Name: service.name, Namespace: isvc.Namespace}, involved)
+		if err != nil {
+			return nil, err
+		}
+		findings = append(findings, componentFindings...)
+	}
+
+	eventFindings, err := diagnoseEvents(ctx, cli, isvc.Namespace, involved)
+	if err != nil {
+		return nil, err
+	}
+	return append(findings, eventFindings...), nil
+}
+
+// componentService is a knative service of a component, the predictor owns the services of its replica pool and
+// model versions besides its default service
+type componentService struct {
+	component v1beta1.ComponentType
+	name      string
+}
+
+// degraded returns if a part of the InferenceService which does not contribute to its Ready condition is not ready:
+// the detectors report their own conditions, the replica pool and the model versions are only routed to once ready
+func degraded(isvc *v1beta1.InferenceService) bool {
+	if isvc.Spec.DriftDetector != nil && !isvc.Status.IsConditionReady(v1beta1.DriftDetectorReady) {
+		return true
+	}
+	if isvc.Spec.OutlierDetector != nil && !isvc.Status.IsConditionReady(v1beta1.OutlierDetectorReady) {
+		return true
+	}
+	predictor := isvc.Status.Components[v1beta1.PredictorComponent]
+	if isvc.Spec.Predictor.Pool != nil && (predictor.Pool == nil || predictor.Pool.LatestReadyRevision == "") {
+		return true
+	}
+	ready := map[string]bool{}
+	for _, version := range predictor.Versions {
+		ready[version.Name] = version.LatestReadyRevision != ""
+	}
+	for _, version := range isvc.Spec.Predictor.Versions {
+		if !ready[version.Name] {
+			return true
+		}
+	}
+	return false
+}
+
+// paused returns if the InferenceService, or its namespace, is paused
+func paused(isvc *v1beta1.InferenceService) bool {
+	condition := isvc.Status.GetCondition(v1beta1.PredictorReady)
+	return condition != nil && condition.Reason == v1beta1.PausedReason
+}
+
+// pending returns if the InferenceService waits for the capacity of an InferenceQuota
+func pending(isvc *v1beta1.InferenceService) bool {
+	condition := isvc.Status.GetCondition(v1beta1.Pending)
+	return condition != nil && condition.Status == v1.ConditionTrue
+}
+
+// diagnoseComponent inspects the knative service of a component, its latest created revision and the revision pods
+func diagnoseComponent(ctx context.Context, cli client.Client, component v1beta1.ComponentType, serviceName types.NamespacedName,
+	involved map[string]bool) ([]Finding, error) {
+	involved[serviceName.Name] = true
+	service := &knservingv1.Service{}
+	if err := cli.Get(ctx, serviceName, service); err != nil {
+		if apierr.IsNotFound(err) {
+			return []Finding{{
+				Component: component,
+				Resource:  "knative service " + serviceName.Name,
+				Reason:    "NotFound",
+				Message:   "the knative service has not been created, check the controller logs",
+			}}, nil
+		}
+		return nil, err
+	}
+	findings := conditionFindings(component, "knative service "+service.Name, service.Status.Conditions)
+
+	revisionName := service.Status.LatestCreatedRevisionName
+	if revisionName == "" {
+		return findings, nil
+	}
+	involved[revisionName] = true
+	revision := &knservingv1.Revision{}
+	if err := cli.Get(ctx, types.NamespacedName{Name: revisionName, Namespace: serviceName.Namespace}, revision); err != nil {
+		if apierr.IsNotFound(err) {
+			return findings, nil
+		}
+		return nil, err
+	}
+	if revision.Status.IsReady() {
+		return findings, nil
+	}
+	findings = append(findings, conditionFindings(component, "revision "+revision.Name, revision.Status.Conditions)...)
+
+	pods := &v1.PodList{}
+	if err := cli.List(ctx, pods, client.InNamespace(serviceName.Namespace),
+		client.MatchingLabels{serving.RevisionLabelKey: revisionName}); err != nil {
+		return nil, err
+	}
+	for i := range pods.Items {
+		involved[pods.Items[i].Name] = true
+		findings = append(findings, podFindings(component, &pods.Items[i])...)
+	}
+	return findings, nil
+}
+
+// conditionFindings reports the conditions which are not true, the aggregated Ready condition is skipped
+// as it repeats the cause of the other conditions.
+func conditionFindings(component v1beta1.ComponentType, resource string, conditions duckv1.Conditions) []Finding {
+	findings := []Finding{}
+	for _, condition := range conditions {
+		if condition.Type == apis.ConditionReady || condition.Status == v1.ConditionTrue {
+			continue
+		}
+		reason := fmt.Sprintf("%s is %s", condition.Type, condition.Status)
+		if condition.Reason != "" {
+			reason = fmt.Sprintf("%s (%s)", reason, condition.Reason)
+		}
+		findings = append(findings, Finding{
+			Component: component,
+			Resource:  resource,
+			Reason:    reason,
+			Message:   condition.Message,
+		})
+	}
+	return findings
+}
+
+// podFindings reports unschedulable pods and waiting or failed containers
+func podFindings(component v1beta1.ComponentType, pod *v1.Pod) []Finding {
+	findings := []Finding{}
+	resource := "pod " + pod.Name
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == v1.PodScheduled && condition.Status == v1.ConditionFalse {
+			findings = append(findings, Finding{
+				Component: component,
+				Resource:  resource,
+				Reason:    condition.Reason,
+				Message:   condition.Message,
+			})
+		}
+	}
+	statuses := append(append([]v1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, status := range statuses {
+		if waiting := status.State.Waiting; waiting != nil && waiting.Reason != "" &&
+			waiting.Reason != "ContainerCreating" && waiting.Reason != "PodInitializing" {
+			message := fmt.Sprintf("container %s with image %s", status.Name, status.Image)
+			if waiting.Message != "" {
+				message += ": " + waiting.Message
+			}
+			if terminated := status.LastTerminationState.Terminated; terminated != nil {
+				message += fmt.Sprintf(", last terminated with %s (exit code %d)", terminated.Reason, terminated.ExitCode)
+			}
+			findings = append(findings, Finding{
+				Component: component,
+				Resource:  resource,
+				Reason:    waiting.Reason,
+				Message:   message,
+			})
+		} else if terminated := status.State.Terminated; terminated != nil && terminated.ExitCode != 0 {
+			message := fmt.Sprintf("container %s with image %s exited with code %d", status.Name, status.Image, terminated.ExitCode)
+			if terminated.Message != "" {
+				message += ": " + terminated.Message
+			}
+			findings = append(findings, Finding{
+				Component: component,
+				Resource:  resource,
+				Reason:    terminated.Reason,
+				Message:   message,
+			})
+		}
+	}
+	return findings
+}
+
+// diagnoseEvents reports the most recent warning events of the involved resources
+func diagnoseEvents(ctx context.Context, cli client.Client, namespace string, involved map[string]bool) ([]Finding, error) {
+	events := &v1.EventList{}
+	if err := cli.List(ctx, events, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	warnings := []v1.Event{}
+	for _, event := range events.Items {
+		if event.Type == v1.EventTypeWarning && involved[event.InvolvedObject.Name] {
+			warnings = append(warnings, event)
+		}
+	}
+	sort.SliceStable(warnings, func(i, j int) bool {
+		return warnings[j].LastTimestamp.Before(&warnings[i].LastTimestamp)
+	})
+	if len(warnings) > MaxEvents {
+		warnings = warnings[:MaxEvents]
+	}
+	findings := []Finding{}
+	for _, event := range warnings {
+		findings = append(findings, Finding{
+			Resource: fmt.Sprintf("event on %s %s", event.InvolvedObject.Kind, event.InvolvedObject.Name),
+			Reason:   event.Reason,
+			Message:  event.Message,
+		})
+	}
+	return findings, nil
+}
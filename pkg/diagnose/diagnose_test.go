@@ -0,0 +1,251 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diagnose
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	"knative.dev/serving/pkg/apis/serving"
+	knservingv1 "knative.dev/serving/pkg/apis/serving/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestDiagnose(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scheme := runtime.NewScheme()
+	g.Expect(v1.AddToScheme(scheme)).Should(gomega.Succeed())
+	g.Expect(v1beta1.AddToScheme(scheme)).Should(gomega.Succeed())
+	g.Expect(knservingv1.AddToScheme(scheme)).Should(gomega.Succeed())
+
+	isvc := &v1beta1.InferenceService{
+		ObjectMeta: metav1.ObjectMeta{Name: "sklearn-iris", Namespace: "default"},
+		Status: v1beta1.InferenceServiceStatus{
+			Status: duckv1.Status{
+				Conditions: duckv1.Conditions{
+					{Type: v1beta1.PredictorReady, Status: v1.ConditionFalse, Reason: "RevisionMissing"},
+					{Type: apis.ConditionReady, Status: v1.ConditionFalse},
+				},
+			},
+		},
+	}
+	service := &knservingv1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "sklearn-iris-predictor-default", Namespace: "default"},
+		Status: knservingv1.ServiceStatus{
+			ConfigurationStatusFields: knservingv1.ConfigurationStatusFields{
+				LatestCreatedRevisionName: "sklearn-iris-predictor-default-00001",
+			},
+		},
+	}
+	revision := &knservingv1.Revision{
+		ObjectMeta: metav1.ObjectMeta{Name: "sklearn-iris-predictor-default-00001", Namespace: "default"},
+		Status: knservingv1.RevisionStatus{
+			Status: duckv1.Status{
+				Conditions: duckv1.Conditions{
+					{Type: knservingv1.RevisionConditionContainerHealthy, Status: v1.ConditionUnknown, Reason: "Deploying"},
+				},
+			},
+		},
+	}
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "sklearn-iris-predictor-default-00001-deployment-5f8d",
+			Namespace: "default",
+			Labels:    map[string]string{serving.RevisionLabelKey: "sklearn-iris-predictor-default-00001"},
+		},
+		Status: v1.PodStatus{
+			ContainerStatuses: []v1.ContainerStatus{
+				{
+					Name:  "kfserving-container",
+					Image: "kfserving/sklearnserver:missing",
+					State: v1.ContainerState{
+						Waiting: &v1.ContainerStateWaiting{Reason: "ImagePullBackOff", Message: "Back-off pulling image"},
+					},
+				},
+			},
+		},
+	}
+	event := &v1.Event{
+		ObjectMeta: metav1.ObjectMeta{Name: "pull-failed", Namespace: "default"},
+		InvolvedObject: v1.ObjectReference{
+			Kind: "Pod",
+			Name: "sklearn-iris-predictor-default-00001-deployment-5f8d",
+		},
+		Type:    v1.EventTypeWarning,
+		Reason:  "Failed",
+		Message: "Failed to pull image",
+	}
+	ignoredEvent := &v1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: "other", Namespace: "default"},
+		InvolvedObject: v1.ObjectReference{Kind: "Pod", Name: "other"},
+		Type:           v1.EventTypeWarning,
+		Reason:         "Failed",
+	}
+	cli := fake.NewFakeClientWithScheme(scheme, isvc, service, revision, pod, event, ignoredEvent)
+
+	findings, err := Diagnose(context.TODO(), cli, isvc)
+	g.Expect(err).Should(gomega.BeNil())
+	messages := []string{}
+	for _, finding := range findings {
+		messages = append(messages, finding.String())
+	}
+	g.Expect(messages).To(gomega.Equal([]string{
+		"InferenceService sklearn-iris: PredictorReady is False (RevisionMissing)",
+		"predictor revision sklearn-iris-predictor-default-00001: ContainerHealthy is Unknown (Deploying)",
+		"predictor pod sklearn-iris-predictor-default-00001-deployment-5f8d: ImagePullBackOff: " +
+			"container kfserving-container with image kfserving/sklearnserver:missing: Back-off pulling image",
+		"event on Pod sklearn-iris-predictor-default-00001-deployment-5f8d: Failed: Failed to pull image",
+	}))
+}
+
+func TestDiagnoseMissingService(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scheme := runtime.NewScheme()
+	g.Expect(v1.AddToScheme(scheme)).Should(gomega.Succeed())
+	g.Expect(knservingv1.AddToScheme(scheme)).Should(gomega.Succeed())
+	isvc := &v1beta1.InferenceService{
+		ObjectMeta: metav1.ObjectMeta{Name: "sklearn-iris", Namespace: "default"},
+	}
+	cli := fake.NewFakeClientWithScheme(scheme)
+
+	findings, err := Diagnose(context.TODO(), cli, isvc)
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(findings).To(gomega.Equal([]Finding{{
+		Component: v1beta1.PredictorComponent,
+		Resource:  "knative service sklearn-iris-predictor-default",
+		Reason:    "NotFound",
+		Message:   "the knative service has not been created, check the controller logs",
+	}}))
+}
+
+func TestDiagnosePausedAndPending(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scheme := runtime.NewScheme()
+	g.Expect(v1.AddToScheme(scheme)).Should(gomega.Succeed())
+	g.Expect(knservingv1.AddToScheme(scheme)).Should(gomega.Succeed())
+	scenarios := map[string]struct {
+		conditions duckv1.Conditions
+		expected   []string
+	}{
+		"Paused": {
+			conditions: duckv1.Conditions{
+				{Type: v1beta1.PredictorReady, Status: v1.ConditionFalse, Reason: v1beta1.PausedReason,
+					Message: "InferenceService is paused"},
+				{Type: apis.ConditionReady, Status: v1.ConditionFalse, Reason: v1beta1.PausedReason},
+			},
+			expected: []string{"InferenceService sklearn-iris: PredictorReady is False (Paused): InferenceService is paused"},
+		},
+		"Pending": {
+			conditions: duckv1.Conditions{
+				{Type: v1beta1.Pending, Status: v1.ConditionTrue, Reason: v1beta1.QuotaExceededReason},
+				{Type: v1beta1.PredictorReady, Status: v1.ConditionUnknown, Reason: v1beta1.QuotaExceededReason,
+					Message: "exceeds 4 GPUs of InferenceQuota default"},
+				{Type: apis.ConditionReady, Status: v1.ConditionUnknown, Reason: v1beta1.QuotaExceededReason},
+			},
+			expected: []string{"InferenceService sklearn-iris: PredictorReady is Unknown (QuotaExceeded): " +
+				"exceeds 4 GPUs of InferenceQuota default"},
+		},
+	}
+	for name, scenario := range scenarios {
+		isvc := &v1beta1.InferenceService{
+			ObjectMeta: metav1.ObjectMeta{Name: "sklearn-iris", Namespace: "default"},
+			Status: v1beta1.InferenceServiceStatus{
+				Status: duckv1.Status{Conditions: scenario.conditions},
+			},
+		}
+		cli := fake.NewFakeClientWithScheme(scheme)
+
+		findings, err := Diagnose(context.TODO(), cli, isvc)
+		g.Expect(err).Should(gomega.BeNil(), name)
+		messages := []string{}
+		for _, finding := range findings {
+			messages = append(messages, finding.String())
+		}
+		g.Expect(messages).To(gomega.Equal(scenario.expected), name)
+	}
+}
+
+func TestDiagnoseDegraded(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scheme := runtime.NewScheme()
+	g.Expect(v1.AddToScheme(scheme)).Should(gomega.Succeed())
+	g.Expect(knservingv1.AddToScheme(scheme)).Should(gomega.Succeed())
+	ready := duckv1.Conditions{
+		{Type: v1beta1.PredictorReady, Status: v1.ConditionTrue},
+		{Type: v1beta1.IngressReady, Status: v1.ConditionTrue},
+		{Type: apis.ConditionReady, Status: v1.ConditionTrue},
+	}
+	isvc := &v1beta1.InferenceService{
+		ObjectMeta: metav1.ObjectMeta{Name: "sklearn-iris", Namespace: "default"},
+		Spec: v1beta1.InferenceServiceSpec{
+			Predictor: v1beta1.PredictorSpec{
+				Pool:     &v1beta1.ReplicaPoolSpec{},
+				Versions: []v1beta1.ModelVersionSpec{{Name: "v1", StorageURI: "gs://kfserving-samples/models/sklearn/iris"}},
+			},
+			OutlierDetector: &v1beta1.OutlierDetectorSpec{},
+		},
+		Status: v1beta1.InferenceServiceStatus{
+			Status: duckv1.Status{
+				Conditions: append(ready, apis.Condition{Type: v1beta1.OutlierDetectorReady, Status: v1.ConditionFalse,
+					Reason: "RevisionMissing"}),
+			},
+			Components: map[v1beta1.ComponentType]v1beta1.ComponentStatusSpec{
+				v1beta1.PredictorComponent: {
+					Versions: []v1beta1.ModelVersionStatus{{Name: "v1", LatestReadyRevision: "sklearn-iris-predictor-version-v1-00001"}},
+				},
+			},
+		},
+	}
+	readyService := func(name string) *knservingv1.Service {
+		return &knservingv1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Status:     knservingv1.ServiceStatus{Status: duckv1.Status{Conditions: ready[2:]}},
+		}
+	}
+	pool := readyService("sklearn-iris-predictor-pool")
+	pool.Status.Conditions = duckv1.Conditions{
+		{Type: knservingv1.ServiceConditionConfigurationsReady, Status: v1.ConditionFalse, Reason: "RevisionFailed"},
+	}
+	cli := fake.NewFakeClientWithScheme(scheme, readyService("sklearn-iris-predictor-default"), pool,
+		readyService("sklearn-iris-predictor-version-v1"))
+
+	findings, err := Diagnose(context.TODO(), cli, isvc)
+	g.Expect(err).Should(gomega.BeNil())
+	messages := []string{}
+	for _, finding := range findings {
+		messages = append(messages, finding.String())
+	}
+	g.Expect(messages).To(gomega.Equal([]string{
+		"InferenceService sklearn-iris: OutlierDetectorReady is False (RevisionMissing)",
+		"predictor knative service sklearn-iris-predictor-pool: ConfigurationsReady is False (RevisionFailed)",
+		"outlierDetector knative service sklearn-iris-outlier-detector-default: NotFound: " +
+			"the knative service has not been created, check the controller logs",
+	}))
+
+	isvc.Spec.Predictor.Pool = nil
+	isvc.Status.Conditions = append(ready, apis.Condition{Type: v1beta1.OutlierDetectorReady, Status: v1.ConditionTrue})
+	findings, err = Diagnose(context.TODO(), cli, isvc)
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(findings).To(gomega.BeNil())
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package digest pins the container images of the knative service templates to the digests of their tags
+package digest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Pinner resolves the image tags of the pod specs to digests with the registry API
+type Pinner struct {
+	client   client.Client
+	config   *v1beta1.DigestPinningConfig
+	resolver *digestResolver
+}
+
+// NewPinner returns the pinner of the digest pinning config, or nil when the digest pinning is not enabled
+func NewPinner(client client.Client, config *v1beta1.DigestPinningConfig) *Pinner {
+	if !config.Enabled {
+		return nil
+	}
+	return &Pinner{
+		client: client,
+		config: config,
+		resolver: &digestResolver{
+			client:             &http.Client{Timeout: time.Duration(config.TimeoutSeconds) * time.Second},
+			insecureRegistries: config.InsecureRegistries,
+		},
+	}
+}
+
+// Pin replaces the tags of the container images of the pod spec with their digests so that all the pods of a revision
+// run the images which were reviewed, and a rollback runs the same images again. The images in pinned were already
+// resolved, e.g. for the previous template, they are mapped to their pinned images and the registries are not queried
+// again. Pin returns the images the containers were pinned from as <container>=<image>.
+func (p *Pinner) Pin(namespace string, podSpec *v1.PodSpec, pinned map[string]string) ([]string, error) {
+	var credentials map[string]registryCredentials
+	tags := []string{}
+	for _, containers := range [][]v1.Container{podSpec.InitContainers, podSpec.Containers} {
+		for i := range containers {
+			container := &containers[i]
+			reference, err := parseImageReference(container.Image)
+			if err != nil {
+				return nil, err
+			}
+			if reference.digest != "" || p.skips(reference.registry) {
+				continue
+			}
+			image, ok := pinned[container.Image]
+			if !ok {
+				if credentials == nil {
+					if credentials, err = p.pullCredentials(namespace, podSpec); err != nil {
+						return nil, err
+					}
+				}
+				var auth *registryCredentials
+				if c, ok := credentials[reference.registry]; ok {
+					auth = &c
+				}
+				digest, err := p.resolver.resolve(reference, auth)
+				if err != nil {
+					return nil, fmt.Errorf("fails to resolve the digest of image %s: %v", container.Image, err)
+				}
+				image = reference.name + "@" + digest
+			}
+			tags = append(tags, container.Name+"="+container.Image)
+			container.Image = image
+		}
+	}
+	return tags, nil
+}
+
+// PinnedImages returns the images of the containers pinned to digests by the image they were pinned from, the tags are
+// the <container>=<image> pairs Pin returned. The containers whose image is not a digest of the image they were
+// pinned from are left out, e.g. after an out of band change.
+func PinnedImages(tags []string, podSpec *v1.PodSpec) map[string]string {
+	images := map[string]string{}
+	for _, containers := range [][]v1.Container{podSpec.InitContainers, podSpec.Containers} {
+		for _, container := range containers {
+			images[container.Name] = container.Image
+		}
+	}
+	pinned := map[string]string{}
+	for _, tag := range tags {
+		parts := strings.SplitN(tag, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		reference, err := parseImageReference(parts[1])
+		if err != nil {
+			continue
+		}
+		if image, ok := images[parts[0]]; ok && strings.HasPrefix(image, reference.name+"@sha256:") {
+			pinned[parts[1]] = image
+		}
+	}
+	return pinned
+}
+
+func (p *Pinner) skips(registry string) bool {
+	for _, skipped := range p.config.SkipRegistries {
+		if skipped == registry {
+			return true
+		}
+	}
+	return false
+}
+
+// pullCredentials reads the registry credentials of the image pull secrets of the pod spec
+func (p *Pinner) pullCredentials(namespace string, podSpec *v1.PodSpec) (map[string]registryCredentials, error) {
+	credentials := map[string]registryCredentials{}
+	for _, reference := range podSpec.ImagePullSecrets {
+		secret := &v1.Secret{}
+		if err := p.client.Get(context.TODO(), types.NamespacedName{Name: reference.Name, Namespace: namespace}, secret); err != nil {
+			if client.IgnoreNotFound(err) == nil {
+				continue
+			}
+			return nil, err
+		}
+		data, ok := secret.Data[v1.DockerConfigJsonKey]
+		if !ok {
+			data, ok = secret.Data[v1.DockerConfigKey]
+		}
+		if !ok {
+			continue
+		}
+		if err := parseDockerConfig(data, credentials); err != nil {
+			return nil, fmt.Errorf("fails to read image pull secret %s: %v", reference.Name, err)
+		}
+	}
+	return credentials, nil
+}
@@ -0,0 +1,228 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package digest
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"knative.dev/pkg/kmp"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const testDigest = "sha256:4b1a0d8dd3e6b5a8f4d1e0b2c4d5e6f708192a3b4c5d6e7f8091a2b3c4d5e6f7"
+
+// newTestRegistry serves the manifest of the image tags to the clients authenticated with a bearer token, the token
+// service requires the credentials of user
+func newTestRegistry(t *testing.T) *httptest.Server {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/token":
+			if username, password, ok := r.BasicAuth(); !ok || username != "user" || password != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if r.URL.Query().Get("scope") != "repository:models/sklearn:pull" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			fmt.Fprint(w, `{"token": "pull-token"}`)
+		case r.URL.Path == "/v2/models/sklearn/manifests/v0.5.0":
+			if r.Header.Get("Authorization") != "Bearer pull-token" {
+				w.Header().Set("WWW-Authenticate",
+					fmt.Sprintf(`Bearer realm="%s/token",service="test-registry"`, server.URL))
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if !strings.Contains(r.Header.Get("Accept"), "application/vnd.docker.distribution.manifest.list.v2+json") {
+				t.Errorf("unexpected accept header %q", r.Header.Get("Accept"))
+			}
+			w.Header().Set("Docker-Content-Digest", testDigest)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return server
+}
+
+func TestPin(t *testing.T) {
+	server := newTestRegistry(t)
+	defer server.Close()
+	registry := strings.TrimPrefix(server.URL, "http://")
+	auth := base64.StdEncoding.EncodeToString([]byte("user:secret"))
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "registry", Namespace: "default"},
+		Type:       v1.SecretTypeDockerConfigJson,
+		Data: map[string][]byte{
+			v1.DockerConfigJsonKey: []byte(fmt.Sprintf(`{"auths": {"http://%s": {"auth": "%s"}}}`, registry, auth)),
+		},
+	}
+	pinner := NewPinner(fake.NewFakeClientWithScheme(scheme.Scheme, secret), &v1beta1.DigestPinningConfig{
+		Enabled:            true,
+		SkipRegistries:     []string{"ko.local"},
+		InsecureRegistries: []string{registry},
+		TimeoutSeconds:     v1beta1.DefaultDigestTimeoutSeconds,
+	})
+	scenarios := map[string]struct {
+		original v1.PodSpec
+		pinned   map[string]string
+		expected v1.PodSpec
+		tags     []string
+		err      bool
+	}{
+		"PinnedTags": {
+			original: v1.PodSpec{
+				ImagePullSecrets: []v1.LocalObjectReference{{Name: "registry"}},
+				Containers: []v1.Container{
+					{Name: constants.InferenceServiceContainerName, Image: registry + "/models/sklearn:v0.5.0"},
+					{Name: "transformer", Image: "ko.local/transformer:latest"},
+				},
+			},
+			expected: v1.PodSpec{
+				ImagePullSecrets: []v1.LocalObjectReference{{Name: "registry"}},
+				Containers: []v1.Container{
+					{Name: constants.InferenceServiceContainerName, Image: registry + "/models/sklearn@" + testDigest},
+					{Name: "transformer", Image: "ko.local/transformer:latest"},
+				},
+			},
+			tags: []string{constants.InferenceServiceContainerName + "=" + registry + "/models/sklearn:v0.5.0"},
+		},
+		"PinnedDigest": {
+			original: v1.PodSpec{
+				Containers: []v1.Container{
+					{Name: constants.InferenceServiceContainerName, Image: registry + "/models/sklearn@" + testDigest},
+				},
+			},
+			expected: v1.PodSpec{
+				Containers: []v1.Container{
+					{Name: constants.InferenceServiceContainerName, Image: registry + "/models/sklearn@" + testDigest},
+				},
+			},
+			tags: []string{},
+		},
+		"PreviouslyPinned": {
+			// the registry is not queried, the pod spec has no credentials
+			original: v1.PodSpec{
+				Containers: []v1.Container{
+					{Name: constants.InferenceServiceContainerName, Image: registry + "/models/sklearn:v0.4.0"},
+				},
+			},
+			pinned: map[string]string{
+				registry + "/models/sklearn:v0.4.0": registry + "/models/sklearn@" + testDigest,
+			},
+			expected: v1.PodSpec{
+				Containers: []v1.Container{
+					{Name: constants.InferenceServiceContainerName, Image: registry + "/models/sklearn@" + testDigest},
+				},
+			},
+			tags: []string{constants.InferenceServiceContainerName + "=" + registry + "/models/sklearn:v0.4.0"},
+		},
+		"MissingCredentials": {
+			original: v1.PodSpec{
+				Containers: []v1.Container{
+					{Name: constants.InferenceServiceContainerName, Image: registry + "/models/sklearn:v0.5.0"},
+				},
+			},
+			err: true,
+		},
+	}
+
+	for name, scenario := range scenarios {
+		tags, err := pinner.Pin("default", &scenario.original, scenario.pinned)
+		if scenario.err {
+			if err == nil {
+				t.Errorf("Test %q expected an error", name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test %q unexpected error %v", name, err)
+		}
+		if diff, _ := kmp.SafeDiff(scenario.expected, scenario.original); diff != "" {
+			t.Errorf("Test %q unexpected result (-want +got): %v", name, diff)
+		}
+		if diff, _ := kmp.SafeDiff(scenario.tags, tags); diff != "" {
+			t.Errorf("Test %q unexpected tags (-want +got): %v", name, diff)
+		}
+	}
+}
+
+func TestPinnedImages(t *testing.T) {
+	podSpec := &v1.PodSpec{
+		Containers: []v1.Container{
+			{Name: constants.InferenceServiceContainerName, Image: "gcr.io/models/sklearn@" + testDigest},
+			{Name: "transformer", Image: "gcr.io/models/transformer:v2"},
+		},
+	}
+	pinned := PinnedImages([]string{
+		constants.InferenceServiceContainerName + "=gcr.io/models/sklearn:v0.5.0",
+		// changed out of band
+		"transformer=gcr.io/models/transformer:v1",
+		"explainer=gcr.io/models/explainer:v1",
+		"invalid",
+	}, podSpec)
+	expected := map[string]string{"gcr.io/models/sklearn:v0.5.0": "gcr.io/models/sklearn@" + testDigest}
+	if diff, _ := kmp.SafeDiff(expected, pinned); diff != "" {
+		t.Errorf("unexpected pinned images (-want +got): %v", diff)
+	}
+}
+
+func TestNewPinner(t *testing.T) {
+	if pinner := NewPinner(nil, &v1beta1.DigestPinningConfig{}); pinner != nil {
+		t.Errorf("unexpected pinner of a disabled digest pinning config")
+	}
+}
+
+func TestParseImageReference(t *testing.T) {
+	scenarios := map[string]imageReference{
+		"tensorflow/serving:1.14.0": {
+			name: "tensorflow/serving", registry: DockerHubRegistry, repository: "tensorflow/serving", tag: "1.14.0",
+		},
+		"busybox": {
+			name: "busybox", registry: DockerHubRegistry, repository: "library/busybox", tag: "latest",
+		},
+		"localhost:5000/kfserving/sklearnserver@" + testDigest: {
+			name: "localhost:5000/kfserving/sklearnserver", registry: "localhost:5000",
+			repository: "kfserving/sklearnserver", tag: "latest", digest: testDigest,
+		},
+		"gcr.io/kfserving/storage-initializer:v0.5.0": {
+			name: "gcr.io/kfserving/storage-initializer", registry: "gcr.io",
+			repository: "kfserving/storage-initializer", tag: "v0.5.0",
+		},
+	}
+
+	for image, expected := range scenarios {
+		reference, err := parseImageReference(image)
+		if err != nil {
+			t.Errorf("Test %q unexpected error %v", image, err)
+			continue
+		}
+		if *reference != expected {
+			t.Errorf("Test %q unexpected reference %+v", image, *reference)
+		}
+	}
+}
@@ -0,0 +1,278 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package digest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	// DockerHubRegistry is the registry of the images which do not name one
+	DockerHubRegistry = "docker.io"
+	// dockerHubAPIHost serves the registry API of docker hub
+	dockerHubAPIHost = "registry-1.docker.io"
+)
+
+// The manifest lists are preferred so that an image resolves to the same digest on all the node architectures
+var manifestMediaTypes = []string{
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+}
+
+// imageReference is a parsed container image, [registry/]repository[:tag][@digest]
+type imageReference struct {
+	// name is the image without its tag and digest, as it is written in the container
+	name       string
+	registry   string
+	repository string
+	tag        string
+	digest     string
+}
+
+// registryCredentials are the credentials of a registry read from the image pull secrets
+type registryCredentials struct {
+	username string
+	password string
+}
+
+// digestResolver resolves the image tags to digests with the registry API
+type digestResolver struct {
+	client *http.Client
+	// registries served over http
+	insecureRegistries []string
+}
+
+func parseImageReference(image string) (*imageReference, error) {
+	reference := &imageReference{}
+	name := image
+	if i := strings.Index(name, "@"); i >= 0 {
+		name, reference.digest = name[:i], name[i+1:]
+	}
+	reference.tag = "latest"
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name, reference.tag = name[:i], name[i+1:]
+	}
+	reference.name = name
+	reference.registry, reference.repository = DockerHubRegistry, name
+	if i := strings.Index(name, "/"); i >= 0 && (strings.ContainsAny(name[:i], ".:") || name[:i] == "localhost") {
+		reference.registry, reference.repository = name[:i], name[i+1:]
+	}
+	if reference.registry == DockerHubRegistry && !strings.Contains(reference.repository, "/") {
+		reference.repository = "library/" + reference.repository
+	}
+	if reference.repository == "" || reference.tag == "" {
+		return nil, fmt.Errorf("invalid image %q", image)
+	}
+	return reference, nil
+}
+
+// normalizeRegistry returns the registry of a docker config entry, e.g. https://index.docker.io/v1/ is docker.io
+func normalizeRegistry(registry string) string {
+	registry = strings.TrimPrefix(strings.TrimPrefix(registry, "https://"), "http://")
+	if i := strings.Index(registry, "/"); i >= 0 {
+		registry = registry[:i]
+	}
+	switch registry {
+	case "index.docker.io", dockerHubAPIHost:
+		return DockerHubRegistry
+	}
+	return registry
+}
+
+// parseDockerConfig reads the credentials of the registries of a docker config, either a config.json with auths or a
+// legacy .dockercfg
+func parseDockerConfig(data []byte, credentials map[string]registryCredentials) error {
+	type entry struct {
+		Auth     string `json:"auth,omitempty"`
+		Username string `json:"username,omitempty"`
+		Password string `json:"password,omitempty"`
+	}
+	config := struct {
+		Auths map[string]entry `json:"auths"`
+	}{}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return err
+	}
+	if config.Auths == nil {
+		if err := json.Unmarshal(data, &config.Auths); err != nil {
+			return err
+		}
+	}
+	for registry, auth := range config.Auths {
+		username, password := auth.Username, auth.Password
+		if auth.Auth != "" {
+			decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+			if err != nil {
+				return fmt.Errorf("invalid auth of registry %s: %v", registry, err)
+			}
+			parts := strings.SplitN(string(decoded), ":", 2)
+			if len(parts) != 2 {
+				return fmt.Errorf("invalid auth of registry %s", registry)
+			}
+			username, password = parts[0], parts[1]
+		}
+		credentials[normalizeRegistry(registry)] = registryCredentials{username: username, password: password}
+	}
+	return nil
+}
+
+// resolve returns the digest of the manifest of the image tag, the registry is authenticated with the credentials
+// when it challenges the anonymous request
+func (r *digestResolver) resolve(reference *imageReference, credentials *registryCredentials) (string, error) {
+	host, scheme := reference.registry, "https"
+	if host == DockerHubRegistry {
+		host = dockerHubAPIHost
+	}
+	for _, registry := range r.insecureRegistries {
+		if registry == reference.registry {
+			scheme = "http"
+		}
+	}
+	manifestURL := fmt.Sprintf("%s://%s/v2/%s/manifests/%s", scheme, host, reference.repository, reference.tag)
+	resp, err := r.headManifest(manifestURL, "")
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		authorization, err := r.authorize(resp.Header.Get("WWW-Authenticate"), reference, credentials)
+		if err != nil {
+			return "", err
+		}
+		if resp, err = r.headManifest(manifestURL, authorization); err != nil {
+			return "", err
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("registry %s returned %s for %s:%s", reference.registry, resp.Status,
+			reference.repository, reference.tag)
+	}
+	digest := resp.Header.Get("Docker-Content-Digest")
+	if !strings.HasPrefix(digest, "sha256:") {
+		return "", fmt.Errorf("registry %s returned no digest for %s:%s", reference.registry, reference.repository,
+			reference.tag)
+	}
+	return digest, nil
+}
+
+func (r *digestResolver) headManifest(manifestURL string, authorization string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodHead, manifestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", strings.Join(manifestMediaTypes, ","))
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	return resp, nil
+}
+
+// authorize answers the challenge of the registry, a bearer token is requested from the token service of the
+// registry with the credentials if any
+func (r *digestResolver) authorize(challenge string, reference *imageReference, credentials *registryCredentials) (string, error) {
+	scheme, params := parseChallenge(challenge)
+	switch strings.ToLower(scheme) {
+	case "basic":
+		if credentials == nil {
+			return "", fmt.Errorf("registry %s requires credentials", reference.registry)
+		}
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(credentials.username+":"+credentials.password)), nil
+	case "bearer":
+		tokenURL, err := url.Parse(params["realm"])
+		if err != nil || params["realm"] == "" {
+			return "", fmt.Errorf("registry %s returned an invalid token realm %q", reference.registry, params["realm"])
+		}
+		query := tokenURL.Query()
+		if service, ok := params["service"]; ok {
+			query.Set("service", service)
+		}
+		query.Set("scope", fmt.Sprintf("repository:%s:pull", reference.repository))
+		tokenURL.RawQuery = query.Encode()
+		req, err := http.NewRequest(http.MethodGet, tokenURL.String(), nil)
+		if err != nil {
+			return "", err
+		}
+		if credentials != nil {
+			req.SetBasicAuth(credentials.username, credentials.password)
+		}
+		resp, err := r.client.Do(req)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("token service of registry %s returned %s", reference.registry, resp.Status)
+		}
+		token := struct {
+			Token       string `json:"token"`
+			AccessToken string `json:"access_token"`
+		}{}
+		if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+			return "", err
+		}
+		if token.Token == "" {
+			token.Token = token.AccessToken
+		}
+		return "Bearer " + token.Token, nil
+	}
+	return "", fmt.Errorf("registry %s returned an unsupported challenge %q", reference.registry, challenge)
+}
+
+// parseChallenge parses a WWW-Authenticate header, e.g. Bearer realm="https://auth.docker.io/token",service="registry.docker.io"
+func parseChallenge(challenge string) (string, map[string]string) {
+	params := map[string]string{}
+	parts := strings.SplitN(strings.TrimSpace(challenge), " ", 2)
+	if len(parts) < 2 {
+		return parts[0], params
+	}
+	rest := parts[1]
+	for rest != "" {
+		i := strings.Index(rest, "=")
+		if i < 0 {
+			break
+		}
+		key := strings.ToLower(strings.TrimSpace(rest[:i]))
+		rest = rest[i+1:]
+		value := ""
+		if strings.HasPrefix(rest, "\"") {
+			end := strings.Index(rest[1:], "\"")
+			if end < 0 {
+				value, rest = rest[1:], ""
+			} else {
+				value, rest = rest[1:end+1], rest[end+2:]
+			}
+		} else if end := strings.Index(rest, ","); end >= 0 {
+			value, rest = rest[:end], rest[end:]
+		} else {
+			value, rest = rest, ""
+		}
+		params[key] = value
+		rest = strings.TrimLeft(rest, ", ")
+	}
+	return parts[0], params
+}
@@ -0,0 +1,311 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package isvcbuilder provides a fluent builder for v1beta1 InferenceServices, e.g.
+//
+//	isvc, err := isvcbuilder.New("sklearn-iris").
+//		Namespace("models").
+//		Sklearn("gs://kfserving-samples/models/sklearn/iris").
+//		GPU(1).
+//		Canary(10).
+//		Build(config)
+package isvcbuilder
+
+import (
+	"fmt"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Known error messages
+const (
+	NoPredictorError        = "InferenceService %q has no predictor, one of the predictor methods must be called"
+	MultiplePredictorsError = "InferenceService %q has more than one predictor, %s was already specified"
+)
+
+// Builder builds a v1beta1 InferenceService
+type Builder struct {
+	isvc *v1beta1.InferenceService
+	// predictorName is the name of the selected predictor implementation
+	predictorName  string
+	runtimeVersion *string
+	protocol       *constants.InferenceServiceProtocol
+	resources      *v1.ResourceRequirements
+	gpus           *int64
+	errs           []error
+}
+
+// New creates a builder for an InferenceService with the given name
+func New(name string) *Builder {
+	return &Builder{
+		isvc: &v1beta1.InferenceService{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: v1beta1.SchemeGroupVersion.String(),
+				Kind:       "InferenceService",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+			},
+		},
+	}
+}
+
+// Namespace sets the namespace of the InferenceService
+func (b *Builder) Namespace(namespace string) *Builder {
+	b.isvc.Namespace = namespace
+	return b
+}
+
+// Labels adds labels to the InferenceService
+func (b *Builder) Labels(labels map[string]string) *Builder {
+	if b.isvc.Labels == nil {
+		b.isvc.Labels = map[string]string{}
+	}
+	for k, v := range labels {
+		b.isvc.Labels[k] = v
+	}
+	return b
+}
+
+// Annotations adds annotations to the InferenceService
+func (b *Builder) Annotations(annotations map[string]string) *Builder {
+	if b.isvc.Annotations == nil {
+		b.isvc.Annotations = map[string]string{}
+	}
+	for k, v := range annotations {
+		b.isvc.Annotations[k] = v
+	}
+	return b
+}
+
+// Sklearn selects the SKLearn model server for the predictor
+func (b *Builder) Sklearn(storageURI string) *Builder {
+	spec := &v1beta1.SKLearnSpec{}
+	b.setPredictor("SKLearn", &spec.PredictorExtensionSpec, storageURI, func() { b.isvc.Spec.Predictor.SKLearn = spec })
+	return b
+}
+
+// XGBoost selects the XGBoost model server for the predictor
+func (b *Builder) XGBoost(storageURI string) *Builder {
+	spec := &v1beta1.XGBoostSpec{}
+	b.setPredictor("XGBoost", &spec.PredictorExtensionSpec, storageURI, func() { b.isvc.Spec.Predictor.XGBoost = spec })
+	return b
+}
+
+// Tensorflow selects TFServing for the predictor
+func (b *Builder) Tensorflow(storageURI string) *Builder {
+	spec := &v1beta1.TFServingSpec{}
+	b.setPredictor("Tensorflow", &spec.PredictorExtensionSpec, storageURI, func() { b.isvc.Spec.Predictor.Tensorflow = spec })
+	return b
+}
+
+// PyTorch selects TorchServe for the predictor
+func (b *Builder) PyTorch(storageURI string, modelClassName string) *Builder {
+	spec := &v1beta1.TorchServeSpec{ModelClassName: modelClassName}
+	b.setPredictor("PyTorch", &spec.PredictorExtensionSpec, storageURI, func() { b.isvc.Spec.Predictor.PyTorch = spec })
+	return b
+}
+
+// Triton selects Triton Inference Server for the predictor
+func (b *Builder) Triton(storageURI string) *Builder {
+	spec := &v1beta1.TritonSpec{}
+	b.setPredictor("Triton", &spec.PredictorExtensionSpec, storageURI, func() { b.isvc.Spec.Predictor.Triton = spec })
+	return b
+}
+
+// ONNX selects ONNX runtime for the predictor
+func (b *Builder) ONNX(storageURI string) *Builder {
+	spec := &v1beta1.ONNXRuntimeSpec{}
+	b.setPredictor("ONNX", &spec.PredictorExtensionSpec, storageURI, func() { b.isvc.Spec.Predictor.ONNX = spec })
+	return b
+}
+
+// PMML selects the PMML model server for the predictor
+func (b *Builder) PMML(storageURI string) *Builder {
+	spec := &v1beta1.PMMLSpec{}
+	b.setPredictor("PMML", &spec.PredictorExtensionSpec, storageURI, func() { b.isvc.Spec.Predictor.PMML = spec })
+	return b
+}
+
+// CustomPredictor uses the given container as a custom predictor
+func (b *Builder) CustomPredictor(container v1.Container) *Builder {
+	b.setPredictor("Custom", nil, "", func() {
+		b.isvc.Spec.Predictor.PodSpec.Containers = []v1.Container{container}
+	})
+	return b
+}
+
+func (b *Builder) setPredictor(name string, spec *v1beta1.PredictorExtensionSpec, storageURI string, set func()) {
+	if b.predictorName != "" {
+		b.errs = append(b.errs, fmt.Errorf(MultiplePredictorsError, b.isvc.Name, b.predictorName))
+		return
+	}
+	if spec != nil && storageURI != "" {
+		spec.StorageURI = &storageURI
+	}
+	b.predictorName = name
+	set()
+}
+
+// RuntimeVersion sets the runtime version of the predictor image
+func (b *Builder) RuntimeVersion(version string) *Builder {
+	b.runtimeVersion = &version
+	return b
+}
+
+// Protocol sets the inference protocol of the predictor
+func (b *Builder) Protocol(protocol constants.InferenceServiceProtocol) *Builder {
+	b.protocol = &protocol
+	return b
+}
+
+// Resources sets the resource requirements of the predictor container
+func (b *Builder) Resources(resources v1.ResourceRequirements) *Builder {
+	b.resources = &resources
+	return b
+}
+
+// GPU requests the given number of nvidia GPUs for the predictor container
+func (b *Builder) GPU(count int64) *Builder {
+	b.gpus = &count
+	return b
+}
+
+// MinReplicas sets the minimum number of predictor replicas, 0 enables scale to zero
+func (b *Builder) MinReplicas(replicas int) *Builder {
+	b.isvc.Spec.Predictor.MinReplicas = &replicas
+	return b
+}
+
+// MaxReplicas sets the maximum number of predictor replicas
+func (b *Builder) MaxReplicas(replicas int) *Builder {
+	b.isvc.Spec.Predictor.MaxReplicas = replicas
+	return b
+}
+
+// Canary sets the percentage of traffic routed to the latest predictor revision
+func (b *Builder) Canary(percent int64) *Builder {
+	b.isvc.Spec.Predictor.CanaryTrafficPercent = &percent
+	return b
+}
+
+// Timeout sets the predictor request timeout in seconds
+func (b *Builder) Timeout(seconds int64) *Builder {
+	b.isvc.Spec.Predictor.TimeoutSeconds = &seconds
+	return b
+}
+
+// ContainerConcurrency sets the hard limit of concurrent requests per predictor container
+func (b *Builder) ContainerConcurrency(concurrency int64) *Builder {
+	b.isvc.Spec.Predictor.ContainerConcurrency = &concurrency
+	return b
+}
+
+// Logger enables payload logging on the predictor
+func (b *Builder) Logger(url string, mode v1beta1.LoggerType) *Builder {
+	logger := &v1beta1.LoggerSpec{Mode: mode}
+	if url != "" {
+		logger.URL = &url
+	}
+	b.isvc.Spec.Predictor.Logger = logger
+	return b
+}
+
+// Batcher enables request batching on the predictor
+func (b *Builder) Batcher(maxBatchSize int, maxLatency int) *Builder {
+	b.isvc.Spec.Predictor.Batcher = &v1beta1.Batcher{
+		MaxBatchSize: &maxBatchSize,
+		MaxLatency:   &maxLatency,
+	}
+	return b
+}
+
+// ServiceAccount sets the service account of the predictor, used to look up storage credentials
+func (b *Builder) ServiceAccount(name string) *Builder {
+	b.isvc.Spec.Predictor.ServiceAccountName = name
+	return b
+}
+
+// Transformer adds a custom transformer running the given container
+func (b *Builder) Transformer(container v1.Container) *Builder {
+	b.isvc.Spec.Transformer = &v1beta1.TransformerSpec{
+		PodSpec: v1beta1.PodSpec{
+			Containers: []v1.Container{container},
+		},
+	}
+	return b
+}
+
+// AlibiExplainer adds an Alibi explainer of the given type
+func (b *Builder) AlibiExplainer(explainerType v1beta1.AlibiExplainerType, storageURI string) *Builder {
+	b.isvc.Spec.Explainer = &v1beta1.ExplainerSpec{
+		Alibi: &v1beta1.AlibiExplainerSpec{
+			Type:       explainerType,
+			StorageURI: storageURI,
+		},
+	}
+	return b
+}
+
+// Build returns the InferenceService with the defaults from the given config applied,
+// or an error if the resulting InferenceService is invalid.
+func (b *Builder) Build(config *v1beta1.InferenceServicesConfig) (*v1beta1.InferenceService, error) {
+	if len(b.errs) != 0 {
+		return nil, b.errs[0]
+	}
+	if b.predictorName == "" {
+		return nil, fmt.Errorf(NoPredictorError, b.isvc.Name)
+	}
+	isvc := b.isvc.DeepCopy()
+	if err := b.applyPredictorOptions(isvc); err != nil {
+		return nil, err
+	}
+	isvc.DefaultInferenceService(config)
+	if err := isvc.ValidateCreate(); err != nil {
+		return nil, err
+	}
+	return isvc, nil
+}
+
+// applyPredictorOptions sets the container level options on the selected predictor implementation
+func (b *Builder) applyPredictorOptions(isvc *v1beta1.InferenceService) error {
+	var container *v1.Container
+	if predictor := isvc.Spec.Predictor.GetPredictorExtensions(); predictor != nil {
+		predictor.RuntimeVersion = b.runtimeVersion
+		predictor.ProtocolVersion = b.protocol
+		container = &predictor.Container
+	} else {
+		if b.runtimeVersion != nil || b.protocol != nil {
+			return fmt.Errorf("runtime version and protocol can not be set on custom predictor of InferenceService %q", b.isvc.Name)
+		}
+		container = &isvc.Spec.Predictor.PodSpec.Containers[0]
+	}
+	if b.resources != nil {
+		container.Resources = *b.resources.DeepCopy()
+	}
+	if b.gpus != nil {
+		gpus := resource.NewQuantity(*b.gpus, resource.DecimalSI)
+		if container.Resources.Limits == nil {
+			container.Resources.Limits = v1.ResourceList{}
+		}
+		container.Resources.Limits[constants.NvidiaGPUResourceType] = *gpus
+	}
+	return nil
+}
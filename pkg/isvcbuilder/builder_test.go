@@ -0,0 +1,110 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isvcbuilder
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+var config = &v1beta1.InferenceServicesConfig{
+	Predictors: v1beta1.PredictorsConfig{
+		SKlearn: v1beta1.PredictorProtocols{
+			V1: &v1beta1.PredictorConfig{
+				ContainerImage:      "kfserving/sklearnserver",
+				DefaultImageVersion: "v0.4.0",
+			},
+			V2: &v1beta1.PredictorConfig{
+				ContainerImage:      "kfserving/mlserver",
+				DefaultImageVersion: "0.1.2",
+			},
+		},
+		Tensorflow: v1beta1.PredictorConfig{
+			ContainerImage:         "tensorflow/serving",
+			DefaultImageVersion:    "1.14.0",
+			DefaultGpuImageVersion: "1.14.0-gpu",
+		},
+	},
+}
+
+func TestBuildSklearn(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc, err := New("sklearn-iris").
+		Namespace("default").
+		Sklearn("gs://kfserving-samples/models/sklearn/iris").
+		MinReplicas(1).
+		MaxReplicas(3).
+		Canary(10).
+		Build(config)
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(isvc.Name).To(gomega.Equal("sklearn-iris"))
+	g.Expect(isvc.Namespace).To(gomega.Equal("default"))
+	g.Expect(*isvc.Spec.Predictor.SKLearn.StorageURI).To(gomega.Equal("gs://kfserving-samples/models/sklearn/iris"))
+	g.Expect(*isvc.Spec.Predictor.SKLearn.RuntimeVersion).To(gomega.Equal("v0.4.0"))
+	g.Expect(*isvc.Spec.Predictor.SKLearn.ProtocolVersion).To(gomega.Equal(constants.ProtocolV1))
+	g.Expect(isvc.Spec.Predictor.SKLearn.Resources.Requests).To(gomega.HaveKey(v1.ResourceCPU))
+	g.Expect(*isvc.Spec.Predictor.MinReplicas).To(gomega.Equal(1))
+	g.Expect(isvc.Spec.Predictor.MaxReplicas).To(gomega.Equal(3))
+	g.Expect(*isvc.Spec.Predictor.CanaryTrafficPercent).To(gomega.Equal(int64(10)))
+}
+
+func TestBuildGPU(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc, err := New("flowers").
+		Tensorflow("gs://kfserving-samples/models/tensorflow/flowers").
+		GPU(1).
+		Build(config)
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(isvc.Spec.Predictor.Tensorflow.Resources.Limits[constants.NvidiaGPUResourceType]).To(gomega.Equal(resource.MustParse("1")))
+	g.Expect(*isvc.Spec.Predictor.Tensorflow.RuntimeVersion).To(gomega.Equal("1.14.0-gpu"))
+}
+
+func TestBuildCustomPredictor(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc, err := New("custom").
+		CustomPredictor(v1.Container{Image: "custom/model:latest"}).
+		Build(config)
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(isvc.Spec.Predictor.Containers[0].Image).To(gomega.Equal("custom/model:latest"))
+
+	_, err = New("custom").
+		CustomPredictor(v1.Container{Image: "custom/model:latest"}).
+		RuntimeVersion("v1").
+		Build(config)
+	g.Expect(err).ShouldNot(gomega.BeNil())
+}
+
+func TestBuildErrors(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	_, err := New("foo").Build(config)
+	g.Expect(err).To(gomega.MatchError(fmt.Sprintf(NoPredictorError, "foo")))
+
+	_, err = New("foo").Sklearn("gs://foo").XGBoost("gs://bar").Build(config)
+	g.Expect(err).To(gomega.MatchError(fmt.Sprintf(MultiplePredictorsError, "foo", "SKLearn")))
+
+	_, err = New("foo").Sklearn("gs://foo").MinReplicas(3).MaxReplicas(2).Build(config)
+	g.Expect(err).To(gomega.MatchError(v1beta1.MinReplicasShouldBeLessThanMaxError))
+
+	_, err = New("Foo").Sklearn("gs://foo").Build(config)
+	g.Expect(err).ShouldNot(gomega.BeNil())
+}
@@ -16,7 +16,13 @@ limitations under the License.
 
 package logger
 
+import "time"
+
 const (
 	LoggerWorkerQueueSize = 100
 	CloudEventsIdHeader   = "Ce-Id"
+	// OutlierHeader is set on the responses when the requests are scored inline by the outlier detector
+	OutlierHeader = "X-Outlier"
+	// OutlierDetectorTimeout bounds the latency added by the inline outlier detection
+	OutlierDetectorTimeout = 5 * time.Second
 )
@@ -0,0 +1,84 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logger
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// DefaultRetries is the number of retries of the log events which failed to be delivered
+	DefaultRetries = 2
+	// DefaultRetryInterval is the delay before the first retry, it doubles with each retry
+	DefaultRetryInterval = time.Second
+)
+
+// DeliveryPolicy defines the retries of the log events and the dead-letter sink the events are sent to once their
+// retries are exhausted
+type DeliveryPolicy struct {
+	Retries       int
+	RetryInterval time.Duration
+	// DeadLetterUrl is nil when the undelivered events are dropped
+	DeadLetterUrl *url.URL
+}
+
+var (
+	deadLetteredEvents = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "kfserving_logger_dead_lettered_events_total",
+		Help: "Log events sent to the dead-letter sink once their delivery retries were exhausted",
+	}, []string{"namespace", "inferenceservice"})
+	droppedEvents = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "kfserving_logger_dropped_events_total",
+		Help: "Log events which could not be delivered to the sink nor to the dead-letter sink",
+	}, []string{"namespace", "inferenceservice"})
+)
+
+func init() {
+	prometheus.MustRegister(deadLetteredEvents, droppedEvents)
+}
+
+// deliver sends the log event to its url with retries, then to the dead-letter sink with the reason of the failure
+func (w *Worker) deliver(logReq LogRequest) {
+	err := w.sendCloudEvent(logReq)
+	interval := w.Delivery.RetryInterval
+	for retry := 0; err != nil && retry < w.Delivery.Retries; retry++ {
+		time.Sleep(interval)
+		interval *= 2
+		err = w.sendCloudEvent(logReq)
+	}
+	if err == nil {
+		return
+	}
+	w.Log.Error(err, "Failed to send log", "URL", logReq.Url.String(), "retries", w.Delivery.Retries)
+	if w.Delivery.DeadLetterUrl == nil {
+		droppedEvents.WithLabelValues(logReq.Namespace, logReq.InferenceService).Inc()
+		return
+	}
+	deadLetter := logReq
+	deadLetter.Url = w.Delivery.DeadLetterUrl
+	deadLetter.DeadLetterSink = logReq.Url.String()
+	deadLetter.DeadLetterReason = err.Error()
+	if err := w.sendCloudEvent(deadLetter); err != nil {
+		w.Log.Error(err, "Failed to send log to the dead-letter sink", "URL", deadLetter.Url.String())
+		droppedEvents.WithLabelValues(logReq.Namespace, logReq.InferenceService).Inc()
+		return
+	}
+	deadLetteredEvents.WithLabelValues(logReq.Namespace, logReq.InferenceService).Inc()
+}
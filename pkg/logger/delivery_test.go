@@ -0,0 +1,85 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestDeliverDeadLetter(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	attempts := 0
+	sink := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		attempts++
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer sink.Close()
+	var deadLetters []http.Header
+	deadLetterSink := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		deadLetters = append(deadLetters, req.Header)
+		rw.WriteHeader(http.StatusAccepted)
+	}))
+
+	logf.SetLogger(logf.ZapLogger(false))
+	log := logf.Log.WithName("entrypoint")
+	sinkUrl, err := url.Parse(sink.URL)
+	g.Expect(err).To(gomega.BeNil())
+	deadLetterUrl, err := url.Parse(deadLetterSink.URL)
+	g.Expect(err).To(gomega.BeNil())
+	sourceUri, err := url.Parse("http://localhost:8080/")
+	g.Expect(err).To(gomega.BeNil())
+	worker := NewWorker(1, make(chan chan LogRequest, 1), DeliveryPolicy{
+		Retries:       2,
+		RetryInterval: time.Millisecond,
+		DeadLetterUrl: deadLetterUrl,
+	}, log)
+	payload := []byte(`{"instances":[[0,0,0]]}`)
+	logReq := LogRequest{
+		Url:              sinkUrl,
+		Bytes:            &payload,
+		ContentType:      "application/json",
+		ReqType:          InferenceRequest,
+		Id:               "1",
+		SourceUri:        sourceUri,
+		InferenceService: "sklearn",
+		Namespace:        "dead-letter",
+		Endpoint:         "default",
+	}
+
+	worker.deliver(logReq)
+	g.Expect(attempts).To(gomega.Equal(3))
+	g.Expect(deadLetters).To(gomega.HaveLen(1))
+	g.Expect(deadLetters[0].Get("Ce-Deadlettersink")).To(gomega.Equal(sink.URL))
+	g.Expect(deadLetters[0].Get("Ce-Deadletterreason")).NotTo(gomega.BeEmpty())
+	g.Expect(testutil.ToFloat64(deadLetteredEvents.WithLabelValues("dead-letter", "sklearn"))).To(gomega.Equal(1.0))
+	g.Expect(testutil.ToFloat64(droppedEvents.WithLabelValues("dead-letter", "sklearn"))).To(gomega.BeZero())
+
+	// the event is dropped when the dead-letter sink fails too
+	deadLetterSink.Close()
+	worker.deliver(logReq)
+	g.Expect(testutil.ToFloat64(deadLetteredEvents.WithLabelValues("dead-letter", "sklearn"))).To(gomega.Equal(1.0))
+	g.Expect(testutil.ToFloat64(droppedEvents.WithLabelValues("dead-letter", "sklearn"))).To(gomega.Equal(1.0))
+}
@@ -22,14 +22,14 @@ import (
 
 var WorkerQueue chan chan LogRequest
 
-func StartDispatcher(nworkers int, log logr.Logger) {
+func StartDispatcher(nworkers int, delivery DeliveryPolicy, log logr.Logger) {
 	// First, initialize the channel we are going to but the workers' work channels into.
 	WorkerQueue = make(chan chan LogRequest, nworkers)
 
 	// Now, create all of our workers.
 	for i := 0; i < nworkers; i++ {
 		log.Info("Starting", "worker", i+1)
-		worker := NewWorker(i+1, WorkerQueue, log)
+		worker := NewWorker(i+1, WorkerQueue, delivery, log)
 		worker.Start()
 	}
 
@@ -0,0 +1,195 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+)
+
+// PredictPath is the v1 predict path of a model, the predict requests of the InferenceService are sent to the
+// models of the ensemble
+const PredictPath = "/v1/models/%s:predict"
+
+// predictResponse is the v1 protocol response of a model
+type predictResponse struct {
+	Predictions []json.RawMessage `json:"predictions"`
+}
+
+// memberResponse is the response of a model of the ensemble
+type memberResponse struct {
+	body        []byte
+	contentType *string
+	statusCode  *int
+	err         error
+}
+
+// callEnsemble sends the request to the models of the ensemble concurrently and combines their predictions, the
+// response of the first model failing the request is returned
+func (eh *LoggerHandler) callEnsemble(b []byte, r *http.Request) ([]byte, *string, *int, error) {
+	var models []v1beta1.EnsembleModelSpec
+	for _, model := range eh.ensemble.Models {
+		if model.GetWeight() > 0 {
+			models = append(models, model)
+		}
+	}
+	responses := make([]chan memberResponse, len(models))
+	for i, model := range models {
+		responses[i] = make(chan memberResponse, 1)
+		go func(name string, response chan memberResponse) {
+			req := r.Clone(r.Context())
+			req.URL.Path = fmt.Sprintf(PredictPath, name)
+			body, contentType, statusCode, err := eh.callService(b, req)
+			response <- memberResponse{body: body, contentType: contentType, statusCode: statusCode, err: err}
+		}(model.Name, responses[i])
+	}
+	predictions := make([][]json.RawMessage, len(models))
+	weights := make([]int64, len(models))
+	for i, model := range models {
+		response := <-responses[i]
+		if response.err != nil {
+			return nil, nil, nil, fmt.Errorf("while calling ensemble model %s: %s", model.Name, response.err)
+		}
+		if *response.statusCode != http.StatusOK {
+			return response.body, response.contentType, response.statusCode, nil
+		}
+		reply := &predictResponse{}
+		if err := json.Unmarshal(response.body, reply); err != nil {
+			return nil, nil, nil, fmt.Errorf("while decoding ensemble model %s response: %s", model.Name, err)
+		}
+		predictions[i] = reply.Predictions
+		weights[i] = model.GetWeight()
+	}
+	combined, err := combinePredictions(eh.ensemble.GetStrategy(), predictions, weights)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	rb, err := json.Marshal(&predictResponse{Predictions: combined})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	contentType := "application/json"
+	statusCode := http.StatusOK
+	return rb, &contentType, &statusCode, nil
+}
+
+// combinePredictions combines the predictions of the instances made by each model with the weights of the models
+func combinePredictions(strategy v1beta1.EnsembleStrategy, predictions [][]json.RawMessage, weights []int64) ([]json.RawMessage, error) {
+	if len(predictions) == 0 {
+		return nil, fmt.Errorf("ensemble has no model with a weight greater than 0")
+	}
+	instances := len(predictions[0])
+	for _, p := range predictions {
+		if len(p) != instances {
+			return nil, fmt.Errorf("ensemble models returned %d and %d predictions", instances, len(p))
+		}
+	}
+	combined := make([]json.RawMessage, instances)
+	for i := 0; i < instances; i++ {
+		votes := make([]json.RawMessage, len(predictions))
+		for m := range predictions {
+			votes[m] = predictions[m][i]
+		}
+		var err error
+		if strategy == v1beta1.MajorityVoteEnsemble {
+			combined[i], err = majorityVote(votes, weights)
+		} else {
+			combined[i], err = weightedAverage(votes, weights)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return combined, nil
+}
+
+// majorityVote returns the prediction with the largest sum of weights, the prediction of the first model wins ties
+func majorityVote(votes []json.RawMessage, weights []int64) (json.RawMessage, error) {
+	tally := map[string]int64{}
+	keys := make([]string, len(votes))
+	for m, vote := range votes {
+		var key bytes.Buffer
+		if err := json.Compact(&key, vote); err != nil {
+			return nil, fmt.Errorf("ensemble prediction is not valid JSON: %s", err)
+		}
+		keys[m] = key.String()
+		tally[keys[m]] += weights[m]
+	}
+	winner := 0
+	for m := range votes {
+		if tally[keys[m]] > tally[keys[winner]] {
+			winner = m
+		}
+	}
+	return json.RawMessage(keys[winner]), nil
+}
+
+// weightedAverage averages the numeric predictions, the predictions which are lists of numbers such as class
+// probabilities are averaged element-wise
+func weightedAverage(votes []json.RawMessage, weights []int64) (json.RawMessage, error) {
+	values := make([]interface{}, len(votes))
+	var total int64
+	for m, vote := range votes {
+		if err := json.Unmarshal(vote, &values[m]); err != nil {
+			return nil, fmt.Errorf("ensemble prediction is not valid JSON: %s", err)
+		}
+		total += weights[m]
+	}
+	average, err := average(values, weights, total)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(average)
+}
+
+func average(values []interface{}, weights []int64, total int64) (interface{}, error) {
+	switch first := values[0].(type) {
+	case float64:
+		sum := 0.0
+		for m, value := range values {
+			number, ok := value.(float64)
+			if !ok {
+				return nil, fmt.Errorf("ensemble predictions %v and %v can not be averaged", first, value)
+			}
+			sum += number * float64(weights[m])
+		}
+		return sum / float64(total), nil
+	case []interface{}:
+		averaged := make([]interface{}, len(first))
+		for j := range first {
+			elements := make([]interface{}, len(values))
+			for m, value := range values {
+				list, ok := value.([]interface{})
+				if !ok || len(list) != len(first) {
+					return nil, fmt.Errorf("ensemble predictions %v and %v can not be averaged", first, value)
+				}
+				elements[m] = list[j]
+			}
+			element, err := average(elements, weights, total)
+			if err != nil {
+				return nil, err
+			}
+			averaged[j] = element
+		}
+		return averaged, nil
+	}
+	return nil, fmt.Errorf("ensemble prediction %v is not numeric, use the MajorityVote strategy", values[0])
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// outlierResponse is the reply of the Alibi Detect outlier detection server, is_outlier is a flag or a list of
+// flags for the instances of the request
+type outlierResponse struct {
+	Data struct {
+		IsOutlier json.RawMessage `json:"is_outlier"`
+	} `json:"data"`
+}
+
+// scoreOutlier sends the request payload to the outlier detector and returns the value of the outlier header, true
+// if any of the instances is an outlier
+func (eh *LoggerHandler) scoreOutlier(b []byte, id string) (string, error) {
+	req, err := http.NewRequest(http.MethodPost, eh.outlierUrl.String(), bytes.NewReader(b))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(CloudEventsIdHeader, id)
+	response, err := eh.outlierClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("while calling outlier detector: %s", err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("outlier detector returned status code %d", response.StatusCode)
+	}
+	reply := &outlierResponse{}
+	if err := json.NewDecoder(response.Body).Decode(reply); err != nil {
+		return "", fmt.Errorf("while decoding outlier detector response: %s", err)
+	}
+	var flags []int
+	if err := json.Unmarshal(reply.Data.IsOutlier, &flags); err != nil {
+		var flag int
+		if err := json.Unmarshal(reply.Data.IsOutlier, &flag); err != nil {
+			return "", fmt.Errorf("outlier detector response has no is_outlier flags")
+		}
+		flags = []int{flag}
+	}
+	for _, flag := range flags {
+		if flag != 0 {
+			return strconv.FormatBool(true), nil
+		}
+	}
+	return strconv.FormatBool(false), nil
+}
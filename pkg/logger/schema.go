@@ -0,0 +1,63 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// SchemaPath is the path of the model schema the requests are validated against
+const SchemaPath = "/v2/models/%s/schema"
+
+// validateRequest validates the v2 inference request against the model metadata, the requests are not validated
+// until the controller has fetched the metadata
+func (eh *LoggerHandler) validateRequest(b []byte) error {
+	metadata, err := eh.schema.Metadata()
+	if err != nil {
+		eh.log.Error(err, "Failed to load the model schema, the request is not validated")
+		return nil
+	}
+	if metadata == nil {
+		return nil
+	}
+	return metadata.Validate(b)
+}
+
+func (eh *LoggerHandler) serveSchema(w http.ResponseWriter) {
+	metadata, err := eh.schema.Metadata()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if metadata == nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("the model schema has not been fetched yet"))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(metadata); err != nil {
+		eh.log.Error(err, "Failed to write the model schema")
+	}
+}
+
+// writeError writes the error in the v2 protocol error format
+func writeError(w http.ResponseWriter, statusCode int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
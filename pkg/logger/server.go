@@ -22,34 +22,45 @@ import (
 	"github.com/go-logr/logr"
 	guuid "github.com/google/uuid"
 	"github.com/kubeflow/kfserving/pkg/apis/serving/v1alpha2"
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/modelschema"
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strings"
 )
 
 type LoggerHandler struct {
 	log              logr.Logger
 	svcHost          string
 	svcPort          string
-	logUrl           *url.URL
+	logUrls          []*url.URL
 	sourceUri        *url.URL
 	logMode          v1alpha2.LoggerMode
 	inferenceService string
 	namespace        string
 	endpoint         string
+	outlierUrl       *url.URL
+	outlierClient    *http.Client
+	schema           *modelschema.File
+	ensemble         *v1beta1.EnsembleSpec
 }
 
-func New(log logr.Logger, svcHost string, svcPort string, logUrl *url.URL, sourceUri *url.URL, logMode v1alpha2.LoggerMode, inferenceService string, namespace string, endpoint string) http.Handler {
+func New(log logr.Logger, svcHost string, svcPort string, logUrls []*url.URL, sourceUri *url.URL, logMode v1alpha2.LoggerMode, inferenceService string, namespace string, endpoint string, outlierUrl *url.URL, schema *modelschema.File, ensemble *v1beta1.EnsembleSpec) http.Handler {
 	return &LoggerHandler{
 		log:              log,
 		svcHost:          svcHost,
 		svcPort:          svcPort,
-		logUrl:           logUrl,
+		logUrls:          logUrls,
 		sourceUri:        sourceUri,
 		logMode:          logMode,
 		inferenceService: inferenceService,
 		namespace:        namespace,
 		endpoint:         endpoint,
+		outlierUrl:       outlierUrl,
+		outlierClient:    &http.Client{Timeout: OutlierDetectorTimeout},
+		schema:           schema,
+		ensemble:         ensemble,
 	}
 }
 
@@ -84,36 +95,78 @@ func getOrCreateID(r *http.Request) string {
 	return id
 }
 
-// call svc and add send request/responses to logUrl
+// logPayload queues the payload for each of the log urls
+func (eh *LoggerHandler) logPayload(b []byte, reqType LogRequestType, id string) error {
+	for _, logUrl := range eh.logUrls {
+		if err := QueueLogRequest(LogRequest{
+			Url:              logUrl,
+			Bytes:            &b,
+			ContentType:      "application/json", // Always JSON at present
+			ReqType:          reqType,
+			Id:               id,
+			SourceUri:        eh.sourceUri,
+			InferenceService: eh.inferenceService,
+			Namespace:        eh.namespace,
+			Endpoint:         eh.endpoint,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// call svc and add send request/responses to logUrls
 func (eh *LoggerHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if eh.schema != nil && r.Method == http.MethodGet && r.URL.Path == fmt.Sprintf(SchemaPath, eh.inferenceService) {
+		eh.serveSchema(w)
+		return
+	}
+
 	// Read Payload
 	b, err := ioutil.ReadAll(r.Body)
 	if err != nil {
 		eh.log.Error(err, "Failed to read request payload")
 	}
 
+	// Reject the requests which do not match the model inputs before they reach the model server
+	if eh.schema != nil && strings.HasSuffix(r.URL.Path, "/infer") {
+		if err := eh.validateRequest(b); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
 	// Get or Create an ID
 	id := getOrCreateID(r)
 
 	// log Request
 	if eh.logMode == v1alpha2.LogAll || eh.logMode == v1alpha2.LogRequest {
-		if err := QueueLogRequest(LogRequest{
-			Url:              eh.logUrl,
-			Bytes:            &b,
-			ContentType:      "application/json", // Always JSON at present
-			ReqType:          InferenceRequest,
-			Id:               id,
-			SourceUri:        eh.sourceUri,
-			InferenceService: eh.inferenceService,
-			Namespace:        eh.namespace,
-			Endpoint:         eh.endpoint,
-		}); err != nil {
+		if err := eh.logPayload(b, InferenceRequest, id); err != nil {
 			eh.log.Error(err, "Failed to log request")
 		}
 	}
 
-	// Call service
-	b, respContentType, statusCode, err := eh.callService(b, r)
+	// Score the request for outliers while the service is called
+	var outlier chan string
+	if eh.outlierUrl != nil {
+		outlier = make(chan string, 1)
+		go func(payload []byte) {
+			value, err := eh.scoreOutlier(payload, id)
+			if err != nil {
+				eh.log.Error(err, "Failed to score request for outliers")
+			}
+			outlier <- value
+		}(b)
+	}
+
+	// Call service, the predict requests of the InferenceService are sent to the models of the ensemble
+	var respContentType *string
+	var statusCode *int
+	if eh.ensemble != nil && r.URL.Path == fmt.Sprintf(PredictPath, eh.inferenceService) {
+		b, respContentType, statusCode, err = eh.callEnsemble(b, r)
+	} else {
+		b, respContentType, statusCode, err = eh.callService(b, r)
+	}
 	// Error in internal calling of service. Non 200 returns code from service will not cause an error.
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -123,17 +176,7 @@ func (eh *LoggerHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// log response if OK
 	if *statusCode == http.StatusOK {
 		if eh.logMode == v1alpha2.LogAll || eh.logMode == v1alpha2.LogResponse {
-			if err := QueueLogRequest(LogRequest{
-				Url:              eh.logUrl,
-				Bytes:            &b,
-				ContentType:      "application/json", // Always JSON at present
-				ReqType:          InferenceResponse,
-				Id:               id,
-				SourceUri:        eh.sourceUri,
-				InferenceService: eh.inferenceService,
-				Namespace:        eh.namespace,
-				Endpoint:         eh.endpoint,
-			}); err != nil {
+			if err := eh.logPayload(b, InferenceResponse, id); err != nil {
 				eh.log.Error(err, "Failed to log response")
 			}
 		}
@@ -145,6 +188,11 @@ func (eh *LoggerHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if *respContentType != "" {
 		w.Header().Set("Content-Type", *respContentType)
 	}
+	if outlier != nil {
+		if value := <-outlier; value != "" {
+			w.Header().Set(OutlierHeader, value)
+		}
+	}
 	w.WriteHeader(*statusCode)
 	_, err = w.Write(b)
 	if err != nil {
@@ -18,12 +18,18 @@ package logger
 
 import (
 	"bytes"
+	"encoding/json"
+	"github.com/golang/protobuf/proto"
 	"github.com/kubeflow/kfserving/pkg/apis/serving/v1alpha2"
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/modelschema"
 	"github.com/onsi/gomega"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
+	"path/filepath"
 	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
 	"testing"
 )
@@ -70,7 +76,7 @@ func TestLogger(t *testing.T) {
 	g.Expect(err).To(gomega.BeNil())
 	sourceUri, err := url.Parse("http://localhost:8080/")
 	g.Expect(err).To(gomega.BeNil())
-	oh := New(log, "0.0.0.0", predictorSvcUrl.Port(), logSvcUrl, sourceUri, v1alpha2.LogAll, "mymodel", "default", "default")
+	oh := New(log, "0.0.0.0", predictorSvcUrl.Port(), []*url.URL{logSvcUrl}, sourceUri, v1alpha2.LogAll, "mymodel", "default", "default", nil, nil, nil)
 
 	oh.ServeHTTP(w, r)
 
@@ -78,3 +84,225 @@ func TestLogger(t *testing.T) {
 	g.Expect(b2).To(gomega.Equal(predictorResponse))
 
 }
+
+func TestLoggerInlineOutlierDetection(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	predictorRequest := []byte(`{"instances":[[0,0,0],[9,9,9]]}`)
+	predictorResponse := []byte(`{"predictions":[1,2]}`)
+
+	outlierDetector := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		b, err := ioutil.ReadAll(req.Body)
+		g.Expect(err).To(gomega.BeNil())
+		g.Expect(b).To(gomega.Equal(predictorRequest))
+		_, err = rw.Write([]byte(`{"data":{"is_outlier":[0,1]},"meta":{"name":"OutlierVAE"}}`))
+		g.Expect(err).To(gomega.BeNil())
+	}))
+	defer outlierDetector.Close()
+
+	predictor := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, err := rw.Write(predictorResponse)
+		g.Expect(err).To(gomega.BeNil())
+	}))
+	defer predictor.Close()
+
+	logf.SetLogger(logf.ZapLogger(false))
+	log := logf.Log.WithName("entrypoint")
+
+	predictorSvcUrl, err := url.Parse(predictor.URL)
+	g.Expect(err).To(gomega.BeNil())
+	outlierUrl, err := url.Parse(outlierDetector.URL)
+	g.Expect(err).To(gomega.BeNil())
+	sourceUri, err := url.Parse("http://localhost:8080/")
+	g.Expect(err).To(gomega.BeNil())
+	oh := New(log, "0.0.0.0", predictorSvcUrl.Port(), nil, sourceUri, v1alpha2.LogAll, "mymodel", "default", "default", outlierUrl, nil, nil)
+
+	r := httptest.NewRequest("POST", "http://a", bytes.NewReader(predictorRequest))
+	w := httptest.NewRecorder()
+	oh.ServeHTTP(w, r)
+
+	g.Expect(w.Result().Header.Get(OutlierHeader)).To(gomega.Equal("true"))
+	b, _ := ioutil.ReadAll(w.Result().Body)
+	g.Expect(b).To(gomega.Equal(predictorResponse))
+
+	// the response is not tagged when the detector fails
+	outlierDetector.Close()
+	r = httptest.NewRequest("POST", "http://a", bytes.NewReader(predictorRequest))
+	w = httptest.NewRecorder()
+	oh.ServeHTTP(w, r)
+	g.Expect(w.Result().Header.Get(OutlierHeader)).To(gomega.BeEmpty())
+	g.Expect(w.Result().StatusCode).To(gomega.Equal(http.StatusOK))
+}
+
+func TestLoggerRequestValidation(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	predictorResponse := []byte(`{"outputs":[]}`)
+	called := 0
+	predictor := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		called++
+		_, err := rw.Write(predictorResponse)
+		g.Expect(err).To(gomega.BeNil())
+	}))
+	defer predictor.Close()
+
+	dir, err := ioutil.TempDir("", "schema")
+	g.Expect(err).To(gomega.BeNil())
+	defer os.RemoveAll(dir)
+	schemaFile := filepath.Join(dir, "schema.json")
+
+	logf.SetLogger(logf.ZapLogger(false))
+	log := logf.Log.WithName("entrypoint")
+	predictorSvcUrl, err := url.Parse(predictor.URL)
+	g.Expect(err).To(gomega.BeNil())
+	sourceUri, err := url.Parse("http://localhost:8080/")
+	g.Expect(err).To(gomega.BeNil())
+	oh := New(log, "0.0.0.0", predictorSvcUrl.Port(), nil, sourceUri, v1alpha2.LogAll, "mymodel", "default", "default", nil,
+		modelschema.NewFile(schemaFile), nil)
+
+	infer := func(body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("POST", "http://a/v2/models/mymodel/infer", bytes.NewReader([]byte(body)))
+		w := httptest.NewRecorder()
+		oh.ServeHTTP(w, r)
+		return w
+	}
+	malformed := `{"inputs":[{"name":"input-0","datatype":"FP32","shape":[1,3],"data":[1,2,3]}]}`
+
+	// the requests are not validated until the schema is fetched
+	g.Expect(infer(malformed).Code).To(gomega.Equal(http.StatusOK))
+	r := httptest.NewRequest("GET", "http://a/v2/models/mymodel/schema", nil)
+	w := httptest.NewRecorder()
+	oh.ServeHTTP(w, r)
+	g.Expect(w.Code).To(gomega.Equal(http.StatusNotFound))
+
+	g.Expect(ioutil.WriteFile(schemaFile, []byte(`{"name":"mymodel","platform":"onnx","inputs":[{"name":"input-0","datatype":"FP32","shape":[-1,4]}]}`), 0644)).To(gomega.Succeed())
+	w = infer(malformed)
+	g.Expect(w.Code).To(gomega.Equal(http.StatusBadRequest))
+	g.Expect(w.Body.String()).To(gomega.ContainSubstring(`input \"input-0\" has shape [1 3], expected [-1 4]`))
+	g.Expect(called).To(gomega.Equal(1))
+
+	w = infer(`{"inputs":[{"name":"input-0","datatype":"FP32","shape":[2,4],"data":[1,2,3,4,5,6,7,8]}]}`)
+	g.Expect(w.Code).To(gomega.Equal(http.StatusOK))
+	g.Expect(called).To(gomega.Equal(2))
+
+	r = httptest.NewRequest("GET", "http://a/v2/models/mymodel/schema", nil)
+	w = httptest.NewRecorder()
+	oh.ServeHTTP(w, r)
+	g.Expect(w.Code).To(gomega.Equal(http.StatusOK))
+	g.Expect(w.Body.String()).To(gomega.ContainSubstring(`"platform":"onnx"`))
+}
+
+func TestLoggerEnsemble(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	predictions := map[string]string{
+		"/v1/models/forest:predict": `{"predictions":[[0.25,0.75],[0.5,0.5]]}`,
+		"/v1/models/linear:predict": `{"predictions":[[0.75,0.25],[1,0]]}`,
+		"/v1/models/mymodel:ready":  `{"ready":true}`,
+	}
+	predictor := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		response, ok := predictions[req.URL.Path]
+		if !ok {
+			rw.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, err := rw.Write([]byte(response))
+		g.Expect(err).To(gomega.BeNil())
+	}))
+	defer predictor.Close()
+
+	logf.SetLogger(logf.ZapLogger(false))
+	log := logf.Log.WithName("entrypoint")
+	predictorSvcUrl, err := url.Parse(predictor.URL)
+	g.Expect(err).To(gomega.BeNil())
+	sourceUri, err := url.Parse("http://localhost:8080/")
+	g.Expect(err).To(gomega.BeNil())
+	ensemble := &v1beta1.EnsembleSpec{
+		Models: []v1beta1.EnsembleModelSpec{
+			{Name: "forest", StorageURI: "gs://models/forest", Weight: proto.Int64(3)},
+			{Name: "linear", StorageURI: "gs://models/linear"},
+		},
+	}
+	oh := New(log, "0.0.0.0", predictorSvcUrl.Port(), nil, sourceUri, v1alpha2.LogAll, "mymodel", "default", "default", nil,
+		nil, ensemble)
+
+	call := func(path string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("POST", "http://a"+path, bytes.NewReader([]byte(`{"instances":[[1,2],[3,4]]}`)))
+		w := httptest.NewRecorder()
+		oh.ServeHTTP(w, r)
+		return w
+	}
+
+	w := call("/v1/models/mymodel:predict")
+	g.Expect(w.Code).To(gomega.Equal(http.StatusOK))
+	g.Expect(w.Body.String()).To(gomega.MatchJSON(`{"predictions":[[0.375,0.625],[0.625,0.375]]}`))
+
+	// the other requests are proxied to the model server
+	w = call("/v1/models/mymodel:ready")
+	g.Expect(w.Body.String()).To(gomega.Equal(`{"ready":true}`))
+
+	// the response of a failing model is returned
+	delete(predictions, "/v1/models/linear:predict")
+	w = call("/v1/models/mymodel:predict")
+	g.Expect(w.Code).To(gomega.Equal(http.StatusNotFound))
+}
+
+func TestCombinePredictions(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	raw := func(values ...string) []json.RawMessage {
+		messages := make([]json.RawMessage, len(values))
+		for i, value := range values {
+			messages[i] = json.RawMessage(value)
+		}
+		return messages
+	}
+	scenarios := map[string]struct {
+		strategy    v1beta1.EnsembleStrategy
+		predictions [][]json.RawMessage
+		weights     []int64
+		expected    string
+		err         string
+	}{
+		"WeightedAverage": {
+			strategy:    v1beta1.WeightedAverageEnsemble,
+			predictions: [][]json.RawMessage{raw("1", "[0,1]"), raw("4", "[1,0]")},
+			weights:     []int64{2, 1},
+			expected:    `[2,[0.3333333333333333,0.6666666666666666]]`,
+		},
+		"MajorityVote": {
+			strategy:    v1beta1.MajorityVoteEnsemble,
+			predictions: [][]json.RawMessage{raw(`"cat"`, "1"), raw(`"dog"`, "2"), raw(`"dog"`, "1")},
+			weights:     []int64{3, 1, 1},
+			expected:    `["cat",1]`,
+		},
+		"MajorityVoteTie": {
+			strategy:    v1beta1.MajorityVoteEnsemble,
+			predictions: [][]json.RawMessage{raw("0"), raw("1")},
+			weights:     []int64{1, 1},
+			expected:    `[0]`,
+		},
+		"NotNumeric": {
+			strategy:    v1beta1.WeightedAverageEnsemble,
+			predictions: [][]json.RawMessage{raw(`"cat"`), raw(`"dog"`)},
+			weights:     []int64{1, 1},
+			err:         "ensemble prediction cat is not numeric, use the MajorityVote strategy",
+		},
+		"MismatchedPredictions": {
+			strategy:    v1beta1.WeightedAverageEnsemble,
+			predictions: [][]json.RawMessage{raw("1", "2"), raw("1")},
+			weights:     []int64{1, 1},
+			err:         "ensemble models returned 2 and 1 predictions",
+		},
+	}
+	for name, scenario := range scenarios {
+		combined, err := combinePredictions(scenario.strategy, scenario.predictions, scenario.weights)
+		if scenario.err != "" {
+			g.Expect(err).To(gomega.MatchError(scenario.err), name)
+			continue
+		}
+		g.Expect(err).To(gomega.BeNil(), name)
+		b, err := json.Marshal(combined)
+		g.Expect(err).To(gomega.BeNil(), name)
+		g.Expect(string(b)).To(gomega.Equal(scenario.expected), name)
+	}
+}
@@ -37,4 +37,8 @@ type LogRequest struct {
 	InferenceService string
 	Namespace        string
 	Endpoint         string
+	// DeadLetterSink is the url the event failed to be delivered to when it is sent to the dead-letter sink
+	DeadLetterSink string
+	// DeadLetterReason is the last delivery error of the event sent to the dead-letter sink
+	DeadLetterReason string
 }
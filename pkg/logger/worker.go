@@ -36,12 +36,15 @@ const (
 	NamespaceAttr        = "namespace"
 	//endpoint would be either default or canary
 	EndpointAttr = "endpoint"
+	// the url and the delivery error of the events sent to the dead-letter sink
+	DeadLetterSinkAttr   = "deadlettersink"
+	DeadLetterReasonAttr = "deadletterreason"
 )
 
-// NewWorker creates, and returns a new Worker object. Its only argument
+// NewWorker creates, and returns a new Worker object. The worker queue
 // is a channel that the worker can add itself to whenever it is done its
-// work.
-func NewWorker(id int, workerQueue chan chan LogRequest, log logr.Logger) Worker {
+// work, the delivery policy defines the retries of the failed events.
+func NewWorker(id int, workerQueue chan chan LogRequest, delivery DeliveryPolicy, log logr.Logger) Worker {
 	// Create, and return the worker.
 	return Worker{
 		Log:         log,
@@ -52,7 +55,8 @@ func NewWorker(id int, workerQueue chan chan LogRequest, log logr.Logger) Worker
 		Client: http.Client{
 			Timeout: 60 * time.Second,
 		},
-		CeCtx: cloudevents.ContextWithEncoding(context.Background(), cloudevents.Binary),
+		CeCtx:    cloudevents.ContextWithEncoding(context.Background(), cloudevents.Binary),
+		Delivery: delivery,
 	}
 }
 
@@ -65,6 +69,7 @@ type Worker struct {
 	Client      http.Client
 	CeCtx       context.Context
 	CeTransport transport.Transport
+	Delivery    DeliveryPolicy
 }
 
 func (W *Worker) sendCloudEvent(logReq LogRequest) error {
@@ -94,6 +99,10 @@ func (W *Worker) sendCloudEvent(logReq LogRequest) error {
 	event.SetExtension(InferenceServiceAttr, logReq.InferenceService)
 	event.SetExtension(NamespaceAttr, logReq.Namespace)
 	event.SetExtension(EndpointAttr, logReq.Endpoint)
+	if logReq.DeadLetterSink != "" {
+		event.SetExtension(DeadLetterSinkAttr, logReq.DeadLetterSink)
+		event.SetExtension(DeadLetterReasonAttr, logReq.DeadLetterReason)
+	}
 
 	event.SetSource(logReq.SourceUri.String())
 	event.SetDataContentType(logReq.ContentType)
@@ -121,9 +130,7 @@ func (w *Worker) Start() {
 				w.Log.Info("Received work request", "workerId", w.ID, "url", work.Url.String(),
 					"requestId", work.Id)
 
-				if err := w.sendCloudEvent(work); err != nil {
-					w.Log.Error(err, "Failed to send log", "URL", work.Url.String())
-				}
+				w.deliver(work)
 
 			case <-w.QuitChan:
 				// We have been asked to stop.
@@ -0,0 +1,149 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package modelschema validates the v2 protocol inference requests against the inputs of the model metadata
+package modelschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+)
+
+// TensorMetadata is the metadata of an input or an output of the model
+type TensorMetadata struct {
+	Name     string  `json:"name"`
+	Datatype string  `json:"datatype"`
+	Shape    []int64 `json:"shape"`
+}
+
+// ModelMetadata is the response of the v2 protocol model metadata endpoint
+type ModelMetadata struct {
+	Name     string           `json:"name"`
+	Versions []string         `json:"versions,omitempty"`
+	Platform string           `json:"platform"`
+	Inputs   []TensorMetadata `json:"inputs"`
+	Outputs  []TensorMetadata `json:"outputs,omitempty"`
+}
+
+// requestInput is an input tensor of a v2 protocol inference request, the data is not validated
+type requestInput struct {
+	Name     string  `json:"name"`
+	Datatype string  `json:"datatype"`
+	Shape    []int64 `json:"shape"`
+}
+
+type inferenceRequest struct {
+	Inputs []requestInput `json:"inputs"`
+}
+
+// Parse decodes the model metadata, the metadata must describe the inputs of the model
+func Parse(b []byte) (*ModelMetadata, error) {
+	metadata := &ModelMetadata{}
+	if err := json.Unmarshal(b, metadata); err != nil {
+		return nil, fmt.Errorf("invalid model metadata: %v", err)
+	}
+	if len(metadata.Inputs) == 0 {
+		return nil, fmt.Errorf("model metadata has no inputs")
+	}
+	return metadata, nil
+}
+
+// Validate returns an error describing the first mismatch between the inference request and the model inputs
+func (m *ModelMetadata) Validate(body []byte) error {
+	request := &inferenceRequest{}
+	if err := json.Unmarshal(body, request); err != nil {
+		return fmt.Errorf("invalid inference request: %v", err)
+	}
+	inputs := map[string]requestInput{}
+	for _, input := range request.Inputs {
+		inputs[input.Name] = input
+	}
+	for _, expected := range m.Inputs {
+		input, ok := inputs[expected.Name]
+		if !ok {
+			return fmt.Errorf("missing input %q", expected.Name)
+		}
+		delete(inputs, expected.Name)
+		if input.Datatype != expected.Datatype {
+			return fmt.Errorf("input %q has datatype %s, expected %s", input.Name, input.Datatype, expected.Datatype)
+		}
+		if !shapeMatches(input.Shape, expected.Shape) {
+			return fmt.Errorf("input %q has shape %v, expected %v", input.Name, input.Shape, expected.Shape)
+		}
+	}
+	for name := range inputs {
+		return fmt.Errorf("unexpected input %q", name)
+	}
+	return nil
+}
+
+// shapeMatches returns true if the shapes have the same dimensions, -1 matches any size
+func shapeMatches(shape []int64, expected []int64) bool {
+	if len(shape) != len(expected) {
+		return false
+	}
+	for i := range shape {
+		if expected[i] != -1 && shape[i] != expected[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// File loads the model metadata from the file of a mounted configmap, the metadata is reloaded when the file changes
+type File struct {
+	path     string
+	mu       sync.Mutex
+	modTime  time.Time
+	metadata *ModelMetadata
+}
+
+// NewFile returns the model metadata of the file at the path, the file is read on the first call to Metadata
+func NewFile(path string) *File {
+	return &File{path: path}
+}
+
+// Metadata returns the model metadata of the file, or nil while the file does not exist
+func (f *File) Metadata() (*ModelMetadata, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	info, err := os.Stat(f.path)
+	if os.IsNotExist(err) {
+		f.metadata = nil
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if f.metadata != nil && info.ModTime().Equal(f.modTime) {
+		return f.metadata, nil
+	}
+	b, err := ioutil.ReadFile(f.path)
+	if err != nil {
+		return nil, err
+	}
+	metadata, err := Parse(b)
+	if err != nil {
+		return nil, err
+	}
+	f.metadata = metadata
+	f.modTime = info.ModTime()
+	return metadata, nil
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package modelschema
+
+import (
+	"testing"
+
+	"github.com/onsi/gomega"
+	"github.com/onsi/gomega/types"
+)
+
+func TestValidate(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	metadata, err := Parse([]byte(`{
+		"name": "mnist",
+		"platform": "onnx",
+		"inputs": [
+			{"name": "image", "datatype": "FP32", "shape": [-1, 28, 28]},
+			{"name": "scale", "datatype": "FP32", "shape": [1]}
+		]
+	}`))
+	g.Expect(err).To(gomega.BeNil())
+
+	scenarios := map[string]struct {
+		request string
+		matcher types.GomegaMatcher
+	}{
+		"Valid": {
+			request: `{"inputs": [
+				{"name": "image", "datatype": "FP32", "shape": [8, 28, 28], "data": []},
+				{"name": "scale", "datatype": "FP32", "shape": [1], "data": [1]}
+			]}`,
+			matcher: gomega.BeNil(),
+		},
+		"MissingInput": {
+			request: `{"inputs": [{"name": "image", "datatype": "FP32", "shape": [1, 28, 28]}]}`,
+			matcher: gomega.MatchError(`missing input "scale"`),
+		},
+		"UnexpectedInput": {
+			request: `{"inputs": [
+				{"name": "image", "datatype": "FP32", "shape": [1, 28, 28]},
+				{"name": "scale", "datatype": "FP32", "shape": [1]},
+				{"name": "label", "datatype": "INT64", "shape": [1]}
+			]}`,
+			matcher: gomega.MatchError(`unexpected input "label"`),
+		},
+		"InvalidDatatype": {
+			request: `{"inputs": [
+				{"name": "image", "datatype": "UINT8", "shape": [1, 28, 28]},
+				{"name": "scale", "datatype": "FP32", "shape": [1]}
+			]}`,
+			matcher: gomega.MatchError(`input "image" has datatype UINT8, expected FP32`),
+		},
+		"InvalidShape": {
+			request: `{"inputs": [
+				{"name": "image", "datatype": "FP32", "shape": [1, 784]},
+				{"name": "scale", "datatype": "FP32", "shape": [1]}
+			]}`,
+			matcher: gomega.MatchError(`input "image" has shape [1 784], expected [-1 28 28]`),
+		},
+		"InvalidJson": {
+			request: `{"inputs": `,
+			matcher: gomega.Not(gomega.BeNil()),
+		},
+	}
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			err := metadata.Validate([]byte(scenario.request))
+			if !g.Expect(err).To(scenario.matcher) {
+				t.Errorf("got %v, want %v", err, scenario.matcher)
+			}
+		})
+	}
+}
+
+func TestParseWithoutInputs(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	_, err := Parse([]byte(`{"name": "mnist", "platform": "onnx"}`))
+	g.Expect(err).To(gomega.MatchError("model metadata has no inputs"))
+}
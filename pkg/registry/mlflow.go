@@ -0,0 +1,107 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+)
+
+// MLflowRegistryType is the registry type of the MLflow model registry
+const MLflowRegistryType = "mlflow"
+
+const requestTimeout = 10 * time.Second
+
+// MLflowPlugin resolves the model versions with the REST API of the MLflow tracking server
+type MLflowPlugin struct {
+	url        string
+	httpClient *http.Client
+}
+
+var _ Plugin = &MLflowPlugin{}
+
+func NewMLflowPlugin(config v1beta1.RegistryConfig) (Plugin, error) {
+	if config.URL == "" {
+		return nil, fmt.Errorf("mlflow registry url must be set")
+	}
+	return &MLflowPlugin{
+		url:        strings.TrimSuffix(config.URL, "/"),
+		httpClient: &http.Client{Timeout: requestTimeout},
+	}, nil
+}
+
+type mlflowModelVersion struct {
+	Version string `json:"version"`
+}
+
+type mlflowLatestVersionsResponse struct {
+	ModelVersions []mlflowModelVersion `json:"model_versions"`
+}
+
+type mlflowDownloadURIResponse struct {
+	ArtifactURI string `json:"artifact_uri"`
+}
+
+// Resolve returns the artifact URI of the model version, the latest version is the highest version of the latest
+// versions of the stages
+func (p *MLflowPlugin) Resolve(model string, version string) (string, string, error) {
+	if version == "" {
+		latest := &mlflowLatestVersionsResponse{}
+		if err := p.get("/api/2.0/mlflow/registered-models/get-latest-versions", url.Values{"name": {model}},
+			latest); err != nil {
+			return "", "", err
+		}
+		highest := -1
+		for _, modelVersion := range latest.ModelVersions {
+			if n, err := strconv.Atoi(modelVersion.Version); err == nil && n > highest {
+				highest = n
+			}
+		}
+		if highest < 0 {
+			return "", "", fmt.Errorf("model %s has no versions", model)
+		}
+		version = strconv.Itoa(highest)
+	}
+	downloadURI := &mlflowDownloadURIResponse{}
+	if err := p.get("/api/2.0/mlflow/model-versions/get-download-uri", url.Values{"name": {model},
+		"version": {version}}, downloadURI); err != nil {
+		return "", "", err
+	}
+	if downloadURI.ArtifactURI == "" {
+		return "", "", fmt.Errorf("model %s version %s has no artifact", model, version)
+	}
+	return downloadURI.ArtifactURI, version, nil
+}
+
+func (p *MLflowPlugin) get(path string, query url.Values, response interface{}) error {
+	resp, err := p.httpClient.Get(p.url + path + "?" + query.Encode())
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned status %d", path, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(response)
+}
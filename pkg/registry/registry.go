@@ -0,0 +1,147 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package registry resolves the model registry references of the predictors to the storage URIs of the model
+// artifacts. The registries are resolved by the plugins of their types, builds of the controller may register
+// plugins for other registries.
+package registry
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+var log = logf.Log.WithName("ModelRegistryResolver")
+
+// RefreshInterval is the period of the resolution of the references to the latest version of a model
+const RefreshInterval = 5 * time.Minute
+
+// Plugin resolves the versions of the models of a registry
+type Plugin interface {
+	// Resolve returns the storage URI of the artifact of the model version and the resolved version, the latest
+	// version of the model is resolved when version is empty
+	Resolve(model string, version string) (storageURI string, resolvedVersion string, err error)
+}
+
+// Factory creates the plugin of a registry from its configuration
+type Factory func(config v1beta1.RegistryConfig) (Plugin, error)
+
+var (
+	mu        sync.RWMutex
+	factories = map[string]Factory{
+		MLflowRegistryType: NewMLflowPlugin,
+	}
+)
+
+// Register adds the plugin factory of a registry type, it replaces the factory already registered for the type
+func Register(registryType string, factory Factory) {
+	mu.Lock()
+	defer mu.Unlock()
+	factories[registryType] = factory
+}
+
+func newPlugin(config v1beta1.RegistryConfig) (Plugin, error) {
+	mu.RLock()
+	factory, ok := factories[config.Type]
+	mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("model registry type %q is not supported", config.Type)
+	}
+	return factory(config)
+}
+
+// Resolve resolves the model registry reference of the predictor, records the resolved version in the status and
+// sets the storage URI of the predictor to the artifact of the version. A pinned version which has already been
+// resolved is not looked up again, and a reference to the latest version is looked up again once the refresh interval
+// elapsed since its last resolution. The last resolution is kept until the next refresh when the registry can not be
+// reached.
+func Resolve(isvc *v1beta1.InferenceService, registries map[string]v1beta1.RegistryConfig, now time.Time) error {
+	extensions := isvc.Spec.Predictor.GetPredictorExtensions()
+	if extensions == nil || extensions.ModelRef == nil {
+		isvc.Status.ResolvedModel = nil
+		return nil
+	}
+	ref := extensions.ModelRef
+	if !isvc.Status.ResolvedModel.Matches(ref) ||
+		(ref.Version == "" && now.Sub(isvc.Status.ResolvedModel.ResolveTime.Time) >= RefreshInterval) {
+		resolved, err := resolve(ref, registries)
+		if err != nil {
+			if !isvc.Status.ResolvedModel.Matches(ref) {
+				return err
+			}
+			log.Error(err, "Failed to resolve the latest model version, keeping the resolved version",
+				"namespace", isvc.Namespace, "name", isvc.Name, "version", isvc.Status.ResolvedModel.Version)
+			isvc.Status.ResolvedModel.ResolveTime = metav1.NewTime(now)
+		} else {
+			resolved.ResolveTime = metav1.NewTime(now)
+			isvc.Status.ResolvedModel = resolved
+		}
+	}
+	Apply(isvc)
+	return nil
+}
+
+// NextRefresh returns the delay until the next resolution of a reference to the latest version, zero if the predictor
+// references no latest version or the reference has not been resolved
+func NextRefresh(isvc *v1beta1.InferenceService, now time.Time) time.Duration {
+	extensions := isvc.Spec.Predictor.GetPredictorExtensions()
+	if extensions == nil || extensions.ModelRef == nil || extensions.ModelRef.Version != "" ||
+		!isvc.Status.ResolvedModel.Matches(extensions.ModelRef) {
+		return 0
+	}
+	next := isvc.Status.ResolvedModel.ResolveTime.Add(RefreshInterval).Sub(now)
+	if next < time.Second {
+		return time.Second
+	}
+	return next
+}
+
+// Apply sets the storage URI of the predictor from the resolved version of the status without calling the registry,
+// the storage URI is left unset when the reference has not been resolved
+func Apply(isvc *v1beta1.InferenceService) {
+	extensions := isvc.Spec.Predictor.GetPredictorExtensions()
+	if extensions == nil || !isvc.Status.ResolvedModel.Matches(extensions.ModelRef) {
+		return
+	}
+	storageURI := isvc.Status.ResolvedModel.StorageURI
+	extensions.StorageURI = &storageURI
+}
+
+func resolve(ref *v1beta1.ModelRegistryRef, registries map[string]v1beta1.RegistryConfig) (*v1beta1.ResolvedModelStatus, error) {
+	config, ok := registries[ref.Registry]
+	if !ok {
+		return nil, fmt.Errorf("model registry %q is not configured", ref.Registry)
+	}
+	plugin, err := newPlugin(config)
+	if err != nil {
+		return nil, err
+	}
+	storageURI, version, err := plugin.Resolve(ref.Model, ref.Version)
+	if err != nil {
+		return nil, fmt.Errorf("fails to resolve model %s of registry %s: %v", ref.Model, ref.Registry, err)
+	}
+	return &v1beta1.ResolvedModelStatus{
+		Registry:   ref.Registry,
+		Model:      ref.Model,
+		Version:    version,
+		StorageURI: storageURI,
+	}, nil
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newInferenceService(version string) *v1beta1.InferenceService {
+	return &v1beta1.InferenceService{
+		ObjectMeta: metav1.ObjectMeta{Name: "sklearn", Namespace: "default"},
+		Spec: v1beta1.InferenceServiceSpec{
+			Predictor: v1beta1.PredictorSpec{
+				SKLearn: &v1beta1.SKLearnSpec{
+					PredictorExtensionSpec: v1beta1.PredictorExtensionSpec{
+						ModelRef: &v1beta1.ModelRegistryRef{Registry: "mlflow", Model: "iris", Version: version},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestResolveMLflowModel(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	var requests []string
+	available := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		requests = append(requests, req.URL.Path)
+		if !available {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		switch req.URL.Path {
+		case "/api/2.0/mlflow/registered-models/get-latest-versions":
+			fmt.Fprint(w, `{"model_versions": [{"version": "3", "current_stage": "Production"}, {"version": "4", "current_stage": "None"}]}`)
+		case "/api/2.0/mlflow/model-versions/get-download-uri":
+			fmt.Fprintf(w, `{"artifact_uri": "s3://mlflow/%s/%s/model"}`, req.URL.Query().Get("name"), req.URL.Query().Get("version"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	registries := map[string]v1beta1.RegistryConfig{"mlflow": {Type: MLflowRegistryType, URL: server.URL}}
+
+	// the latest version is resolved once per refresh interval
+	now := time.Now()
+	isvc := newInferenceService("")
+	g.Expect(Resolve(isvc, registries, now)).To(gomega.Succeed())
+	g.Expect(isvc.Status.ResolvedModel).To(gomega.Equal(&v1beta1.ResolvedModelStatus{
+		Registry:    "mlflow",
+		Model:       "iris",
+		Version:     "4",
+		StorageURI:  "s3://mlflow/iris/4/model",
+		ResolveTime: metav1.NewTime(now),
+	}))
+	g.Expect(*isvc.Spec.Predictor.SKLearn.StorageURI).To(gomega.Equal("s3://mlflow/iris/4/model"))
+	g.Expect(NextRefresh(isvc, now.Add(time.Minute))).To(gomega.Equal(RefreshInterval - time.Minute))
+	requests = nil
+	isvc.Spec.Predictor.SKLearn.StorageURI = nil
+	g.Expect(Resolve(isvc, registries, now.Add(time.Minute))).To(gomega.Succeed())
+	g.Expect(requests).To(gomega.BeEmpty())
+	g.Expect(*isvc.Spec.Predictor.SKLearn.StorageURI).To(gomega.Equal("s3://mlflow/iris/4/model"))
+
+	// the resolved version is kept until the next refresh while the registry is unavailable
+	available = false
+	now = now.Add(RefreshInterval)
+	isvc.Spec.Predictor.SKLearn.StorageURI = nil
+	g.Expect(Resolve(isvc, registries, now)).To(gomega.Succeed())
+	g.Expect(requests).NotTo(gomega.BeEmpty())
+	g.Expect(*isvc.Spec.Predictor.SKLearn.StorageURI).To(gomega.Equal("s3://mlflow/iris/4/model"))
+	g.Expect(NextRefresh(isvc, now)).To(gomega.Equal(RefreshInterval))
+
+	// a pinned version is resolved once
+	g.Expect(NextRefresh(newInferenceService("3"), now)).To(gomega.BeZero())
+	available = true
+	requests = nil
+	isvc = newInferenceService("3")
+	g.Expect(Resolve(isvc, registries, now)).To(gomega.Succeed())
+	g.Expect(isvc.Status.ResolvedModel.StorageURI).To(gomega.Equal("s3://mlflow/iris/3/model"))
+	g.Expect(requests).To(gomega.Equal([]string{"/api/2.0/mlflow/model-versions/get-download-uri"}))
+	requests = nil
+	isvc.Spec.Predictor.SKLearn.StorageURI = nil
+	g.Expect(Resolve(isvc, registries, now)).To(gomega.Succeed())
+	g.Expect(requests).To(gomega.BeEmpty())
+	g.Expect(*isvc.Spec.Predictor.SKLearn.StorageURI).To(gomega.Equal("s3://mlflow/iris/3/model"))
+
+	// a reference which has never been resolved fails while the registry is unavailable
+	available = false
+	isvc = newInferenceService("2")
+	g.Expect(Resolve(isvc, registries, now)).NotTo(gomega.Succeed())
+	g.Expect(isvc.Spec.Predictor.SKLearn.StorageURI).To(gomega.BeNil())
+
+	// unknown registries are rejected
+	isvc = newInferenceService("")
+	g.Expect(Resolve(isvc, map[string]v1beta1.RegistryConfig{}, now)).To(gomega.MatchError(`model registry "mlflow" is not configured`))
+}
+
+type staticPlugin struct{}
+
+func (p *staticPlugin) Resolve(model string, version string) (string, string, error) {
+	return "gs://models/" + model, "1", nil
+}
+
+func TestRegisterPlugin(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	Register("static", func(config v1beta1.RegistryConfig) (Plugin, error) {
+		return &staticPlugin{}, nil
+	})
+	isvc := newInferenceService("")
+	g.Expect(Resolve(isvc, map[string]v1beta1.RegistryConfig{"mlflow": {Type: "static"}}, time.Now())).To(gomega.Succeed())
+	g.Expect(*isvc.Spec.Predictor.SKLearn.StorageURI).To(gomega.Equal("gs://models/iris"))
+}
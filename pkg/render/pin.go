@@ -0,0 +1,231 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/serving/pkg/apis/serving"
+	"sigs.k8s.io/yaml"
+)
+
+// ModelDigestFunc returns the digest of the model at the storage uri, or an empty digest if the storage is not
+// supported, in which case the model is not pinned
+type ModelDigestFunc func(storageURI string) (string, error)
+
+// componentImplementations returns the implementations of the InferenceService components by component type
+func componentImplementations(isvc *v1beta1.InferenceService) map[v1beta1.ComponentType]v1beta1.ComponentImplementation {
+	implementations := map[v1beta1.ComponentType]v1beta1.ComponentImplementation{}
+	if implementation := isvc.Spec.Predictor.GetImplementation(); implementation != nil {
+		implementations[v1beta1.PredictorComponent] = implementation
+	}
+	if isvc.Spec.Transformer != nil {
+		if implementation := isvc.Spec.Transformer.GetImplementation(); implementation != nil {
+			implementations[v1beta1.TransformerComponent] = implementation
+		}
+	}
+	if isvc.Spec.Explainer != nil {
+		if implementation := isvc.Spec.Explainer.GetImplementation(); implementation != nil {
+			implementations[v1beta1.ExplainerComponent] = implementation
+		}
+	}
+	if isvc.Spec.DriftDetector != nil {
+		if implementation := isvc.Spec.DriftDetector.GetImplementation(); implementation != nil {
+			implementations[v1beta1.DriftDetectorComponent] = implementation
+		}
+	}
+	if isvc.Spec.OutlierDetector != nil {
+		if implementation := isvc.Spec.OutlierDetector.GetImplementation(); implementation != nil {
+			implementations[v1beta1.OutlierDetectorComponent] = implementation
+		}
+	}
+	return implementations
+}
+
+// implementationContainer returns the container of the spec the image of a component is set on
+func implementationContainer(implementation v1beta1.ComponentImplementation) *v1.Container {
+	switch impl := implementation.(type) {
+	case *v1beta1.CustomPredictor:
+		if len(impl.Containers) > 0 {
+			return &impl.Containers[0]
+		}
+	case *v1beta1.CustomExplainer:
+		if len(impl.Containers) > 0 {
+			return &impl.Containers[0]
+		}
+	case *v1beta1.CustomTransformer:
+		if len(impl.Containers) > 0 {
+			return &impl.Containers[0]
+		}
+	case *v1beta1.CustomDetector:
+		if len(impl.Containers) > 0 {
+			return &impl.Containers[0]
+		}
+	default:
+		// The framework specs embed the container overriding the defaults of the inferenceservice configmap
+		if field := reflect.ValueOf(implementation).Elem().FieldByName("Container"); field.IsValid() && field.CanAddr() {
+			if container, ok := field.Addr().Interface().(*v1.Container); ok {
+				return container
+			}
+		}
+	}
+	return nil
+}
+
+// imageRepository strips the tag or digest of an image reference
+func imageRepository(image string) string {
+	if i := strings.Index(image, "@"); i >= 0 {
+		image = image[:i]
+	}
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image = image[:i]
+	}
+	return image
+}
+
+// resolvedImage returns the image of the container pinned to the digest the kubelet resolved for it
+func resolvedImage(pod *v1.Pod, containerName string) (string, error) {
+	image := ""
+	for _, container := range pod.Spec.Containers {
+		if container.Name == containerName {
+			image = container.Image
+		}
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name != containerName {
+			continue
+		}
+		// The image id is reported as e.g. docker-pullable://kfserving/sklearnserver@sha256:<hex>
+		i := strings.LastIndex(status.ImageID, "@sha256:")
+		if image == "" || i < 0 {
+			break
+		}
+		return imageRepository(image) + status.ImageID[i:], nil
+	}
+	return "", fmt.Errorf("container %s of pod %s has no resolved image digest", containerName, pod.Name)
+}
+
+// Pin sets the images of the InferenceService components to the digests resolved in their running pods, and records
+// the digests of the component models in the model-digests annotation unless modelDigest is nil. The pods are the
+// pods of the InferenceService, the pods of the latest ready revision of each component are used.
+func Pin(isvc *v1beta1.InferenceService, pods []v1.Pod, modelDigest ModelDigestFunc) error {
+	modelDigests := []string{}
+	for component, implementation := range componentImplementations(isvc) {
+		container := implementationContainer(implementation)
+		if container == nil {
+			return fmt.Errorf("the %s of InferenceService %q has no container to pin", component, isvc.Name)
+		}
+		containerName := container.Name
+		if containerName == "" {
+			containerName = constants.InferenceServiceContainerName
+		}
+		revision := isvc.Status.Components[component].LatestReadyRevision
+		var pod *v1.Pod
+		for i := range pods {
+			if pods[i].Labels[constants.KServiceComponentLabel] != string(component) || pods[i].Status.Phase != v1.PodRunning {
+				continue
+			}
+			if revision == "" || pods[i].Labels[serving.RevisionLabelKey] == revision {
+				pod = &pods[i]
+				break
+			}
+		}
+		if pod == nil {
+			return fmt.Errorf("the %s of InferenceService %q has no running pod to resolve its image", component, isvc.Name)
+		}
+		image, err := resolvedImage(pod, containerName)
+		if err != nil {
+			return err
+		}
+		container.Image = image
+
+		if storageURI := implementation.GetStorageUri(); storageURI != nil && modelDigest != nil {
+			digest, err := modelDigest(*storageURI)
+			if err != nil {
+				return fmt.Errorf("fails to compute the model digest of the %s: %v", component, err)
+			}
+			if digest != "" {
+				modelDigests = append(modelDigests, string(component)+"="+digest)
+			}
+		}
+	}
+	if len(modelDigests) > 0 {
+		sort.Strings(modelDigests)
+		if isvc.Annotations == nil {
+			isvc.Annotations = map[string]string{}
+		}
+		isvc.Annotations[constants.ModelDigestsAnnotationKey] = strings.Join(modelDigests, ",")
+	}
+	return nil
+}
+
+// VerifyModelDigests checks that the models of the InferenceService still have the digests recorded when it was
+// exported, so that an imported InferenceService serves the same models as the exported one
+func VerifyModelDigests(isvc *v1beta1.InferenceService, modelDigest ModelDigestFunc) error {
+	annotation, ok := isvc.Annotations[constants.ModelDigestsAnnotationKey]
+	if !ok {
+		return nil
+	}
+	implementations := componentImplementations(isvc)
+	for _, entry := range strings.Split(annotation, ",") {
+		tokens := strings.SplitN(entry, "=", 2)
+		if len(tokens) != 2 {
+			return fmt.Errorf("invalid model digest %q", entry)
+		}
+		component, expected := v1beta1.ComponentType(tokens[0]), tokens[1]
+		implementation, ok := implementations[component]
+		if !ok || implementation.GetStorageUri() == nil {
+			return fmt.Errorf("model digest %q does not match a component model", entry)
+		}
+		digest, err := modelDigest(*implementation.GetStorageUri())
+		if err != nil {
+			return fmt.Errorf("fails to compute the model digest of the %s: %v", component, err)
+		}
+		if digest != expected {
+			return fmt.Errorf("the model of the %s has digest %s, the exported digest is %s", component, digest, expected)
+		}
+	}
+	return nil
+}
+
+// ParseExport returns the InferenceService of an exported YAML stream, the child resources are ignored as the
+// controller creates them again
+func ParseExport(data []byte) (*v1beta1.InferenceService, error) {
+	for _, document := range bytes.Split(data, []byte("\n---\n")) {
+		typeMeta := &metav1.TypeMeta{}
+		if err := yaml.Unmarshal(document, typeMeta); err != nil {
+			return nil, err
+		}
+		if typeMeta.Kind != "InferenceService" {
+			continue
+		}
+		isvc := &v1beta1.InferenceService{}
+		if err := yaml.Unmarshal(document, isvc); err != nil {
+			return nil, err
+		}
+		return isvc, nil
+	}
+	return nil, fmt.Errorf("no InferenceService found in the exported manifests")
+}
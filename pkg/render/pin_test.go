@@ -0,0 +1,167 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render
+
+import (
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/serving/pkg/apis/serving"
+	knservingv1 "knative.dev/serving/pkg/apis/serving/v1"
+)
+
+const imageDigest = "sha256:9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+
+func predictorPod(revision string, imageID string) v1.Pod {
+	return v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: revision + "-deployment-5d8f7c9b4-x7k2p",
+			Labels: map[string]string{
+				constants.KServiceComponentLabel: string(v1beta1.PredictorComponent),
+				serving.RevisionLabelKey:         revision,
+			},
+		},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{Name: constants.InferenceServiceContainerName, Image: "localhost:5000/kfserving/sklearnserver:v0.4.0"},
+				{Name: "queue-proxy", Image: "gcr.io/knative-releases/queue"},
+			},
+		},
+		Status: v1.PodStatus{
+			Phase: v1.PodRunning,
+			ContainerStatuses: []v1.ContainerStatus{
+				{Name: "queue-proxy", ImageID: "docker-pullable://gcr.io/knative-releases/queue@sha256:0000"},
+				{Name: constants.InferenceServiceContainerName, ImageID: imageID},
+			},
+		},
+	}
+}
+
+func TestPin(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	storageUri := "s3://models/sklearn/iris"
+	isvc := &v1beta1.InferenceService{
+		ObjectMeta: metav1.ObjectMeta{Name: "sklearn-iris", Namespace: "default"},
+		Spec: v1beta1.InferenceServiceSpec{
+			Predictor: v1beta1.PredictorSpec{
+				SKLearn: &v1beta1.SKLearnSpec{
+					PredictorExtensionSpec: v1beta1.PredictorExtensionSpec{StorageURI: &storageUri},
+				},
+			},
+		},
+		Status: v1beta1.InferenceServiceStatus{
+			Components: map[v1beta1.ComponentType]v1beta1.ComponentStatusSpec{
+				v1beta1.PredictorComponent: {LatestReadyRevision: "sklearn-iris-predictor-default-00002"},
+			},
+		},
+	}
+	pods := []v1.Pod{
+		predictorPod("sklearn-iris-predictor-default-00001", "docker-pullable://kfserving/sklearnserver@sha256:1111"),
+		predictorPod("sklearn-iris-predictor-default-00002", "docker-pullable://kfserving/sklearnserver@"+imageDigest),
+	}
+	modelDigest := func(uri string) (string, error) {
+		return "sha256:" + uri, nil
+	}
+
+	g.Expect(Pin(isvc, pods, modelDigest)).Should(gomega.Succeed())
+	g.Expect(isvc.Spec.Predictor.SKLearn.Image).To(gomega.Equal("localhost:5000/kfserving/sklearnserver@" + imageDigest))
+	g.Expect(isvc.Annotations).To(gomega.HaveKeyWithValue(constants.ModelDigestsAnnotationKey,
+		"predictor=sha256:s3://models/sklearn/iris"))
+
+	// the pinned image is kept by the defaulting, so the rendered knative service runs the same image
+	objects, err := Render(isvc, options)
+	g.Expect(err).Should(gomega.BeNil())
+	ksvc := objects[0].(*knservingv1.Service)
+	g.Expect(ksvc.Spec.Template.Spec.Containers[0].Image).To(gomega.Equal(isvc.Spec.Predictor.SKLearn.Image))
+}
+
+func TestPinWithoutResolvedDigest(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := &v1beta1.InferenceService{
+		ObjectMeta: metav1.ObjectMeta{Name: "sklearn-iris", Namespace: "default"},
+		Spec: v1beta1.InferenceServiceSpec{
+			Predictor: v1beta1.PredictorSpec{
+				SKLearn: &v1beta1.SKLearnSpec{},
+			},
+		},
+	}
+	err := Pin(isvc, []v1.Pod{predictorPod("sklearn-iris-predictor-default-00001", "sha256:1111")}, nil)
+	g.Expect(err).ShouldNot(gomega.BeNil())
+
+	err = Pin(isvc, []v1.Pod{}, nil)
+	g.Expect(err).ShouldNot(gomega.BeNil())
+}
+
+func TestVerifyModelDigests(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	storageUri := "s3://models/sklearn/iris"
+	isvc := &v1beta1.InferenceService{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "sklearn-iris",
+			Annotations: map[string]string{constants.ModelDigestsAnnotationKey: "predictor=sha256:1111"},
+		},
+		Spec: v1beta1.InferenceServiceSpec{
+			Predictor: v1beta1.PredictorSpec{
+				SKLearn: &v1beta1.SKLearnSpec{
+					PredictorExtensionSpec: v1beta1.PredictorExtensionSpec{StorageURI: &storageUri},
+				},
+			},
+		},
+	}
+	digest := "sha256:1111"
+	modelDigest := func(uri string) (string, error) {
+		return digest, nil
+	}
+	g.Expect(VerifyModelDigests(isvc, modelDigest)).Should(gomega.Succeed())
+
+	digest = "sha256:2222"
+	g.Expect(VerifyModelDigests(isvc, modelDigest)).ShouldNot(gomega.Succeed())
+
+	isvc.Annotations[constants.ModelDigestsAnnotationKey] = "explainer=sha256:1111"
+	g.Expect(VerifyModelDigests(isvc, modelDigest)).ShouldNot(gomega.Succeed())
+}
+
+func TestParseExport(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	storageUri := "gs://kfserving-samples/models/sklearn/iris"
+	isvc := &v1beta1.InferenceService{
+		ObjectMeta: metav1.ObjectMeta{Name: "sklearn-iris", Namespace: "default"},
+		Spec: v1beta1.InferenceServiceSpec{
+			Predictor: v1beta1.PredictorSpec{
+				SKLearn: &v1beta1.SKLearnSpec{
+					PredictorExtensionSpec: v1beta1.PredictorExtensionSpec{StorageURI: &storageUri},
+				},
+			},
+		},
+	}
+	objects, err := Export(isvc, options)
+	g.Expect(err).Should(gomega.BeNil())
+	data, err := ToYAML(append(objects[1:], objects[0]))
+	g.Expect(err).Should(gomega.BeNil())
+
+	imported, err := ParseExport(data)
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(imported.Name).To(gomega.Equal("sklearn-iris"))
+	g.Expect(*imported.Spec.Predictor.SKLearn.StorageURI).To(gomega.Equal(storageUri))
+
+	_, err = ParseExport([]byte("apiVersion: v1\nkind: ConfigMap\n"))
+	g.Expect(err).ShouldNot(gomega.BeNil())
+}
@@ -0,0 +1,225 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package render generates the child resources of an InferenceService the same way the controller does,
+// without applying them to a cluster.
+package render
+
+import (
+	"fmt"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/components"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/reconcilers/ingress"
+	v1beta1utils "github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/utils"
+	"github.com/kubeflow/kfserving/pkg/registry"
+	"github.com/pkg/errors"
+	"istio.io/client-go/pkg/apis/networking/v1alpha3"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	knservingv1 "knative.dev/serving/pkg/apis/serving/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// DefaultDomain is the knative default domain used to derive the InferenceService host
+	DefaultDomain = "example.com"
+	// lastAppliedConfigAnnotation is set by kubectl apply, it is not part of the exported manifest
+	lastAppliedConfigAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+)
+
+// unexportedAnnotations are removed from the exported manifests, they are set by the servers or they refer to the
+// revisions and the observed state of the source cluster
+var unexportedAnnotations = []string{
+	lastAppliedConfigAnnotation,
+	constants.DesiredSpecHashInternalAnnotationKey,
+	constants.RollbackAnnotationKey,
+	"serving.knative.dev/creator",
+	"serving.knative.dev/lastModifier",
+}
+
+// Scheme knows about the InferenceService and all the resources rendered for it
+var Scheme = runtime.NewScheme()
+
+func init() {
+	for _, addToScheme := range []func(*runtime.Scheme) error{
+		v1.AddToScheme,
+		v1beta1.AddToScheme,
+		knservingv1.AddToScheme,
+		v1alpha3.AddToScheme,
+	} {
+		if err := addToScheme(Scheme); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Options configures how the child resources are rendered
+type Options struct {
+	// InferenceServicesConfig is the component configuration from the inferenceservice configmap
+	InferenceServicesConfig *v1beta1.InferenceServicesConfig
+	// IngressConfig is the ingress configuration from the inferenceservice configmap
+	IngressConfig *v1beta1.IngressConfig
+	// Domain is used to derive the host of an InferenceService which has not been assigned a url yet,
+	// defaults to DefaultDomain
+	Domain string
+}
+
+// Render returns the child resources the controller creates for the InferenceService. The InferenceService is
+// defaulted and validated the same way as by the admission webhooks, the given object is not modified.
+func Render(isvc *v1beta1.InferenceService, options Options) ([]runtime.Object, error) {
+	if options.InferenceServicesConfig == nil || options.IngressConfig == nil {
+		return nil, fmt.Errorf("InferenceServicesConfig and IngressConfig are required to render InferenceService %q", isvc.Name)
+	}
+	domain := options.Domain
+	if domain == "" {
+		domain = DefaultDomain
+	}
+	isvc = isvc.DeepCopy()
+	isvc.DefaultInferenceService(options.InferenceServicesConfig)
+	if err := isvc.ValidateCreate(); err != nil {
+		return nil, errors.Wrapf(err, "invalid InferenceService %q", isvc.Name)
+	}
+	// The registry is not called, the model reference renders with the version recorded in the status
+	registry.Apply(isvc)
+
+	if v1beta1utils.GetDeploymentMode(isvc) == constants.ModelMeshDeployment {
+		objects, err := components.NewModelMesh(nil, Scheme, options.InferenceServicesConfig).Render(isvc)
+		if err != nil {
+			return nil, errors.Wrapf(err, "fails to render modelmesh predictor")
+		}
+		return objects, nil
+	}
+
+	renderers := []components.Component{
+		components.NewPredictor(nil, Scheme, options.InferenceServicesConfig),
+	}
+	if isvc.Spec.Transformer != nil {
+		renderers = append(renderers, components.NewTransformer(nil, Scheme, options.InferenceServicesConfig))
+	}
+	if isvc.Spec.Explainer != nil {
+		renderers = append(renderers, components.NewExplainer(nil, Scheme, options.InferenceServicesConfig))
+	}
+	if isvc.Spec.DriftDetector != nil {
+		renderers = append(renderers, components.NewDriftDetector(nil, Scheme, options.InferenceServicesConfig))
+	}
+	if isvc.Spec.OutlierDetector != nil {
+		renderers = append(renderers, components.NewOutlierDetector(nil, Scheme, options.InferenceServicesConfig))
+	}
+	objects := []runtime.Object{}
+	for _, renderer := range renderers {
+		rendered, err := renderer.Render(isvc)
+		if err != nil {
+			return nil, errors.Wrapf(err, "fails to render component")
+		}
+		objects = append(objects, rendered...)
+	}
+	rendered, err := ingress.NewIngressReconciler(nil, Scheme, options.IngressConfig,
+		options.InferenceServicesConfig.Drift.Policy).Render(isvc, domain)
+	if err != nil {
+		return nil, errors.Wrapf(err, "fails to render ingress")
+	}
+	objects = append(objects, rendered...)
+
+	// The reconcilers do not set the type meta, which is needed to apply or diff the rendered resources
+	for _, object := range objects {
+		gvk, err := apiutil.GVKForObject(object, Scheme)
+		if err != nil {
+			return nil, err
+		}
+		object.GetObjectKind().SetGroupVersionKind(gvk)
+	}
+	return objects, nil
+}
+
+// Export returns the defaulted InferenceService followed by its child resources. The server populated metadata and
+// the status are removed from the exported InferenceService, so that the manifests can be reviewed and committed
+// to a GitOps repository.
+func Export(isvc *v1beta1.InferenceService, options Options) ([]runtime.Object, error) {
+	children, err := Render(isvc, options)
+	if err != nil {
+		return nil, err
+	}
+	exported := isvc.DeepCopy()
+	exported.DefaultInferenceService(options.InferenceServicesConfig)
+	exported.Status = v1beta1.InferenceServiceStatus{}
+	gvk, err := apiutil.GVKForObject(exported, Scheme)
+	if err != nil {
+		return nil, err
+	}
+	exported.SetGroupVersionKind(gvk)
+	objects := append([]runtime.Object{exported}, children...)
+	for _, object := range objects {
+		if err := stripServerFields(object); err != nil {
+			return nil, err
+		}
+	}
+	return objects, nil
+}
+
+// stripServerFields removes the metadata set by the servers and the owner references from the object, so that the
+// manifest can be applied to another cluster. The revisions the knative service traffic is pinned to only exist in the
+// source cluster, the exported knative service routes its traffic to the latest revision.
+func stripServerFields(object runtime.Object) error {
+	accessor, err := meta.Accessor(object)
+	if err != nil {
+		return err
+	}
+	annotations := accessor.GetAnnotations()
+	for _, annotation := range unexportedAnnotations {
+		delete(annotations, annotation)
+	}
+	accessor.SetAnnotations(annotations)
+	accessor.SetOwnerReferences(nil)
+	accessor.SetUID("")
+	accessor.SetResourceVersion("")
+	accessor.SetGeneration(0)
+	accessor.SetCreationTimestamp(metav1.Time{})
+	accessor.SetDeletionTimestamp(nil)
+	accessor.SetFinalizers(nil)
+	accessor.SetManagedFields(nil)
+	accessor.SetSelfLink("")
+	if service, ok := object.(*knservingv1.Service); ok {
+		service.Status = knservingv1.ServiceStatus{}
+		for _, target := range service.Spec.Traffic {
+			if target.RevisionName != "" {
+				service.Spec.Traffic = nil
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// ToYAML serializes the objects to a multi-document YAML stream
+func ToYAML(objects []runtime.Object) ([]byte, error) {
+	out := []byte{}
+	for i, object := range objects {
+		data, err := yaml.Marshal(object)
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			out = append(out, []byte("---\n")...)
+		}
+		out = append(out, data...)
+	}
+	return out, nil
+}
@@ -0,0 +1,165 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/onsi/gomega"
+	"istio.io/client-go/pkg/apis/networking/v1alpha3"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	knservingv1 "knative.dev/serving/pkg/apis/serving/v1"
+)
+
+var options = Options{
+	InferenceServicesConfig: &v1beta1.InferenceServicesConfig{
+		Predictors: v1beta1.PredictorsConfig{
+			SKlearn: v1beta1.PredictorProtocols{
+				V1: &v1beta1.PredictorConfig{
+					ContainerImage:      "kfserving/sklearnserver",
+					DefaultImageVersion: "v0.4.0",
+				},
+			},
+		},
+	},
+	IngressConfig: &v1beta1.IngressConfig{
+		IngressGateway:     "knative-serving/knative-ingress-gateway",
+		IngressServiceName: "istio-ingressgateway.istio-system.svc.cluster.local",
+	},
+}
+
+func TestRender(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	storageUri := "gs://kfserving-samples/models/sklearn/iris"
+	isvc := &v1beta1.InferenceService{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "sklearn-iris",
+			Namespace: "default",
+		},
+		Spec: v1beta1.InferenceServiceSpec{
+			Predictor: v1beta1.PredictorSpec{
+				SKLearn: &v1beta1.SKLearnSpec{
+					PredictorExtensionSpec: v1beta1.PredictorExtensionSpec{
+						StorageURI: &storageUri,
+					},
+				},
+			},
+			Transformer: &v1beta1.TransformerSpec{
+				PodSpec: v1beta1.PodSpec{
+					Containers: []v1.Container{
+						{Image: "transformer:v1"},
+					},
+				},
+			},
+		},
+	}
+	objects, err := Render(isvc, options)
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(objects).To(gomega.HaveLen(4))
+	// the given InferenceService is not defaulted
+	g.Expect(isvc.Spec.Predictor.SKLearn.RuntimeVersion).To(gomega.BeNil())
+
+	predictor := objects[0].(*knservingv1.Service)
+	g.Expect(predictor.Name).To(gomega.Equal(constants.DefaultPredictorServiceName("sklearn-iris")))
+	g.Expect(predictor.Kind).To(gomega.Equal("Service"))
+	g.Expect(predictor.APIVersion).To(gomega.Equal("serving.knative.dev/v1"))
+	g.Expect(predictor.OwnerReferences).To(gomega.HaveLen(1))
+	g.Expect(predictor.Spec.Template.Spec.Containers[0].Image).To(gomega.Equal("kfserving/sklearnserver:v0.4.0"))
+	g.Expect(predictor.Spec.Template.Annotations).To(gomega.HaveKeyWithValue(
+		constants.StorageInitializerSourceUriInternalAnnotationKey, storageUri))
+
+	transformer := objects[1].(*knservingv1.Service)
+	g.Expect(transformer.Name).To(gomega.Equal(constants.DefaultTransformerServiceName("sklearn-iris")))
+
+	externalService := objects[2].(*v1.Service)
+	g.Expect(externalService.APIVersion).To(gomega.Equal("v1"))
+	g.Expect(externalService.Spec.ExternalName).To(gomega.Equal(constants.LocalGatewayHost))
+
+	virtualService := objects[3].(*v1alpha3.VirtualService)
+	g.Expect(virtualService.Kind).To(gomega.Equal("VirtualService"))
+	g.Expect(virtualService.Spec.Hosts).To(gomega.ContainElement("sklearn-iris.default.example.com"))
+
+	data, err := ToYAML(objects)
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(strings.Count(string(data), "\n---\n")).To(gomega.Equal(3))
+}
+
+func TestRenderInvalid(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := &v1beta1.InferenceService{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "sklearn-iris",
+			Namespace: "default",
+		},
+	}
+	_, err := Render(isvc, options)
+	g.Expect(err).ShouldNot(gomega.BeNil())
+}
+
+func TestExport(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	storageUri := "gs://kfserving-samples/models/sklearn/iris"
+	isvc := &v1beta1.InferenceService{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "sklearn-iris",
+			Namespace:       "default",
+			ResourceVersion: "1",
+			UID:             "2a7b7b8e-0e8c-4f1d-9e3b-1c5b1f0e6d3a",
+			Finalizers:      []string{"inferenceservice.finalizers"},
+			Annotations: map[string]string{
+				lastAppliedConfigAnnotation:     "{}",
+				constants.RollbackAnnotationKey: "true",
+				"serving.kubeflow.org/foo":      "bar",
+			},
+		},
+		Spec: v1beta1.InferenceServiceSpec{
+			Predictor: v1beta1.PredictorSpec{
+				SKLearn: &v1beta1.SKLearnSpec{
+					PredictorExtensionSpec: v1beta1.PredictorExtensionSpec{
+						StorageURI: &storageUri,
+					},
+				},
+			},
+		},
+	}
+	objects, err := Export(isvc, options)
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(objects).To(gomega.HaveLen(4))
+
+	exported := objects[0].(*v1beta1.InferenceService)
+	g.Expect(exported.Kind).To(gomega.Equal("InferenceService"))
+	g.Expect(exported.APIVersion).To(gomega.Equal("serving.kubeflow.org/v1beta1"))
+	g.Expect(exported.ResourceVersion).To(gomega.BeEmpty())
+	g.Expect(exported.UID).To(gomega.BeEmpty())
+	g.Expect(exported.Finalizers).To(gomega.BeEmpty())
+	g.Expect(exported.Annotations).To(gomega.Equal(map[string]string{"serving.kubeflow.org/foo": "bar"}))
+	g.Expect(*exported.Spec.Predictor.SKLearn.RuntimeVersion).To(gomega.Equal("v0.4.0"))
+	// the children can be applied without their owner
+	for _, child := range objects[1:] {
+		accessor, err := meta.Accessor(child)
+		g.Expect(err).Should(gomega.BeNil())
+		g.Expect(accessor.GetOwnerReferences()).To(gomega.BeEmpty())
+		g.Expect(accessor.GetAnnotations()).NotTo(gomega.HaveKey(constants.DesiredSpecHashInternalAnnotationKey))
+	}
+	// the given InferenceService is not modified
+	g.Expect(isvc.Annotations).To(gomega.HaveKey(lastAppliedConfigAnnotation))
+}
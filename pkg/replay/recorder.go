@@ -0,0 +1,144 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package replay
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/kubeflow/kfserving/pkg/logger"
+)
+
+// Recorder archives the log events the payload logger sends to it, the logger url of the InferenceService is set to
+// the recorder. The events are appended in the structured JSON format of CloudEvents to one JSON lines file per day,
+// e.g. 2020-10-01.jsonl, so the files are replayed in the order the events were recorded.
+type Recorder struct {
+	// OutputDir is the directory of the archive
+	OutputDir string
+	Log       logr.Logger
+	// now returns the time the events are recorded at
+	now func() time.Time
+
+	mu   sync.Mutex
+	day  string
+	file *os.File
+}
+
+// NewRecorder returns the recorder of the archive in outputDir
+func NewRecorder(outputDir string, log logr.Logger) *Recorder {
+	return &Recorder{OutputDir: outputDir, Log: log, now: time.Now}
+}
+
+// ServeHTTP archives the CloudEvent of the request, the logger sends the events in the binary mode
+func (r *Recorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, req.Body, maxEventSize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+	event, err := parseEvent(req.Header, body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := r.write(event); err != nil {
+		r.Log.Error(err, "Failed to archive event", "id", event.ID)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// parseEvent returns the event of a binary or structured mode CloudEvent request
+func parseEvent(header http.Header, body []byte) (*Event, error) {
+	contentType := header.Get("Content-Type")
+	event := &Event{}
+	if strings.HasPrefix(contentType, "application/cloudevents+json") {
+		if err := json.Unmarshal(body, event); err != nil {
+			return nil, fmt.Errorf("invalid structured CloudEvent: %v", err)
+		}
+	} else {
+		event = &Event{
+			ID:               header.Get("Ce-Id"),
+			Type:             header.Get("Ce-Type"),
+			Source:           header.Get("Ce-Source"),
+			DataContentType:  contentType,
+			InferenceService: header.Get("Ce-" + logger.InferenceServiceAttr),
+			Namespace:        header.Get("Ce-" + logger.NamespaceAttr),
+			Endpoint:         header.Get("Ce-" + logger.EndpointAttr),
+		}
+		// the payloads which are not JSON are base64 encoded in the structured format
+		if json.Valid(body) && (contentType == "" || strings.Contains(contentType, "json")) {
+			event.Data = body
+		} else if len(body) != 0 {
+			event.DataBase64 = base64.StdEncoding.EncodeToString(body)
+		}
+	}
+	if event.ID == "" || event.Type == "" {
+		return nil, fmt.Errorf("the request is not a CloudEvent, it has no id or type")
+	}
+	return event, nil
+}
+
+// write appends the event to the archive file of the day
+func (r *Recorder) write(event *Event) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	day := r.now().UTC().Format("2006-01-02")
+	if r.file == nil || r.day != day {
+		if r.file != nil {
+			r.file.Close()
+		}
+		file, err := os.OpenFile(filepath.Join(r.OutputDir, day+".jsonl"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			r.file = nil
+			return err
+		}
+		r.file, r.day = file, day
+	}
+	_, err = r.file.Write(append(line, '\n'))
+	return err
+}
+
+// Close closes the archive file
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
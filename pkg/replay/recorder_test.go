@@ -0,0 +1,106 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package replay
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kubeflow/kfserving/pkg/logger"
+	"github.com/onsi/gomega"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestRecorder(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	dir, err := ioutil.TempDir("", "archive")
+	g.Expect(err).Should(gomega.BeNil())
+	defer os.RemoveAll(dir)
+
+	now := time.Date(2020, 10, 1, 23, 59, 0, 0, time.UTC)
+	recorder := NewRecorder(dir, logf.Log)
+	recorder.now = func() time.Time { return now }
+	defer recorder.Close()
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+
+	post := func(headers map[string]string, body string) int {
+		request, err := http.NewRequest(http.MethodPost, server.URL, strings.NewReader(body))
+		g.Expect(err).Should(gomega.BeNil())
+		for name, value := range headers {
+			request.Header.Set(name, value)
+		}
+		response, err := http.DefaultClient.Do(request)
+		g.Expect(err).Should(gomega.BeNil())
+		response.Body.Close()
+		return response.StatusCode
+	}
+	binary := func(id string, eventType string, contentType string) map[string]string {
+		return map[string]string{
+			"Ce-Specversion":          "1.0",
+			"Ce-Id":                   id,
+			"Ce-Type":                 eventType,
+			"Ce-Source":               "http://localhost:9081/",
+			"Ce-Inferenceservicename": "iris",
+			"Ce-Namespace":            "default",
+			"Ce-Endpoint":             "default",
+			"Content-Type":            contentType,
+		}
+	}
+
+	g.Expect(post(binary("1", logger.CEInferenceRequest, "application/json"), "{\n  \"instances\": [2]\n}")).
+		To(gomega.Equal(http.StatusAccepted))
+	g.Expect(post(binary("2", logger.CEInferenceRequest, "text/plain"), "cat")).To(gomega.Equal(http.StatusAccepted))
+	now = now.Add(2 * time.Minute)
+	g.Expect(post(map[string]string{"Content-Type": "application/cloudevents+json"},
+		event("1", logger.CEInferenceResponse, "iris", `{"predictions":[1]}`))).To(gomega.Equal(http.StatusAccepted))
+	g.Expect(post(map[string]string{"Content-Type": "application/json"}, `{"instances":[2]}`)).
+		To(gomega.Equal(http.StatusBadRequest))
+	response, err := http.Get(server.URL)
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(response.StatusCode).To(gomega.Equal(http.StatusMethodNotAllowed))
+
+	// the events are archived in the file of the day they were recorded
+	files, err := filepath.Glob(filepath.Join(dir, "*.jsonl"))
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(files).To(gomega.Equal([]string{filepath.Join(dir, "2020-10-01.jsonl"), filepath.Join(dir, "2020-10-02.jsonl")}))
+	data, err := ioutil.ReadFile(files[0])
+	g.Expect(err).Should(gomega.BeNil())
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	g.Expect(lines).To(gomega.HaveLen(2))
+	recorded := Event{}
+	g.Expect(json.Unmarshal([]byte(lines[0]), &recorded)).Should(gomega.Succeed())
+	g.Expect(recorded).To(gomega.Equal(Event{ID: "1", Type: logger.CEInferenceRequest, Source: "http://localhost:9081/",
+		DataContentType: "application/json", Data: json.RawMessage(`{"instances":[2]}`), InferenceService: "iris",
+		Namespace: "default", Endpoint: "default"}))
+	g.Expect(json.Unmarshal([]byte(lines[1]), &recorded)).Should(gomega.Succeed())
+	g.Expect(recorded.payload()).To(gomega.Equal([]byte("cat")))
+
+	// the archive is replayed with the recorded responses
+	requests, err := (&Replayer{InputDir: dir, InferenceService: "iris", Log: logf.Log}).read()
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(requests).To(gomega.HaveLen(2))
+	g.Expect(requests[0].recorded).NotTo(gomega.BeNil())
+	g.Expect(string(requests[0].recorded.Data)).To(gomega.Equal(`{"predictions":[1]}`))
+}
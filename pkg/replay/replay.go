@@ -0,0 +1,431 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package replay sends the inference requests archived by the payload logger to an InferenceService and compares
+// its responses with the recorded responses.
+package replay
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/kubeflow/kfserving/pkg/logger"
+)
+
+const (
+	// maxEventSize is the largest archived event accepted
+	maxEventSize = 16 * 1024 * 1024
+)
+
+// Status of a replayed request
+type Status string
+
+const (
+	// Matched when the response is equal to the recorded response
+	Matched Status = "Matched"
+	// Mismatched when the response differs from the recorded response
+	Mismatched Status = "Mismatched"
+	// Unrecorded when the archive has no response for the request, the response is not compared
+	Unrecorded Status = "Unrecorded"
+	// Failed when the request could not be replayed
+	Failed Status = "Failed"
+)
+
+// Event is an archived log event in the structured JSON format of CloudEvents, the extensions are the ones set by
+// the payload logger
+type Event struct {
+	ID               string          `json:"id"`
+	Type             string          `json:"type"`
+	Source           string          `json:"source"`
+	DataContentType  string          `json:"datacontenttype,omitempty"`
+	Data             json.RawMessage `json:"data,omitempty"`
+	DataBase64       string          `json:"data_base64,omitempty"`
+	InferenceService string          `json:"inferenceservicename,omitempty"`
+	Namespace        string          `json:"namespace,omitempty"`
+	Endpoint         string          `json:"endpoint,omitempty"`
+}
+
+// payload returns the data of the event, the binary data is base64 encoded in the structured format
+func (e *Event) payload() ([]byte, error) {
+	if e.DataBase64 != "" {
+		return base64.StdEncoding.DecodeString(e.DataBase64)
+	}
+	// a JSON string holds the payloads which are not JSON
+	if e.DataContentType != "" && !strings.Contains(e.DataContentType, "json") {
+		var text string
+		if err := json.Unmarshal(e.Data, &text); err == nil {
+			return []byte(text), nil
+		}
+	}
+	return e.Data, nil
+}
+
+// Summary is the accounting of a replay, the replayer writes it as the termination message of its container
+type Summary struct {
+	Replayed   int64 `json:"replayed"`
+	Matched    int64 `json:"matched"`
+	Mismatched int64 `json:"mismatched"`
+	Unrecorded int64 `json:"unrecorded"`
+	Failed     int64 `json:"failed"`
+}
+
+// Result is a line of the output, the results are not in the archive order so the result refers to its event
+type Result struct {
+	// ID of the logged request
+	ID string `json:"id"`
+	// Source is the archive file and line number of the request, e.g. "2020-10-01.jsonl:12"
+	Source string `json:"source"`
+	Status Status `json:"status"`
+	// Diff is the first difference between the response and the recorded response
+	Diff string `json:"diff,omitempty"`
+	// Response of the target, it is only kept when it does not match the recorded response
+	Response json.RawMessage `json:"response,omitempty"`
+	// Recorded response, it is only kept when it does not match the response
+	Recorded json.RawMessage `json:"recorded,omitempty"`
+	// Error is the failure of the request
+	Error string `json:"error,omitempty"`
+}
+
+// Replayer sends the archived requests to the target in the archive order
+type Replayer struct {
+	// InputDir contains the JSON lines files of the archived events
+	InputDir string
+	// TargetURL is the endpoint the requests are sent to, e.g. http://sklearn-iris.default.svc.cluster.local/v1/models/sklearn-iris:predict
+	TargetURL string
+	// InferenceService and Namespace select the archived events of an InferenceService, all the events are replayed
+	// when they are empty
+	InferenceService string
+	Namespace        string
+	// Rate is the number of requests sent per second, the requests are not throttled when it is 0
+	Rate float64
+	// Workers is the number of concurrent requests
+	Workers int
+	// Tolerance is the largest difference of the numbers of the responses which still match
+	Tolerance float64
+	Client    *http.Client
+	Log       logr.Logger
+}
+
+type replayRequest struct {
+	source   string
+	event    *Event
+	recorded *Event
+}
+
+// Run replays the requests of the archive and writes one Result per request to out. An error is only returned when
+// the archive can not be read or the output can not be written, failed requests are accounted in the summary.
+func (r *Replayer) Run(out io.Writer) (*Summary, error) {
+	requests, err := r.read()
+	if err != nil {
+		return nil, err
+	}
+	workers := r.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	queue := make(chan *replayRequest)
+	results := make(chan Result)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for request := range queue {
+				results <- r.replay(request)
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+	go func() {
+		defer close(queue)
+		var throttle <-chan time.Time
+		if r.Rate > 0 {
+			ticker := time.NewTicker(time.Duration(float64(time.Second) / r.Rate))
+			defer ticker.Stop()
+			throttle = ticker.C
+		}
+		for i, request := range requests {
+			if throttle != nil && i > 0 {
+				<-throttle
+			}
+			queue <- request
+		}
+	}()
+
+	summary := &Summary{}
+	encoder := json.NewEncoder(out)
+	var writeErr error
+	for result := range results {
+		summary.Replayed++
+		switch result.Status {
+		case Matched:
+			summary.Matched++
+		case Mismatched:
+			summary.Mismatched++
+		case Unrecorded:
+			summary.Unrecorded++
+		case Failed:
+			summary.Failed++
+		}
+		if writeErr == nil {
+			writeErr = encoder.Encode(result)
+		}
+	}
+	return summary, writeErr
+}
+
+// read returns the requests of the archive files in lexical order, each with its recorded response. The logger
+// sends the request and the response of an inference with the same event id.
+func (r *Replayer) read() ([]*replayRequest, error) {
+	files := []string{}
+	if err := filepath.Walk(r.InputDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		// skip the hidden files and directories left by the storage initializer
+		if strings.HasPrefix(info.Name(), ".") && path != r.InputDir {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.Mode().IsRegular() {
+			files = append(files, path)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	requests := []*replayRequest{}
+	indexes := map[string]int{}
+	responses := map[string]*Event{}
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		name, _ := filepath.Rel(r.InputDir, file)
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 64*1024), maxEventSize)
+		line := 0
+		for scanner.Scan() {
+			line++
+			data := bytes.TrimSpace(scanner.Bytes())
+			if len(data) == 0 {
+				continue
+			}
+			event := &Event{}
+			if err := json.Unmarshal(data, event); err != nil {
+				r.Log.Info("Skipping invalid event", "source", fmt.Sprintf("%s:%d", name, line), "error", err.Error())
+				continue
+			}
+			if (r.InferenceService != "" && event.InferenceService != r.InferenceService) ||
+				(r.Namespace != "" && event.Namespace != r.Namespace) {
+				continue
+			}
+			switch event.Type {
+			case logger.CEInferenceRequest:
+				indexes[event.ID] = len(requests)
+				requests = append(requests, &replayRequest{source: fmt.Sprintf("%s:%d", name, line), event: event})
+			case logger.CEInferenceResponse:
+				responses[event.ID] = event
+			}
+		}
+		err = scanner.Err()
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("fails to read %s: %v", name, err)
+		}
+	}
+	for id, response := range responses {
+		if index, ok := indexes[id]; ok {
+			requests[index].recorded = response
+		}
+	}
+	return requests, nil
+}
+
+// replay sends the request to the target and compares the response with the recorded response
+func (r *Replayer) replay(request *replayRequest) Result {
+	result := Result{ID: request.event.ID, Source: request.source}
+	body, err := request.event.payload()
+	if err != nil {
+		result.Status = Failed
+		result.Error = fmt.Sprintf("fails to decode the request: %v", err)
+		return result
+	}
+	response, err := r.post(body, request.event.DataContentType)
+	if err != nil {
+		r.Log.Info("Failed to replay request", "source", request.source, "error", err.Error())
+		result.Status = Failed
+		result.Error = err.Error()
+		return result
+	}
+	if request.recorded == nil {
+		result.Status = Unrecorded
+		return result
+	}
+	recorded, err := request.recorded.payload()
+	if err != nil {
+		result.Status = Failed
+		result.Error = fmt.Sprintf("fails to decode the recorded response: %v", err)
+		return result
+	}
+	if result.Diff = r.diff(response, recorded); result.Diff == "" {
+		result.Status = Matched
+		return result
+	}
+	result.Status = Mismatched
+	result.Response = rawJSON(response)
+	result.Recorded = rawJSON(recorded)
+	return result
+}
+
+func (r *Replayer) post(body []byte, contentType string) ([]byte, error) {
+	client := r.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	response, err := client.Post(r.TargetURL, contentType, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	data, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("target returned %d: %s", response.StatusCode, strings.TrimSpace(string(data)))
+	}
+	return data, nil
+}
+
+// diff returns the first difference between the responses, the JSON responses are compared by value with the
+// numeric tolerance and the other responses byte by byte
+func (r *Replayer) diff(response, recorded []byte) string {
+	var actual, expected interface{}
+	if json.Unmarshal(response, &actual) != nil || json.Unmarshal(recorded, &expected) != nil {
+		if bytes.Equal(response, recorded) {
+			return ""
+		}
+		return "response differs from the recorded response"
+	}
+	return r.diffValues("", actual, expected)
+}
+
+func (r *Replayer) diffValues(path string, actual, expected interface{}) string {
+	switch expectedValue := expected.(type) {
+	case map[string]interface{}:
+		actualValue, ok := actual.(map[string]interface{})
+		if !ok {
+			return fmt.Sprintf("%s: %s is not an object", pathOrRoot(path), describe(actual))
+		}
+		keys := []string{}
+		for key := range expectedValue {
+			keys = append(keys, key)
+		}
+		for key := range actualValue {
+			if _, ok := expectedValue[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			field := path + "." + key
+			a, inActual := actualValue[key]
+			e, inExpected := expectedValue[key]
+			if !inActual {
+				return fmt.Sprintf("%s: missing", field)
+			}
+			if !inExpected {
+				return fmt.Sprintf("%s: unexpected %s", field, describe(a))
+			}
+			if diff := r.diffValues(field, a, e); diff != "" {
+				return diff
+			}
+		}
+		return ""
+	case []interface{}:
+		actualValue, ok := actual.([]interface{})
+		if !ok {
+			return fmt.Sprintf("%s: %s is not an array", pathOrRoot(path), describe(actual))
+		}
+		if len(actualValue) != len(expectedValue) {
+			return fmt.Sprintf("%s: %d elements, recorded %d", pathOrRoot(path), len(actualValue), len(expectedValue))
+		}
+		for i := range expectedValue {
+			if diff := r.diffValues(fmt.Sprintf("%s[%d]", path, i), actualValue[i], expectedValue[i]); diff != "" {
+				return diff
+			}
+		}
+		return ""
+	case float64:
+		if actualValue, ok := actual.(float64); ok && math.Abs(actualValue-expectedValue) <= r.Tolerance {
+			return ""
+		}
+	default:
+		if reflect.DeepEqual(actual, expected) {
+			return ""
+		}
+	}
+	return fmt.Sprintf("%s: %s, recorded %s", pathOrRoot(path), describe(actual), describe(expected))
+}
+
+func pathOrRoot(path string) string {
+	if path == "" {
+		return "."
+	}
+	return path
+}
+
+func describe(value interface{}) string {
+	data, _ := json.Marshal(value)
+	return string(data)
+}
+
+// rawJSON returns the payload as JSON, the payloads which are not JSON are kept as strings
+func rawJSON(payload []byte) json.RawMessage {
+	if json.Valid(payload) {
+		return payload
+	}
+	data, _ := json.Marshal(string(payload))
+	return data
+}
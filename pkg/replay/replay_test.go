@@ -0,0 +1,163 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/logger"
+	"github.com/onsi/gomega"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func event(id string, eventType string, isvc string, data string) string {
+	e := Event{
+		ID:               id,
+		Type:             eventType,
+		Source:           "http://localhost:9081/",
+		DataContentType:  "application/json",
+		Data:             json.RawMessage(data),
+		InferenceService: isvc,
+		Namespace:        "default",
+		Endpoint:         "default",
+	}
+	line, _ := json.Marshal(e)
+	return string(line)
+}
+
+func writeArchive(g *gomega.GomegaWithT, dir string, name string, lines ...string) {
+	g.Expect(ioutil.WriteFile(filepath.Join(dir, name), []byte(strings.Join(lines, "\n")), 0644)).Should(gomega.Succeed())
+}
+
+func readResults(g *gomega.GomegaWithT, out *bytes.Buffer) []Result {
+	results := []Result{}
+	decoder := json.NewDecoder(out)
+	for decoder.More() {
+		result := Result{}
+		g.Expect(decoder.Decode(&result)).Should(gomega.Succeed())
+		results = append(results, result)
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Source < results[j].Source })
+	return results
+}
+
+func TestReplay(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request := struct {
+			Instances []float64 `json:"instances"`
+		}{}
+		g.Expect(json.NewDecoder(r.Body).Decode(&request)).Should(gomega.Succeed())
+		if request.Instances[0] < 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		prediction, _ := json.Marshal(map[string][]float64{"predictions": {request.Instances[0] * 0.5}})
+		w.Write(prediction)
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "archive")
+	g.Expect(err).Should(gomega.BeNil())
+	defer os.RemoveAll(dir)
+	writeArchive(g, dir, "a.jsonl",
+		event("1", logger.CEInferenceRequest, "iris", `{"instances":[2]}`),
+		event("2", logger.CEInferenceRequest, "iris", `{"instances":[4]}`),
+		event("1", logger.CEInferenceResponse, "iris", `{"predictions":[1.001]}`),
+		"",
+		"not an event",
+		event("3", logger.CEInferenceRequest, "flowers", `{"instances":[6]}`),
+	)
+	writeArchive(g, dir, "b.jsonl",
+		event("2", logger.CEInferenceResponse, "iris", `{"predictions":[3]}`),
+		event("4", logger.CEInferenceRequest, "iris", `{"instances":[8]}`),
+		event("5", logger.CEInferenceRequest, "iris", `{"instances":[-1]}`),
+	)
+
+	out := &bytes.Buffer{}
+	replayer := &Replayer{InputDir: dir, TargetURL: server.URL, InferenceService: "iris", Namespace: "default",
+		Rate: 100, Workers: 2, Tolerance: 0.01, Log: logf.Log}
+	summary, err := replayer.Run(out)
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(*summary).To(gomega.Equal(Summary{Replayed: 4, Matched: 1, Mismatched: 1, Unrecorded: 1, Failed: 1}))
+	results := readResults(g, out)
+	g.Expect(results).To(gomega.HaveLen(4))
+	g.Expect(results[0]).To(gomega.Equal(Result{ID: "1", Source: "a.jsonl:1", Status: Matched}))
+	g.Expect(results[1]).To(gomega.Equal(Result{ID: "2", Source: "a.jsonl:2", Status: Mismatched,
+		Diff: ".predictions[0]: 2, recorded 3", Response: json.RawMessage(`{"predictions":[2]}`),
+		Recorded: json.RawMessage(`{"predictions":[3]}`)}))
+	g.Expect(results[2]).To(gomega.Equal(Result{ID: "4", Source: "b.jsonl:2", Status: Unrecorded}))
+	g.Expect(results[3].Status).To(gomega.Equal(Failed))
+	g.Expect(results[3].Error).To(gomega.HavePrefix("target returned 400"))
+}
+
+func TestDiff(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	replayer := &Replayer{Tolerance: 0.1}
+	scenarios := map[string]struct {
+		response string
+		recorded string
+		diff     string
+	}{
+		"Equal": {
+			response: `{"predictions":[[0.52,0.48]],"model":"iris"}`,
+			recorded: `{"model":"iris","predictions":[[0.5,0.5]]}`,
+		},
+		"Value": {
+			response: `{"predictions":[{"label":"cat"}]}`,
+			recorded: `{"predictions":[{"label":"dog"}]}`,
+			diff:     `.predictions[0].label: "cat", recorded "dog"`,
+		},
+		"Length": {
+			response: `{"predictions":[1]}`,
+			recorded: `{"predictions":[1,2]}`,
+			diff:     `.predictions: 1 elements, recorded 2`,
+		},
+		"Missing": {
+			response: `{}`,
+			recorded: `{"predictions":[1]}`,
+			diff:     `.predictions: missing`,
+		},
+		"Unexpected": {
+			response: `{"predictions":[1],"error":"overloaded"}`,
+			recorded: `{"predictions":[1]}`,
+			diff:     `.error: unexpected "overloaded"`,
+		},
+		"Type": {
+			response: `[1]`,
+			recorded: `{"predictions":[1]}`,
+			diff:     `.: [1] is not an object`,
+		},
+		"NotJSON": {
+			response: `cat`,
+			recorded: `dog`,
+			diff:     "response differs from the recorded response",
+		},
+	}
+	for name, scenario := range scenarios {
+		g.Expect(replayer.diff([]byte(scenario.response), []byte(scenario.recorded))).To(gomega.Equal(scenario.diff), name)
+	}
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package schedule parses the standard five field cron expressions of the component scaling schedules.
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxSearchDays bounds the search of the previous and next activation, a schedule which does not fire within a year
+// is considered inactive
+const maxSearchDays = 366
+
+type field struct {
+	name     string
+	min, max int
+}
+
+var fields = []field{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12},
+	{name: "day of week", min: 0, max: 7},
+}
+
+// Schedule is a parsed cron expression, the times are evaluated in UTC
+type Schedule struct {
+	minute, hour, dom, month, dow uint64
+	// domStar and dowStar follow the cron rule that a day matches either the day of month or the day of week when
+	// both are restricted
+	domStar, dowStar bool
+}
+
+// Parse parses a "minute hour day-of-month month day-of-week" expression. Each field is a list of values, ranges
+// and steps, e.g. "0 8 * * 1-5" or "*/15 0-6,22-23 * * *".
+func Parse(expr string) (*Schedule, error) {
+	parts := strings.Fields(expr)
+	if len(parts) != len(fields) {
+		return nil, fmt.Errorf("invalid cron expression %q, expected %d fields but got %d", expr, len(fields), len(parts))
+	}
+	bits := make([]uint64, len(fields))
+	for i, part := range parts {
+		b, err := parseField(part, fields[i])
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron expression %q: %v", expr, err)
+		}
+		bits[i] = b
+	}
+	// Sunday is both 0 and 7
+	if bits[4]&(1<<7) != 0 {
+		bits[4] |= 1
+	}
+	return &Schedule{
+		minute:  bits[0],
+		hour:    bits[1],
+		dom:     bits[2],
+		month:   bits[3],
+		dow:     bits[4],
+		domStar: strings.HasPrefix(parts[2], "*"),
+		dowStar: strings.HasPrefix(parts[4], "*"),
+	}, nil
+}
+
+func parseField(value string, f field) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(value, ",") {
+		rangePart, step := item, 1
+		if i := strings.Index(item, "/"); i >= 0 {
+			s, err := strconv.Atoi(item[i+1:])
+			if err != nil || s < 1 {
+				return 0, fmt.Errorf("invalid step %q in %s field", item[i+1:], f.name)
+			}
+			rangePart, step = item[:i], s
+		}
+		low, high := f.min, f.max
+		if rangePart != "*" {
+			bounds := strings.SplitN(rangePart, "-", 2)
+			var err error
+			if low, err = strconv.Atoi(bounds[0]); err != nil {
+				return 0, fmt.Errorf("invalid value %q in %s field", bounds[0], f.name)
+			}
+			high = low
+			if len(bounds) == 2 {
+				if high, err = strconv.Atoi(bounds[1]); err != nil {
+					return 0, fmt.Errorf("invalid value %q in %s field", bounds[1], f.name)
+				}
+			} else if step > 1 {
+				// "5/15" means every 15 starting at 5
+				high = f.max
+			}
+			if low < f.min || high > f.max || low > high {
+				return 0, fmt.Errorf("%q is out of the %d-%d range of the %s field", rangePart, f.min, f.max, f.name)
+			}
+		}
+		for v := low; v <= high; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func (s *Schedule) dayMatches(t time.Time) bool {
+	if s.month&(1<<uint(t.Month())) == 0 {
+		return false
+	}
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// latest returns the highest set bit not greater than limit, or -1
+func latest(bits uint64, limit int) int {
+	for v := limit; v >= 0; v-- {
+		if bits&(1<<uint(v)) != 0 {
+			return v
+		}
+	}
+	return -1
+}
+
+// earliest returns the lowest set bit not less than from, or -1
+func earliest(bits uint64, from int, max int) int {
+	for v := from; v <= max; v++ {
+		if bits&(1<<uint(v)) != 0 {
+			return v
+		}
+	}
+	return -1
+}
+
+// Prev returns the latest activation at or before t, and false if the schedule did not fire within a year
+func (s *Schedule) Prev(t time.Time) (time.Time, bool) {
+	t = t.UTC().Truncate(time.Minute)
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	hourLimit, minuteLimit := t.Hour(), t.Minute()
+	for i := 0; i < maxSearchDays; i++ {
+		if s.dayMatches(day) {
+			for h := latest(s.hour, hourLimit); h >= 0; h = latest(s.hour, h-1) {
+				limit := 59
+				if h == hourLimit {
+					limit = minuteLimit
+				}
+				if m := latest(s.minute, limit); m >= 0 {
+					return day.Add(time.Duration(h)*time.Hour + time.Duration(m)*time.Minute), true
+				}
+			}
+		}
+		day = day.AddDate(0, 0, -1)
+		hourLimit, minuteLimit = 23, 59
+	}
+	return time.Time{}, false
+}
+
+// Next returns the earliest activation after t, and false if the schedule does not fire within a year
+func (s *Schedule) Next(t time.Time) (time.Time, bool) {
+	t = t.UTC().Truncate(time.Minute).Add(time.Minute)
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	hourFrom, minuteFrom := t.Hour(), t.Minute()
+	for i := 0; i < maxSearchDays; i++ {
+		if s.dayMatches(day) {
+			for h := earliest(s.hour, hourFrom, 23); h >= 0; h = earliest(s.hour, h+1, 23) {
+				from := 0
+				if h == hourFrom {
+					from = minuteFrom
+				}
+				if m := earliest(s.minute, from, 59); m >= 0 {
+					return day.Add(time.Duration(h)*time.Hour + time.Duration(m)*time.Minute), true
+				}
+			}
+		}
+		day = day.AddDate(0, 0, 1)
+		hourFrom, minuteFrom = 0, 0
+	}
+	return time.Time{}, false
+}
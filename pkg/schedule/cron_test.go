@@ -0,0 +1,117 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+)
+
+func TestParse(t *testing.T) {
+	scenarios := map[string]struct {
+		expr    string
+		invalid bool
+	}{
+		"Every minute":  {expr: "* * * * *"},
+		"Business days": {expr: "0 8 * * 1-5"},
+		"Lists":         {expr: "0,30 0-6,22-23 1,15 * *"},
+		"Steps":         {expr: "*/15 5/6 * */2 *"},
+		"Sunday as 7":   {expr: "0 0 * * 7"},
+		"Missing field": {expr: "0 8 * *", invalid: true},
+		"Out of range":  {expr: "60 8 * * *", invalid: true},
+		"Reversed":      {expr: "0 18-8 * * *", invalid: true},
+		"Bad step":      {expr: "*/0 * * * *", invalid: true},
+		"Names":         {expr: "0 8 * * MON", invalid: true},
+	}
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			g := gomega.NewGomegaWithT(t)
+			_, err := Parse(scenario.expr)
+			if scenario.invalid {
+				g.Expect(err).ShouldNot(gomega.BeNil())
+			} else {
+				g.Expect(err).Should(gomega.BeNil())
+			}
+		})
+	}
+}
+
+func TestPrevNext(t *testing.T) {
+	// Wednesday
+	now := time.Date(2020, time.November, 4, 12, 30, 45, 0, time.UTC)
+	scenarios := map[string]struct {
+		expr         string
+		expectedPrev time.Time
+		expectedNext time.Time
+	}{
+		"Business hours": {
+			expr:         "0 8 * * 1-5",
+			expectedPrev: time.Date(2020, time.November, 4, 8, 0, 0, 0, time.UTC),
+			expectedNext: time.Date(2020, time.November, 5, 8, 0, 0, 0, time.UTC),
+		},
+		"Weekend": {
+			expr:         "0 0 * * 0,6",
+			expectedPrev: time.Date(2020, time.November, 1, 0, 0, 0, 0, time.UTC),
+			expectedNext: time.Date(2020, time.November, 7, 0, 0, 0, 0, time.UTC),
+		},
+		"Current minute": {
+			expr:         "30 12 * * *",
+			expectedPrev: time.Date(2020, time.November, 4, 12, 30, 0, 0, time.UTC),
+			expectedNext: time.Date(2020, time.November, 5, 12, 30, 0, 0, time.UTC),
+		},
+		"Every quarter": {
+			expr:         "*/15 * * * *",
+			expectedPrev: time.Date(2020, time.November, 4, 12, 30, 0, 0, time.UTC),
+			expectedNext: time.Date(2020, time.November, 4, 12, 45, 0, 0, time.UTC),
+		},
+		"Day of month or day of week": {
+			expr:         "0 0 1 * 5",
+			expectedPrev: time.Date(2020, time.November, 1, 0, 0, 0, 0, time.UTC),
+			expectedNext: time.Date(2020, time.November, 6, 0, 0, 0, 0, time.UTC),
+		},
+		"Yearly": {
+			expr:         "0 0 1 1 *",
+			expectedPrev: time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC),
+			expectedNext: time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			g := gomega.NewGomegaWithT(t)
+			s, err := Parse(scenario.expr)
+			g.Expect(err).Should(gomega.BeNil())
+			prev, ok := s.Prev(now)
+			g.Expect(ok).To(gomega.BeTrue())
+			g.Expect(prev).To(gomega.Equal(scenario.expectedPrev))
+			next, ok := s.Next(now)
+			g.Expect(ok).To(gomega.BeTrue())
+			g.Expect(next).To(gomega.Equal(scenario.expectedNext))
+		})
+	}
+}
+
+func TestNeverFires(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	s, err := Parse("0 0 31 2 *")
+	g.Expect(err).Should(gomega.BeNil())
+	_, ok := s.Prev(time.Now())
+	g.Expect(ok).To(gomega.BeFalse())
+	_, ok = s.Next(time.Now())
+	g.Expect(ok).To(gomega.BeFalse())
+}
@@ -17,6 +17,8 @@ limitations under the License.
 package utils
 
 import (
+	"strings"
+
 	"github.com/kubeflow/kfserving/pkg/constants"
 	v1 "k8s.io/api/core/v1"
 )
@@ -65,9 +67,19 @@ func AppendVolumeIfNotExists(slice []v1.Volume, volume v1.Volume) []v1.Volume {
 	return append(slice, volume)
 }
 
+// IsGPUResource returns true for the full GPUs, the MIG slices and their time-sliced variants
+func IsGPUResource(name v1.ResourceName) bool {
+	resource := strings.TrimSuffix(string(name), constants.NvidiaSharedGPUResourceSuffix)
+	return resource == constants.NvidiaGPUResourceType || strings.HasPrefix(resource, constants.NvidiaMIGResourcePrefix)
+}
+
 func IsGPUEnabled(requirements v1.ResourceRequirements) bool {
-	_, ok := requirements.Limits[constants.NvidiaGPUResourceType]
-	return ok
+	for name := range requirements.Limits {
+		if IsGPUResource(name) {
+			return true
+		}
+	}
+	return false
 }
 
 // FirstNonNilError returns the first non nil interface in the slice
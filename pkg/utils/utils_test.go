@@ -19,6 +19,7 @@ package utils
 import (
 	"github.com/kubeflow/kfserving/pkg/credentials/gcs"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -238,3 +239,32 @@ func TestAppendVolumeIfNotExists(t *testing.T) {
 		}
 	}
 }
+
+func TestIsGPUEnabled(t *testing.T) {
+	scenarios := map[string]struct {
+		limits   v1.ResourceList
+		expected bool
+	}{
+		"GPU": {
+			limits:   v1.ResourceList{"nvidia.com/gpu": resource.MustParse("1")},
+			expected: true,
+		},
+		"TimeSlicedGPU": {
+			limits:   v1.ResourceList{"nvidia.com/gpu.shared": resource.MustParse("1")},
+			expected: true,
+		},
+		"MIG": {
+			limits:   v1.ResourceList{"nvidia.com/mig-1g.5gb": resource.MustParse("1")},
+			expected: true,
+		},
+		"CPU": {
+			limits:   v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")},
+			expected: false,
+		},
+	}
+	for name, scenario := range scenarios {
+		if got := IsGPUEnabled(v1.ResourceRequirements{Limits: scenario.limits}); got != scenario.expected {
+			t.Errorf("Test %q unexpected result, want %v got %v", name, scenario.expected, got)
+		}
+	}
+}
@@ -16,6 +16,8 @@ limitations under the License.
 package pod
 
 import (
+	"strings"
+
 	"github.com/kubeflow/kfserving/pkg/constants"
 	"github.com/kubeflow/kfserving/pkg/utils"
 	v1 "k8s.io/api/core/v1"
@@ -25,22 +27,65 @@ import (
 const (
 	GkeAcceleratorNodeSelector = "cloud.google.com/gke-accelerator"
 	NvidiaGPUTaintValue        = "present"
+	// GKE advertises the MIG slices and the time-shared GPUs as nvidia.com/gpu on nodes labeled with the partition
+	// size and the sharing strategy
+	GkeGPUPartitionSizeNodeSelector   = "cloud.google.com/gke-gpu-partition-size"
+	GkeGPUSharingStrategyNodeSelector = "cloud.google.com/gke-gpu-sharing-strategy"
+	GkeGPUTimeSharingStrategy         = "time-sharing"
 )
 
 func InjectGKEAcceleratorSelector(pod *v1.Pod) error {
 	gpuEnabled := false
-	for _, container := range pod.Spec.Containers {
-		if _, ok := container.Resources.Limits[constants.NvidiaGPUResourceType]; ok {
+	selectors := map[string]string{}
+	for i := range pod.Spec.Containers {
+		resources := &pod.Spec.Containers[i].Resources
+		if utils.IsGPUEnabled(*resources) {
 			gpuEnabled = true
 		}
+		if _, ok := pod.Annotations[constants.InferenceServiceGKEAcceleratorAnnotationKey]; !ok {
+			continue
+		}
+		for _, list := range []v1.ResourceList{resources.Limits, resources.Requests} {
+			for name, quantity := range list {
+				if profile := strings.TrimPrefix(string(name), constants.NvidiaMIGResourcePrefix); profile != string(name) {
+					delete(list, name)
+					list[constants.NvidiaGPUResourceType] = quantity
+					selectors[GkeGPUPartitionSizeNodeSelector] = profile
+				}
+			}
+		}
 	}
 	// check if GPU is specified on container resource before applying the node selector
 	if gpuEnabled {
 		if gpuSelector, ok := pod.Annotations[constants.InferenceServiceGKEAcceleratorAnnotationKey]; ok {
-			pod.Spec.NodeSelector = utils.Union(
-				pod.Spec.NodeSelector,
-				map[string]string{GkeAcceleratorNodeSelector: gpuSelector},
-			)
+			selectors[GkeAcceleratorNodeSelector] = gpuSelector
+			if pod.Annotations[constants.GPUSharingAnnotationKey] == constants.GPUSharingTimeSlicing {
+				selectors[GkeGPUSharingStrategyNodeSelector] = GkeGPUTimeSharingStrategy
+			}
+			pod.Spec.NodeSelector = utils.Union(pod.Spec.NodeSelector, selectors)
+		}
+	}
+	return nil
+}
+
+// InjectGPUSharing requests the time-sliced variant of the GPU resources when time-slicing is requested, on GKE the
+// time-shared nodes are selected by InjectGKEAcceleratorSelector instead.
+func InjectGPUSharing(pod *v1.Pod) error {
+	if pod.Annotations[constants.GPUSharingAnnotationKey] != constants.GPUSharingTimeSlicing {
+		return nil
+	}
+	if _, ok := pod.Annotations[constants.InferenceServiceGKEAcceleratorAnnotationKey]; ok {
+		return nil
+	}
+	for i := range pod.Spec.Containers {
+		resources := &pod.Spec.Containers[i].Resources
+		for _, list := range []v1.ResourceList{resources.Limits, resources.Requests} {
+			for name, quantity := range list {
+				if utils.IsGPUResource(name) && !strings.HasSuffix(string(name), constants.NvidiaSharedGPUResourceSuffix) {
+					delete(list, name)
+					list[name+constants.NvidiaSharedGPUResourceSuffix] = quantity
+				}
+			}
 		}
 	}
 	return nil
@@ -16,6 +16,7 @@ limitations under the License.
 package pod
 
 import (
+	"sort"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -65,6 +66,33 @@ func TestAcceleratorInjector(t *testing.T) {
 				},
 			},
 		},
+		"AddMIGPartitionSelector": {
+			original: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "deployment",
+					Annotations: map[string]string{
+						constants.InferenceServiceGKEAcceleratorAnnotationKey: "nvidia-tesla-a100",
+						constants.GPUSharingAnnotationKey:                     constants.GPUSharingTimeSlicing,
+					},
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{
+						Resources: v1.ResourceRequirements{
+							Limits: v1.ResourceList{"nvidia.com/mig-1g.5gb": resource.MustParse("1")},
+						},
+					}},
+				},
+			},
+			expected: &v1.Pod{
+				Spec: v1.PodSpec{
+					NodeSelector: map[string]string{
+						GkeAcceleratorNodeSelector:        "nvidia-tesla-a100",
+						GkeGPUPartitionSizeNodeSelector:   "1g.5gb",
+						GkeGPUSharingStrategyNodeSelector: GkeGPUTimeSharingStrategy,
+					},
+				},
+			},
+		},
 		"DoNotAddGPUSelector": {
 			original: &v1.Pod{
 				ObjectMeta: metav1.ObjectMeta{
@@ -110,3 +138,57 @@ func TestAcceleratorInjector(t *testing.T) {
 		}
 	}
 }
+
+func TestGPUSharingInjector(t *testing.T) {
+	scenarios := map[string]struct {
+		annotations map[string]string
+		limits      v1.ResourceList
+		expected    []v1.ResourceName
+	}{
+		"TimeSlicedGPU": {
+			annotations: map[string]string{constants.GPUSharingAnnotationKey: constants.GPUSharingTimeSlicing},
+			limits:      v1.ResourceList{constants.NvidiaGPUResourceType: resource.MustParse("1")},
+			expected:    []v1.ResourceName{"nvidia.com/gpu.shared"},
+		},
+		"TimeSlicedMIG": {
+			annotations: map[string]string{constants.GPUSharingAnnotationKey: constants.GPUSharingTimeSlicing},
+			limits: v1.ResourceList{
+				"nvidia.com/mig-1g.5gb": resource.MustParse("1"),
+				v1.ResourceCPU:          resource.MustParse("1"),
+			},
+			expected: []v1.ResourceName{v1.ResourceCPU, "nvidia.com/mig-1g.5gb.shared"},
+		},
+		"NoSharing": {
+			annotations: map[string]string{},
+			limits:      v1.ResourceList{constants.NvidiaGPUResourceType: resource.MustParse("1")},
+			expected:    []v1.ResourceName{constants.NvidiaGPUResourceType},
+		},
+		"GKE": {
+			annotations: map[string]string{
+				constants.GPUSharingAnnotationKey:                     constants.GPUSharingTimeSlicing,
+				constants.InferenceServiceGKEAcceleratorAnnotationKey: "nvidia-tesla-t4",
+			},
+			limits:   v1.ResourceList{constants.NvidiaGPUResourceType: resource.MustParse("1")},
+			expected: []v1.ResourceName{constants.NvidiaGPUResourceType},
+		},
+	}
+	for name, scenario := range scenarios {
+		pod := &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Annotations: scenario.annotations},
+			Spec: v1.PodSpec{
+				Containers: []v1.Container{{
+					Resources: v1.ResourceRequirements{Limits: scenario.limits},
+				}},
+			},
+		}
+		InjectGPUSharing(pod)
+		names := []v1.ResourceName{}
+		for resourceName := range pod.Spec.Containers[0].Resources.Limits {
+			names = append(names, resourceName)
+		}
+		sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+		if diff := cmp.Diff(scenario.expected, names); diff != "" {
+			t.Errorf("Test %q unexpected result (-want +got): %v", name, diff)
+		}
+	}
+}
@@ -36,6 +36,11 @@ const (
 	LoggerArgumentInferenceService = "--inference-service"
 	LoggerArgumentNamespace        = "--namespace"
 	LoggerArgumentEndpoint         = "--endpoint"
+	LoggerArgumentOutlierUrl       = "--outlier-url"
+	LoggerArgumentSchemaFile       = "--schema-file"
+	LoggerArgumentEnsemble         = "--ensemble"
+	LoggerArgumentRetries          = "--retries"
+	LoggerArgumentDeadLetterUrl    = "--dead-letter-url"
 )
 
 type LoggerConfig struct {
@@ -77,14 +82,18 @@ func getLoggerConfigs(configMap *v1.ConfigMap) (*LoggerConfig, error) {
 }
 
 func (il *LoggerInjector) InjectLogger(pod *v1.Pod) error {
-	// Only inject if the required annotations are set
-	_, ok := pod.ObjectMeta.Annotations[constants.LoggerInternalAnnotationKey]
-	if !ok {
+	// Only inject if the required annotations are set, the logger also scores the requests of the inline outlier
+	// detector, validates the requests and combines the predictions of the ensemble models without logging them
+	_, logging := pod.ObjectMeta.Annotations[constants.LoggerInternalAnnotationKey]
+	outlierUrl, inlineOutlierDetection := pod.ObjectMeta.Annotations[constants.LoggerOutlierUrlInternalAnnotationKey]
+	schemaConfigMap, requestValidation := pod.ObjectMeta.Annotations[constants.LoggerSchemaInternalAnnotationKey]
+	ensemble, ensembling := pod.ObjectMeta.Annotations[constants.LoggerEnsembleInternalAnnotationKey]
+	if !logging && !inlineOutlierDetection && !requestValidation && !ensembling {
 		return nil
 	}
 
 	logUrl, ok := pod.ObjectMeta.Annotations[constants.LoggerSinkUrlInternalAnnotationKey]
-	if !ok {
+	if !ok && logging {
 		logUrl = il.config.DefaultUrl
 	}
 
@@ -107,23 +116,40 @@ func (il *LoggerInjector) InjectLogger(pod *v1.Pod) error {
 	// Make sure securityContext is initialized and valid
 	securityContext := pod.Spec.Containers[0].SecurityContext.DeepCopy()
 
+	args := []string{
+		LoggerArgumentLogUrl,
+		logUrl,
+		LoggerArgumentSourceUri,
+		pod.Name,
+		LoggerArgumentMode,
+		logMode,
+		LoggerArgumentInferenceService,
+		inferenceServiceName,
+		LoggerArgumentNamespace,
+		namespace,
+		LoggerArgumentEndpoint,
+		endpoint,
+	}
+	if inlineOutlierDetection {
+		args = append(args, LoggerArgumentOutlierUrl, outlierUrl)
+	}
+	if requestValidation {
+		args = append(args, LoggerArgumentSchemaFile, constants.ModelSchemaDir+"/"+constants.ModelSchemaFileName)
+	}
+	if retries, ok := pod.ObjectMeta.Annotations[constants.LoggerRetriesInternalAnnotationKey]; ok {
+		args = append(args, LoggerArgumentRetries, retries)
+	}
+	if deadLetterUrl, ok := pod.ObjectMeta.Annotations[constants.LoggerDeadLetterUrlInternalAnnotationKey]; ok {
+		args = append(args, LoggerArgumentDeadLetterUrl, deadLetterUrl)
+	}
+	if ensembling {
+		args = append(args, LoggerArgumentEnsemble, ensemble)
+	}
+
 	loggerContainer := &v1.Container{
 		Name:  LoggerContainerName,
 		Image: il.config.Image,
-		Args: []string{
-			LoggerArgumentLogUrl,
-			logUrl,
-			LoggerArgumentSourceUri,
-			pod.Name,
-			LoggerArgumentMode,
-			logMode,
-			LoggerArgumentInferenceService,
-			inferenceServiceName,
-			LoggerArgumentNamespace,
-			namespace,
-			LoggerArgumentEndpoint,
-			endpoint,
-		},
+		Args:  args,
 		Resources: v1.ResourceRequirements{
 			Limits: map[v1.ResourceName]resource.Quantity{
 				v1.ResourceCPU:    resource.MustParse(il.config.CpuLimit),
@@ -140,5 +166,23 @@ func (il *LoggerInjector) InjectLogger(pod *v1.Pod) error {
 	// Add container to the spec
 	pod.Spec.Containers = append(pod.Spec.Containers, *loggerContainer)
 
+	// The schema configmap is created once the latest ready revision answers the metadata request, the logger does
+	// not validate the requests until then
+	if requestValidation {
+		optional := true
+		schemaVolume := v1.Volume{
+			Name: constants.ModelSchemaVolumeName,
+			VolumeSource: v1.VolumeSource{
+				ConfigMap: &v1.ConfigMapVolumeSource{
+					LocalObjectReference: v1.LocalObjectReference{
+						Name: schemaConfigMap,
+					},
+					Optional: &optional,
+				},
+			},
+		}
+		mountVolumeToContainer(LoggerContainerName, pod, schemaVolume, constants.ModelSchemaDir)
+	}
+
 	return nil
 }
@@ -17,6 +17,7 @@ limitations under the License.
 package pod
 
 import (
+	"github.com/golang/protobuf/proto"
 	"github.com/kubeflow/kfserving/pkg/apis/serving/v1alpha2"
 	"k8s.io/apimachinery/pkg/api/resource"
 	"knative.dev/pkg/kmp"
@@ -119,6 +120,172 @@ func TestLoggerInjector(t *testing.T) {
 				},
 			},
 		},
+		"AddInlineOutlierDetection": {
+			original: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "deployment",
+					Namespace: "default",
+					Annotations: map[string]string{
+						constants.LoggerOutlierUrlInternalAnnotationKey: "http://sklearn-outlier-detector-default.default.svc.cluster.local",
+					},
+					Labels: map[string]string{
+						constants.KServiceModelLabel:    "sklearn",
+						constants.KServiceEndpointLabel: "default",
+					},
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{
+						Name: "sklearn",
+					}},
+				},
+			},
+			expected: &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{
+						Name: "sklearn",
+					},
+						{
+							Name:  LoggerContainerName,
+							Image: loggerConfig.Image,
+							Args: []string{
+								LoggerArgumentLogUrl,
+								"",
+								LoggerArgumentSourceUri,
+								"deployment",
+								LoggerArgumentMode,
+								"all",
+								LoggerArgumentInferenceService,
+								"sklearn",
+								LoggerArgumentNamespace,
+								"default",
+								LoggerArgumentEndpoint,
+								"default",
+								LoggerArgumentOutlierUrl,
+								"http://sklearn-outlier-detector-default.default.svc.cluster.local",
+							},
+							Resources: loggerResourceRequirement,
+						},
+					},
+				},
+			},
+		},
+		"AddRequestValidation": {
+			original: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "deployment",
+					Namespace: "default",
+					Annotations: map[string]string{
+						constants.LoggerSchemaInternalAnnotationKey: "sklearn-model-schema",
+					},
+					Labels: map[string]string{
+						constants.KServiceModelLabel:    "sklearn",
+						constants.KServiceEndpointLabel: "default",
+					},
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{
+						Name: "sklearn",
+					}},
+				},
+			},
+			expected: &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{
+						Name: "sklearn",
+					},
+						{
+							Name:  LoggerContainerName,
+							Image: loggerConfig.Image,
+							Args: []string{
+								LoggerArgumentLogUrl,
+								"",
+								LoggerArgumentSourceUri,
+								"deployment",
+								LoggerArgumentMode,
+								"all",
+								LoggerArgumentInferenceService,
+								"sklearn",
+								LoggerArgumentNamespace,
+								"default",
+								LoggerArgumentEndpoint,
+								"default",
+								LoggerArgumentSchemaFile,
+								"/mnt/schema/schema.json",
+							},
+							Resources: loggerResourceRequirement,
+							VolumeMounts: []v1.VolumeMount{
+								{
+									Name:      constants.ModelSchemaVolumeName,
+									MountPath: constants.ModelSchemaDir,
+								},
+							},
+						},
+					},
+					Volumes: []v1.Volume{
+						{
+							Name: constants.ModelSchemaVolumeName,
+							VolumeSource: v1.VolumeSource{
+								ConfigMap: &v1.ConfigMapVolumeSource{
+									LocalObjectReference: v1.LocalObjectReference{
+										Name: "sklearn-model-schema",
+									},
+									Optional: proto.Bool(true),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		"AddEnsemble": {
+			original: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "deployment",
+					Namespace: "default",
+					Annotations: map[string]string{
+						constants.LoggerEnsembleInternalAnnotationKey: `{"models":[{"name":"a","storageUri":"gs://a"},{"name":"b","storageUri":"gs://b"}]}`,
+					},
+					Labels: map[string]string{
+						constants.KServiceModelLabel:    "sklearn",
+						constants.KServiceEndpointLabel: "default",
+					},
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{
+						Name: "sklearn",
+					}},
+				},
+			},
+			expected: &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{
+						Name: "sklearn",
+					},
+						{
+							Name:  LoggerContainerName,
+							Image: loggerConfig.Image,
+							Args: []string{
+								LoggerArgumentLogUrl,
+								"",
+								LoggerArgumentSourceUri,
+								"deployment",
+								LoggerArgumentMode,
+								"all",
+								LoggerArgumentInferenceService,
+								"sklearn",
+								LoggerArgumentNamespace,
+								"default",
+								LoggerArgumentEndpoint,
+								"default",
+								LoggerArgumentEnsemble,
+								`{"models":[{"name":"a","storageUri":"gs://a"},{"name":"b","storageUri":"gs://b"}]}`,
+							},
+							Resources: loggerResourceRequirement,
+						},
+					},
+				},
+			},
+		},
 		"DoNotAddLogger": {
 			original: &v1.Pod{
 				ObjectMeta: metav1.ObjectMeta{
@@ -34,7 +34,10 @@ var log = logf.Log.WithName(constants.PodMutatorWebhookName)
 
 // Mutator is a webhook that injects incoming pods
 type Mutator struct {
-	Client  client.Client
+	Client client.Client
+	// Reader reads the pods of the components from the API server, listing them with the cached client would start
+	// an informer of all the pods of the cluster inside the admission request
+	Reader  client.Reader
 	Decoder *admission.Decoder
 }
 
@@ -116,12 +119,51 @@ func (mutator *Mutator) mutate(pod *v1.Pod, configMap *v1.ConfigMap) error {
 		config:            agentConfig,
 	}
 
+	spotConfig, err := getSpotConfigs(configMap)
+	if err != nil {
+		return err
+	}
+
+	spotInjector := &SpotInjector{
+		reader: mutator.Reader,
+		config: spotConfig,
+	}
+
+	topologySpreadConfig, err := getTopologySpreadConfigs(configMap)
+	if err != nil {
+		return err
+	}
+
+	topologySpreadInjector := &TopologySpreadInjector{
+		config: topologySpreadConfig,
+	}
+
+	scratchVolumesConfig, err := getScratchVolumesConfigs(configMap)
+	if err != nil {
+		return err
+	}
+
+	scratchVolumeInjector := &ScratchVolumeInjector{
+		config: scratchVolumesConfig,
+	}
+
+	runtimeClassInjector := &RuntimeClassInjector{
+		client: mutator.Client,
+	}
+
 	mutators := []func(pod *v1.Pod) error{
 		InjectGKEAcceleratorSelector,
+		InjectGPUSharing,
+		spotInjector.InjectSpotPlacement,
+		topologySpreadInjector.InjectTopologySpread,
+		runtimeClassInjector.InjectRuntimeClass,
+		InjectStartupProbe,
 		storageInitializer.InjectStorageInitializer,
+		scratchVolumeInjector.InjectScratchVolumes,
 		loggerInjector.InjectLogger,
 		batcherInjector.InjectBatcher,
 		agentInjector.InjectAgent,
+		InjectGracefulShutdown,
 	}
 
 	for _, mutator := range mutators {
@@ -0,0 +1,72 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kubeflow/kfserving/pkg/constants"
+	v1 "k8s.io/api/core/v1"
+	nodev1beta1 "k8s.io/api/node/v1beta1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type RuntimeClassInjector struct {
+	client client.Client
+}
+
+// InjectRuntimeClass sets the runtime class of the component in its pods, knative drops it from the revision pod spec
+// unless its kubernetes.podspec-runtimeclassname feature is enabled. The RuntimeClass admission controller runs before
+// the webhooks, so the overhead and the scheduling of the runtime class are set as it would set them, the pods would
+// otherwise be rejected for a missing overhead.
+func (ri *RuntimeClassInjector) InjectRuntimeClass(pod *v1.Pod) error {
+	name, ok := pod.Annotations[constants.RuntimeClassInternalAnnotationKey]
+	// The runtime class of a pod is immutable, don't inject in the pods which were already created
+	if !ok || pod.UID != "" || pod.Spec.RuntimeClassName != nil {
+		return nil
+	}
+	runtimeClass := &nodev1beta1.RuntimeClass{}
+	if err := ri.client.Get(context.TODO(), types.NamespacedName{Name: name}, runtimeClass); err != nil {
+		return fmt.Errorf("Unable to get the runtime class %v due to %v", name, err)
+	}
+	if runtimeClass.Scheduling != nil {
+		for key, value := range runtimeClass.Scheduling.NodeSelector {
+			if existing, ok := pod.Spec.NodeSelector[key]; ok && existing != value {
+				return fmt.Errorf("The node selector %v=%v of the runtime class %v conflicts with the pod node selector",
+					key, value, name)
+			}
+		}
+		for key, value := range runtimeClass.Scheduling.NodeSelector {
+			if pod.Spec.NodeSelector == nil {
+				pod.Spec.NodeSelector = map[string]string{}
+			}
+			pod.Spec.NodeSelector[key] = value
+		}
+		for _, toleration := range runtimeClass.Scheduling.Tolerations {
+			if !hasToleration(pod.Spec.Tolerations, toleration) {
+				pod.Spec.Tolerations = append(pod.Spec.Tolerations, toleration)
+			}
+		}
+	}
+	if runtimeClass.Overhead != nil {
+		pod.Spec.Overhead = runtimeClass.Overhead.PodFixed
+	}
+	pod.Spec.RuntimeClassName = &name
+	return nil
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/constants"
+	v1 "k8s.io/api/core/v1"
+	nodev1beta1 "k8s.io/api/node/v1beta1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"knative.dev/pkg/kmp"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestInjectRuntimeClass(t *testing.T) {
+	gvisor, kata := "gvisor", "kata"
+	overhead := v1.ResourceList{v1.ResourceMemory: resource.MustParse("120Mi")}
+	sandbox := v1.Toleration{Key: "sandbox", Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoSchedule}
+	injector := &RuntimeClassInjector{
+		client: fake.NewFakeClientWithScheme(scheme.Scheme,
+			&nodev1beta1.RuntimeClass{
+				ObjectMeta: metav1.ObjectMeta{Name: gvisor},
+				Handler:    "runsc",
+			},
+			&nodev1beta1.RuntimeClass{
+				ObjectMeta: metav1.ObjectMeta{Name: kata},
+				Handler:    "kata",
+				Overhead:   &nodev1beta1.Overhead{PodFixed: overhead},
+				Scheduling: &nodev1beta1.Scheduling{
+					NodeSelector: map[string]string{"sandbox": "kata"},
+					Tolerations:  []v1.Toleration{sandbox},
+				},
+			}),
+	}
+	scenarios := map[string]struct {
+		original *v1.Pod
+		expected v1.PodSpec
+		err      bool
+	}{
+		"RuntimeClass": {
+			original: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+					constants.RuntimeClassInternalAnnotationKey: gvisor,
+				}},
+			},
+			expected: v1.PodSpec{RuntimeClassName: &gvisor},
+		},
+		"OverheadAndScheduling": {
+			original: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+					constants.RuntimeClassInternalAnnotationKey: kata,
+				}},
+				Spec: v1.PodSpec{NodeSelector: map[string]string{"zone": "a"}},
+			},
+			expected: v1.PodSpec{
+				RuntimeClassName: &kata,
+				Overhead:         overhead,
+				NodeSelector:     map[string]string{"zone": "a", "sandbox": "kata"},
+				Tolerations:      []v1.Toleration{sandbox},
+			},
+		},
+		"ConflictingNodeSelector": {
+			original: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+					constants.RuntimeClassInternalAnnotationKey: kata,
+				}},
+				Spec: v1.PodSpec{NodeSelector: map[string]string{"sandbox": "gvisor"}},
+			},
+			expected: v1.PodSpec{NodeSelector: map[string]string{"sandbox": "gvisor"}},
+			err:      true,
+		},
+		"NotFound": {
+			original: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+					constants.RuntimeClassInternalAnnotationKey: "runc",
+				}},
+			},
+			err: true,
+		},
+		"Created": {
+			original: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					UID:         types.UID("6a2b3c"),
+					Annotations: map[string]string{constants.RuntimeClassInternalAnnotationKey: kata},
+				},
+			},
+		},
+		"NoRuntimeClass": {
+			original: &v1.Pod{},
+		},
+	}
+
+	for name, scenario := range scenarios {
+		err := injector.InjectRuntimeClass(scenario.original)
+		if scenario.err != (err != nil) {
+			t.Errorf("Test %q unexpected error %v", name, err)
+		}
+		if diff, _ := kmp.SafeDiff(scenario.expected, scenario.original.Spec); diff != "" {
+			t.Errorf("Test %q unexpected result (-want +got): %v", name, diff)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/kubeflow/kfserving/pkg/constants"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	ScratchVolumesConfigMapKeyName = "scratchVolumes"
+	ScratchVolumeNamePrefix        = "kfserving-scratch-"
+	DefaultScratchDir              = "/tmp"
+)
+
+// ScratchVolumesConfig describes the writable directories of the model servers which run with a read-only root
+// filesystem
+type ScratchVolumesConfig struct {
+	// Directories of all the model servers, defaults to /tmp
+	Default []string `json:"default,omitempty"`
+	// Directories of the model servers of a runtime in addition to the default ones, by the name of the runtime in the
+	// predictors, transformers, explainers and detectors configs, e.g. pytorch
+	Runtimes map[string][]string `json:"runtimes,omitempty"`
+	// Size limit of each scratch volume
+	SizeLimit *resource.Quantity `json:"sizeLimit,omitempty"`
+}
+
+type ScratchVolumeInjector struct {
+	config *ScratchVolumesConfig
+}
+
+func getScratchVolumesConfigs(configMap *v1.ConfigMap) (*ScratchVolumesConfig, error) {
+	scratchVolumesConfig := &ScratchVolumesConfig{Default: []string{DefaultScratchDir}}
+	if scratchVolumes, ok := configMap.Data[ScratchVolumesConfigMapKeyName]; ok {
+		scratchVolumesConfig = &ScratchVolumesConfig{}
+		if err := json.Unmarshal([]byte(scratchVolumes), scratchVolumesConfig); err != nil {
+			return nil, fmt.Errorf("Unable to unmarshall %v json string due to %v ", ScratchVolumesConfigMapKeyName, err)
+		}
+	}
+	dirs := append([]string{}, scratchVolumesConfig.Default...)
+	for _, runtimeDirs := range scratchVolumesConfig.Runtimes {
+		dirs = append(dirs, runtimeDirs...)
+	}
+	for _, dir := range dirs {
+		if !strings.HasPrefix(dir, "/") {
+			return nil, fmt.Errorf("Invalid scratch volumes config, %q is not an absolute path.", dir)
+		}
+	}
+	return scratchVolumesConfig, nil
+}
+
+// InjectScratchVolumes mounts an emptyDir on each scratch directory of the runtime of the model server container when
+// it runs with a read-only root filesystem, the directories which are already mounted are left untouched
+func (si *ScratchVolumeInjector) InjectScratchVolumes(pod *v1.Pod) error {
+	for i := range pod.Spec.Containers {
+		container := &pod.Spec.Containers[i]
+		if container.Name != constants.InferenceServiceContainerName || container.SecurityContext == nil ||
+			container.SecurityContext.ReadOnlyRootFilesystem == nil || !*container.SecurityContext.ReadOnlyRootFilesystem {
+			continue
+		}
+		dirs := append(append([]string{}, si.config.Default...),
+			si.config.Runtimes[pod.Annotations[constants.RuntimeInternalAnnotationKey]]...)
+		for _, dir := range dirs {
+			if isMounted(container, dir) {
+				continue
+			}
+			name := fmt.Sprintf("%s%d", ScratchVolumeNamePrefix, len(pod.Spec.Volumes))
+			pod.Spec.Volumes = append(pod.Spec.Volumes, v1.Volume{
+				Name: name,
+				VolumeSource: v1.VolumeSource{
+					EmptyDir: &v1.EmptyDirVolumeSource{SizeLimit: si.config.SizeLimit},
+				},
+			})
+			container.VolumeMounts = append(container.VolumeMounts, v1.VolumeMount{Name: name, MountPath: dir})
+		}
+	}
+	return nil
+}
+
+func isMounted(container *v1.Container, dir string) bool {
+	for _, mount := range container.VolumeMounts {
+		if strings.TrimSuffix(mount.MountPath, "/") == strings.TrimSuffix(dir, "/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/constants"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/kmp"
+)
+
+func TestInjectScratchVolumes(t *testing.T) {
+	readOnly := true
+	securityContext := &v1.SecurityContext{ReadOnlyRootFilesystem: &readOnly}
+	injector := &ScratchVolumeInjector{
+		config: &ScratchVolumesConfig{
+			Default:  []string{"/tmp"},
+			Runtimes: map[string][]string{"pytorch": {"/home/model-server/logs"}},
+		},
+	}
+	scenarios := map[string]struct {
+		original *v1.Pod
+		expected v1.PodSpec
+	}{
+		"RuntimeScratchVolumes": {
+			original: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+					constants.RuntimeInternalAnnotationKey: "pytorch",
+				}},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{Name: constants.InferenceServiceContainerName, SecurityContext: securityContext},
+					},
+				},
+			},
+			expected: v1.PodSpec{
+				Containers: []v1.Container{
+					{
+						Name:            constants.InferenceServiceContainerName,
+						SecurityContext: securityContext,
+						VolumeMounts: []v1.VolumeMount{
+							{Name: "kfserving-scratch-0", MountPath: "/tmp"},
+							{Name: "kfserving-scratch-1", MountPath: "/home/model-server/logs"},
+						},
+					},
+				},
+				Volumes: []v1.Volume{
+					{Name: "kfserving-scratch-0", VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}}},
+					{Name: "kfserving-scratch-1", VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}}},
+				},
+			},
+		},
+		"AlreadyMounted": {
+			original: &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{
+							Name:            constants.InferenceServiceContainerName,
+							SecurityContext: securityContext,
+							VolumeMounts:    []v1.VolumeMount{{Name: "tmp", MountPath: "/tmp/"}},
+						},
+					},
+					Volumes: []v1.Volume{{Name: "tmp"}},
+				},
+			},
+			expected: v1.PodSpec{
+				Containers: []v1.Container{
+					{
+						Name:            constants.InferenceServiceContainerName,
+						SecurityContext: securityContext,
+						VolumeMounts:    []v1.VolumeMount{{Name: "tmp", MountPath: "/tmp/"}},
+					},
+				},
+				Volumes: []v1.Volume{{Name: "tmp"}},
+			},
+		},
+		"WritableRootFilesystem": {
+			original: &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{Name: constants.InferenceServiceContainerName}},
+				},
+			},
+			expected: v1.PodSpec{
+				Containers: []v1.Container{{Name: constants.InferenceServiceContainerName}},
+			},
+		},
+	}
+
+	for name, scenario := range scenarios {
+		if err := injector.InjectScratchVolumes(scenario.original); err != nil {
+			t.Errorf("Test %q unexpected error %v", name, err)
+		}
+		if diff, _ := kmp.SafeDiff(scenario.expected, scenario.original.Spec); diff != "" {
+			t.Errorf("Test %q unexpected result (-want +got): %v", name, diff)
+		}
+	}
+}
+
+func TestGetScratchVolumesConfigs(t *testing.T) {
+	scenarios := map[string]struct {
+		configMap *v1.ConfigMap
+		expected  *ScratchVolumesConfig
+		err       bool
+	}{
+		"Default": {
+			configMap: &v1.ConfigMap{},
+			expected:  &ScratchVolumesConfig{Default: []string{DefaultScratchDir}},
+		},
+		"Runtimes": {
+			configMap: &v1.ConfigMap{Data: map[string]string{
+				ScratchVolumesConfigMapKeyName: `{"default": ["/tmp"], "runtimes": {"triton": ["/root/.cache"]}}`,
+			}},
+			expected: &ScratchVolumesConfig{
+				Default:  []string{"/tmp"},
+				Runtimes: map[string][]string{"triton": {"/root/.cache"}},
+			},
+		},
+		"RelativePath": {
+			configMap: &v1.ConfigMap{Data: map[string]string{
+				ScratchVolumesConfigMapKeyName: `{"default": ["tmp"]}`,
+			}},
+			err: true,
+		},
+	}
+
+	for name, scenario := range scenarios {
+		config, err := getScratchVolumesConfigs(scenario.configMap)
+		if scenario.err {
+			if err == nil {
+				t.Errorf("Test %q expected an error", name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test %q unexpected error %v", name, err)
+		}
+		if diff, _ := kmp.SafeDiff(scenario.expected, config); diff != "" {
+			t.Errorf("Test %q unexpected result (-want +got): %v", name, diff)
+		}
+	}
+}
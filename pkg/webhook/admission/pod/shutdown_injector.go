@@ -0,0 +1,77 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/kubeflow/kfserving/pkg/constants"
+	v1 "k8s.io/api/core/v1"
+)
+
+// QueueProxyContainerName is the name of the knative sidecar, it drains the requests in flight on SIGTERM on its own
+const QueueProxyContainerName = "queue-proxy"
+
+// preStopSkippedContainers are the sidecars which are not given the pre-stop sleep, the kfserving sidecars are
+// distroless images without a sleep binary and their pre-stop hook would fail
+var preStopSkippedContainers = map[string]bool{
+	QueueProxyContainerName:      true,
+	LoggerContainerName:          true,
+	BatcherContainerName:         true,
+	constants.AgentContainerName: true,
+}
+
+// InjectGracefulShutdown sets the termination grace period of the component pods and injects the pre-stop sleep in
+// their containers, so that the containers keep serving while the pod is removed from the endpoints
+func InjectGracefulShutdown(pod *v1.Pod) error {
+	// The containers of a pod are immutable, don't inject in the pods which were already created
+	if pod.UID != "" {
+		return nil
+	}
+	if value, ok := pod.Annotations[constants.TerminationGracePeriodInternalAnnotationKey]; ok {
+		gracePeriod, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("Unable to parse the termination grace period %v due to %v", value, err)
+		}
+		pod.Spec.TerminationGracePeriodSeconds = &gracePeriod
+	}
+	value, ok := pod.Annotations[constants.PreStopSleepInternalAnnotationKey]
+	if !ok {
+		return nil
+	}
+	sleep, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return fmt.Errorf("Unable to parse the pre-stop sleep %v due to %v", value, err)
+	}
+	if sleep == 0 {
+		return nil
+	}
+	for i := range pod.Spec.Containers {
+		container := &pod.Spec.Containers[i]
+		if preStopSkippedContainers[container.Name] || (container.Lifecycle != nil && container.Lifecycle.PreStop != nil) {
+			continue
+		}
+		if container.Lifecycle == nil {
+			container.Lifecycle = &v1.Lifecycle{}
+		}
+		container.Lifecycle.PreStop = &v1.Handler{
+			Exec: &v1.ExecAction{Command: []string{"sleep", strconv.FormatInt(sleep, 10)}},
+		}
+	}
+	return nil
+}
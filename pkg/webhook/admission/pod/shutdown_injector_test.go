@@ -0,0 +1,102 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/constants"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/kmp"
+)
+
+func TestInjectGracefulShutdown(t *testing.T) {
+	gracePeriod := int64(60)
+	sleep := &v1.Lifecycle{PreStop: &v1.Handler{Exec: &v1.ExecAction{Command: []string{"sleep", "15"}}}}
+	drain := &v1.Lifecycle{PreStop: &v1.Handler{Exec: &v1.ExecAction{Command: []string{"/drain"}}}}
+	scenarios := map[string]struct {
+		original *v1.Pod
+		expected v1.PodSpec
+	}{
+		"PreStopSleep": {
+			original: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+					constants.PreStopSleepInternalAnnotationKey:           "15",
+					constants.TerminationGracePeriodInternalAnnotationKey: "60",
+				}},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{Name: constants.InferenceServiceContainerName},
+						{Name: "explainer", Lifecycle: drain},
+						{Name: QueueProxyContainerName},
+					},
+				},
+			},
+			expected: v1.PodSpec{
+				TerminationGracePeriodSeconds: &gracePeriod,
+				Containers: []v1.Container{
+					{Name: constants.InferenceServiceContainerName, Lifecycle: sleep},
+					{Name: "explainer", Lifecycle: drain},
+					{Name: QueueProxyContainerName},
+				},
+			},
+		},
+		"DistrolessSidecars": {
+			original: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+					constants.PreStopSleepInternalAnnotationKey: "15",
+				}},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{Name: constants.InferenceServiceContainerName},
+						{Name: LoggerContainerName},
+						{Name: BatcherContainerName},
+						{Name: constants.AgentContainerName},
+					},
+				},
+			},
+			expected: v1.PodSpec{
+				Containers: []v1.Container{
+					{Name: constants.InferenceServiceContainerName, Lifecycle: sleep},
+					{Name: LoggerContainerName},
+					{Name: BatcherContainerName},
+					{Name: constants.AgentContainerName},
+				},
+			},
+		},
+		"NoShutdown": {
+			original: &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{Name: constants.InferenceServiceContainerName}},
+				},
+			},
+			expected: v1.PodSpec{
+				Containers: []v1.Container{{Name: constants.InferenceServiceContainerName}},
+			},
+		},
+	}
+
+	for name, scenario := range scenarios {
+		if err := InjectGracefulShutdown(scenario.original); err != nil {
+			t.Errorf("Test %q unexpected error %v", name, err)
+		}
+		if diff, _ := kmp.SafeDiff(scenario.expected, scenario.original.Spec); diff != "" {
+			t.Errorf("Test %q unexpected result (-want +got): %v", name, diff)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	SpotConfigMapKeyName = "spot"
+	// The GKE spot node pools are selected when the spot config is not set
+	GkeSpotNodeSelector       = "cloud.google.com/gke-spot"
+	DefaultSpotWeight         = 100
+	DefaultPreemptionFallback = 600
+)
+
+// Reasons set on the pods terminated by the shutdown of their node
+var preemptedPodReasons = map[string]bool{
+	"NodeShutdown": true,
+	"Terminated":   true,
+	"Shutdown":     true,
+	"NodeLost":     true,
+}
+
+const podDisruptionTarget v1.PodConditionType = "DisruptionTarget"
+
+// SpotConfig describes the spot node pools the PreferSpot components are placed on
+type SpotConfig struct {
+	// Label of the spot nodes
+	NodeSelectorKey   string `json:"nodeSelectorKey"`
+	NodeSelectorValue string `json:"nodeSelectorValue"`
+	// Tolerations of the taints of the spot nodes
+	Tolerations []v1.Toleration `json:"tolerations,omitempty"`
+	// Weight of the preference for the spot nodes, between 1 and 100
+	Weight int32 `json:"weight,omitempty"`
+	// Seconds after a pod of a component is preempted during which its new pods prefer the on-demand nodes
+	PreemptionFallbackSeconds int64 `json:"preemptionFallbackSeconds,omitempty"`
+}
+
+type SpotInjector struct {
+	reader client.Reader
+	config *SpotConfig
+}
+
+func getSpotConfigs(configMap *v1.ConfigMap) (*SpotConfig, error) {
+	spotConfig := &SpotConfig{
+		NodeSelectorKey:   GkeSpotNodeSelector,
+		NodeSelectorValue: "true",
+		Tolerations: []v1.Toleration{{
+			Key:      GkeSpotNodeSelector,
+			Operator: v1.TolerationOpEqual,
+			Value:    "true",
+			Effect:   v1.TaintEffectNoSchedule,
+		}},
+	}
+	if spot, ok := configMap.Data[SpotConfigMapKeyName]; ok {
+		spotConfig = &SpotConfig{}
+		if err := json.Unmarshal([]byte(spot), spotConfig); err != nil {
+			return nil, fmt.Errorf("Unable to unmarshall %v json string due to %v ", SpotConfigMapKeyName, err)
+		}
+	}
+	if spotConfig.NodeSelectorKey == "" {
+		return nil, fmt.Errorf("Invalid spot config, nodeSelectorKey is required.")
+	}
+	if spotConfig.Weight == 0 {
+		spotConfig.Weight = DefaultSpotWeight
+	}
+	if spotConfig.Weight < 1 || spotConfig.Weight > 100 {
+		return nil, fmt.Errorf("Invalid spot config, weight must be between 1 and 100.")
+	}
+	if spotConfig.PreemptionFallbackSeconds == 0 {
+		spotConfig.PreemptionFallbackSeconds = DefaultPreemptionFallback
+	}
+	return spotConfig, nil
+}
+
+// preemptedAt returns the time the pod was terminated by the shutdown of its spot node, or false if it was not
+func preemptedAt(pod *v1.Pod) (time.Time, bool) {
+	preempted := preemptedPodReasons[pod.Status.Reason]
+	var at time.Time
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == podDisruptionTarget && condition.Status == v1.ConditionTrue {
+			preempted = true
+		}
+		if (condition.Type == podDisruptionTarget || condition.Type == v1.PodReady) &&
+			condition.LastTransitionTime.Time.After(at) {
+			at = condition.LastTransitionTime.Time
+		}
+	}
+	return at, preempted && !at.IsZero()
+}
+
+// recentlyPreempted returns true if a pod of the same component was preempted within the fallback period, the pods of
+// the component are listed from the API server by namespace and labels
+func (si *SpotInjector) recentlyPreempted(pod *v1.Pod) (bool, error) {
+	pods := &v1.PodList{}
+	if err := si.reader.List(context.TODO(), pods, client.InNamespace(pod.Namespace), client.MatchingLabels{
+		constants.InferenceServicePodLabelKey: pod.Labels[constants.InferenceServicePodLabelKey],
+		constants.KServiceComponentLabel:      pod.Labels[constants.KServiceComponentLabel],
+	}); err != nil {
+		return false, err
+	}
+	since := time.Now().Add(-time.Duration(si.config.PreemptionFallbackSeconds) * time.Second)
+	for i := range pods.Items {
+		if at, ok := preemptedAt(&pods.Items[i]); ok && at.After(since) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// InjectSpotPlacement prefers the spot nodes for the pods of the PreferSpot components. The preference is not
+// required, so the pods are scheduled on on-demand nodes when there is no spot capacity. After a pod of the component
+// is preempted, its new pods prefer the on-demand nodes for the fallback period so that they are not rescheduled on
+// the spot capacity which is being reclaimed.
+func (si *SpotInjector) InjectSpotPlacement(pod *v1.Pod) error {
+	if pod.Annotations[constants.PlacementPolicyInternalAnnotationKey] != string(v1beta1.PreferSpotPlacement) {
+		return nil
+	}
+	if pod.Spec.Affinity == nil {
+		pod.Spec.Affinity = &v1.Affinity{}
+	}
+	if pod.Spec.Affinity.NodeAffinity == nil {
+		pod.Spec.Affinity.NodeAffinity = &v1.NodeAffinity{}
+	}
+	nodeAffinity := pod.Spec.Affinity.NodeAffinity
+	// Don't inject if the placement was already injected when the pod was created
+	for _, term := range nodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution {
+		for _, expression := range term.Preference.MatchExpressions {
+			if expression.Key == si.config.NodeSelectorKey {
+				return nil
+			}
+		}
+	}
+
+	preempted, err := si.recentlyPreempted(pod)
+	if err != nil {
+		return err
+	}
+	spotNodes := v1.NodeSelectorRequirement{
+		Key:      si.config.NodeSelectorKey,
+		Operator: v1.NodeSelectorOpIn,
+		Values:   []string{si.config.NodeSelectorValue},
+	}
+	if preempted {
+		log.Info("Placing pod on on-demand nodes after a recent preemption", "namespace", pod.Namespace,
+			"name", pod.Labels[constants.InferenceServicePodLabelKey])
+		spotNodes.Operator = v1.NodeSelectorOpNotIn
+	} else {
+		for _, toleration := range si.config.Tolerations {
+			if !hasToleration(pod.Spec.Tolerations, toleration) {
+				pod.Spec.Tolerations = append(pod.Spec.Tolerations, toleration)
+			}
+		}
+	}
+	nodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution = append(
+		nodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution, v1.PreferredSchedulingTerm{
+			Weight:     si.config.Weight,
+			Preference: v1.NodeSelectorTerm{MatchExpressions: []v1.NodeSelectorRequirement{spotNodes}},
+		})
+	return nil
+}
+
+func hasToleration(tolerations []v1.Toleration, toleration v1.Toleration) bool {
+	for _, t := range tolerations {
+		if t.MatchToleration(&toleration) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,202 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kubeflow/kfserving/pkg/constants"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"knative.dev/pkg/kmp"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var spotConfig = &SpotConfig{
+	NodeSelectorKey:   GkeSpotNodeSelector,
+	NodeSelectorValue: "true",
+	Tolerations: []v1.Toleration{{
+		Key:      GkeSpotNodeSelector,
+		Operator: v1.TolerationOpEqual,
+		Value:    "true",
+		Effect:   v1.TaintEffectNoSchedule,
+	}},
+	Weight:                    DefaultSpotWeight,
+	PreemptionFallbackSeconds: DefaultPreemptionFallback,
+}
+
+func spotPod(name string, annotations map[string]string) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   "default",
+			Annotations: annotations,
+			Labels: map[string]string{
+				constants.InferenceServicePodLabelKey: "sklearn",
+				constants.KServiceComponentLabel:      "predictor",
+			},
+		},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{{
+				Name: "sklearn",
+			}},
+		},
+	}
+}
+
+func preemptedPod(name string, at time.Time) *v1.Pod {
+	pod := spotPod(name, nil)
+	pod.Status = v1.PodStatus{
+		Phase:  v1.PodFailed,
+		Reason: "NodeShutdown",
+		Conditions: []v1.PodCondition{{
+			Type:               v1.PodReady,
+			Status:             v1.ConditionFalse,
+			LastTransitionTime: metav1.NewTime(at),
+		}},
+	}
+	return pod
+}
+
+func spotAffinity(operator v1.NodeSelectorOperator) *v1.Affinity {
+	return &v1.Affinity{
+		NodeAffinity: &v1.NodeAffinity{
+			PreferredDuringSchedulingIgnoredDuringExecution: []v1.PreferredSchedulingTerm{{
+				Weight: DefaultSpotWeight,
+				Preference: v1.NodeSelectorTerm{
+					MatchExpressions: []v1.NodeSelectorRequirement{{
+						Key:      GkeSpotNodeSelector,
+						Operator: operator,
+						Values:   []string{"true"},
+					}},
+				},
+			}},
+		},
+	}
+}
+
+func TestSpotInjector(t *testing.T) {
+	preferSpot := map[string]string{constants.PlacementPolicyInternalAnnotationKey: "PreferSpot"}
+	scenarios := map[string]struct {
+		pods     []runtime.Object
+		original *v1.Pod
+		expected *v1.Pod
+	}{
+		"PreferSpot": {
+			original: spotPod("deployment", preferSpot),
+			expected: &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers:  []v1.Container{{Name: "sklearn"}},
+					Tolerations: spotConfig.Tolerations,
+					Affinity:    spotAffinity(v1.NodeSelectorOpIn),
+				},
+			},
+		},
+		"FallbackToOnDemandAfterPreemption": {
+			pods:     []runtime.Object{preemptedPod("preempted", time.Now().Add(-time.Minute))},
+			original: spotPod("deployment", preferSpot),
+			expected: &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{Name: "sklearn"}},
+					Affinity:   spotAffinity(v1.NodeSelectorOpNotIn),
+				},
+			},
+		},
+		"PreferSpotAfterFallbackPeriod": {
+			pods:     []runtime.Object{preemptedPod("preempted", time.Now().Add(-time.Hour))},
+			original: spotPod("deployment", preferSpot),
+			expected: &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers:  []v1.Container{{Name: "sklearn"}},
+					Tolerations: spotConfig.Tolerations,
+					Affinity:    spotAffinity(v1.NodeSelectorOpIn),
+				},
+			},
+		},
+		"DoNotInjectAgain": {
+			original: &v1.Pod{
+				ObjectMeta: spotPod("deployment", preferSpot).ObjectMeta,
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{Name: "sklearn"}},
+					Affinity:   spotAffinity(v1.NodeSelectorOpNotIn),
+				},
+			},
+			expected: &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{Name: "sklearn"}},
+					Affinity:   spotAffinity(v1.NodeSelectorOpNotIn),
+				},
+			},
+		},
+		"OnDemand": {
+			original: spotPod("deployment", nil),
+			expected: &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{Name: "sklearn"}},
+				},
+			},
+		},
+	}
+
+	for name, scenario := range scenarios {
+		injector := &SpotInjector{
+			reader: fake.NewFakeClientWithScheme(scheme.Scheme, scenario.pods...),
+			config: spotConfig,
+		}
+		if err := injector.InjectSpotPlacement(scenario.original); err != nil {
+			t.Errorf("Test %q unexpected error %v", name, err)
+		}
+		if diff, _ := kmp.SafeDiff(scenario.expected.Spec, scenario.original.Spec); diff != "" {
+			t.Errorf("Test %q unexpected result (-want +got): %v", name, diff)
+		}
+	}
+}
+
+func TestGetSpotConfigs(t *testing.T) {
+	config, err := getSpotConfigs(&v1.ConfigMap{})
+	if err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	if diff, _ := kmp.SafeDiff(spotConfig, config); diff != "" {
+		t.Errorf("unexpected default config (-want +got): %v", diff)
+	}
+
+	config, err = getSpotConfigs(&v1.ConfigMap{Data: map[string]string{
+		SpotConfigMapKeyName: `{"nodeSelectorKey": "eks.amazonaws.com/capacityType", "nodeSelectorValue": "SPOT"}`,
+	}})
+	if err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	expected := &SpotConfig{
+		NodeSelectorKey:           "eks.amazonaws.com/capacityType",
+		NodeSelectorValue:         "SPOT",
+		Weight:                    DefaultSpotWeight,
+		PreemptionFallbackSeconds: DefaultPreemptionFallback,
+	}
+	if diff, _ := kmp.SafeDiff(expected, config); diff != "" {
+		t.Errorf("unexpected config (-want +got): %v", diff)
+	}
+
+	if _, err := getSpotConfigs(&v1.ConfigMap{Data: map[string]string{
+		SpotConfigMapKeyName: `{"nodeSelectorKey": "spot", "weight": 200}`,
+	}}); err == nil {
+		t.Errorf("expected error for the out of range weight")
+	}
+}
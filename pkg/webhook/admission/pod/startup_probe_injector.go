@@ -0,0 +1,55 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/kubeflow/kfserving/pkg/constants"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// InjectStartupProbe injects the startup probe of the predictor in the model server container, a probe without
+// handler checks that the model server listens on its port
+func InjectStartupProbe(pod *v1.Pod) error {
+	data, ok := pod.Annotations[constants.StartupProbeInternalAnnotationKey]
+	// The probes of a pod are immutable, don't inject in the pods which were already created
+	if !ok || pod.UID != "" {
+		return nil
+	}
+	probe := &v1.Probe{}
+	if err := json.Unmarshal([]byte(data), probe); err != nil {
+		return fmt.Errorf("Unable to unmarshall the startup probe %v due to %v", data, err)
+	}
+	for i := range pod.Spec.Containers {
+		container := &pod.Spec.Containers[i]
+		if container.Name != constants.InferenceServiceContainerName || container.StartupProbe != nil {
+			continue
+		}
+		if probe.Handler == (v1.Handler{}) {
+			port := intstr.Parse(constants.InferenceServiceDefaultHttpPort)
+			if len(container.Ports) != 0 {
+				port = intstr.FromInt(int(container.Ports[0].ContainerPort))
+			}
+			probe.Handler.TCPSocket = &v1.TCPSocketAction{Port: port}
+		}
+		container.StartupProbe = probe
+	}
+	return nil
+}
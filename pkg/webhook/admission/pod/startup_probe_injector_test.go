@@ -0,0 +1,83 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/constants"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"knative.dev/pkg/kmp"
+)
+
+func TestInjectStartupProbe(t *testing.T) {
+	startupProbe := map[string]string{
+		constants.StartupProbeInternalAnnotationKey: `{"periodSeconds": 10, "failureThreshold": 90}`,
+	}
+	scenarios := map[string]struct {
+		original *v1.Pod
+		expected *v1.Probe
+	}{
+		"TCPSocketOnContainerPort": {
+			original: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Annotations: startupProbe},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{
+						Name:  constants.InferenceServiceContainerName,
+						Ports: []v1.ContainerPort{{Name: "user-port", ContainerPort: 8081}},
+					}},
+				},
+			},
+			expected: &v1.Probe{
+				Handler:          v1.Handler{TCPSocket: &v1.TCPSocketAction{Port: intstr.FromInt(8081)}},
+				PeriodSeconds:    10,
+				FailureThreshold: 90,
+			},
+		},
+		"ProbeHandler": {
+			original: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+					constants.StartupProbeInternalAnnotationKey: `{"httpGet": {"path": "/v2/health/ready", "port": 8080}}`,
+				}},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{Name: constants.InferenceServiceContainerName}},
+				},
+			},
+			expected: &v1.Probe{
+				Handler: v1.Handler{HTTPGet: &v1.HTTPGetAction{Path: "/v2/health/ready", Port: intstr.FromInt(8080)}},
+			},
+		},
+		"NoStartupProbe": {
+			original: &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{Name: constants.InferenceServiceContainerName}},
+				},
+			},
+		},
+	}
+
+	for name, scenario := range scenarios {
+		if err := InjectStartupProbe(scenario.original); err != nil {
+			t.Errorf("Test %q unexpected error %v", name, err)
+		}
+		if diff, _ := kmp.SafeDiff(scenario.expected, scenario.original.Spec.Containers[0].StartupProbe); diff != "" {
+			t.Errorf("Test %q unexpected result (-want +got): %v", name, diff)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/kubeflow/kfserving/pkg/constants"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	TopologySpreadConfigMapKeyName = "topologySpread"
+)
+
+// TopologySpreadConfig describes how the pods of the components without topology spread constraints are spread
+type TopologySpreadConfig struct {
+	// Constraints of the pods of the components which don't set topologySpreadConstraints, the pods are spread across
+	// the zones when the topology spread config is not set
+	DefaultConstraints []v1.TopologySpreadConstraint `json:"defaultConstraints"`
+}
+
+type TopologySpreadInjector struct {
+	config *TopologySpreadConfig
+}
+
+func getTopologySpreadConfigs(configMap *v1.ConfigMap) (*TopologySpreadConfig, error) {
+	topologySpreadConfig := &TopologySpreadConfig{
+		DefaultConstraints: []v1.TopologySpreadConstraint{{
+			MaxSkew:           1,
+			TopologyKey:       v1.LabelZoneFailureDomainStable,
+			WhenUnsatisfiable: v1.ScheduleAnyway,
+		}},
+	}
+	if topologySpread, ok := configMap.Data[TopologySpreadConfigMapKeyName]; ok {
+		topologySpreadConfig = &TopologySpreadConfig{}
+		if err := json.Unmarshal([]byte(topologySpread), topologySpreadConfig); err != nil {
+			return nil, fmt.Errorf("Unable to unmarshall %v json string due to %v ", TopologySpreadConfigMapKeyName, err)
+		}
+	}
+	for _, constraint := range topologySpreadConfig.DefaultConstraints {
+		if constraint.TopologyKey == "" || constraint.MaxSkew < 1 || constraint.WhenUnsatisfiable == "" {
+			return nil, fmt.Errorf("Invalid topology spread config, default constraints must have a topologyKey, a maxSkew and whenUnsatisfiable.")
+		}
+	}
+	return topologySpreadConfig, nil
+}
+
+// InjectTopologySpread injects the topology spread constraints of the component, or the default constraints, in its
+// pods. The constraints without label selector select the pods of the component.
+func (ti *TopologySpreadInjector) InjectTopologySpread(pod *v1.Pod) error {
+	// The constraints of a pod are immutable, don't inject in the pods which were already created
+	if pod.UID != "" || len(pod.Spec.TopologySpreadConstraints) != 0 {
+		return nil
+	}
+	constraints := ti.config.DefaultConstraints
+	if data, ok := pod.Annotations[constants.TopologySpreadInternalAnnotationKey]; ok {
+		constraints = nil
+		if err := json.Unmarshal([]byte(data), &constraints); err != nil {
+			return fmt.Errorf("Unable to unmarshall the topology spread constraints %v due to %v", data, err)
+		}
+	}
+	for _, constraint := range constraints {
+		if constraint.LabelSelector == nil {
+			constraint.LabelSelector = &metav1.LabelSelector{MatchLabels: map[string]string{
+				constants.InferenceServicePodLabelKey: pod.Labels[constants.InferenceServicePodLabelKey],
+				constants.KServiceComponentLabel:      pod.Labels[constants.KServiceComponentLabel],
+			}}
+		}
+		pod.Spec.TopologySpreadConstraints = append(pod.Spec.TopologySpreadConstraints, constraint)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/constants"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/kmp"
+)
+
+func TestTopologySpreadInjector(t *testing.T) {
+	config, err := getTopologySpreadConfigs(&v1.ConfigMap{})
+	if err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	componentPods := &metav1.LabelSelector{MatchLabels: map[string]string{
+		constants.InferenceServicePodLabelKey: "sklearn",
+		constants.KServiceComponentLabel:      "predictor",
+	}}
+	scenarios := map[string]struct {
+		original *v1.Pod
+		expected []v1.TopologySpreadConstraint
+	}{
+		"DefaultConstraints": {
+			original: spotPod("deployment", nil),
+			expected: []v1.TopologySpreadConstraint{{
+				MaxSkew:           1,
+				TopologyKey:       v1.LabelZoneFailureDomainStable,
+				WhenUnsatisfiable: v1.ScheduleAnyway,
+				LabelSelector:     componentPods,
+			}},
+		},
+		"ComponentConstraints": {
+			original: spotPod("deployment", map[string]string{
+				constants.TopologySpreadInternalAnnotationKey: `[{"maxSkew": 2, "topologyKey": "kubernetes.io/hostname", "whenUnsatisfiable": "DoNotSchedule"}]`,
+			}),
+			expected: []v1.TopologySpreadConstraint{{
+				MaxSkew:           2,
+				TopologyKey:       v1.LabelHostname,
+				WhenUnsatisfiable: v1.DoNotSchedule,
+				LabelSelector:     componentPods,
+			}},
+		},
+		"DoNotInjectInCreatedPods": {
+			original: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{UID: "6d0c6f2e", Labels: spotPod("deployment", nil).Labels},
+			},
+		},
+	}
+
+	for name, scenario := range scenarios {
+		injector := &TopologySpreadInjector{config: config}
+		if err := injector.InjectTopologySpread(scenario.original); err != nil {
+			t.Errorf("Test %q unexpected error %v", name, err)
+		}
+		if diff, _ := kmp.SafeDiff(scenario.expected, scenario.original.Spec.TopologySpreadConstraints); diff != "" {
+			t.Errorf("Test %q unexpected result (-want +got): %v", name, diff)
+		}
+	}
+
+	config, err = getTopologySpreadConfigs(&v1.ConfigMap{Data: map[string]string{
+		TopologySpreadConfigMapKeyName: `{"defaultConstraints": []}`,
+	}})
+	if err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	pod := spotPod("deployment", nil)
+	if err := (&TopologySpreadInjector{config: config}).InjectTopologySpread(pod); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	if len(pod.Spec.TopologySpreadConstraints) != 0 {
+		t.Errorf("expected no constraints when the default constraints are disabled")
+	}
+}
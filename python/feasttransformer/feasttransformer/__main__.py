@@ -0,0 +1,46 @@
+# Copyright 2020 kubeflow.org.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#    http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import argparse
+
+import kfserving
+from feasttransformer import FeastTransformer
+
+DEFAULT_MODEL_NAME = "model"
+
+
+def parse_entity(value: str):
+    join_key, sep, field = value.partition("=")
+    if not sep or not join_key or not field:
+        raise argparse.ArgumentTypeError("entity %s is not of the form <join key>=<request field>" % value)
+    return join_key, field
+
+
+parser = argparse.ArgumentParser(parents=[kfserving.kfserver.parser])
+parser.add_argument('--model_name', default=DEFAULT_MODEL_NAME,
+                    help='The name that the model is served under.')
+parser.add_argument('--predictor_host', help='The URL for the model predict function', required=True)
+parser.add_argument('--feast_serving_url', help='The address of the Feast online feature server', required=True)
+parser.add_argument('--feature_service', help='The feature service listing the joined features', required=True)
+parser.add_argument('--entity', dest='entities', type=parse_entity, action='append', required=True,
+                    help='The join key of an entity and the request field holding it, e.g. driver_id=driver')
+
+args, _ = parser.parse_known_args()
+
+if __name__ == "__main__":
+    transformer = FeastTransformer(args.model_name, predictor_host=args.predictor_host,
+                                   feast_serving_url=args.feast_serving_url,
+                                   feature_service=args.feature_service, entities=args.entities)
+    kfserver = kfserving.KFServer()
+    kfserver.start(models=[transformer])
@@ -0,0 +1,87 @@
+# Copyright 2020 kubeflow.org.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#    http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import logging
+from typing import Dict, List, Tuple
+
+import kfserving
+import requests
+import tornado.web
+
+logging.basicConfig(level=kfserving.constants.KFSERVING_LOGLEVEL)
+
+ONLINE_FEATURES_PATH = "/get-online-features"
+
+
+class FeastTransformer(kfserving.KFModel):
+    """Joins the online features of a Feast feature service into the request instances.
+
+    The features are read from the HTTP endpoint of the Feast feature server (`feast serve`), which returns one
+    result per feature holding the values of all the requested entity rows.
+    """
+
+    def __init__(self, name: str, predictor_host: str, feast_serving_url: str, feature_service: str,
+                 entities: List[Tuple[str, str]]):
+        super().__init__(name)
+        self.predictor_host = predictor_host
+        if "://" not in feast_serving_url:
+            feast_serving_url = "http://" + feast_serving_url
+        self.online_features_url = feast_serving_url.rstrip("/") + ONLINE_FEATURES_PATH
+        self.feature_service = feature_service
+        self.entities = entities
+        self.session = requests.Session()
+
+    def entity_rows(self, instances: List[Dict]) -> Dict[str, List]:
+        rows: Dict[str, List] = {join_key: [] for join_key, _ in self.entities}
+        for index, instance in enumerate(instances):
+            if not isinstance(instance, dict):
+                raise tornado.web.HTTPError(
+                    status_code=400, reason="instance %d is not an object of named fields" % index)
+            for join_key, field in self.entities:
+                if field not in instance:
+                    raise tornado.web.HTTPError(
+                        status_code=400, reason="instance %d has no field %s" % (index, field))
+                rows[join_key].append(instance[field])
+        return rows
+
+    def online_features(self, rows: Dict[str, List]) -> Dict:
+        try:
+            response = self.session.post(self.online_features_url, timeout=self.timeout,
+                                         json={"feature_service": self.feature_service, "entities": rows})
+            response.raise_for_status()
+            return response.json()
+        except (requests.RequestException, ValueError) as e:
+            raise tornado.web.HTTPError(
+                status_code=503, reason="fails to read the online features from %s: %s" % (self.online_features_url, e))
+
+    def preprocess(self, inputs: Dict) -> Dict:
+        instances = inputs.get("instances")
+        if not isinstance(instances, list):
+            raise tornado.web.HTTPError(status_code=400, reason="expected \"instances\" to be a list")
+        if not instances:
+            return inputs
+        features = self.online_features(self.entity_rows(instances))
+        join_keys = {join_key for join_key, _ in self.entities}
+        names = features["metadata"]["feature_names"]
+        enriched = [dict(instance) for instance in instances]
+        for name, result in zip(names, features["results"]):
+            # The entity columns echo the keys of the request
+            if name in join_keys:
+                continue
+            for instance, value in zip(enriched, result["values"]):
+                instance[name] = value
+        return {**inputs, "instances": enriched}
+
+    def postprocess(self, outputs: Dict) -> Dict:
+        return outputs
@@ -0,0 +1,66 @@
+# Copyright 2020 kubeflow.org.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#    http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+from unittest import mock
+
+import pytest
+import requests
+import tornado.web
+
+from feasttransformer import FeastTransformer
+
+ONLINE_FEATURES = {
+    "metadata": {"feature_names": ["driver_id", "conv_rate", "acc_rate"]},
+    "results": [
+        {"values": [1001, 1002], "statuses": ["PRESENT", "PRESENT"]},
+        {"values": [0.5, 0.7], "statuses": ["PRESENT", "PRESENT"]},
+        {"values": [0.9, None], "statuses": ["PRESENT", "NOT_FOUND"]},
+    ]
+}
+
+
+def transformer() -> FeastTransformer:
+    return FeastTransformer("driver", predictor_host="driver-predictor-default.default",
+                            feast_serving_url="feast-feature-server.feast:6566",
+                            feature_service="driver_activity", entities=[("driver_id", "driver")])
+
+
+def test_preprocess_joins_features():
+    model = transformer()
+    response = mock.Mock()
+    response.json.return_value = ONLINE_FEATURES
+    with mock.patch.object(model.session, "post", return_value=response) as post:
+        request = model.preprocess({"instances": [{"driver": 1001, "trip": 3}, {"driver": 1002, "trip": 5}]})
+    post.assert_called_once_with("http://feast-feature-server.feast:6566/get-online-features",
+                                 timeout=model.timeout,
+                                 json={"feature_service": "driver_activity", "entities": {"driver_id": [1001, 1002]}})
+    assert request == {"instances": [
+        {"driver": 1001, "trip": 3, "conv_rate": 0.5, "acc_rate": 0.9},
+        {"driver": 1002, "trip": 5, "conv_rate": 0.7, "acc_rate": None},
+    ]}
+
+
+def test_preprocess_missing_field():
+    model = transformer()
+    with pytest.raises(tornado.web.HTTPError) as e:
+        model.preprocess({"instances": [{"trip": 3}]})
+    assert e.value.status_code == 400
+
+
+def test_preprocess_feature_server_unavailable():
+    model = transformer()
+    with mock.patch.object(model.session, "post", side_effect=requests.ConnectionError("refused")):
+        with pytest.raises(tornado.web.HTTPError) as e:
+            model.preprocess({"instances": [{"driver": 1001}]})
+    assert e.value.status_code == 503